@@ -0,0 +1,71 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen-openapi writes the OpenAPI definitions of the custom resources of the operator, which the Python and Java
+// SDKs are generated from. Run it from the root of the repository through hack/update-sdk.sh.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+
+	"github.com/golang/glog"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/openapi"
+)
+
+const apiPackage = "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+
+var (
+	output = flag.String("output", "api/openapi-spec/swagger.json", "The file the OpenAPI document is written to.")
+	apiDir = flag.String("api-dir", "pkg/apis/sparkoperator.k8s.io/v1beta1",
+		"The directory of the source of the API types.")
+)
+
+func main() {
+	flag.Parse()
+
+	generator := openapi.NewGenerator()
+	if err := generator.LoadDescriptions(apiPackage, *apiDir); err != nil {
+		glog.Fatal(err)
+	}
+	if err := generator.Add(
+		&v1beta1.SparkApplication{},
+		&v1beta1.SparkApplicationList{},
+		&v1beta1.ScheduledSparkApplication{},
+		&v1beta1.ScheduledSparkApplicationList{},
+		&v1beta1.IngestJob{},
+		&v1beta1.IngestJobList{},
+		&v1beta1.SparkThriftServer{},
+		&v1beta1.SparkThriftServerList{},
+		&v1beta1.SparkApplicationRun{},
+		&v1beta1.SparkApplicationRunList{},
+		&v1beta1.SparkOperatorConfiguration{},
+		&v1beta1.SparkOperatorConfigurationList{},
+	); err != nil {
+		glog.Fatalf("failed to generate the OpenAPI definitions: %v", err)
+	}
+
+	content, err := json.MarshalIndent(generator.Document("Spark Operator", v1beta1.Version), "", "  ")
+	if err != nil {
+		glog.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*output, append(content, '\n'), 0644); err != nil {
+		glog.Fatalf("failed to write the OpenAPI document to %s: %v", *output, err)
+	}
+}
@@ -0,0 +1,46 @@
+#!/bin/bash
+#
+# Copyright 2018 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+# Generates the OpenAPI definitions of the custom resources and the models of the Python and Java SDKs from them.
+# Requires Go and Docker. The hand-written files of the SDKs are listed in their .openapi-generator-ignore.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd $(dirname ${BASH_SOURCE})/..; pwd)
+OPENAPI_GENERATOR_IMAGE=${OPENAPI_GENERATOR_IMAGE:-openapitools/openapi-generator-cli:v4.0.3}
+SWAGGER_FILE=api/openapi-spec/swagger.json
+SDK_VERSION=${SDK_VERSION:-0.1.0}
+
+cd ${SCRIPT_ROOT}
+mkdir -p $(dirname ${SWAGGER_FILE})
+go run hack/gen-openapi/main.go -output ${SWAGGER_FILE}
+
+function generate {
+  docker run --rm -u "$(id -u):$(id -g)" -v "${SCRIPT_ROOT}:/local" ${OPENAPI_GENERATOR_IMAGE} generate \
+    -i /local/${SWAGGER_FILE} --skip-validate-spec "$@"
+}
+
+echo "Generating the Python SDK"
+generate -g python -o /local/sdk/python \
+  --additional-properties packageName=spark_operator,projectName=spark-operator,packageVersion=${SDK_VERSION}
+
+echo "Generating the Java SDK"
+generate -g java --library okhttp-gson -o /local/sdk/java \
+  --additional-properties groupId=io.k8s.sparkoperator,artifactId=spark-operator-client,artifactVersion=${SDK_VERSION} \
+  --additional-properties invokerPackage=io.k8s.sparkoperator,modelPackage=io.k8s.sparkoperator.models \
+  --additional-properties apiPackage=io.k8s.sparkoperator.apis,hideGenerationTimestamp=true
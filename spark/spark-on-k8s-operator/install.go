@@ -0,0 +1,93 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/installer"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// runInstaller runs the install or upgrade subcommand with the given command-line arguments.
+func runInstaller(command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	master := flags.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig.")
+	kubeConfig := flags.String("kubeConfig", "", "Path to a kube config. Defaults to $KUBECONFIG or ~/.kube/config.")
+	namespace := flags.String("namespace", "spark-operator", "The namespace the operator is installed in.")
+	image := flags.String("image", installer.DefaultImage, "The image of the operator, e.g., one in a private registry of an air-gapped cluster.")
+	enableWebhook := flags.Bool("enable-webhook", false, "Whether to install the mutating admission webhook, including its certificates.")
+	webhookSvcName := flags.String("webhook-svc-name", "spark-webhook", "The name of the Service for the webhook server.")
+	webhookPort := flags.Int("webhook-port", 8080, "Service port of the webhook server.")
+	webhookConfigName := flags.String("webhook-config-name", "spark-webhook-config", "The name of the MutatingWebhookConfiguration object to create.")
+	dryRun := flags.Bool("dry-run", false, "Whether to print the objects of the installation as YAML instead of applying them.")
+	var operatorArgs util.ArrayFlags
+	flags.Var(&operatorArgs, "operator-arg", "An additional argument of the operator, e.g., -operator-arg=-enable-metrics=true. May be repeated.")
+	flags.Parse(args)
+
+	config := installer.Config{
+		Namespace:          *namespace,
+		Image:              *image,
+		EnableWebhook:      *enableWebhook,
+		WebhookServiceName: *webhookSvcName,
+		WebhookPort:        *webhookPort,
+		WebhookConfigName:  *webhookConfigName,
+		Args:               operatorArgs,
+	}
+	if *dryRun {
+		return installer.New(config, nil, nil, os.Stdout).DryRun()
+	}
+
+	restConfig, err := buildInstallerConfig(*master, *kubeConfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := clientset.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	apiExtensionsClient, err := apiextensionsclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	if err := installer.New(config, kubeClient, apiExtensionsClient, os.Stdout).Install(command == "upgrade"); err != nil {
+		return err
+	}
+	fmt.Printf("The operator is installed in namespace %s\n", *namespace)
+	return nil
+}
+
+// buildInstallerConfig builds the client config of the installer, which unlike the operator usually runs
+// outside of the cluster.
+func buildInstallerConfig(masterURL string, kubeConfig string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeConfig != "" {
+		rules.ExplicitPath = kubeConfig
+	}
+	overrides := &clientcmd.ConfigOverrides{}
+	if masterURL != "" {
+		overrides.ClusterInfo.Server = masterURL
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
@@ -19,32 +19,58 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/golang/glog"
 	apiv1 "k8s.io/api/core/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/archive"
 	crclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
 	crinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	operatorConfig "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/ingestjob"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/scheduledsparkapplication"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparkapplication"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparkdashboard"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparknamespace"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparkoperatorconfiguration"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/controller/sparkthriftserver"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd"
+	hccrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/hadoopcluster"
+	ijcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/ingestjob"
 	ssacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/scheduledsparkapplication"
 	sacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplication"
+	sarcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplicationrun"
+	socrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkoperatorconfiguration"
+	stscrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkthriftserver"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/datahub"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/features"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/fips"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/lineage"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/livy"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/migration"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/notification"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/scheduler"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/ui"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/webhook"
 )
@@ -53,7 +79,9 @@ var (
 	master              = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	kubeConfig          = flag.String("kubeConfig", "", "Path to a kube config. Only required if out-of-cluster.")
 	installCRDs         = flag.Bool("install-crds", true, "Whether to install CRDs")
+	migrateOnStartup    = flag.Bool("migrate-on-startup", false, "Whether to rewrite the custom resources created by earlier versions of the operator in the latest schema before starting the controllers, like the migrate subcommand does.")
 	controllerThreads   = flag.Int("controller-threads", 10, "Number of worker threads used by the SparkApplication controller.")
+	submissionWorkers   = flag.Int("submission-workers", 5, "Number of worker threads of the SparkApplication controller dedicated to submitting applications, in addition to -controller-threads. Applications are submitted by the -controller-threads workers if not positive.")
 	resyncInterval      = flag.Int("resync-interval", 30, "Informer resync interval in seconds.")
 	namespace           = flag.String("namespace", apiv1.NamespaceAll, "The Kubernetes namespace to manage. Will manage custom resource objects of the managed CRD types for the whole cluster if unset.")
 	enableWebhook       = flag.Bool("enable-webhook", false, "Whether to enable the mutating admission webhook for admitting and patching Spark pods.")
@@ -67,13 +95,152 @@ var (
 	metricsEndpoint     = flag.String("metrics-endpoint", "/metrics", "Metrics endpoint.")
 	metricsPrefix       = flag.String("metrics-prefix", "", "Prefix for the metrics.")
 	ingressUrlFormat    = flag.String("ingress-url-format", "", "Ingress URL format.")
+	seccompProfile      = flag.String("default-seccomp-profile", "", "Default seccomp profile applied by the webhook to Spark pods, e.g., runtime/default.")
+	appArmorProfile     = flag.String("default-apparmor-profile", "", "Default AppArmor profile applied by the webhook to Spark containers, e.g., runtime/default.")
+	maxRunningApps      = flag.Int("max-running-applications", 0, "Maximum number of SparkApplications running concurrently. Additional applications are queued and started in weighted fair-share order. Unlimited if not positive.")
+	archiveBucket       = flag.String("archive-bucket-url", "", "URL of a gs:// or s3:// bucket and optional key prefix to which records of deleted SparkApplications are archived. Archival is disabled if unset.")
+	archiveEndpoint     = flag.String("archive-endpoint", "", "Endpoint of S3-compatible storage used for archival.")
+	archiveRegion       = flag.String("archive-region", "", "Region of the S3 bucket used for archival.")
+	archiveLogLines     = flag.Int64("archive-driver-log-lines", 100, "Number of lines at the end of the driver log included in archived records.")
+	impersonate         = flag.Bool("enable-impersonation", false, "Whether to impersonate the user who created a SparkApplication when running spark-submit for it. Requires the webhook.")
+	defaultEnvConfigMap = flag.String("default-env-configmap", "", "Key <namespace>/<name> of a ConfigMap of environment variables the webhook injects into all Spark containers.")
+	enforceLinuxNodes   = flag.Bool("enforce-linux-nodes", false, "Whether the webhook restricts Spark pods to Linux nodes and rejects SparkApplications selecting other nodes.")
+	enableIstioMode     = flag.Bool("enable-istio-mode", false, "Whether to make Spark pods work with Istio sidecar proxies injected into them.")
+	nsDeletionPolicy    = flag.String("namespace-deletion-policy", string(webhook.NamespaceDeletionAllow), "What the webhook does when a namespace with running SparkApplications is deleted: Allow, Block, or Drain, which deletes the applications and denies the deletion until they are gone.")
+	proxyUserSuperuser  = flag.String("proxy-user-superuser", "", "Hadoop superuser impersonating the proxy users of SparkApplications, set as HADOOP_USER_NAME of their drivers unless they set it.")
+	enableNsBootstrap   = flag.Bool("enable-namespace-bootstrap", false, "Whether to bootstrap RBAC, resource defaults and network policies in namespaces labeled spark-enabled=true.")
+	bootstrapSA         = flag.String("bootstrap-service-account", "spark", "Name of the driver service account created in bootstrapped namespaces.")
+	bootstrapCPU        = flag.String("bootstrap-default-cpu-request", "100m", "Default CPU request of containers in bootstrapped namespaces.")
+	bootstrapMemory     = flag.String("bootstrap-default-memory-request", "256Mi", "Default memory request of containers in bootstrapped namespaces.")
+	enableDashboards    = flag.Bool("enable-dashboards", false, "Whether to generate a GrafanaDashboard and PrometheusRule for each class of SparkApplications. Requires -enable-metrics.")
+	dashboardClassLabel = flag.String("dashboard-class-label", operatorConfig.SparkAppClassLabel, "Label of SparkApplications whose value is the class dashboards and alert rules are generated for.")
+	dashboardTemplate   = flag.String("dashboard-template", "", "Path to a template of the Grafana dashboard JSON generated for each application class. Uses a built-in dashboard if unset.")
+	alertRulesTemplate  = flag.String("alert-rules-template", "", "Path to a template of the YAML list of Prometheus rule groups generated for each application class. Uses built-in rules if unset.")
+	progressInterval    = flag.Duration("progress-reporting-interval", 0, "Interval at which the REST API of running drivers is polled for the progress of their jobs and stages, which is recorded in the application status. Progress reporting is disabled if not positive.")
+	enableUI            = flag.Bool("enable-ui", false, "Whether to serve a web UI listing SparkApplications with actions to kill and resubmit them. Requires an authenticating proxy in front of the UI.")
+	uiPort              = flag.Int("ui-port", 8090, "Port of the web UI.")
+	enableLivy          = flag.Bool("enable-livy", false, "Whether to serve a Livy-compatible REST API running batches and interactive sessions as SparkApplications. Clients authenticate with bearer tokens and are authorized on the SparkApplications in -livy-namespace.")
+	livyAddress         = flag.String("livy-address", "", "Address the Livy-compatible API listens on, e.g., 127.0.0.1 to only be reachable through a proxy in the operator pod. Listens on all addresses if empty.")
+	livyPort            = flag.Int("livy-port", 8998, "Port of the Livy-compatible API.")
+	livyNamespace       = flag.String("livy-namespace", apiv1.NamespaceDefault, "Namespace of the SparkApplications of Livy batches and sessions.")
+	livyImage           = flag.String("livy-image", "", "Container image of Livy batches and sessions, unless set in spark.kubernetes.container.image. Sessions require PySpark in the image.")
+	livySparkVersion    = flag.String("livy-spark-version", "", "Version of Spark in the image of Livy batches and sessions.")
+	livyServiceAccount  = flag.String("livy-service-account", "", "Service account of the drivers of Livy batches and sessions.")
+	uiAddress           = flag.String("ui-address", "", "Address the web UI listens on, e.g., 127.0.0.1 to only be reachable through a proxy in the operator pod. Listens on all addresses if empty.")
+	uiUserHeader        = flag.String("ui-user-header", "", "Request header carrying the name of the user authenticated by a proxy in the operator pod in front of the web UI, e.g., X-Forwarded-User. Only trusted on requests from localhost, and requires -ui-address=127.0.0.1 or -ui-proxy-secret-file. Users can only authenticate with bearer tokens if empty.")
+	uiGroupsHeader      = flag.String("ui-groups-header", "X-Forwarded-Groups", "Request header carrying the comma-separated groups of the user authenticated by a proxy in the operator pod in front of the web UI. Only trusted on requests from localhost.")
+	uiProxySecretFile   = flag.String("ui-proxy-secret-file", "", "File containing a secret the proxy in front of the web UI passes in the X-Proxy-Secret header. The user headers are only trusted on requests carrying it if set.")
+	historyServerURL    = flag.String("ui-history-server-url", "", "Base URL of the Spark history server linked to from the web UI.")
+	sparkDistributions  = flag.String("spark-distributions", "", "Path to a YAML file listing the Spark distributions in the operator image, each with its version, SPARK_HOME and default Spark configuration. Applications are submitted with the distribution matching their sparkVersion, or with SPARK_HOME if none matches.")
+	lineageURL          = flag.String("openlineage-url", "", "Base URL of an OpenLineage backend, e.g., Marquez, to which run events of SparkApplications are emitted. Applications are also configured to run the OpenLineage Spark listener reporting to it. Lineage emission is disabled if unset.")
+	lineageNamespace    = flag.String("openlineage-namespace", "", "OpenLineage namespace of the jobs of SparkApplications. Defaults to the Kubernetes namespace of each application.")
+	lineageListenerJar  = flag.String("openlineage-listener-jar", "", "Location of the OpenLineage Spark listener jar added to the dependencies of SparkApplications. The jar is expected to be in the Spark image if unset.")
+	datahubURL          = flag.String("datahub-url", "", "Base URL of the DataHub metadata service (GMS) to which the owner, inputs and outputs, status, and duration of terminated SparkApplications are pushed. The token in the DATAHUB_GMS_TOKEN environment variable is used if set. Pushing metadata is disabled if unset.")
+	enableIngestJobs    = flag.Bool("enable-ingest-jobs", true, "Whether to run the controller expanding IngestJobs into SparkApplications. Requires the IngestJob CRD.")
+	enableThriftServers = flag.Bool("enable-thrift-servers", true, "Whether to run the controller running SparkThriftServers as SparkApplications exposed through Services. Requires the SparkThriftServer CRD.")
+	datahubCluster      = flag.String("datahub-cluster", "prod", "DataHub cluster, i.e., environment, of the jobs and datasets of SparkApplications.")
+	notificationConfig  = flag.String("notification-config", "", "Path to a YAML file configuring the Slack, PagerDuty, and email sinks notified of failed SparkApplications and SLA breaches, and the routes of notifications to them. Notifications are disabled if unset.")
+	operatorConfigName  = flag.String("operator-config-name", "", "Name of a cluster-scoped SparkOperatorConfiguration overriding the default Spark configuration, webhook, queueing, and metrics flags. Changes are applied without a restart except for metrics settings and enabling queueing. Requires the OperatorConfiguration feature gate. Disabled if unset.")
+	submitter           = flag.String("submitter", sparkapplication.SparkSubmitSubmitterName, "Default submitter of SparkApplications: spark-submit, native to create driver pods directly, remote to hand submissions to the submission service at -remote-submitter-url, or dry-run to only render the spark-submit command.")
+	remoteSubmitterURL  = flag.String("remote-submitter-url", "", "URL of a submission service SparkApplications using the remote submitter are posted to. The remote submitter is disabled if unset.")
+	submissionTmpDir    = flag.String("submission-tmp-dir", "", "Directory in which a temporary directory is created for each run of spark-submit and removed afterwards. spark-submit uses the temporary directory of the operator if unset.")
+	submissionTimeout   = flag.Duration("submission-timeout", 0, "How long spark-submit may run before it is killed and the submission fails. No timeout if not positive.")
+	submissionCgroup    = flag.String("submission-cgroup", "", "A cgroup v2 directory delegated to the operator, e.g., /sys/fs/cgroup/spark-submit, in which a cgroup limited by -submission-memory-limit and -submission-cpu-limit is created for each run of spark-submit. Runs are not put in cgroups if unset.")
+	submissionMemory    = flag.String("submission-memory-limit", "", "Memory limit of the cgroups of spark-submit, e.g., 1Gi. Requires -submission-cgroup.")
+	submissionCPU       = flag.String("submission-cpu-limit", "", "CPU limit of the cgroups of spark-submit, e.g., 500m. Requires -submission-cgroup.")
+	inputEndpoint       = flag.String("input-endpoint", "", "Endpoint of S3-compatible storage the inputs of SparkApplications that set autoTuning are listed from. Requires the AutoTuning feature gate.")
+	inputRegion         = flag.String("input-region", "", "Region of the S3 buckets the inputs of SparkApplications that set autoTuning are listed from. Requires the AutoTuning feature gate.")
+	dataCacheImage      = flag.String("data-cache-image", "", "Image of the cache daemons and warmup Jobs of SparkApplications whose dataCache sets no image. Requires the DataCache feature gate.")
+	idempotencyWindow   = flag.Duration("idempotency-window", time.Hour, "How long a SparkApplication that succeeded keeps new SparkApplications with the same idempotencyKey in its namespace from running.")
+	fipsMode            = flag.Bool("fips-mode", false, "Whether to restrict the webhook server to TLS 1.2 or later with FIPS-approved cipher suites, and fail the submission of SparkApplications configuring cryptography that is not FIPS-approved. Requires an operator built with BoringCrypto.")
 )
 
 func main() {
+	if len(os.Args) > 1 && (os.Args[1] == "install" || os.Args[1] == "upgrade") {
+		if err := runInstaller(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigration(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "policies" {
+		if err := runPolicies(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "node-agent" {
+		if err := runNodeAgent(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsLabels util.ArrayFlags
 	flag.Var(&metricsLabels, "metrics-labels", "Labels for the metrics")
+	var queueWeights util.ArrayFlags
+	flag.Var(&queueWeights, "queue-weights", "Weights of scheduling queues in the form of queue=weight. Queues default to a weight of 1.")
+	var maxPriorities util.ArrayFlags
+	flag.Var(&maxPriorities, "max-priorities", "Maximum priorities of SparkApplications in the form of namespace=priority, where the namespace * applies to namespaces not listed. Applications in namespaces without a maximum priority get a priority of at most 0.")
+	var dashboardLabels util.ArrayFlags
+	flag.Var(&dashboardLabels, "dashboard-resource-labels", "Labels in the form of key=value added to generated GrafanaDashboards and PrometheusRules.")
+	var allowedProxyUsers util.ArrayFlags
+	var submissionEnvAllowlist util.ArrayFlags
+	flag.Var(&submissionEnvAllowlist, "submission-env-allowlist", "Environment variables of the operator passed on to spark-submit besides PATH, HOME, JAVA_HOME, SPARK_HOME, and the address of the API server. A trailing * matches a prefix. May be repeated. spark-submit gets the whole environment of the operator if unset.")
+	flag.Var(&allowedProxyUsers, "allowed-proxy-users", "Users SparkApplications may set as their proxyUser, or * for any user. May be repeated.")
+	var allowedDataCacheImages util.ArrayFlags
+	flag.Var(&allowedDataCacheImages, "allowed-data-cache-images", "Images besides -data-cache-image the dataCache of SparkApplications may set. The cache daemons run privileged, so SparkApplications setting other images fail. May be repeated.")
+	var propagatedLabels util.ArrayFlags
+	flag.Var(&propagatedLabels, "propagated-labels", "Keys of the labels of SparkApplications copied to their pods, Services, ConfigMaps, and PersistentVolumeClaims. May be repeated.")
+	var propagatedAnnotations util.ArrayFlags
+	flag.Var(&propagatedAnnotations, "propagated-annotations", "Keys of the annotations of SparkApplications copied to their pods, Services, ConfigMaps, and PersistentVolumeClaims. May be repeated.")
+	flag.Var(features.DefaultGate, "feature-gates", "Comma-separated list of <feature>=<bool> pairs enabling or disabling features. Known features are:\n"+
+		strings.Join(features.DefaultGate.KnownFeatures(), "\n"))
 	flag.Parse()
 
+	if *fipsMode {
+		if err := fips.Enable(); err != nil {
+			glog.Fatal(err)
+		}
+		glog.Info("Running in FIPS mode")
+	}
+
+	if *operatorConfigName != "" && !features.Enabled(features.OperatorConfiguration) {
+		glog.Fatalf("-operator-config-name requires the %s feature gate", features.OperatorConfiguration)
+	}
+	if *dataCacheImage != "" && !features.Enabled(features.DataCache) {
+		glog.Fatalf("-data-cache-image requires the %s feature gate", features.DataCache)
+	}
+	if (*inputEndpoint != "" || *inputRegion != "") && !features.Enabled(features.AutoTuning) {
+		glog.Fatalf("-input-endpoint and -input-region require the %s feature gate", features.AutoTuning)
+	}
+	if *impersonate && !*enableWebhook {
+		glog.Fatal("-enable-impersonation requires -enable-webhook")
+	}
+
+	if *enableDashboards {
+		if !*enableMetrics {
+			glog.Fatal("-enable-dashboards requires -enable-metrics")
+		}
+		// Generated dashboards and alert rules select the metrics of a class in a namespace by the class label
+		// and the namespace.
+		if !containsString(metricsLabels, *dashboardClassLabel) {
+			metricsLabels = append(metricsLabels, *dashboardClassLabel)
+		}
+		if !containsString(metricsLabels, operatorConfig.SparkAppNamespaceMetricLabel) {
+			metricsLabels = append(metricsLabels, operatorConfig.SparkAppNamespaceMetricLabel)
+		}
+	}
+
 	// Create the client config. Use kubeConfig if given, otherwise assume in-cluster.
 	config, err := buildConfig(*master, *kubeConfig)
 	if err != nil {
@@ -84,54 +251,266 @@ func main() {
 		glog.Fatal(err)
 	}
 
+	crClient, err := crclientset.NewForConfig(config)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	apiExtensionsClient, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	if *installCRDs {
+		err = crd.CreateOrUpdateCRD(apiExtensionsClient, sacrd.GetCRD())
+		if err != nil {
+			glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", sacrd.FullName, err)
+		}
+
+		err = crd.CreateOrUpdateCRD(apiExtensionsClient, ssacrd.GetCRD())
+		if err != nil {
+			glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", ssacrd.FullName, err)
+		}
+
+		if *enableIngestJobs {
+			err = crd.CreateOrUpdateCRD(apiExtensionsClient, ijcrd.GetCRD())
+			if err != nil {
+				glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", ijcrd.FullName, err)
+			}
+		}
+
+		if features.Enabled(features.RunHistory) {
+			err = crd.CreateOrUpdateCRD(apiExtensionsClient, sarcrd.GetCRD())
+			if err != nil {
+				glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", sarcrd.FullName, err)
+			}
+		}
+
+		if features.Enabled(features.HadoopClusters) {
+			err = crd.CreateOrUpdateCRD(apiExtensionsClient, hccrd.GetCRD())
+			if err != nil {
+				glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", hccrd.FullName, err)
+			}
+		}
+
+		if *enableThriftServers {
+			err = crd.CreateOrUpdateCRD(apiExtensionsClient, stscrd.GetCRD())
+			if err != nil {
+				glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", stscrd.FullName, err)
+			}
+		}
+
+		if *operatorConfigName != "" {
+			err = crd.CreateOrUpdateCRD(apiExtensionsClient, socrd.GetCRD())
+			if err != nil {
+				glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", socrd.FullName, err)
+			}
+		}
+	}
+
+	if *migrateOnStartup {
+		summary := migration.New(crClient, apiExtensionsClient, *namespace, false).Run()
+		var out bytes.Buffer
+		summary.Print(&out)
+		glog.Infof("Migrated custom resources:\n%s", out.String())
+		if failed := summary.Failed(); failed > 0 {
+			glog.Errorf("failed to migrate %d objects, run the migrate subcommand to retry", failed)
+		}
+	}
+
+	weights, err := scheduler.ParseQueueWeights(queueWeights)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	priorities, err := scheduler.ParseMaxPriorities(maxPriorities)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	// The flags are overridden by the operator configuration, if any.
+	flagSettings := sparkoperatorconfiguration.Settings{
+		DefaultSeccompProfile:  *seccompProfile,
+		DefaultAppArmorProfile: *appArmorProfile,
+		EnforceLinuxNodes:      *enforceLinuxNodes,
+		MaxRunningApplications: *maxRunningApps,
+		QueueWeights:           weights,
+		MaxPriorities:          priorities,
+		MetricsPrefix:          *metricsPrefix,
+		MetricsLabels:          metricsLabels,
+		AllowedProxyUsers:      allowedProxyUsers,
+		ProxyUserSuperuser:     *proxyUserSuperuser,
+		PropagatedLabels:       propagatedLabels,
+		PropagatedAnnotations:  propagatedAnnotations,
+	}
+	settings := flagSettings
+	if *operatorConfigName != "" {
+		if settings, err = sparkoperatorconfiguration.Load(crClient, *operatorConfigName, flagSettings); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
 	var metricConfig *util.MetricConfig
 	if *enableMetrics {
 		metricConfig = &util.MetricConfig{
 			MetricsEndpoint: *metricsEndpoint,
 			MetricsPort:     *metricsPort,
-			MetricsPrefix:   *metricsPrefix,
-			MetricsLabels:   metricsLabels,
+			MetricsPrefix:   settings.MetricsPrefix,
+			MetricsLabels:   settings.MetricsLabels,
 		}
 
 		glog.Info("Enabling metrics collecting and exporting to Prometheus")
 		util.InitializeMetrics(metricConfig)
+		features.DefaultGate.RegisterMetrics(metricConfig.MetricsPrefix)
 	}
 
 	glog.Info("Starting the Spark Operator")
 
 	stopCh := make(chan struct{})
 
-	crClient, err := crclientset.NewForConfig(config)
+	crInformerFactory := buildCustomResourceInformerFactory(crClient)
+	podInformerFactory := buildPodInformerFactory(kubeClient)
+	var appScheduler *scheduler.FairShareScheduler
+	if settings.MaxRunningApplications > 0 {
+		appScheduler = scheduler.NewFairShareScheduler(
+			crInformerFactory.Sparkoperator().V1beta1().SparkApplications().Lister(), settings.MaxRunningApplications,
+			settings.QueueWeights, settings.MaxPriorities, metricConfig)
+	}
+	var appArchiver *archive.Archiver
+	if *archiveBucket != "" {
+		bucket, prefix, err := archive.OpenBucket(context.Background(), *archiveBucket, *archiveEndpoint, *archiveRegion)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		appArchiver = archive.NewArchiver(kubeClient, bucket, prefix, *archiveLogLines)
+	}
+	var distributions []sparkapplication.SparkDistribution
+	if *sparkDistributions != "" {
+		if distributions, err = sparkapplication.LoadSparkDistributions(*sparkDistributions); err != nil {
+			glog.Fatal(err)
+		}
+	}
+	var lineageClient *lineage.Client
+	if *lineageURL != "" {
+		lineageClient = lineage.NewClient(*lineageURL, *lineageNamespace, *lineageListenerJar)
+	}
+	var catalogClient *datahub.Client
+	if *datahubURL != "" {
+		catalogClient = datahub.NewClient(*datahubURL, *datahubCluster, os.Getenv("DATAHUB_GMS_TOKEN"))
+	}
+	var notifier *notification.Notifier
+	if *notificationConfig != "" {
+		config, err := notification.LoadConfig(*notificationConfig)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		if notifier, err = notification.NewNotifier(config); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	applicationController := sparkapplication.NewController(
+		crClient, kubeClient, crInformerFactory, podInformerFactory, metricConfig, *namespace, *ingressUrlFormat, *enableIstioMode,
+		*impersonate, appScheduler, appArchiver, *progressInterval, distributions, lineageClient,
+		catalogClient)
+	applicationController.SetDefaultSparkConf(settings.DefaultSparkConf)
+	applicationController.SetProxyUserSettings(settings.AllowedProxyUsers, settings.ProxyUserSuperuser)
+	applicationController.SetPropagatedMetadata(settings.PropagatedLabels, settings.PropagatedAnnotations)
+	if notifier != nil {
+		applicationController.SetNotifier(notifier)
+	}
+	if *remoteSubmitterURL != "" {
+		applicationController.RegisterSubmitter(sparkapplication.RemoteSubmitterName,
+			sparkapplication.NewRemoteSubmitter(*remoteSubmitterURL))
+	}
+	sandbox, err := buildSubmissionSandbox(submissionEnvAllowlist)
 	if err != nil {
 		glog.Fatal(err)
 	}
-	apiExtensionsClient, err := apiextensionsclient.NewForConfig(config)
-	if err != nil {
+	applicationController.RegisterSubmitter(sparkapplication.SparkSubmitSubmitterName,
+		sparkapplication.NewSparkSubmitter(sandbox))
+	applicationController.SetSubmissionWorkers(*submissionWorkers)
+	applicationController.SetDataCacheImage(*dataCacheImage, allowedDataCacheImages)
+	applicationController.SetInputStorage(*inputEndpoint, *inputRegion)
+	applicationController.SetIdempotencyWindow(*idempotencyWindow)
+	if err = applicationController.SetDefaultSubmitter(*submitter); err != nil {
 		glog.Fatal(err)
 	}
+	var configInformerFactory informers.SharedInformerFactory
+	if features.Enabled(features.ConfigChangeDetection) {
+		configInformerFactory = buildConfigInformerFactory(kubeClient)
+		applicationController.WatchConfigChanges(configInformerFactory)
+	}
+	scheduledApplicationController := scheduledsparkapplication.NewController(
+		crClient, kubeClient, apiExtensionsClient, crInformerFactory, clock.RealClock{})
+	var ingestJobController *ingestjob.Controller
+	if *enableIngestJobs {
+		ingestJobController = ingestjob.NewController(crClient, kubeClient, crInformerFactory)
+	}
+	var thriftServerController *sparkthriftserver.Controller
+	if *enableThriftServers {
+		thriftServerController = sparkthriftserver.NewController(crClient, kubeClient, crInformerFactory)
+	}
 
-	if *installCRDs {
-		err = crd.CreateOrUpdateCRD(apiExtensionsClient, sacrd.GetCRD())
+	var namespaceController *sparknamespace.Controller
+	var namespaceInformerFactory informers.SharedInformerFactory
+	if *enableNsBootstrap {
+		namespaceInformerFactory = buildNamespaceInformerFactory(kubeClient)
+		namespaceController = sparknamespace.NewController(kubeClient, namespaceInformerFactory, sparknamespace.Config{
+			ServiceAccountName:   *bootstrapSA,
+			DefaultCPURequest:    *bootstrapCPU,
+			DefaultMemoryRequest: *bootstrapMemory,
+		})
+	}
+
+	var dashboardController *sparkdashboard.Controller
+	if *enableDashboards {
+		dashboardController, err = buildDashboardController(config, crInformerFactory, dashboardLabels)
 		if err != nil {
-			glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", sacrd.FullName, err)
+			glog.Fatal(err)
 		}
+	}
 
-		err = crd.CreateOrUpdateCRD(apiExtensionsClient, ssacrd.GetCRD())
-		if err != nil {
-			glog.Fatalf("failed to create or update CustomResourceDefinition %s: %v", ssacrd.FullName, err)
+	var uiServer *ui.Server
+	if *enableUI {
+		uiConfig := ui.Config{
+			Address:          *uiAddress,
+			Port:             *uiPort,
+			UserHeader:       *uiUserHeader,
+			GroupsHeader:     *uiGroupsHeader,
+			HistoryServerURL: *historyServerURL,
+		}
+		if *uiProxySecretFile != "" {
+			content, err := ioutil.ReadFile(*uiProxySecretFile)
+			if err != nil {
+				glog.Fatal(err)
+			}
+			uiConfig.ProxySecret = strings.TrimSpace(string(content))
+		}
+		if err := uiConfig.Validate(); err != nil {
+			glog.Fatal(err)
 		}
+		uiServer = ui.NewServer(crClient, kubeClient, crInformerFactory, uiConfig)
 	}
 
-	crInformerFactory := buildCustomResourceInformerFactory(crClient)
-	podInformerFactory := buildPodInformerFactory(kubeClient)
-	applicationController := sparkapplication.NewController(
-		crClient, kubeClient, crInformerFactory, podInformerFactory, metricConfig, *namespace, *ingressUrlFormat)
-	scheduledApplicationController := scheduledsparkapplication.NewController(
-		crClient, kubeClient, apiExtensionsClient, crInformerFactory, clock.RealClock{})
+	var livyServer *livy.Server
+	if *enableLivy {
+		livyServer = livy.NewServer(crClient, kubeClient, crInformerFactory, livy.Config{
+			Address:        *livyAddress,
+			Port:           *livyPort,
+			Namespace:      *livyNamespace,
+			Image:          *livyImage,
+			SparkVersion:   *livySparkVersion,
+			ServiceAccount: *livyServiceAccount,
+		})
+	}
 
 	// Start the informer factory that in turn starts the informer.
 	go crInformerFactory.Start(stopCh)
 	go podInformerFactory.Start(stopCh)
+	if configInformerFactory != nil {
+		go configInformerFactory.Start(stopCh)
+	}
+	if *enableNsBootstrap {
+		go namespaceInformerFactory.Start(stopCh)
+	}
 
 	if err = applicationController.Start(*controllerThreads, stopCh); err != nil {
 		glog.Fatal(err)
@@ -139,20 +518,77 @@ func main() {
 	if err = scheduledApplicationController.Start(*controllerThreads, stopCh); err != nil {
 		glog.Fatal(err)
 	}
+	if *enableIngestJobs {
+		if err = ingestJobController.Start(*controllerThreads, stopCh); err != nil {
+			glog.Fatal(err)
+		}
+	}
+	if *enableThriftServers {
+		if err = thriftServerController.Start(*controllerThreads, stopCh); err != nil {
+			glog.Fatal(err)
+		}
+	}
+	if *enableNsBootstrap {
+		if err = namespaceController.Start(1, stopCh); err != nil {
+			glog.Fatal(err)
+		}
+	}
+	if *enableDashboards {
+		if err = dashboardController.Start(1, stopCh); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	if *enableUI {
+		uiServer.Start()
+	}
+	if *enableLivy {
+		livyServer.Start()
+	}
 
 	var hook *webhook.WebHook
 	if *enableWebhook {
 		var err error
-		hook, err = webhook.New(kubeClient, crInformerFactory, *webhookCertDir, *webhookSvcNamespace, *webhookSvcName, *webhookPort, *namespace)
+		hook, err = webhook.New(kubeClient, crInformerFactory, *webhookCertDir, *webhookSvcNamespace, *webhookSvcName, *webhookPort, *namespace, *enableIstioMode,
+			settings.DefaultSeccompProfile, settings.DefaultAppArmorProfile, settings.EnforceLinuxNodes, *defaultEnvConfigMap)
 		if err != nil {
 			glog.Fatal(err)
 		}
+		policy, err := webhook.ParseNamespaceDeletionPolicy(*nsDeletionPolicy)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		hook.SetNamespaceDeletionPolicy(policy, crClient)
+		hook.SetRequireSubmitter(*impersonate)
+		hook.SetPropagatedMetadata(settings.PropagatedLabels, settings.PropagatedAnnotations)
 
 		if err = hook.Start(*webhookConfigName); err != nil {
 			glog.Fatal(err)
 		}
 	}
 
+	var configController *sparkoperatorconfiguration.Controller
+	if *operatorConfigName != "" {
+		configController = sparkoperatorconfiguration.NewController(crClient, *operatorConfigName, flagSettings, settings,
+			func(settings sparkoperatorconfiguration.Settings) {
+				applicationController.SetDefaultSparkConf(settings.DefaultSparkConf)
+				applicationController.SetProxyUserSettings(settings.AllowedProxyUsers, settings.ProxyUserSuperuser)
+				applicationController.SetPropagatedMetadata(settings.PropagatedLabels, settings.PropagatedAnnotations)
+				if appScheduler != nil {
+					appScheduler.SetLimits(settings.MaxRunningApplications, settings.QueueWeights,
+						settings.MaxPriorities)
+				}
+				if hook != nil {
+					hook.SetPodSecurityDefaults(settings.DefaultSeccompProfile, settings.DefaultAppArmorProfile,
+						settings.EnforceLinuxNodes)
+					hook.SetPropagatedMetadata(settings.PropagatedLabels, settings.PropagatedAnnotations)
+				}
+			})
+		if err = configController.Start(stopCh); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
 	<-signalCh
@@ -162,6 +598,31 @@ func main() {
 	glog.Info("Shutting down the Spark Operator")
 	applicationController.Stop()
 	scheduledApplicationController.Stop()
+	if *enableIngestJobs {
+		ingestJobController.Stop()
+	}
+	if *enableThriftServers {
+		thriftServerController.Stop()
+	}
+	if *enableNsBootstrap {
+		namespaceController.Stop()
+	}
+	if *operatorConfigName != "" {
+		configController.Stop()
+	}
+	if *enableDashboards {
+		dashboardController.Stop()
+	}
+	if *enableUI {
+		if err := uiServer.Stop(); err != nil {
+			glog.Error(err)
+		}
+	}
+	if *enableLivy {
+		if err := livyServer.Stop(); err != nil {
+			glog.Error(err)
+		}
+	}
 	if *enableWebhook {
 		if err := hook.Stop(*webhookConfigName); err != nil {
 			glog.Fatal(err)
@@ -199,3 +660,94 @@ func buildPodInformerFactory(kubeClient clientset.Interface) informers.SharedInf
 	podFactoryOpts = append(podFactoryOpts, informers.WithTweakListOptions(tweakListOptionsFunc))
 	return informers.NewSharedInformerFactoryWithOptions(kubeClient, time.Duration(*resyncInterval)*time.Second, podFactoryOpts...)
 }
+
+// buildConfigInformerFactory builds the informer factory of the ConfigMaps and Secrets SparkApplications use.
+func buildConfigInformerFactory(kubeClient clientset.Interface) informers.SharedInformerFactory {
+	var factoryOpts []informers.SharedInformerOption
+	if *namespace != apiv1.NamespaceAll {
+		factoryOpts = append(factoryOpts, informers.WithNamespace(*namespace))
+	}
+	return informers.NewSharedInformerFactoryWithOptions(kubeClient, time.Duration(*resyncInterval)*time.Second, factoryOpts...)
+}
+
+func buildNamespaceInformerFactory(kubeClient clientset.Interface) informers.SharedInformerFactory {
+	tweakListOptionsFunc := func(options *metav1.ListOptions) {
+		options.LabelSelector = fmt.Sprintf("%s=true", operatorConfig.SparkEnabledNamespaceLabel)
+	}
+	return informers.NewSharedInformerFactoryWithOptions(kubeClient, time.Duration(*resyncInterval)*time.Second,
+		informers.WithTweakListOptions(tweakListOptionsFunc))
+}
+
+func buildDashboardController(
+	config *rest.Config,
+	crInformerFactory crinformers.SharedInformerFactory,
+	resourceLabels []string) (*sparkdashboard.Controller, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	controllerConfig := sparkdashboard.Config{
+		ClassLabel:     *dashboardClassLabel,
+		MetricsPrefix:  *metricsPrefix,
+		ResourceLabels: make(map[string]string),
+	}
+	for _, label := range resourceLabels {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid dashboard resource label %q, expected key=value", label)
+		}
+		controllerConfig.ResourceLabels[parts[0]] = parts[1]
+	}
+	if *dashboardTemplate != "" {
+		content, err := ioutil.ReadFile(*dashboardTemplate)
+		if err != nil {
+			return nil, err
+		}
+		controllerConfig.DashboardTemplate = string(content)
+	}
+	if *alertRulesTemplate != "" {
+		content, err := ioutil.ReadFile(*alertRulesTemplate)
+		if err != nil {
+			return nil, err
+		}
+		controllerConfig.AlertRulesTemplate = string(content)
+	}
+	return sparkdashboard.NewController(dynamicClient, crInformerFactory, controllerConfig)
+}
+
+func buildSubmissionSandbox(envAllowlist []string) (*sparkapplication.SubmissionSandbox, error) {
+	sandbox := &sparkapplication.SubmissionSandbox{
+		EnvAllowlist: envAllowlist,
+		TmpDir:       *submissionTmpDir,
+		Timeout:      *submissionTimeout,
+		CgroupParent: *submissionCgroup,
+	}
+	if (*submissionMemory != "" || *submissionCPU != "") && sandbox.CgroupParent == "" {
+		return nil, fmt.Errorf("-submission-memory-limit and -submission-cpu-limit require -submission-cgroup")
+	}
+	if *submissionMemory != "" {
+		memory, err := resource.ParseQuantity(*submissionMemory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -submission-memory-limit %q: %v", *submissionMemory, err)
+		}
+		sandbox.MemoryLimit = memory.Value()
+	}
+	if *submissionCPU != "" {
+		cpu, err := resource.ParseQuantity(*submissionCPU)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -submission-cpu-limit %q: %v", *submissionCPU, err)
+		}
+		sandbox.CPULimit = cpu.MilliValue()
+	}
+	return sandbox, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+
+	crclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/migration"
+)
+
+// runMigration runs the migrate subcommand with the given command-line arguments, which rewrites the custom
+// resources created by earlier versions of the operator in the latest schema and prints a summary.
+func runMigration(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	master := flags.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig.")
+	kubeConfig := flags.String("kubeConfig", "", "Path to a kube config. Defaults to $KUBECONFIG or ~/.kube/config.")
+	namespace := flags.String("namespace", "", "The namespace whose objects are migrated. All namespaces if unset, which is required to record that the CustomResourceDefinitions no longer store objects in old API versions.")
+	dryRun := flags.Bool("dry-run", false, "Whether to only report what would be migrated without writing any object.")
+	flags.Parse(args)
+
+	restConfig, err := buildInstallerConfig(*master, *kubeConfig)
+	if err != nil {
+		return err
+	}
+	crClient, err := crclientset.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	apiExtensionsClient, err := apiextensionsclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	summary := migration.New(crClient, apiExtensionsClient, *namespace, *dryRun).Run()
+	summary.Print(os.Stdout)
+	if failed := summary.Failed(); failed > 0 {
+		return fmt.Errorf("failed to migrate %d objects", failed)
+	}
+	return nil
+}
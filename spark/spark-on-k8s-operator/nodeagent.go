@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/nodeagent"
+)
+
+// runNodeAgent runs the node-agent subcommand with the given command-line arguments, which records the resource
+// pressure of the Spark executors on the node it runs on until it is terminated.
+func runNodeAgent(args []string) error {
+	flags := flag.NewFlagSet("node-agent", flag.ExitOnError)
+	master := flags.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeConfig := flags.String("kubeConfig", "", "Path to a kube config. Only required if out-of-cluster.")
+	nodeName := flags.String("node-name", os.Getenv("NODE_NAME"), "The name of the node the agent runs on. Defaults to $NODE_NAME.")
+	cgroupRoot := flags.String("cgroup-root", "/sys/fs/cgroup", "The path the cgroup v2 hierarchy of the node is mounted at.")
+	interval := flags.Duration("interval", time.Minute, "The interval at which the resource pressure of executors is recorded. Every sample updates the status of the application of the executor.")
+	// The glog flags, e.g., -logtostderr and -v, are registered with the default flag set.
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Parse(args)
+
+	restConfig, err := buildConfig(*master, *kubeConfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := clientset.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	agent, err := nodeagent.New(kubeClient, *nodeName, *cgroupRoot, *interval)
+	if err != nil {
+		return err
+	}
+
+	stopCh := make(chan struct{})
+	go agent.Run(stopCh)
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
+	<-signalCh
+	glog.Info("Shutting down the node agent")
+	close(stopCh)
+	return nil
+}
@@ -46,6 +46,16 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SparkApplicationList{},
 		&ScheduledSparkApplication{},
 		&ScheduledSparkApplicationList{},
+		&IngestJob{},
+		&IngestJobList{},
+		&SparkThriftServer{},
+		&SparkThriftServerList{},
+		&SparkApplicationRun{},
+		&SparkApplicationRunList{},
+		&SparkOperatorConfiguration{},
+		&SparkOperatorConfigurationList{},
+		&HadoopCluster{},
+		&HadoopClusterList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -56,6 +56,34 @@ type RestartPolicy struct {
 	// Interval to wait between successive retries of a failed application.
 	OnSubmissionFailureRetryInterval *int64 `json:"onSubmissionFailureRetryInterval,omitempty"`
 	OnFailureRetryInterval           *int64 `json:"onFailureRetryInterval,omitempty"`
+
+	// RetryableErrors are regular expressions matched against the error message of a failed run or submission,
+	// e.g., the termination reason of the driver or the output of spark-submit. If set, the OnFailure policy
+	// only retries failures whose error message matches any of them, so that transient infrastructure errors
+	// are retried but deterministic application errors are not.
+	// Optional.
+	RetryableErrors []string `json:"retryableErrors,omitempty"`
+
+	// MemoryBump, if set, increases the memory of the driver or executors on the next attempt if their pods ran
+	// out of memory in a failed run. Runs that failed after pods ran out of memory are then retried regardless of
+	// RetryableErrors.
+	// Optional.
+	MemoryBump *MemoryBumpPolicy `json:"memoryBump,omitempty"`
+}
+
+// MemoryBumpPolicy describes how the memory of the driver or executors is increased when a run that failed after
+// their pods ran out of memory is retried.
+type MemoryBumpPolicy struct {
+	// Factor is the factor the memory and memory overhead of the driver or executors are multiplied by on each
+	// retry, e.g., "1.5".
+	// Optional. Defaults to "1.5".
+	Factor *string `json:"factor,omitempty"`
+	// MaxDriverMemory is the upper bound of the increased memory of the driver, e.g., "8g".
+	// Optional. Defaults to four times the memory of the driver.
+	MaxDriverMemory *string `json:"maxDriverMemory,omitempty"`
+	// MaxExecutorMemory is the upper bound of the increased memory of the executors, e.g., "16g".
+	// Optional. Defaults to four times the memory of the executors.
+	MaxExecutorMemory *string `json:"maxExecutorMemory,omitempty"`
 }
 
 type RestartPolicyType string
@@ -67,7 +95,6 @@ const (
 )
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:defaulter-gen=true
 
@@ -144,9 +171,532 @@ type ScheduledSparkApplicationList struct {
 	Items           []ScheduledSparkApplication `json:"items,omitempty"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+
+// IngestJob is a common ingestion pipeline, e.g., copying a database table into Parquet files, which the
+// operator runs as a SparkApplication generated from a built-in template.
+type IngestJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              IngestJobSpec   `json:"spec"`
+	Status            IngestJobStatus `json:"status,omitempty"`
+}
+
+// IngestTemplate is the name of a built-in template of IngestJobs.
+type IngestTemplate string
+
+// Built-in templates of IngestJobs.
+const (
+	// JDBCToParquetTemplate copies a table of a database into Parquet files, replacing any files written before.
+	JDBCToParquetTemplate IngestTemplate = "JDBCToParquet"
+	// KafkaToDeltaTemplate appends the records of a Kafka topic to a Delta table using Structured Streaming.
+	KafkaToDeltaTemplate IngestTemplate = "KafkaToDelta"
+)
+
+// IngestJobSpec describes the source and sink of an IngestJob, and the parts of the SparkApplication
+// running it that are not determined by the template.
+type IngestJobSpec struct {
+	// Template is the built-in template the SparkApplication of the job is generated from.
+	Template IngestTemplate `json:"template"`
+	// JDBC is the source of jobs using the JDBCToParquet template.
+	// Optional.
+	JDBC *JDBCSource `json:"jdbc,omitempty"`
+	// Kafka is the source of jobs using the KafkaToDelta template.
+	// Optional.
+	Kafka *KafkaSource `json:"kafka,omitempty"`
+	// OutputPath is the location of the Parquet files or the Delta table the job writes.
+	OutputPath string `json:"outputPath"`
+	// Image is the container image of the driver and executors, which must include PySpark.
+	// Optional.
+	Image *string `json:"image,omitempty"`
+	// SparkVersion is the version of Spark the job uses.
+	SparkVersion string `json:"sparkVersion"`
+	// Deps are dependencies of the job not in the image, e.g., the JDBC driver, or the Kafka and Delta
+	// connectors.
+	// Optional.
+	Deps Dependencies `json:"deps,omitempty"`
+	// SparkConf carries additional Spark configuration properties of the job.
+	// Optional.
+	SparkConf map[string]string `json:"sparkConf,omitempty"`
+	// HadoopConf carries Hadoop configuration properties of the job, e.g., the credentials of the location
+	// of the output.
+	// Optional.
+	HadoopConf map[string]string `json:"hadoopConf,omitempty"`
+	// Driver is the driver specification.
+	Driver DriverSpec `json:"driver"`
+	// Executor is the executor specification.
+	Executor ExecutorSpec `json:"executor"`
+	// RestartPolicy defines the policy on if and in which conditions the job is restarted.
+	// Optional.
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+}
+
+// JDBCSource is a table of a database read through JDBC.
+type JDBCSource struct {
+	// URL is the JDBC URL of the database.
+	URL string `json:"url"`
+	// Table is the table to copy, or a subquery in parentheses with an alias.
+	Table string `json:"table"`
+	// CredentialsSecret is the name of a Secret with the keys "username" and "password" of the database user.
+	// Optional.
+	CredentialsSecret *string `json:"credentialsSecret,omitempty"`
+	// PartitionColumn is a numeric, date, or timestamp column by which the table is read in parallel, in
+	// NumPartitions ranges between LowerBound and UpperBound. All four are required if any is set.
+	// Optional.
+	PartitionColumn *string `json:"partitionColumn,omitempty"`
+	LowerBound      *string `json:"lowerBound,omitempty"`
+	UpperBound      *string `json:"upperBound,omitempty"`
+	NumPartitions   *int32  `json:"numPartitions,omitempty"`
+}
+
+// KafkaSource is a topic of Kafka read with Structured Streaming.
+type KafkaSource struct {
+	// BootstrapServers is the comma-separated list of the host:port pairs of the Kafka brokers.
+	BootstrapServers string `json:"bootstrapServers"`
+	// Topic is the topic to read.
+	Topic string `json:"topic"`
+	// StartingOffsets is where the first run of the job starts reading the topic, either "earliest" or "latest".
+	// Optional.
+	// Defaults to "earliest".
+	StartingOffsets *string `json:"startingOffsets,omitempty"`
+	// Options are additional options of the Kafka source, e.g., "kafka.security.protocol".
+	// Optional.
+	Options map[string]string `json:"options,omitempty"`
+	// CheckpointPath is the location of the checkpoint of the stream, which tracks the offsets written.
+	// Optional.
+	// Defaults to the directory "_checkpoint" in OutputPath.
+	CheckpointPath *string `json:"checkpointPath,omitempty"`
+	// TriggerInterval is the interval of micro-batches, e.g., "1 minute", which keeps the job running. If
+	// unset, the job writes the records available when it starts and terminates.
+	// Optional.
+	TriggerInterval *string `json:"triggerInterval,omitempty"`
+}
+
+// IngestJobStatus describes the current status of an IngestJob.
+type IngestJobStatus struct {
+	// SparkApplicationName is the name of the SparkApplication generated for the job.
+	SparkApplicationName string `json:"sparkApplicationName,omitempty"`
+	// AppState is the state of the SparkApplication of the job, or FAILED with the reason if the job is
+	// invalid.
+	AppState ApplicationState `json:"applicationState,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IngestJobList carries a list of IngestJob objects.
+type IngestJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IngestJob `json:"items,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+
+// SparkThriftServer is a long-running Spark Thrift server, i.e., a Spark driver serving SQL queries over the
+// HiveServer2 protocol, which the operator runs as a SparkApplication exposed through a Service.
+type SparkThriftServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SparkThriftServerSpec   `json:"spec"`
+	Status            SparkThriftServerStatus `json:"status,omitempty"`
+}
+
+// ThriftTransportMode is the transport of the HiveServer2 protocol.
+type ThriftTransportMode string
+
+// Different transports of the HiveServer2 protocol.
+const (
+	ThriftBinaryTransport ThriftTransportMode = "binary"
+	ThriftHTTPTransport   ThriftTransportMode = "http"
+)
+
+// SparkThriftServerSpec describes how a Spark Thrift server is exposed and scaled, and the parts of the
+// SparkApplication running it that are not determined by the operator.
+type SparkThriftServerSpec struct {
+	// Image is the container image of the driver and executors, which must include PySpark and the Spark
+	// Thrift server.
+	// Optional.
+	Image *string `json:"image,omitempty"`
+	// SparkVersion is the version of Spark the server uses.
+	SparkVersion string `json:"sparkVersion"`
+	// TransportMode is the transport of the HiveServer2 protocol.
+	// Optional.
+	// Defaults to "binary".
+	TransportMode ThriftTransportMode `json:"transportMode,omitempty"`
+	// Port is the port of the HiveServer2 endpoint.
+	// Optional.
+	// Defaults to 10000 with the binary and 10001 with the http transport mode.
+	Port *int32 `json:"port,omitempty"`
+	// ServiceType is the type of the Service exposing the server.
+	// Optional.
+	// Defaults to ClusterIP.
+	ServiceType *apiv1.ServiceType `json:"serviceType,omitempty"`
+	// IngressHost is the host at which the server is exposed through an Ingress, which requires the http
+	// transport mode.
+	// Optional.
+	IngressHost *string `json:"ingressHost,omitempty"`
+	// Autoscaling scales the executors of the server with the load of queries.
+	// Optional.
+	Autoscaling *ThriftServerAutoscaling `json:"autoscaling,omitempty"`
+	// Authentication configures how clients of the server authenticate.
+	// Optional.
+	Authentication *ThriftServerAuthentication `json:"authentication,omitempty"`
+	// Deps are dependencies of the server not in the image.
+	// Optional.
+	Deps Dependencies `json:"deps,omitempty"`
+	// SparkConf carries additional Spark configuration properties of the server.
+	// Optional.
+	SparkConf map[string]string `json:"sparkConf,omitempty"`
+	// HadoopConf carries Hadoop configuration properties of the server, including the configuration of
+	// HiveServer2 and the Hive metastore.
+	// Optional.
+	HadoopConf map[string]string `json:"hadoopConf,omitempty"`
+	// Driver is the driver specification.
+	Driver DriverSpec `json:"driver"`
+	// Executor is the executor specification.
+	Executor ExecutorSpec `json:"executor"`
+}
+
+// ThriftServerAutoscaling scales the executors of a Spark Thrift server with Spark dynamic allocation, which
+// adds executors while tasks of queries are pending and removes idle ones.
+type ThriftServerAutoscaling struct {
+	// MinExecutors is the number of executors kept when the server is idle.
+	// Optional.
+	// Defaults to 0.
+	MinExecutors *int32 `json:"minExecutors,omitempty"`
+	// MaxExecutors is the maximum number of executors.
+	MaxExecutors int32 `json:"maxExecutors"`
+	// ExecutorIdleTimeout is how long an executor may be idle before it is removed, e.g., "60s".
+	// Optional.
+	ExecutorIdleTimeout *string `json:"executorIdleTimeout,omitempty"`
+}
+
+// ThriftServerAuthenticationType is a HiveServer2 authentication mechanism.
+type ThriftServerAuthenticationType string
+
+// Different authentication mechanisms of Spark Thrift servers.
+const (
+	ThriftNoAuthentication   ThriftServerAuthenticationType = "NONE"
+	ThriftLDAPAuthentication ThriftServerAuthenticationType = "LDAP"
+)
+
+// ThriftServerAuthentication configures how clients of a Spark Thrift server authenticate.
+type ThriftServerAuthentication struct {
+	// Type is the authentication mechanism.
+	Type ThriftServerAuthenticationType `json:"type"`
+	// LDAPURL is the URL of the LDAP server users are authenticated against.
+	// Optional.
+	LDAPURL *string `json:"ldapURL,omitempty"`
+	// LDAPBaseDN is the base DN of users in the LDAP directory.
+	// Optional.
+	LDAPBaseDN *string `json:"ldapBaseDN,omitempty"`
+	// ImpersonateUsers runs the queries of each session as the authenticated user instead of the user of the
+	// driver, so that the file system and the metastore apply the permissions of the user.
+	// Optional.
+	// Defaults to false.
+	ImpersonateUsers *bool `json:"impersonateUsers,omitempty"`
+}
+
+// SparkThriftServerStatus describes the current status of a SparkThriftServer.
+type SparkThriftServerStatus struct {
+	// SparkApplicationName is the name of the SparkApplication running the server.
+	SparkApplicationName string `json:"sparkApplicationName,omitempty"`
+	// AppState is the state of the SparkApplication of the server, or FAILED with the reason if the server is
+	// invalid.
+	AppState ApplicationState `json:"applicationState,omitempty"`
+	// Endpoint is the JDBC URL of the server in the cluster.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SparkThriftServerList carries a list of SparkThriftServer objects.
+type SparkThriftServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SparkThriftServer `json:"items,omitempty"`
+}
+
 // +genclient
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SparkApplicationRun is an immutable record of a run of a SparkApplication, i.e., an execution attempt or a
+// failed submission, which the operator creates when the run ends. A SparkApplicationRun is owned by its
+// SparkApplication like a Pod is by its Job.
+type SparkApplicationRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SparkApplicationRunSpec   `json:"spec"`
+	Status            SparkApplicationRunStatus `json:"status,omitempty"`
+}
+
+// SparkApplicationRunSpec describes what ran in a run of a SparkApplication.
+type SparkApplicationRunSpec struct {
+	// ApplicationName is the name of the SparkApplication of the run.
+	ApplicationName string `json:"applicationName"`
+	// ExecutionAttempt is the number of execution attempts of the application, including the run.
+	ExecutionAttempt int32 `json:"executionAttempt,omitempty"`
+	// SubmissionAttempt is the number of submission attempts of the application, including the run.
+	SubmissionAttempt int32 `json:"submissionAttempt,omitempty"`
+	// SourceRevision is the revision of the source artifact the spec of the application was loaded from, if any.
+	SourceRevision string `json:"sourceRevision,omitempty"`
+	// ApplicationSpec is the spec of the application the run was submitted with, with the defaults of the
+	// operator applied. Values referring to Secrets are kept unresolved.
+	ApplicationSpec SparkApplicationSpec `json:"applicationSpec"`
+}
+
+// SparkApplicationRunStatus describes how a run of a SparkApplication went.
+type SparkApplicationRunStatus struct {
+	// SparkApplicationID is the application ID of the run in Spark.
+	SparkApplicationID string `json:"sparkApplicationId,omitempty"`
+	// State is the state the run ended in, i.e., COMPLETED, FAILED or SUBMISSION_FAILED. Runs reported while
+	// the driver is still shutting down end in COMPLETED or FAILED.
+	State ApplicationStateType `json:"state"`
+	// ErrorMessage is the error the run failed with, if any.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// SubmittedBy is the user who submitted the run.
+	SubmittedBy string `json:"submittedBy,omitempty"`
+	// SubmissionTime is the time the run was submitted.
+	SubmissionTime metav1.Time `json:"submissionTime,omitempty"`
+	// DriverRunningTime is the time the driver of the run started running.
+	DriverRunningTime metav1.Time `json:"driverRunningTime,omitempty"`
+	// TerminationTime is the time the run ended.
+	TerminationTime metav1.Time `json:"terminationTime,omitempty"`
+	// DurationSeconds is the number of seconds from the submission to the end of the run.
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+	// ResourceUsage summarizes the resources the run used.
+	ResourceUsage RunResourceUsage `json:"resourceUsage,omitempty"`
+}
+
+// RunResourceUsage summarizes the resources a run of a SparkApplication used.
+type RunResourceUsage struct {
+	// Executors is the number of executors of the run.
+	Executors int32 `json:"executors,omitempty"`
+	// ReclaimedExecutors is the number of idle executors the operator deleted during the run.
+	ReclaimedExecutors int32 `json:"reclaimedExecutors,omitempty"`
+	// CoreSeconds is an estimate of the CPU core-seconds the run reserved, i.e., the cores requested by the
+	// driver and executors of the run times the duration of the run.
+	CoreSeconds int64 `json:"coreSeconds,omitempty"`
+	// MemoryMiBSeconds is an estimate of the memory in MiB-seconds the run reserved, i.e., the memory and memory
+	// overhead requested by the driver and executors of the run times the duration of the run.
+	MemoryMiBSeconds int64 `json:"memoryMiBSeconds,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SparkApplicationRunList carries a list of SparkApplicationRun objects.
+type SparkApplicationRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SparkApplicationRun `json:"items,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HadoopCluster describes how clients connect to an HDFS cluster. SparkApplications referring to a HadoopCluster
+// get the Hadoop configuration files for it generated by the operator.
+type HadoopCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              HadoopClusterSpec `json:"spec"`
+}
+
+// HadoopClusterSpec describes the namenodes and security settings of an HDFS cluster.
+type HadoopClusterSpec struct {
+	// NameService is the logical name of the cluster, used in hdfs://<nameService>/ URIs.
+	NameService string `json:"nameService"`
+	// NameNodes is the list of namenodes of the cluster. With more than one, clients fail over between them.
+	// +listType=map
+	// +listMapKey=name
+	NameNodes []HadoopNameNode `json:"nameNodes"`
+	// RPCProtection is the protection of the RPCs to the cluster, i.e., authentication, integrity or privacy.
+	// Optional. Defaults to authentication.
+	RPCProtection *string `json:"rpcProtection,omitempty"`
+	// Kerberos enables Kerberos authentication to the cluster.
+	// Optional.
+	Kerberos *HadoopKerberosSpec `json:"kerberos,omitempty"`
+	// CoreSite carries additional properties of core-site.xml, which take precedence over the generated ones.
+	// Optional.
+	// +mapType=granular
+	CoreSite map[string]string `json:"coreSite,omitempty"`
+	// HDFSSite carries additional properties of hdfs-site.xml, which take precedence over the generated ones.
+	// Optional.
+	// +mapType=granular
+	HDFSSite map[string]string `json:"hdfsSite,omitempty"`
+}
+
+// HadoopNameNode describes a namenode of an HDFS cluster.
+type HadoopNameNode struct {
+	// Name is the ID of the namenode in the cluster.
+	Name string `json:"name"`
+	// RPCAddress is the host:port of the RPC server of the namenode.
+	RPCAddress string `json:"rpcAddress"`
+	// HTTPAddress is the host:port of the web server of the namenode.
+	// Optional.
+	HTTPAddress *string `json:"httpAddress,omitempty"`
+}
+
+// HadoopKerberosSpec describes the Kerberos realm of an HDFS cluster.
+type HadoopKerberosSpec struct {
+	// Realm is the Kerberos realm of the cluster.
+	Realm string `json:"realm"`
+	// NameNodePrincipal is the Kerberos principal of the namenodes.
+	// Optional. Defaults to nn/_HOST@<realm>.
+	NameNodePrincipal *string `json:"nameNodePrincipal,omitempty"`
+	// KDCs is the list of host[:port] of the KDCs of the realm. If set, a krb5.conf for the realm is generated
+	// along with the Hadoop configuration files and used by the driver and executors.
+	// Optional.
+	KDCs []string `json:"kdcs,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HadoopClusterList carries a list of HadoopCluster objects.
+type HadoopClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HadoopCluster `json:"items,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SparkOperatorConfiguration is cluster-wide configuration of the operator overriding its command-line flags.
+// The operator watches the configuration with the name it is given and applies changes without a restart
+// where possible.
+type SparkOperatorConfiguration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SparkOperatorConfigurationSpec   `json:"spec"`
+	Status            SparkOperatorConfigurationStatus `json:"status,omitempty"`
+}
+
+// SparkOperatorConfigurationSpec carries the settings of the operator. Unset settings keep the values of the
+// corresponding command-line flags.
+type SparkOperatorConfigurationSpec struct {
+	// Defaults are defaults applied to SparkApplications when they are submitted.
+	// Optional.
+	Defaults *OperatorDefaults `json:"defaults,omitempty"`
+	// Webhook configures how the mutating admission webhook patches Spark pods.
+	// Optional.
+	Webhook *OperatorWebhookConfiguration `json:"webhook,omitempty"`
+	// Queueing configures the queueing of SparkApplications.
+	// Optional.
+	Queueing *OperatorQueueingConfiguration `json:"queueing,omitempty"`
+	// Metrics configures the metrics exported by the operator. Changes only take effect when the operator
+	// restarts.
+	// Optional.
+	Metrics *OperatorMetricsConfiguration `json:"metrics,omitempty"`
+	// ProxyUsers configures which users SparkApplications may run as through spark-submit --proxy-user.
+	// Optional.
+	ProxyUsers *OperatorProxyUserConfiguration `json:"proxyUsers,omitempty"`
+}
+
+// OperatorDefaults are defaults of SparkApplications.
+type OperatorDefaults struct {
+	// SparkConf carries Spark configuration properties added to applications that do not set them, after the
+	// defaults of the Spark distribution of the application.
+	// Optional.
+	SparkConf map[string]string `json:"sparkConf,omitempty"`
+	// PropagatedLabels are the keys of the labels of SparkApplications copied to the pods, Services,
+	// ConfigMaps, and PersistentVolumeClaims of them, e.g., for cost allocation.
+	// Optional.
+	PropagatedLabels []string `json:"propagatedLabels,omitempty"`
+	// PropagatedAnnotations are the keys of the annotations of SparkApplications copied to the pods, Services,
+	// ConfigMaps, and PersistentVolumeClaims of them.
+	// Optional.
+	PropagatedAnnotations []string `json:"propagatedAnnotations,omitempty"`
+}
+
+// OperatorWebhookConfiguration configures the mutating admission webhook.
+type OperatorWebhookConfiguration struct {
+	// DefaultSeccompProfile is the seccomp profile applied to Spark pods that do not set one, e.g.,
+	// "runtime/default".
+	// Optional.
+	DefaultSeccompProfile *string `json:"defaultSeccompProfile,omitempty"`
+	// DefaultAppArmorProfile is the AppArmor profile applied to Spark containers that do not set one.
+	// Optional.
+	DefaultAppArmorProfile *string `json:"defaultAppArmorProfile,omitempty"`
+	// EnforceLinuxNodes restricts Spark pods to Linux nodes and rejects SparkApplications selecting other
+	// nodes.
+	// Optional.
+	EnforceLinuxNodes *bool `json:"enforceLinuxNodes,omitempty"`
+}
+
+// OperatorQueueingConfiguration configures the fair-share queueing of SparkApplications.
+type OperatorQueueingConfiguration struct {
+	// MaxRunningApplications is the maximum number of SparkApplications running concurrently, or unlimited
+	// if 0. Enabling queueing when the operator was started without it requires a restart.
+	// Optional.
+	MaxRunningApplications *int32 `json:"maxRunningApplications,omitempty"`
+	// QueueWeights are the weights of the scheduling queues. Queues not listed get a weight of 1.
+	// Optional.
+	QueueWeights map[string]int32 `json:"queueWeights,omitempty"`
+	// MaxPriorities are the maximum priorities of SparkApplications by namespace. The namespace "*" applies to
+	// namespaces not listed, and SparkApplications in namespaces without a maximum priority get a priority of at
+	// most 0.
+	// Optional.
+	MaxPriorities map[string]int32 `json:"maxPriorities,omitempty"`
+}
+
+// OperatorMetricsConfiguration configures the metrics of the operator.
+type OperatorMetricsConfiguration struct {
+	// Prefix is the prefix of the names of the metrics.
+	// Optional.
+	Prefix *string `json:"prefix,omitempty"`
+	// Labels are the labels of SparkApplications exported as labels of the metrics.
+	// Optional.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// OperatorProxyUserConfiguration configures the Hadoop impersonation of proxy users of SparkApplications.
+type OperatorProxyUserConfiguration struct {
+	// Allowed are the users SparkApplications may set as their proxyUser, or "*" for any user. No proxy users
+	// are allowed if empty.
+	// Optional.
+	Allowed []string `json:"allowed,omitempty"`
+	// Superuser is the Hadoop user that impersonates proxy users, which the Hadoop cluster must allow to do so
+	// through its hadoop.proxyuser.<superuser>.* properties. It is set as HADOOP_USER_NAME of drivers with a
+	// proxy user that do not set it, as is needed without Kerberos.
+	// Optional.
+	Superuser *string `json:"superuser,omitempty"`
+}
+
+// SparkOperatorConfigurationStatus describes which configuration the operator has applied.
+type SparkOperatorConfigurationStatus struct {
+	// ObservedGeneration is the latest generation of the configuration the operator has seen.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// AppliedGeneration is the latest generation of the configuration the operator has applied. It lags
+	// behind ObservedGeneration if the latest generation is invalid.
+	AppliedGeneration int64 `json:"appliedGeneration,omitempty"`
+	// LastAppliedTime is the time the operator last applied the configuration.
+	LastAppliedTime metav1.Time `json:"lastAppliedTime,omitempty"`
+	// RestartRequired tells if the applied configuration has changes that take effect only when the operator
+	// restarts.
+	RestartRequired bool `json:"restartRequired,omitempty"`
+	// Message explains why the latest generation is invalid or requires a restart.
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SparkOperatorConfigurationList carries a list of SparkOperatorConfiguration objects.
+type SparkOperatorConfigurationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SparkOperatorConfiguration `json:"items,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:defaulter-gen=true
 
 // SparkApplication represents a Spark application running on and using Kubernetes as a cluster manager.
@@ -178,6 +728,7 @@ type SparkApplicationSpec struct {
 	ImagePullPolicy *string `json:"imagePullPolicy,omitempty"`
 	// ImagePullSecrets is the list of image-pull secrets.
 	// Optional.
+	// +listType=atomic
 	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
 	// MainClass is the fully-qualified main class of the Spark application.
 	// This only applies to Java/Scala Spark applications.
@@ -188,15 +739,18 @@ type SparkApplicationSpec struct {
 	MainApplicationFile *string `json:"mainApplicationFile"`
 	// Arguments is a list of arguments to be passed to the application.
 	// Optional.
+	// +listType=atomic
 	Arguments []string `json:"arguments,omitempty"`
 	// SparkConf carries user-specified Spark configuration properties as they would use the  "--conf" option in
 	// spark-submit.
 	// Optional.
+	// +mapType=granular
 	SparkConf map[string]string `json:"sparkConf,omitempty"`
 	// HadoopConf carries user-specified Hadoop configuration properties as they would use the  the "--conf" option
 	// in spark-submit.  The SparkApplication controller automatically adds prefix "spark.hadoop." to Hadoop
 	// configuration properties.
 	// Optional.
+	// +mapType=granular
 	HadoopConf map[string]string `json:"hadoopConf,omitempty"`
 	// SparkConfigMap carries the name of the ConfigMap containing Spark configuration files such as log4j.properties.
 	// The controller will add environment variable SPARK_CONF_DIR to the path where the ConfigMap is mounted to.
@@ -206,8 +760,15 @@ type SparkApplicationSpec struct {
 	// The controller will add environment variable HADOOP_CONF_DIR to the path where the ConfigMap is mounted to.
 	// Optional.
 	HadoopConfigMap *string `json:"hadoopConfigMap,omitempty"`
+	// HadoopClusterRef is the name of a HadoopCluster in the namespace of the application to generate the Hadoop
+	// configuration files from. The operator keeps them in a ConfigMap mounted like HadoopConfigMap, so the two
+	// are mutually exclusive.
+	// Optional.
+	HadoopClusterRef *string `json:"hadoopClusterRef,omitempty"`
 	// Volumes is the list of Kubernetes volumes that can be mounted by the driver and/or executors.
 	// Optional.
+	// +listType=map
+	// +listMapKey=name
 	Volumes []apiv1.Volume `json:"volumes,omitempty"`
 	// Driver is the driver specification.
 	Driver DriverSpec `json:"driver"`
@@ -219,12 +780,15 @@ type SparkApplicationSpec struct {
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
 	// NodeSelector is the Kubernetes node selector to be added to the driver and executor pods.
 	// Optional.
+	// +mapType=granular
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 	// FailureRetries is the number of times to retry a failed application before giving up.
 	// This is best effort and actual retry attempts can be >= the value specified.
+	// Deprecated: use RestartPolicy.OnFailureRetries instead, which the operator migrates this field to.
 	// Optional.
 	FailureRetries *int32 `json:"failureRetries,omitempty"`
 	// RetryInterval is the unit of intervals in seconds between submission retries.
+	// Deprecated: use RestartPolicy.OnFailureRetryInterval instead, which the operator migrates this field to.
 	// Optional.
 	RetryInterval *int64 `json:"retryInterval,omitempty"`
 	// This sets the major Python version of the docker
@@ -239,6 +803,327 @@ type SparkApplicationSpec struct {
 	// Monitoring configures how monitoring is handled.
 	// Optional.
 	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+	// ExternalDriver describes a driver running outside of the cluster, e.g., in a notebook server. Only used
+	// with mode client. The operator then does not run spark-submit, but provisions what the external driver
+	// needs to run executors in the cluster.
+	// Optional.
+	ExternalDriver *ExternalDriverSpec `json:"externalDriver,omitempty"`
+	// CreateServiceAccount tells the operator to create a dedicated ServiceAccount for the driver, which is only
+	// allowed to manage the executor pods in the namespace of the application, instead of using a shared one.
+	// The ServiceAccount is deleted once the application terminates. Cannot be used together with
+	// Driver.ServiceAccount.
+	// Optional. Defaults to false.
+	CreateServiceAccount *bool `json:"createServiceAccount,omitempty"`
+	// SpreadExecutors tells the webhook to add a preferred pod anti-affinity to the executors that spreads them
+	// over as many nodes as possible.
+	// Optional. Defaults to false.
+	SpreadExecutors *bool `json:"spreadExecutors,omitempty"`
+	// SeparateDriver tells the webhook to add a preferred pod anti-affinity to the executors that keeps them off
+	// the node of the driver.
+	// Optional. Defaults to false.
+	SeparateDriver *bool `json:"separateDriver,omitempty"`
+	// Zone is the zone the driver and executors are pinned to through a required node affinity, which avoids
+	// cross-zone shuffle traffic. Takes precedence over ZoneAffinity.
+	// Optional.
+	Zone *string `json:"zone,omitempty"`
+	// ZoneAffinity pins the executors to a zone chosen relative to the driver. Only "sameAsDriver" is supported,
+	// which schedules the executors in the zone the driver runs in.
+	// Optional.
+	ZoneAffinity *ZoneAffinityType `json:"zoneAffinity,omitempty"`
+	// Rotation tells the operator to periodically restart a long-running application, e.g., a streaming
+	// application, to limit the effect of memory leaks.
+	// Optional.
+	Rotation *RotationPolicy `json:"rotation,omitempty"`
+	// OutputCleanup tells the operator to run a Job deleting the _temporary directories output committers leave
+	// behind under the output paths of the application when it fails, so that downstream readers do not see
+	// stale partial data.
+	// Optional.
+	OutputCleanup *OutputCleanupSpec `json:"outputCleanup,omitempty"`
+	// DriverLogCapture tells the operator to save the end of the driver log to a ConfigMap when the application
+	// fails, so it is kept after the driver pod is deleted.
+	// Optional.
+	DriverLogCapture *DriverLogCaptureSpec `json:"driverLogCapture,omitempty"`
+	// Notifications describes the notifications sent about the application in addition to the ones the operator
+	// routes to its notification sinks.
+	// Optional.
+	Notifications *NotificationSpec `json:"notifications,omitempty"`
+	// Priority is the priority of the application when it is queued. Higher-priority applications start before
+	// lower-priority ones in the same queue, and may preempt running lower-priority applications if there is no
+	// capacity left for them.
+	// Optional. Defaults to 0.
+	Priority *int32 `json:"priority,omitempty"`
+	// PreemptionPolicy tells if the application may preempt running lower-priority applications.
+	// Optional. Defaults to "PreemptLowerPriority".
+	PreemptionPolicy *PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	// ExecutorGroups are groups of executors in addition to the ones described by Executor, each with its own
+	// resources and placement, e.g., a memory-optimized group and a GPU group. Each group is backed by a Spark
+	// resource profile, which the stages running on the group request through stage-level scheduling.
+	// Optional.
+	ExecutorGroups []ExecutorGroup `json:"executorGroups,omitempty"`
+	// ResourceProfiles are the resources of the executors Spark launches for the resource profiles the application
+	// builds for stage-level scheduling. The resources of a profile replace the ones of Executor for its executors.
+	// Optional.
+	ResourceProfiles []ResourceProfile `json:"resourceProfiles,omitempty"`
+	// SourceRef refers to a versioned Git or OCI artifact holding the manifest of a SparkApplication. The
+	// operator loads the spec of the manifest before the application runs and makes it the spec of the
+	// application, so that the spec is reviewed and versioned outside of the cluster.
+	// Optional.
+	SourceRef *SourceReference `json:"sourceRef,omitempty"`
+	// ExecutorIdleTimeout tells the operator to delete executors that have been idle for too long and to keep
+	// Spark from replacing them, which reclaims the capacity held by applications requesting more executors than
+	// they use. Ignored if the application enables dynamic allocation, which removes idle executors itself.
+	// Optional.
+	ExecutorIdleTimeout *ExecutorIdleTimeoutSpec `json:"executorIdleTimeout,omitempty"`
+	// MinExecutorsBeforeStart is the number of executors that must be running before the operator opens the
+	// start gate of the driver, i.e., creates the file the environment variable SPARK_START_GATE_FILE of the
+	// driver points to. The application is expected to wait for the file before it starts processing, e.g.,
+	// before a streaming query starts consuming, so it does not start with only part of its executors.
+	// Optional.
+	MinExecutorsBeforeStart *int32 `json:"minExecutorsBeforeStart,omitempty"`
+	// ProxyUser is the user spark-submit runs the application as through --proxy-user, so that it accesses
+	// HDFS, Hive and other Hadoop services with the permissions of that user. The user must be allowed in the
+	// operator configuration. Requires Spark 3.1 or later.
+	// Optional.
+	ProxyUser *string `json:"proxyUser,omitempty"`
+	// CloudIdentity is the cloud identity the driver and executors assume. It implies CreateServiceAccount, as
+	// the identity is bound to the dedicated ServiceAccount. The executors run as the ServiceAccount of the
+	// driver, which requires Spark 3.1 or later.
+	// Optional.
+	CloudIdentity *CloudIdentitySpec `json:"cloudIdentity,omitempty"`
+	// EncryptLocalData tells the operator to encrypt the shuffle and spill files of the application, along with
+	// the RPC traffic between the driver and executors, with a shared secret it generates and keeps in a Secret
+	// owned by the application. Requires Spark 3.0 or later.
+	// Optional. Defaults to false.
+	EncryptLocalData *bool `json:"encryptLocalData,omitempty"`
+	// RunHistory tells the operator to record every run of the application, i.e., every execution attempt or
+	// failed submission, as an immutable SparkApplicationRun owned by the application.
+	// Optional.
+	RunHistory *RunHistorySpec `json:"runHistory,omitempty"`
+	// RestartOnConfigChange tells the operator to restart the application when a ConfigMap or Secret its driver
+	// or executors use changes while it runs, e.g., when credentials are rotated. Otherwise the application is
+	// only reported as Stale. Requires the ConfigChangeDetection feature gate.
+	// Optional. Defaults to false.
+	RestartOnConfigChange *bool `json:"restartOnConfigChange,omitempty"`
+	// MLMode runs the application as a distributed training job, e.g., with Horovod, whose executors each run one
+	// training process of a barrier stage. The executors are scheduled together and get the GPUs and the NCCL
+	// and MPI environment of the training processes.
+	// Optional.
+	MLMode *MLModeSpec `json:"mlMode,omitempty"`
+	// GPU gives the executors GPUs Spark schedules tasks on, and optionally runs SQL and DataFrame operations on
+	// them with the RAPIDS Accelerator for Apache Spark.
+	// Optional.
+	GPU *GPUAccelerationSpec `json:"gpu,omitempty"`
+	// Debug tells the JVMs of the driver and executors to write heap dumps when they run out of memory, or JDK
+	// Flight Recorder recordings, to a PersistentVolumeClaim, from which the operator uploads them to object
+	// storage when the driver or an executor runs out of memory.
+	// Optional.
+	Debug *DebugSpec `json:"debug,omitempty"`
+	// DataCache mounts a node-local cache of hot datasets into the executors, kept by cache daemons the operator
+	// runs on the nodes, so that repeated scans of the same datasets read from local disks. Requires the
+	// DataCache feature gate and the mutating admission webhook.
+	// Optional.
+	DataCache *DataCacheSpec `json:"dataCache,omitempty"`
+	// ConnectionPooler runs a PgBouncer sidecar in the driver and executors through which they connect to a
+	// PostgreSQL database such as the Hive metastore database, so that many executors share a few connections.
+	// Requires the mutating admission webhook.
+	// Optional.
+	ConnectionPooler *ConnectionPoolerSpec `json:"connectionPooler,omitempty"`
+	// AutoTuning sets the number of shuffle partitions and executors of each run from the size of the input of
+	// the application, which the operator lists before submitting the run. Requires the AutoTuning feature gate.
+	// Optional.
+	AutoTuning *AutoTuningSpec `json:"autoTuning,omitempty"`
+	// Submitter is the name of the submitter the operator submits the application with: "spark-submit",
+	// "native" to create the driver pod directly, "remote" to hand the submission to a remote submission
+	// service, or "dry-run" to only render the submission.
+	// Optional. Defaults to the default submitter of the operator.
+	Submitter *string `json:"submitter,omitempty"`
+	// IdempotencyKey identifies the work the application does, e.g., a pipeline run an orchestrator triggers it
+	// for. An application is skipped instead of submitted if another application in its namespace with the same
+	// key is running or succeeded recently, so that a trigger sent twice does not run the work twice.
+	// Optional.
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+	// MetadataPropagation lists labels and annotations of the application that are copied to its pods,
+	// Services, ConfigMaps, and PersistentVolumeClaims, besides the ones the operator propagates.
+	// Optional.
+	MetadataPropagation *MetadataPropagationSpec `json:"metadataPropagation,omitempty"`
+}
+
+// MetadataPropagationSpec lists the keys of labels and annotations of an application that are copied to the
+// resources of it. Resources keep labels and annotations they already have.
+type MetadataPropagationSpec struct {
+	// Labels are the keys of the labels to propagate.
+	// Optional.
+	Labels []string `json:"labels,omitempty"`
+	// Annotations are the keys of the annotations to propagate.
+	// Optional.
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// RunHistorySpec describes how long the SparkApplicationRuns of an application are kept.
+type RunHistorySpec struct {
+	// Limit is the number of the most recent SparkApplicationRuns of the application that are kept.
+	// Optional. Defaults to 10.
+	Limit *int32 `json:"limit,omitempty"`
+	// TTLSeconds is the number of seconds after the end of a run its SparkApplicationRun is deleted after.
+	// Optional. SparkApplicationRuns are only deleted beyond Limit if not set.
+	TTLSeconds *int64 `json:"ttlSeconds,omitempty"`
+}
+
+// SourceReference refers to a versioned artifact holding the manifest of a SparkApplication. Exactly one of Git
+// and OCI must be set.
+type SourceReference struct {
+	// Git refers to a manifest in a Git repository.
+	// Optional.
+	Git *GitSource `json:"git,omitempty"`
+	// OCI refers to a manifest in an OCI artifact, e.g., one pushed with "oras push".
+	// Optional.
+	OCI *OCISource `json:"oci,omitempty"`
+	// SecretName is the name of a secret in the namespace of the application whose "username" and "password"
+	// keys authenticate with the Git server or the registry, e.g., with an access token as the password.
+	// Optional.
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// GitSource refers to a manifest in a Git repository.
+type GitSource struct {
+	// URL is the URL of the repository, e.g., https://github.com/org/pipelines.git.
+	URL string `json:"url"`
+	// Revision is the branch, tag, or commit SHA the manifest is read at. A full commit SHA pins the manifest, and
+	// the commit fetched is verified to be the one pinned.
+	// Optional. Defaults to "HEAD".
+	Revision *string `json:"revision,omitempty"`
+	// Path is the path of the manifest in the repository.
+	Path string `json:"path"`
+}
+
+// OCISource refers to a manifest in an OCI artifact.
+type OCISource struct {
+	// Reference is the reference of the artifact, e.g., ghcr.io/org/pipelines:v1 or
+	// ghcr.io/org/pipelines@sha256:<digest>. A digest pins the artifact, and the artifact manifest fetched is
+	// verified to have the digest.
+	Reference string `json:"reference"`
+	// Path is the title of the layer holding the manifest, which "oras push" sets to the name of the file pushed.
+	// Optional if the artifact has a single layer.
+	Path *string `json:"path,omitempty"`
+}
+
+// PreemptionPolicy describes if a queued application may preempt running lower-priority applications.
+type PreemptionPolicy string
+
+// Different preemption policies.
+const (
+	PreemptLowerPriority PreemptionPolicy = "PreemptLowerPriority"
+	PreemptNever         PreemptionPolicy = "Never"
+)
+
+// OutputCleanupSpec describes the output of an application that is cleaned up when the application fails.
+type OutputCleanupSpec struct {
+	// OutputPaths are the Hadoop file system paths the application writes to, e.g., s3a://bucket/table or
+	// hdfs:///data/table.
+	OutputPaths []string `json:"outputPaths"`
+	// Image is the container image of the cleanup Job, which needs Spark and the Hadoop file system
+	// implementations of the output paths.
+	// Optional. Defaults to the image of the driver.
+	Image *string `json:"image,omitempty"`
+}
+
+// DriverLogCaptureSpec describes how much of the driver log of a failed application is saved.
+type DriverLogCaptureSpec struct {
+	// MaxKB is the number of KiB at the end of the driver log that are saved, at most 512.
+	// Optional. Defaults to 64.
+	MaxKB *int32 `json:"maxKB,omitempty"`
+}
+
+// CloudIdentitySpec describes the cloud identity of an application. Exactly one of AWSRoleARN and
+// GCPServiceAccount must be set.
+type CloudIdentitySpec struct {
+	// AWSRoleARN is the ARN of the AWS IAM role assumed through IAM Roles for Service Accounts (IRSA).
+	// Optional.
+	AWSRoleARN *string `json:"awsRoleARN,omitempty"`
+	// GCPServiceAccount is the email of the Google service account impersonated through GKE Workload Identity.
+	// Optional.
+	GCPServiceAccount *string `json:"gcpServiceAccount,omitempty"`
+}
+
+// ExecutorIdleTimeoutSpec describes when the operator deletes idle executors of an application.
+type ExecutorIdleTimeoutSpec struct {
+	// TimeoutSeconds is the number of seconds an executor must have been idle for before it is deleted.
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+	// CPUThreshold is the CPU usage of an executor pod below which the executor is idle, e.g., "50m".
+	// Optional. Defaults to "50m".
+	CPUThreshold *string `json:"cpuThreshold,omitempty"`
+	// MinExecutors is the number of executors that are kept even if they are idle.
+	// Optional. Defaults to 1.
+	MinExecutors *int32 `json:"minExecutors,omitempty"`
+}
+
+// NotificationSpec describes the notifications sent about an application.
+type NotificationSpec struct {
+	// Sinks are the names of the notification sinks configured in the operator the notifications are sent to.
+	Sinks []string `json:"sinks,omitempty"`
+	// Events are the events notified.
+	// Optional. Defaults to all events but Completed.
+	Events []NotificationEvent `json:"events,omitempty"`
+	// OutputPaths are the paths the application writes its output to, which are listed in the artifact
+	// manifest of notifications.
+	// Optional. Defaults to the output paths of OutputCleanup.
+	OutputPaths []string `json:"outputPaths,omitempty"`
+	// SLASeconds is the number of seconds a run of the application may take from its submission before an
+	// SLABreached notification is sent.
+	// Optional.
+	SLASeconds *int64 `json:"slaSeconds,omitempty"`
+	// Template is a Go template of the notification message, executed with the Event and the App.
+	// Optional. Defaults to the template of the operator.
+	Template *string `json:"template,omitempty"`
+}
+
+// NotificationEvent is an event of an application that is notified.
+type NotificationEvent string
+
+// Different notification events.
+const (
+	// FailedNotificationEvent is sent when an application fails for good.
+	FailedNotificationEvent NotificationEvent = "Failed"
+	// RetriesExhaustedNotificationEvent is sent instead of FailedNotificationEvent when an application fails for
+	// good after it has been retried.
+	RetriesExhaustedNotificationEvent NotificationEvent = "RetriesExhausted"
+	// SLABreachedNotificationEvent is sent when a run takes longer than the SLA of an application.
+	SLABreachedNotificationEvent NotificationEvent = "SLABreached"
+	// CompletedNotificationEvent is sent when an application completes successfully. It is only sent if it is
+	// listed explicitly.
+	CompletedNotificationEvent NotificationEvent = "Completed"
+)
+
+// RotationPolicy describes when a long-running application is restarted.
+type RotationPolicy struct {
+	// MaxRuntimeBeforeRotation is the number of seconds a run of the application may take before its driver is
+	// gracefully terminated and the application is re-submitted.
+	MaxRuntimeBeforeRotation int64 `json:"maxRuntimeBeforeRotation"`
+	// Window restricts restarts to a daily time window in UTC in the format "HH:MM-HH:MM", e.g., "02:00-04:00",
+	// so that they happen at a low-traffic time. A run exceeding its maximum runtime outside of the window is
+	// restarted at the start of the next window.
+	// Optional.
+	Window *string `json:"window,omitempty"`
+}
+
+// ZoneAffinityType describes how the executors of an application are pinned to a zone.
+type ZoneAffinityType string
+
+// Different types of zone affinity.
+const (
+	SameAsDriverZoneAffinity ZoneAffinityType = "sameAsDriver"
+)
+
+// ExternalDriverSpec describes a driver running outside of the cluster.
+type ExternalDriverSpec struct {
+	// IP is the IP address executors reach the driver at.
+	IP string `json:"ip"`
+	// Port is the port of the RPC endpoint of the driver.
+	// Optional. Defaults to 7078.
+	Port *int32 `json:"port,omitempty"`
+	// BlockManagerPort is the port of the block manager of the driver.
+	// Optional. Defaults to 7079.
+	BlockManagerPort *int32 `json:"blockManagerPort,omitempty"`
 }
 
 // ApplicationStateType represents the type of the current state of an application.
@@ -247,6 +1132,7 @@ type ApplicationStateType string
 // Different states an application may have.
 const (
 	NewState              ApplicationStateType = ""
+	QueuedState           ApplicationStateType = "QUEUED"
 	SubmittedState        ApplicationStateType = "SUBMITTED"
 	RunningState          ApplicationStateType = "RUNNING"
 	CompletedState        ApplicationStateType = "COMPLETED"
@@ -257,6 +1143,7 @@ const (
 	SucceedingState       ApplicationStateType = "SUCCEEDING"
 	FailingState          ApplicationStateType = "FAILING"
 	UnknownState          ApplicationStateType = "UNKNOWN"
+	SkippedState          ApplicationStateType = "SKIPPED"
 )
 
 // ApplicationState tells the current state of the application and an error message in case of failures.
@@ -295,6 +1182,275 @@ type SparkApplicationStatus struct {
 	ExecutionAttempts int32 `json:"executionAttempts,omitempty"`
 	// SubmissionAttempts is the total number of submission attempts made to submit a Spark App.
 	SubmissionAttempts int32 `json:"submissionAttempts,omitempty"`
+	// SubmittedBy is the name of the user who created the SparkApplication, as recorded by the webhook.
+	SubmittedBy string `json:"submittedBy,omitempty"`
+	// AttachedTo is the name of the application with the same IdempotencyKey the application was skipped for.
+	AttachedTo string `json:"attachedTo,omitempty"`
+	// QueuedTime is the time when the application was last queued for starting.
+	QueuedTime metav1.Time `json:"queuedTime,omitempty"`
+	// LineageRunID is the ID of the OpenLineage run of the current run of the application. Only set if lineage
+	// emission is enabled in the operator.
+	LineageRunID string `json:"lineageRunId,omitempty"`
+	// Progress is the progress of the running application as reported by the REST API of the driver.
+	// Only set if progress reporting is enabled in the operator.
+	Progress *ApplicationProgress `json:"progress,omitempty"`
+	// Usage is the resource usage of the last run of the application as last reported by the REST API of the
+	// driver, recorded when the run ended. Only set if progress reporting is enabled in the operator.
+	Usage *ApplicationUsage `json:"usage,omitempty"`
+	// LaunchLatency breaks down how long the current run of the application took to launch.
+	LaunchLatency *LaunchLatency `json:"launchLatency,omitempty"`
+	// DriverLogConfigMap is the name of the ConfigMap holding the end of the driver log of the last failed run,
+	// if the application captures it.
+	DriverLogConfigMap string `json:"driverLogConfigMap,omitempty"`
+	// SLABreachTime is the time the current run was found to take longer than the SLA of the application.
+	SLABreachTime metav1.Time `json:"slaBreachTime,omitempty"`
+	// SourceRevision is the commit SHA of the Git source or the digest of the OCI source the spec was loaded from,
+	// if the application has a sourceRef.
+	SourceRevision string `json:"sourceRevision,omitempty"`
+	// ReclaimedExecutors is the number of idle executors the operator has deleted in the current run. Spark may
+	// run that many fewer executors than requested for the rest of the run.
+	ReclaimedExecutors int32 `json:"reclaimedExecutors,omitempty"`
+	// StartGateOpenTime is the time the start gate of the current run was opened, if the application sets
+	// minExecutorsBeforeStart.
+	StartGateOpenTime metav1.Time `json:"startGateOpenTime,omitempty"`
+	// PodTemplateHash is the hash of the parts of the spec that determine the driver and executor pods, as of the
+	// submission of the current run. The pods of the run are annotated with it.
+	PodTemplateHash string `json:"podTemplateHash,omitempty"`
+	// Conditions are the current conditions of the application.
+	Conditions []ApplicationCondition `json:"conditions,omitempty"`
+	// ExecutorRestart is the progress of the last rolling restart of the executors of the current run, if any.
+	ExecutorRestart *ExecutorRestartStatus `json:"executorRestart,omitempty"`
+	// ConfigHashes are the hashes of the data of the ConfigMaps and Secrets the driver and executors use, keyed
+	// by "ConfigMap/<name>" or "Secret/<name>", as of the submission of the current run. A ConfigMap or Secret
+	// that did not exist has an empty hash. Only set with the ConfigChangeDetection feature gate enabled.
+	ConfigHashes map[string]string `json:"configHashes,omitempty"`
+	// ResourcePressure summarizes the CPU throttling and resource pressure of the executors of the current run,
+	// as reported by the node agent.
+	ResourcePressure *ResourcePressureSummary `json:"resourcePressure,omitempty"`
+	// Dumps describes the heap dumps and flight recordings of the current run, if the application sets Debug.
+	Dumps *DumpStatus `json:"dumps,omitempty"`
+	// AutoTuning describes how the current run was sized from its input, if the application sets AutoTuning.
+	AutoTuning *AutoTuningStatus `json:"autoTuning,omitempty"`
+	// MemoryBump describes how the memory of the driver or executors was increased after their pods ran out of
+	// memory, if the RestartPolicy sets MemoryBump. It is kept across runs until a change of the spec restarts the
+	// application.
+	MemoryBump *MemoryBumpStatus `json:"memoryBump,omitempty"`
+	// LastSpecUpdate describes the last update of the spec, telling the changed fields that required a restart
+	// apart from the ones that applied to the current run.
+	LastSpecUpdate *SpecUpdateStatus `json:"lastSpecUpdate,omitempty"`
+	// ObservedGeneration is the generation of the spec the operator has last processed. A spec edit has been
+	// acted upon once it is at least the generation of the edited application.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// SubmissionOutput is the output of spark-submit in the last submission attempt, if the application was
+	// submitted with spark-submit.
+	SubmissionOutput *SubmissionOutput `json:"submissionOutput,omitempty"`
+}
+
+// SubmissionOutput is the output of a run of spark-submit, with the values of Secrets redacted. Only the end of
+// long output is kept.
+type SubmissionOutput struct {
+	// Attempt is the submission attempt spark-submit was run in, counting like SubmissionAttempts.
+	Attempt int32 `json:"attempt"`
+	// ExitCode is the exit code of spark-submit, or -1 if it was killed.
+	ExitCode int32 `json:"exitCode"`
+	// TimedOut tells if spark-submit was killed for taking longer than the submission timeout of the operator.
+	TimedOut bool `json:"timedOut,omitempty"`
+	// Stdout is the standard output of spark-submit.
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr is the standard error of spark-submit.
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// SpecUpdateStatus describes an update of the spec of an application. Changes to fields that determine the
+// driver and executor pods require a restart, while changes to fields the operator only reads itself while the
+// application runs are hot-swappable, i.e., apply to the current run.
+type SpecUpdateStatus struct {
+	// UpdateTime is the time the update was processed.
+	UpdateTime metav1.Time `json:"updateTime"`
+	// Restarted tells if the application was restarted for the update.
+	Restarted bool `json:"restarted"`
+	// RestartRequiringFields are the changed fields that required a restart.
+	RestartRequiringFields []FieldChange `json:"restartRequiringFields,omitempty"`
+	// HotSwappableFields are the changed fields that applied to the current run.
+	HotSwappableFields []FieldChange `json:"hotSwappableFields,omitempty"`
+}
+
+// FieldChange describes a changed field of the spec of an application.
+type FieldChange struct {
+	// Path is the path of the field, e.g., spec.driver.cores or spec.sparkConf[spark.eventLog.dir]. Lists are
+	// compared as a whole.
+	Path string `json:"path"`
+	// OldValue is the JSON of the old value of the field, empty if it was unset. Long values are truncated.
+	OldValue string `json:"oldValue,omitempty"`
+	// NewValue is the JSON of the new value of the field, empty if it was unset. Long values are truncated.
+	NewValue string `json:"newValue,omitempty"`
+}
+
+// DumpStatus describes where the heap dumps and flight recordings of a run are, and which of its pods ran out of
+// memory.
+type DumpStatus struct {
+	// Path is the directory of the dumps of the run on the PersistentVolumeClaim, which has a subdirectory per
+	// pod.
+	Path string `json:"path"`
+	// OutOfMemoryPods are the names of the driver and executor pods of the run that ran out of memory.
+	OutOfMemoryPods []string `json:"outOfMemoryPods,omitempty"`
+	// UploadJobName is the name of the Job uploading the dumps, once the run has ended.
+	UploadJobName string `json:"uploadJobName,omitempty"`
+	// UploadURL is the URI of the directory the dumps are uploaded to.
+	UploadURL string `json:"uploadURL,omitempty"`
+}
+
+// AutoTuningStatus describes how a run of a SparkApplication was sized from its input.
+type AutoTuningStatus struct {
+	// InputBytes is the size of the input of the run in bytes.
+	InputBytes int64 `json:"inputBytes"`
+	// ShufflePartitions is the number of shuffle partitions spark.sql.shuffle.partitions was set to.
+	ShufflePartitions int32 `json:"shufflePartitions"`
+	// Executors is the number of executors, or the maximum number of executors with dynamic allocation.
+	Executors int32 `json:"executors"`
+}
+
+// MemoryBumpStatus describes how the memory of the driver or executors of an application was increased after
+// their pods ran out of memory.
+type MemoryBumpStatus struct {
+	// Bumps is the number of times the memory was increased.
+	Bumps int32 `json:"bumps"`
+	// LastBumpTime is the time the memory was last increased.
+	LastBumpTime metav1.Time `json:"lastBumpTime,omitempty"`
+	// DriverMemory is the memory the driver runs with, if increased.
+	DriverMemory *string `json:"driverMemory,omitempty"`
+	// DriverMemoryOverhead is the memory overhead the driver runs with, if increased.
+	DriverMemoryOverhead *string `json:"driverMemoryOverhead,omitempty"`
+	// ExecutorMemory is the memory the executors run with, if increased.
+	ExecutorMemory *string `json:"executorMemory,omitempty"`
+	// ExecutorMemoryOverhead is the memory overhead the executors run with, if increased.
+	ExecutorMemoryOverhead *string `json:"executorMemoryOverhead,omitempty"`
+	// OutOfMemory lists the roles, i.e., driver or executor, of the pods of the current run that ran out of memory.
+	OutOfMemory []string `json:"outOfMemory,omitempty"`
+}
+
+// ResourcePressureSummary summarizes how much the executors of a run were slowed down by the CPU limits of their
+// containers and by contention for CPU and memory, from the cgroup v2 statistics and pressure stall information
+// (PSI) the node agent samples. The percentages are over the lifetime of the executors sampled last.
+type ResourcePressureSummary struct {
+	// SampledExecutors is the number of executors the summary is computed from.
+	SampledExecutors int32 `json:"sampledExecutors"`
+	// CPUThrottledPercent is the percentage of CFS enforcement periods in which the executors were throttled by
+	// their CPU limits.
+	CPUThrottledPercent float64 `json:"cpuThrottledPercent"`
+	// CPUPressurePercent is the percentage of time in which some tasks of the executors were stalled waiting for
+	// CPU.
+	CPUPressurePercent float64 `json:"cpuPressurePercent"`
+	// MemoryPressurePercent is the percentage of time in which some tasks of the executors were stalled waiting
+	// for memory, e.g., for reclaim.
+	MemoryPressurePercent float64 `json:"memoryPressurePercent"`
+	// LastSampleTime is the time of the last sample the summary is computed from.
+	LastSampleTime metav1.Time `json:"lastSampleTime,omitempty"`
+}
+
+// ExecutorRestartStatus describes the progress of a rolling restart of the executors of an application, which
+// deletes the executors in waves while Spark replaces them.
+type ExecutorRestartStatus struct {
+	// RequestedAt is the time the restart was requested. Executors created before are restarted.
+	RequestedAt metav1.Time `json:"requestedAt"`
+	// RestartedExecutors is the number of executors deleted so far.
+	RestartedExecutors int32 `json:"restartedExecutors,omitempty"`
+	// LastWaveTime is the time the last wave of executors was deleted.
+	LastWaveTime metav1.Time `json:"lastWaveTime,omitempty"`
+	// CompletionTime is the time no executor created before the request was left.
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ApplicationConditionType is the type of a condition of an application.
+type ApplicationConditionType string
+
+// Different conditions of applications.
+const (
+	// UpdateRequiredCondition is true when the spec of an application has changed in a way that requires the
+	// driver and executor pods of the current run to be recreated, until the operator has submitted a new run.
+	UpdateRequiredCondition ApplicationConditionType = "UpdateRequired"
+	// StaleCondition is true when a ConfigMap or Secret the driver or executors of the current run of an
+	// application use has changed since the run was submitted.
+	StaleCondition ApplicationConditionType = "Stale"
+)
+
+// ApplicationCondition describes a condition of an application.
+type ApplicationCondition struct {
+	// Type is the type of the condition.
+	Type ApplicationConditionType `json:"type"`
+	// Status is the status of the condition, i.e., True, False or Unknown.
+	Status apiv1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the time the condition last changed its status.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a machine-readable reason for the last transition of the condition.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable description of the last transition of the condition.
+	Message string `json:"message,omitempty"`
+}
+
+// LaunchLatency breaks down the time it took to launch a run of an application.
+type LaunchLatency struct {
+	// DriverRunningTime is the time the driver of the run was first seen running.
+	DriverRunningTime metav1.Time `json:"driverRunningTime,omitempty"`
+	// FirstExecutorRunningTime is the time the first executor of the run was seen running.
+	FirstExecutorRunningTime metav1.Time `json:"firstExecutorRunningTime,omitempty"`
+	// CreationToDriverRunningMillis is the time in milliseconds from the creation of the SparkApplication to
+	// its driver running. Only set for the first run of the application.
+	CreationToDriverRunningMillis *int64 `json:"creationToDriverRunningMillis,omitempty"`
+	// DriverToFirstExecutorRunningMillis is the time in milliseconds from the driver running to the first
+	// executor running.
+	DriverToFirstExecutorRunningMillis *int64 `json:"driverToFirstExecutorRunningMillis,omitempty"`
+}
+
+// ApplicationProgress describes the progress of a running application.
+type ApplicationProgress struct {
+	// PercentComplete is the percentage of completed or skipped tasks of the jobs started so far.
+	PercentComplete int32 `json:"percentComplete"`
+	// ActiveJobs is the number of running jobs.
+	ActiveJobs int32 `json:"activeJobs,omitempty"`
+	// CompletedJobs is the number of jobs that have succeeded.
+	CompletedJobs int32 `json:"completedJobs,omitempty"`
+	// FailedJobs is the number of jobs that have failed.
+	FailedJobs int32 `json:"failedJobs,omitempty"`
+	// ActiveStages are the stages that are currently running.
+	ActiveStages []StageProgress `json:"activeStages,omitempty"`
+	// OutputBytes is the number of bytes written to output by the stages so far.
+	OutputBytes int64 `json:"outputBytes,omitempty"`
+	// OutputRecords is the number of records written to output by the stages so far.
+	OutputRecords int64 `json:"outputRecords,omitempty"`
+	// LastUpdateTime is the time the progress last changed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// ApplicationUsage describes the resources a run of an application used, as reported by the REST API of the
+// driver.
+type ApplicationUsage struct {
+	// PeakExecutors is the largest number of executors the run had at the same time.
+	PeakExecutors int32 `json:"peakExecutors,omitempty"`
+	// Tasks is the number of tasks the stages of the run ran, including failed and killed task attempts.
+	Tasks int64 `json:"tasks,omitempty"`
+	// InputBytes is the number of bytes the stages of the run read from input.
+	InputBytes int64 `json:"inputBytes,omitempty"`
+	// OutputBytes is the number of bytes the stages of the run wrote to output.
+	OutputBytes int64 `json:"outputBytes,omitempty"`
+	// ShuffleReadBytes is the number of shuffle bytes the stages of the run read.
+	ShuffleReadBytes int64 `json:"shuffleReadBytes,omitempty"`
+	// ShuffleWriteBytes is the number of shuffle bytes the stages of the run wrote.
+	ShuffleWriteBytes int64 `json:"shuffleWriteBytes,omitempty"`
+	// SnapshotTime is the time the driver last reported the usage.
+	SnapshotTime metav1.Time `json:"snapshotTime,omitempty"`
+}
+
+// StageProgress describes the progress of a running stage.
+type StageProgress struct {
+	// StageID is the ID of the stage.
+	StageID int32 `json:"stageId"`
+	// Name is the name of the stage.
+	Name string `json:"name"`
+	// CompletedTasks is the number of completed tasks of the stage.
+	CompletedTasks int32 `json:"completedTasks"`
+	// TotalTasks is the total number of tasks of the stage.
+	TotalTasks int32 `json:"totalTasks"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -344,39 +1500,98 @@ type SparkPodSpec struct {
 	// MemoryOverhead is the amount of off-heap memory to allocate in cluster mode, in MiB unless otherwise specified.
 	// Optional.
 	MemoryOverhead *string `json:"memoryOverhead,omitempty"`
+	// EphemeralStorage is the amount of local ephemeral storage to request for the pod, e.g., "10Gi".
+	// Optional.
+	EphemeralStorage *string `json:"ephemeralStorage,omitempty"`
+	// EphemeralStorageLimit specifies a hard limit on local ephemeral storage for the pod.
+	// Optional.
+	EphemeralStorageLimit *string `json:"ephemeralStorageLimit,omitempty"`
 	// Image is the container image to use. Overrides Spec.Image if set.
 	// Optional.
 	Image *string `json:"image,omitempty"`
 	// ConfigMaps carries information of other ConfigMaps to add to the pod.
 	// Optional.
+	// +listType=atomic
 	ConfigMaps []NamePath `json:"configMaps,omitempty"`
 	// Secrets carries information of secrets to add to the pod.
 	// Optional.
+	// +listType=atomic
 	Secrets []SecretInfo `json:"secrets,omitempty"`
 	// EnvVars carries the environment variables to add to the pod.
 	// Optional.
+	// +mapType=granular
 	EnvVars map[string]string `json:"envVars,omitempty"`
 	// EnvSecretKeyRefs holds a mapping from environment variable names to SecretKeyRefs.
 	// Optional.
+	// +mapType=granular
 	EnvSecretKeyRefs map[string]NameKey `json:"envSecretKeyRefs,omitempty"`
 	// Labels are the Kubernetes labels to be added to the pod.
 	// Optional.
+	// +mapType=granular
 	Labels map[string]string `json:"labels,omitempty"`
 	// Annotations are the Kubernetes annotations to be added to the pod.
 	// Optional.
+	// +mapType=granular
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// VolumeMounts specifies the volumes listed in ".spec.volumes" to mount into the main container's filesystem.
 	// Optional.
+	// +listType=map
+	// +listMapKey=mountPath
 	VolumeMounts []apiv1.VolumeMount `json:"volumeMounts,omitempty"`
 	// Affinity specifies the affinity/anti-affinity settings for the pod.
 	// Optional.
 	Affinity *apiv1.Affinity `json:"affinity,omitempty"`
 	// Tolerations specifies the tolerations listed in ".spec.tolerations" to be applied to the pod.
 	// Optional.
+	// +listType=atomic
 	Tolerations []apiv1.Toleration `json:"tolerations,omitempty"`
+	// NotReadyTolerationSeconds is how long the pod stays bound to a node that has become not ready before it
+	// gets evicted. Replaces the cluster default of the node.kubernetes.io/not-ready toleration, e.g., to let
+	// the driver fail over quickly while executors wait out short node outages.
+	// Optional.
+	NotReadyTolerationSeconds *int64 `json:"notReadyTolerationSeconds,omitempty"`
+	// UnreachableTolerationSeconds is how long the pod stays bound to a node that has become unreachable before
+	// it gets evicted. Replaces the cluster default of the node.kubernetes.io/unreachable toleration.
+	// Optional.
+	UnreachableTolerationSeconds *int64 `json:"unreachableTolerationSeconds,omitempty"`
 	// SecurityContenxt specifies the PodSecurityContext to apply.
 	// Optional.
 	SecurityContenxt *apiv1.PodSecurityContext `json:"securityContext,omitempty"`
+	// SeccompProfile is the seccomp profile to apply to the pod, e.g., "runtime/default" or
+	// "localhost/<profile>". Overrides the default seccomp profile configured for the operator.
+	// Optional.
+	SeccompProfile *string `json:"seccompProfile,omitempty"`
+	// AppArmorProfile is the AppArmor profile to apply to the Spark container of the pod, e.g.,
+	// "runtime/default" or "localhost/<profile>". Overrides the default AppArmor profile configured
+	// for the operator.
+	// Optional.
+	AppArmorProfile *string `json:"appArmorProfile,omitempty"`
+	// RequireNodeFeatures are the features the node of the pod must have, as advertised by Node Feature Discovery,
+	// e.g., "local-nvme" for nodes labeled with feature.node.kubernetes.io/local-nvme=true. A feature may be given
+	// as "<name>=<value>" to require another value, and a name with a prefix, e.g., "example.com/gpu", is used
+	// as the label as is.
+	// Optional.
+	RequireNodeFeatures []string `json:"requireNodeFeatures,omitempty"`
+	// Networks are the secondary networks attached to the pod by Multus, e.g., an SR-IOV or RDMA network for a
+	// fast data plane.
+	// Optional.
+	Networks []NetworkAttachment `json:"networks,omitempty"`
+}
+
+// NetworkAttachment describes a secondary network of a pod, as defined by a NetworkAttachmentDefinition.
+type NetworkAttachment struct {
+	// Name is the name of the NetworkAttachmentDefinition.
+	Name string `json:"name"`
+	// Namespace is the namespace of the NetworkAttachmentDefinition.
+	// Optional. Defaults to the namespace of the application.
+	Namespace *string `json:"namespace,omitempty"`
+	// Interface is the name of the network interface in the pod.
+	// Optional. Defaults to the name Multus chooses, e.g., "net1".
+	Interface *string `json:"interface,omitempty"`
+	// ResourceName is the extended resource of the devices the network needs, e.g., "mellanox.com/sriov", one of
+	// which is requested for the Spark container of the pod.
+	// Optional.
+	ResourceName *string `json:"resourceName,omitempty"`
 }
 
 // DriverSpec is specification of the driver.
@@ -394,6 +1609,37 @@ type DriverSpec struct {
 	// JavaOptions is a string of extra JVM options to pass to the driver. For instance,
 	// GC settings or other logging.
 	JavaOptions *string `json:"javaOptions,omitempty"`
+	// UIProxy specifies an OAuth2 proxy sidecar that authenticates requests to the driver UI.
+	// Optional.
+	UIProxy *UIProxySpec `json:"uiProxy,omitempty"`
+	// TerminationGracePeriodSeconds is how long the driver gets to shut down cleanly, e.g., to commit or clean
+	// up its output, when the operator deletes the driver pod.
+	// Optional. Defaults to the Kubernetes default of 30 seconds.
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// UIProxySpec is specification of the OAuth2 proxy sidecar in front of the driver UI. Requests to the UI
+// Service and Ingress go through the proxy, which only lets users authenticated by the OIDC issuer through.
+type UIProxySpec struct {
+	// Image is the container image of the proxy.
+	// Optional. Defaults to an oauth2-proxy image.
+	Image *string `json:"image,omitempty"`
+	// Port is the port the proxy listens on.
+	// Optional. Defaults to 4180.
+	Port *int32 `json:"port,omitempty"`
+	// OIDCIssuerURL is the URL of the OpenID Connect issuer used to authenticate users.
+	OIDCIssuerURL string `json:"oidcIssuerURL"`
+	// ClientID is the OAuth2 client ID registered with the issuer.
+	ClientID string `json:"clientID"`
+	// SecretName is the name of a secret in the namespace of the application holding the OAuth2 client
+	// secret under key "client-secret" and the secret used to sign cookies under key "cookie-secret".
+	SecretName string `json:"secretName"`
+	// AllowedGroups restricts access to users in any of the given groups.
+	// Optional. Defaults to all authenticated users.
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+	// EmailDomains restricts access to users with an email address in any of the given domains.
+	// Optional. Defaults to all domains.
+	EmailDomains []string `json:"emailDomains,omitempty"`
 }
 
 // ExecutorSpec is specification of the executor.
@@ -408,6 +1654,251 @@ type ExecutorSpec struct {
 	// JavaOptions is a string of extra JVM options to pass to the executors. For instance,
 	// GC settings or other logging.
 	JavaOptions *string `json:"javaOptions,omitempty"`
+	// LocalDirs are the volumes the executors use as Spark local directories for shuffle and spill data,
+	// instead of the ephemeral storage of the executor pods.
+	// Optional.
+	LocalDirs []LocalDir `json:"localDirs,omitempty"`
+}
+
+// ExecutorGroup is a group of executors with their own resources and placement. The executors of a group are
+// the ones Spark launches for a resource profile the application builds from the Spark configuration properties
+// describing the group.
+type ExecutorGroup struct {
+	// Name is the name of the group, which the executor pods of the group are labeled with.
+	Name string `json:"name"`
+	// ResourceProfileID is the ID of the Spark resource profile of the group. Spark assigns IDs to the resource
+	// profiles an application builds in the order it builds them, starting at 1.
+	// Optional. Defaults to the position of the group in ExecutorGroups, starting at 1.
+	ResourceProfileID *int32 `json:"resourceProfileId,omitempty"`
+	// Instances is the number of executors of the group.
+	// Optional.
+	Instances *int32 `json:"instances,omitempty"`
+	// Cores is the number of cores of each executor of the group.
+	// Optional.
+	Cores *int32 `json:"cores,omitempty"`
+	// CoreRequest is the physical CPU core request of the executor pods of the group.
+	// Optional.
+	CoreRequest *string `json:"coreRequest,omitempty"`
+	// CoreLimit is a hard limit on CPU cores of the executor pods of the group.
+	// Optional.
+	CoreLimit *string `json:"coreLimit,omitempty"`
+	// Memory is the amount of memory of each executor of the group, e.g., "8g".
+	// Optional.
+	Memory *string `json:"memory,omitempty"`
+	// MemoryOverhead is the amount of off-heap memory of each executor of the group.
+	// Optional.
+	MemoryOverhead *string `json:"memoryOverhead,omitempty"`
+	// GPU is the GPUs of each executor of the group.
+	// Optional.
+	GPU *GPUSpec `json:"gpu,omitempty"`
+	// NodeSelector is added to the node selector of the executor pods of the group.
+	// Optional.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are added to the executor pods of the group.
+	// Optional.
+	// +listType=atomic
+	Tolerations []apiv1.Toleration `json:"tolerations,omitempty"`
+	// Affinity replaces Executor.Affinity for the executor pods of the group.
+	// Optional.
+	Affinity *apiv1.Affinity `json:"affinity,omitempty"`
+	// Labels are added to the executor pods of the group.
+	// Optional.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// GPUSpec describes the GPUs of an executor.
+type GPUSpec struct {
+	// Name is the name of the extended resource of the GPUs, e.g., "nvidia.com/gpu".
+	Name string `json:"name"`
+	// Quantity is the number of GPUs.
+	Quantity int64 `json:"quantity"`
+}
+
+// MLModeSpec describes how the executors of a distributed training job are run.
+type MLModeSpec struct {
+	// GPU is the GPUs of each executor, which are all assigned to the training process of the executor.
+	// Optional.
+	GPU *GPUSpec `json:"gpu,omitempty"`
+	// NetworkInterface is the network interface NCCL, Gloo, and MPI communicate over, e.g., "net1" of a
+	// secondary network of the executors.
+	// Optional.
+	NetworkInterface *string `json:"networkInterface,omitempty"`
+	// TopologyKey is the key of a node label whose values group nodes with a fast interconnect, e.g., an NVLink
+	// domain or a rack. The executors are preferably placed on nodes with the same value.
+	// Optional.
+	TopologyKey *string `json:"topologyKey,omitempty"`
+}
+
+// GPUAccelerationSpec describes the GPUs of the executors of an application and how Spark uses them.
+type GPUAccelerationSpec struct {
+	// Enabled tells whether the executors get GPUs.
+	Enabled bool `json:"enabled"`
+	// Name is the name of the extended resource of the GPUs.
+	// Optional. Defaults to "nvidia.com/gpu".
+	Name *string `json:"name,omitempty"`
+	// Quantity is the number of GPUs of each executor.
+	// Optional. Defaults to 1.
+	Quantity *int64 `json:"quantity,omitempty"`
+	// TaskAmount is the amount of GPUs of each task, e.g., "0.25" for four tasks sharing a GPU.
+	// Optional. Defaults to the GPUs of an executor divided by the number of its concurrent tasks.
+	TaskAmount *string `json:"taskAmount,omitempty"`
+	// Rapids enables the RAPIDS Accelerator for Apache Spark, whose plugin runs SQL and DataFrame operations on
+	// the GPUs.
+	// Optional. Defaults to false.
+	Rapids *bool `json:"rapids,omitempty"`
+	// RapidsJar is the location of the jar of the RAPIDS Accelerator, e.g., a URL of a version of it.
+	// Optional. Defaults to version 23.02.0 from Maven Central.
+	RapidsJar *string `json:"rapidsJar,omitempty"`
+}
+
+// DebugSpec describes the JVM diagnostics captured from the driver and executors of an application.
+type DebugSpec struct {
+	// ClaimName is the name of the PersistentVolumeClaim the dumps are written to, which is mounted into the
+	// driver and executor pods. Pods of a run on different nodes share it, so it should be ReadWriteMany.
+	ClaimName string `json:"claimName"`
+	// HeapDumpOnOutOfMemory tells the JVMs to dump their heap when they run out of memory.
+	// Optional. Defaults to true.
+	HeapDumpOnOutOfMemory *bool `json:"heapDumpOnOutOfMemory,omitempty"`
+	// FlightRecorder tells the JVMs to record with the JDK Flight Recorder, whose recording is dumped when they
+	// exit. Requires Java 11, or Java 8u262 or later.
+	// Optional. Defaults to false.
+	FlightRecorder *bool `json:"flightRecorder,omitempty"`
+	// UploadPath is the URI of a directory of a Hadoop-compatible file system, e.g., "s3a://bucket/dumps", the
+	// dumps of a run in which the driver or an executor ran out of memory are uploaded to once the run has ended.
+	// Optional. Dumps are only kept on the PersistentVolumeClaim if unset.
+	UploadPath *string `json:"uploadPath,omitempty"`
+	// Image is the container image of the Job uploading the dumps, which needs Spark and the Hadoop file system
+	// of UploadPath.
+	// Optional. Defaults to the image of the driver.
+	Image *string `json:"image,omitempty"`
+}
+
+// DataCacheSpec describes the node-local cache of datasets an application uses. The cache daemons run the image
+// with the argument "daemon" and keep the cached data in SPARK_DATA_CACHE_DIR, which they expose to the executors,
+// e.g., with a FUSE file system of a caching layer like Alluxio. The warmup Job runs the image with the argument
+// "warmup" followed by the paths to load into the cache.
+type DataCacheSpec struct {
+	// Name is the name of the cache. Applications in the same namespace using a cache with the same name share its
+	// daemons and cached data.
+	Name string `json:"name"`
+	// Image is the container image of the cache daemons and the warmup Job.
+	// Must be the image set with the flag -data-cache-image of the operator or one set with its flag
+	// -allowed-data-cache-images, as the cache daemons run privileged.
+	// Optional. Defaults to the image set with the flag -data-cache-image of the operator.
+	Image *string `json:"image,omitempty"`
+	// MountPath is the path the cache is mounted at in the executors.
+	// Optional. Defaults to "/var/cache/spark-data".
+	MountPath *string `json:"mountPath,omitempty"`
+	// SizeLimit is the amount of disk space the daemon on each node may use for the cache, e.g., "100Gi", which
+	// the daemons get in SPARK_DATA_CACHE_SIZE.
+	// Optional. The daemons decide if unset.
+	SizeLimit *string `json:"sizeLimit,omitempty"`
+	// WarmupPaths are the paths of datasets loaded into the cache when the application is submitted, e.g.,
+	// "s3a://bucket/tables/dim_customer".
+	// Optional.
+	// +listType=atomic
+	WarmupPaths []string `json:"warmupPaths,omitempty"`
+}
+
+// ConnectionPoolerSpec is specification of the PgBouncer sidecar of the driver and executors of an application.
+// The JDBC URLs in the Spark configuration properties the sidecar is used for are rewritten to point to it.
+type ConnectionPoolerSpec struct {
+	// Image is the container image of the sidecar, which is configured through the environment variables of the
+	// edoburu/pgbouncer image.
+	// Optional. Defaults to an edoburu/pgbouncer image.
+	Image *string `json:"image,omitempty"`
+	// Port is the port the sidecar listens on in the pods.
+	// Optional. Defaults to 6432.
+	Port *int32 `json:"port,omitempty"`
+	// DatabaseHost is the host of the database.
+	DatabaseHost string `json:"databaseHost"`
+	// DatabasePort is the port of the database.
+	// Optional. Defaults to 5432.
+	DatabasePort *int32 `json:"databasePort,omitempty"`
+	// SecretName is the name of a Secret in the namespace of the application holding the user and password the
+	// sidecar connects to the database with under keys "username" and "password".
+	SecretName string `json:"secretName"`
+	// PoolSize is the maximum number of connections of each pod to the database.
+	// Optional. Defaults to 2.
+	PoolSize *int32 `json:"poolSize,omitempty"`
+	// PoolMode is when connections are returned to the pool, i.e., "session", "transaction" or "statement".
+	// Optional. Defaults to "transaction".
+	PoolMode *string `json:"poolMode,omitempty"`
+	// URLConfKeys are the Spark configuration properties holding the JDBC URLs to rewrite, which may also be set
+	// in hadoopConf without the "spark.hadoop." prefix.
+	// Optional. Defaults to the URL of the Hive metastore database, i.e., spark.hadoop.javax.jdo.option.ConnectionURL.
+	URLConfKeys []string `json:"urlConfKeys,omitempty"`
+}
+
+// AutoTuningSpec describes how the shuffle partitions and executors of an application are sized from its input.
+type AutoTuningSpec struct {
+	// InputPaths are the gs:// or s3:// URLs of the input of the application. The sizes of the objects with the
+	// paths of the URLs as prefix are summed up.
+	InputPaths []string `json:"inputPaths"`
+	// BytesPerPartition is the input size per shuffle partition, e.g., 128Mi.
+	// Optional. Defaults to 128Mi.
+	BytesPerPartition *string `json:"bytesPerPartition,omitempty"`
+	// BytesPerExecutor is the input size per executor, e.g., 4Gi.
+	// Optional. Defaults to 4Gi.
+	BytesPerExecutor *string `json:"bytesPerExecutor,omitempty"`
+	// MinExecutors is the lower bound of the number of executors.
+	// Optional. Defaults to 1.
+	MinExecutors *int32 `json:"minExecutors,omitempty"`
+	// MaxExecutors is the upper bound of the number of executors.
+	// Optional. Defaults to no bound.
+	MaxExecutors *int32 `json:"maxExecutors,omitempty"`
+}
+
+// ResourceProfile describes the resources of the executors and tasks of a Spark resource profile.
+type ResourceProfile struct {
+	// ID is the ID Spark assigns to the resource profile. Spark assigns IDs to the resource profiles an
+	// application builds in the order it builds them, starting at 1.
+	ID int32 `json:"id"`
+	// Cores is the number of cores of each executor.
+	// Optional.
+	Cores *int32 `json:"cores,omitempty"`
+	// CoreRequest is the physical CPU core request of the executor pods.
+	// Optional.
+	CoreRequest *string `json:"coreRequest,omitempty"`
+	// CoreLimit is a hard limit on CPU cores of the executor pods.
+	// Optional.
+	CoreLimit *string `json:"coreLimit,omitempty"`
+	// Memory is the amount of memory of each executor, e.g., "8g".
+	// Optional.
+	Memory *string `json:"memory,omitempty"`
+	// MemoryOverhead is the amount of off-heap memory of each executor.
+	// Optional.
+	MemoryOverhead *string `json:"memoryOverhead,omitempty"`
+	// EphemeralStorage is the amount of local ephemeral storage to request for the executor pods.
+	// Optional.
+	EphemeralStorage *string `json:"ephemeralStorage,omitempty"`
+	// EphemeralStorageLimit specifies a hard limit on local ephemeral storage for the executor pods.
+	// Optional.
+	EphemeralStorageLimit *string `json:"ephemeralStorageLimit,omitempty"`
+	// GPU is the GPUs of each executor.
+	// Optional.
+	GPU *GPUSpec `json:"gpu,omitempty"`
+	// TaskCPUs is the number of cores of each task.
+	// Optional.
+	TaskCPUs *int32 `json:"taskCpus,omitempty"`
+}
+
+// LocalDir is a volume mounted into the executors as a Spark local directory. Exactly one of EmptyDir,
+// HostPath, and PersistentVolumeClaim must be set.
+type LocalDir struct {
+	// Name is the name of the volume.
+	Name string `json:"name"`
+	// MountPath is the path in the executor containers the volume is mounted to.
+	MountPath string `json:"mountPath"`
+	// EmptyDir is an emptyDir volume, e.g., with medium Memory for a tmpfs-backed local directory.
+	// Optional.
+	EmptyDir *apiv1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+	// HostPath is a directory on the host the executor pods run on.
+	// Optional.
+	HostPath *apiv1.HostPathVolumeSource `json:"hostPath,omitempty"`
+	// PersistentVolumeClaim is an existing PersistentVolumeClaim.
+	// Optional.
+	PersistentVolumeClaim *apiv1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
 }
 
 // NamePath is a pair of a name and a path to which the named objects should be mounted to.
@@ -442,6 +1933,14 @@ type DriverInfo struct {
 	WebUIIngressName    string `json:"webUIIngressName,omitempty"`
 	WebUIIngressAddress string `json:"webUIIngressAddress,omitempty"`
 	PodName             string `json:"podName,omitempty"`
+	// Details of the resources provisioned for an external driver.
+	// ServiceName is the name of the headless Service executors reach the external driver through.
+	ServiceName string `json:"serviceName,omitempty"`
+	// ServiceAccountName is the name of the ServiceAccount the operator created for the driver, which an external
+	// driver must authenticate as.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// SparkConf is the Spark configuration the external driver must be started with.
+	SparkConf map[string]string `json:"sparkConf,omitempty"`
 }
 
 // SecretInfo captures information of a secret.
@@ -470,6 +1969,42 @@ type MonitoringSpec struct {
 	// Prometheus is for configuring the Prometheus JMX exporter.
 	// Optional.
 	Prometheus *PrometheusSpec `json:"prometheus,omitempty"`
+	// MetricsSink is for configuring a sink of the Spark metric system the driver and executors report
+	// metrics to. Its configuration is added to the metrics.properties.
+	// Optional.
+	MetricsSink *MetricsSinkSpec `json:"metricsSink,omitempty"`
+}
+
+// MetricsSinkType describes the type of a sink of the Spark metric system.
+type MetricsSinkType string
+
+// Different types of metrics sinks.
+const (
+	StatsDSink            MetricsSinkType = "StatsD"
+	GraphiteSink          MetricsSinkType = "Graphite"
+	PrometheusServletSink MetricsSinkType = "PrometheusServlet"
+)
+
+// MetricsSinkSpec defines a sink of the Spark metric system.
+type MetricsSinkSpec struct {
+	// Type is the type of the sink.
+	Type MetricsSinkType `json:"type"`
+	// Host is the host of the StatsD daemon or Graphite server. Required for the Graphite sink.
+	// Optional.
+	Host *string `json:"host,omitempty"`
+	// Port is the port of the StatsD daemon or Graphite server. Required for the Graphite sink.
+	// Optional.
+	Port *int32 `json:"port,omitempty"`
+	// Period is the interval in seconds at which metrics are reported to the StatsD daemon or Graphite server.
+	// Optional.
+	Period *int32 `json:"period,omitempty"`
+	// Prefix is the prefix prepended to names of metrics reported to the StatsD daemon or Graphite server.
+	// Optional.
+	Prefix *string `json:"prefix,omitempty"`
+	// Path is the path of the driver UI at which the Prometheus servlet serves metrics.
+	// Optional.
+	// If not specified, "/metrics/prometheus" will be used as the default.
+	Path *string `json:"path,omitempty"`
 }
 
 // PrometheusSpec defines the Prometheus specification when Prometheus is to be used for
@@ -27,6 +27,45 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationCondition) DeepCopyInto(out *ApplicationCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationCondition.
+func (in *ApplicationCondition) DeepCopy() *ApplicationCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationProgress) DeepCopyInto(out *ApplicationProgress) {
+	*out = *in
+	if in.ActiveStages != nil {
+		in, out := &in.ActiveStages, &out.ActiveStages
+		*out = make([]StageProgress, len(*in))
+		copy(*out, *in)
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationProgress.
+func (in *ApplicationProgress) DeepCopy() *ApplicationProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationState) DeepCopyInto(out *ApplicationState) {
 	*out = *in
@@ -44,283 +83,2533 @@ func (in *ApplicationState) DeepCopy() *ApplicationState {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Dependencies) DeepCopyInto(out *Dependencies) {
+func (in *ApplicationUsage) DeepCopyInto(out *ApplicationUsage) {
 	*out = *in
-	if in.Jars != nil {
-		in, out := &in.Jars, &out.Jars
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	in.SnapshotTime.DeepCopyInto(&out.SnapshotTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationUsage.
+func (in *ApplicationUsage) DeepCopy() *ApplicationUsage {
+	if in == nil {
+		return nil
 	}
-	if in.PyFiles != nil {
-		in, out := &in.PyFiles, &out.PyFiles
+	out := new(ApplicationUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoTuningSpec) DeepCopyInto(out *AutoTuningSpec) {
+	*out = *in
+	if in.InputPaths != nil {
+		in, out := &in.InputPaths, &out.InputPaths
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.JarsDownloadDir != nil {
-		in, out := &in.JarsDownloadDir, &out.JarsDownloadDir
+	if in.BytesPerPartition != nil {
+		in, out := &in.BytesPerPartition, &out.BytesPerPartition
 		*out = new(string)
 		**out = **in
 	}
-	if in.FilesDownloadDir != nil {
-		in, out := &in.FilesDownloadDir, &out.FilesDownloadDir
+	if in.BytesPerExecutor != nil {
+		in, out := &in.BytesPerExecutor, &out.BytesPerExecutor
 		*out = new(string)
 		**out = **in
 	}
-	if in.DownloadTimeout != nil {
-		in, out := &in.DownloadTimeout, &out.DownloadTimeout
+	if in.MinExecutors != nil {
+		in, out := &in.MinExecutors, &out.MinExecutors
 		*out = new(int32)
 		**out = **in
 	}
-	if in.MaxSimultaneousDownloads != nil {
-		in, out := &in.MaxSimultaneousDownloads, &out.MaxSimultaneousDownloads
+	if in.MaxExecutors != nil {
+		in, out := &in.MaxExecutors, &out.MaxExecutors
 		*out = new(int32)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependencies.
-func (in *Dependencies) DeepCopy() *Dependencies {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoTuningSpec.
+func (in *AutoTuningSpec) DeepCopy() *AutoTuningSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Dependencies)
+	out := new(AutoTuningSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DriverInfo) DeepCopyInto(out *DriverInfo) {
+func (in *AutoTuningStatus) DeepCopyInto(out *AutoTuningStatus) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverInfo.
-func (in *DriverInfo) DeepCopy() *DriverInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoTuningStatus.
+func (in *AutoTuningStatus) DeepCopy() *AutoTuningStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DriverInfo)
+	out := new(AutoTuningStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DriverSpec) DeepCopyInto(out *DriverSpec) {
+func (in *CloudIdentitySpec) DeepCopyInto(out *CloudIdentitySpec) {
 	*out = *in
-	in.SparkPodSpec.DeepCopyInto(&out.SparkPodSpec)
-	if in.PodName != nil {
-		in, out := &in.PodName, &out.PodName
+	if in.AWSRoleARN != nil {
+		in, out := &in.AWSRoleARN, &out.AWSRoleARN
 		*out = new(string)
 		**out = **in
 	}
-	if in.ServiceAccount != nil {
-		in, out := &in.ServiceAccount, &out.ServiceAccount
+	if in.GCPServiceAccount != nil {
+		in, out := &in.GCPServiceAccount, &out.GCPServiceAccount
 		*out = new(string)
 		**out = **in
 	}
-	if in.JavaOptions != nil {
-		in, out := &in.JavaOptions, &out.JavaOptions
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudIdentitySpec.
+func (in *CloudIdentitySpec) DeepCopy() *CloudIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionPoolerSpec) DeepCopyInto(out *ConnectionPoolerSpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DatabasePort != nil {
+		in, out := &in.DatabasePort, &out.DatabasePort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PoolSize != nil {
+		in, out := &in.PoolSize, &out.PoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PoolMode != nil {
+		in, out := &in.PoolMode, &out.PoolMode
 		*out = new(string)
 		**out = **in
 	}
+	if in.URLConfKeys != nil {
+		in, out := &in.URLConfKeys, &out.URLConfKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverSpec.
-func (in *DriverSpec) DeepCopy() *DriverSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionPoolerSpec.
+func (in *ConnectionPoolerSpec) DeepCopy() *ConnectionPoolerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DriverSpec)
+	out := new(ConnectionPoolerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
+func (in *DataCacheSpec) DeepCopyInto(out *DataCacheSpec) {
 	*out = *in
-	in.SparkPodSpec.DeepCopyInto(&out.SparkPodSpec)
-	if in.Instances != nil {
-		in, out := &in.Instances, &out.Instances
-		*out = new(int32)
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
 		**out = **in
 	}
-	if in.CoreRequest != nil {
-		in, out := &in.CoreRequest, &out.CoreRequest
+	if in.MountPath != nil {
+		in, out := &in.MountPath, &out.MountPath
 		*out = new(string)
 		**out = **in
 	}
-	if in.JavaOptions != nil {
-		in, out := &in.JavaOptions, &out.JavaOptions
+	if in.SizeLimit != nil {
+		in, out := &in.SizeLimit, &out.SizeLimit
 		*out = new(string)
 		**out = **in
 	}
+	if in.WarmupPaths != nil {
+		in, out := &in.WarmupPaths, &out.WarmupPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorSpec.
-func (in *ExecutorSpec) DeepCopy() *ExecutorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataCacheSpec.
+func (in *DataCacheSpec) DeepCopy() *DataCacheSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ExecutorSpec)
+	out := new(DataCacheSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+func (in *DebugSpec) DeepCopyInto(out *DebugSpec) {
 	*out = *in
-	if in.MetricsProperties != nil {
-		in, out := &in.MetricsProperties, &out.MetricsProperties
+	if in.HeapDumpOnOutOfMemory != nil {
+		in, out := &in.HeapDumpOnOutOfMemory, &out.HeapDumpOnOutOfMemory
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FlightRecorder != nil {
+		in, out := &in.FlightRecorder, &out.FlightRecorder
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UploadPath != nil {
+		in, out := &in.UploadPath, &out.UploadPath
 		*out = new(string)
 		**out = **in
 	}
-	if in.Prometheus != nil {
-		in, out := &in.Prometheus, &out.Prometheus
-		*out = new(PrometheusSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
-func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugSpec.
+func (in *DebugSpec) DeepCopy() *DebugSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringSpec)
+	out := new(DebugSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NameKey) DeepCopyInto(out *NameKey) {
+func (in *Dependencies) DeepCopyInto(out *Dependencies) {
 	*out = *in
+	if in.Jars != nil {
+		in, out := &in.Jars, &out.Jars
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PyFiles != nil {
+		in, out := &in.PyFiles, &out.PyFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JarsDownloadDir != nil {
+		in, out := &in.JarsDownloadDir, &out.JarsDownloadDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.FilesDownloadDir != nil {
+		in, out := &in.FilesDownloadDir, &out.FilesDownloadDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.DownloadTimeout != nil {
+		in, out := &in.DownloadTimeout, &out.DownloadTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSimultaneousDownloads != nil {
+		in, out := &in.MaxSimultaneousDownloads, &out.MaxSimultaneousDownloads
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameKey.
-func (in *NameKey) DeepCopy() *NameKey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependencies.
+func (in *Dependencies) DeepCopy() *Dependencies {
 	if in == nil {
 		return nil
 	}
-	out := new(NameKey)
+	out := new(Dependencies)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamePath) DeepCopyInto(out *NamePath) {
+func (in *DriverInfo) DeepCopyInto(out *DriverInfo) {
 	*out = *in
+	if in.SparkConf != nil {
+		in, out := &in.SparkConf, &out.SparkConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamePath.
-func (in *NamePath) DeepCopy() *NamePath {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverInfo.
+func (in *DriverInfo) DeepCopy() *DriverInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(NamePath)
+	out := new(DriverInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
+func (in *DriverLogCaptureSpec) DeepCopyInto(out *DriverLogCaptureSpec) {
 	*out = *in
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
+	if in.MaxKB != nil {
+		in, out := &in.MaxKB, &out.MaxKB
 		*out = new(int32)
 		**out = **in
 	}
-	if in.ConfigFile != nil {
-		in, out := &in.ConfigFile, &out.ConfigFile
-		*out = new(string)
-		**out = **in
-	}
-	if in.Configuration != nil {
-		in, out := &in.Configuration, &out.Configuration
-		*out = new(string)
-		**out = **in
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSpec.
-func (in *PrometheusSpec) DeepCopy() *PrometheusSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverLogCaptureSpec.
+func (in *DriverLogCaptureSpec) DeepCopy() *DriverLogCaptureSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusSpec)
+	out := new(DriverLogCaptureSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RestartPolicy) DeepCopyInto(out *RestartPolicy) {
+func (in *DriverSpec) DeepCopyInto(out *DriverSpec) {
 	*out = *in
-	if in.OnSubmissionFailureRetries != nil {
-		in, out := &in.OnSubmissionFailureRetries, &out.OnSubmissionFailureRetries
-		*out = new(int32)
+	in.SparkPodSpec.DeepCopyInto(&out.SparkPodSpec)
+	if in.PodName != nil {
+		in, out := &in.PodName, &out.PodName
+		*out = new(string)
 		**out = **in
 	}
-	if in.OnFailureRetries != nil {
-		in, out := &in.OnFailureRetries, &out.OnFailureRetries
-		*out = new(int32)
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(string)
 		**out = **in
 	}
-	if in.OnSubmissionFailureRetryInterval != nil {
-		in, out := &in.OnSubmissionFailureRetryInterval, &out.OnSubmissionFailureRetryInterval
-		*out = new(int64)
+	if in.JavaOptions != nil {
+		in, out := &in.JavaOptions, &out.JavaOptions
+		*out = new(string)
 		**out = **in
 	}
-	if in.OnFailureRetryInterval != nil {
-		in, out := &in.OnFailureRetryInterval, &out.OnFailureRetryInterval
+	if in.UIProxy != nil {
+		in, out := &in.UIProxy, &out.UIProxy
+		*out = new(UIProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
 		*out = new(int64)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartPolicy.
-func (in *RestartPolicy) DeepCopy() *RestartPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverSpec.
+func (in *DriverSpec) DeepCopy() *DriverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RestartPolicy)
+	out := new(DriverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduledSparkApplication) DeepCopyInto(out *ScheduledSparkApplication) {
+func (in *DumpStatus) DeepCopyInto(out *DumpStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
-	return
-}
+	if in.OutOfMemoryPods != nil {
+		in, out := &in.OutOfMemoryPods, &out.OutOfMemoryPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DumpStatus.
+func (in *DumpStatus) DeepCopy() *DumpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DumpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorGroup) DeepCopyInto(out *ExecutorGroup) {
+	*out = *in
+	if in.ResourceProfileID != nil {
+		in, out := &in.ResourceProfileID, &out.ResourceProfileID
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Cores != nil {
+		in, out := &in.Cores, &out.Cores
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CoreRequest != nil {
+		in, out := &in.CoreRequest, &out.CoreRequest
+		*out = new(string)
+		**out = **in
+	}
+	if in.CoreLimit != nil {
+		in, out := &in.CoreLimit, &out.CoreLimit
+		*out = new(string)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemoryOverhead != nil {
+		in, out := &in.MemoryOverhead, &out.MemoryOverhead
+		*out = new(string)
+		**out = **in
+	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(GPUSpec)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorGroup.
+func (in *ExecutorGroup) DeepCopy() *ExecutorGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorIdleTimeoutSpec) DeepCopyInto(out *ExecutorIdleTimeoutSpec) {
+	*out = *in
+	if in.CPUThreshold != nil {
+		in, out := &in.CPUThreshold, &out.CPUThreshold
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinExecutors != nil {
+		in, out := &in.MinExecutors, &out.MinExecutors
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorIdleTimeoutSpec.
+func (in *ExecutorIdleTimeoutSpec) DeepCopy() *ExecutorIdleTimeoutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorIdleTimeoutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorRestartStatus) DeepCopyInto(out *ExecutorRestartStatus) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+	in.LastWaveTime.DeepCopyInto(&out.LastWaveTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorRestartStatus.
+func (in *ExecutorRestartStatus) DeepCopy() *ExecutorRestartStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorRestartStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
+	*out = *in
+	in.SparkPodSpec.DeepCopyInto(&out.SparkPodSpec)
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CoreRequest != nil {
+		in, out := &in.CoreRequest, &out.CoreRequest
+		*out = new(string)
+		**out = **in
+	}
+	if in.JavaOptions != nil {
+		in, out := &in.JavaOptions, &out.JavaOptions
+		*out = new(string)
+		**out = **in
+	}
+	if in.LocalDirs != nil {
+		in, out := &in.LocalDirs, &out.LocalDirs
+		*out = make([]LocalDir, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorSpec.
+func (in *ExecutorSpec) DeepCopy() *ExecutorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDriverSpec) DeepCopyInto(out *ExternalDriverSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BlockManagerPort != nil {
+		in, out := &in.BlockManagerPort, &out.BlockManagerPort
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDriverSpec.
+func (in *ExternalDriverSpec) DeepCopy() *ExternalDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDriverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldChange) DeepCopyInto(out *FieldChange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldChange.
+func (in *FieldChange) DeepCopy() *FieldChange {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUAccelerationSpec) DeepCopyInto(out *GPUAccelerationSpec) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Quantity != nil {
+		in, out := &in.Quantity, &out.Quantity
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TaskAmount != nil {
+		in, out := &in.TaskAmount, &out.TaskAmount
+		*out = new(string)
+		**out = **in
+	}
+	if in.Rapids != nil {
+		in, out := &in.Rapids, &out.Rapids
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RapidsJar != nil {
+		in, out := &in.RapidsJar, &out.RapidsJar
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUAccelerationSpec.
+func (in *GPUAccelerationSpec) DeepCopy() *GPUAccelerationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUAccelerationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUSpec) DeepCopyInto(out *GPUSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUSpec.
+func (in *GPUSpec) DeepCopy() *GPUSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSource) DeepCopyInto(out *GitSource) {
+	*out = *in
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSource.
+func (in *GitSource) DeepCopy() *GitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopCluster) DeepCopyInto(out *HadoopCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopCluster.
+func (in *HadoopCluster) DeepCopy() *HadoopCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HadoopCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopClusterList) DeepCopyInto(out *HadoopClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HadoopCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopClusterList.
+func (in *HadoopClusterList) DeepCopy() *HadoopClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HadoopClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopClusterSpec) DeepCopyInto(out *HadoopClusterSpec) {
+	*out = *in
+	if in.NameNodes != nil {
+		in, out := &in.NameNodes, &out.NameNodes
+		*out = make([]HadoopNameNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RPCProtection != nil {
+		in, out := &in.RPCProtection, &out.RPCProtection
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(HadoopKerberosSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CoreSite != nil {
+		in, out := &in.CoreSite, &out.CoreSite
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HDFSSite != nil {
+		in, out := &in.HDFSSite, &out.HDFSSite
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopClusterSpec.
+func (in *HadoopClusterSpec) DeepCopy() *HadoopClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopKerberosSpec) DeepCopyInto(out *HadoopKerberosSpec) {
+	*out = *in
+	if in.NameNodePrincipal != nil {
+		in, out := &in.NameNodePrincipal, &out.NameNodePrincipal
+		*out = new(string)
+		**out = **in
+	}
+	if in.KDCs != nil {
+		in, out := &in.KDCs, &out.KDCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopKerberosSpec.
+func (in *HadoopKerberosSpec) DeepCopy() *HadoopKerberosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopKerberosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopNameNode) DeepCopyInto(out *HadoopNameNode) {
+	*out = *in
+	if in.HTTPAddress != nil {
+		in, out := &in.HTTPAddress, &out.HTTPAddress
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopNameNode.
+func (in *HadoopNameNode) DeepCopy() *HadoopNameNode {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopNameNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestJob) DeepCopyInto(out *IngestJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestJob.
+func (in *IngestJob) DeepCopy() *IngestJob {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IngestJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestJobList) DeepCopyInto(out *IngestJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IngestJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestJobList.
+func (in *IngestJobList) DeepCopy() *IngestJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IngestJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestJobSpec) DeepCopyInto(out *IngestJobSpec) {
+	*out = *in
+	if in.JDBC != nil {
+		in, out := &in.JDBC, &out.JDBC
+		*out = new(JDBCSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	in.Deps.DeepCopyInto(&out.Deps)
+	if in.SparkConf != nil {
+		in, out := &in.SparkConf, &out.SparkConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HadoopConf != nil {
+		in, out := &in.HadoopConf, &out.HadoopConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Driver.DeepCopyInto(&out.Driver)
+	in.Executor.DeepCopyInto(&out.Executor)
+	in.RestartPolicy.DeepCopyInto(&out.RestartPolicy)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestJobSpec.
+func (in *IngestJobSpec) DeepCopy() *IngestJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestJobStatus) DeepCopyInto(out *IngestJobStatus) {
+	*out = *in
+	out.AppState = in.AppState
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestJobStatus.
+func (in *IngestJobStatus) DeepCopy() *IngestJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JDBCSource) DeepCopyInto(out *JDBCSource) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.PartitionColumn != nil {
+		in, out := &in.PartitionColumn, &out.PartitionColumn
+		*out = new(string)
+		**out = **in
+	}
+	if in.LowerBound != nil {
+		in, out := &in.LowerBound, &out.LowerBound
+		*out = new(string)
+		**out = **in
+	}
+	if in.UpperBound != nil {
+		in, out := &in.UpperBound, &out.UpperBound
+		*out = new(string)
+		**out = **in
+	}
+	if in.NumPartitions != nil {
+		in, out := &in.NumPartitions, &out.NumPartitions
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JDBCSource.
+func (in *JDBCSource) DeepCopy() *JDBCSource {
+	if in == nil {
+		return nil
+	}
+	out := new(JDBCSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSource) DeepCopyInto(out *KafkaSource) {
+	*out = *in
+	if in.StartingOffsets != nil {
+		in, out := &in.StartingOffsets, &out.StartingOffsets
+		*out = new(string)
+		**out = **in
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CheckpointPath != nil {
+		in, out := &in.CheckpointPath, &out.CheckpointPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.TriggerInterval != nil {
+		in, out := &in.TriggerInterval, &out.TriggerInterval
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSource.
+func (in *KafkaSource) DeepCopy() *KafkaSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LaunchLatency) DeepCopyInto(out *LaunchLatency) {
+	*out = *in
+	in.DriverRunningTime.DeepCopyInto(&out.DriverRunningTime)
+	in.FirstExecutorRunningTime.DeepCopyInto(&out.FirstExecutorRunningTime)
+	if in.CreationToDriverRunningMillis != nil {
+		in, out := &in.CreationToDriverRunningMillis, &out.CreationToDriverRunningMillis
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DriverToFirstExecutorRunningMillis != nil {
+		in, out := &in.DriverToFirstExecutorRunningMillis, &out.DriverToFirstExecutorRunningMillis
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LaunchLatency.
+func (in *LaunchLatency) DeepCopy() *LaunchLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(LaunchLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalDir) DeepCopyInto(out *LocalDir) {
+	*out = *in
+	if in.EmptyDir != nil {
+		in, out := &in.EmptyDir, &out.EmptyDir
+		*out = new(v1.EmptyDirVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostPath != nil {
+		in, out := &in.HostPath, &out.HostPath
+		*out = new(v1.HostPathVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(v1.PersistentVolumeClaimVolumeSource)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDir.
+func (in *LocalDir) DeepCopy() *LocalDir {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalDir)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MLModeSpec) DeepCopyInto(out *MLModeSpec) {
+	*out = *in
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(GPUSpec)
+		**out = **in
+	}
+	if in.NetworkInterface != nil {
+		in, out := &in.NetworkInterface, &out.NetworkInterface
+		*out = new(string)
+		**out = **in
+	}
+	if in.TopologyKey != nil {
+		in, out := &in.TopologyKey, &out.TopologyKey
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MLModeSpec.
+func (in *MLModeSpec) DeepCopy() *MLModeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MLModeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryBumpPolicy) DeepCopyInto(out *MemoryBumpPolicy) {
+	*out = *in
+	if in.Factor != nil {
+		in, out := &in.Factor, &out.Factor
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxDriverMemory != nil {
+		in, out := &in.MaxDriverMemory, &out.MaxDriverMemory
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxExecutorMemory != nil {
+		in, out := &in.MaxExecutorMemory, &out.MaxExecutorMemory
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryBumpPolicy.
+func (in *MemoryBumpPolicy) DeepCopy() *MemoryBumpPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryBumpPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryBumpStatus) DeepCopyInto(out *MemoryBumpStatus) {
+	*out = *in
+	in.LastBumpTime.DeepCopyInto(&out.LastBumpTime)
+	if in.DriverMemory != nil {
+		in, out := &in.DriverMemory, &out.DriverMemory
+		*out = new(string)
+		**out = **in
+	}
+	if in.DriverMemoryOverhead != nil {
+		in, out := &in.DriverMemoryOverhead, &out.DriverMemoryOverhead
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExecutorMemory != nil {
+		in, out := &in.ExecutorMemory, &out.ExecutorMemory
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExecutorMemoryOverhead != nil {
+		in, out := &in.ExecutorMemoryOverhead, &out.ExecutorMemoryOverhead
+		*out = new(string)
+		**out = **in
+	}
+	if in.OutOfMemory != nil {
+		in, out := &in.OutOfMemory, &out.OutOfMemory
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryBumpStatus.
+func (in *MemoryBumpStatus) DeepCopy() *MemoryBumpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryBumpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPropagationSpec) DeepCopyInto(out *MetadataPropagationSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPropagationSpec.
+func (in *MetadataPropagationSpec) DeepCopy() *MetadataPropagationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPropagationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsSinkSpec) DeepCopyInto(out *MetricsSinkSpec) {
+	*out = *in
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsSinkSpec.
+func (in *MetricsSinkSpec) DeepCopy() *MetricsSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.MetricsProperties != nil {
+		in, out := &in.MetricsProperties, &out.MetricsProperties
+		*out = new(string)
+		**out = **in
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(PrometheusSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsSink != nil {
+		in, out := &in.MetricsSink, &out.MetricsSink
+		*out = new(MetricsSinkSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NameKey) DeepCopyInto(out *NameKey) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameKey.
+func (in *NameKey) DeepCopy() *NameKey {
+	if in == nil {
+		return nil
+	}
+	out := new(NameKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamePath) DeepCopyInto(out *NamePath) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamePath.
+func (in *NamePath) DeepCopy() *NamePath {
+	if in == nil {
+		return nil
+	}
+	out := new(NamePath)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkAttachment) DeepCopyInto(out *NetworkAttachment) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.Interface != nil {
+		in, out := &in.Interface, &out.Interface
+		*out = new(string)
+		**out = **in
+	}
+	if in.ResourceName != nil {
+		in, out := &in.ResourceName, &out.ResourceName
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkAttachment.
+func (in *NetworkAttachment) DeepCopy() *NetworkAttachment {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkAttachment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSpec) DeepCopyInto(out *NotificationSpec) {
+	*out = *in
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.OutputPaths != nil {
+		in, out := &in.OutputPaths, &out.OutputPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SLASeconds != nil {
+		in, out := &in.SLASeconds, &out.SLASeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSpec.
+func (in *NotificationSpec) DeepCopy() *NotificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCISource) DeepCopyInto(out *OCISource) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCISource.
+func (in *OCISource) DeepCopy() *OCISource {
+	if in == nil {
+		return nil
+	}
+	out := new(OCISource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorDefaults) DeepCopyInto(out *OperatorDefaults) {
+	*out = *in
+	if in.SparkConf != nil {
+		in, out := &in.SparkConf, &out.SparkConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PropagatedLabels != nil {
+		in, out := &in.PropagatedLabels, &out.PropagatedLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PropagatedAnnotations != nil {
+		in, out := &in.PropagatedAnnotations, &out.PropagatedAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorDefaults.
+func (in *OperatorDefaults) DeepCopy() *OperatorDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorMetricsConfiguration) DeepCopyInto(out *OperatorMetricsConfiguration) {
+	*out = *in
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorMetricsConfiguration.
+func (in *OperatorMetricsConfiguration) DeepCopy() *OperatorMetricsConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorMetricsConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorProxyUserConfiguration) DeepCopyInto(out *OperatorProxyUserConfiguration) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Superuser != nil {
+		in, out := &in.Superuser, &out.Superuser
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorProxyUserConfiguration.
+func (in *OperatorProxyUserConfiguration) DeepCopy() *OperatorProxyUserConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorProxyUserConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorQueueingConfiguration) DeepCopyInto(out *OperatorQueueingConfiguration) {
+	*out = *in
+	if in.MaxRunningApplications != nil {
+		in, out := &in.MaxRunningApplications, &out.MaxRunningApplications
+		*out = new(int32)
+		**out = **in
+	}
+	if in.QueueWeights != nil {
+		in, out := &in.QueueWeights, &out.QueueWeights
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxPriorities != nil {
+		in, out := &in.MaxPriorities, &out.MaxPriorities
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorQueueingConfiguration.
+func (in *OperatorQueueingConfiguration) DeepCopy() *OperatorQueueingConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorQueueingConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorWebhookConfiguration) DeepCopyInto(out *OperatorWebhookConfiguration) {
+	*out = *in
+	if in.DefaultSeccompProfile != nil {
+		in, out := &in.DefaultSeccompProfile, &out.DefaultSeccompProfile
+		*out = new(string)
+		**out = **in
+	}
+	if in.DefaultAppArmorProfile != nil {
+		in, out := &in.DefaultAppArmorProfile, &out.DefaultAppArmorProfile
+		*out = new(string)
+		**out = **in
+	}
+	if in.EnforceLinuxNodes != nil {
+		in, out := &in.EnforceLinuxNodes, &out.EnforceLinuxNodes
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorWebhookConfiguration.
+func (in *OperatorWebhookConfiguration) DeepCopy() *OperatorWebhookConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorWebhookConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputCleanupSpec) DeepCopyInto(out *OutputCleanupSpec) {
+	*out = *in
+	if in.OutputPaths != nil {
+		in, out := &in.OutputPaths, &out.OutputPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputCleanupSpec.
+func (in *OutputCleanupSpec) DeepCopy() *OutputCleanupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputCleanupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConfigFile != nil {
+		in, out := &in.ConfigFile, &out.ConfigFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSpec.
+func (in *PrometheusSpec) DeepCopy() *PrometheusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcePressureSummary) DeepCopyInto(out *ResourcePressureSummary) {
+	*out = *in
+	in.LastSampleTime.DeepCopyInto(&out.LastSampleTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePressureSummary.
+func (in *ResourcePressureSummary) DeepCopy() *ResourcePressureSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourcePressureSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceProfile) DeepCopyInto(out *ResourceProfile) {
+	*out = *in
+	if in.Cores != nil {
+		in, out := &in.Cores, &out.Cores
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CoreRequest != nil {
+		in, out := &in.CoreRequest, &out.CoreRequest
+		*out = new(string)
+		**out = **in
+	}
+	if in.CoreLimit != nil {
+		in, out := &in.CoreLimit, &out.CoreLimit
+		*out = new(string)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemoryOverhead != nil {
+		in, out := &in.MemoryOverhead, &out.MemoryOverhead
+		*out = new(string)
+		**out = **in
+	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(string)
+		**out = **in
+	}
+	if in.EphemeralStorageLimit != nil {
+		in, out := &in.EphemeralStorageLimit, &out.EphemeralStorageLimit
+		*out = new(string)
+		**out = **in
+	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(GPUSpec)
+		**out = **in
+	}
+	if in.TaskCPUs != nil {
+		in, out := &in.TaskCPUs, &out.TaskCPUs
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceProfile.
+func (in *ResourceProfile) DeepCopy() *ResourceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestartPolicy) DeepCopyInto(out *RestartPolicy) {
+	*out = *in
+	if in.OnSubmissionFailureRetries != nil {
+		in, out := &in.OnSubmissionFailureRetries, &out.OnSubmissionFailureRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OnFailureRetries != nil {
+		in, out := &in.OnFailureRetries, &out.OnFailureRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OnSubmissionFailureRetryInterval != nil {
+		in, out := &in.OnSubmissionFailureRetryInterval, &out.OnSubmissionFailureRetryInterval
+		*out = new(int64)
+		**out = **in
+	}
+	if in.OnFailureRetryInterval != nil {
+		in, out := &in.OnFailureRetryInterval, &out.OnFailureRetryInterval
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RetryableErrors != nil {
+		in, out := &in.RetryableErrors, &out.RetryableErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MemoryBump != nil {
+		in, out := &in.MemoryBump, &out.MemoryBump
+		*out = new(MemoryBumpPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartPolicy.
+func (in *RestartPolicy) DeepCopy() *RestartPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RestartPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationPolicy) DeepCopyInto(out *RotationPolicy) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationPolicy.
+func (in *RotationPolicy) DeepCopy() *RotationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunHistorySpec) DeepCopyInto(out *RunHistorySpec) {
+	*out = *in
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLSeconds != nil {
+		in, out := &in.TTLSeconds, &out.TTLSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunHistorySpec.
+func (in *RunHistorySpec) DeepCopy() *RunHistorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RunHistorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunResourceUsage) DeepCopyInto(out *RunResourceUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunResourceUsage.
+func (in *RunResourceUsage) DeepCopy() *RunResourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(RunResourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSparkApplication) DeepCopyInto(out *ScheduledSparkApplication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplication.
+func (in *ScheduledSparkApplication) DeepCopy() *ScheduledSparkApplication {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSparkApplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledSparkApplication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSparkApplicationList) DeepCopyInto(out *ScheduledSparkApplicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScheduledSparkApplication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplicationList.
+func (in *ScheduledSparkApplicationList) DeepCopy() *ScheduledSparkApplicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSparkApplicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledSparkApplicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSparkApplicationSpec) DeepCopyInto(out *ScheduledSparkApplicationSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SuccessfulRunHistoryLimit != nil {
+		in, out := &in.SuccessfulRunHistoryLimit, &out.SuccessfulRunHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedRunHistoryLimit != nil {
+		in, out := &in.FailedRunHistoryLimit, &out.FailedRunHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplicationSpec.
+func (in *ScheduledSparkApplicationSpec) DeepCopy() *ScheduledSparkApplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSparkApplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSparkApplicationStatus) DeepCopyInto(out *ScheduledSparkApplicationStatus) {
+	*out = *in
+	in.LastRun.DeepCopyInto(&out.LastRun)
+	in.NextRun.DeepCopyInto(&out.NextRun)
+	if in.PastSuccessfulRunNames != nil {
+		in, out := &in.PastSuccessfulRunNames, &out.PastSuccessfulRunNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PastFailedRunNames != nil {
+		in, out := &in.PastFailedRunNames, &out.PastFailedRunNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplicationStatus.
+func (in *ScheduledSparkApplicationStatus) DeepCopy() *ScheduledSparkApplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSparkApplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretInfo) DeepCopyInto(out *SecretInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretInfo.
+func (in *SecretInfo) DeepCopy() *SecretInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceReference) DeepCopyInto(out *SourceReference) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(OCISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceReference.
+func (in *SourceReference) DeepCopy() *SourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplication) DeepCopyInto(out *SparkApplication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplication.
+func (in *SparkApplication) DeepCopy() *SparkApplication {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SparkApplication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationList) DeepCopyInto(out *SparkApplicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SparkApplication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationList.
+func (in *SparkApplicationList) DeepCopy() *SparkApplicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SparkApplicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationRun) DeepCopyInto(out *SparkApplicationRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationRun.
+func (in *SparkApplicationRun) DeepCopy() *SparkApplicationRun {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SparkApplicationRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationRunList) DeepCopyInto(out *SparkApplicationRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SparkApplicationRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationRunList.
+func (in *SparkApplicationRunList) DeepCopy() *SparkApplicationRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SparkApplicationRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationRunSpec) DeepCopyInto(out *SparkApplicationRunSpec) {
+	*out = *in
+	in.ApplicationSpec.DeepCopyInto(&out.ApplicationSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationRunSpec.
+func (in *SparkApplicationRunSpec) DeepCopy() *SparkApplicationRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationRunStatus) DeepCopyInto(out *SparkApplicationRunStatus) {
+	*out = *in
+	in.SubmissionTime.DeepCopyInto(&out.SubmissionTime)
+	in.DriverRunningTime.DeepCopyInto(&out.DriverRunningTime)
+	in.TerminationTime.DeepCopyInto(&out.TerminationTime)
+	out.ResourceUsage = in.ResourceUsage
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationRunStatus.
+func (in *SparkApplicationRunStatus) DeepCopy() *SparkApplicationRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationSpec) DeepCopyInto(out *SparkApplicationSpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.InitContainerImage != nil {
+		in, out := &in.InitContainerImage, &out.InitContainerImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImagePullPolicy != nil {
+		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MainClass != nil {
+		in, out := &in.MainClass, &out.MainClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.MainApplicationFile != nil {
+		in, out := &in.MainApplicationFile, &out.MainApplicationFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SparkConf != nil {
+		in, out := &in.SparkConf, &out.SparkConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HadoopConf != nil {
+		in, out := &in.HadoopConf, &out.HadoopConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SparkConfigMap != nil {
+		in, out := &in.SparkConfigMap, &out.SparkConfigMap
+		*out = new(string)
+		**out = **in
+	}
+	if in.HadoopConfigMap != nil {
+		in, out := &in.HadoopConfigMap, &out.HadoopConfigMap
+		*out = new(string)
+		**out = **in
+	}
+	if in.HadoopClusterRef != nil {
+		in, out := &in.HadoopClusterRef, &out.HadoopClusterRef
+		*out = new(string)
+		**out = **in
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Driver.DeepCopyInto(&out.Driver)
+	in.Executor.DeepCopyInto(&out.Executor)
+	in.Deps.DeepCopyInto(&out.Deps)
+	in.RestartPolicy.DeepCopyInto(&out.RestartPolicy)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FailureRetries != nil {
+		in, out := &in.FailureRetries, &out.FailureRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PythonVersion != nil {
+		in, out := &in.PythonVersion, &out.PythonVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemoryOverheadFactor != nil {
+		in, out := &in.MemoryOverheadFactor, &out.MemoryOverheadFactor
+		*out = new(string)
+		**out = **in
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalDriver != nil {
+		in, out := &in.ExternalDriver, &out.ExternalDriver
+		*out = new(ExternalDriverSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CreateServiceAccount != nil {
+		in, out := &in.CreateServiceAccount, &out.CreateServiceAccount
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SpreadExecutors != nil {
+		in, out := &in.SpreadExecutors, &out.SpreadExecutors
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SeparateDriver != nil {
+		in, out := &in.SeparateDriver, &out.SeparateDriver
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Zone != nil {
+		in, out := &in.Zone, &out.Zone
+		*out = new(string)
+		**out = **in
+	}
+	if in.ZoneAffinity != nil {
+		in, out := &in.ZoneAffinity, &out.ZoneAffinity
+		*out = new(ZoneAffinityType)
+		**out = **in
+	}
+	if in.Rotation != nil {
+		in, out := &in.Rotation, &out.Rotation
+		*out = new(RotationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutputCleanup != nil {
+		in, out := &in.OutputCleanup, &out.OutputCleanup
+		*out = new(OutputCleanupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DriverLogCapture != nil {
+		in, out := &in.DriverLogCapture, &out.DriverLogCapture
+		*out = new(DriverLogCaptureSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PreemptionPolicy != nil {
+		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
+		*out = new(PreemptionPolicy)
+		**out = **in
+	}
+	if in.ExecutorGroups != nil {
+		in, out := &in.ExecutorGroups, &out.ExecutorGroups
+		*out = make([]ExecutorGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceProfiles != nil {
+		in, out := &in.ResourceProfiles, &out.ResourceProfiles
+		*out = make([]ResourceProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(SourceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExecutorIdleTimeout != nil {
+		in, out := &in.ExecutorIdleTimeout, &out.ExecutorIdleTimeout
+		*out = new(ExecutorIdleTimeoutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinExecutorsBeforeStart != nil {
+		in, out := &in.MinExecutorsBeforeStart, &out.MinExecutorsBeforeStart
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ProxyUser != nil {
+		in, out := &in.ProxyUser, &out.ProxyUser
+		*out = new(string)
+		**out = **in
+	}
+	if in.CloudIdentity != nil {
+		in, out := &in.CloudIdentity, &out.CloudIdentity
+		*out = new(CloudIdentitySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EncryptLocalData != nil {
+		in, out := &in.EncryptLocalData, &out.EncryptLocalData
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RunHistory != nil {
+		in, out := &in.RunHistory, &out.RunHistory
+		*out = new(RunHistorySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestartOnConfigChange != nil {
+		in, out := &in.RestartOnConfigChange, &out.RestartOnConfigChange
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MLMode != nil {
+		in, out := &in.MLMode, &out.MLMode
+		*out = new(MLModeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(GPUAccelerationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(DebugSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataCache != nil {
+		in, out := &in.DataCache, &out.DataCache
+		*out = new(DataCacheSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionPooler != nil {
+		in, out := &in.ConnectionPooler, &out.ConnectionPooler
+		*out = new(ConnectionPoolerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoTuning != nil {
+		in, out := &in.AutoTuning, &out.AutoTuning
+		*out = new(AutoTuningSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Submitter != nil {
+		in, out := &in.Submitter, &out.Submitter
+		*out = new(string)
+		**out = **in
+	}
+	if in.IdempotencyKey != nil {
+		in, out := &in.IdempotencyKey, &out.IdempotencyKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.MetadataPropagation != nil {
+		in, out := &in.MetadataPropagation, &out.MetadataPropagation
+		*out = new(MetadataPropagationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplication.
-func (in *ScheduledSparkApplication) DeepCopy() *ScheduledSparkApplication {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationSpec.
+func (in *SparkApplicationSpec) DeepCopy() *SparkApplicationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduledSparkApplication)
+	out := new(SparkApplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkApplicationStatus) DeepCopyInto(out *SparkApplicationStatus) {
+	*out = *in
+	in.LastSubmissionAttemptTime.DeepCopyInto(&out.LastSubmissionAttemptTime)
+	in.TerminationTime.DeepCopyInto(&out.TerminationTime)
+	in.DriverInfo.DeepCopyInto(&out.DriverInfo)
+	out.AppState = in.AppState
+	if in.ExecutorState != nil {
+		in, out := &in.ExecutorState, &out.ExecutorState
+		*out = make(map[string]ExecutorState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.QueuedTime.DeepCopyInto(&out.QueuedTime)
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(ApplicationProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(ApplicationUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LaunchLatency != nil {
+		in, out := &in.LaunchLatency, &out.LaunchLatency
+		*out = new(LaunchLatency)
+		(*in).DeepCopyInto(*out)
+	}
+	in.SLABreachTime.DeepCopyInto(&out.SLABreachTime)
+	in.StartGateOpenTime.DeepCopyInto(&out.StartGateOpenTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ApplicationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExecutorRestart != nil {
+		in, out := &in.ExecutorRestart, &out.ExecutorRestart
+		*out = new(ExecutorRestartStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigHashes != nil {
+		in, out := &in.ConfigHashes, &out.ConfigHashes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourcePressure != nil {
+		in, out := &in.ResourcePressure, &out.ResourcePressure
+		*out = new(ResourcePressureSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dumps != nil {
+		in, out := &in.Dumps, &out.Dumps
+		*out = new(DumpStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoTuning != nil {
+		in, out := &in.AutoTuning, &out.AutoTuning
+		*out = new(AutoTuningStatus)
+		**out = **in
+	}
+	if in.MemoryBump != nil {
+		in, out := &in.MemoryBump, &out.MemoryBump
+		*out = new(MemoryBumpStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSpecUpdate != nil {
+		in, out := &in.LastSpecUpdate, &out.LastSpecUpdate
+		*out = new(SpecUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SubmissionOutput != nil {
+		in, out := &in.SubmissionOutput, &out.SubmissionOutput
+		*out = new(SubmissionOutput)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationStatus.
+func (in *SparkApplicationStatus) DeepCopy() *SparkApplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkApplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkOperatorConfiguration) DeepCopyInto(out *SparkOperatorConfiguration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkOperatorConfiguration.
+func (in *SparkOperatorConfiguration) DeepCopy() *SparkOperatorConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkOperatorConfiguration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ScheduledSparkApplication) DeepCopyObject() runtime.Object {
+func (in *SparkOperatorConfiguration) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -328,13 +2617,13 @@ func (in *ScheduledSparkApplication) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduledSparkApplicationList) DeepCopyInto(out *ScheduledSparkApplicationList) {
+func (in *SparkOperatorConfigurationList) DeepCopyInto(out *SparkOperatorConfigurationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ScheduledSparkApplication, len(*in))
+		*out = make([]SparkOperatorConfiguration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -342,18 +2631,18 @@ func (in *ScheduledSparkApplicationList) DeepCopyInto(out *ScheduledSparkApplica
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplicationList.
-func (in *ScheduledSparkApplicationList) DeepCopy() *ScheduledSparkApplicationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkOperatorConfigurationList.
+func (in *SparkOperatorConfigurationList) DeepCopy() *SparkOperatorConfigurationList {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduledSparkApplicationList)
+	out := new(SparkOperatorConfigurationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ScheduledSparkApplicationList) DeepCopyObject() runtime.Object {
+func (in *SparkOperatorConfigurationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -361,103 +2650,230 @@ func (in *ScheduledSparkApplicationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduledSparkApplicationSpec) DeepCopyInto(out *ScheduledSparkApplicationSpec) {
+func (in *SparkOperatorConfigurationSpec) DeepCopyInto(out *SparkOperatorConfigurationSpec) {
 	*out = *in
-	in.Template.DeepCopyInto(&out.Template)
-	if in.Suspend != nil {
-		in, out := &in.Suspend, &out.Suspend
-		*out = new(bool)
-		**out = **in
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(OperatorDefaults)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.SuccessfulRunHistoryLimit != nil {
-		in, out := &in.SuccessfulRunHistoryLimit, &out.SuccessfulRunHistoryLimit
-		*out = new(int32)
-		**out = **in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(OperatorWebhookConfiguration)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.FailedRunHistoryLimit != nil {
-		in, out := &in.FailedRunHistoryLimit, &out.FailedRunHistoryLimit
-		*out = new(int32)
-		**out = **in
+	if in.Queueing != nil {
+		in, out := &in.Queueing, &out.Queueing
+		*out = new(OperatorQueueingConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(OperatorMetricsConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyUsers != nil {
+		in, out := &in.ProxyUsers, &out.ProxyUsers
+		*out = new(OperatorProxyUserConfiguration)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplicationSpec.
-func (in *ScheduledSparkApplicationSpec) DeepCopy() *ScheduledSparkApplicationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkOperatorConfigurationSpec.
+func (in *SparkOperatorConfigurationSpec) DeepCopy() *SparkOperatorConfigurationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduledSparkApplicationSpec)
+	out := new(SparkOperatorConfigurationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduledSparkApplicationStatus) DeepCopyInto(out *ScheduledSparkApplicationStatus) {
+func (in *SparkOperatorConfigurationStatus) DeepCopyInto(out *SparkOperatorConfigurationStatus) {
 	*out = *in
-	in.LastRun.DeepCopyInto(&out.LastRun)
-	in.NextRun.DeepCopyInto(&out.NextRun)
-	if in.PastSuccessfulRunNames != nil {
-		in, out := &in.PastSuccessfulRunNames, &out.PastSuccessfulRunNames
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.PastFailedRunNames != nil {
-		in, out := &in.PastFailedRunNames, &out.PastFailedRunNames
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
+	in.LastAppliedTime.DeepCopyInto(&out.LastAppliedTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSparkApplicationStatus.
-func (in *ScheduledSparkApplicationStatus) DeepCopy() *ScheduledSparkApplicationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkOperatorConfigurationStatus.
+func (in *SparkOperatorConfigurationStatus) DeepCopy() *SparkOperatorConfigurationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduledSparkApplicationStatus)
+	out := new(SparkOperatorConfigurationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretInfo) DeepCopyInto(out *SecretInfo) {
+func (in *SparkPodSpec) DeepCopyInto(out *SparkPodSpec) {
 	*out = *in
+	if in.Cores != nil {
+		in, out := &in.Cores, &out.Cores
+		*out = new(float32)
+		**out = **in
+	}
+	if in.CoreLimit != nil {
+		in, out := &in.CoreLimit, &out.CoreLimit
+		*out = new(string)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(string)
+		**out = **in
+	}
+	if in.MemoryOverhead != nil {
+		in, out := &in.MemoryOverhead, &out.MemoryOverhead
+		*out = new(string)
+		**out = **in
+	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(string)
+		**out = **in
+	}
+	if in.EphemeralStorageLimit != nil {
+		in, out := &in.EphemeralStorageLimit, &out.EphemeralStorageLimit
+		*out = new(string)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]NamePath, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]SecretInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnvVars != nil {
+		in, out := &in.EnvVars, &out.EnvVars
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EnvSecretKeyRefs != nil {
+		in, out := &in.EnvSecretKeyRefs, &out.EnvSecretKeyRefs
+		*out = make(map[string]NameKey, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NotReadyTolerationSeconds != nil {
+		in, out := &in.NotReadyTolerationSeconds, &out.NotReadyTolerationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UnreachableTolerationSeconds != nil {
+		in, out := &in.UnreachableTolerationSeconds, &out.UnreachableTolerationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SecurityContenxt != nil {
+		in, out := &in.SecurityContenxt, &out.SecurityContenxt
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SeccompProfile != nil {
+		in, out := &in.SeccompProfile, &out.SeccompProfile
+		*out = new(string)
+		**out = **in
+	}
+	if in.AppArmorProfile != nil {
+		in, out := &in.AppArmorProfile, &out.AppArmorProfile
+		*out = new(string)
+		**out = **in
+	}
+	if in.RequireNodeFeatures != nil {
+		in, out := &in.RequireNodeFeatures, &out.RequireNodeFeatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]NetworkAttachment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretInfo.
-func (in *SecretInfo) DeepCopy() *SecretInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkPodSpec.
+func (in *SparkPodSpec) DeepCopy() *SparkPodSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretInfo)
+	out := new(SparkPodSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SparkApplication) DeepCopyInto(out *SparkApplication) {
+func (in *SparkThriftServer) DeepCopyInto(out *SparkThriftServer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplication.
-func (in *SparkApplication) DeepCopy() *SparkApplication {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkThriftServer.
+func (in *SparkThriftServer) DeepCopy() *SparkThriftServer {
 	if in == nil {
 		return nil
 	}
-	out := new(SparkApplication)
+	out := new(SparkThriftServer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SparkApplication) DeepCopyObject() runtime.Object {
+func (in *SparkThriftServer) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -465,13 +2881,13 @@ func (in *SparkApplication) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SparkApplicationList) DeepCopyInto(out *SparkApplicationList) {
+func (in *SparkThriftServerList) DeepCopyInto(out *SparkThriftServerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SparkApplication, len(*in))
+		*out = make([]SparkThriftServer, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -479,18 +2895,18 @@ func (in *SparkApplicationList) DeepCopyInto(out *SparkApplicationList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationList.
-func (in *SparkApplicationList) DeepCopy() *SparkApplicationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkThriftServerList.
+func (in *SparkThriftServerList) DeepCopy() *SparkThriftServerList {
 	if in == nil {
 		return nil
 	}
-	out := new(SparkApplicationList)
+	out := new(SparkThriftServerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SparkApplicationList) DeepCopyObject() runtime.Object {
+func (in *SparkThriftServerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -498,43 +2914,39 @@ func (in *SparkApplicationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SparkApplicationSpec) DeepCopyInto(out *SparkApplicationSpec) {
+func (in *SparkThriftServerSpec) DeepCopyInto(out *SparkThriftServerSpec) {
 	*out = *in
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
 		*out = new(string)
 		**out = **in
 	}
-	if in.InitContainerImage != nil {
-		in, out := &in.InitContainerImage, &out.InitContainerImage
-		*out = new(string)
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
 		**out = **in
 	}
-	if in.ImagePullPolicy != nil {
-		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
-		*out = new(string)
+	if in.ServiceType != nil {
+		in, out := &in.ServiceType, &out.ServiceType
+		*out = new(v1.ServiceType)
 		**out = **in
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.MainClass != nil {
-		in, out := &in.MainClass, &out.MainClass
+	if in.IngressHost != nil {
+		in, out := &in.IngressHost, &out.IngressHost
 		*out = new(string)
 		**out = **in
 	}
-	if in.MainApplicationFile != nil {
-		in, out := &in.MainApplicationFile, &out.MainApplicationFile
-		*out = new(string)
-		**out = **in
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(ThriftServerAutoscaling)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Arguments != nil {
-		in, out := &in.Arguments, &out.Arguments
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(ThriftServerAuthentication)
+		(*in).DeepCopyInto(*out)
 	}
+	in.Deps.DeepCopyInto(&out.Deps)
 	if in.SparkConf != nil {
 		in, out := &in.SparkConf, &out.SparkConf
 		*out = make(map[string]string, len(*in))
@@ -549,198 +2961,186 @@ func (in *SparkApplicationSpec) DeepCopyInto(out *SparkApplicationSpec) {
 			(*out)[key] = val
 		}
 	}
-	if in.SparkConfigMap != nil {
-		in, out := &in.SparkConfigMap, &out.SparkConfigMap
-		*out = new(string)
-		**out = **in
-	}
-	if in.HadoopConfigMap != nil {
-		in, out := &in.HadoopConfigMap, &out.HadoopConfigMap
-		*out = new(string)
-		**out = **in
-	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	in.Driver.DeepCopyInto(&out.Driver)
 	in.Executor.DeepCopyInto(&out.Executor)
-	in.Deps.DeepCopyInto(&out.Deps)
-	in.RestartPolicy.DeepCopyInto(&out.RestartPolicy)
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.FailureRetries != nil {
-		in, out := &in.FailureRetries, &out.FailureRetries
-		*out = new(int32)
-		**out = **in
-	}
-	if in.RetryInterval != nil {
-		in, out := &in.RetryInterval, &out.RetryInterval
-		*out = new(int64)
-		**out = **in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkThriftServerSpec.
+func (in *SparkThriftServerSpec) DeepCopy() *SparkThriftServerSpec {
+	if in == nil {
+		return nil
 	}
-	if in.PythonVersion != nil {
-		in, out := &in.PythonVersion, &out.PythonVersion
-		*out = new(string)
-		**out = **in
+	out := new(SparkThriftServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkThriftServerStatus) DeepCopyInto(out *SparkThriftServerStatus) {
+	*out = *in
+	out.AppState = in.AppState
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkThriftServerStatus.
+func (in *SparkThriftServerStatus) DeepCopy() *SparkThriftServerStatus {
+	if in == nil {
+		return nil
 	}
-	if in.MemoryOverheadFactor != nil {
-		in, out := &in.MemoryOverheadFactor, &out.MemoryOverheadFactor
-		*out = new(string)
-		**out = **in
+	out := new(SparkThriftServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecUpdateStatus) DeepCopyInto(out *SpecUpdateStatus) {
+	*out = *in
+	in.UpdateTime.DeepCopyInto(&out.UpdateTime)
+	if in.RestartRequiringFields != nil {
+		in, out := &in.RestartRequiringFields, &out.RestartRequiringFields
+		*out = make([]FieldChange, len(*in))
+		copy(*out, *in)
 	}
-	if in.Monitoring != nil {
-		in, out := &in.Monitoring, &out.Monitoring
-		*out = new(MonitoringSpec)
-		(*in).DeepCopyInto(*out)
+	if in.HotSwappableFields != nil {
+		in, out := &in.HotSwappableFields, &out.HotSwappableFields
+		*out = make([]FieldChange, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationSpec.
-func (in *SparkApplicationSpec) DeepCopy() *SparkApplicationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecUpdateStatus.
+func (in *SpecUpdateStatus) DeepCopy() *SpecUpdateStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SparkApplicationSpec)
+	out := new(SpecUpdateStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SparkApplicationStatus) DeepCopyInto(out *SparkApplicationStatus) {
+func (in *StageProgress) DeepCopyInto(out *StageProgress) {
 	*out = *in
-	in.LastSubmissionAttemptTime.DeepCopyInto(&out.LastSubmissionAttemptTime)
-	in.TerminationTime.DeepCopyInto(&out.TerminationTime)
-	out.DriverInfo = in.DriverInfo
-	out.AppState = in.AppState
-	if in.ExecutorState != nil {
-		in, out := &in.ExecutorState, &out.ExecutorState
-		*out = make(map[string]ExecutorState, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageProgress.
+func (in *StageProgress) DeepCopy() *StageProgress {
+	if in == nil {
+		return nil
 	}
+	out := new(StageProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubmissionOutput) DeepCopyInto(out *SubmissionOutput) {
+	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationStatus.
-func (in *SparkApplicationStatus) DeepCopy() *SparkApplicationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubmissionOutput.
+func (in *SubmissionOutput) DeepCopy() *SubmissionOutput {
 	if in == nil {
 		return nil
 	}
-	out := new(SparkApplicationStatus)
+	out := new(SubmissionOutput)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SparkPodSpec) DeepCopyInto(out *SparkPodSpec) {
+func (in *ThriftServerAuthentication) DeepCopyInto(out *ThriftServerAuthentication) {
 	*out = *in
-	if in.Cores != nil {
-		in, out := &in.Cores, &out.Cores
-		*out = new(float32)
+	if in.LDAPURL != nil {
+		in, out := &in.LDAPURL, &out.LDAPURL
+		*out = new(string)
 		**out = **in
 	}
-	if in.CoreLimit != nil {
-		in, out := &in.CoreLimit, &out.CoreLimit
+	if in.LDAPBaseDN != nil {
+		in, out := &in.LDAPBaseDN, &out.LDAPBaseDN
 		*out = new(string)
 		**out = **in
 	}
-	if in.Memory != nil {
-		in, out := &in.Memory, &out.Memory
-		*out = new(string)
+	if in.ImpersonateUsers != nil {
+		in, out := &in.ImpersonateUsers, &out.ImpersonateUsers
+		*out = new(bool)
 		**out = **in
 	}
-	if in.MemoryOverhead != nil {
-		in, out := &in.MemoryOverhead, &out.MemoryOverhead
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThriftServerAuthentication.
+func (in *ThriftServerAuthentication) DeepCopy() *ThriftServerAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(ThriftServerAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThriftServerAutoscaling) DeepCopyInto(out *ThriftServerAutoscaling) {
+	*out = *in
+	if in.MinExecutors != nil {
+		in, out := &in.MinExecutors, &out.MinExecutors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExecutorIdleTimeout != nil {
+		in, out := &in.ExecutorIdleTimeout, &out.ExecutorIdleTimeout
 		*out = new(string)
 		**out = **in
 	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThriftServerAutoscaling.
+func (in *ThriftServerAutoscaling) DeepCopy() *ThriftServerAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(ThriftServerAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UIProxySpec) DeepCopyInto(out *UIProxySpec) {
+	*out = *in
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
 		*out = new(string)
 		**out = **in
 	}
-	if in.ConfigMaps != nil {
-		in, out := &in.ConfigMaps, &out.ConfigMaps
-		*out = make([]NamePath, len(*in))
-		copy(*out, *in)
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Secrets != nil {
-		in, out := &in.Secrets, &out.Secrets
-		*out = make([]SecretInfo, len(*in))
+	if in.AllowedGroups != nil {
+		in, out := &in.AllowedGroups, &out.AllowedGroups
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.EnvVars != nil {
-		in, out := &in.EnvVars, &out.EnvVars
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.EnvSecretKeyRefs != nil {
-		in, out := &in.EnvSecretKeyRefs, &out.EnvSecretKeyRefs
-		*out = make(map[string]NameKey, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SecurityContenxt != nil {
-		in, out := &in.SecurityContenxt, &out.SecurityContenxt
-		*out = new(v1.PodSecurityContext)
-		(*in).DeepCopyInto(*out)
+	if in.EmailDomains != nil {
+		in, out := &in.EmailDomains, &out.EmailDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkPodSpec.
-func (in *SparkPodSpec) DeepCopy() *SparkPodSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UIProxySpec.
+func (in *UIProxySpec) DeepCopy() *UIProxySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SparkPodSpec)
+	out := new(UIProxySpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,152 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/go-cloud/blob"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const writeTimeout = 30 * time.Second
+
+// Record is the archived final state of a deleted SparkApplication.
+type Record struct {
+	Application   *v1beta1.SparkApplication `json:"application"`
+	Events        []apiv1.Event             `json:"events,omitempty"`
+	DriverLogTail string                    `json:"driverLogTail,omitempty"`
+	ArchivedAt    metav1.Time               `json:"archivedAt"`
+}
+
+// Archiver writes a Record of deleted SparkApplications to an object storage bucket, so an audit trail of
+// them is kept after they are gone from the API server.
+type Archiver struct {
+	kubeClient   clientset.Interface
+	bucket       *blob.Bucket
+	prefix       string
+	logTailLines int64
+}
+
+// NewArchiver creates a new Archiver writing records under the given key prefix of the bucket. The last
+// logTailLines lines of the driver log are included in records if logTailLines is positive.
+func NewArchiver(kubeClient clientset.Interface, bucket *blob.Bucket, prefix string, logTailLines int64) *Archiver {
+	return &Archiver{
+		kubeClient:   kubeClient,
+		bucket:       bucket,
+		prefix:       prefix,
+		logTailLines: logTailLines,
+	}
+}
+
+// Archive writes a Record of the given SparkApplication. It is expected to be called before the driver pod
+// of the application is deleted, so its log is still available.
+func (a *Archiver) Archive(app *v1beta1.SparkApplication) error {
+	data, err := json.Marshal(a.buildRecord(app))
+	if err != nil {
+		return fmt.Errorf("failed to serialize archive record of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	key := getRecordKey(a.prefix, app)
+	w, err := a.bucket.NewWriter(ctx, key, &blob.WriterOptions{ContentType: "application/json"})
+	if err != nil {
+		return fmt.Errorf("failed to open archive record %s: %v", key, err)
+	}
+	_, writeErr := w.Write(data)
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write archive record %s: %v", key, err)
+	}
+	if writeErr != nil {
+		return fmt.Errorf("failed to write archive record %s: %v", key, writeErr)
+	}
+
+	glog.Infof("Archived SparkApplication %s/%s to %s", app.Namespace, app.Name, key)
+	return nil
+}
+
+// buildRecord builds a Record of the given application. Events and driver logs are best-effort, failing to
+// get them does not keep the application from being archived.
+func (a *Archiver) buildRecord(app *v1beta1.SparkApplication) *Record {
+	record := &Record{
+		Application: app,
+		ArchivedAt:  metav1.Now(),
+	}
+
+	events, err := a.getEvents(app)
+	if err != nil {
+		glog.Errorf("failed to get events of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	}
+	record.Events = events
+
+	if a.logTailLines > 0 && app.Status.DriverInfo.PodName != "" {
+		logTail, err := a.getDriverLogTail(app)
+		if err != nil {
+			glog.Errorf("failed to get driver log of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+		record.DriverLogTail = logTail
+	}
+
+	return record
+}
+
+func (a *Archiver) getEvents(app *v1beta1.SparkApplication) ([]apiv1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind": "SparkApplication",
+		"involvedObject.name": app.Name,
+	}.AsSelector().String()
+	events, err := a.kubeClient.CoreV1().Events(app.Namespace).List(metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	// Events of an earlier SparkApplication with the same name are left out.
+	var result []apiv1.Event
+	for _, event := range events.Items {
+		if event.InvolvedObject.UID == "" || event.InvolvedObject.UID == app.UID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (a *Archiver) getDriverLogTail(app *v1beta1.SparkApplication) (string, error) {
+	tailLines := a.logTailLines
+	logs, err := a.kubeClient.CoreV1().Pods(app.Namespace).GetLogs(
+		app.Status.DriverInfo.PodName, &apiv1.PodLogOptions{TailLines: &tailLines}).Do().Raw()
+	if err != nil {
+		return "", err
+	}
+	return string(logs), nil
+}
+
+// getRecordKey returns the key of the record of the given application, which is unique across applications
+// that are re-created with the same name.
+func getRecordKey(prefix string, app *v1beta1.SparkApplication) string {
+	return path.Join(prefix, app.Namespace, app.Name, fmt.Sprintf("%s.json", app.UID))
+}
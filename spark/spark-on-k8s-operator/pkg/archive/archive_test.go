@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cloud/blob/fileblob"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spark-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bucket, err := fileblob.NewBucket(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-pi",
+			Namespace: "default",
+			UID:       "uid-2",
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.CompletedState},
+		},
+	}
+	kubeClient := kubeclientfake.NewSimpleClientset(
+		&apiv1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-1", Namespace: "default"},
+			InvolvedObject: apiv1.ObjectReference{Kind: "SparkApplication", Name: "spark-pi", UID: "uid-2"},
+			Reason:         "SparkApplicationCompleted",
+		},
+		&apiv1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-2", Namespace: "default"},
+			InvolvedObject: apiv1.ObjectReference{Kind: "SparkApplication", Name: "spark-pi", UID: "uid-1"},
+			Reason:         "SparkApplicationFailed",
+		})
+
+	archiver := NewArchiver(kubeClient, bucket, "audit", 0)
+	if err := archiver.Archive(app); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "audit", "default", "spark-pi", "uid-2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := &Record{}
+	if err := json.Unmarshal(data, record); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "spark-pi", record.Application.Name)
+	assert.Equal(t, v1beta1.CompletedState, record.Application.Status.AppState.State)
+	assert.False(t, record.ArchivedAt.IsZero())
+	// Only events of this very application are archived.
+	assert.Equal(t, 1, len(record.Events))
+	assert.Equal(t, "SparkApplicationCompleted", record.Events[0].Reason)
+}
+
+func TestGetRecordKey(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi", Namespace: "default", UID: "uid"},
+	}
+	assert.Equal(t, "default/spark-pi/uid.json", getRecordKey("", app))
+	assert.Equal(t, "audit/default/spark-pi/uid.json", getRecordKey("audit", app))
+}
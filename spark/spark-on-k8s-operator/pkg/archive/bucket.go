@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/google/go-cloud/blob"
+	"github.com/google/go-cloud/blob/gcsblob"
+	"github.com/google/go-cloud/blob/s3blob"
+	"github.com/google/go-cloud/gcp"
+)
+
+// OpenBucket opens the bucket referred to by the given gs:// or s3:// URL and returns it along with the key
+// prefix given by the path of the URL.
+func OpenBucket(ctx context.Context, bucketURL string, endpoint string, region string) (*blob.Bucket, string, error) {
+	parsed, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse archive bucket URL %s: %v", bucketURL, err)
+	}
+	if parsed.Host == "" {
+		return nil, "", fmt.Errorf("archive bucket URL %s has no bucket name", bucketURL)
+	}
+	prefix := strings.Trim(parsed.Path, "/")
+
+	var bucket *blob.Bucket
+	switch parsed.Scheme {
+	case "gs":
+		bucket, err = openGCSBucket(ctx, parsed.Host)
+	case "s3":
+		bucket, err = openS3Bucket(ctx, parsed.Host, endpoint, region)
+	default:
+		return nil, "", fmt.Errorf("unsupported archive bucket URL scheme: %s", parsed.Scheme)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open archive bucket %s: %v", bucketURL, err)
+	}
+	return bucket, prefix, nil
+}
+
+func openGCSBucket(ctx context.Context, bucket string) (*blob.Bucket, error) {
+	creds, err := gcp.DefaultCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := gcp.NewHTTPClient(gcp.DefaultTransport(), gcp.CredentialsTokenSource(creds))
+	if err != nil {
+		return nil, err
+	}
+	return gcsblob.OpenBucket(ctx, bucket, c)
+}
+
+func openS3Bucket(ctx context.Context, bucket string, endpoint string, region string) (*blob.Bucket, error) {
+	// The AWS SDK requires a region even for S3-compatible endpoints.
+	if region == "" {
+		region = "us-east-1"
+	}
+	c := &aws.Config{Region: aws.String(region)}
+	if endpoint != "" {
+		c.Endpoint = aws.String(endpoint)
+	}
+	sess, err := session.NewSession(c)
+	if err != nil {
+		return nil, err
+	}
+	return s3blob.OpenBucket(ctx, sess, bucket)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package application helps services create SparkApplications and wait for them with the typed clientset of
+// the operator, without copying the generated clients.
+package application
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+// Builder builds a SparkApplication. Its methods return the Builder so that calls can be chained, e.g.:
+//
+//	app, err := application.NewSparkApplication("default", "spark-pi").
+//		WithType(v1beta1.ScalaApplicationType).
+//		WithImage("gcr.io/spark-operator/spark:v2.4.0").
+//		WithMainClass("org.apache.spark.examples.SparkPi").
+//		WithMainApplicationFile("local:///opt/spark/examples/jars/spark-examples_2.11-2.4.0.jar").
+//		WithExecutors(2, 1, "512m").
+//		Submit(client)
+type Builder struct {
+	app *v1beta1.SparkApplication
+}
+
+// NewSparkApplication returns a Builder of a SparkApplication with the given namespace and name, which runs in
+// cluster mode and is never restarted unless set otherwise.
+func NewSparkApplication(namespace, name string) *Builder {
+	return &Builder{app: &v1beta1.SparkApplication{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Kind:       "SparkApplication",
+		},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: v1beta1.SparkApplicationSpec{
+			Mode:          v1beta1.ClusterMode,
+			RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+		},
+	}}
+}
+
+// WithLabels adds the given labels to the application.
+func (b *Builder) WithLabels(labels map[string]string) *Builder {
+	if b.app.Labels == nil {
+		b.app.Labels = make(map[string]string)
+	}
+	for key, value := range labels {
+		b.app.Labels[key] = value
+	}
+	return b
+}
+
+// WithType sets the type of the application, e.g., v1beta1.ScalaApplicationType.
+func (b *Builder) WithType(appType v1beta1.SparkApplicationType) *Builder {
+	b.app.Spec.Type = appType
+	return b
+}
+
+// WithSparkVersion sets the version of Spark the application uses.
+func (b *Builder) WithSparkVersion(version string) *Builder {
+	b.app.Spec.SparkVersion = version
+	return b
+}
+
+// WithImage sets the container image of the driver and executors.
+func (b *Builder) WithImage(image string) *Builder {
+	b.app.Spec.Image = &image
+	return b
+}
+
+// WithMainClass sets the main class of a Java or Scala application.
+func (b *Builder) WithMainClass(mainClass string) *Builder {
+	b.app.Spec.MainClass = &mainClass
+	return b
+}
+
+// WithMainApplicationFile sets the path of the jar, Python, or R file of the application.
+func (b *Builder) WithMainApplicationFile(file string) *Builder {
+	b.app.Spec.MainApplicationFile = &file
+	return b
+}
+
+// WithArguments appends the given arguments of the application.
+func (b *Builder) WithArguments(args ...string) *Builder {
+	b.app.Spec.Arguments = append(b.app.Spec.Arguments, args...)
+	return b
+}
+
+// WithSparkConf sets a Spark configuration property of the application.
+func (b *Builder) WithSparkConf(key, value string) *Builder {
+	if b.app.Spec.SparkConf == nil {
+		b.app.Spec.SparkConf = make(map[string]string)
+	}
+	b.app.Spec.SparkConf[key] = value
+	return b
+}
+
+// WithHadoopConf sets a Hadoop configuration property of the application.
+func (b *Builder) WithHadoopConf(key, value string) *Builder {
+	if b.app.Spec.HadoopConf == nil {
+		b.app.Spec.HadoopConf = make(map[string]string)
+	}
+	b.app.Spec.HadoopConf[key] = value
+	return b
+}
+
+// WithDriver sets the cores and memory of the driver, e.g., 1 and "1g".
+func (b *Builder) WithDriver(cores float32, memory string) *Builder {
+	b.app.Spec.Driver.Cores = &cores
+	b.app.Spec.Driver.Memory = &memory
+	return b
+}
+
+// WithServiceAccount sets the service account the driver runs as.
+func (b *Builder) WithServiceAccount(serviceAccount string) *Builder {
+	b.app.Spec.Driver.ServiceAccount = &serviceAccount
+	return b
+}
+
+// WithExecutors sets the number, cores, and memory of the executors.
+func (b *Builder) WithExecutors(instances int32, cores float32, memory string) *Builder {
+	b.app.Spec.Executor.Instances = &instances
+	b.app.Spec.Executor.Cores = &cores
+	b.app.Spec.Executor.Memory = &memory
+	return b
+}
+
+// WithRestartPolicy sets the restart policy of the application.
+func (b *Builder) WithRestartPolicy(policy v1beta1.RestartPolicy) *Builder {
+	b.app.Spec.RestartPolicy = policy
+	return b
+}
+
+// Build returns a copy of the application built so far, after checking that the required fields are set.
+func (b *Builder) Build() (*v1beta1.SparkApplication, error) {
+	app := b.app.DeepCopy()
+	if app.Namespace == "" || app.Name == "" {
+		return nil, fmt.Errorf("SparkApplication requires a namespace and a name")
+	}
+	if app.Spec.Type == "" {
+		return nil, fmt.Errorf("SparkApplication %s/%s requires a type", app.Namespace, app.Name)
+	}
+	if app.Spec.MainApplicationFile == nil {
+		return nil, fmt.Errorf("SparkApplication %s/%s requires a main application file", app.Namespace, app.Name)
+	}
+	if (app.Spec.Type == v1beta1.JavaApplicationType || app.Spec.Type == v1beta1.ScalaApplicationType) &&
+		app.Spec.MainClass == nil {
+		return nil, fmt.Errorf("%s SparkApplication %s/%s requires a main class", app.Spec.Type, app.Namespace,
+			app.Name)
+	}
+	return app, nil
+}
+
+// Submit builds the application and creates it with the given clientset, returning the created application.
+func (b *Builder) Submit(client crdclientset.Interface) (*v1beta1.SparkApplication, error) {
+	app, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	created, err := client.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	}
+	return created, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+)
+
+func TestBuild(t *testing.T) {
+	builder := NewSparkApplication("default", "spark-pi").
+		WithLabels(map[string]string{"team": "a"}).
+		WithType(v1beta1.ScalaApplicationType).
+		WithSparkVersion("2.4.0").
+		WithImage("gcr.io/spark-operator/spark:v2.4.0").
+		WithMainClass("org.apache.spark.examples.SparkPi").
+		WithMainApplicationFile("local:///opt/spark/examples/jars/spark-examples_2.11-2.4.0.jar").
+		WithArguments("1000").
+		WithSparkConf("spark.eventLog.enabled", "true").
+		WithDriver(1, "1g").
+		WithServiceAccount("spark").
+		WithExecutors(2, 1, "512m")
+	app, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "SparkApplication", app.Kind)
+	assert.Equal(t, "sparkoperator.k8s.io/v1beta1", app.APIVersion)
+	assert.Equal(t, map[string]string{"team": "a"}, app.Labels)
+	assert.Equal(t, v1beta1.ClusterMode, app.Spec.Mode)
+	assert.Equal(t, v1beta1.Never, app.Spec.RestartPolicy.Type)
+	assert.Equal(t, "org.apache.spark.examples.SparkPi", *app.Spec.MainClass)
+	assert.Equal(t, []string{"1000"}, app.Spec.Arguments)
+	assert.Equal(t, "true", app.Spec.SparkConf["spark.eventLog.enabled"])
+	assert.Equal(t, "spark", *app.Spec.Driver.ServiceAccount)
+	assert.Equal(t, int32(2), *app.Spec.Executor.Instances)
+	assert.Equal(t, "512m", *app.Spec.Executor.Memory)
+
+	// Built applications do not change with the builder.
+	builder.WithArguments("2000")
+	assert.Equal(t, []string{"1000"}, app.Spec.Arguments)
+}
+
+func TestBuild_Invalid(t *testing.T) {
+	for _, builder := range []*Builder{
+		NewSparkApplication("", "spark-pi").WithType(v1beta1.PythonApplicationType).WithMainApplicationFile("pi.py"),
+		NewSparkApplication("default", "spark-pi").WithMainApplicationFile("pi.py"),
+		NewSparkApplication("default", "spark-pi").WithType(v1beta1.PythonApplicationType),
+		NewSparkApplication("default", "spark-pi").WithType(v1beta1.JavaApplicationType).WithMainApplicationFile("pi.jar"),
+	} {
+		_, err := builder.Build()
+		assert.NotNil(t, err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	client := crdclientfake.NewSimpleClientset()
+	created, err := NewSparkApplication("default", "pi").
+		WithType(v1beta1.PythonApplicationType).
+		WithMainApplicationFile("local:///opt/spark/examples/src/main/python/pi.py").
+		Submit(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "pi", created.Name)
+	_, err = client.SparkoperatorV1beta1().SparkApplications("default").Get("pi", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	// The application exists already.
+	_, err = NewSparkApplication("default", "pi").
+		WithType(v1beta1.PythonApplicationType).
+		WithMainApplicationFile("local:///opt/spark/examples/src/main/python/pi.py").
+		Submit(client)
+	assert.NotNil(t, err)
+}
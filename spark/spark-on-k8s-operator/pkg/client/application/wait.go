@@ -0,0 +1,65 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+// DefaultPollInterval is how often WaitForCompletion checks the state of an application.
+const DefaultPollInterval = 5 * time.Second
+
+// IsTerminated tells if an application in the given state has terminated for good.
+func IsTerminated(state v1beta1.ApplicationStateType) bool {
+	return state == v1beta1.CompletedState || state == v1beta1.FailedState || state == v1beta1.SkippedState
+}
+
+// WaitForCompletion waits until the application with the given namespace and name has completed or failed, or
+// the given context is done, and returns the application as last seen. Applications restarted Always never
+// terminate, so waiting for them only ends with the context.
+func WaitForCompletion(ctx context.Context, client crdclientset.Interface, namespace, name string) (
+	*v1beta1.SparkApplication, error) {
+	return waitForCompletion(ctx, client, namespace, name, DefaultPollInterval)
+}
+
+func waitForCompletion(ctx context.Context, client crdclientset.Interface, namespace, name string,
+	interval time.Duration) (*v1beta1.SparkApplication, error) {
+	var app *v1beta1.SparkApplication
+	err := wait.PollImmediateUntil(interval, func() (bool, error) {
+		current, err := client.SparkoperatorV1beta1().SparkApplications(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		app = current
+		return IsTerminated(app.Status.AppState.State), nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return app, fmt.Errorf("failed to wait for SparkApplication %s/%s to complete: %v", namespace, name, err)
+	}
+	return app, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+)
+
+func TestWaitForCompletion(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+		},
+	}
+	client := crdclientfake.NewSimpleClientset()
+	if _, err := client.SparkoperatorV1beta1().SparkApplications("default").Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	// The application is still running when the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	last, err := waitForCompletion(ctx, client, "default", "foo", 10*time.Millisecond)
+	assert.NotNil(t, err)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, v1beta1.RunningState, last.Status.AppState.State)
+	}
+
+	app.Status.AppState.State = v1beta1.CompletedState
+	if _, err := client.SparkoperatorV1beta1().SparkApplications("default").Update(app); err != nil {
+		t.Fatal(err)
+	}
+	last, err = waitForCompletion(context.Background(), client, "default", "foo", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.NotNil(t, last) {
+		assert.Equal(t, v1beta1.CompletedState, last.Status.AppState.State)
+	}
+
+	last, err = waitForCompletion(context.Background(), client, "default", "bar", 10*time.Millisecond)
+	assert.NotNil(t, err)
+	assert.Nil(t, last)
+}
@@ -30,6 +30,14 @@ type FakeSparkoperatorV1beta1 struct {
 	*testing.Fake
 }
 
+func (c *FakeSparkoperatorV1beta1) HadoopClusters(namespace string) v1beta1.HadoopClusterInterface {
+	return &FakeHadoopClusters{c, namespace}
+}
+
+func (c *FakeSparkoperatorV1beta1) IngestJobs(namespace string) v1beta1.IngestJobInterface {
+	return &FakeIngestJobs{c, namespace}
+}
+
 func (c *FakeSparkoperatorV1beta1) ScheduledSparkApplications(namespace string) v1beta1.ScheduledSparkApplicationInterface {
 	return &FakeScheduledSparkApplications{c, namespace}
 }
@@ -38,6 +46,18 @@ func (c *FakeSparkoperatorV1beta1) SparkApplications(namespace string) v1beta1.S
 	return &FakeSparkApplications{c, namespace}
 }
 
+func (c *FakeSparkoperatorV1beta1) SparkApplicationRuns(namespace string) v1beta1.SparkApplicationRunInterface {
+	return &FakeSparkApplicationRuns{c, namespace}
+}
+
+func (c *FakeSparkoperatorV1beta1) SparkOperatorConfigurations() v1beta1.SparkOperatorConfigurationInterface {
+	return &FakeSparkOperatorConfigurations{c}
+}
+
+func (c *FakeSparkoperatorV1beta1) SparkThriftServers(namespace string) v1beta1.SparkThriftServerInterface {
+	return &FakeSparkThriftServers{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeSparkoperatorV1beta1) RESTClient() rest.Interface {
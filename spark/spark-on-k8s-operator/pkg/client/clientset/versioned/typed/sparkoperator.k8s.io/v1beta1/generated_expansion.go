@@ -20,6 +20,16 @@ limitations under the License.
 
 package v1beta1
 
+type HadoopClusterExpansion interface{}
+
+type IngestJobExpansion interface{}
+
 type ScheduledSparkApplicationExpansion interface{}
 
 type SparkApplicationExpansion interface{}
+
+type SparkApplicationRunExpansion interface{}
+
+type SparkOperatorConfigurationExpansion interface{}
+
+type SparkThriftServerExpansion interface{}
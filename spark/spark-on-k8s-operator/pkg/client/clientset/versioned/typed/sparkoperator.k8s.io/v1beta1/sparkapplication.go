@@ -39,6 +39,7 @@ type SparkApplicationsGetter interface {
 type SparkApplicationInterface interface {
 	Create(*v1beta1.SparkApplication) (*v1beta1.SparkApplication, error)
 	Update(*v1beta1.SparkApplication) (*v1beta1.SparkApplication, error)
+	UpdateStatus(*v1beta1.SparkApplication) (*v1beta1.SparkApplication, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1beta1.SparkApplication, error)
@@ -122,6 +123,22 @@ func (c *sparkApplications) Update(sparkApplication *v1beta1.SparkApplication) (
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *sparkApplications) UpdateStatus(sparkApplication *v1beta1.SparkApplication) (result *v1beta1.SparkApplication, err error) {
+	result = &v1beta1.SparkApplication{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("sparkapplications").
+		Name(sparkApplication.Name).
+		SubResource("status").
+		Body(sparkApplication).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the sparkApplication and deletes it. Returns an error if one occurs.
 func (c *sparkApplications) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -29,8 +29,13 @@ import (
 
 type SparkoperatorV1beta1Interface interface {
 	RESTClient() rest.Interface
+	HadoopClustersGetter
+	IngestJobsGetter
 	ScheduledSparkApplicationsGetter
 	SparkApplicationsGetter
+	SparkApplicationRunsGetter
+	SparkOperatorConfigurationsGetter
+	SparkThriftServersGetter
 }
 
 // SparkoperatorV1beta1Client is used to interact with features provided by the sparkoperator group.
@@ -38,6 +43,14 @@ type SparkoperatorV1beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *SparkoperatorV1beta1Client) HadoopClusters(namespace string) HadoopClusterInterface {
+	return newHadoopClusters(c, namespace)
+}
+
+func (c *SparkoperatorV1beta1Client) IngestJobs(namespace string) IngestJobInterface {
+	return newIngestJobs(c, namespace)
+}
+
 func (c *SparkoperatorV1beta1Client) ScheduledSparkApplications(namespace string) ScheduledSparkApplicationInterface {
 	return newScheduledSparkApplications(c, namespace)
 }
@@ -46,6 +59,18 @@ func (c *SparkoperatorV1beta1Client) SparkApplications(namespace string) SparkAp
 	return newSparkApplications(c, namespace)
 }
 
+func (c *SparkoperatorV1beta1Client) SparkApplicationRuns(namespace string) SparkApplicationRunInterface {
+	return newSparkApplicationRuns(c, namespace)
+}
+
+func (c *SparkoperatorV1beta1Client) SparkOperatorConfigurations() SparkOperatorConfigurationInterface {
+	return newSparkOperatorConfigurations(c)
+}
+
+func (c *SparkoperatorV1beta1Client) SparkThriftServers(namespace string) SparkThriftServerInterface {
+	return newSparkThriftServers(c, namespace)
+}
+
 // NewForConfig creates a new SparkoperatorV1beta1Client for the given config.
 func NewForConfig(c *rest.Config) (*SparkoperatorV1beta1Client, error) {
 	config := *c
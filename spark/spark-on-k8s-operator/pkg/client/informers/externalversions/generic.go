@@ -62,10 +62,20 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1alpha1().SparkApplications().Informer()}, nil
 
 		// Group=sparkoperator, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("hadoopclusters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().HadoopClusters().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("ingestjobs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().IngestJobs().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("scheduledsparkapplications"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().ScheduledSparkApplications().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("sparkapplicationruns"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().SparkApplicationRuns().Informer()}, nil
 	case v1beta1.SchemeGroupVersion.WithResource("sparkapplications"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().SparkApplications().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("sparkoperatorconfigurations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().SparkOperatorConfigurations().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("sparkthriftservers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sparkoperator().V1beta1().SparkThriftServers().Informer()}, nil
 
 	}
 
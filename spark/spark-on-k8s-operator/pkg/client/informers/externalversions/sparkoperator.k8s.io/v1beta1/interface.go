@@ -26,10 +26,20 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// HadoopClusters returns a HadoopClusterInformer.
+	HadoopClusters() HadoopClusterInformer
+	// IngestJobs returns a IngestJobInformer.
+	IngestJobs() IngestJobInformer
 	// ScheduledSparkApplications returns a ScheduledSparkApplicationInformer.
 	ScheduledSparkApplications() ScheduledSparkApplicationInformer
 	// SparkApplications returns a SparkApplicationInformer.
 	SparkApplications() SparkApplicationInformer
+	// SparkApplicationRuns returns a SparkApplicationRunInformer.
+	SparkApplicationRuns() SparkApplicationRunInformer
+	// SparkOperatorConfigurations returns a SparkOperatorConfigurationInformer.
+	SparkOperatorConfigurations() SparkOperatorConfigurationInformer
+	// SparkThriftServers returns a SparkThriftServerInformer.
+	SparkThriftServers() SparkThriftServerInformer
 }
 
 type version struct {
@@ -43,6 +53,16 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// HadoopClusters returns a HadoopClusterInformer.
+func (v *version) HadoopClusters() HadoopClusterInformer {
+	return &hadoopClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// IngestJobs returns a IngestJobInformer.
+func (v *version) IngestJobs() IngestJobInformer {
+	return &ingestJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ScheduledSparkApplications returns a ScheduledSparkApplicationInformer.
 func (v *version) ScheduledSparkApplications() ScheduledSparkApplicationInformer {
 	return &scheduledSparkApplicationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -52,3 +72,18 @@ func (v *version) ScheduledSparkApplications() ScheduledSparkApplicationInformer
 func (v *version) SparkApplications() SparkApplicationInformer {
 	return &sparkApplicationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// SparkApplicationRuns returns a SparkApplicationRunInformer.
+func (v *version) SparkApplicationRuns() SparkApplicationRunInformer {
+	return &sparkApplicationRunInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SparkOperatorConfigurations returns a SparkOperatorConfigurationInformer.
+func (v *version) SparkOperatorConfigurations() SparkOperatorConfigurationInformer {
+	return &sparkOperatorConfigurationInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// SparkThriftServers returns a SparkThriftServerInformer.
+func (v *version) SparkThriftServers() SparkThriftServerInformer {
+	return &sparkThriftServerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -20,6 +20,22 @@ limitations under the License.
 
 package v1beta1
 
+// HadoopClusterListerExpansion allows custom methods to be added to
+// HadoopClusterLister.
+type HadoopClusterListerExpansion interface{}
+
+// HadoopClusterNamespaceListerExpansion allows custom methods to be added to
+// HadoopClusterNamespaceLister.
+type HadoopClusterNamespaceListerExpansion interface{}
+
+// IngestJobListerExpansion allows custom methods to be added to
+// IngestJobLister.
+type IngestJobListerExpansion interface{}
+
+// IngestJobNamespaceListerExpansion allows custom methods to be added to
+// IngestJobNamespaceLister.
+type IngestJobNamespaceListerExpansion interface{}
+
 // ScheduledSparkApplicationListerExpansion allows custom methods to be added to
 // ScheduledSparkApplicationLister.
 type ScheduledSparkApplicationListerExpansion interface{}
@@ -35,3 +51,23 @@ type SparkApplicationListerExpansion interface{}
 // SparkApplicationNamespaceListerExpansion allows custom methods to be added to
 // SparkApplicationNamespaceLister.
 type SparkApplicationNamespaceListerExpansion interface{}
+
+// SparkApplicationRunListerExpansion allows custom methods to be added to
+// SparkApplicationRunLister.
+type SparkApplicationRunListerExpansion interface{}
+
+// SparkApplicationRunNamespaceListerExpansion allows custom methods to be added to
+// SparkApplicationRunNamespaceLister.
+type SparkApplicationRunNamespaceListerExpansion interface{}
+
+// SparkOperatorConfigurationListerExpansion allows custom methods to be added to
+// SparkOperatorConfigurationLister.
+type SparkOperatorConfigurationListerExpansion interface{}
+
+// SparkThriftServerListerExpansion allows custom methods to be added to
+// SparkThriftServerLister.
+type SparkThriftServerListerExpansion interface{}
+
+// SparkThriftServerNamespaceListerExpansion allows custom methods to be added to
+// SparkThriftServerNamespaceLister.
+type SparkThriftServerNamespaceListerExpansion interface{}
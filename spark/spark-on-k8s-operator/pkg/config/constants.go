@@ -33,6 +33,48 @@ const (
 	// HadoopConfDirEnvVar is the environment variable to add to the driver and executor Pods that point
 	// to the directory where the Hadoop ConfigMap is mounted.
 	HadoopConfDirEnvVar = "HADOOP_CONF_DIR"
+	// SparkLocalDirsEnvVar is the environment variable to add to the executor Pods that lists the
+	// directories Spark uses for shuffle and spill data.
+	SparkLocalDirsEnvVar = "SPARK_LOCAL_DIRS"
+	// StartGateDir is the directory where the start gate ConfigMap is mounted in the driver container.
+	StartGateDir = "/etc/spark-start-gate"
+	// StartGateVolumeName is the name of the ConfigMap volume of the start gate.
+	StartGateVolumeName = "spark-start-gate-volume"
+	// StartGateKey is the key of the start gate ConfigMap, and thus the file in StartGateDir, that is added
+	// once the start gate is open.
+	StartGateKey = "open"
+	// StartGateFileEnvVar is the environment variable to add to the driver Pod that points to the file that
+	// exists once the start gate is open.
+	StartGateFileEnvVar = "SPARK_START_GATE_FILE"
+	// GPUDiscoveryDir is the directory where the GPU discovery script ConfigMap is mounted in the executor
+	// container.
+	GPUDiscoveryDir = "/etc/spark-gpu-discovery"
+	// GPUDiscoveryVolumeName is the name of the ConfigMap volume of the GPU discovery script.
+	GPUDiscoveryVolumeName = "spark-gpu-discovery-volume"
+	// GPUDiscoveryScriptKey is the key of the GPU discovery script in its ConfigMap, and thus the file in
+	// GPUDiscoveryDir.
+	GPUDiscoveryScriptKey = "getGpusResources.sh"
+	// DumpDir is the directory where the PersistentVolumeClaim of the heap dumps and flight recordings of an
+	// application is mounted in the driver and executor containers, and in the container uploading them.
+	DumpDir = "/var/spark-dumps"
+	// DumpVolumeName is the name of the volume of the PersistentVolumeClaim of the dumps.
+	DumpVolumeName = "spark-dumps-volume"
+	// DataCacheHostDir is the directory on the nodes under which the cache daemons keep the data of each cache,
+	// in <namespace>/<name>.
+	DataCacheHostDir = "/var/cache/spark-data"
+	// DefaultDataCacheMountPath is the default path the data cache of an application is mounted at in its
+	// executors.
+	DefaultDataCacheMountPath = "/var/cache/spark-data"
+	// DataCacheVolumeName is the name of the hostPath volume of the data cache.
+	DataCacheVolumeName = "spark-data-cache-volume"
+	// DataCacheDirEnvVar is the environment variable of the cache daemons with the directory they keep the
+	// cached data in.
+	DataCacheDirEnvVar = "SPARK_DATA_CACHE_DIR"
+	// DataCacheSizeEnvVar is the environment variable of the cache daemons with the sizeLimit of the cache.
+	DataCacheSizeEnvVar = "SPARK_DATA_CACHE_SIZE"
+	// DataCacheNameEnvVar is the environment variable of the cache daemons and warmup Jobs with the name of the
+	// cache.
+	DataCacheNameEnvVar = "SPARK_DATA_CACHE_NAME"
 )
 
 const (
@@ -59,10 +101,37 @@ const (
 	// AffinityAnnotation is the name of the annotation added to the driver and executor Pods that
 	// specifies the value of the Pod Affinity.
 	AffinityAnnotation = LabelAnnotationPrefix + "affinity"
+	// ArchiveFinalizer is the finalizer holding deleted SparkApplications until they are archived.
+	ArchiveFinalizer = LabelAnnotationPrefix + "archive"
 	// SparkAppNameLabel is the name of the label for the SparkApplication object name.
 	SparkAppNameLabel = LabelAnnotationPrefix + "app-name"
 	// ScheduledSparkAppNameLabel is the name of the label for the ScheduledSparkApplication object name.
 	ScheduledSparkAppNameLabel = LabelAnnotationPrefix + "scheduled-app-name"
+	// IngestJobNameLabel is the name of the label for the IngestJob object name.
+	IngestJobNameLabel = LabelAnnotationPrefix + "ingest-job-name"
+	// SparkThriftServerNameLabel is the name of the label for the SparkThriftServer object name.
+	SparkThriftServerNameLabel = LabelAnnotationPrefix + "thrift-server-name"
+	// DataCacheNameLabel is the name of the label for the name of the data cache of cache daemons.
+	DataCacheNameLabel = LabelAnnotationPrefix + "data-cache"
+	// GeneratedSpecHashAnnotation is the name of the annotation on the SparkApplications generated for
+	// IngestJobs and SparkThriftServers that records the hash of the generated spec, which tells if the spec
+	// of the owner has changed since.
+	GeneratedSpecHashAnnotation = LabelAnnotationPrefix + "generated-spec-hash"
+	// PodTemplateHashAnnotation is the name of the annotation on driver and executor pods that records the hash
+	// of the parts of the spec of their SparkApplication that determined them.
+	PodTemplateHashAnnotation = LabelAnnotationPrefix + "pod-template-hash"
+	// DumpPathAnnotation is the name of the annotation on driver and executor pods that records the directory of
+	// the dumps of their run on the PersistentVolumeClaim of the dumps.
+	DumpPathAnnotation = LabelAnnotationPrefix + "dump-path"
+	// SparkAppQueueLabel is the name of the label for the scheduling queue of a SparkApplication. The
+	// namespace of a SparkApplication is used as its queue if the label is not set.
+	SparkAppQueueLabel = LabelAnnotationPrefix + "queue"
+	// SparkAppClassLabel is the default name of the label for the class of a SparkApplication, for which
+	// dashboards and alert rules are generated.
+	SparkAppClassLabel = LabelAnnotationPrefix + "app-class"
+	// SparkAppNamespaceMetricLabel is the metric label that is set to the namespace of a SparkApplication
+	// instead of the value of a label of it when listed in the metric labels.
+	SparkAppNamespaceMetricLabel = "app_namespace"
 	// LaunchedBySparkOperatorLabel is a label on Spark pods launched through the Spark Operator.
 	LaunchedBySparkOperatorLabel = LabelAnnotationPrefix + "launched-by-spark-operator"
 	// TolerationsAnnotationPrefix is the prefix of annotations that specify a Toleration.
@@ -75,6 +144,61 @@ const (
 	SparkDriverRole = "driver"
 	// SparkExecutorRole is the value of the spark-role label for the executors.
 	SparkExecutorRole = "executor"
+	// SparkResourceProfileIDLabel is the label set by the spark-distribution on executor Pods for the ID of the
+	// resource profile the executors are launched for.
+	SparkResourceProfileIDLabel = "spark-exec-resourceprofile-id"
+	// ExecutorGroupLabel is the label on executor Pods for the name of the executor group they belong to.
+	ExecutorGroupLabel = LabelAnnotationPrefix + "executor-group"
+	// SparkEnabledNamespaceLabel is the label on namespaces that should be bootstrapped for running Spark
+	// applications. Only namespaces with the label set to "true" are bootstrapped.
+	SparkEnabledNamespaceLabel = "spark-enabled"
+	// BootstrappedBySparkOperatorLabel is a label on objects created by the operator when bootstrapping a
+	// Spark-enabled namespace.
+	BootstrappedBySparkOperatorLabel = LabelAnnotationPrefix + "bootstrapped-by-spark-operator"
+	// SubmittedByAnnotation is the name of the annotation added to SparkApplications by the webhook that
+	// records the name of the user who created the SparkApplication.
+	SubmittedByAnnotation = LabelAnnotationPrefix + "submitted-by"
+	// SubmittedByGroupsAnnotation is the name of the annotation added to SparkApplications by the webhook
+	// that records the comma-separated groups of the user who created the SparkApplication.
+	SubmittedByGroupsAnnotation = LabelAnnotationPrefix + "submitted-by-groups"
+	// SubmittedByLabel is the name of the label added to SparkApplications by the webhook that records the
+	// name of the user who created the SparkApplication, sanitized to be a valid label value.
+	SubmittedByLabel = LabelAnnotationPrefix + "submitted-by"
+	// SparkAppStateLabel is the name of the label the operator keeps set to the state of a SparkApplication, so
+	// that applications can be listed by state with a label selector.
+	SparkAppStateLabel = LabelAnnotationPrefix + "app-state"
+	// AdoptedFromUIDAnnotation is the name of the annotation added to SparkApplications and
+	// ScheduledSparkApplications imported by sparkctl that records the UID of the exported original.
+	AdoptedFromUIDAnnotation = LabelAnnotationPrefix + "adopted-from-uid"
+	// InjectDefaultEnvAnnotation is the name of the annotation on namespaces and SparkApplications that, if set
+	// to "false", opts their Spark pods out of the default environment variables injected by the webhook.
+	InjectDefaultEnvAnnotation = LabelAnnotationPrefix + "inject-default-env"
+	// PatchAnnotation is the name of the annotation on SparkApplications holding RFC6902 JSON patch operations
+	// the webhook applies to their driver and executor pods, for pod fields the SparkApplication does not model.
+	PatchAnnotation = LabelAnnotationPrefix + "patch"
+	// RestartExecutorsAnnotation is the name of the annotation on SparkApplications holding the last request for
+	// a rolling restart of their executors, as set by "sparkctl restart-executors".
+	RestartExecutorsAnnotation = LabelAnnotationPrefix + "restart-executors"
+	// ResourcePressureAnnotation is the name of the annotation on executor pods holding the last resource pressure
+	// sample of the pod, as recorded by the node agent.
+	ResourcePressureAnnotation = LabelAnnotationPrefix + "resource-pressure"
+	// OwnerAnnotation is the name of the annotation on SparkApplications naming the user who owns the
+	// application in the metadata catalog. Defaults to the user who submitted the application.
+	OwnerAnnotation = LabelAnnotationPrefix + "owner"
+	// InputsAnnotation and OutputsAnnotation are the names of the annotations on SparkApplications listing the
+	// datasets the application reads and writes, which are recorded in the metadata catalog.
+	InputsAnnotation  = LabelAnnotationPrefix + "inputs"
+	OutputsAnnotation = LabelAnnotationPrefix + "outputs"
+	// LivyBatchIDLabel and LivySessionIDLabel are the names of the labels on SparkApplications created through
+	// the Livy-compatible API that record the ID of the batch or interactive session the application runs.
+	LivyBatchIDLabel   = LabelAnnotationPrefix + "livy-batch-id"
+	LivySessionIDLabel = LabelAnnotationPrefix + "livy-session-id"
+	// LivyNameAnnotation is the name of the annotation on SparkApplications created through the Livy-compatible
+	// API that records the name given to the batch or session by the client.
+	LivyNameAnnotation = LabelAnnotationPrefix + "livy-name"
+	// LivySessionKindAnnotation is the name of the annotation on the SparkApplications of Livy interactive
+	// sessions that records the default kind of the statements of the session.
+	LivySessionKindAnnotation = LabelAnnotationPrefix + "livy-session-kind"
 )
 
 const (
@@ -128,6 +252,9 @@ const (
 	// SparkDriverServiceAccountName is the Spark configuration key for specifying name of the Kubernetes service
 	// account used by the driver pod.
 	SparkDriverServiceAccountName = "spark.kubernetes.authenticate.driver.serviceAccountName"
+	// SparkExecutorServiceAccountName is the Spark configuration key for specifying name of the Kubernetes
+	// service account used by the executor pods.
+	SparkExecutorServiceAccountName = "spark.kubernetes.authenticate.executor.serviceAccountName"
 	// SparkInitContainerImage is the Spark configuration key for specifying a custom init-container image.
 	SparkInitContainerImage = "spark.kubernetes.initContainer.image"
 	// SparkJarsDownloadDir is the Spark configuration key for specifying the download path in the driver and
@@ -152,6 +279,194 @@ const (
 	SparkDriverJavaOptions = "spark.driver.extraJavaOptions"
 	// SparkExecutorJavaOptions is the Spark configuration key for a string of extra JVM options to pass to executors.
 	SparkExecutorJavaOptions = "spark.executor.extraJavaOptions"
+	// SparkExecutorGroupKeyPrefix is the Spark configuration key prefix for the properties describing the executor
+	// groups of an application, from which the application builds their resource profiles.
+	SparkExecutorGroupKeyPrefix = "spark.sparkoperator.executorGroup."
+	// SparkResourceProfileKeyPrefix is the Spark configuration key prefix for the properties describing the
+	// resource profiles of an application, from which the application builds them.
+	SparkResourceProfileKeyPrefix = "spark.sparkoperator.resourceProfile."
+	// SparkExecutorInstancesKey is the Spark configuration key for the number of executors of an application.
+	SparkExecutorInstancesKey = "spark.executor.instances"
+	// DefaultSparkExecutorInstances is the number of executors Spark runs if spark.executor.instances is not set.
+	DefaultSparkExecutorInstances = 2
+	// SparkDynamicAllocationEnabledKey is the Spark configuration key for enabling dynamic allocation, which is
+	// required for executors of resource profiles other than the default one.
+	SparkDynamicAllocationEnabledKey = "spark.dynamicAllocation.enabled"
+	// SparkDynamicAllocationShuffleTrackingKey is the Spark configuration key for enabling shuffle tracking, which
+	// allows dynamic allocation without an external shuffle service.
+	SparkDynamicAllocationShuffleTrackingKey = "spark.dynamicAllocation.shuffleTracking.enabled"
+	// SparkDynamicAllocationMaxExecutorsKey is the Spark configuration key for the maximum number of executors
+	// with dynamic allocation.
+	SparkDynamicAllocationMaxExecutorsKey = "spark.dynamicAllocation.maxExecutors"
+	// SparkSQLShufflePartitionsKey is the Spark configuration key for the number of partitions of shuffles of
+	// Spark SQL.
+	SparkSQLShufflePartitionsKey = "spark.sql.shuffle.partitions"
+	// SparkMinRegisteredResourcesRatioKey is the Spark configuration key for the ratio of the executors that must
+	// have registered before the driver starts scheduling tasks.
+	SparkMinRegisteredResourcesRatioKey = "spark.scheduler.minRegisteredResourcesRatio"
+	// SparkTaskCPUsKey is the Spark configuration key for the number of cores of each task.
+	SparkTaskCPUsKey = "spark.task.cpus"
+	// SparkExecutorResourceKeyPrefix is the Spark configuration key prefix for the custom resources of executors,
+	// e.g., spark.executor.resource.gpu.amount.
+	SparkExecutorResourceKeyPrefix = "spark.executor.resource."
+	// SparkTaskGPUAmountKey is the Spark configuration key for the number of GPUs of each task.
+	SparkTaskGPUAmountKey = "spark.task.resource.gpu.amount"
+	// SparkExecutorGPUDiscoveryScriptKey is the Spark configuration key for the script executors discover the
+	// addresses of their GPUs with.
+	SparkExecutorGPUDiscoveryScriptKey = "spark.executor.resource.gpu.discoveryScript"
+	// DefaultGPUDiscoveryScript is the GPU discovery script shipped with Spark, which lists NVIDIA GPUs.
+	DefaultGPUDiscoveryScript = "/opt/spark/examples/src/main/scripts/getGpusResources.sh"
+	// DefaultGPUResourceName is the extended resource of GPUs if an application does not name one.
+	DefaultGPUResourceName = "nvidia.com/gpu"
+	// SparkPluginsKey is the Spark configuration key for the comma-separated plugins of the driver and executors.
+	SparkPluginsKey = "spark.plugins"
+	// RapidsSQLPlugin is the Spark plugin of the RAPIDS Accelerator for Apache Spark.
+	RapidsSQLPlugin = "com.nvidia.spark.SQLPlugin"
+	// SparkRapidsSQLEnabledKey is the Spark configuration key for running SQL and DataFrame operations on GPUs
+	// with the RAPIDS Accelerator.
+	SparkRapidsSQLEnabledKey = "spark.rapids.sql.enabled"
+	// DefaultRapidsJar is the jar of the RAPIDS Accelerator added to applications that do not set one.
+	DefaultRapidsJar = "https://repo1.maven.org/maven2/com/nvidia/rapids-4-spark_2.12/23.02.0/rapids-4-spark_2.12-23.02.0.jar"
+	// SparkDriverPortKey is the Spark configuration key for the port the driver listens on.
+	SparkDriverPortKey = "spark.driver.port"
+	// SparkBlockManagerPortKey is the Spark configuration key for the port the block managers listen on.
+	SparkBlockManagerPortKey = "spark.blockManager.port"
+	// DefaultSparkDriverPort is the default driver port used by Spark on Kubernetes.
+	DefaultSparkDriverPort = "7078"
+	// DefaultSparkBlockManagerPort is the default block manager port used by Spark on Kubernetes.
+	DefaultSparkBlockManagerPort = "7079"
+	// SparkDriverHostKey is the Spark configuration key for the address the driver advertises to executors.
+	SparkDriverHostKey = "spark.driver.host"
+	// SparkDriverBindAddressKey is the Spark configuration key for the address the driver binds to.
+	SparkDriverBindAddressKey = "spark.driver.bindAddress"
+	// SparkDriverBlockManagerPortKey is the Spark configuration key for the port the block manager of the driver
+	// listens on.
+	SparkDriverBlockManagerPortKey = "spark.driver.blockManager.port"
+	// SparkUIPortKey is the Spark configuration key for the port the driver UI listens on.
+	SparkUIPortKey = "spark.ui.port"
+	// DefaultSparkUIPort is the default port of the driver UI.
+	DefaultSparkUIPort = "4040"
+)
+
+const (
+	// SparkSubmitOptsEnvVar is the environment variable for passing JVM options to spark-submit.
+	SparkSubmitOptsEnvVar = "SPARK_SUBMIT_OPTS"
+	// KubernetesImpersonateUserProperty is the Java system property used by the Kubernetes client in
+	// spark-submit for specifying the user to impersonate.
+	KubernetesImpersonateUserProperty = "kubernetes.impersonate.username"
+	// KubernetesImpersonateGroupProperty is the Java system property used by the Kubernetes client in
+	// spark-submit for specifying the groups to impersonate.
+	KubernetesImpersonateGroupProperty = "kubernetes.impersonate.group"
+)
+
+const (
+	// IstioExcludeInboundPortsAnnotation is the Istio annotation for specifying the inbound ports that are not
+	// redirected to the sidecar proxy.
+	IstioExcludeInboundPortsAnnotation = "traffic.sidecar.istio.io/excludeInboundPorts"
+	// IstioExcludeOutboundPortsAnnotation is the Istio annotation for specifying the outbound ports that are not
+	// redirected to the sidecar proxy.
+	IstioExcludeOutboundPortsAnnotation = "traffic.sidecar.istio.io/excludeOutboundPorts"
+	// IstioProxyContainerName is the name of the sidecar proxy container injected by Istio.
+	IstioProxyContainerName = "istio-proxy"
+	// IstioProxyQuitURLFormat is the format of the URL used to tell the Istio sidecar proxy of a pod to exit.
+	IstioProxyQuitURLFormat = "http://%s:15020/quitquitquit"
+)
+
+const (
+	// NodeOSLabel is the well-known node label for the operating system of a node.
+	NodeOSLabel = "kubernetes.io/os"
+	// BetaNodeOSLabel is the deprecated beta version of NodeOSLabel, still set on nodes by older kubelets.
+	BetaNodeOSLabel = "beta.kubernetes.io/os"
+	// LinuxNodeOS is the value of the node OS labels on Linux nodes.
+	LinuxNodeOS = "linux"
+	// NodeHostnameLabel is the well-known node label for the hostname of a node.
+	NodeHostnameLabel = "kubernetes.io/hostname"
+	// NodeZoneLabel is the well-known node label for the zone of a node.
+	NodeZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+	// NodeFeatureLabelPrefix is the prefix of the node labels Node Feature Discovery advertises features with.
+	NodeFeatureLabelPrefix = "feature.node.kubernetes.io/"
+)
+
+const (
+	// NodeNotReadyTaintKey is the key of the taint the node controller adds to nodes that are not ready.
+	NodeNotReadyTaintKey = "node.kubernetes.io/not-ready"
+	// NodeUnreachableTaintKey is the key of the taint the node controller adds to nodes that are unreachable.
+	NodeUnreachableTaintKey = "node.kubernetes.io/unreachable"
+)
+
+const (
+	// UIProxyContainerName is the name of the OAuth2 proxy sidecar container in front of the driver UI.
+	UIProxyContainerName = "oauth2-proxy"
+	// DefaultUIProxyImage is the container image of the OAuth2 proxy if not specified.
+	DefaultUIProxyImage = "quay.io/oauth2-proxy/oauth2-proxy:v7.4.0"
+	// DefaultUIProxyPort is the port the OAuth2 proxy listens on if not specified.
+	DefaultUIProxyPort int32 = 4180
+	// UIProxyClientSecretKey is the key of the OAuth2 client secret in the secret of the OAuth2 proxy.
+	UIProxyClientSecretKey = "client-secret"
+	// UIProxyCookieSecretKey is the key of the cookie signing secret in the secret of the OAuth2 proxy.
+	UIProxyCookieSecretKey = "cookie-secret"
+	// UIProxyStoppedAnnotation is the annotation of driver pods whose OAuth2 proxy the operator stopped after
+	// the Spark driver container terminated.
+	UIProxyStoppedAnnotation = LabelAnnotationPrefix + "ui-proxy-stopped"
+)
+
+const (
+	// ConnectionPoolerContainerName is the name of the PgBouncer sidecar container of the driver and executors.
+	ConnectionPoolerContainerName = "connection-pooler"
+	// DefaultConnectionPoolerImage is the container image of the PgBouncer sidecar if not specified.
+	DefaultConnectionPoolerImage = "edoburu/pgbouncer:1.18.0"
+	// DefaultConnectionPoolerPort is the port the PgBouncer sidecar listens on if not specified.
+	DefaultConnectionPoolerPort int32 = 6432
+	// DefaultConnectionPoolerDatabasePort is the port of the database of the PgBouncer sidecar if not specified.
+	DefaultConnectionPoolerDatabasePort int32 = 5432
+	// DefaultConnectionPoolerPoolSize is the maximum number of connections of each pod to the database if not
+	// specified.
+	DefaultConnectionPoolerPoolSize int32 = 2
+	// DefaultConnectionPoolerPoolMode is the pool mode of the PgBouncer sidecar if not specified.
+	DefaultConnectionPoolerPoolMode = "transaction"
+	// ConnectionPoolerUsernameKey is the key of the database user in the secret of the PgBouncer sidecar.
+	ConnectionPoolerUsernameKey = "username"
+	// ConnectionPoolerPasswordKey is the key of the database password in the secret of the PgBouncer sidecar.
+	ConnectionPoolerPasswordKey = "password"
+	// MetastoreConnectionURLKey is the Spark configuration key for the JDBC URL of the Hive metastore database.
+	MetastoreConnectionURLKey = "spark.hadoop.javax.jdo.option.ConnectionURL"
+)
+
+const (
+	// SeccompPodAnnotation is the annotation for specifying the seccomp profile of all containers of a pod.
+	SeccompPodAnnotation = "seccomp.security.alpha.kubernetes.io/pod"
+	// AppArmorAnnotationKeyPrefix is the prefix of the annotation for specifying the AppArmor profile of a
+	// container. The name of the container is appended to the prefix.
+	AppArmorAnnotationKeyPrefix = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+const (
+	// MultusNetworksAnnotation is the pod annotation listing the secondary networks Multus attaches to a pod.
+	MultusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+)
+
+const (
+	// AWSRoleARNAnnotation is the ServiceAccount annotation of IAM Roles for Service Accounts (IRSA) for
+	// specifying the IAM role pods running as the ServiceAccount assume.
+	AWSRoleARNAnnotation = "eks.amazonaws.com/role-arn"
+	// AWSRoleARNEnvVar is the environment variable the AWS SDKs read the IAM role to assume from.
+	AWSRoleARNEnvVar = "AWS_ROLE_ARN"
+	// AWSWebIdentityTokenFileEnvVar is the environment variable the AWS SDKs read the path of the web identity
+	// token the role is assumed with from.
+	AWSWebIdentityTokenFileEnvVar = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	// AWSIAMTokenVolumeName is the name of the projected ServiceAccount token volume of IRSA. It is the one the
+	// EKS pod identity webhook uses, so the webhook does not add it again.
+	AWSIAMTokenVolumeName = "aws-iam-token"
+	// AWSIAMTokenDir is the directory where the ServiceAccount token of IRSA is mounted.
+	AWSIAMTokenDir = "/var/run/secrets/eks.amazonaws.com/serviceaccount"
+	// AWSIAMTokenAudience is the audience of the ServiceAccount token of IRSA.
+	AWSIAMTokenAudience = "sts.amazonaws.com"
+	// AWSIAMTokenExpirationSeconds is the validity of the ServiceAccount token of IRSA, which the kubelet
+	// refreshes.
+	AWSIAMTokenExpirationSeconds int64 = 86400
+	// GCPServiceAccountAnnotation is the ServiceAccount annotation of GKE Workload Identity for specifying the
+	// Google service account pods running as the ServiceAccount impersonate.
+	GCPServiceAccountAnnotation = "iam.gke.io/gcp-service-account"
 )
 
 const (
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ExecutorRestartRequest is a request for a rolling restart of the executors of a running SparkApplication, as
+// recorded in its RestartExecutorsAnnotation.
+type ExecutorRestartRequest struct {
+	// RequestedAt is the time of the request, which tells it apart from earlier ones. Executors created before
+	// it are restarted.
+	RequestedAt time.Time `json:"requestedAt"`
+	// BatchSize is the number of executors deleted in each wave.
+	BatchSize int32 `json:"batchSize"`
+	// IntervalSeconds is the minimum number of seconds between two waves.
+	IntervalSeconds int64 `json:"intervalSeconds"`
+}
+
+// NewExecutorRestartRequest returns the value of the RestartExecutorsAnnotation requesting a rolling restart of
+// executors in batches of the given size at least the given interval apart.
+func NewExecutorRestartRequest(batchSize int32, interval time.Duration, now time.Time) (string, error) {
+	if batchSize < 1 {
+		return "", fmt.Errorf("the batch size must be positive, got %d", batchSize)
+	}
+	if interval < 0 {
+		return "", fmt.Errorf("the interval must not be negative, got %v", interval)
+	}
+	// The request time is compared with the times in the status of the application, which are in seconds.
+	value, err := json.Marshal(ExecutorRestartRequest{
+		RequestedAt:     now.UTC().Truncate(time.Second),
+		BatchSize:       batchSize,
+		IntervalSeconds: int64(interval / time.Second),
+	})
+	return string(value), err
+}
+
+// ParseExecutorRestartRequest parses the value of a RestartExecutorsAnnotation.
+func ParseExecutorRestartRequest(value string) (*ExecutorRestartRequest, error) {
+	var request ExecutorRestartRequest
+	if err := json.Unmarshal([]byte(value), &request); err != nil {
+		return nil, fmt.Errorf("invalid executor restart request %q: %v", value, err)
+	}
+	if request.RequestedAt.IsZero() || request.BatchSize < 1 || request.IntervalSeconds < 0 {
+		return nil, fmt.Errorf("invalid executor restart request %q", value)
+	}
+	return &request, nil
+}
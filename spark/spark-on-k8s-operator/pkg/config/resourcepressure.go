@@ -0,0 +1,55 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ResourcePressureSample is a sample of the cgroup v2 CPU throttling and pressure stall information (PSI) of an
+// executor pod, as recorded in its ResourcePressureAnnotation by the node agent. The counters are cumulative
+// since the pod started.
+type ResourcePressureSample struct {
+	// SampleTime is the time of the sample.
+	SampleTime time.Time `json:"sampleTime"`
+	// ElapsedMicros is the time from the start of the pod to the sample in microseconds.
+	ElapsedMicros int64 `json:"elapsedMicros"`
+	// CPUPeriods is the number of CFS enforcement periods in which the pod was runnable.
+	CPUPeriods int64 `json:"cpuPeriods"`
+	// CPUThrottledPeriods is the number of periods in which the pod was throttled by its CPU limits.
+	CPUThrottledPeriods int64 `json:"cpuThrottledPeriods"`
+	// CPUPressureMicros is the time in microseconds in which some tasks of the pod were stalled waiting for CPU.
+	CPUPressureMicros int64 `json:"cpuPressureMicros"`
+	// MemoryPressureMicros is the time in microseconds in which some tasks of the pod were stalled waiting for
+	// memory, e.g., for reclaim.
+	MemoryPressureMicros int64 `json:"memoryPressureMicros"`
+}
+
+// ParseResourcePressureSample parses the value of a ResourcePressureAnnotation.
+func ParseResourcePressureSample(value string) (*ResourcePressureSample, error) {
+	var sample ResourcePressureSample
+	if err := json.Unmarshal([]byte(value), &sample); err != nil {
+		return nil, fmt.Errorf("invalid resource pressure sample %q: %v", value, err)
+	}
+	if sample.ElapsedMicros < 0 || sample.CPUPeriods < 0 || sample.CPUThrottledPeriods < 0 ||
+		sample.CPUPressureMicros < 0 || sample.MemoryPressureMicros < 0 {
+		return nil, fmt.Errorf("invalid resource pressure sample %q", value)
+	}
+	return &sample, nil
+}
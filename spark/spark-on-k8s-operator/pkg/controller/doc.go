@@ -33,3 +33,6 @@ package controller
 // also uses a sparkPodMonitor to watch Spark driver and executor pods. The sparkPodMonitor sends driver
 // and executor state updates to the controller, which then updates status field of SparkApplication
 // objects accordingly.
+//
+// The Spark namespace bootstrap controller is responsible for watching namespaces labeled spark-enabled=true
+// and creating the driver service account, RBAC, resource defaults and network policies in them.
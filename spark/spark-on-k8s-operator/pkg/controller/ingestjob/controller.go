@@ -0,0 +1,314 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingestjob
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdscheme "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/scheme"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+var (
+	keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// Controller expands IngestJobs into SparkApplications generated from built-in templates, and reports the
+// state of the SparkApplication of each job in the status of the job. The SparkApplication of a job is
+// updated, and hence rerun, whenever the job changes.
+type Controller struct {
+	crdClient   crdclientset.Interface
+	kubeClient  kubernetes.Interface
+	queue       workqueue.RateLimitingInterface
+	cacheSynced []cache.InformerSynced
+	jobLister   crdlisters.IngestJobLister
+	appLister   crdlisters.SparkApplicationLister
+}
+
+// NewController creates a new Controller.
+func NewController(
+	crdClient crdclientset.Interface,
+	kubeClient kubernetes.Interface,
+	informerFactory crdinformers.SharedInformerFactory) *Controller {
+	crdscheme.AddToScheme(scheme.Scheme)
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"ingest-job-controller")
+
+	controller := &Controller{
+		crdClient:  crdClient,
+		kubeClient: kubeClient,
+		queue:      queue,
+	}
+
+	jobInformer := informerFactory.Sparkoperator().V1beta1().IngestJobs()
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) { controller.enqueue(newObj) },
+		DeleteFunc: controller.dequeue,
+	})
+	appInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications()
+	appInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onAppChange,
+		UpdateFunc: func(oldObj, newObj interface{}) { controller.onAppChange(newObj) },
+		DeleteFunc: controller.onAppChange,
+	})
+	controller.cacheSynced = []cache.InformerSynced{jobInformer.Informer().HasSynced, appInformer.Informer().HasSynced}
+	controller.jobLister = jobInformer.Lister()
+	controller.appLister = appInformer.Lister()
+
+	return controller
+}
+
+func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
+	glog.Info("Starting the IngestJob controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced...) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	glog.Info("Starting the workers of the IngestJob controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	return nil
+}
+
+func (c *Controller) Stop() {
+	glog.Info("Stopping the IngestJob controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncIngestJob(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to sync IngestJob %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+
+	c.queue.AddRateLimited(key)
+}
+
+func (c *Controller) dequeue(obj interface{}) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+
+	c.queue.Forget(key)
+	c.queue.Done(key)
+}
+
+// onAppChange enqueues the IngestJob owning the given SparkApplication, if any, to update its status.
+func (c *Controller) onAppChange(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	app, ok := obj.(*v1beta1.SparkApplication)
+	if !ok {
+		return
+	}
+	if owner := getOwningJob(app); owner != "" {
+		c.queue.Add(app.Namespace + "/" + owner)
+	}
+}
+
+// getOwningJob returns the name of the IngestJob the given SparkApplication was generated for, if any.
+func getOwningJob(app *v1beta1.SparkApplication) string {
+	if ref := metav1.GetControllerOf(app); ref != nil && ref.Kind == reflect.TypeOf(v1beta1.IngestJob{}).Name() {
+		return ref.Name
+	}
+	return ""
+}
+
+func (c *Controller) syncIngestJob(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	job, err := c.jobLister.IngestJobs(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The SparkApplication and ConfigMap of a deleted job are garbage collected.
+			return nil
+		}
+		return err
+	}
+
+	glog.V(2).Infof("Syncing IngestJob %s/%s", job.Namespace, job.Name)
+	status := job.Status.DeepCopy()
+	app, err := buildSparkApplication(job)
+	if err != nil {
+		glog.Errorf("invalid IngestJob %s/%s: %v", job.Namespace, job.Name, err)
+		status.AppState = v1beta1.ApplicationState{State: v1beta1.FailedState, ErrorMessage: err.Error()}
+		return c.updateIngestJobStatus(job, status)
+	}
+
+	if err := c.syncScripts(job); err != nil {
+		return err
+	}
+	current, err := c.syncSparkApplication(job, app)
+	if err != nil {
+		return err
+	}
+	status.SparkApplicationName = current.Name
+	status.AppState = current.Status.AppState
+	return c.updateIngestJobStatus(job, status)
+}
+
+// syncScripts creates or updates the ConfigMap with the script of the template of the given job.
+func (c *Controller) syncScripts(job *v1beta1.IngestJob) error {
+	configMap := buildScriptConfigMap(job)
+	existing, err := c.kubeClient.CoreV1().ConfigMaps(job.Namespace).Get(configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(job.Namespace).Create(configMap)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Data, configMap.Data) {
+		return nil
+	}
+	existing.Data = configMap.Data
+	_, err = c.kubeClient.CoreV1().ConfigMaps(job.Namespace).Update(existing)
+	return err
+}
+
+// syncSparkApplication creates the given SparkApplication generated for the given job, or updates the
+// existing one if the generated spec has changed, and returns the current SparkApplication.
+func (c *Controller) syncSparkApplication(job *v1beta1.IngestJob, app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
+	existing, err := c.appLister.SparkApplications(job.Namespace).Get(app.Name)
+	if errors.IsNotFound(err) {
+		glog.Infof("Creating SparkApplication %s/%s for IngestJob %s", app.Namespace, app.Name, job.Name)
+		return c.crdClient.SparkoperatorV1beta1().SparkApplications(job.Namespace).Create(app)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if getOwningJob(existing) != job.Name {
+		return nil, fmt.Errorf("SparkApplication %s/%s exists and is not owned by IngestJob %s",
+			existing.Namespace, existing.Name, job.Name)
+	}
+	if existing.Annotations[config.GeneratedSpecHashAnnotation] == app.Annotations[config.GeneratedSpecHashAnnotation] {
+		return existing, nil
+	}
+
+	// The SparkApplication controller reruns applications whose spec has changed.
+	glog.Infof("Updating SparkApplication %s/%s of changed IngestJob %s", app.Namespace, app.Name, job.Name)
+	toUpdate := existing.DeepCopy()
+	// Labels and annotations set by others, e.g., the state label of the application, are kept.
+	toUpdate.Labels = util.MergeOwnedEntries(existing.Labels, app.Labels)
+	toUpdate.Annotations = util.MergeOwnedEntries(existing.Annotations, app.Annotations)
+	toUpdate.Spec = app.Spec
+	return c.crdClient.SparkoperatorV1beta1().SparkApplications(job.Namespace).Update(toUpdate)
+}
+
+func (c *Controller) updateIngestJobStatus(job *v1beta1.IngestJob, newStatus *v1beta1.IngestJobStatus) error {
+	// If the status has not changed, do not perform an update.
+	if reflect.DeepEqual(newStatus, &job.Status) {
+		return nil
+	}
+
+	toUpdate := job.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		toUpdate.Status = *newStatus
+		_, updateErr := c.crdClient.SparkoperatorV1beta1().IngestJobs(toUpdate.Namespace).UpdateStatus(toUpdate)
+		if updateErr == nil {
+			return nil
+		}
+
+		result, err := c.crdClient.SparkoperatorV1beta1().IngestJobs(toUpdate.Namespace).Get(
+			toUpdate.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		toUpdate = result
+
+		return updateErr
+	})
+}
+
+func newOwnerReference(job *v1beta1.IngestJob) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: v1beta1.SchemeGroupVersion.String(),
+		Kind:       reflect.TypeOf(v1beta1.IngestJob{}).Name(),
+		Name:       job.Name,
+		UID:        job.UID,
+		Controller: &controller,
+	}
+}
+
+func buildScriptConfigMap(job *v1beta1.IngestJob) *apiv1.ConfigMap {
+	return &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            getScriptConfigMapName(job),
+			Namespace:       job.Namespace,
+			Labels:          map[string]string{config.IngestJobNameLabel: job.Name},
+			OwnerReferences: []metav1.OwnerReference{newOwnerReference(job)},
+		},
+		Data: map[string]string{scriptFileName: scripts[job.Spec.Template]},
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingestjob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestBuildSparkApplication_JDBCToParquet(t *testing.T) {
+	secret := "db-credentials"
+	column := "id"
+	lower := "0"
+	upper := "1000000"
+	var partitions int32 = 8
+	job := &v1beta1.IngestJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "ingest", Labels: map[string]string{"team": "a"}},
+		Spec: v1beta1.IngestJobSpec{
+			Template: v1beta1.JDBCToParquetTemplate,
+			JDBC: &v1beta1.JDBCSource{
+				URL:               "jdbc:postgresql://db:5432/shop",
+				Table:             "public.orders",
+				CredentialsSecret: &secret,
+				PartitionColumn:   &column,
+				LowerBound:        &lower,
+				UpperBound:        &upper,
+				NumPartitions:     &partitions,
+			},
+			OutputPath:   "s3a://lake/raw/orders",
+			SparkVersion: "2.4.0",
+		},
+	}
+
+	app, err := buildSparkApplication(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "orders", app.Name)
+	assert.Equal(t, "orders", app.Labels[config.IngestJobNameLabel])
+	assert.Equal(t, "a", app.Labels["team"])
+	assert.Equal(t, "IngestJob", app.OwnerReferences[0].Kind)
+	assert.Equal(t, v1beta1.PythonApplicationType, app.Spec.Type)
+	assert.Equal(t, "local:///etc/spark-ingest/ingest.py", *app.Spec.MainApplicationFile)
+	assert.Equal(t, []string{
+		"--url", "jdbc:postgresql://db:5432/shop",
+		"--table", "public.orders",
+		"--partition-column", "id",
+		"--lower-bound", "0",
+		"--upper-bound", "1000000",
+		"--num-partitions", "8",
+		"--output-path", "s3a://lake/raw/orders",
+	}, app.Spec.Arguments)
+	assert.Equal(t, []v1beta1.NamePath{{Name: "orders-ingest-script", Path: scriptMountPath}}, app.Spec.Driver.ConfigMaps)
+	assert.Equal(t, v1beta1.NameKey{Name: secret, Key: "password"}, app.Spec.Driver.EnvSecretKeyRefs[jdbcPasswordEnvVar])
+	assert.NotEmpty(t, app.Annotations[config.GeneratedSpecHashAnnotation])
+	// The job itself is not modified.
+	assert.Nil(t, job.Spec.Driver.ConfigMaps)
+
+	job.Spec.JDBC.NumPartitions = nil
+	_, err = buildSparkApplication(job)
+	assert.NotNil(t, err)
+}
+
+func TestBuildSparkApplication_KafkaToDelta(t *testing.T) {
+	interval := "1 minute"
+	job := &v1beta1.IngestJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "clicks", Namespace: "ingest"},
+		Spec: v1beta1.IngestJobSpec{
+			Template: v1beta1.KafkaToDeltaTemplate,
+			Kafka: &v1beta1.KafkaSource{
+				BootstrapServers: "kafka:9092",
+				Topic:            "clicks",
+				Options:          map[string]string{"kafka.security.protocol": "SSL"},
+				TriggerInterval:  &interval,
+			},
+			OutputPath:   "s3a://lake/raw/clicks",
+			SparkVersion: "2.4.0",
+			SparkConf:    map[string]string{"spark.sql.catalog.spark_catalog": "custom"},
+		},
+	}
+
+	app, err := buildSparkApplication(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		"--bootstrap-servers", "kafka:9092",
+		"--topic", "clicks",
+		"--option", "kafka.security.protocol=SSL",
+		"--checkpoint-path", "s3a://lake/raw/clicks/_checkpoint",
+		"--trigger-interval", "1 minute",
+		"--output-path", "s3a://lake/raw/clicks",
+	}, app.Spec.Arguments)
+	assert.Equal(t, "io.delta.sql.DeltaSparkSessionExtension", app.Spec.SparkConf["spark.sql.extensions"])
+	assert.Equal(t, "custom", app.Spec.SparkConf["spark.sql.catalog.spark_catalog"])
+
+	job.Spec.Kafka = nil
+	_, err = buildSparkApplication(job)
+	assert.NotNil(t, err)
+}
+
+func TestSyncIngestJob(t *testing.T) {
+	job := &v1beta1.IngestJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "ingest"},
+		Spec: v1beta1.IngestJobSpec{
+			Template:     v1beta1.JDBCToParquetTemplate,
+			JDBC:         &v1beta1.JDBCSource{URL: "jdbc:postgresql://db:5432/shop", Table: "orders"},
+			OutputPath:   "s3a://lake/raw/orders",
+			SparkVersion: "2.4.0",
+		},
+	}
+	c := newFakeController()
+	if _, err := c.crdClient.SparkoperatorV1beta1().IngestJobs(job.Namespace).Create(job); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.syncIngestJob("ingest/orders"); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err := c.kubeClient.CoreV1().ConfigMaps("ingest").Get("orders-ingest-script", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, jdbcToParquetScript, configMap.Data[scriptFileName])
+	app, err := c.crdClient.SparkoperatorV1beta1().SparkApplications("ingest").Get("orders", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := app.Annotations[config.GeneratedSpecHashAnnotation]
+
+	// The state of the SparkApplication is reported in the status of the job.
+	app.Status.AppState.State = v1beta1.RunningState
+	app.Labels["argocd.argoproj.io/instance"] = "lake"
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications("ingest").Update(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncIngestJob("ingest/orders"); err != nil {
+		t.Fatal(err)
+	}
+	job, err = c.crdClient.SparkoperatorV1beta1().IngestJobs("ingest").Get("orders", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "orders", job.Status.SparkApplicationName)
+	assert.Equal(t, v1beta1.RunningState, job.Status.AppState.State)
+
+	// A change of the job updates the spec of the SparkApplication.
+	job.Spec.OutputPath = "s3a://lake/raw/orders-v2"
+	if _, err := c.crdClient.SparkoperatorV1beta1().IngestJobs("ingest").Update(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncIngestJob("ingest/orders"); err != nil {
+		t.Fatal(err)
+	}
+	app, err = c.crdClient.SparkoperatorV1beta1().SparkApplications("ingest").Get("orders", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, hash, app.Annotations[config.GeneratedSpecHashAnnotation])
+	assert.Equal(t, "s3a://lake/raw/orders-v2", app.Spec.Arguments[len(app.Spec.Arguments)-1])
+	// Labels set by others are kept.
+	assert.Equal(t, "lake", app.Labels["argocd.argoproj.io/instance"])
+	assert.Equal(t, "orders", app.Labels[config.IngestJobNameLabel])
+
+	// An invalid job fails without a SparkApplication.
+	job.Spec.JDBC = nil
+	if _, err := c.crdClient.SparkoperatorV1beta1().IngestJobs("ingest").Update(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncIngestJob("ingest/orders"); err != nil {
+		t.Fatal(err)
+	}
+	job, err = c.crdClient.SparkoperatorV1beta1().IngestJobs("ingest").Get("orders", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.FailedState, job.Status.AppState.State)
+	assert.NotEmpty(t, job.Status.AppState.ErrorMessage)
+}
+
+func TestSyncIngestJob_NotOwned(t *testing.T) {
+	job := &v1beta1.IngestJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "ingest"},
+		Spec: v1beta1.IngestJobSpec{
+			Template:     v1beta1.JDBCToParquetTemplate,
+			JDBC:         &v1beta1.JDBCSource{URL: "jdbc:postgresql://db:5432/shop", Table: "orders"},
+			OutputPath:   "s3a://lake/raw/orders",
+			SparkVersion: "2.4.0",
+		},
+	}
+	c := newFakeController()
+	if _, err := c.crdClient.SparkoperatorV1beta1().IngestJobs(job.Namespace).Create(job); err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "ingest"}}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotNil(t, c.syncIngestJob("ingest/orders"))
+}
+
+func newFakeController() *Controller {
+	crdClient := crdclientfake.NewSimpleClientset()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 1*time.Second)
+	controller := NewController(crdClient, kubeClient, informerFactory)
+	jobInformer := informerFactory.Sparkoperator().V1beta1().IngestJobs().Informer()
+	appInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
+	crdClient.PrependReactor("create", "ingestjobs",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			jobInformer.GetStore().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("update", "ingestjobs",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.UpdateAction).GetObject()
+			jobInformer.GetStore().Update(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("create", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			appInformer.GetStore().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("update", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.UpdateAction).GetObject()
+			appInformer.GetStore().Update(obj)
+			return false, obj, nil
+		})
+	return controller
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingestjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	// scriptMountPath is where the ConfigMap with the script of a job is mounted in the driver.
+	scriptMountPath = "/etc/spark-ingest"
+	scriptFileName  = "ingest.py"
+
+	// The environment variables of the driver carrying the credentials of the database user.
+	jdbcUserEnvVar     = "JDBC_USER"
+	jdbcPasswordEnvVar = "JDBC_PASSWORD"
+)
+
+// scripts are the PySpark scripts of the built-in templates, which take the parameters of a job as arguments.
+var scripts = map[v1beta1.IngestTemplate]string{
+	v1beta1.JDBCToParquetTemplate: jdbcToParquetScript,
+	v1beta1.KafkaToDeltaTemplate:  kafkaToDeltaScript,
+}
+
+const jdbcToParquetScript = `import argparse
+import os
+
+from pyspark.sql import SparkSession
+
+parser = argparse.ArgumentParser()
+parser.add_argument("--url", required=True)
+parser.add_argument("--table", required=True)
+parser.add_argument("--output-path", required=True)
+parser.add_argument("--partition-column")
+parser.add_argument("--lower-bound")
+parser.add_argument("--upper-bound")
+parser.add_argument("--num-partitions")
+args = parser.parse_args()
+
+spark = SparkSession.builder.getOrCreate()
+reader = spark.read.format("jdbc").option("url", args.url).option("dbtable", args.table)
+if "JDBC_USER" in os.environ:
+    reader = reader.option("user", os.environ["JDBC_USER"]).option("password", os.environ.get("JDBC_PASSWORD", ""))
+if args.partition_column:
+    reader = (reader.option("partitionColumn", args.partition_column)
+              .option("lowerBound", args.lower_bound)
+              .option("upperBound", args.upper_bound)
+              .option("numPartitions", args.num_partitions))
+reader.load().write.mode("overwrite").parquet(args.output_path)
+spark.stop()
+`
+
+const kafkaToDeltaScript = `import argparse
+
+from pyspark.sql import SparkSession
+
+parser = argparse.ArgumentParser()
+parser.add_argument("--bootstrap-servers", required=True)
+parser.add_argument("--topic", required=True)
+parser.add_argument("--starting-offsets", default="earliest")
+parser.add_argument("--option", action="append", default=[])
+parser.add_argument("--output-path", required=True)
+parser.add_argument("--checkpoint-path", required=True)
+parser.add_argument("--trigger-interval")
+args = parser.parse_args()
+
+spark = SparkSession.builder.getOrCreate()
+reader = (spark.readStream.format("kafka")
+          .option("kafka.bootstrap.servers", args.bootstrap_servers)
+          .option("subscribe", args.topic)
+          .option("startingOffsets", args.starting_offsets))
+for option in args.option:
+    key, value = option.split("=", 1)
+    reader = reader.option(key, value)
+writer = (reader.load()
+          .selectExpr("CAST(key AS STRING) AS key", "CAST(value AS STRING) AS value",
+                      "topic", "partition", "offset", "timestamp")
+          .writeStream.format("delta")
+          .option("checkpointLocation", args.checkpoint_path))
+if args.trigger_interval:
+    writer = writer.trigger(processingTime=args.trigger_interval)
+else:
+    writer = writer.trigger(once=True)
+writer.start(args.output_path).awaitTermination()
+spark.stop()
+`
+
+// deltaSparkConf is the Spark configuration enabling Delta Lake, which jobs can override.
+var deltaSparkConf = map[string]string{
+	"spark.sql.extensions":            "io.delta.sql.DeltaSparkSessionExtension",
+	"spark.sql.catalog.spark_catalog": "org.apache.spark.sql.delta.catalog.DeltaCatalog",
+}
+
+func getScriptConfigMapName(job *v1beta1.IngestJob) string {
+	return util.BuildName(job.Name, "ingest-script", util.DNS1123SubdomainMaxLength)
+}
+
+// buildArguments returns the arguments of the script of the template of the given job, or an error if the
+// job lacks the source of its template.
+func buildArguments(job *v1beta1.IngestJob) ([]string, error) {
+	if job.Spec.OutputPath == "" {
+		return nil, fmt.Errorf("outputPath is required")
+	}
+	var args []string
+	switch job.Spec.Template {
+	case v1beta1.JDBCToParquetTemplate:
+		source := job.Spec.JDBC
+		if source == nil || source.URL == "" || source.Table == "" {
+			return nil, fmt.Errorf("template %s requires jdbc.url and jdbc.table", job.Spec.Template)
+		}
+		args = append(args, "--url", source.URL, "--table", source.Table)
+		if source.PartitionColumn != nil || source.LowerBound != nil || source.UpperBound != nil || source.NumPartitions != nil {
+			if source.PartitionColumn == nil || source.LowerBound == nil || source.UpperBound == nil || source.NumPartitions == nil {
+				return nil, fmt.Errorf("jdbc.partitionColumn, lowerBound, upperBound, and numPartitions must be set together")
+			}
+			args = append(args,
+				"--partition-column", *source.PartitionColumn,
+				"--lower-bound", *source.LowerBound,
+				"--upper-bound", *source.UpperBound,
+				"--num-partitions", fmt.Sprintf("%d", *source.NumPartitions))
+		}
+	case v1beta1.KafkaToDeltaTemplate:
+		source := job.Spec.Kafka
+		if source == nil || source.BootstrapServers == "" || source.Topic == "" {
+			return nil, fmt.Errorf("template %s requires kafka.bootstrapServers and kafka.topic", job.Spec.Template)
+		}
+		args = append(args, "--bootstrap-servers", source.BootstrapServers, "--topic", source.Topic)
+		if source.StartingOffsets != nil {
+			args = append(args, "--starting-offsets", *source.StartingOffsets)
+		}
+		var keys []string
+		for key := range source.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			args = append(args, "--option", fmt.Sprintf("%s=%s", key, source.Options[key]))
+		}
+		checkpointPath := job.Spec.OutputPath + "/_checkpoint"
+		if source.CheckpointPath != nil {
+			checkpointPath = *source.CheckpointPath
+		}
+		args = append(args, "--checkpoint-path", checkpointPath)
+		if source.TriggerInterval != nil {
+			args = append(args, "--trigger-interval", *source.TriggerInterval)
+		}
+	default:
+		return nil, fmt.Errorf("unknown template %q", job.Spec.Template)
+	}
+	return append(args, "--output-path", job.Spec.OutputPath), nil
+}
+
+// buildSparkApplication generates the SparkApplication running the given job from the template of the job.
+func buildSparkApplication(job *v1beta1.IngestJob) (*v1beta1.SparkApplication, error) {
+	args, err := buildArguments(job)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &v1beta1.SparkApplication{}
+	app.Name = job.Name
+	app.Namespace = job.Namespace
+	app.OwnerReferences = append(app.OwnerReferences, newOwnerReference(job))
+	app.Labels = make(map[string]string)
+	for key, value := range job.Labels {
+		app.Labels[key] = value
+	}
+	app.Labels[config.IngestJobNameLabel] = job.Name
+
+	spec := job.Spec.DeepCopy()
+	pythonVersion := "3"
+	mainApplicationFile := fmt.Sprintf("local://%s/%s", scriptMountPath, scriptFileName)
+	app.Spec = v1beta1.SparkApplicationSpec{
+		Type:                v1beta1.PythonApplicationType,
+		PythonVersion:       &pythonVersion,
+		Mode:                v1beta1.ClusterMode,
+		Image:               spec.Image,
+		MainApplicationFile: &mainApplicationFile,
+		Arguments:           args,
+		SparkVersion:        spec.SparkVersion,
+		Deps:                spec.Deps,
+		SparkConf:           spec.SparkConf,
+		HadoopConf:          spec.HadoopConf,
+		Driver:              spec.Driver,
+		Executor:            spec.Executor,
+		RestartPolicy:       spec.RestartPolicy,
+	}
+	app.Spec.Driver.ConfigMaps = append(app.Spec.Driver.ConfigMaps,
+		v1beta1.NamePath{Name: getScriptConfigMapName(job), Path: scriptMountPath})
+
+	switch job.Spec.Template {
+	case v1beta1.JDBCToParquetTemplate:
+		if secret := job.Spec.JDBC.CredentialsSecret; secret != nil {
+			if app.Spec.Driver.EnvSecretKeyRefs == nil {
+				app.Spec.Driver.EnvSecretKeyRefs = make(map[string]v1beta1.NameKey)
+			}
+			app.Spec.Driver.EnvSecretKeyRefs[jdbcUserEnvVar] = v1beta1.NameKey{Name: *secret, Key: "username"}
+			app.Spec.Driver.EnvSecretKeyRefs[jdbcPasswordEnvVar] = v1beta1.NameKey{Name: *secret, Key: "password"}
+		}
+	case v1beta1.KafkaToDeltaTemplate:
+		if app.Spec.SparkConf == nil {
+			app.Spec.SparkConf = make(map[string]string)
+		}
+		for key, value := range deltaSparkConf {
+			if _, ok := app.Spec.SparkConf[key]; !ok {
+				app.Spec.SparkConf[key] = value
+			}
+		}
+	}
+
+	hash, err := hashSpec(&app.Spec)
+	if err != nil {
+		return nil, err
+	}
+	app.Annotations = map[string]string{config.GeneratedSpecHashAnnotation: hash}
+	return app, nil
+}
+
+// hashSpec returns a hash of the given spec, which tells if the spec generated for a job has changed without
+// comparing it to the spec of the SparkApplication, which gets defaulted.
+func hashSpec(spec *v1beta1.SparkApplicationSpec) (string, error) {
+	specBytes, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hasher := util.NewHash32()
+	hasher.Write(specBytes)
+	return fmt.Sprintf("%08x", hasher.Sum32()), nil
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/golang/glog"
@@ -43,6 +44,7 @@ import (
 	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 var (
@@ -240,7 +242,7 @@ func (c *Controller) createSparkApplication(
 	scheduledApp *v1beta1.ScheduledSparkApplication, t time.Time) (string, error) {
 	app := &v1beta1.SparkApplication{}
 	app.Spec = scheduledApp.Spec.Template
-	app.Name = fmt.Sprintf("%s-%d", scheduledApp.Name, t.UnixNano())
+	app.Name = util.BuildName(scheduledApp.Name, strconv.FormatInt(t.UnixNano(), 10), util.DNS1123SubdomainMaxLength)
 	app.OwnerReferences = append(app.OwnerReferences, metav1.OwnerReference{
 		APIVersion: v1beta1.SchemeGroupVersion.String(),
 		Kind:       reflect.TypeOf(v1beta1.ScheduledSparkApplication{}).Name(),
@@ -295,7 +297,8 @@ func (c *Controller) startNextRun(app *v1beta1.ScheduledSparkApplication, now ti
 
 func (c *Controller) hasLastRunFinished(app *v1beta1.SparkApplication) bool {
 	return app.Status.AppState.State == v1beta1.CompletedState ||
-		app.Status.AppState.State == v1beta1.FailedState
+		app.Status.AppState.State == v1beta1.FailedState ||
+		app.Status.AppState.State == v1beta1.SkippedState
 }
 
 func (c *Controller) killLastRunIfNotFinished(app *v1beta1.SparkApplication) error {
@@ -355,7 +358,7 @@ func (c *Controller) updateScheduledSparkApplicationStatus(
 	toUpdate := app.DeepCopy()
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		toUpdate.Status = *newStatus
-		_, updateErr := c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(toUpdate.Namespace).Update(
+		_, updateErr := c.crdClient.SparkoperatorV1beta1().ScheduledSparkApplications(toUpdate.Namespace).UpdateStatus(
 			toUpdate)
 		if updateErr == nil {
 			return nil
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// missingDriverGracePeriod is how long after a submission the driver pod may not show up in the pod
+// informer cache before the driver is considered lost.
+const missingDriverGracePeriod = 2 * time.Minute
+
+// shouldAdoptDriverPod tells if the given driver pod was created for the given application by a submission
+// the operator did not get to record, e.g., because it stopped right after running spark-submit.
+func shouldAdoptDriverPod(app *v1beta1.SparkApplication, pod *apiv1.Pod) bool {
+	switch app.Status.AppState.State {
+	case v1beta1.NewState, v1beta1.QueuedState, v1beta1.FailedSubmissionState:
+	default:
+		return false
+	}
+
+	// Driver pods patched by the webhook are owned by the SparkApplication they were created for, which
+	// tells them apart from driver pods of a deleted SparkApplication with the same name.
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "SparkApplication" && ref.UID != app.UID {
+			return false
+		}
+	}
+	return true
+}
+
+// adoptDriverPod records the submission that created the given driver pod in the status of the application.
+func (c *Controller) adoptDriverPod(app *v1beta1.SparkApplication, pod *apiv1.Pod) {
+	glog.Infof("Adopting driver pod %s of SparkApplication %s/%s", pod.Name, app.Namespace, app.Name)
+	app.Status.SubmissionAttempts++
+	app.Status.ExecutionAttempts++
+	app.Status.LastSubmissionAttemptTime = pod.CreationTimestamp
+	if app.Status.LastSubmissionAttemptTime.IsZero() {
+		app.Status.LastSubmissionAttemptTime = metav1.Now()
+	}
+	app.Status.SubmittedBy = app.Annotations[config.SubmittedByAnnotation]
+	app.Status.AppState.ErrorMessage = ""
+	c.recorder.Eventf(
+		app,
+		apiv1.EventTypeNormal,
+		"SparkApplicationDriverAdopted",
+		"Driver pod %s of SparkApplication %s was adopted",
+		pod.Name,
+		app.Name)
+}
+
+// isDriverLost tells if the driver of the given submitted application is gone before the operator got to
+// observe it, e.g., because the driver pod was deleted while the operator was down. Applications with a
+// recorded driver pod are handled by the caller.
+func isDriverLost(app *v1beta1.SparkApplication) bool {
+	switch app.Status.AppState.State {
+	case v1beta1.SubmittedState, v1beta1.RunningState, v1beta1.UnknownState:
+	default:
+		return false
+	}
+	if app.Status.DriverInfo.PodName != "" || app.Status.LastSubmissionAttemptTime.IsZero() {
+		return false
+	}
+	return time.Since(app.Status.LastSubmissionAttemptTime.Time) > missingDriverGracePeriod
+}
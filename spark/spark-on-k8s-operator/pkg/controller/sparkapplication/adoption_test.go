@@ -0,0 +1,113 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newDriverPod(appName string, phase apiv1.PodPhase) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appName + "-driver",
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: appName,
+			},
+		},
+		Status: apiv1.PodStatus{Phase: phase},
+	}
+}
+
+func TestSyncSparkApplication_AdoptsDriverPod(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.NewState},
+		},
+	}
+	ctrl, recorder := newFakeController(app, newDriverPod(app.Name, apiv1.PodRunning))
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ctrl.syncSparkApplication("test/foo")
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.RunningState, updatedApp.Status.AppState.State)
+	assert.Equal(t, "foo-driver", updatedApp.Status.DriverInfo.PodName)
+	assert.Equal(t, int32(1), updatedApp.Status.SubmissionAttempts)
+	assert.Equal(t, int32(1), updatedApp.Status.ExecutionAttempts)
+	assert.False(t, updatedApp.Status.LastSubmissionAttemptTime.IsZero())
+	assert.Contains(t, <-recorder.Events, "SparkApplicationDriverAdopted")
+}
+
+func TestSyncSparkApplication_LostDriverPod(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.SubmittedState},
+			LastSubmissionAttemptTime: metav1.NewTime(time.Now().Add(-2 * missingDriverGracePeriod)),
+			ExecutionAttempts:         1,
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ctrl.syncSparkApplication("test/foo")
+	assert.Nil(t, err)
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.FailedState, updatedApp.Status.AppState.State)
+	assert.Equal(t, "Driver Pod not found", updatedApp.Status.AppState.ErrorMessage)
+}
+
+func TestShouldAdoptDriverPod(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-2"},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.QueuedState},
+		},
+	}
+	pod := newDriverPod(app.Name, apiv1.PodRunning)
+	assert.True(t, shouldAdoptDriverPod(app, pod))
+
+	// Driver pods left behind by a deleted SparkApplication with the same name are not adopted.
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "SparkApplication", Name: app.Name, UID: "uid-1"}}
+	assert.False(t, shouldAdoptDriverPod(app, pod))
+
+	pod.OwnerReferences[0].UID = app.UID
+	assert.True(t, shouldAdoptDriverPod(app, pod))
+
+	app.Status.AppState.State = v1beta1.RunningState
+	assert.False(t, shouldAdoptDriverPod(app, pod))
+}
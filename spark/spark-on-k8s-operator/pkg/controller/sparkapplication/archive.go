@@ -0,0 +1,72 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// hasArchiveFinalizer tells if the given application is held by the archive finalizer.
+func hasArchiveFinalizer(app *v1beta1.SparkApplication) bool {
+	for _, finalizer := range app.Finalizers {
+		if finalizer == config.ArchiveFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// addArchiveFinalizer adds the archive finalizer to the given application, so it is archived by a worker before
+// it is gone, and the driver pod with it. The update re-enqueues the application.
+func (c *Controller) addArchiveFinalizer(app *v1beta1.SparkApplication) error {
+	appToUpdate := app.DeepCopy()
+	appToUpdate.Finalizers = append(appToUpdate.Finalizers, config.ArchiveFinalizer)
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Update(appToUpdate); err != nil {
+		return fmt.Errorf("failed to add the archive finalizer to SparkApplication %s/%s: %v", app.Namespace,
+			app.Name, err)
+	}
+	return nil
+}
+
+// archiveDeletedApplication archives the given application being deleted and removes its archive finalizer.
+// Archival is best-effort: a failure to archive is logged and does not keep the application from being deleted.
+// Applications are not archived but still released if archival has been disabled since they were created.
+func (c *Controller) archiveDeletedApplication(app *v1beta1.SparkApplication) error {
+	if c.archiver != nil {
+		if err := c.archiver.Archive(app); err != nil {
+			glog.Errorf("failed to archive deleted SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+	}
+
+	appToUpdate := app.DeepCopy()
+	appToUpdate.Finalizers = nil
+	for _, finalizer := range app.Finalizers {
+		if finalizer != config.ArchiveFinalizer {
+			appToUpdate.Finalizers = append(appToUpdate.Finalizers, finalizer)
+		}
+	}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Update(appToUpdate); err != nil {
+		return fmt.Errorf("failed to remove the archive finalizer of SparkApplication %s/%s: %v", app.Namespace,
+			app.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cloud/blob/fileblob"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/archive"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestArchiveFinalizer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spark-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bucket, err := fileblob.NewBucket(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "uid-1"},
+	}
+	ctrl, _ := newFakeController(nil)
+	ctrl.archiver = archive.NewArchiver(ctrl.kubeClient, bucket, "", 0)
+	client := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("default")
+	if _, err := client.Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	// Applications are held by the finalizer while archival is enabled.
+	assert.False(t, hasArchiveFinalizer(app))
+	if err := ctrl.addArchiveFinalizer(app); err != nil {
+		t.Fatal(err)
+	}
+	held, err := client.Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{config.ArchiveFinalizer}, held.Finalizers)
+
+	// Deleted applications are archived, then released.
+	now := metav1.Now()
+	held.DeletionTimestamp = &now
+	held.Finalizers = append(held.Finalizers, "example.com/other")
+	if err := ctrl.archiveDeletedApplication(held); err != nil {
+		t.Fatal(err)
+	}
+	released, err := client.Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"example.com/other"}, released.Finalizers)
+	_, err = os.Stat(filepath.Join(dir, "default", "foo", "uid-1.json"))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"google.golang.org/api/iterator"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	defaultBytesPerPartition = "128Mi"
+	defaultBytesPerExecutor  = "4Gi"
+	// inputListingTimeout bounds the time spent listing the input of an application before submitting it.
+	inputListingTimeout = time.Minute
+)
+
+// inputSizer returns the total size in bytes of the objects with the path of the given gs:// or s3:// URL as
+// prefix.
+type inputSizer func(ctx context.Context, inputURL string) (int64, error)
+
+// SetInputStorage sets the endpoint and region of the S3-compatible storage the inputs of applications that set
+// autoTuning are listed from.
+func (c *Controller) SetInputStorage(endpoint string, region string) {
+	c.inputSizer = func(ctx context.Context, inputURL string) (int64, error) {
+		return getInputSize(ctx, inputURL, endpoint, region)
+	}
+}
+
+// getInputSize lists the objects with the path of the given gs:// or s3:// URL as prefix and sums up their sizes.
+func getInputSize(ctx context.Context, inputURL string, endpoint string, region string) (int64, error) {
+	parsed, err := url.Parse(inputURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse input URL %s: %v", inputURL, err)
+	}
+	if parsed.Host == "" {
+		return 0, fmt.Errorf("input URL %s has no bucket name", inputURL)
+	}
+	prefix := strings.TrimPrefix(parsed.Path, "/")
+
+	var size int64
+	switch parsed.Scheme {
+	case "gs":
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer client.Close()
+		objects := client.Bucket(parsed.Host).Objects(ctx, &storage.Query{Prefix: prefix})
+		for {
+			object, err := objects.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to list %s: %v", inputURL, err)
+			}
+			size += object.Size
+		}
+	case "s3":
+		// The AWS SDK requires a region even for S3-compatible endpoints.
+		if region == "" {
+			region = "us-east-1"
+		}
+		c := &aws.Config{Region: aws.String(region)}
+		if endpoint != "" {
+			c.Endpoint = aws.String(endpoint)
+			c.S3ForcePathStyle = aws.Bool(true)
+		}
+		sess, err := session.NewSession(c)
+		if err != nil {
+			return 0, err
+		}
+		input := &s3.ListObjectsV2Input{Bucket: aws.String(parsed.Host), Prefix: aws.String(prefix)}
+		err = s3.New(sess).ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, last bool) bool {
+			for _, object := range page.Contents {
+				size += aws.Int64Value(object.Size)
+			}
+			return true
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list %s: %v", inputURL, err)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported input URL scheme: %s", parsed.Scheme)
+	}
+	return size, nil
+}
+
+// getAutoTuningStatus returns the number of shuffle partitions and executors of the given application for an
+// input of the given size: one partition per bytesPerPartition and one executor per bytesPerExecutor, rounded
+// up, with the number of executors bounded by minExecutors and maxExecutors.
+func getAutoTuningStatus(tuning *v1beta1.AutoTuningSpec, inputBytes int64) (*v1beta1.AutoTuningStatus, error) {
+	bytesPerPartition, err := parseAutoTuningQuantity("bytesPerPartition", tuning.BytesPerPartition,
+		defaultBytesPerPartition)
+	if err != nil {
+		return nil, err
+	}
+	bytesPerExecutor, err := parseAutoTuningQuantity("bytesPerExecutor", tuning.BytesPerExecutor,
+		defaultBytesPerExecutor)
+	if err != nil {
+		return nil, err
+	}
+	minExecutors := int64(1)
+	if tuning.MinExecutors != nil {
+		minExecutors = int64(*tuning.MinExecutors)
+	}
+
+	partitions := (inputBytes + bytesPerPartition - 1) / bytesPerPartition
+	if partitions < 1 {
+		partitions = 1
+	}
+	executors := (inputBytes + bytesPerExecutor - 1) / bytesPerExecutor
+	if tuning.MaxExecutors != nil && executors > int64(*tuning.MaxExecutors) {
+		executors = int64(*tuning.MaxExecutors)
+	}
+	if executors < minExecutors {
+		executors = minExecutors
+	}
+	return &v1beta1.AutoTuningStatus{
+		InputBytes:        inputBytes,
+		ShufflePartitions: int32(partitions),
+		Executors:         int32(executors),
+	}, nil
+}
+
+func parseAutoTuningQuantity(field string, value *string, defaultValue string) (int64, error) {
+	if value == nil {
+		value = &defaultValue
+	}
+	quantity, err := resource.ParseQuantity(*value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid autoTuning %s %q: %v", field, *value, err)
+	}
+	if quantity.Value() <= 0 {
+		return 0, fmt.Errorf("invalid autoTuning %s %q: must be positive", field, *value)
+	}
+	return quantity.Value(), nil
+}
+
+// autoTune sizes the given application from the size of its input. It sets spark.sql.shuffle.partitions, and the
+// number of executors, or the maximum number of executors if the application uses dynamic allocation.
+func (c *Controller) autoTune(app *v1beta1.SparkApplication) (*v1beta1.AutoTuningStatus, error) {
+	tuning := app.Spec.AutoTuning
+	if len(tuning.InputPaths) == 0 {
+		return nil, fmt.Errorf("autoTuning requires inputPaths")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), inputListingTimeout)
+	defer cancel()
+	var inputBytes int64
+	for _, path := range tuning.InputPaths {
+		size, err := c.inputSizer(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the size of the input of autoTuning: %v", err)
+		}
+		inputBytes += size
+	}
+	status, err := getAutoTuningStatus(tuning, inputBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	app.Spec.SparkConf[config.SparkSQLShufflePartitionsKey] = strconv.Itoa(int(status.ShufflePartitions))
+	if app.Spec.SparkConf[config.SparkDynamicAllocationEnabledKey] == "true" {
+		app.Spec.SparkConf[config.SparkDynamicAllocationMaxExecutorsKey] = strconv.Itoa(int(status.Executors))
+	} else {
+		executors := status.Executors
+		app.Spec.Executor.Instances = &executors
+	}
+	return status, nil
+}
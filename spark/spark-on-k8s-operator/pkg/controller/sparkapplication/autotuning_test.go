@@ -0,0 +1,115 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetAutoTuningStatus(t *testing.T) {
+	int32ptr := func(n int32) *int32 { return &n }
+	type testcase struct {
+		name     string
+		tuning   v1beta1.AutoTuningSpec
+		bytes    int64
+		expected v1beta1.AutoTuningStatus
+	}
+	testcases := []testcase{
+		{
+			name:     "defaults",
+			bytes:    10 << 30,
+			expected: v1beta1.AutoTuningStatus{InputBytes: 10 << 30, ShufflePartitions: 80, Executors: 3},
+		},
+		{
+			name:     "empty input",
+			expected: v1beta1.AutoTuningStatus{ShufflePartitions: 1, Executors: 1},
+		},
+		{
+			name: "bounded",
+			tuning: v1beta1.AutoTuningSpec{
+				BytesPerPartition: stringptr("1Gi"),
+				MinExecutors:      int32ptr(4),
+				MaxExecutors:      int32ptr(10),
+			},
+			bytes:    100 << 30,
+			expected: v1beta1.AutoTuningStatus{InputBytes: 100 << 30, ShufflePartitions: 100, Executors: 10},
+		},
+		{
+			name:     "minimum",
+			tuning:   v1beta1.AutoTuningSpec{MinExecutors: int32ptr(4)},
+			bytes:    1 << 30,
+			expected: v1beta1.AutoTuningStatus{InputBytes: 1 << 30, ShufflePartitions: 8, Executors: 4},
+		},
+	}
+	for _, test := range testcases {
+		status, err := getAutoTuningStatus(&test.tuning, test.bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, test.expected, *status, test.name)
+	}
+
+	_, err := getAutoTuningStatus(&v1beta1.AutoTuningSpec{BytesPerExecutor: stringptr("0")}, 1)
+	assert.NotNil(t, err)
+}
+
+func TestAutoTune(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			AutoTuning: &v1beta1.AutoTuningSpec{InputPaths: []string{"gs://bucket/a", "s3://bucket/b"}},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	sizes := map[string]int64{"gs://bucket/a": 6 << 30, "s3://bucket/b": 2 << 30}
+	ctrl.inputSizer = func(ctx context.Context, inputURL string) (int64, error) {
+		size, ok := sizes[inputURL]
+		if !ok {
+			return 0, fmt.Errorf("%s not found", inputURL)
+		}
+		return size, nil
+	}
+
+	status, err := ctrl.autoTune(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(8<<30), status.InputBytes)
+	assert.Equal(t, "64", app.Spec.SparkConf[config.SparkSQLShufflePartitionsKey])
+	assert.Equal(t, int32(2), *app.Spec.Executor.Instances)
+
+	// With dynamic allocation, the maximum number of executors is set instead.
+	app.Spec.Executor.Instances = nil
+	app.Spec.SparkConf[config.SparkDynamicAllocationEnabledKey] = "true"
+	if _, err = ctrl.autoTune(app); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2", app.Spec.SparkConf[config.SparkDynamicAllocationMaxExecutorsKey])
+	assert.Nil(t, app.Spec.Executor.Instances)
+
+	app.Spec.AutoTuning.InputPaths = append(app.Spec.AutoTuning.InputPaths, "gs://bucket/missing")
+	_, err = ctrl.autoTune(app)
+	assert.NotNil(t, err)
+}
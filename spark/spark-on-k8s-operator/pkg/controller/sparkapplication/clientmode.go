@@ -0,0 +1,272 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// externalDriverPolicyRules are the permissions Spark needs to run executors for an external driver: managing
+// executor pods, the ConfigMaps with their configuration, and their on-demand PersistentVolumeClaims.
+var externalDriverPolicyRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "configmaps", "persistentvolumeclaims"},
+		Verbs:     []string{"*"},
+	},
+}
+
+// The ports an external driver listens on by default, which are the defaults of Spark on Kubernetes.
+const (
+	defaultExternalDriverPort             int32 = 7078
+	defaultExternalDriverBlockManagerPort int32 = 7079
+)
+
+// hasExternalDriver tells if the driver of the given application runs outside of the cluster.
+func hasExternalDriver(app *v1beta1.SparkApplication) bool {
+	return app.Spec.Mode == v1beta1.ClientMode && app.Spec.ExternalDriver != nil
+}
+
+// provisionExternalDriver creates what the external driver of the given application needs to run executors in
+// the cluster instead of running spark-submit: a ServiceAccount the driver authenticates as, a Role letting it
+// manage executor pods, and a headless Service executors reach the driver through. All of them are owned by the
+// application, so they are garbage collected along with it.
+func (c *Controller) provisionExternalDriver(app *v1beta1.SparkApplication) (*v1beta1.DriverInfo, error) {
+	if net.ParseIP(app.Spec.ExternalDriver.IP) == nil {
+		return nil, fmt.Errorf("invalid IP address %q of the external driver", app.Spec.ExternalDriver.IP)
+	}
+
+	name := getExternalDriverResourceName(app)
+	if err := c.ensureServiceAccount(app, name, externalDriverPolicyRules, nil); err != nil {
+		return nil, err
+	}
+	if err := c.ensureExternalDriverService(app, name); err != nil {
+		return nil, fmt.Errorf("failed to create Service %s/%s: %v", app.Namespace, name, err)
+	}
+	sparkConf, err := buildExternalDriverConf(app, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1beta1.DriverInfo{
+		ServiceName:        name,
+		ServiceAccountName: name,
+		SparkConf:          sparkConf,
+	}, nil
+}
+
+// submitExternalDriverApplication provisions the external driver of the given application in place of running
+// spark-submit for it.
+func (c *Controller) submitExternalDriverApplication(
+	app *v1beta1.SparkApplication,
+	appToSubmit *v1beta1.SparkApplication) *v1beta1.SparkApplication {
+	submittedBy := app.Annotations[config.SubmittedByAnnotation]
+	driverInfo, err := c.provisionExternalDriver(appToSubmit)
+	if err != nil {
+		app.Status = v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State:        v1beta1.FailedSubmissionState,
+				ErrorMessage: err.Error(),
+			},
+			SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
+			LastSubmissionAttemptTime: metav1.Now(),
+			SubmittedBy:               submittedBy,
+		}
+		c.recordSparkApplicationEvent(app)
+		glog.Errorf("failed to provision the external driver of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return app
+	}
+
+	glog.Infof("The external driver of SparkApplication %s/%s has been provisioned", app.Namespace, app.Name)
+	app.Status = v1beta1.SparkApplicationStatus{
+		AppState: v1beta1.ApplicationState{
+			State: v1beta1.SubmittedState,
+		},
+		DriverInfo:                *driverInfo,
+		SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
+		ExecutionAttempts:         app.Status.ExecutionAttempts + 1,
+		LastSubmissionAttemptTime: metav1.Now(),
+		SubmittedBy:               submittedBy,
+	}
+	c.recordSparkApplicationEvent(app)
+	return app
+}
+
+func getExternalDriverResourceName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "driver", util.DNS1123LabelMaxLength)
+}
+
+func getExternalDriverPorts(app *v1beta1.SparkApplication) (int32, int32) {
+	port, blockManagerPort := defaultExternalDriverPort, defaultExternalDriverBlockManagerPort
+	if app.Spec.ExternalDriver.Port != nil {
+		port = *app.Spec.ExternalDriver.Port
+	}
+	if app.Spec.ExternalDriver.BlockManagerPort != nil {
+		blockManagerPort = *app.Spec.ExternalDriver.BlockManagerPort
+	}
+	return port, blockManagerPort
+}
+
+// ensureExternalDriverService creates a headless Service without a selector and sets its Endpoints to the
+// address of the external driver, so executors reach the driver by a stable name in the cluster. The Endpoints
+// are updated if the address of the driver has changed since the last run.
+func (c *Controller) ensureExternalDriverService(app *v1beta1.SparkApplication, name string) error {
+	port, blockManagerPort := getExternalDriverPorts(app)
+	_, err := c.kubeClient.CoreV1().Services(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		service := &apiv1.Service{
+			ObjectMeta: buildAppResourceObjectMeta(app, name),
+			Spec: apiv1.ServiceSpec{
+				ClusterIP: apiv1.ClusterIPNone,
+				Ports: []apiv1.ServicePort{
+					{Name: "driver-rpc-port", Port: port},
+					{Name: "blockmanager", Port: blockManagerPort},
+				},
+			},
+		}
+		c.getPropagatedMetadata(app).apply(&service.ObjectMeta)
+		_, err = c.kubeClient.CoreV1().Services(app.Namespace).Create(service)
+		err = ignoreAlreadyExists(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	endpoints := &apiv1.Endpoints{
+		ObjectMeta: buildAppResourceObjectMeta(app, name),
+		Subsets: []apiv1.EndpointSubset{
+			{
+				Addresses: []apiv1.EndpointAddress{{IP: app.Spec.ExternalDriver.IP}},
+				Ports: []apiv1.EndpointPort{
+					{Name: "driver-rpc-port", Port: port},
+					{Name: "blockmanager", Port: blockManagerPort},
+				},
+			},
+		},
+	}
+	existing, err := c.kubeClient.CoreV1().Endpoints(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().Endpoints(app.Namespace).Create(endpoints)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing = existing.DeepCopy()
+	existing.Subsets = endpoints.Subsets
+	_, err = c.kubeClient.CoreV1().Endpoints(app.Namespace).Update(existing)
+	return err
+}
+
+// buildExternalDriverConf returns the Spark configuration the external driver must be started with for its
+// executors to reach it and to be managed by the operator like the executors of any other application.
+func buildExternalDriverConf(app *v1beta1.SparkApplication, serviceName string) (map[string]string, error) {
+	port, blockManagerPort := getExternalDriverPorts(app)
+	sparkConf := map[string]string{
+		"spark.submit.deployMode":             string(v1beta1.ClientMode),
+		"spark.kubernetes.namespace":          app.Namespace,
+		"spark.app.name":                      app.Name,
+		config.SparkDriverHostKey:             fmt.Sprintf("%s.%s.svc", serviceName, app.Namespace),
+		config.SparkDriverBindAddressKey:      "0.0.0.0",
+		config.SparkDriverPortKey:             fmt.Sprintf("%d", port),
+		config.SparkDriverBlockManagerPortKey: fmt.Sprintf("%d", blockManagerPort),
+	}
+	if app.Spec.Image != nil {
+		sparkConf[config.SparkContainerImageKey] = *app.Spec.Image
+	}
+	if app.Spec.ImagePullPolicy != nil {
+		sparkConf[config.SparkContainerImagePullPolicyKey] = *app.Spec.ImagePullPolicy
+	}
+	if len(app.Spec.ImagePullSecrets) > 0 {
+		sparkConf[config.SparkImagePullSecretKey] = strings.Join(app.Spec.ImagePullSecrets, ",")
+	}
+	for key, value := range app.Spec.NodeSelector {
+		sparkConf[config.SparkNodeSelectorKeyPrefix+key] = value
+	}
+
+	// The executor options label the executors so that the operator tracks them and the webhook patches them.
+	options, err := addExecutorConfOptions(app)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) == 2 {
+			sparkConf[parts[0]] = parts[1]
+		}
+	}
+
+	for key, value := range app.Spec.HadoopConf {
+		sparkConf["spark.hadoop."+key] = value
+	}
+	for key, value := range app.Spec.SparkConf {
+		sparkConf[key] = value
+	}
+	return sparkConf, nil
+}
+
+// getExternalDriverAppState returns the state of an application with an external driver, which is running as
+// long as any of its executors is running, and submitted while it waits for the driver to start executors.
+func getExternalDriverAppState(executorStateMap map[string]v1beta1.ExecutorState) v1beta1.ApplicationStateType {
+	for _, state := range executorStateMap {
+		if state == v1beta1.ExecutorRunningState {
+			return v1beta1.RunningState
+		}
+	}
+	return v1beta1.SubmittedState
+}
+
+// deleteExternalDriverExecutors deletes the executor pods of an application with an external driver. Unlike the
+// executors of a driver running in the cluster, they are not owned by a driver pod that takes them down with it.
+func (c *Controller) deleteExternalDriverExecutors(app *v1beta1.SparkApplication) error {
+	selector := labels.SelectorFromSet(labels.Set{
+		config.SparkAppNameLabel: app.Name,
+		config.SparkRoleLabel:    config.SparkExecutorRole,
+	})
+	pods, err := c.podLister.Pods(app.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		glog.V(2).Infof("Deleting executor pod %s/%s of SparkApplication %s", pod.Namespace, pod.Name, app.Name)
+		err := c.kubeClient.CoreV1().Pods(pod.Namespace).Delete(pod.Name, metav1.NewDeleteOptions(0))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func ignoreAlreadyExists(err error) error {
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
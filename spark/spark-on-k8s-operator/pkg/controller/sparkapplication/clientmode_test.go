@@ -0,0 +1,163 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/features"
+)
+
+func newExternalDriverApp() *v1beta1.SparkApplication {
+	image := "spark:latest"
+	instances := int32(2)
+	return &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "notebook", Namespace: "test", UID: "uid-1"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Mode:           v1beta1.ClientMode,
+			Image:          &image,
+			ExternalDriver: &v1beta1.ExternalDriverSpec{IP: "10.1.2.3"},
+			Executor:       v1beta1.ExecutorSpec{Instances: &instances},
+			SparkConf:      map[string]string{"spark.sql.shuffle.partitions": "10"},
+		},
+	}
+}
+
+func TestSyncSparkApplication_ExternalDriver(t *testing.T) {
+	app := newExternalDriverApp()
+	ctrl, _ := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.syncSparkApplication("test/notebook"); err != nil {
+		t.Fatal(err)
+	}
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.SubmittedState, updatedApp.Status.AppState.State)
+	assert.Equal(t, "notebook-driver", updatedApp.Status.DriverInfo.ServiceName)
+	assert.Equal(t, "notebook-driver", updatedApp.Status.DriverInfo.ServiceAccountName)
+	assert.Equal(t, "", updatedApp.Status.DriverInfo.WebUIServiceName)
+
+	sparkConf := updatedApp.Status.DriverInfo.SparkConf
+	assert.Equal(t, "notebook-driver.test.svc", sparkConf[config.SparkDriverHostKey])
+	assert.Equal(t, "0.0.0.0", sparkConf[config.SparkDriverBindAddressKey])
+	assert.Equal(t, "7078", sparkConf[config.SparkDriverPortKey])
+	assert.Equal(t, "7079", sparkConf[config.SparkDriverBlockManagerPortKey])
+	assert.Equal(t, "spark:latest", sparkConf[config.SparkContainerImageKey])
+	assert.Equal(t, "2", sparkConf["spark.executor.instances"])
+	assert.Equal(t, "notebook", sparkConf[config.SparkExecutorLabelKeyPrefix+config.SparkAppNameLabel])
+	assert.Equal(t, "true", sparkConf[config.SparkExecutorLabelKeyPrefix+config.LaunchedBySparkOperatorLabel])
+	assert.Equal(t, "10", sparkConf["spark.sql.shuffle.partitions"])
+
+	service, err := ctrl.kubeClient.CoreV1().Services(app.Namespace).Get("notebook-driver", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, apiv1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.Equal(t, "uid-1", string(service.OwnerReferences[0].UID))
+	endpoints, err := ctrl.kubeClient.CoreV1().Endpoints(app.Namespace).Get("notebook-driver", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "10.1.2.3", endpoints.Subsets[0].Addresses[0].IP)
+	_, err = ctrl.kubeClient.CoreV1().ServiceAccounts(app.Namespace).Get("notebook-driver", metav1.GetOptions{})
+	assert.Nil(t, err)
+	roleBinding, err := ctrl.kubeClient.RbacV1().RoleBindings(app.Namespace).Get("notebook-driver", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "notebook-driver", roleBinding.Subjects[0].Name)
+
+	// Provisioning again updates the Endpoints to the new address of the driver.
+	app.Spec.ExternalDriver.IP = "10.1.2.4"
+	if _, err := ctrl.provisionExternalDriver(app); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, _ = ctrl.kubeClient.CoreV1().Endpoints(app.Namespace).Get("notebook-driver", metav1.GetOptions{})
+	assert.Equal(t, "10.1.2.4", endpoints.Subsets[0].Addresses[0].IP)
+
+	app.Spec.ExternalDriver.IP = "notebook.example.com"
+	_, err = ctrl.provisionExternalDriver(app)
+	assert.NotNil(t, err)
+}
+
+func TestSyncSparkApplication_ExternalDriverDisabled(t *testing.T) {
+	if err := features.DefaultGate.Set("ExternalDrivers=false"); err != nil {
+		t.Fatal(err)
+	}
+	defer features.DefaultGate.Set("ExternalDrivers=true")
+
+	app := newExternalDriverApp()
+	ctrl, _ := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.syncSparkApplication("test/notebook"); err != nil {
+		t.Fatal(err)
+	}
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.FailedSubmissionState, updatedApp.Status.AppState.State)
+	assert.Contains(t, updatedApp.Status.AppState.ErrorMessage, "ExternalDrivers")
+	_, err = ctrl.kubeClient.CoreV1().Services(app.Namespace).Get("notebook-driver", metav1.GetOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestUpdateAppStatus_ExternalDriver(t *testing.T) {
+	app := newExternalDriverApp()
+	app.Status.AppState.State = v1beta1.SubmittedState
+	app.Status.LastSubmissionAttemptTime = metav1.Now()
+	executor := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "notebook-exec-1",
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkExecutorRole,
+				config.SparkAppNameLabel: app.Name,
+			},
+		},
+		Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+	}
+
+	ctrl, _ := newFakeController(app)
+	assert.Nil(t, ctrl.updateAppStatus(app))
+	assert.Equal(t, v1beta1.SubmittedState, app.Status.AppState.State)
+
+	ctrl, _ = newFakeController(app, executor)
+	assert.Nil(t, ctrl.updateAppStatus(app))
+	assert.Equal(t, v1beta1.RunningState, app.Status.AppState.State)
+	assert.Equal(t, v1beta1.ExecutorRunningState, app.Status.ExecutorState["notebook-exec-1"])
+
+	// The executors are deleted along with the resources of the application.
+	ctrl.kubeClient.CoreV1().Pods("test").Create(executor)
+	assert.Nil(t, ctrl.deleteSparkResources(app))
+	_, err := ctrl.kubeClient.CoreV1().Pods("test").Get("notebook-exec-1", metav1.GetOptions{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	configMapKind       = "ConfigMap"
+	secretKind          = "Secret"
+	configChangedReason = "ConfigChanged"
+)
+
+// WatchConfigChanges makes the controller watch the ConfigMaps and Secrets of the given informer factory, to
+// detect changes to the ones the driver and executors of submitted and running applications use. It must be
+// called before the controller and the informer factory are started.
+func (c *Controller) WatchConfigChanges(informerFactory informers.SharedInformerFactory) {
+	configMapInformer := informerFactory.Core().V1().ConfigMaps()
+	secretInformer := informerFactory.Core().V1().Secrets()
+	handler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.onConfigUpdate,
+		DeleteFunc: c.onConfigDelete,
+	}
+	configMapInformer.Informer().AddEventHandler(handler)
+	secretInformer.Informer().AddEventHandler(handler)
+	c.configMapLister = configMapInformer.Lister()
+	c.secretLister = secretInformer.Lister()
+
+	cacheSynced := c.cacheSynced
+	c.cacheSynced = func() bool {
+		return cacheSynced() && configMapInformer.Informer().HasSynced() && secretInformer.Informer().HasSynced()
+	}
+}
+
+func (c *Controller) onConfigUpdate(oldObj, newObj interface{}) {
+	// Periodic resyncs send updates of unchanged objects.
+	if oldObj.(metav1.Object).GetResourceVersion() == newObj.(metav1.Object).GetResourceVersion() {
+		return
+	}
+	c.enqueueConfigUsers(newObj)
+}
+
+func (c *Controller) onConfigDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	c.enqueueConfigUsers(obj)
+}
+
+// enqueueConfigUsers enqueues the submitted and running applications whose current run uses the given ConfigMap
+// or Secret.
+func (c *Controller) enqueueConfigUsers(obj interface{}) {
+	var key string
+	switch config := obj.(type) {
+	case *apiv1.ConfigMap:
+		key = getConfigKey(configMapKind, config.Name)
+	case *apiv1.Secret:
+		key = getConfigKey(secretKind, config.Name)
+	default:
+		return
+	}
+	namespace := obj.(metav1.Object).GetNamespace()
+	apps, err := c.applicationLister.SparkApplications(namespace).List(labels.Everything())
+	if err != nil {
+		glog.Errorf("failed to list SparkApplications in namespace %s: %v", namespace, err)
+		return
+	}
+	for _, app := range apps {
+		if _, ok := app.Status.ConfigHashes[key]; ok {
+			glog.V(2).Infof("%s of SparkApplication %s/%s was changed, enqueueing it", key, app.Namespace, app.Name)
+			c.enqueue(app)
+		}
+	}
+}
+
+func getConfigKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// getConfigReferences returns the keys of the ConfigMaps and Secrets the driver and executors of the given
+// application use, i.e., "ConfigMap/<name>" or "Secret/<name>", sorted.
+func getConfigReferences(app *v1beta1.SparkApplication) []string {
+	references := make(map[string]bool)
+	if app.Spec.SparkConfigMap != nil {
+		references[getConfigKey(configMapKind, *app.Spec.SparkConfigMap)] = true
+	}
+	if hadoopConfigMap := util.GetHadoopConfigMapName(app); hadoopConfigMap != nil {
+		references[getConfigKey(configMapKind, *hadoopConfigMap)] = true
+	}
+	for _, podSpec := range []v1beta1.SparkPodSpec{app.Spec.Driver.SparkPodSpec, app.Spec.Executor.SparkPodSpec} {
+		for _, configMap := range podSpec.ConfigMaps {
+			references[getConfigKey(configMapKind, configMap.Name)] = true
+		}
+		for _, secret := range podSpec.Secrets {
+			references[getConfigKey(secretKind, secret.Name)] = true
+		}
+		for _, ref := range podSpec.EnvSecretKeyRefs {
+			references[getConfigKey(secretKind, ref.Name)] = true
+		}
+	}
+	for _, volume := range app.Spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			references[getConfigKey(configMapKind, volume.ConfigMap.Name)] = true
+		case volume.Secret != nil:
+			references[getConfigKey(secretKind, volume.Secret.SecretName)] = true
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					references[getConfigKey(configMapKind, source.ConfigMap.Name)] = true
+				}
+				if source.Secret != nil {
+					references[getConfigKey(secretKind, source.Secret.Name)] = true
+				}
+			}
+		}
+	}
+
+	var keys []string
+	for key := range references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hashConfigData returns a hash of the given data of a ConfigMap or Secret.
+func hashConfigData(data interface{}) (string, error) {
+	// Maps are marshaled with sorted keys, so equal data have equal hashes.
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	hasher := util.NewHash32()
+	hasher.Write(dataBytes)
+	return fmt.Sprintf("%08x", hasher.Sum32()), nil
+}
+
+// getConfigHash returns the hash of the data of the ConfigMap or Secret with the given key in the given namespace,
+// which is empty if it does not exist.
+func (c *Controller) getConfigHash(namespace, key string) (string, error) {
+	parts := strings.SplitN(key, "/", 2)
+	switch parts[0] {
+	case configMapKind:
+		configMap, err := c.configMapLister.ConfigMaps(namespace).Get(parts[1])
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return hashConfigData([]interface{}{configMap.Data, configMap.BinaryData})
+	default:
+		secret, err := c.secretLister.Secrets(namespace).Get(parts[1])
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return hashConfigData(secret.Data)
+	}
+}
+
+// getConfigHashes returns the hashes of the ConfigMaps and Secrets the driver and executors of the given
+// application use, or nil if the controller does not watch them.
+func (c *Controller) getConfigHashes(app *v1beta1.SparkApplication) map[string]string {
+	if c.configMapLister == nil {
+		return nil
+	}
+	references := getConfigReferences(app)
+	if len(references) == 0 {
+		return nil
+	}
+	hashes := make(map[string]string, len(references))
+	for _, key := range references {
+		hash, err := c.getConfigHash(app.Namespace, key)
+		if err != nil {
+			glog.Errorf("failed to get %s of SparkApplication %s/%s: %v", key, app.Namespace, app.Name, err)
+		}
+		hashes[key] = hash
+	}
+	return hashes
+}
+
+// getChangedConfigs returns the keys of the ConfigMaps and Secrets whose hash has changed from the given
+// recorded hashes, sorted.
+func getChangedConfigs(recorded, current map[string]string) []string {
+	var changed []string
+	for key, hash := range recorded {
+		if current[key] != hash {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// detectConfigChange keeps the Stale condition of the given submitted or running application up to date, and
+// restarts the application if one of the ConfigMaps or Secrets its current run uses has changed and it sets
+// restartOnConfigChange.
+func (c *Controller) detectConfigChange(app *v1beta1.SparkApplication) {
+	state := app.Status.AppState.State
+	if c.configMapLister == nil || len(app.Status.ConfigHashes) == 0 ||
+		(state != v1beta1.SubmittedState && state != v1beta1.RunningState) {
+		return
+	}
+	changed := getChangedConfigs(app.Status.ConfigHashes, c.getConfigHashes(app))
+	condition := getApplicationCondition(&app.Status, v1beta1.StaleCondition)
+	if len(changed) == 0 {
+		if condition != nil {
+			setApplicationCondition(&app.Status, v1beta1.StaleCondition, apiv1.ConditionFalse, "", "")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("%s changed since the current run was submitted", strings.Join(changed, ", "))
+	if app.Spec.RestartOnConfigChange != nil && *app.Spec.RestartOnConfigChange {
+		glog.Infof("%s of SparkApplication %s/%s changed, restarting it", strings.Join(changed, ", "),
+			app.Namespace, app.Name)
+		setApplicationCondition(&app.Status, v1beta1.StaleCondition, apiv1.ConditionTrue, configChangedReason,
+			message+", the application is restarted")
+		app.Status.AppState.State = v1beta1.InvalidatingState
+		c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkApplicationConfigChanged",
+			"SparkApplication %s is restarted as %s", app.Name, message)
+		return
+	}
+	if condition == nil || condition.Status != apiv1.ConditionTrue {
+		c.recorder.Eventf(app, apiv1.EventTypeWarning, "SparkApplicationStale",
+			"SparkApplication %s is stale as %s", app.Name, message)
+	}
+	setApplicationCondition(&app.Status, v1beta1.StaleCondition, apiv1.ConditionTrue, configChangedReason, message)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestGetConfigReferences(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConfigMap: stringptr("spark-conf"),
+			Driver: v1beta1.DriverSpec{SparkPodSpec: v1beta1.SparkPodSpec{
+				ConfigMaps:       []v1beta1.NamePath{{Name: "driver-conf", Path: "/etc/driver"}},
+				EnvSecretKeyRefs: map[string]v1beta1.NameKey{"PASSWORD": {Name: "db", Key: "password"}},
+			}},
+			Executor: v1beta1.ExecutorSpec{SparkPodSpec: v1beta1.SparkPodSpec{
+				Secrets: []v1beta1.SecretInfo{{Name: "gcp-key", Path: "/etc/gcp"}},
+			}},
+			Volumes: []apiv1.Volume{
+				{Name: "certs", VolumeSource: apiv1.VolumeSource{Secret: &apiv1.SecretVolumeSource{SecretName: "certs"}}},
+				{Name: "tmp", VolumeSource: apiv1.VolumeSource{EmptyDir: &apiv1.EmptyDirVolumeSource{}}},
+				{Name: "projected", VolumeSource: apiv1.VolumeSource{Projected: &apiv1.ProjectedVolumeSource{
+					Sources: []apiv1.VolumeProjection{{ConfigMap: &apiv1.ConfigMapProjection{
+						LocalObjectReference: apiv1.LocalObjectReference{Name: "driver-conf"},
+					}}},
+				}}},
+			},
+		},
+	}
+	assert.Equal(t, []string{"ConfigMap/driver-conf", "ConfigMap/spark-conf", "Secret/certs", "Secret/db",
+		"Secret/gcp-key"}, getConfigReferences(app))
+}
+
+func TestDetectConfigChange(t *testing.T) {
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-conf", Namespace: "test"},
+		Data:       map[string]string{"log4j.properties": "log4j.rootCategory=INFO"},
+	}
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("foo")},
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConfigMap: stringptr("spark-conf"),
+			Driver: v1beta1.DriverSpec{SparkPodSpec: v1beta1.SparkPodSpec{
+				EnvSecretKeyRefs: map[string]v1beta1.NameKey{"PASSWORD": {Name: "db", Key: "password"}},
+			}},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+		},
+	}
+	ctrl, recorder := newFakeController(app)
+	informerFactory := informers.NewSharedInformerFactory(ctrl.kubeClient, 0*time.Second)
+	ctrl.WatchConfigChanges(informerFactory)
+	configMapIndexer := informerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	secretIndexer := informerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	configMapIndexer.Add(configMap)
+	secretIndexer.Add(secret)
+
+	app.Status.ConfigHashes = ctrl.getConfigHashes(app)
+	assert.Equal(t, 2, len(app.Status.ConfigHashes))
+	assert.NotEqual(t, "", app.Status.ConfigHashes["ConfigMap/spark-conf"])
+	assert.NotEqual(t, "", app.Status.ConfigHashes["Secret/db"])
+
+	ctrl.detectConfigChange(app)
+	assert.Nil(t, getApplicationCondition(&app.Status, v1beta1.StaleCondition))
+
+	// A changed Secret makes the application stale.
+	rotated := secret.DeepCopy()
+	rotated.Data["password"] = []byte("bar")
+	secretIndexer.Update(rotated)
+	ctrl.detectConfigChange(app)
+	condition := getApplicationCondition(&app.Status, v1beta1.StaleCondition)
+	assert.NotNil(t, condition)
+	assert.Equal(t, apiv1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Secret/db changed since the current run was submitted", condition.Message)
+	assert.Equal(t, v1beta1.RunningState, app.Status.AppState.State)
+	assert.Equal(t, 1, len(recorder.Events))
+
+	// The event is only emitted once.
+	ctrl.detectConfigChange(app)
+	assert.Equal(t, 1, len(recorder.Events))
+
+	// Reverting the change clears the condition.
+	secretIndexer.Update(secret)
+	ctrl.detectConfigChange(app)
+	assert.Equal(t, apiv1.ConditionFalse, getApplicationCondition(&app.Status, v1beta1.StaleCondition).Status)
+
+	// A deleted ConfigMap restarts an application that sets restartOnConfigChange.
+	restart := true
+	app.Spec.RestartOnConfigChange = &restart
+	configMapIndexer.Delete(configMap)
+	ctrl.detectConfigChange(app)
+	assert.Equal(t, v1beta1.InvalidatingState, app.Status.AppState.State)
+	assert.Equal(t, apiv1.ConditionTrue, getApplicationCondition(&app.Status, v1beta1.StaleCondition).Status)
+}
+
+func TestGetConfigHashesWithoutWatch(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{SparkConfigMap: stringptr("spark-conf")},
+	}
+	ctrl, _ := newFakeController(app)
+	assert.Nil(t, ctrl.getConfigHashes(app))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const (
+	// confSecretRefPrefix starts the values of sparkConf and hadoopConf that refer to a key of a Secret in the
+	// form secretKeyRef:<name>:<key>.
+	confSecretRefPrefix = "secretKeyRef:"
+	// sparkConfSecretEnvPrefix and hadoopConfSecretEnvPrefix prefix the environment variables the keys of Secrets
+	// sparkConf and hadoopConf refer to are exposed as.
+	sparkConfSecretEnvPrefix  = "SPARK_CONF_SECRET_"
+	hadoopConfSecretEnvPrefix = "HADOOP_CONF_SECRET_"
+)
+
+// parseConfSecretRef returns the name and key of the Secret the given conf value refers to, if it refers to one.
+func parseConfSecretRef(value string) (name string, key string, ok bool, err error) {
+	if !strings.HasPrefix(value, confSecretRefPrefix) {
+		return "", "", false, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, confSecretRefPrefix), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false, fmt.Errorf("invalid secret reference %q, expected %s<name>:<key>", value,
+			confSecretRefPrefix)
+	}
+	return parts[0], parts[1], true, nil
+}
+
+// getConfSecretEnvName returns the name of the environment variable the Secret key the given property refers to
+// is exposed as.
+func getConfSecretEnvName(prefix string, property string) string {
+	return prefix + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(property))
+}
+
+// exposeConfSecrets exposes the keys of Secrets the values of sparkConf and hadoopConf of the given application
+// refer to as environment variables the driver and executors get through secretKeyRefs, and removes the properties,
+// so the values of the keys are neither read by the operator nor passed to spark-submit. Spark and Hadoop do not
+// expand references to environment variables in arbitrary properties, so the application has to read the
+// variables itself. Only the copy of the application being submitted must be passed.
+func exposeConfSecrets(app *v1beta1.SparkApplication) error {
+	expose := func(conf map[string]string, prefix string) error {
+		for property, value := range conf {
+			name, key, ok, err := parseConfSecretRef(value)
+			if err != nil {
+				return fmt.Errorf("failed to expose %s: %v", property, err)
+			}
+			if !ok {
+				continue
+			}
+			env := getConfSecretEnvName(prefix, property)
+			ref := v1beta1.NameKey{Name: name, Key: key}
+			for _, podSpec := range []*v1beta1.SparkPodSpec{&app.Spec.Driver.SparkPodSpec,
+				&app.Spec.Executor.SparkPodSpec} {
+				if existing, found := podSpec.EnvSecretKeyRefs[env]; found && existing != ref {
+					return fmt.Errorf("failed to expose %s: environment variable %s is already set", property, env)
+				}
+				if podSpec.EnvSecretKeyRefs == nil {
+					podSpec.EnvSecretKeyRefs = make(map[string]v1beta1.NameKey)
+				}
+				podSpec.EnvSecretKeyRefs[env] = ref
+			}
+			delete(conf, property)
+		}
+		return nil
+	}
+
+	if err := expose(app.Spec.SparkConf, sparkConfSecretEnvPrefix); err != nil {
+		return err
+	}
+	return expose(app.Spec.HadoopConf, hadoopConfSecretEnvPrefix)
+}
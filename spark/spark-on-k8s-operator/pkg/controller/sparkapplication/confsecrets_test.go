@@ -0,0 +1,76 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestParseConfSecretRef(t *testing.T) {
+	name, key, ok, err := parseConfSecretRef("secretKeyRef:jdbc:password")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "jdbc", name)
+	assert.Equal(t, "password", key)
+
+	_, _, ok, err = parseConfSecretRef("plain")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, _, _, err = parseConfSecretRef("secretKeyRef:jdbc")
+	assert.NotNil(t, err)
+	_, _, _, err = parseConfSecretRef("secretKeyRef::password")
+	assert.NotNil(t, err)
+}
+
+func TestExposeConfSecrets(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConf: map[string]string{
+				"spark.jdbc.password": "secretKeyRef:jdbc:password",
+				"spark.jdbc.url":      "jdbc:postgresql://db/etl",
+			},
+			HadoopConf: map[string]string{"fs.s3a.access.key": "secretKeyRef:s3:access-key"},
+		},
+	}
+
+	err := exposeConfSecrets(app)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"spark.jdbc.url": "jdbc:postgresql://db/etl"}, app.Spec.SparkConf)
+	assert.Empty(t, app.Spec.HadoopConf)
+	expected := map[string]v1beta1.NameKey{
+		"SPARK_CONF_SECRET_SPARK_JDBC_PASSWORD": {Name: "jdbc", Key: "password"},
+		"HADOOP_CONF_SECRET_FS_S3A_ACCESS_KEY":  {Name: "s3", Key: "access-key"},
+	}
+	assert.Equal(t, expected, app.Spec.Driver.EnvSecretKeyRefs)
+	assert.Equal(t, expected, app.Spec.Executor.EnvSecretKeyRefs)
+
+	// Environment variables the application sets to other keys are not overwritten.
+	app.Spec.SparkConf["spark.jdbc.password"] = "secretKeyRef:other:password"
+	err = exposeConfSecrets(app)
+	assert.NotNil(t, err)
+
+	app.Spec.SparkConf["spark.jdbc.password"] = "secretKeyRef:jdbc"
+	err = exposeConfSecrets(app)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const hadoopConfKeyPrefix = "spark.hadoop."
+
+var poolModes = map[string]bool{"session": true, "transaction": true, "statement": true}
+
+// rewriteJDBCURL returns the given JDBC URL with its host and port replaced by localhost:<port>, keeping the
+// database and the parameters, e.g., jdbc:postgresql://db:5432/metastore becomes
+// jdbc:postgresql://localhost:6432/metastore.
+func rewriteJDBCURL(url string, port int32) (string, error) {
+	i := strings.Index(url, "://")
+	if !strings.HasPrefix(url, "jdbc:") || i < 0 {
+		return "", fmt.Errorf("%q is not a JDBC URL with a host", url)
+	}
+	start := i + len("://")
+	end := len(url)
+	if j := strings.IndexAny(url[start:], "/?;"); j >= 0 {
+		end = start + j
+	}
+	return fmt.Sprintf("%slocalhost:%d%s", url[:start], port, url[end:]), nil
+}
+
+// configConnectionPooler checks the connection pooler of the given application and points the JDBC URLs of the
+// Spark configuration properties it is used for to the sidecar the webhook adds to the driver and executors.
+func configConnectionPooler(app *v1beta1.SparkApplication) error {
+	pooler := app.Spec.ConnectionPooler
+	if pooler.DatabaseHost == "" {
+		return fmt.Errorf("connectionPooler requires a databaseHost")
+	}
+	if pooler.SecretName == "" {
+		return fmt.Errorf("connectionPooler requires a secretName")
+	}
+	if pooler.PoolMode != nil && !poolModes[*pooler.PoolMode] {
+		return fmt.Errorf("invalid connectionPooler poolMode %q", *pooler.PoolMode)
+	}
+
+	keys := pooler.URLConfKeys
+	if len(keys) == 0 {
+		keys = []string{config.MetastoreConnectionURLKey}
+	}
+	port := util.GetConnectionPoolerPort(app)
+	rewritten := 0
+	for _, key := range keys {
+		confs := []map[string]string{app.Spec.SparkConf}
+		confKeys := []string{key}
+		if strings.HasPrefix(key, hadoopConfKeyPrefix) {
+			confs = append(confs, app.Spec.HadoopConf)
+			confKeys = append(confKeys, strings.TrimPrefix(key, hadoopConfKeyPrefix))
+		}
+		for i, conf := range confs {
+			url, ok := conf[confKeys[i]]
+			if !ok {
+				continue
+			}
+			if strings.HasPrefix(url, confSecretRefPrefix) {
+				return fmt.Errorf("connectionPooler cannot rewrite %s as it refers to a Secret", key)
+			}
+			local, err := rewriteJDBCURL(url, port)
+			if err != nil {
+				return fmt.Errorf("connectionPooler cannot rewrite %s: %v", key, err)
+			}
+			conf[confKeys[i]] = local
+			rewritten++
+		}
+	}
+	if rewritten == 0 {
+		return fmt.Errorf("connectionPooler found none of %s set", strings.Join(keys, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestRewriteJDBCURL(t *testing.T) {
+	type testcase struct {
+		url      string
+		expected string
+		err      bool
+	}
+	testcases := []testcase{
+		{url: "jdbc:postgresql://db:5432/metastore", expected: "jdbc:postgresql://localhost:6432/metastore"},
+		{url: "jdbc:postgresql://db/metastore?ssl=true", expected: "jdbc:postgresql://localhost:6432/metastore?ssl=true"},
+		{url: "jdbc:postgresql://db:5432", expected: "jdbc:postgresql://localhost:6432"},
+		{url: "postgresql://db:5432/metastore", err: true},
+		{url: "jdbc:derby:metastore_db", err: true},
+	}
+	for _, test := range testcases {
+		url, err := rewriteJDBCURL(test.url, 6432)
+		assert.Equal(t, test.err, err != nil, test.url)
+		assert.Equal(t, test.expected, url, test.url)
+	}
+}
+
+func TestConfigConnectionPooler(t *testing.T) {
+	port := int32(7432)
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConf: map[string]string{
+				config.MetastoreConnectionURLKey: "jdbc:postgresql://metastore-db:5432/hive",
+			},
+			HadoopConf: map[string]string{"javax.jdo.option.ConnectionURL": "jdbc:postgresql://metastore-db/hive"},
+			ConnectionPooler: &v1beta1.ConnectionPoolerSpec{
+				DatabaseHost: "metastore-db",
+				SecretName:   "metastore-db-credentials",
+				Port:         &port,
+			},
+		},
+	}
+	assert.Nil(t, configConnectionPooler(app))
+	assert.Equal(t, "jdbc:postgresql://localhost:7432/hive", app.Spec.SparkConf[config.MetastoreConnectionURLKey])
+	assert.Equal(t, "jdbc:postgresql://localhost:7432/hive", app.Spec.HadoopConf["javax.jdo.option.ConnectionURL"])
+
+	// URLs in Secrets cannot be rewritten.
+	app.Spec.SparkConf[config.MetastoreConnectionURLKey] = "secretKeyRef:metastore:url"
+	assert.NotNil(t, configConnectionPooler(app))
+
+	// Some of the URLs must be set.
+	app.Spec.SparkConf = nil
+	app.Spec.HadoopConf = nil
+	app.Spec.ConnectionPooler.URLConfKeys = []string{"spark.sql.catalog.pg.url"}
+	assert.NotNil(t, configConnectionPooler(app))
+
+	invalid := "pipeline"
+	app.Spec.ConnectionPooler.PoolMode = &invalid
+	assert.NotNil(t, configConnectionPooler(app))
+}
@@ -20,10 +20,11 @@ import (
 	"fmt"
 	"os/exec"
 	"reflect"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
-	"golang.org/x/time/rate"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,23 +39,28 @@ import (
 	v1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/workqueue"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/archive"
 	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
 	crdscheme "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/scheme"
 	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/datahub"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/features"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/fips"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/lineage"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/notification"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/scheduler"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 const (
 	sparkExecutorIDLabel      = "spark-exec-id"
 	podAlreadyExistsErrorCode = "code=409"
-	queueTokenRefillRate      = 50
-	queueTokenBucketSize      = 500
 	maximumUpdateRetries      = 3
+	queuedAppRecheckInterval  = 10 * time.Second
 )
 
 var (
@@ -66,13 +72,42 @@ var (
 type Controller struct {
 	crdClient         crdclientset.Interface
 	kubeClient        clientset.Interface
-	queue             workqueue.RateLimitingInterface
+	queue             *laneQueue
 	cacheSynced       cache.InformerSynced
 	recorder          record.EventRecorder
 	metrics           *sparkAppMetrics
 	applicationLister crdlisters.SparkApplicationLister
 	podLister         v1.PodLister
+	configMapLister   v1.ConfigMapLister
+	secretLister      v1.SecretLister
 	ingressURLFormat  string
+	enableIstioMode   bool
+	impersonateUser   bool
+	scheduler         *scheduler.FairShareScheduler
+	archiver          *archive.Archiver
+	progress          *progressTracker
+	idleExecutors     *idleExecutorTracker
+	runHistory        *runHistoryTracker
+	distributions     []SparkDistribution
+	lineage           *lineage.Client
+	catalog           *datahub.Client
+	notifier          *notification.Notifier
+	submitters        map[string]Submitter
+	defaultSubmitter  string
+	submissionWorkers int
+	dataCacheImage    string
+	// dataCacheImages are the images besides dataCacheImage applications may run their cache daemons with.
+	dataCacheImages   []string
+	inputSizer        inputSizer
+	idempotencyWindow time.Duration
+	defaultsMutex     sync.RWMutex
+	defaultSparkConf  map[string]string
+	// allowedProxyUsers, proxyUserSuperuser, propagatedLabels and propagatedAnnotations are guarded by
+	// defaultsMutex.
+	allowedProxyUsers     []string
+	proxyUserSuperuser    string
+	propagatedLabels      []string
+	propagatedAnnotations []string
 }
 
 // NewController creates a new Controller.
@@ -83,7 +118,15 @@ func NewController(
 	podInformerFactory informers.SharedInformerFactory,
 	metricsConfig *util.MetricConfig,
 	namespace string,
-	ingressURLFormat string) *Controller {
+	ingressURLFormat string,
+	enableIstioMode bool,
+	impersonateUser bool,
+	appScheduler *scheduler.FairShareScheduler,
+	appArchiver *archive.Archiver,
+	progressInterval time.Duration,
+	sparkDistributions []SparkDistribution,
+	lineageClient *lineage.Client,
+	catalogClient *datahub.Client) *Controller {
 	crdscheme.AddToScheme(scheme.Scheme)
 
 	eventBroadcaster := record.NewBroadcaster()
@@ -93,7 +136,7 @@ func NewController(
 	})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "spark-operator"})
 
-	return newSparkApplicationController(crdClient, kubeClient, crdInformerFactory, podInformerFactory, recorder, metricsConfig, ingressURLFormat)
+	return newSparkApplicationController(crdClient, kubeClient, crdInformerFactory, podInformerFactory, recorder, metricsConfig, ingressURLFormat, enableIstioMode, impersonateUser, appScheduler, appArchiver, progressInterval, sparkDistributions, lineageClient, catalogClient)
 }
 
 func newSparkApplicationController(
@@ -103,21 +146,49 @@ func newSparkApplicationController(
 	podInformerFactory informers.SharedInformerFactory,
 	eventRecorder record.EventRecorder,
 	metricsConfig *util.MetricConfig,
-	ingressURLFormat string) *Controller {
-	queue := workqueue.NewNamedRateLimitingQueue(&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(queueTokenRefillRate), queueTokenBucketSize)},
-		"spark-application-controller")
-
+	ingressURLFormat string,
+	enableIstioMode bool,
+	impersonateUser bool,
+	appScheduler *scheduler.FairShareScheduler,
+	appArchiver *archive.Archiver,
+	progressInterval time.Duration,
+	sparkDistributions []SparkDistribution,
+	lineageClient *lineage.Client,
+	catalogClient *datahub.Client) *Controller {
 	controller := &Controller{
 		crdClient:        crdClient,
 		kubeClient:       kubeClient,
 		recorder:         eventRecorder,
-		queue:            queue,
 		ingressURLFormat: ingressURLFormat,
+		enableIstioMode:  enableIstioMode,
+		impersonateUser:  impersonateUser,
+		scheduler:        appScheduler,
+		archiver:         appArchiver,
+		distributions:    sparkDistributions,
+		lineage:          lineageClient,
+		catalog:          catalogClient,
+		idleExecutors:    newIdleExecutorTracker(),
+		runHistory:       newRunHistoryTracker(),
+		submitters: map[string]Submitter{
+			SparkSubmitSubmitterName: &SparkSubmitter{},
+			NativeSubmitterName:      NewNativePodBuilder(kubeClient),
+			DryRunSubmitterName:      &DryRunSubmitter{},
+		},
+		defaultSubmitter:  SparkSubmitSubmitterName,
+		idempotencyWindow: defaultIdempotencyWindow,
+	}
+	controller.queue = newLaneQueue(controller.getQueueLane)
+	controller.SetInputStorage("", "")
+
+	if progressInterval > 0 {
+		controller.progress = newProgressTracker(progressInterval, func(key string) { controller.queue.Add(key) })
 	}
 
 	if metricsConfig != nil {
 		controller.metrics = newSparkAppMetrics(metricsConfig.MetricsPrefix, metricsConfig.MetricsLabels)
 		controller.metrics.registerMetrics()
+		controller.queue.metrics = newLaneQueueMetrics(metricsConfig.MetricsPrefix)
+		controller.queue.metrics.registerMetrics()
 	}
 
 	crdInformer := crdInformerFactory.Sparkoperator().V1beta1().SparkApplications()
@@ -144,13 +215,26 @@ func newSparkApplicationController(
 	return controller
 }
 
+// SetSubmissionWorkers dedicates the given number of workers to submitting applications, which the other workers
+// then leave to them. All workers submit applications if the number is not positive.
+func (c *Controller) SetSubmissionWorkers(workers int) {
+	c.submissionWorkers = workers
+}
+
 // Start starts the Controller by registering a watcher for SparkApplication objects.
 func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
 	glog.Info("Starting the workers of the SparkApplication controller")
+	var lanes []queueLane
+	if c.submissionWorkers > 0 {
+		lanes = []queueLane{statusLane, cleanupLane}
+		for i := 0; i < c.submissionWorkers; i++ {
+			go wait.Until(func() { c.runWorker(submissionLane) }, time.Second, stopCh)
+		}
+	}
 	for i := 0; i < workers; i++ {
 		// runWorker will loop until "something bad" happens. Until will then rekick
 		// the worker after one second.
-		go wait.Until(c.runWorker, time.Second, stopCh)
+		go wait.Until(func() { c.runWorker(lanes...) }, time.Second, stopCh)
 	}
 
 	// Wait for all involved caches to be synced, before processing items from the queue is started.
@@ -164,6 +248,9 @@ func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
 func (c *Controller) Stop() {
 	glog.Info("Stopping the SparkApplication controller")
 	c.queue.ShutDown()
+	if c.progress != nil {
+		c.progress.stop()
+	}
 }
 
 // Callback function called when a new SparkApplication object gets created.
@@ -178,35 +265,77 @@ func (c *Controller) onUpdate(oldObj, newObj interface{}) {
 	oldApp := oldObj.(*v1beta1.SparkApplication)
 	newApp := newObj.(*v1beta1.SparkApplication)
 
-	// The spec has changed. This is currently best effort as we can potentially miss updates
-	// and end up in an inconsistent state.
-	if !reflect.DeepEqual(oldApp.Spec, newApp.Spec) {
-		// Force-set the application status to Invalidating which handles clean-up and application re-run.
-		if _, err := c.updateApplicationStatusWithRetries(newApp, func(status *v1beta1.SparkApplicationStatus) {
-			status.AppState.State = v1beta1.InvalidatingState
-		}); err != nil {
-			c.recorder.Eventf(
-				newApp,
-				apiv1.EventTypeWarning,
-				"SparkApplicationSpecUpdateFailed",
-				"failed to process spec update for SparkApplication %s: %v",
-				newApp.Name,
-				err)
-			return
-		}
+	// The spec has changed. This is currently best effort as we can potentially miss updates, which the
+	// drift detection of submitted and running applications catches up on.
+	if !reflect.DeepEqual(oldApp.Spec, newApp.Spec) && !isSourceLoad(oldApp, newApp) {
+		c.processSpecUpdate(oldApp, newApp)
+	}
 
-		c.recorder.Eventf(
-			newApp,
-			apiv1.EventTypeNormal,
-			"SparkApplicationSpecUpdateProcessed",
-			"Successfully processed spec update for SparkApplication %s",
+	if isStatusOnlyUpdate(oldApp, newApp) {
+		glog.V(2).Infof("Only the status of SparkApplication %s/%s was updated, not enqueueing it", newApp.Namespace,
 			newApp.Name)
+		return
 	}
 
 	glog.V(2).Infof("SparkApplication %s/%s was updated, enqueueing it", newApp.Namespace, newApp.Name)
 	c.enqueue(newApp)
 }
 
+// isStatusOnlyUpdate tells if the given update of an application only changed its status without changing its
+// state, which is mostly the operator recording what it has just done and does not need another reconcile.
+// Periodic resyncs, which do not change the resource version, are not status-only updates.
+func isStatusOnlyUpdate(oldApp, newApp *v1beta1.SparkApplication) bool {
+	return oldApp.ResourceVersion != newApp.ResourceVersion &&
+		oldApp.Generation == newApp.Generation &&
+		oldApp.Status.AppState.State == newApp.Status.AppState.State &&
+		oldApp.DeletionTimestamp.Equal(newApp.DeletionTimestamp) &&
+		reflect.DeepEqual(oldApp.Spec, newApp.Spec) &&
+		reflect.DeepEqual(withoutStateLabel(oldApp.Labels), withoutStateLabel(newApp.Labels)) &&
+		reflect.DeepEqual(oldApp.Annotations, newApp.Annotations) &&
+		reflect.DeepEqual(oldApp.Finalizers, newApp.Finalizers)
+}
+
+// processSpecUpdate re-runs the given application if its spec has changed in a way that requires new pods.
+// Changes to fields the operator reads itself apply to the current run. Which fields changed, and whether they
+// required a restart, is recorded in the status.
+func (c *Controller) processSpecUpdate(oldApp, newApp *v1beta1.SparkApplication) {
+	hash := getPodTemplateHash(newApp)
+	restart := hash != getPodTemplateHash(oldApp)
+	update := newSpecUpdateStatus(oldApp, newApp, restart)
+
+	sourceChanged := !reflect.DeepEqual(oldApp.Spec.SourceRef, newApp.Spec.SourceRef)
+	if _, err := c.updateApplicationStatusWithRetries(newApp, func(status *v1beta1.SparkApplicationStatus) {
+		status.LastSpecUpdate = update
+		status.ObservedGeneration = newApp.Generation
+		if !restart {
+			return
+		}
+		if status.PodTemplateHash != "" {
+			setUpdateRequired(status, hash)
+		}
+		// The memory was increased from the old spec.
+		status.MemoryBump = nil
+		// Force-set the application status to Invalidating which handles clean-up and application re-run.
+		status.AppState.State = v1beta1.InvalidatingState
+		if sourceChanged {
+			// Load the spec from the new source when the application re-runs.
+			status.SourceRevision = ""
+		}
+	}); err != nil {
+		c.recorder.Eventf(
+			newApp,
+			apiv1.EventTypeWarning,
+			"SparkApplicationSpecUpdateFailed",
+			"failed to process spec update for SparkApplication %s: %v",
+			newApp.Name,
+			err)
+		return
+	}
+
+	c.recorder.Event(newApp, apiv1.EventTypeNormal, "SparkApplicationSpecUpdateProcessed",
+		getSpecUpdateMessage(newApp, update))
+}
+
 func (c *Controller) onDelete(obj interface{}) {
 	var app *v1beta1.SparkApplication
 	switch obj.(type) {
@@ -228,15 +357,16 @@ func (c *Controller) onDelete(obj interface{}) {
 	}
 }
 
-// runWorker runs a single controller worker.
-func (c *Controller) runWorker() {
+// runWorker runs a single controller worker processing the applications in the given lanes of the queue, or in
+// all lanes if none are given.
+func (c *Controller) runWorker(lanes ...queueLane) {
 	defer utilruntime.HandleCrash()
-	for c.processNextItem() {
+	for c.processNextItem(lanes) {
 	}
 }
 
-func (c *Controller) processNextItem() bool {
-	key, quit := c.queue.Get()
+func (c *Controller) processNextItem(lanes []queueLane) bool {
+	key, quit := c.queue.getFrom(lanes)
 
 	if quit {
 		return false
@@ -264,8 +394,10 @@ type driverState struct {
 	podName            string         // Name of the driver pod.
 	sparkApplicationID string         // Spark application ID.
 	nodeName           string         // Name of the node the driver pod runs on.
+	podIP              string         // IP of the driver pod.
 	podPhase           apiv1.PodPhase // Driver pod phase.
 	completionTime     metav1.Time    // Time the driver completes.
+	failureMessage     string         // Why the driver failed, if it did.
 }
 
 func (c *Controller) updateAppStatus(app *v1beta1.SparkApplication) error {
@@ -286,15 +418,32 @@ func (c *Controller) updateAppStatus(app *v1beta1.SparkApplication) error {
 	var executorApplicationID string
 	for _, pod := range pods {
 		if util.IsDriverPod(pod) {
+			if shouldAdoptDriverPod(app, pod) {
+				c.adoptDriverPod(app, pod)
+			}
 			currentDriverState = &driverState{
 				podName:            pod.Name,
 				nodeName:           pod.Spec.NodeName,
-				podPhase:           pod.Status.Phase,
+				podIP:              pod.Status.PodIP,
+				podPhase:           getDriverPodPhase(pod),
 				sparkApplicationID: getSparkApplicationID(pod),
 			}
-			if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			if currentDriverState.podPhase == apiv1.PodSucceeded || currentDriverState.podPhase == apiv1.PodFailed {
 				currentDriverState.completionTime = metav1.Now()
 			}
+			if currentDriverState.podPhase == apiv1.PodFailed {
+				currentDriverState.failureMessage = getDriverFailureMessage(pod)
+			}
+			if c.enableIstioMode && shouldQuitIstioProxy(pod) {
+				if err := quitIstioProxy(pod); err != nil {
+					glog.Warning(err)
+				}
+			}
+			if shouldStopUIProxy(pod) {
+				if err := stopUIProxy(pod, c.kubeClient); err != nil {
+					glog.Warning(err)
+				}
+			}
 		}
 		if util.IsExecutorPod(pod) {
 			newState := podPhaseToExecutorState(pod.Status.Phase)
@@ -327,13 +476,23 @@ func (c *Controller) updateAppStatus(app *v1beta1.SparkApplication) error {
 			if app.Status.TerminationTime.IsZero() && !currentDriverState.completionTime.IsZero() {
 				app.Status.TerminationTime = currentDriverState.completionTime
 			}
+			if newState == v1beta1.FailingState && app.Status.AppState.ErrorMessage == "" {
+				app.Status.AppState.ErrorMessage = currentDriverState.failureMessage
+			}
 		}
 		app.Status.AppState.State = newState
+	} else if hasExternalDriver(app) {
+		// There is no driver pod to derive the state of the application from, only its executors.
+		switch app.Status.AppState.State {
+		case v1beta1.SubmittedState, v1beta1.RunningState:
+			app.Status.AppState.State = getExternalDriverAppState(executorStateMap)
+		}
 	} else {
 		glog.Warningf("driver not found for SparkApplication: %s/%s", app.Namespace, app.Name)
 		// The application has not terminated and has a recorded driver Pod, but no driver Pod was found for it.
 		// This is likely because the driver Pod was deleted. In this case, set the application state to FailingState.
-		if app.Status.TerminationTime.IsZero() && app.Status.DriverInfo.PodName != "" {
+		// The same applies to an application whose driver Pod was gone before it could be recorded.
+		if app.Status.TerminationTime.IsZero() && (app.Status.DriverInfo.PodName != "" || isDriverLost(app)) {
 			app.Status.AppState.ErrorMessage = "Driver Pod not found"
 			app.Status.AppState.State = v1beta1.FailingState
 			app.Status.TerminationTime = metav1.Now()
@@ -352,6 +511,15 @@ func (c *Controller) updateAppStatus(app *v1beta1.SparkApplication) error {
 	for name, execStatus := range executorStateMap {
 		app.Status.ExecutorState[name] = execStatus
 	}
+	// Executors that are gone take their samples with them, so the last summary is kept until new samples exist.
+	if summary := summarizeResourcePressure(pods); summary != nil {
+		app.Status.ResourcePressure = summary
+	}
+	recordOutOfMemoryPods(app, pods)
+	recordOutOfMemoryRoles(app, pods)
+	recordLaunchLatency(app, metav1.Now())
+
+	c.updateProgress(app, currentDriverState)
 
 	// Handle missing/deleted executors.
 	for name, oldStatus := range app.Status.ExecutorState {
@@ -366,6 +534,12 @@ func (c *Controller) updateAppStatus(app *v1beta1.SparkApplication) error {
 }
 
 func (c *Controller) handleSparkApplicationDeletion(app *v1beta1.SparkApplication) {
+	if c.progress != nil {
+		c.progress.untrack(getApplicationKey(app.Namespace, app.Name))
+	}
+	c.idleExecutors.forget(getApplicationKey(app.Namespace, app.Name))
+	c.runHistory.forget(getApplicationKey(app.Namespace, app.Name))
+
 	// SparkApplication deletion requested, lets delete driver pod.
 	if err := c.deleteSparkResources(app); err != nil {
 		glog.Errorf("failed to delete resources associated wirh deleted SparkApplication: %s/%s: %v", app.Namespace, app.Name, err)
@@ -381,24 +555,44 @@ func shouldRetry(app *v1beta1.SparkApplication) bool {
 		if app.Spec.RestartPolicy.Type == v1beta1.Always {
 			return true
 		} else if app.Spec.RestartPolicy.Type == v1beta1.OnFailure {
-			// We retry if we haven't hit the retry limit.
+			// We retry if we haven't hit the retry limit and the failure is worth retrying.
 			if app.Spec.RestartPolicy.OnFailureRetries != nil && app.Status.ExecutionAttempts <= *app.Spec.RestartPolicy.OnFailureRetries {
-				return true
+				return isRetryableError(app)
 			}
 		}
 	case v1beta1.FailedSubmissionState:
 		if app.Spec.RestartPolicy.Type == v1beta1.Always {
 			return true
 		} else if app.Spec.RestartPolicy.Type == v1beta1.OnFailure {
-			// We retry if we haven't hit the retry limit.
+			// We retry if we haven't hit the retry limit and the failure is worth retrying.
 			if app.Spec.RestartPolicy.OnSubmissionFailureRetries != nil && app.Status.SubmissionAttempts <= *app.Spec.RestartPolicy.OnSubmissionFailureRetries {
-				return true
+				return isRetryableError(app)
 			}
 		}
 	}
 	return false
 }
 
+// isRetryableError tells if the error message of the failed run or submission of the given SparkApplication
+// matches any of the retryable errors of its RestartPolicy. All errors are retryable if none are specified, and
+// runs that ran out of memory are if the RestartPolicy bumps memory.
+func isRetryableError(app *v1beta1.SparkApplication) bool {
+	if len(app.Spec.RestartPolicy.RetryableErrors) == 0 || isOutOfMemoryFailure(app) {
+		return true
+	}
+	for _, pattern := range app.Spec.RestartPolicy.RetryableErrors {
+		matched, err := regexp.MatchString(pattern, app.Status.AppState.ErrorMessage)
+		if err != nil {
+			glog.Errorf("invalid retryable error %q of SparkApplication %s/%s: %v", pattern, app.Namespace, app.Name, err)
+			continue
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
 // State Machine for SparkApplication:
 //+--------------------------------------------------------------------------------------------------------------------+
 //|                                                                                                                    |
@@ -450,21 +644,75 @@ func (c *Controller) syncSparkApplication(key string) error {
 		return nil
 	}
 	if !app.DeletionTimestamp.IsZero() {
+		if hasArchiveFinalizer(app) {
+			return c.archiveDeletedApplication(app)
+		}
 		c.handleSparkApplicationDeletion(app)
 		return nil
 	}
+	if c.archiver != nil && !hasArchiveFinalizer(app) {
+		return c.addArchiveFinalizer(app)
+	}
 
 	appToUpdate := app.DeepCopy()
 	if err := c.updateAppStatus(appToUpdate); err != nil {
 		return err
 	}
 
+	if needsSource(appToUpdate) {
+		return c.syncSource(key, appToUpdate)
+	}
+
+	c.detectPodTemplateDrift(appToUpdate)
+	c.detectConfigChange(appToUpdate)
+
 	// Take action based on application state.
 	switch appToUpdate.Status.AppState.State {
 	case v1beta1.NewState:
 		c.recordSparkApplicationEvent(appToUpdate)
 		appToUpdate.Status.SubmissionAttempts = 0
-		appToUpdate = c.submitSparkApplication(appToUpdate)
+		original, err := c.findOriginalApplication(appToUpdate)
+		if err != nil {
+			return err
+		}
+		if original != nil {
+			skipDuplicateApplication(appToUpdate, original)
+			c.recordSparkApplicationEvent(appToUpdate)
+		} else if c.scheduler != nil {
+			c.queueSparkApplication(appToUpdate)
+		} else {
+			appToUpdate = c.submitSparkApplication(appToUpdate)
+		}
+	case v1beta1.QueuedState:
+		start := true
+		if c.scheduler != nil {
+			if start, err = c.scheduler.ShouldStart(appToUpdate); err != nil {
+				return err
+			}
+			if !start && features.Enabled(features.Preemption) {
+				if start, err = c.preemptFor(appToUpdate); err != nil {
+					return err
+				}
+			}
+		}
+		if start {
+			appToUpdate.Status.TerminationTime = metav1.Time{}
+			appToUpdate = c.submitSparkApplication(appToUpdate)
+		} else {
+			// Check again later, the application is also enqueued on every informer resync.
+			c.queue.AddAfter(key, queuedAppRecheckInterval)
+		}
+	case v1beta1.RunningState:
+		rotationTime, rotates, err := getRotationTime(appToUpdate)
+		if err != nil {
+			glog.Errorf("failed to get the rotation time of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		} else if rotates && features.Enabled(features.ApplicationRotation) {
+			if now := time.Now(); now.Before(rotationTime) {
+				c.queue.AddAfter(key, rotationTime.Sub(now))
+			} else if err := c.rotateSparkApplication(appToUpdate); err != nil {
+				return err
+			}
+		}
 	case v1beta1.SucceedingState:
 		if !shouldRetry(appToUpdate) {
 			// App will never be retried. Move to terminal CompletedState.
@@ -489,6 +737,7 @@ func (c *Controller) syncSparkApplication(key string) error {
 					appToUpdate.Namespace, appToUpdate.Name, err)
 				return err
 			}
+			c.bumpMemoryForRetry(appToUpdate)
 			appToUpdate.Status.AppState.State = v1beta1.PendingRerunState
 			appToUpdate.Status.AppState.ErrorMessage = ""
 		}
@@ -498,7 +747,11 @@ func (c *Controller) syncSparkApplication(key string) error {
 			appToUpdate.Status.AppState.State = v1beta1.FailedState
 			c.recordSparkApplicationEvent(appToUpdate)
 		} else if hasRetryIntervalPassed(appToUpdate.Spec.RestartPolicy.OnSubmissionFailureRetryInterval, appToUpdate.Status.SubmissionAttempts, appToUpdate.Status.LastSubmissionAttemptTime) {
-			appToUpdate = c.submitSparkApplication(appToUpdate)
+			if c.scheduler != nil {
+				c.queueSparkApplication(appToUpdate)
+			} else {
+				appToUpdate = c.submitSparkApplication(appToUpdate)
+			}
 		}
 	case v1beta1.InvalidatingState:
 		// Invalidate the current run and enqueue the SparkApplication for re-execution.
@@ -513,12 +766,80 @@ func (c *Controller) syncSparkApplication(key string) error {
 		if c.validateSparkResourceDeletion(appToUpdate) {
 			// Reset SubmissionAttempts count since this is a new overall run.
 			appToUpdate.Status.SubmissionAttempts = 0
-			appToUpdate.Status.TerminationTime = metav1.Time{}
-			appToUpdate = c.submitSparkApplication(appToUpdate)
+			if c.scheduler != nil {
+				c.queueSparkApplication(appToUpdate)
+			} else {
+				appToUpdate.Status.TerminationTime = metav1.Time{}
+				appToUpdate = c.submitSparkApplication(appToUpdate)
+			}
 		}
 	}
 
+	if appToUpdate != nil && isAppTerminated(appToUpdate.Status.AppState.State) &&
+		appToUpdate.Status.AppState.State != app.Status.AppState.State {
+		// The dedicated ServiceAccount of the driver is not needed anymore once the application has terminated.
+		if err := c.deleteDriverServiceAccount(appToUpdate); err != nil {
+			glog.Errorf("failed to delete the driver ServiceAccount of SparkApplication %s/%s: %v",
+				appToUpdate.Namespace, appToUpdate.Name, err)
+		}
+		if appToUpdate.Status.AppState.State == v1beta1.FailedState && appToUpdate.Spec.OutputCleanup != nil &&
+			features.Enabled(features.OutputCleanup) {
+			if err := c.cleanUpOutput(appToUpdate); err != nil {
+				glog.Errorf("failed to clean up the output of SparkApplication %s/%s: %v",
+					appToUpdate.Namespace, appToUpdate.Name, err)
+			}
+		}
+		if appToUpdate.Status.AppState.State == v1beta1.FailedState && appToUpdate.Spec.DriverLogCapture != nil &&
+			features.Enabled(features.DriverLogCapture) {
+			if err := c.captureDriverLog(appToUpdate); err != nil {
+				glog.Errorf("failed to capture the driver log of SparkApplication %s/%s: %v",
+					appToUpdate.Namespace, appToUpdate.Name, err)
+			}
+		}
+		if c.catalog != nil {
+			if err := c.catalog.Push(appToUpdate); err != nil {
+				glog.Warning(err)
+			}
+		}
+		if c.notifier != nil {
+			if event, ok := getTerminationNotificationEvent(appToUpdate); ok {
+				if err := c.notifier.Notify(appToUpdate, event); err != nil {
+					glog.Error(err)
+				}
+			}
+		}
+	}
+
+	if appToUpdate != nil && c.notifier != nil {
+		c.checkSLA(key, appToUpdate)
+	}
+
+	if appToUpdate != nil {
+		c.openStartGate(appToUpdate)
+	}
+
+	if appToUpdate != nil && features.Enabled(features.ExecutorIdleTimeout) {
+		c.reclaimIdleExecutors(key, appToUpdate)
+	}
+
+	if appToUpdate != nil {
+		c.restartExecutors(key, appToUpdate)
+	}
+
+	if appToUpdate != nil && features.Enabled(features.DumpUpload) {
+		c.uploadDumps(appToUpdate)
+	}
+
+	if appToUpdate != nil {
+		c.emitLineageEvent(app, appToUpdate)
+	}
+
+	if appToUpdate != nil && features.Enabled(features.RunHistory) {
+		c.recordRunHistory(key, app, appToUpdate)
+	}
+
 	if appToUpdate != nil {
+		appToUpdate.Status.ObservedGeneration = app.Generation
 		glog.V(2).Infof("Trying to update SparkApplication %s/%s, from: [%v] to [%v]", app.Namespace, app.Name, app.Status, appToUpdate.Status)
 		err = c.updateStatusAndExportMetrics(app, appToUpdate)
 		if err != nil {
@@ -530,6 +851,34 @@ func (c *Controller) syncSparkApplication(key string) error {
 	return nil
 }
 
+// emitLineageEvent emits the OpenLineage run event of the current run of the given application, if any, when
+// the run has started or terminated.
+func (c *Controller) emitLineageEvent(oldApp, app *v1beta1.SparkApplication) {
+	runID := app.Status.LineageRunID
+	if c.lineage == nil || runID == "" || app.Status.AppState.State == oldApp.Status.AppState.State {
+		return
+	}
+	var eventType string
+	switch app.Status.AppState.State {
+	case v1beta1.RunningState:
+		eventType = lineage.StartEvent
+	case v1beta1.SucceedingState, v1beta1.CompletedState:
+		eventType = lineage.CompleteEvent
+	case v1beta1.FailingState, v1beta1.FailedState:
+		eventType = lineage.FailEvent
+	default:
+		return
+	}
+	// A run goes through SUCCEEDING or FAILING before COMPLETED or FAILED, which is reported once.
+	if isAppTerminated(app.Status.AppState.State) &&
+		(oldApp.Status.AppState.State == v1beta1.SucceedingState || oldApp.Status.AppState.State == v1beta1.FailingState) {
+		return
+	}
+	if err := c.lineage.Emit(app, eventType, runID); err != nil {
+		glog.Warning(err)
+	}
+}
+
 // Helper func to determine if we have waited enough to retry the SparkApplication.
 func hasRetryIntervalPassed(retryInterval *int64, attemptsDone int32, lastEventTime metav1.Time) bool {
 	glog.V(3).Infof("retryInterval: %d , lastEventTime: %v, attempsDone: %d", retryInterval, lastEventTime, attemptsDone)
@@ -547,17 +896,138 @@ func hasRetryIntervalPassed(retryInterval *int64, attemptsDone int32, lastEventT
 	return false
 }
 
-// submitSparkApplication creates a new submission for the given SparkApplication and submits it using spark-submit.
+// queueSparkApplication puts the given SparkApplication into QueuedState so it gets started once the
+// scheduler admits it.
+func (c *Controller) queueSparkApplication(app *v1beta1.SparkApplication) {
+	app.Status.AppState.State = v1beta1.QueuedState
+	app.Status.AppState.ErrorMessage = ""
+	app.Status.QueuedTime = metav1.Now()
+	c.recordSparkApplicationEvent(app)
+}
+
+// SetDefaultSparkConf sets the Spark configuration properties added to applications that do not set them
+// when they are submitted from then on.
+func (c *Controller) SetDefaultSparkConf(conf map[string]string) {
+	c.defaultsMutex.Lock()
+	defer c.defaultsMutex.Unlock()
+	c.defaultSparkConf = conf
+}
+
+func (c *Controller) applyDefaultSparkConf(app *v1beta1.SparkApplication) {
+	c.defaultsMutex.RLock()
+	defer c.defaultsMutex.RUnlock()
+	if len(c.defaultSparkConf) == 0 {
+		return
+	}
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	for key, value := range c.defaultSparkConf {
+		if _, ok := app.Spec.SparkConf[key]; !ok {
+			app.Spec.SparkConf[key] = value
+		}
+	}
+}
+
+// submitSparkApplication creates a new submission for the given SparkApplication and submits it with its submitter.
 func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1beta1.SparkApplication {
-	// Make a copy since configPrometheusMonitoring may update app.Spec which causes an onUpdate callback.
+	// Make a copy since configMonitoring may update app.Spec which causes an onUpdate callback.
 	appToSubmit := app.DeepCopy()
-	if appToSubmit.Spec.Monitoring != nil && appToSubmit.Spec.Monitoring.Prometheus != nil {
-		if err := configPrometheusMonitoring(appToSubmit, c.kubeClient); err != nil {
+	distribution := selectSparkDistribution(appToSubmit, c.distributions)
+	if distribution != nil {
+		applySparkDistribution(appToSubmit, distribution)
+	}
+	c.applyDefaultSparkConf(appToSubmit)
+	applyMemoryBump(appToSubmit)
+	if appToSubmit.Spec.Monitoring != nil {
+		if err := configMonitoring(appToSubmit, c.getPropagatedMetadata(app), c.kubeClient); err != nil {
 			glog.Error(err)
 		}
 	}
-
-	submissionCmdArgs, err := buildSubmissionCommandArgs(appToSubmit)
+	submittedBy := app.Annotations[config.SubmittedByAnnotation]
+	var err error
+	if hasExternalDriver(appToSubmit) {
+		if features.Enabled(features.ExternalDrivers) {
+			return c.submitExternalDriverApplication(app, appToSubmit)
+		}
+		err = fmt.Errorf("external drivers are disabled by the %s feature gate", features.ExternalDrivers)
+	}
+	if err == nil {
+		err = c.applyProxyUser(appToSubmit)
+	}
+	if err == nil && appToSubmit.Spec.EncryptLocalData != nil && *appToSubmit.Spec.EncryptLocalData {
+		err = c.setUpLocalDataEncryption(appToSubmit)
+	}
+	if err == nil && createsDriverServiceAccount(appToSubmit) {
+		err = c.setUpDriverServiceAccount(appToSubmit)
+	}
+	if err == nil && appToSubmit.Spec.OutputCleanup != nil && features.Enabled(features.OutputCleanup) {
+		err = validateOutputPaths(appToSubmit)
+	}
+	if err == nil && appToSubmit.Spec.MinExecutorsBeforeStart != nil {
+		// The gate of a previous run may have been left open.
+		err = c.setStartGate(appToSubmit, false)
+	}
+	if err == nil && util.GetGPUSpec(appToSubmit) != nil {
+		err = c.setUpGPUDiscoveryScript(appToSubmit)
+	}
+	if err == nil && appToSubmit.Spec.DataCache != nil {
+		if features.Enabled(features.DataCache) {
+			err = c.setUpDataCache(appToSubmit)
+		} else {
+			err = fmt.Errorf("dataCache is disabled by the %s feature gate", features.DataCache)
+		}
+	}
+	if err == nil && appToSubmit.Spec.ConnectionPooler != nil {
+		err = configConnectionPooler(appToSubmit)
+	}
+	if err == nil && appToSubmit.Spec.HadoopClusterRef != nil {
+		if features.Enabled(features.HadoopClusters) {
+			err = c.setUpHadoopConfig(appToSubmit)
+		} else {
+			err = fmt.Errorf("hadoopClusterRef is disabled by the %s feature gate", features.HadoopClusters)
+		}
+	}
+	var autoTuning *v1beta1.AutoTuningStatus
+	if err == nil && appToSubmit.Spec.AutoTuning != nil {
+		if features.Enabled(features.AutoTuning) {
+			autoTuning, err = c.autoTune(appToSubmit)
+		} else {
+			err = fmt.Errorf("autoTuning is disabled by the %s feature gate", features.AutoTuning)
+		}
+	}
+	var dumpPath string
+	if err == nil && appToSubmit.Spec.Debug != nil {
+		dumpPath = getDumpPath(appToSubmit, time.Now())
+		err = configDumps(appToSubmit, dumpPath)
+	}
+	var lineageRunID string
+	if err == nil && c.lineage != nil {
+		if lineageRunID, err = lineage.NewRunID(); err == nil {
+			c.lineage.ConfigureListener(appToSubmit, lineageRunID)
+		}
+	}
+	if err == nil {
+		err = exposeConfSecrets(appToSubmit)
+	}
+	if err == nil && fips.Enabled() {
+		err = fips.ValidateSparkConf(appToSubmit.Spec.SparkConf)
+	}
+	podTemplateHash := getPodTemplateHash(app)
+	configHashes := c.getConfigHashes(app)
+	var submissionCmdArgs []string
+	if err == nil {
+		addPodTemplateHashAnnotations(appToSubmit, podTemplateHash)
+		submissionCmdArgs, err = buildSubmissionCommandArgs(appToSubmit)
+	}
+	var submissionEnv []string
+	if err == nil && c.impersonateUser {
+		submissionEnv, err = buildImpersonationEnv(appToSubmit)
+	}
+	var submitter Submitter
+	if err == nil {
+		submitter, err = c.getSubmitter(appToSubmit)
+	}
 	if err != nil {
 		app.Status = v1beta1.SparkApplicationStatus{
 			AppState: v1beta1.ApplicationState{
@@ -566,12 +1036,24 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 			},
 			SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
 			LastSubmissionAttemptTime: metav1.Now(),
+			SubmittedBy:               submittedBy,
+			LastSpecUpdate:            app.Status.LastSpecUpdate,
+			MemoryBump:                app.Status.MemoryBump,
+			SourceRevision:            app.Status.SourceRevision,
 		}
 		return app
 	}
 
-	// Try submitting the application by running spark-submit.
-	submitted, err := runSparkSubmit(newSubmission(submissionCmdArgs, appToSubmit))
+	// Try submitting the application with its submitter.
+	submission := newSubmission(submissionCmdArgs, appToSubmit)
+	submission.Env = submissionEnv
+	if distribution != nil {
+		submission.SparkHome = distribution.SparkHome
+	}
+	submitted, err := submitter.Submit(submission)
+	if submission.Output != nil {
+		submission.Output.Attempt = app.Status.SubmissionAttempts + 1
+	}
 	if err != nil {
 		app.Status = v1beta1.SparkApplicationStatus{
 			AppState: v1beta1.ApplicationState{
@@ -580,15 +1062,20 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 			},
 			SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
 			LastSubmissionAttemptTime: metav1.Now(),
+			SubmittedBy:               submittedBy,
+			LastSpecUpdate:            app.Status.LastSpecUpdate,
+			MemoryBump:                app.Status.MemoryBump,
+			SourceRevision:            app.Status.SourceRevision,
+			SubmissionOutput:          submission.Output,
 		}
 		c.recordSparkApplicationEvent(app)
-		glog.Errorf("failed to run spark-submit for SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		glog.Errorf("failed to submit SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
 		return app
 	}
 	if !submitted {
 		// The application may not have been submitted even if err == nil, e.g., when some
 		// state update caused an attempt to re-submit the application, in which case no
-		// error gets returned from runSparkSubmit. If this is the case, we simply return.
+		// error gets returned from the submitter. If this is the case, we simply return.
 		return app
 	}
 
@@ -600,10 +1087,30 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 		SubmissionAttempts:        app.Status.SubmissionAttempts + 1,
 		ExecutionAttempts:         app.Status.ExecutionAttempts + 1,
 		LastSubmissionAttemptTime: metav1.Now(),
+		SubmittedBy:               submittedBy,
+		LineageRunID:              lineageRunID,
+		PodTemplateHash:           podTemplateHash,
+		ConfigHashes:              configHashes,
+		LastSpecUpdate:            app.Status.LastSpecUpdate,
+		MemoryBump:                app.Status.MemoryBump,
+		SourceRevision:            app.Status.SourceRevision,
+		SubmissionOutput:          submission.Output,
+	}
+	if app.Status.MemoryBump != nil {
+		// Pods that ran out of memory in a run that was not retried for it must not bump the memory later.
+		app.Status.MemoryBump.OutOfMemory = nil
+	}
+	if dumpPath != "" {
+		app.Status.Dumps = &v1beta1.DumpStatus{Path: dumpPath}
+	}
+	app.Status.AutoTuning = autoTuning
+	if createsDriverServiceAccount(appToSubmit) {
+		app.Status.DriverInfo.ServiceAccountName = *appToSubmit.Spec.Driver.ServiceAccount
 	}
 	c.recordSparkApplicationEvent(app)
 
-	service, err := createSparkUIService(app, c.kubeClient)
+	metadata := c.getPropagatedMetadata(app)
+	service, err := createSparkUIService(app, metadata, c.kubeClient)
 	if err != nil {
 		glog.Errorf("failed to create UI service for SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
 	} else {
@@ -611,7 +1118,7 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 		app.Status.DriverInfo.WebUIPort = service.nodePort
 		// Create UI Ingress if ingress-format is set.
 		if c.ingressURLFormat != "" {
-			ingress, err := createSparkUIIngress(app, *service, c.ingressURLFormat, c.kubeClient)
+			ingress, err := createSparkUIIngress(app, *service, c.ingressURLFormat, metadata, c.kubeClient)
 			if err != nil {
 				glog.Errorf("failed to create UI Ingress for SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
 			} else {
@@ -620,6 +1127,12 @@ func (c *Controller) submitSparkApplication(app *v1beta1.SparkApplication) *v1be
 			}
 		}
 	}
+	if app.Spec.Driver.UIProxy != nil {
+		if err := createUIProxyNetworkPolicy(app, metadata, c.kubeClient); err != nil {
+			glog.Errorf("failed to restrict access to the UI of SparkApplication %s/%s: %v", app.Namespace,
+				app.Name, err)
+		}
+	}
 	return app
 }
 
@@ -632,11 +1145,11 @@ func (c *Controller) updateApplicationStatusWithRetries(
 	for i := 0; i < maximumUpdateRetries; i++ {
 		updateFunc(&toUpdate.Status)
 		if reflect.DeepEqual(original.Status, toUpdate.Status) {
-			return toUpdate, nil
+			return c.syncStateLabel(toUpdate), nil
 		}
-		_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(toUpdate.Namespace).Update(toUpdate)
+		_, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(toUpdate.Namespace).UpdateStatus(toUpdate)
 		if err == nil {
-			return toUpdate, nil
+			return c.syncStateLabel(toUpdate), nil
 		}
 
 		lastUpdateErr = err
@@ -693,9 +1206,7 @@ func (c *Controller) getSparkApplication(namespace string, name string) (*v1beta
 func (c *Controller) deleteSparkResources(app *v1beta1.SparkApplication) error {
 	driverPodName := app.Status.DriverInfo.PodName
 	if driverPodName != "" {
-		glog.V(2).Infof("Deleting pod with name %s in namespace %s", driverPodName, app.Namespace)
-		err := c.kubeClient.CoreV1().Pods(app.Namespace).Delete(driverPodName, metav1.NewDeleteOptions(0))
-		if err != nil && !errors.IsNotFound(err) {
+		if err := c.terminateDriverPod(app, driverPodName); err != nil {
 			return err
 		}
 	}
@@ -718,6 +1229,14 @@ func (c *Controller) deleteSparkResources(app *v1beta1.SparkApplication) error {
 		}
 	}
 
+	if err := c.deleteDriverServiceAccount(app); err != nil {
+		return err
+	}
+
+	if hasExternalDriver(app) {
+		return c.deleteExternalDriverExecutors(app)
+	}
+
 	return nil
 }
 
@@ -784,6 +1303,13 @@ func (c *Controller) recordSparkApplicationEvent(app *v1beta1.SparkApplication)
 			"SparkApplicationAdded",
 			"SparkApplication %s was added, Enqueuing it for submission",
 			app.Name)
+	case v1beta1.QueuedState:
+		c.recorder.Eventf(
+			app,
+			apiv1.EventTypeNormal,
+			"SparkApplicationQueued",
+			"SparkApplication %s was queued for submission",
+			app.Name)
 	case v1beta1.SubmittedState:
 		c.recorder.Eventf(
 			app,
@@ -815,6 +1341,14 @@ func (c *Controller) recordSparkApplicationEvent(app *v1beta1.SparkApplication)
 			"SparkApplication %s terminated with state: %v",
 			app.Name,
 			app.Status.AppState.State)
+	case v1beta1.SkippedState:
+		c.recorder.Eventf(
+			app,
+			apiv1.EventTypeNormal,
+			"SparkApplicationSkipped",
+			"SparkApplication %s was skipped as SparkApplication %s has the same idempotency key",
+			app.Name,
+			app.Status.AttachedTo)
 	}
 }
 
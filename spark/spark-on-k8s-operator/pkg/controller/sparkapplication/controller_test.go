@@ -17,7 +17,10 @@ limitations under the License.
 package sparkapplication
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"strings"
@@ -39,6 +42,7 @@ import (
 	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
 	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/lineage"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
@@ -65,7 +69,7 @@ func newFakeController(app *v1beta1.SparkApplication, pods ...*apiv1.Pod) (*Cont
 
 	podInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0*time.Second)
 	controller := newSparkApplicationController(crdClient, kubeClient, informerFactory, podInformerFactory, recorder,
-		&util.MetricConfig{}, "")
+		&util.MetricConfig{}, "", false, false, nil, nil, 0, nil, nil, nil)
 
 	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
 	if app != nil {
@@ -127,6 +131,14 @@ func TestOnUpdate(t *testing.T) {
 
 	ctrl.onUpdate(appTemplate, copyWithSameSpec)
 
+	// Verify that the SparkApplication was not enqueued as only its status changed.
+	assert.Equal(t, 0, ctrl.queue.Len())
+	assert.Equal(t, 0, len(recorder.Events))
+
+	// A change of the state is enqueued.
+	copyWithSameSpec.Status.AppState.State = v1beta1.InvalidatingState
+	ctrl.onUpdate(appTemplate, copyWithSameSpec)
+
 	// Verify that the SparkApplication was enqueued but no spec update events fired.
 	item, _ := ctrl.queue.Get()
 	key, ok := item.(string)
@@ -141,6 +153,7 @@ func TestOnUpdate(t *testing.T) {
 	copyWithSpecUpdate := appTemplate.DeepCopy()
 	copyWithSpecUpdate.Spec.Image = stringptr("foo-image:v2")
 	copyWithSpecUpdate.ResourceVersion = "2"
+	copyWithSpecUpdate.Generation = 2
 
 	ctrl.onUpdate(appTemplate, copyWithSpecUpdate)
 
@@ -170,6 +183,79 @@ func TestOnUpdate(t *testing.T) {
 	app, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(appTemplate.Namespace).Get(appTemplate.Name, metav1.GetOptions{})
 	assert.Nil(t, err)
 	assert.Equal(t, v1beta1.InvalidatingState, app.Status.AppState.State)
+	assert.Equal(t, int64(2), app.Status.ObservedGeneration)
+	assert.True(t, app.Status.LastSpecUpdate.Restarted)
+	assert.Equal(t, []v1beta1.FieldChange{
+		{Path: "spec.image", OldValue: `"foo-image:v1"`, NewValue: `"foo-image:v2"`},
+	}, app.Status.LastSpecUpdate.RestartRequiringFields)
+
+	// Case4: Spec update not requiring new pods.
+	runningApp := appTemplate.DeepCopy()
+	runningApp.Status.AppState.State = v1beta1.RunningState
+	runningApp.Status.PodTemplateHash = getPodTemplateHash(appTemplate)
+	runningApp.ResourceVersion = "3"
+	ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(appTemplate.Namespace).Update(runningApp)
+	copyWithRestartPolicyUpdate := runningApp.DeepCopy()
+	copyWithRestartPolicyUpdate.Spec.RestartPolicy = v1beta1.RestartPolicy{Type: v1beta1.OnFailure}
+	ctrl.onUpdate(runningApp, copyWithRestartPolicyUpdate)
+
+	item, _ = ctrl.queue.Get()
+	ctrl.queue.Forget(item)
+	ctrl.queue.Done(item)
+	assert.Equal(t, 1, len(recorder.Events))
+	event = <-recorder.Events
+	assert.True(t, strings.Contains(event, "without a restart, changed spec.restartPolicy."))
+	app, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(appTemplate.Namespace).Get(appTemplate.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.RunningState, app.Status.AppState.State)
+	assert.False(t, app.Status.LastSpecUpdate.Restarted)
+	assert.Nil(t, app.Status.LastSpecUpdate.RestartRequiringFields)
+	assert.Equal(t, []v1beta1.FieldChange{
+		{Path: "spec.restartPolicy.onFailureRetryInterval", NewValue: "5"},
+		{Path: "spec.restartPolicy.onSubmissionFailureRetryInterval", NewValue: "5"},
+		{Path: "spec.restartPolicy.type", OldValue: `"Never"`, NewValue: `"OnFailure"`},
+	}, app.Status.LastSpecUpdate.HotSwappableFields)
+}
+
+func TestIsStatusOnlyUpdate(t *testing.T) {
+	oldApp := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", ResourceVersion: "1", Generation: 1},
+		Spec:       v1beta1.SparkApplicationSpec{Image: stringptr("foo-image:v1")},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+		},
+	}
+
+	// A periodic resync.
+	assert.False(t, isStatusOnlyUpdate(oldApp, oldApp.DeepCopy()))
+
+	newApp := oldApp.DeepCopy()
+	newApp.ResourceVersion = "2"
+	newApp.Status.ExecutorState = map[string]v1beta1.ExecutorState{"exec-1": v1beta1.ExecutorRunningState}
+	assert.True(t, isStatusOnlyUpdate(oldApp, newApp))
+
+	stateChanged := newApp.DeepCopy()
+	stateChanged.Status.AppState.State = v1beta1.CompletedState
+	assert.False(t, isStatusOnlyUpdate(oldApp, stateChanged))
+
+	specChanged := newApp.DeepCopy()
+	specChanged.Generation = 2
+	specChanged.Spec.Image = stringptr("foo-image:v2")
+	assert.False(t, isStatusOnlyUpdate(oldApp, specChanged))
+
+	annotated := newApp.DeepCopy()
+	annotated.Annotations = map[string]string{"foo": "bar"}
+	assert.False(t, isStatusOnlyUpdate(oldApp, annotated))
+
+	deleted := newApp.DeepCopy()
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+	assert.False(t, isStatusOnlyUpdate(oldApp, deleted))
+
+	// The state label only follows the state.
+	labeled := newApp.DeepCopy()
+	labeled.Labels = map[string]string{config.SparkAppStateLabel: string(v1beta1.RunningState)}
+	assert.True(t, isStatusOnlyUpdate(oldApp, labeled))
 }
 
 func TestOnDelete(t *testing.T) {
@@ -277,6 +363,8 @@ func TestSyncSparkApplication_SubmissionFailed(t *testing.T) {
 
 	assert.Equal(t, v1beta1.FailedSubmissionState, updatedApp.Status.AppState.State)
 	assert.Equal(t, int32(1), updatedApp.Status.SubmissionAttempts)
+	assert.Equal(t, int32(1), updatedApp.Status.SubmissionOutput.Attempt)
+	assert.Equal(t, int32(2), updatedApp.Status.SubmissionOutput.ExitCode)
 	assert.Equal(t, float64(0), fetchCounterValue(ctrl.metrics.sparkAppSubmitCount, map[string]string{}))
 	assert.Equal(t, float64(1), fetchCounterValue(ctrl.metrics.sparkAppFailureCount, map[string]string{}))
 
@@ -1079,6 +1167,99 @@ func TestHasRetryIntervalPassed(t *testing.T) {
 	assert.True(t, hasRetryIntervalPassed(int64ptr(50), 3, metav1.Time{Time: metav1.Now().Add(-151 * time.Second)}))
 }
 
+func TestGetDriverPodPhase(t *testing.T) {
+	pod := &apiv1.Pod{
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{
+					Name:  sparkDriverContainerName,
+					State: apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}},
+				},
+				{
+					Name:  config.UIProxyContainerName,
+					State: apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, apiv1.PodRunning, getDriverPodPhase(pod))
+
+	pod.Status.ContainerStatuses[0].State = apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 0}}
+	assert.Equal(t, apiv1.PodSucceeded, getDriverPodPhase(pod))
+
+	pod.Status.ContainerStatuses[0].State = apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 1}}
+	assert.Equal(t, apiv1.PodFailed, getDriverPodPhase(pod))
+
+	// Pods failed by stopping the UI proxy succeed if the Spark container did.
+	pod.Annotations = map[string]string{config.UIProxyStoppedAnnotation: "true"}
+	pod.Status.Phase = apiv1.PodFailed
+	pod.Status.Reason = "DeadlineExceeded"
+	pod.Status.ContainerStatuses[0].State = apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 0}}
+	assert.Equal(t, apiv1.PodSucceeded, getDriverPodPhase(pod))
+}
+
+func TestShouldRetry_RetryableErrors(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type:                       v1beta1.OnFailure,
+				OnFailureRetries:           int32ptr(3),
+				OnSubmissionFailureRetries: int32ptr(3),
+				RetryableErrors:            []string{"reason (Evicted|NodeLost)", "Too Many Requests", "("},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State:        v1beta1.FailingState,
+				ErrorMessage: "driver pod failed with reason Evicted: The node was low on resource: memory.",
+			},
+			ExecutionAttempts: 1,
+		},
+	}
+	assert.True(t, shouldRetry(app))
+
+	app.Status.AppState.ErrorMessage = "driver container terminated with exit code 1 and reason Error: "
+	assert.False(t, shouldRetry(app))
+
+	app.Status.AppState.State = v1beta1.FailedSubmissionState
+	app.Status.AppState.ErrorMessage = "failed to run spark-submit for SparkApplication default/foo: 429 Too Many Requests"
+	app.Status.SubmissionAttempts = 1
+	assert.True(t, shouldRetry(app))
+
+	// The retry limit still applies to retryable errors.
+	app.Status.SubmissionAttempts = 4
+	assert.False(t, shouldRetry(app))
+}
+
+func TestGetDriverFailureMessage(t *testing.T) {
+	pod := &apiv1.Pod{
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodFailed,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{
+					Name: sparkDriverContainerName,
+					State: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{
+						ExitCode: 137,
+						Reason:   "OOMKilled",
+					}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, "driver container terminated with exit code 137 and reason OOMKilled: ", getDriverFailureMessage(pod))
+
+	pod.Status.Reason = "Evicted"
+	pod.Status.Message = "The node was low on resource: memory."
+	assert.Equal(t, "driver pod failed with reason Evicted: The node was low on resource: memory.", getDriverFailureMessage(pod))
+
+	// Pods failed by stopping the UI proxy fail for their Spark container.
+	pod.Annotations = map[string]string{config.UIProxyStoppedAnnotation: "true"}
+	pod.Status.Reason = "DeadlineExceeded"
+	assert.Equal(t, "driver container terminated with exit code 137 and reason OOMKilled: ", getDriverFailureMessage(pod))
+}
+
 func stringptr(s string) *string {
 	return &s
 }
@@ -1090,3 +1271,32 @@ func int32ptr(n int32) *int32 {
 func int64ptr(n int64) *int64 {
 	return &n
 }
+
+func TestEmitLineageEvent(t *testing.T) {
+	var eventTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event lineage.RunEvent
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&event))
+		assert.Equal(t, "run-1", event.Run.RunID)
+		eventTypes = append(eventTypes, event.EventType)
+	}))
+	defer server.Close()
+
+	ctrl, _ := newFakeController(nil)
+	ctrl.lineage = lineage.NewClient(server.URL, "", "")
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:     v1beta1.ApplicationState{State: v1beta1.SubmittedState},
+			LineageRunID: "run-1",
+		},
+	}
+	for _, state := range []v1beta1.ApplicationStateType{v1beta1.RunningState, v1beta1.RunningState,
+		v1beta1.FailingState, v1beta1.FailedState} {
+		updatedApp := app.DeepCopy()
+		updatedApp.Status.AppState.State = state
+		ctrl.emitLineageEvent(app, updatedApp)
+		app = updatedApp
+	}
+	assert.Equal(t, []string{lineage.StartEvent, lineage.FailEvent}, eventTypes)
+}
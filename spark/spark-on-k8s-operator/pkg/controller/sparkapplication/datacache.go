@@ -0,0 +1,230 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	dataCacheDaemonContainerName = "cache-daemon"
+	dataCacheWarmupContainerName = "cache-warmup"
+	dataCacheWarmupBackoffLimit  = 2
+	// dataCacheDaemonDir is the path the directory of the cache on the node is mounted at in the cache daemons.
+	dataCacheDaemonDir = "/var/cache/spark-data"
+)
+
+// SetDataCacheImage sets the image of the cache daemons and warmup Jobs of applications whose dataCache does
+// not set one, and the other images applications may set. As the cache daemons run privileged, applications
+// may not run images the operator does not allow.
+func (c *Controller) SetDataCacheImage(image string, allowed []string) {
+	c.dataCacheImage = image
+	c.dataCacheImages = allowed
+}
+
+// getDataCacheImage returns the image of the cache daemons and warmup Job of the given application.
+func (c *Controller) getDataCacheImage(app *v1beta1.SparkApplication) (string, error) {
+	if app.Spec.DataCache.Image != nil {
+		image := *app.Spec.DataCache.Image
+		if !c.isDataCacheImageAllowed(image) {
+			return "", fmt.Errorf("dataCache image %s is neither the -data-cache-image of the operator nor one "+
+				"of its -allowed-data-cache-images", image)
+		}
+		return image, nil
+	}
+	if c.dataCacheImage == "" {
+		return "", fmt.Errorf("dataCache requires an image as the operator sets no -data-cache-image")
+	}
+	return c.dataCacheImage, nil
+}
+
+func (c *Controller) isDataCacheImageAllowed(image string) bool {
+	if image != "" && image == c.dataCacheImage {
+		return true
+	}
+	for _, allowed := range c.dataCacheImages {
+		if allowed == image {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDataCache checks the data cache of the given application.
+func validateDataCache(app *v1beta1.SparkApplication) error {
+	cache := app.Spec.DataCache
+	if errs := validation.IsDNS1123Label(cache.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid dataCache name %q: %s", cache.Name, strings.Join(errs, "; "))
+	}
+	if cache.SizeLimit != nil {
+		if _, err := resource.ParseQuantity(*cache.SizeLimit); err != nil {
+			return fmt.Errorf("invalid dataCache sizeLimit %q: %v", *cache.SizeLimit, err)
+		}
+	}
+	return nil
+}
+
+// buildDataCacheDaemonSet returns the DaemonSet of the cache daemons of the data cache of the given application,
+// which keep the cached data in a directory of the node shared with the executors. The daemons mount it with
+// bidirectional propagation, so that the executors see file systems the daemons mount in it, which requires them
+// to be privileged.
+func buildDataCacheDaemonSet(app *v1beta1.SparkApplication, image string) *appsv1.DaemonSet {
+	cache := app.Spec.DataCache
+	labels := map[string]string{config.DataCacheNameLabel: cache.Name}
+	privileged := true
+	propagation := apiv1.MountPropagationBidirectional
+	hostPathType := apiv1.HostPathDirectoryOrCreate
+	container := apiv1.Container{
+		Name:  dataCacheDaemonContainerName,
+		Image: image,
+		Args:  []string{"daemon"},
+		Env: []apiv1.EnvVar{
+			{Name: config.DataCacheNameEnvVar, Value: cache.Name},
+			{Name: config.DataCacheDirEnvVar, Value: dataCacheDaemonDir},
+		},
+		VolumeMounts: []apiv1.VolumeMount{{
+			Name:             config.DataCacheVolumeName,
+			MountPath:        dataCacheDaemonDir,
+			MountPropagation: &propagation,
+		}},
+		SecurityContext: &apiv1.SecurityContext{Privileged: &privileged},
+	}
+	if cache.SizeLimit != nil {
+		container.Env = append(container.Env, apiv1.EnvVar{Name: config.DataCacheSizeEnvVar, Value: *cache.SizeLimit})
+	}
+	podSpec := apiv1.PodSpec{
+		Containers: []apiv1.Container{container},
+		Volumes: []apiv1.Volume{{
+			Name: config.DataCacheVolumeName,
+			VolumeSource: apiv1.VolumeSource{HostPath: &apiv1.HostPathVolumeSource{
+				Path: util.GetDataCacheHostPath(app),
+				Type: &hostPathType,
+			}},
+		}},
+		NodeSelector: app.Spec.NodeSelector,
+	}
+	for _, secret := range app.Spec.ImagePullSecrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, apiv1.LocalObjectReference{Name: secret})
+	}
+
+	// The DaemonSet is shared by the applications using the cache, and garbage collected once all of them are gone.
+	ownerReference := *getOwnerReference(app)
+	ownerReference.Controller = nil
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            util.GetDataCacheDaemonSetName(cache.Name),
+			Namespace:       app.Namespace,
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{ownerReference},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+func getDataCacheWarmupJobName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "cache-warmup", util.DNS1123LabelMaxLength)
+}
+
+// buildDataCacheWarmupJob returns the Job loading the warmup paths of the given application into its data cache,
+// with the credentials of the driver to read them.
+func buildDataCacheWarmupJob(app *v1beta1.SparkApplication, image string) *batchv1.Job {
+	container := apiv1.Container{
+		Name: dataCacheWarmupContainerName,
+		Args: append([]string{"warmup"}, app.Spec.DataCache.WarmupPaths...),
+		Env:  []apiv1.EnvVar{{Name: config.DataCacheNameEnvVar, Value: app.Spec.DataCache.Name}},
+	}
+	return buildDriverJob(app, getDataCacheWarmupJobName(app), &image, container, dataCacheWarmupBackoffLimit)
+}
+
+// setUpDataCache creates the DaemonSet of the cache daemons of the data cache of the given application unless it
+// exists, in which case the application is added to its owners, and starts the Job warming up the cache.
+func (c *Controller) setUpDataCache(app *v1beta1.SparkApplication) error {
+	if err := validateDataCache(app); err != nil {
+		return err
+	}
+	image, err := c.getDataCacheImage(app)
+	if err != nil {
+		return err
+	}
+	daemonSet := buildDataCacheDaemonSet(app, image)
+	existing, err := c.kubeClient.AppsV1().DaemonSets(app.Namespace).Get(daemonSet.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.AppsV1().DaemonSets(app.Namespace).Create(daemonSet)
+		if err == nil {
+			glog.Infof("Created the DaemonSet %s/%s of data cache %s", app.Namespace, daemonSet.Name,
+				app.Spec.DataCache.Name)
+		}
+	} else if err == nil && !hasOwner(existing.OwnerReferences, app) {
+		existing.OwnerReferences = append(existing.OwnerReferences, daemonSet.OwnerReferences...)
+		_, err = c.kubeClient.AppsV1().DaemonSets(app.Namespace).Update(existing)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up the DaemonSet %s/%s of data cache %s: %v", app.Namespace,
+			daemonSet.Name, app.Spec.DataCache.Name, err)
+	}
+
+	if len(app.Spec.DataCache.WarmupPaths) == 0 {
+		return nil
+	}
+	job := buildDataCacheWarmupJob(app, image)
+	if _, err := c.kubeClient.BatchV1().Jobs(app.Namespace).Create(job); err != nil {
+		// The cache of a resubmitted application has been warmed up before.
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to create Job %s/%s: %v", app.Namespace, job.Name, err)
+	}
+	c.recorder.Eventf(
+		app,
+		apiv1.EventTypeNormal,
+		"SparkApplicationDataCacheWarmupStarted",
+		"Started Job %s to load %d paths into data cache %s",
+		job.Name,
+		len(app.Spec.DataCache.WarmupPaths),
+		app.Spec.DataCache.Name)
+	return nil
+}
+
+// hasOwner tells if the given owner references include the given application.
+func hasOwner(references []metav1.OwnerReference, app *v1beta1.SparkApplication) bool {
+	for _, reference := range references {
+		if reference.UID == app.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestValidateDataCache(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{DataCache: &v1beta1.DataCacheSpec{Name: "hot-tables"}},
+	}
+	assert.Nil(t, validateDataCache(app))
+
+	sizeLimit := "100Gi"
+	app.Spec.DataCache.SizeLimit = &sizeLimit
+	assert.Nil(t, validateDataCache(app))
+
+	sizeLimit = "a lot"
+	assert.NotNil(t, validateDataCache(app))
+
+	app.Spec.DataCache = &v1beta1.DataCacheSpec{Name: "Hot_Tables"}
+	assert.NotNil(t, validateDataCache(app))
+}
+
+func TestSetUpDataCache(t *testing.T) {
+	sizeLimit := "100Gi"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "foo-123"},
+		Spec: v1beta1.SparkApplicationSpec{
+			NodeSelector: map[string]string{"pool": "spark"},
+			DataCache: &v1beta1.DataCacheSpec{
+				Name:        "hot-tables",
+				SizeLimit:   &sizeLimit,
+				WarmupPaths: []string{"s3a://bucket/tables/dim_customer"},
+			},
+		},
+	}
+	ctrl, recorder := newFakeController(app)
+
+	// The image must be set by the application or the operator.
+	assert.NotNil(t, ctrl.setUpDataCache(app))
+	ctrl.SetDataCacheImage("cache:latest", []string{"cache:canary"})
+	assert.Nil(t, ctrl.setUpDataCache(app))
+
+	daemonSet, err := ctrl.kubeClient.AppsV1().DaemonSets("test").Get("hot-tables-data-cache", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "hot-tables", daemonSet.Spec.Selector.MatchLabels[config.DataCacheNameLabel])
+	assert.Equal(t, map[string]string{"pool": "spark"}, daemonSet.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, "/var/cache/spark-data/test/hot-tables", daemonSet.Spec.Template.Spec.Volumes[0].HostPath.Path)
+	container := daemonSet.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "cache:latest", container.Image)
+	assert.Equal(t, []string{"daemon"}, container.Args)
+	assert.Equal(t, apiv1.MountPropagationBidirectional, *container.VolumeMounts[0].MountPropagation)
+	assert.Contains(t, container.Env, apiv1.EnvVar{Name: config.DataCacheSizeEnvVar, Value: "100Gi"})
+	assert.Equal(t, 1, len(daemonSet.OwnerReferences))
+	assert.Nil(t, daemonSet.OwnerReferences[0].Controller)
+
+	job, err := ctrl.kubeClient.BatchV1().Jobs("test").Get("foo-cache-warmup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "cache:latest", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"warmup", "s3a://bucket/tables/dim_customer"}, job.Spec.Template.Spec.Containers[0].Args)
+	assert.Equal(t, 1, len(recorder.Events))
+
+	// Another application using the cache shares the DaemonSet, and a resubmission does not warm up again.
+	other := app.DeepCopy()
+	other.Name = "bar"
+	other.UID = "bar-456"
+	other.Spec.DataCache.WarmupPaths = nil
+	assert.Nil(t, ctrl.setUpDataCache(other))
+	assert.Nil(t, ctrl.setUpDataCache(app))
+	daemonSet, err = ctrl.kubeClient.AppsV1().DaemonSets("test").Get("hot-tables-data-cache", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(daemonSet.OwnerReferences))
+	assert.Equal(t, 1, len(recorder.Events))
+}
+
+func TestGetDataCacheImage(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{DataCache: &v1beta1.DataCacheSpec{Name: "hot-tables"}},
+	}
+	ctrl, _ := newFakeController(app)
+	ctrl.SetDataCacheImage("cache:latest", []string{"cache:canary"})
+
+	image, err := ctrl.getDataCacheImage(app)
+	assert.Nil(t, err)
+	assert.Equal(t, "cache:latest", image)
+
+	// Applications may only set the images allowed by the operator.
+	for _, allowed := range []string{"cache:latest", "cache:canary"} {
+		app.Spec.DataCache.Image = &allowed
+		image, err = ctrl.getDataCacheImage(app)
+		assert.Nil(t, err)
+		assert.Equal(t, allowed, image)
+	}
+	other := "attacker/rootkit:latest"
+	app.Spec.DataCache.Image = &other
+	_, err = ctrl.getDataCacheImage(app)
+	assert.NotNil(t, err)
+}
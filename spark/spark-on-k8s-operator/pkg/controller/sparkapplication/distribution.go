@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// SparkDistribution is a Spark distribution installed in the operator image that applications using a
+// matching Spark version are submitted with.
+type SparkDistribution struct {
+	// Version is the Spark version of the distribution, e.g., "3.1" or "3.1.2". It matches the
+	// spec.sparkVersion of an application if it is equal to it or a prefix of it ending at a dot.
+	Version string `json:"version"`
+	// SparkHome is the directory the distribution is installed in, which contains bin/spark-submit.
+	SparkHome string `json:"sparkHome"`
+	// SparkConf is the default Spark configuration of applications submitted with the distribution.
+	// Properties set in the spec of an application take precedence.
+	SparkConf map[string]string `json:"sparkConf,omitempty"`
+}
+
+// LoadSparkDistributions reads a YAML or JSON list of Spark distributions from the file with the given path.
+func LoadSparkDistributions(path string) ([]SparkDistribution, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Spark distributions from %s: %v", path, err)
+	}
+	var distributions []SparkDistribution
+	if err = yaml.Unmarshal(content, &distributions); err != nil {
+		return nil, fmt.Errorf("failed to parse Spark distributions from %s: %v", path, err)
+	}
+	for _, distribution := range distributions {
+		if distribution.Version == "" || distribution.SparkHome == "" {
+			return nil, fmt.Errorf("every Spark distribution in %s must have a version and a sparkHome", path)
+		}
+	}
+	return distributions, nil
+}
+
+// selectSparkDistribution returns the distribution with the most specific version matching the Spark
+// version of the given application, or nil if none matches.
+func selectSparkDistribution(app *v1beta1.SparkApplication, distributions []SparkDistribution) *SparkDistribution {
+	var selected *SparkDistribution
+	for i := range distributions {
+		distribution := &distributions[i]
+		if !sparkVersionMatches(distribution.Version, app.Spec.SparkVersion) {
+			continue
+		}
+		if selected == nil || len(distribution.Version) > len(selected.Version) {
+			selected = distribution
+		}
+	}
+	return selected
+}
+
+func sparkVersionMatches(distributionVersion string, appVersion string) bool {
+	distributionVersion = strings.TrimPrefix(distributionVersion, "v")
+	appVersion = strings.TrimPrefix(appVersion, "v")
+	return appVersion == distributionVersion || strings.HasPrefix(appVersion, distributionVersion+".")
+}
+
+// applySparkDistribution adds the default Spark configuration of the given distribution to the given
+// application, keeping any property the application sets itself.
+func applySparkDistribution(app *v1beta1.SparkApplication, distribution *SparkDistribution) {
+	if len(distribution.SparkConf) == 0 {
+		return
+	}
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	for key, value := range distribution.SparkConf {
+		if _, ok := app.Spec.SparkConf[key]; !ok {
+			app.Spec.SparkConf[key] = value
+		}
+	}
+}
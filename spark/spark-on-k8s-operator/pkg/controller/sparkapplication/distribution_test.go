@@ -0,0 +1,117 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestLoadSparkDistributions(t *testing.T) {
+	file, err := ioutil.TempFile("", "spark-distributions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(`
+- version: "2.4"
+  sparkHome: /opt/spark-2.4
+- version: "3.1"
+  sparkHome: /opt/spark-3.1
+  sparkConf:
+    spark.sql.adaptive.enabled: "true"
+`)
+	file.Close()
+
+	distributions, err := LoadSparkDistributions(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []SparkDistribution{
+		{Version: "2.4", SparkHome: "/opt/spark-2.4"},
+		{Version: "3.1", SparkHome: "/opt/spark-3.1", SparkConf: map[string]string{"spark.sql.adaptive.enabled": "true"}},
+	}, distributions)
+
+	ioutil.WriteFile(file.Name(), []byte(`[{"version": "2.4"}]`), 0644)
+	_, err = LoadSparkDistributions(file.Name())
+	assert.NotNil(t, err)
+}
+
+func TestSelectSparkDistribution(t *testing.T) {
+	distributions := []SparkDistribution{
+		{Version: "2.4", SparkHome: "/opt/spark-2.4"},
+		{Version: "3.1", SparkHome: "/opt/spark-3.1"},
+		{Version: "3.1.3", SparkHome: "/opt/spark-3.1.3"},
+	}
+
+	testFn := func(sparkVersion string, expectedHome string) {
+		app := &v1beta1.SparkApplication{Spec: v1beta1.SparkApplicationSpec{SparkVersion: sparkVersion}}
+		selected := selectSparkDistribution(app, distributions)
+		if expectedHome == "" {
+			assert.Nil(t, selected, "Spark version %s", sparkVersion)
+			return
+		}
+		if assert.NotNil(t, selected, "Spark version %s", sparkVersion) {
+			assert.Equal(t, expectedHome, selected.SparkHome, "Spark version %s", sparkVersion)
+		}
+	}
+	testFn("2.4.5", "/opt/spark-2.4")
+	testFn("v2.4.5", "/opt/spark-2.4")
+	testFn("3.1", "/opt/spark-3.1")
+	testFn("3.1.2", "/opt/spark-3.1")
+	testFn("3.1.3", "/opt/spark-3.1.3")
+	testFn("3.10.0", "")
+	testFn("3.5.0", "")
+	testFn("", "")
+}
+
+func TestApplySparkDistribution(t *testing.T) {
+	app := &v1beta1.SparkApplication{Spec: v1beta1.SparkApplicationSpec{
+		SparkConf: map[string]string{"spark.sql.shuffle.partitions": "10"},
+	}}
+	applySparkDistribution(app, &SparkDistribution{SparkConf: map[string]string{
+		"spark.sql.shuffle.partitions": "200",
+		"spark.sql.adaptive.enabled":   "true",
+	}})
+	assert.Equal(t, map[string]string{
+		"spark.sql.shuffle.partitions": "10",
+		"spark.sql.adaptive.enabled":   "true",
+	}, app.Spec.SparkConf)
+}
+
+func TestApplyDefaultSparkConf(t *testing.T) {
+	c := &Controller{}
+	app := &v1beta1.SparkApplication{}
+	c.applyDefaultSparkConf(app)
+	assert.Nil(t, app.Spec.SparkConf)
+
+	c.SetDefaultSparkConf(map[string]string{
+		"spark.eventLog.enabled":       "true",
+		"spark.sql.shuffle.partitions": "200",
+	})
+	app.Spec.SparkConf = map[string]string{"spark.sql.shuffle.partitions": "10"}
+	c.applyDefaultSparkConf(app)
+	assert.Equal(t, map[string]string{
+		"spark.eventLog.enabled":       "true",
+		"spark.sql.shuffle.partitions": "10",
+	}, app.Spec.SparkConf)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	driverLogConfigMapKey = "driver.log"
+	defaultDriverLogKB    = 64
+	// maxDriverLogKB keeps the ConfigMap well below the 1 MiB size limit of objects.
+	maxDriverLogKB = 512
+)
+
+func getDriverLogConfigMapName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "driver-log", util.DNS1123SubdomainMaxLength)
+}
+
+// getDriverLogMaxBytes returns the number of bytes at the end of the driver log of the given app that are saved.
+func getDriverLogMaxBytes(app *v1beta1.SparkApplication) int {
+	kb := int32(defaultDriverLogKB)
+	if app.Spec.DriverLogCapture.MaxKB != nil && *app.Spec.DriverLogCapture.MaxKB > 0 {
+		kb = *app.Spec.DriverLogCapture.MaxKB
+	}
+	if kb > maxDriverLogKB {
+		kb = maxDriverLogKB
+	}
+	return int(kb) * 1024
+}
+
+// readTail reads the given reader to the end and returns at most the last maxBytes bytes read, so that
+// arbitrarily long logs are read with bounded memory.
+func readTail(r io.Reader, maxBytes int) ([]byte, error) {
+	buf := make([]byte, 32*1024)
+	tail := make([]byte, 0, maxBytes+len(buf))
+	for {
+		n, err := r.Read(buf)
+		tail = append(tail, buf[:n]...)
+		if len(tail) > maxBytes && cap(tail)-len(tail) < len(buf) {
+			tail = append(tail[:0], tail[len(tail)-maxBytes:]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(tail) > maxBytes {
+		tail = tail[len(tail)-maxBytes:]
+	}
+	return tail, nil
+}
+
+func buildDriverLogConfigMap(app *v1beta1.SparkApplication, log []byte) *apiv1.ConfigMap {
+	return &apiv1.ConfigMap{
+		ObjectMeta: buildAppResourceObjectMeta(app, getDriverLogConfigMapName(app)),
+		Data:       map[string]string{driverLogConfigMapKey: string(log)},
+	}
+}
+
+// captureDriverLog saves the end of the log of the driver of the given failed application to a ConfigMap owned
+// by the application and records the name of the ConfigMap in its status.
+func (c *Controller) captureDriverLog(app *v1beta1.SparkApplication) error {
+	podName := app.Status.DriverInfo.PodName
+	if podName == "" {
+		return nil
+	}
+	stream, err := c.kubeClient.CoreV1().Pods(app.Namespace).GetLogs(podName,
+		&apiv1.PodLogOptions{Container: sparkDriverContainerName}).Stream()
+	if err != nil {
+		return fmt.Errorf("failed to get the log of driver pod %s/%s: %v", app.Namespace, podName, err)
+	}
+	defer stream.Close()
+	log, err := readTail(stream, getDriverLogMaxBytes(app))
+	if err != nil {
+		return fmt.Errorf("failed to read the log of driver pod %s/%s: %v", app.Namespace, podName, err)
+	}
+
+	configMap := buildDriverLogConfigMap(app, log)
+	c.getPropagatedMetadata(app).apply(&configMap.ObjectMeta)
+	existing, err := c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Create(configMap)
+	} else if err == nil {
+		// The ConfigMap holds the log of an earlier failed run.
+		existing.Data = configMap.Data
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Update(existing)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the driver log to ConfigMap %s/%s: %v", app.Namespace, configMap.Name, err)
+	}
+
+	glog.Infof("Saved the end of the driver log of failed SparkApplication %s/%s to ConfigMap %s",
+		app.Namespace, app.Name, configMap.Name)
+	app.Status.DriverLogConfigMap = configMap.Name
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestReadTail(t *testing.T) {
+	tail, err := readTail(strings.NewReader("short log"), 1024)
+	assert.Nil(t, err)
+	assert.Equal(t, "short log", string(tail))
+
+	// Logs longer than the read buffer are cut to their end.
+	log := bytes.Repeat([]byte("0123456789"), 10000)
+	log = append(log, []byte("the end")...)
+	tail, err = readTail(bytes.NewReader(log), 1024)
+	assert.Nil(t, err)
+	assert.Equal(t, 1024, len(tail))
+	assert.Equal(t, log[len(log)-1024:], tail)
+}
+
+func TestGetDriverLogMaxBytes(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			DriverLogCapture: &v1beta1.DriverLogCaptureSpec{},
+		},
+	}
+	assert.Equal(t, 64*1024, getDriverLogMaxBytes(app))
+
+	maxKB := int32(128)
+	app.Spec.DriverLogCapture.MaxKB = &maxKB
+	assert.Equal(t, 128*1024, getDriverLogMaxBytes(app))
+
+	maxKB = 4096
+	assert.Equal(t, 512*1024, getDriverLogMaxBytes(app))
+}
+
+func TestBuildDriverLogConfigMap(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "foo-123",
+		},
+	}
+
+	configMap := buildDriverLogConfigMap(app, []byte("Exception in thread \"main\""))
+	assert.Equal(t, "foo-driver-log", configMap.Name)
+	assert.Equal(t, "default", configMap.Namespace)
+	assert.Equal(t, "foo", configMap.Labels[config.SparkAppNameLabel])
+	assert.Equal(t, app.UID, configMap.OwnerReferences[0].UID)
+	assert.Equal(t, map[string]string{"driver.log": "Exception in thread \"main\""}, configMap.Data)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	sparkExecutorContainerName = "executor"
+	dumpUploadContainerName    = "dump-upload"
+	dumpUploadPathEnvVar       = "SPARK_DUMP_UPLOAD_PATH"
+	// dumpUploadScript uploads the directories of the pods of a run with the Hadoop FsShell, like
+	// outputCleanupScript, with the Hadoop generic options in the arguments of the container.
+	dumpUploadScript = `cd ` + config.DumpDir + ` && ` +
+		`fs() { "${SPARK_HOME:-/opt/spark}/bin/spark-class" org.apache.hadoop.fs.FsShell "$@"; } && ` +
+		`fs "$@" -mkdir -p "$SPARK_DUMP_UPLOAD_PATH" && fs "$@" -put -f * "$SPARK_DUMP_UPLOAD_PATH"`
+	dumpUploadBackoffLimit = 2
+	flightRecordingFile    = "flight-recording.jfr"
+	// sparkOutOfMemoryExitCode is the exit code of Spark JVMs whose uncaught exception handler caught an
+	// OutOfMemoryError.
+	sparkOutOfMemoryExitCode = 52
+	oomKilledReason          = "OOMKilled"
+)
+
+// getDumpPath returns the directory of the dumps of a run of the given application submitted at the given time,
+// relative to the root of its PersistentVolumeClaim of dumps.
+func getDumpPath(app *v1beta1.SparkApplication, submissionTime time.Time) string {
+	return path.Join(app.Name, strconv.FormatInt(submissionTime.Unix(), 10))
+}
+
+func getDumpUploadJobName(app *v1beta1.SparkApplication) string {
+	// Every run has its own Job.
+	return util.BuildName(app.Name, "dump-upload-"+path.Base(app.Status.Dumps.Path), util.DNS1123LabelMaxLength)
+}
+
+// getDumpJavaOptions returns the JVM options writing the dumps the given application asks for to DumpDir.
+func getDumpJavaOptions(app *v1beta1.SparkApplication) []string {
+	var options []string
+	if app.Spec.Debug.HeapDumpOnOutOfMemory == nil || *app.Spec.Debug.HeapDumpOnOutOfMemory {
+		options = append(options, "-XX:+HeapDumpOnOutOfMemoryError", "-XX:HeapDumpPath="+config.DumpDir)
+	}
+	if app.Spec.Debug.FlightRecorder != nil && *app.Spec.Debug.FlightRecorder {
+		options = append(options, fmt.Sprintf("-XX:StartFlightRecording=dumponexit=true,filename=%s/%s",
+			config.DumpDir, flightRecordingFile))
+	}
+	return options
+}
+
+// configDumps configures the driver and executors of the given application to write their dumps to the given
+// directory of the PersistentVolumeClaim of dumps, which the webhook mounts into them.
+func configDumps(app *v1beta1.SparkApplication, dumpPath string) error {
+	if app.Spec.Debug.ClaimName == "" {
+		return fmt.Errorf("debug requires a claimName")
+	}
+	if app.Spec.Debug.UploadPath != nil && !strings.Contains(*app.Spec.Debug.UploadPath, "://") {
+		return fmt.Errorf("debug upload path %q must be a URI with a scheme", *app.Spec.Debug.UploadPath)
+	}
+	if options := getDumpJavaOptions(app); len(options) > 0 {
+		javaOption := strings.Join(options, " ")
+		for _, javaOptions := range []**string{&app.Spec.Driver.JavaOptions, &app.Spec.Executor.JavaOptions} {
+			if *javaOptions == nil {
+				*javaOptions = &javaOption
+			} else {
+				joined := **javaOptions + " " + javaOption
+				*javaOptions = &joined
+			}
+		}
+	}
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	app.Spec.SparkConf[config.SparkDriverAnnotationKeyPrefix+config.DumpPathAnnotation] = dumpPath
+	app.Spec.SparkConf[config.SparkExecutorAnnotationKeyPrefix+config.DumpPathAnnotation] = dumpPath
+	return nil
+}
+
+// isOutOfMemory tells if the Spark container of the given driver or executor pod was terminated as it ran out of
+// memory, either by its JVM or by the kernel.
+func isOutOfMemory(pod *apiv1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != sparkDriverContainerName && status.Name != sparkExecutorContainerName {
+			continue
+		}
+		for _, terminated := range []*apiv1.ContainerStateTerminated{
+			status.State.Terminated, status.LastTerminationState.Terminated} {
+			if terminated != nil &&
+				(terminated.ExitCode == sparkOutOfMemoryExitCode || terminated.Reason == oomKilledReason) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordOutOfMemoryPods records the given driver and executor pods of the current run of the given application
+// that ran out of memory in its status, if it writes dumps.
+func recordOutOfMemoryPods(app *v1beta1.SparkApplication, pods []*apiv1.Pod) {
+	dumps := app.Status.Dumps
+	if dumps == nil {
+		return
+	}
+	for _, pod := range pods {
+		if !isOutOfMemory(pod) {
+			continue
+		}
+		recorded := false
+		for _, name := range dumps.OutOfMemoryPods {
+			recorded = recorded || name == pod.Name
+		}
+		if !recorded {
+			glog.Infof("Pod %s/%s of SparkApplication %s ran out of memory", pod.Namespace, pod.Name, app.Name)
+			dumps.OutOfMemoryPods = append(dumps.OutOfMemoryPods, pod.Name)
+		}
+	}
+}
+
+// hasRunEnded tells if the driver of the current run of an application in the given state has terminated.
+func hasRunEnded(state v1beta1.ApplicationStateType) bool {
+	switch state {
+	case v1beta1.SucceedingState, v1beta1.FailingState, v1beta1.CompletedState, v1beta1.FailedState,
+		v1beta1.InvalidatingState, v1beta1.PendingRerunState:
+		return true
+	}
+	return false
+}
+
+// buildDumpUploadJob returns the Job uploading the dumps of the current run of the given application to its
+// upload path.
+func buildDumpUploadJob(app *v1beta1.SparkApplication, uploadURL string) *batchv1.Job {
+	container := apiv1.Container{
+		Name:    dumpUploadContainerName,
+		Command: []string{"/bin/sh", "-c", dumpUploadScript, "spark-class"},
+		Args:    buildHadoopConfArgs(app),
+		Env:     []apiv1.EnvVar{{Name: dumpUploadPathEnvVar, Value: uploadURL}},
+		VolumeMounts: []apiv1.VolumeMount{{
+			Name:      config.DumpVolumeName,
+			MountPath: config.DumpDir,
+			SubPath:   app.Status.Dumps.Path,
+			ReadOnly:  true,
+		}},
+	}
+	job := buildDriverJob(app, getDumpUploadJobName(app), app.Spec.Debug.Image, container, dumpUploadBackoffLimit)
+	job.Spec.Template.Spec.Volumes = []apiv1.Volume{{
+		Name: config.DumpVolumeName,
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{
+				ClaimName: app.Spec.Debug.ClaimName,
+				ReadOnly:  true,
+			},
+		},
+	}}
+	return job
+}
+
+// uploadDumps starts the Job uploading the dumps of the current run of the given application once the run has
+// ended, if the driver or an executor ran out of memory, and records the Job and where the dumps are uploaded
+// to in the status.
+func (c *Controller) uploadDumps(app *v1beta1.SparkApplication) {
+	dumps := app.Status.Dumps
+	if dumps == nil || len(dumps.OutOfMemoryPods) == 0 || dumps.UploadJobName != "" ||
+		app.Spec.Debug == nil || app.Spec.Debug.UploadPath == nil || !hasRunEnded(app.Status.AppState.State) {
+		return
+	}
+	uploadURL := strings.TrimRight(*app.Spec.Debug.UploadPath, "/") + "/" + dumps.Path
+	job := buildDumpUploadJob(app, uploadURL)
+	_, err := c.kubeClient.BatchV1().Jobs(app.Namespace).Create(job)
+	if err = ignoreAlreadyExists(err); err != nil {
+		glog.Errorf("failed to create Job %s/%s: %v", app.Namespace, job.Name, err)
+		return
+	}
+	glog.Infof("Started Job %s/%s to upload the dumps of SparkApplication %s to %s", app.Namespace, job.Name,
+		app.Name, uploadURL)
+	dumps.UploadJobName = job.Name
+	dumps.UploadURL = uploadURL
+	c.recorder.Eventf(
+		app,
+		apiv1.EventTypeWarning,
+		"SparkApplicationDumpUploadStarted",
+		"Pods %s of SparkApplication %s ran out of memory, uploading their dumps to %s",
+		strings.Join(dumps.OutOfMemoryPods, ", "),
+		app.Name,
+		uploadURL)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/features"
+)
+
+func TestConfigDumps(t *testing.T) {
+	javaOptions := "-Dfoo=bar"
+	flightRecorder := true
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{JavaOptions: &javaOptions},
+			Debug:  &v1beta1.DebugSpec{ClaimName: "dumps", FlightRecorder: &flightRecorder},
+		},
+	}
+	dumpPath := getDumpPath(app, time.Unix(1600000000, 0))
+	assert.Equal(t, "foo/1600000000", dumpPath)
+	assert.NoError(t, configDumps(app, dumpPath))
+	options := "-XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/var/spark-dumps " +
+		"-XX:StartFlightRecording=dumponexit=true,filename=/var/spark-dumps/flight-recording.jfr"
+	assert.Equal(t, "-Dfoo=bar "+options, *app.Spec.Driver.JavaOptions)
+	assert.Equal(t, options, *app.Spec.Executor.JavaOptions)
+	assert.Equal(t, "-Dfoo=bar", javaOptions)
+	assert.Equal(t, dumpPath, app.Spec.SparkConf[config.SparkDriverAnnotationKeyPrefix+config.DumpPathAnnotation])
+	assert.Equal(t, dumpPath, app.Spec.SparkConf[config.SparkExecutorAnnotationKeyPrefix+config.DumpPathAnnotation])
+
+	app.Spec.Debug.ClaimName = ""
+	assert.Error(t, configDumps(app, dumpPath))
+	uploadPath := "bucket/dumps"
+	app.Spec.Debug = &v1beta1.DebugSpec{ClaimName: "dumps", UploadPath: &uploadPath}
+	assert.Error(t, configDumps(app, dumpPath))
+}
+
+func TestRecordOutOfMemoryPods(t *testing.T) {
+	newPod := func(name, container string, terminated *apiv1.ContainerStateTerminated) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: apiv1.PodStatus{ContainerStatuses: []apiv1.ContainerStatus{{
+				Name:  container,
+				State: apiv1.ContainerState{Terminated: terminated},
+			}}},
+		}
+	}
+	pods := []*apiv1.Pod{
+		newPod("foo-driver", sparkDriverContainerName, nil),
+		newPod("foo-exec-1", sparkExecutorContainerName, &apiv1.ContainerStateTerminated{ExitCode: 52}),
+		newPod("foo-exec-2", sparkExecutorContainerName, &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}),
+		newPod("foo-exec-3", sparkExecutorContainerName, &apiv1.ContainerStateTerminated{ExitCode: 1}),
+		newPod("foo-exec-4", "istio-proxy", &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}),
+	}
+	app := &v1beta1.SparkApplication{}
+	recordOutOfMemoryPods(app, pods)
+	assert.Nil(t, app.Status.Dumps)
+
+	app.Status.Dumps = &v1beta1.DumpStatus{Path: "foo/1600000000", OutOfMemoryPods: []string{"foo-exec-1"}}
+	recordOutOfMemoryPods(app, pods)
+	assert.Equal(t, []string{"foo-exec-1", "foo-exec-2"}, app.Status.Dumps.OutOfMemoryPods)
+}
+
+func TestSyncSparkApplication_UploadDumps(t *testing.T) {
+	if err := features.DefaultGate.Set("DumpUpload=true"); err != nil {
+		t.Fatal(err)
+	}
+	defer features.DefaultGate.Set("DumpUpload=false")
+
+	image := "spark:3.0.0"
+	uploadPath := "s3a://bucket/dumps/"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-1"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Image:      &image,
+			HadoopConf: map[string]string{"fs.s3a.endpoint": "s3.example.com"},
+			Debug:      &v1beta1.DebugSpec{ClaimName: "dumps", UploadPath: &uploadPath},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:        v1beta1.ApplicationState{State: v1beta1.FailingState},
+			TerminationTime: metav1.Now(),
+			Dumps:           &v1beta1.DumpStatus{Path: "foo/1600000000", OutOfMemoryPods: []string{"foo-exec-1"}},
+		},
+	}
+	ctrl, recorder := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.syncSparkApplication("test/foo"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := ctrl.kubeClient.BatchV1().Jobs("test").Get("foo-dump-upload-1600000000", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, "dumps", podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	container := podSpec.Containers[0]
+	assert.Equal(t, image, container.Image)
+	assert.Equal(t, []string{"-D", "fs.s3a.endpoint=s3.example.com"}, container.Args)
+	assert.Equal(t, "s3a://bucket/dumps/foo/1600000000", container.Env[0].Value)
+	assert.Equal(t, "foo/1600000000", container.VolumeMounts[0].SubPath)
+	assert.True(t, container.VolumeMounts[0].ReadOnly)
+
+	updated, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo-dump-upload-1600000000", updated.Status.Dumps.UploadJobName)
+	assert.Equal(t, "s3a://bucket/dumps/foo/1600000000", updated.Status.Dumps.UploadURL)
+	assert.True(t, len(recorder.Events) > 0)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	authSecretKey  = "secret"
+	authSecretDir  = "/etc/spark-auth-secret"
+	authSecretSize = 32
+	// Spark names the volume of a mounted Secret after the Secret with this suffix, and the volume name must be
+	// a DNS label.
+	secretVolumeNameSuffix = "-volume"
+)
+
+func getAuthSecretName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "auth-secret", util.DNS1123LabelMaxLength-len(secretVolumeNameSuffix))
+}
+
+// getLocalDataEncryptionConf returns the Spark configuration that encrypts the shuffle and spill files of an
+// application, along with the RPC traffic the key of the files is distributed to the executors with, using the
+// shared secret mounted at the given path.
+func getLocalDataEncryptionConf(secretFile string) map[string]string {
+	return map[string]string{
+		"spark.authenticate":             "true",
+		"spark.authenticate.secret.file": secretFile,
+		"spark.network.crypto.enabled":   "true",
+		"spark.io.encryption.enabled":    "true",
+	}
+}
+
+// setUpLocalDataEncryption creates the Secret holding the shared authentication secret of the given application
+// unless it exists, mounts it into the driver and executors, and sets the encryption configuration.
+func (c *Controller) setUpLocalDataEncryption(app *v1beta1.SparkApplication) error {
+	name := getAuthSecretName(app)
+	_, err := c.kubeClient.CoreV1().Secrets(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		secret := make([]byte, authSecretSize)
+		if _, err = rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate the authentication secret: %v", err)
+		}
+		_, err = c.kubeClient.CoreV1().Secrets(app.Namespace).Create(&apiv1.Secret{
+			ObjectMeta: buildAppResourceObjectMeta(app, name),
+			Data:       map[string][]byte{authSecretKey: []byte(base64.StdEncoding.EncodeToString(secret))},
+		})
+		err = ignoreAlreadyExists(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create Secret %s/%s: %v", app.Namespace, name, err)
+	}
+
+	secretInfo := v1beta1.SecretInfo{Name: name, Path: authSecretDir}
+	app.Spec.Driver.Secrets = append(app.Spec.Driver.Secrets, secretInfo)
+	app.Spec.Executor.Secrets = append(app.Spec.Executor.Secrets, secretInfo)
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	for key, value := range getLocalDataEncryptionConf(authSecretDir + "/" + authSecretKey) {
+		app.Spec.SparkConf[key] = value
+	}
+	return nil
+}
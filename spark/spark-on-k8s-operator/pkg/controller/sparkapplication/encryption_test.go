@@ -0,0 +1,63 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestSetUpLocalDataEncryption(t *testing.T) {
+	encrypt := true
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-1"},
+		Spec:       v1beta1.SparkApplicationSpec{EncryptLocalData: &encrypt},
+	}
+	ctrl, _ := newFakeController(app)
+
+	if err := ctrl.setUpLocalDataEncryption(app.DeepCopy()); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := ctrl.kubeClient.CoreV1().Secrets("test").Get("foo-auth-secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "uid-1", string(secret.OwnerReferences[0].UID))
+	assert.NotEmpty(t, secret.Data[authSecretKey])
+
+	// The secret is kept for later runs.
+	appToSubmit := app.DeepCopy()
+	if err := ctrl.setUpLocalDataEncryption(appToSubmit); err != nil {
+		t.Fatal(err)
+	}
+	secretAgain, err := ctrl.kubeClient.CoreV1().Secrets("test").Get("foo-auth-secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, secret.Data, secretAgain.Data)
+
+	secretInfo := v1beta1.SecretInfo{Name: "foo-auth-secret", Path: authSecretDir}
+	assert.Equal(t, []v1beta1.SecretInfo{secretInfo}, appToSubmit.Spec.Driver.Secrets)
+	assert.Equal(t, []v1beta1.SecretInfo{secretInfo}, appToSubmit.Spec.Executor.Secrets)
+	assert.Equal(t, "true", appToSubmit.Spec.SparkConf["spark.io.encryption.enabled"])
+	assert.Equal(t, "true", appToSubmit.Spec.SparkConf["spark.network.crypto.enabled"])
+	assert.Equal(t, "/etc/spark-auth-secret/secret", appToSubmit.Spec.SparkConf["spark.authenticate.secret.file"])
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// executorRestartCheckInterval is how often a rolling restart of executors waiting for the replacements of the
+// last wave is checked again.
+const executorRestartCheckInterval = 10 * time.Second
+
+// getExecutorsToRestart returns the names of the running executors created before the given time, sorted, and
+// whether any executor is still pending or being deleted.
+func getExecutorsToRestart(pods []*apiv1.Pod, requestedAt time.Time) ([]string, bool) {
+	var names []string
+	waiting := false
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == apiv1.PodPending {
+			waiting = true
+			continue
+		}
+		if pod.Status.Phase == apiv1.PodRunning && pod.CreationTimestamp.Time.Before(requestedAt) {
+			names = append(names, pod.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, waiting
+}
+
+// restartExecutors carries out the rolling restart of executors requested by the RestartExecutorsAnnotation of
+// the given running application. Executors created before the request are deleted in waves of the requested
+// size, which Spark replaces with executors picking up the current secrets and ConfigMaps. A wave only starts
+// once the interval since the previous one has passed and no executor is pending or terminating.
+func (c *Controller) restartExecutors(key string, app *v1beta1.SparkApplication) {
+	value, ok := app.Annotations[config.RestartExecutorsAnnotation]
+	if !ok || app.Status.AppState.State != v1beta1.RunningState {
+		return
+	}
+	request, err := config.ParseExecutorRestartRequest(value)
+	if err != nil {
+		glog.Errorf("failed to restart the executors of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+	// The executors of runs submitted after the request are all new already.
+	if request.RequestedAt.Before(app.Status.LastSubmissionAttemptTime.Time) {
+		return
+	}
+
+	now := time.Now()
+	requestedAt := metav1.NewTime(request.RequestedAt)
+	status := app.Status.ExecutorRestart
+	if status == nil || !status.RequestedAt.Equal(&requestedAt) {
+		status = &v1beta1.ExecutorRestartStatus{RequestedAt: requestedAt}
+		app.Status.ExecutorRestart = status
+		c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkExecutorRestartStarted",
+			"Restarting the executors of SparkApplication %s in batches of %d", app.Name, request.BatchSize)
+	}
+	if !status.CompletionTime.IsZero() {
+		return
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{
+		config.SparkAppNameLabel: app.Name,
+		config.SparkRoleLabel:    config.SparkExecutorRole,
+	})
+	pods, err := c.podLister.Pods(app.Namespace).List(selector)
+	if err != nil {
+		glog.Errorf("failed to get the executors of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+	names, waiting := getExecutorsToRestart(pods, request.RequestedAt)
+	if len(names) == 0 {
+		status.CompletionTime = metav1.NewTime(now)
+		c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkExecutorRestartCompleted",
+			"Restarted %d executors of SparkApplication %s", status.RestartedExecutors, app.Name)
+		return
+	}
+
+	interval := time.Duration(request.IntervalSeconds) * time.Second
+	if waiting {
+		c.queue.AddAfter(key, executorRestartCheckInterval)
+		return
+	}
+	if next := status.LastWaveTime.Add(interval); now.Before(next) {
+		c.queue.AddAfter(key, next.Sub(now))
+		return
+	}
+
+	if len(names) > int(request.BatchSize) {
+		names = names[:request.BatchSize]
+	}
+	for _, name := range names {
+		err := c.kubeClient.CoreV1().Pods(app.Namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				glog.Errorf("failed to delete executor pod %s/%s: %v", app.Namespace, name, err)
+			}
+			continue
+		}
+		glog.Infof("Deleted executor pod %s/%s of SparkApplication %s for a restart", app.Namespace, name, app.Name)
+		status.RestartedExecutors++
+	}
+	status.LastWaveTime = metav1.NewTime(now)
+	c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkExecutorRestartWave",
+		"Deleted %d executors of SparkApplication %s for a restart", len(names), app.Name)
+	if interval < executorRestartCheckInterval {
+		interval = executorRestartCheckInterval
+	}
+	c.queue.AddAfter(key, interval)
+}
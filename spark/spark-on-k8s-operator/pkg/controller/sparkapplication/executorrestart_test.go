@@ -0,0 +1,173 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestRestartExecutors(t *testing.T) {
+	now := time.Now()
+	requestedAt := now.Add(-time.Minute)
+	request, err := config.NewExecutorRestartRequest(2, 30*time.Second, requestedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "test",
+			Annotations: map[string]string{config.RestartExecutorsAnnotation: request},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.RunningState},
+			LastSubmissionAttemptTime: metav1.NewTime(now.Add(-time.Hour)),
+		},
+	}
+	newExecutor := func(name string, created time.Time, phase apiv1.PodPhase) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "test",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					config.SparkAppNameLabel: "foo",
+					config.SparkRoleLabel:    config.SparkExecutorRole,
+				},
+			},
+			Status: apiv1.PodStatus{Phase: phase},
+		}
+	}
+	old := now.Add(-30 * time.Minute)
+	pods := []*apiv1.Pod{
+		newExecutor("exec-3", old, apiv1.PodRunning),
+		newExecutor("exec-1", old, apiv1.PodRunning),
+		newExecutor("exec-2", old, apiv1.PodRunning),
+		newExecutor("exec-4", now, apiv1.PodRunning),
+		newExecutor("exec-5", old, apiv1.PodFailed),
+	}
+	ctrl, recorder := newFakeController(app, pods...)
+	for _, pod := range pods {
+		ctrl.kubeClient.CoreV1().Pods("test").Create(pod)
+	}
+	key := getApplicationKey(app.Namespace, app.Name)
+
+	// The first wave deletes the two oldest executors by name.
+	ctrl.restartExecutors(key, app)
+	status := app.Status.ExecutorRestart
+	assert.NotNil(t, status)
+	assert.Equal(t, requestedAt.Unix(), status.RequestedAt.Unix())
+	assert.Equal(t, int32(2), status.RestartedExecutors)
+	assert.False(t, status.LastWaveTime.IsZero())
+	assert.True(t, status.CompletionTime.IsZero())
+	for _, name := range []string{"exec-1", "exec-2"} {
+		_, err := ctrl.kubeClient.CoreV1().Pods("test").Get(name, metav1.GetOptions{})
+		assert.Error(t, err, name)
+	}
+	_, err = ctrl.kubeClient.CoreV1().Pods("test").Get("exec-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(recorder.Events))
+
+	// The next wave waits for the interval.
+	ctrl.restartExecutors(key, app)
+	assert.Equal(t, int32(2), status.RestartedExecutors)
+	_, err = ctrl.kubeClient.CoreV1().Pods("test").Get("exec-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// The next wave waits for the replacements of the last one.
+	pods = []*apiv1.Pod{
+		newExecutor("exec-3", old, apiv1.PodRunning),
+		newExecutor("exec-4", now, apiv1.PodRunning),
+		newExecutor("exec-6", now, apiv1.PodPending),
+	}
+	ctrl, _ = newFakeController(app, pods...)
+	for _, pod := range pods {
+		ctrl.kubeClient.CoreV1().Pods("test").Create(pod)
+	}
+	status.LastWaveTime = metav1.NewTime(now.Add(-time.Minute))
+	ctrl.restartExecutors(key, app)
+	assert.Equal(t, int32(2), status.RestartedExecutors)
+
+	pods[2].Status.Phase = apiv1.PodRunning
+	ctrl, _ = newFakeController(app, pods...)
+	for _, pod := range pods {
+		ctrl.kubeClient.CoreV1().Pods("test").Create(pod)
+	}
+	ctrl.restartExecutors(key, app)
+	assert.Equal(t, int32(3), status.RestartedExecutors)
+	_, err = ctrl.kubeClient.CoreV1().Pods("test").Get("exec-3", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// The restart completes once no executor created before the request is left.
+	remaining := []*apiv1.Pod{newExecutor("exec-4", now, apiv1.PodRunning), newExecutor("exec-6", now, apiv1.PodRunning)}
+	ctrl, _ = newFakeController(app, remaining...)
+	ctrl.restartExecutors(key, app)
+	assert.False(t, app.Status.ExecutorRestart.CompletionTime.IsZero())
+	assert.Equal(t, int32(3), app.Status.ExecutorRestart.RestartedExecutors)
+}
+
+func TestRestartExecutorsIgnoresStaleRequests(t *testing.T) {
+	now := time.Now()
+	request, err := config.NewExecutorRestartRequest(1, 0, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "test",
+			Annotations: map[string]string{config.RestartExecutorsAnnotation: request},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.RunningState},
+			LastSubmissionAttemptTime: metav1.NewTime(now.Add(-time.Minute)),
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	ctrl.restartExecutors(getApplicationKey(app.Namespace, app.Name), app)
+	assert.Nil(t, app.Status.ExecutorRestart)
+}
+
+func TestGetExecutorsToRestart(t *testing.T) {
+	requestedAt := time.Now()
+	before := metav1.NewTime(requestedAt.Add(-time.Minute))
+	deleted := metav1.Now()
+	pods := []*apiv1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", CreationTimestamp: before}, Status: apiv1.PodStatus{Phase: apiv1.PodRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", CreationTimestamp: before}, Status: apiv1.PodStatus{Phase: apiv1.PodRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", CreationTimestamp: metav1.NewTime(requestedAt)}, Status: apiv1.PodStatus{Phase: apiv1.PodRunning}},
+	}
+	names, waiting := getExecutorsToRestart(pods, requestedAt)
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.False(t, waiting)
+
+	pods = append(pods, &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "d", CreationTimestamp: before, DeletionTimestamp: &deleted},
+		Status:     apiv1.PodStatus{Phase: apiv1.PodRunning},
+	})
+	names, waiting = getExecutorsToRestart(pods, requestedAt)
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.True(t, waiting)
+}
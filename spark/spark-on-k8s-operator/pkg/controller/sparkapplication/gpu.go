@@ -0,0 +1,169 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// gpuDiscoveryScript is the script the executors discover the addresses of their NVIDIA GPUs with, which Spark
+// ships as an example.
+const gpuDiscoveryScript = `#!/usr/bin/env bash
+ADDRS=$(nvidia-smi --query-gpu=index --format=csv,noheader | sed -e ':a' -e 'N' -e '$!ba' -e 's/\n/","/g')
+echo {\"name\": \"gpu\", \"addresses\":[\"$ADDRS\"]}
+`
+
+// isRapidsEnabled tells if the given application runs SQL and DataFrame operations on the GPUs of its executors.
+func isRapidsEnabled(app *v1beta1.SparkApplication) bool {
+	return util.GetGPUSpec(app) != nil && app.Spec.GPU.Rapids != nil && *app.Spec.GPU.Rapids
+}
+
+// getRapidsJar returns the jar of the RAPIDS Accelerator the given application depends on, if any.
+func getRapidsJar(app *v1beta1.SparkApplication) string {
+	if !isRapidsEnabled(app) {
+		return ""
+	}
+	if app.Spec.GPU.RapidsJar != nil {
+		return *app.Spec.GPU.RapidsJar
+	}
+	return config.DefaultRapidsJar
+}
+
+// getConcurrentTasks returns the number of tasks each executor of the given application runs concurrently.
+func getConcurrentTasks(app *v1beta1.SparkApplication) (int64, error) {
+	cores := int64(1)
+	if app.Spec.Executor.Cores != nil {
+		cores = int64(*app.Spec.Executor.Cores)
+	}
+	taskCPUs := int64(1)
+	if value, ok := app.Spec.SparkConf[config.SparkTaskCPUsKey]; ok {
+		var err error
+		if taskCPUs, err = strconv.ParseInt(value, 10, 64); err != nil || taskCPUs < 1 {
+			return 0, fmt.Errorf("invalid %s %q", config.SparkTaskCPUsKey, value)
+		}
+	}
+	if cores < taskCPUs {
+		return 1, nil
+	}
+	return cores / taskCPUs, nil
+}
+
+// getTaskGPUAmount returns the amount of GPUs of each task of the given application, and checks that the GPUs
+// of an executor are enough for all the tasks it runs concurrently. Spark only supports fractional amounts that
+// evenly divide a GPU, so by default the concurrent tasks share the GPUs evenly, rounded down to such amounts.
+func getTaskGPUAmount(app *v1beta1.SparkApplication, gpus int64) (string, error) {
+	tasks, err := getConcurrentTasks(app)
+	if err != nil {
+		return "", err
+	}
+	var amount float64
+	if app.Spec.GPU.TaskAmount != nil {
+		if amount, err = strconv.ParseFloat(*app.Spec.GPU.TaskAmount, 64); err != nil || amount <= 0 {
+			return "", fmt.Errorf("invalid GPU taskAmount %q", *app.Spec.GPU.TaskAmount)
+		}
+		if (amount < 1 && amount > 0.5) || (amount > 1 && amount != math.Floor(amount)) {
+			return "", fmt.Errorf("invalid GPU taskAmount %q, fractional amounts must be at most 0.5 and "+
+				"amounts above 1 must be whole", *app.Spec.GPU.TaskAmount)
+		}
+	} else if gpus >= tasks {
+		amount = float64(gpus / tasks)
+	} else {
+		amount = 1 / math.Ceil(float64(tasks)/float64(gpus))
+	}
+
+	var slots int64
+	if amount < 1 {
+		slots = gpus * int64(math.Floor(1/amount))
+	} else {
+		slots = gpus / int64(amount)
+	}
+	if slots < tasks {
+		return "", fmt.Errorf("the %d GPUs of an executor only run %d of its %d concurrent tasks with a GPU "+
+			"taskAmount of %g", gpus, slots, tasks, amount)
+	}
+	return strconv.FormatFloat(amount, 'f', -1, 64), nil
+}
+
+// addGPUConfOptions returns the Spark configuration properties scheduling the tasks of the given application on
+// the GPUs of its executors, which discover them with the script the webhook mounts, and enabling the RAPIDS
+// Accelerator if the application uses it.
+func addGPUConfOptions(app *v1beta1.SparkApplication) ([]string, error) {
+	gpu := util.GetGPUSpec(app)
+	if gpu == nil {
+		return nil, nil
+	}
+	if app.Spec.MLMode != nil && app.Spec.MLMode.GPU != nil {
+		return nil, fmt.Errorf("gpu and mlMode.gpu are mutually exclusive")
+	}
+	if gpu.Quantity < 1 {
+		return nil, fmt.Errorf("invalid GPU quantity %d", gpu.Quantity)
+	}
+	taskAmount, err := getTaskGPUAmount(app, gpu.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	options := getExecutorResourceConfOptions(config.SparkExecutorResourceKeyPrefix, nil, nil, nil, gpu)
+	options = append(options,
+		fmt.Sprintf("%s=%s/%s", config.SparkExecutorGPUDiscoveryScriptKey, config.GPUDiscoveryDir,
+			config.GPUDiscoveryScriptKey),
+		fmt.Sprintf("%s=%s", config.SparkTaskGPUAmountKey, taskAmount))
+	if isRapidsEnabled(app) {
+		plugins := app.Spec.SparkConf[config.SparkPluginsKey]
+		if !strings.Contains(plugins, config.RapidsSQLPlugin) {
+			plugins = strings.TrimPrefix(plugins+","+config.RapidsSQLPlugin, ",")
+		}
+		options = append(options, fmt.Sprintf("%s=%s", config.SparkPluginsKey, plugins))
+		if _, ok := app.Spec.SparkConf[config.SparkRapidsSQLEnabledKey]; !ok {
+			options = append(options, fmt.Sprintf("%s=true", config.SparkRapidsSQLEnabledKey))
+		}
+	}
+	return options, nil
+}
+
+// setUpGPUDiscoveryScript creates or updates the ConfigMap holding the GPU discovery script of the given
+// application, which the webhook mounts into the executors.
+func (c *Controller) setUpGPUDiscoveryScript(app *v1beta1.SparkApplication) error {
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: buildAppResourceObjectMeta(app, util.GetGPUDiscoveryConfigMapName(app)),
+		Data:       map[string]string{config.GPUDiscoveryScriptKey: gpuDiscoveryScript},
+	}
+	c.getPropagatedMetadata(app).apply(&configMap.ObjectMeta)
+	existing, err := c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Create(configMap)
+	} else if err == nil {
+		existing.Data = configMap.Data
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Update(existing)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up the GPU discovery script ConfigMap %s/%s: %v", app.Namespace,
+			configMap.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetTaskGPUAmount(t *testing.T) {
+	type testcase struct {
+		name       string
+		cores      float32
+		taskCPUs   string
+		gpus       int64
+		taskAmount string
+		expected   string
+		err        bool
+	}
+	testcases := []testcase{
+		{name: "one task per GPU", cores: 2, gpus: 2, expected: "1"},
+		{name: "tasks sharing a GPU", cores: 4, gpus: 1, expected: "0.25"},
+		{name: "uneven sharing", cores: 3, gpus: 2, expected: "0.5"},
+		{name: "multiple GPUs per task", cores: 2, gpus: 4, expected: "2"},
+		{name: "task CPUs", cores: 8, taskCPUs: "4", gpus: 1, expected: "0.5"},
+		{name: "task amount", cores: 4, gpus: 2, taskAmount: "0.5", expected: "0.5"},
+		{name: "too few GPUs", cores: 4, gpus: 1, taskAmount: "0.5", err: true},
+		{name: "invalid fraction", cores: 1, gpus: 1, taskAmount: "0.75", err: true},
+		{name: "invalid task amount", cores: 1, gpus: 1, taskAmount: "all", err: true},
+		{name: "invalid task CPUs", cores: 1, taskCPUs: "0", gpus: 1, err: true},
+	}
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			Spec: v1beta1.SparkApplicationSpec{
+				GPU:       &v1beta1.GPUAccelerationSpec{Enabled: true},
+				SparkConf: map[string]string{},
+			},
+		}
+		cores := test.cores
+		app.Spec.Executor.Cores = &cores
+		if test.taskCPUs != "" {
+			app.Spec.SparkConf[config.SparkTaskCPUsKey] = test.taskCPUs
+		}
+		if test.taskAmount != "" {
+			taskAmount := test.taskAmount
+			app.Spec.GPU.TaskAmount = &taskAmount
+		}
+		amount, err := getTaskGPUAmount(app, test.gpus)
+		if test.err {
+			assert.NotNil(t, err, test.name)
+			continue
+		}
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.expected, amount, test.name)
+	}
+}
+
+func TestAddGPUConfOptions(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+	options, err := addGPUConfOptions(app)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(options))
+	assert.Equal(t, "", getRapidsJar(app))
+
+	rapids := true
+	cores := float32(4)
+	app.Spec.Executor.Cores = &cores
+	app.Spec.GPU = &v1beta1.GPUAccelerationSpec{Enabled: true, Rapids: &rapids}
+	app.Spec.SparkConf = map[string]string{config.SparkPluginsKey: "com.example.Plugin"}
+	options, err = addGPUConfOptions(app)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"spark.executor.resource.gpu.amount=1",
+		"spark.executor.resource.gpu.vendor=nvidia.com",
+		"spark.executor.resource.gpu.discoveryScript=/etc/spark-gpu-discovery/getGpusResources.sh",
+		"spark.task.resource.gpu.amount=0.25",
+		"spark.plugins=com.example.Plugin,com.nvidia.spark.SQLPlugin",
+		"spark.rapids.sql.enabled=true",
+	}, options)
+	assert.Equal(t, config.DefaultRapidsJar, getRapidsJar(app))
+
+	app.Spec.Deps.Jars = []string{"local:///opt/spark/jars/app.jar"}
+	assert.Equal(t, []string{"--jars", "local:///opt/spark/jars/app.jar," + config.DefaultRapidsJar},
+		addDependenciesConfOptions(app))
+	assert.Equal(t, []string{"local:///opt/spark/jars/app.jar"}, app.Spec.Deps.Jars)
+
+	// The GPUs of the application and of ML mode are mutually exclusive.
+	app.Spec.MLMode = &v1beta1.MLModeSpec{GPU: &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 1}}
+	_, err = addGPUConfOptions(app)
+	assert.NotNil(t, err)
+
+	// Disabled GPUs are ignored.
+	app.Spec.GPU.Enabled = false
+	options, err = addGPUConfOptions(app)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(options))
+	assert.Equal(t, "", getRapidsJar(app))
+}
+
+func TestSetUpGPUDiscoveryScript(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "foo-123"},
+		Spec:       v1beta1.SparkApplicationSpec{GPU: &v1beta1.GPUAccelerationSpec{Enabled: true}},
+	}
+	ctrl, _ := newFakeController(app)
+	assert.Nil(t, ctrl.setUpGPUDiscoveryScript(app))
+	// Setting it up again for a new run updates the existing ConfigMap.
+	assert.Nil(t, ctrl.setUpGPUDiscoveryScript(app))
+
+	configMap, err := ctrl.kubeClient.CoreV1().ConfigMaps("test").Get("foo-gpu-discovery", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, gpuDiscoveryScript, configMap.Data[config.GPUDiscoveryScriptKey])
+	assert.Equal(t, "foo", configMap.Labels[config.SparkAppNameLabel])
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	coreSiteKey               = "core-site.xml"
+	hdfsSiteKey               = "hdfs-site.xml"
+	krb5ConfKey               = "krb5.conf"
+	hdfsFailoverProxyProvider = "org.apache.hadoop.hdfs.server.namenode.ha.ConfiguredFailoverProxyProvider"
+)
+
+var rpcProtections = map[string]bool{"authentication": true, "integrity": true, "privacy": true}
+
+// hadoopConfiguration is the XML document of a Hadoop configuration file such as core-site.xml.
+type hadoopConfiguration struct {
+	XMLName    xml.Name         `xml:"configuration"`
+	Properties []hadoopProperty `xml:"property"`
+}
+
+type hadoopProperty struct {
+	Name  string `xml:"name"`
+	Value string `xml:"value"`
+}
+
+// renderHadoopConfiguration renders the given properties as a Hadoop configuration file, sorted by name.
+func renderHadoopConfiguration(properties map[string]string) (string, error) {
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	configuration := hadoopConfiguration{}
+	for _, name := range names {
+		configuration.Properties = append(configuration.Properties, hadoopProperty{Name: name, Value: properties[name]})
+	}
+	out, err := xml.MarshalIndent(configuration, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out) + "\n", nil
+}
+
+// validateHadoopCluster checks the given HadoopCluster spec.
+func validateHadoopCluster(spec *v1beta1.HadoopClusterSpec) error {
+	if spec.NameService == "" {
+		return fmt.Errorf("nameService is required")
+	}
+	if len(spec.NameNodes) == 0 {
+		return fmt.Errorf("at least one namenode is required")
+	}
+	names := make(map[string]bool)
+	for _, nameNode := range spec.NameNodes {
+		if nameNode.Name == "" || nameNode.RPCAddress == "" {
+			return fmt.Errorf("namenodes require a name and an rpcAddress")
+		}
+		if names[nameNode.Name] {
+			return fmt.Errorf("duplicate namenode %q", nameNode.Name)
+		}
+		names[nameNode.Name] = true
+	}
+	if spec.RPCProtection != nil && !rpcProtections[*spec.RPCProtection] {
+		return fmt.Errorf("invalid rpcProtection %q", *spec.RPCProtection)
+	}
+	if spec.Kerberos != nil && spec.Kerberos.Realm == "" {
+		return fmt.Errorf("kerberos requires a realm")
+	}
+	return nil
+}
+
+// buildHadoopConfigFiles returns the Hadoop configuration files of clients of the HDFS cluster with the given
+// spec, i.e., core-site.xml, hdfs-site.xml and, if the spec sets Kerberos KDCs, krb5.conf. The namenodes are
+// always configured as an HA nameservice, which also works with a single namenode.
+func buildHadoopConfigFiles(spec *v1beta1.HadoopClusterSpec) (map[string]string, error) {
+	if err := validateHadoopCluster(spec); err != nil {
+		return nil, err
+	}
+
+	coreSite := map[string]string{"fs.defaultFS": "hdfs://" + spec.NameService}
+	if spec.RPCProtection != nil {
+		coreSite["hadoop.rpc.protection"] = *spec.RPCProtection
+	}
+	hdfsSite := map[string]string{
+		"dfs.nameservices": spec.NameService,
+		"dfs.client.failover.proxy.provider." + spec.NameService: hdfsFailoverProxyProvider,
+	}
+	var nameNodes []string
+	for _, nameNode := range spec.NameNodes {
+		nameNodes = append(nameNodes, nameNode.Name)
+		key := spec.NameService + "." + nameNode.Name
+		hdfsSite["dfs.namenode.rpc-address."+key] = nameNode.RPCAddress
+		if nameNode.HTTPAddress != nil {
+			hdfsSite["dfs.namenode.http-address."+key] = *nameNode.HTTPAddress
+		}
+	}
+	hdfsSite["dfs.ha.namenodes."+spec.NameService] = strings.Join(nameNodes, ",")
+
+	files := make(map[string]string)
+	if kerberos := spec.Kerberos; kerberos != nil {
+		coreSite["hadoop.security.authentication"] = "kerberos"
+		coreSite["hadoop.security.authorization"] = "true"
+		principal := "nn/_HOST@" + kerberos.Realm
+		if kerberos.NameNodePrincipal != nil {
+			principal = *kerberos.NameNodePrincipal
+		}
+		hdfsSite["dfs.namenode.kerberos.principal"] = principal
+		if len(kerberos.KDCs) > 0 {
+			files[krb5ConfKey] = buildKrb5Conf(kerberos)
+		}
+	}
+
+	for name, value := range spec.CoreSite {
+		coreSite[name] = value
+	}
+	for name, value := range spec.HDFSSite {
+		hdfsSite[name] = value
+	}
+	var err error
+	if files[coreSiteKey], err = renderHadoopConfiguration(coreSite); err != nil {
+		return nil, err
+	}
+	if files[hdfsSiteKey], err = renderHadoopConfiguration(hdfsSite); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// buildKrb5Conf returns a krb5.conf making the given realm the default one, served by its KDCs.
+func buildKrb5Conf(kerberos *v1beta1.HadoopKerberosSpec) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "[libdefaults]\n  default_realm = %s\n\n[realms]\n  %s = {\n", kerberos.Realm, kerberos.Realm)
+	for _, kdc := range kerberos.KDCs {
+		fmt.Fprintf(&b, "    kdc = %s\n", kdc)
+	}
+	b.WriteString("  }\n")
+	return b.String()
+}
+
+// setUpHadoopConfig creates or updates the ConfigMap holding the Hadoop configuration files generated from the
+// HadoopCluster the given application refers to, which the webhook mounts like hadoopConfigMap. If a krb5.conf is
+// generated, the driver and executors are pointed to it.
+func (c *Controller) setUpHadoopConfig(app *v1beta1.SparkApplication) error {
+	if app.Spec.HadoopConfigMap != nil {
+		return fmt.Errorf("hadoopClusterRef and hadoopConfigMap are mutually exclusive")
+	}
+	cluster, err := c.crdClient.SparkoperatorV1beta1().HadoopClusters(app.Namespace).Get(*app.Spec.HadoopClusterRef,
+		metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get HadoopCluster %s/%s: %v", app.Namespace, *app.Spec.HadoopClusterRef, err)
+	}
+	files, err := buildHadoopConfigFiles(&cluster.Spec)
+	if err != nil {
+		return fmt.Errorf("invalid HadoopCluster %s/%s: %v", app.Namespace, cluster.Name, err)
+	}
+
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: buildAppResourceObjectMeta(app, *util.GetHadoopConfigMapName(app)),
+		Data:       files,
+	}
+	c.getPropagatedMetadata(app).apply(&configMap.ObjectMeta)
+	existing, err := c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Create(configMap)
+	} else if err == nil {
+		existing.Data = configMap.Data
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Update(existing)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up the Hadoop configuration ConfigMap %s/%s: %v", app.Namespace,
+			configMap.Name, err)
+	}
+
+	if _, ok := files[krb5ConfKey]; ok {
+		javaOption := fmt.Sprintf("-Djava.security.krb5.conf=%s/%s", config.DefaultHadoopConfDir, krb5ConfKey)
+		for _, javaOptions := range []**string{&app.Spec.Driver.JavaOptions, &app.Spec.Executor.JavaOptions} {
+			if *javaOptions == nil {
+				*javaOptions = &javaOption
+			} else {
+				joined := **javaOptions + " " + javaOption
+				*javaOptions = &joined
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestRenderHadoopConfiguration(t *testing.T) {
+	out, err := renderHadoopConfiguration(map[string]string{"b": "x&y", "a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+  <property>
+    <name>a</name>
+    <value>1</value>
+  </property>
+  <property>
+    <name>b</name>
+    <value>x&amp;y</value>
+  </property>
+</configuration>
+`
+	assert.Equal(t, expected, out)
+}
+
+func TestBuildHadoopConfigFiles(t *testing.T) {
+	privacy := "privacy"
+	httpAddress := "nn1.example.com:9870"
+	spec := &v1beta1.HadoopClusterSpec{
+		NameService: "prod",
+		NameNodes: []v1beta1.HadoopNameNode{
+			{Name: "nn1", RPCAddress: "nn1.example.com:8020", HTTPAddress: &httpAddress},
+			{Name: "nn2", RPCAddress: "nn2.example.com:8020"},
+		},
+		RPCProtection: &privacy,
+		Kerberos:      &v1beta1.HadoopKerberosSpec{Realm: "EXAMPLE.COM", KDCs: []string{"kdc1.example.com"}},
+		HDFSSite:      map[string]string{"dfs.client.use.datanode.hostname": "true"},
+	}
+	files, err := buildHadoopConfigFiles(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(files))
+	coreSite := files[coreSiteKey]
+	assert.True(t, strings.Contains(coreSite, "<name>fs.defaultFS</name>\n    <value>hdfs://prod</value>"))
+	assert.True(t, strings.Contains(coreSite, "<name>hadoop.rpc.protection</name>\n    <value>privacy</value>"))
+	assert.True(t, strings.Contains(coreSite, "<name>hadoop.security.authentication</name>\n    <value>kerberos</value>"))
+	hdfsSite := files[hdfsSiteKey]
+	assert.True(t, strings.Contains(hdfsSite, "<name>dfs.ha.namenodes.prod</name>\n    <value>nn1,nn2</value>"))
+	assert.True(t, strings.Contains(hdfsSite,
+		"<name>dfs.namenode.rpc-address.prod.nn2</name>\n    <value>nn2.example.com:8020</value>"))
+	assert.True(t, strings.Contains(hdfsSite,
+		"<name>dfs.namenode.http-address.prod.nn1</name>\n    <value>nn1.example.com:9870</value>"))
+	assert.False(t, strings.Contains(hdfsSite, "dfs.namenode.http-address.prod.nn2"))
+	assert.True(t, strings.Contains(hdfsSite,
+		"<name>dfs.namenode.kerberos.principal</name>\n    <value>nn/_HOST@EXAMPLE.COM</value>"))
+	assert.True(t, strings.Contains(hdfsSite, "<name>dfs.client.use.datanode.hostname</name>"))
+	assert.Equal(t, "[libdefaults]\n  default_realm = EXAMPLE.COM\n\n[realms]\n  EXAMPLE.COM = {\n"+
+		"    kdc = kdc1.example.com\n  }\n", files[krb5ConfKey])
+
+	// Without Kerberos, only the Hadoop configuration files are generated.
+	spec.Kerberos = nil
+	files, err = buildHadoopConfigFiles(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(files))
+	assert.False(t, strings.Contains(files[coreSiteKey], "kerberos"))
+
+	invalid := "none"
+	spec.RPCProtection = &invalid
+	_, err = buildHadoopConfigFiles(spec)
+	assert.NotNil(t, err)
+	spec.RPCProtection = nil
+	spec.NameNodes = append(spec.NameNodes, v1beta1.HadoopNameNode{Name: "nn1", RPCAddress: "nn3.example.com:8020"})
+	_, err = buildHadoopConfigFiles(spec)
+	assert.NotNil(t, err)
+}
+
+func TestSetUpHadoopConfig(t *testing.T) {
+	clusterName := "prod"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "foo-123"},
+		Spec:       v1beta1.SparkApplicationSpec{HadoopClusterRef: &clusterName},
+	}
+	ctrl, _ := newFakeController(app)
+
+	// The HadoopCluster does not exist yet.
+	assert.NotNil(t, ctrl.setUpHadoopConfig(app))
+
+	_, err := ctrl.crdClient.SparkoperatorV1beta1().HadoopClusters("test").Create(&v1beta1.HadoopCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "test"},
+		Spec: v1beta1.HadoopClusterSpec{
+			NameService: "prod",
+			NameNodes:   []v1beta1.HadoopNameNode{{Name: "nn1", RPCAddress: "nn1.example.com:8020"}},
+			Kerberos:    &v1beta1.HadoopKerberosSpec{Realm: "EXAMPLE.COM", KDCs: []string{"kdc1.example.com"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, ctrl.setUpHadoopConfig(app))
+
+	configMap, err := ctrl.kubeClient.CoreV1().ConfigMaps("test").Get("foo-hadoop-conf", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(configMap.Data))
+	assert.Equal(t, "foo", configMap.Labels[config.SparkAppNameLabel])
+	assert.Equal(t, "-Djava.security.krb5.conf=/etc/hadoop/conf/krb5.conf", *app.Spec.Driver.JavaOptions)
+	assert.Equal(t, "-Djava.security.krb5.conf=/etc/hadoop/conf/krb5.conf", *app.Spec.Executor.JavaOptions)
+
+	// Referring to a HadoopCluster and setting hadoopConfigMap are mutually exclusive.
+	hadoopConfigMap := "hadoop-conf"
+	app.Spec.HadoopConfigMap = &hadoopConfigMap
+	assert.NotNil(t, ctrl.setUpHadoopConfig(app))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// defaultIdempotencyWindow is how long an application that succeeded keeps new applications with the same
+// idempotency key from running by default.
+const defaultIdempotencyWindow = time.Hour
+
+// SetIdempotencyWindow sets how long an application that succeeded keeps new applications with the same
+// idempotency key from running.
+func (c *Controller) SetIdempotencyWindow(window time.Duration) {
+	c.idempotencyWindow = window
+}
+
+// findOriginalApplication returns the application the given new application duplicates, i.e., another application
+// in its namespace with the same idempotency key that is running or succeeded within the idempotency window, or
+// nil if there is none. Of new applications with the same key, the one created first is the original.
+func (c *Controller) findOriginalApplication(app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
+	if app.Spec.IdempotencyKey == nil || *app.Spec.IdempotencyKey == "" {
+		return nil, nil
+	}
+	apps, err := c.applicationLister.SparkApplications(app.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the SparkApplications in namespace %s: %v", app.Namespace, err)
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	now := time.Now()
+	for _, other := range apps {
+		if other.Name == app.Name || other.Spec.IdempotencyKey == nil ||
+			*other.Spec.IdempotencyKey != *app.Spec.IdempotencyKey || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if isOriginalOf(other, app, c.idempotencyWindow, now) {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// isOriginalOf tells if the given application with the same idempotency key as the given new application is
+// running or succeeded within the given window before now.
+func isOriginalOf(other, app *v1beta1.SparkApplication, window time.Duration, now time.Time) bool {
+	switch other.Status.AppState.State {
+	case v1beta1.FailedState, v1beta1.SkippedState:
+		return false
+	case v1beta1.CompletedState:
+		terminationTime := other.Status.TerminationTime
+		return !terminationTime.IsZero() && now.Sub(terminationTime.Time) < window
+	case v1beta1.NewState:
+		if other.CreationTimestamp.Equal(&app.CreationTimestamp) {
+			return other.Name < app.Name
+		}
+		return other.CreationTimestamp.Before(&app.CreationTimestamp)
+	}
+	return true
+}
+
+// skipDuplicateApplication moves the given new application to the terminal SkippedState as a duplicate of the
+// given original application.
+func skipDuplicateApplication(app, original *v1beta1.SparkApplication) {
+	glog.Infof("SparkApplication %s/%s has the idempotency key %q of SparkApplication %s, skipping it",
+		app.Namespace, app.Name, *app.Spec.IdempotencyKey, original.Name)
+	app.Status.AppState.State = v1beta1.SkippedState
+	app.Status.AttachedTo = original.Name
+	app.Status.TerminationTime = metav1.Now()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestIsOriginalOf(t *testing.T) {
+	now := time.Now()
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{
+		Name:              "foo-2",
+		CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+	}}
+	newOther := func(name string, state v1beta1.ApplicationStateType, created, terminated time.Time) *v1beta1.SparkApplication {
+		return &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState:        v1beta1.ApplicationState{State: state},
+				TerminationTime: metav1.NewTime(terminated),
+			},
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		other    *v1beta1.SparkApplication
+		original bool
+	}{
+		{"running", newOther("foo-1", v1beta1.RunningState, now.Add(-time.Hour), time.Time{}), true},
+		{"pending rerun", newOther("foo-1", v1beta1.PendingRerunState, now.Add(-time.Hour), time.Time{}), true},
+		{"recently completed", newOther("foo-1", v1beta1.CompletedState, now.Add(-time.Hour), now.Add(-10*time.Minute)), true},
+		{"completed long ago", newOther("foo-1", v1beta1.CompletedState, now.Add(-3*time.Hour), now.Add(-2*time.Hour)), false},
+		{"failed", newOther("foo-1", v1beta1.FailedState, now.Add(-time.Hour), now.Add(-10*time.Minute)), false},
+		{"skipped", newOther("foo-1", v1beta1.SkippedState, now.Add(-time.Hour), now.Add(-10*time.Minute)), false},
+		{"new and older", newOther("foo-3", v1beta1.NewState, now.Add(-time.Hour), time.Time{}), true},
+		{"new and younger", newOther("foo-1", v1beta1.NewState, now, time.Time{}), false},
+		{"new at the same time", newOther("foo-1", v1beta1.NewState, now.Add(-time.Minute), time.Time{}), true},
+	}
+	for _, test := range testcases {
+		assert.Equal(t, test.original, isOriginalOf(test.other, app, time.Hour, now), test.name)
+	}
+}
+
+func TestFindOriginalApplication(t *testing.T) {
+	key := "pipeline-run-42"
+	original := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-1", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{IdempotencyKey: &key},
+		Status:     v1beta1.SparkApplicationStatus{AppState: v1beta1.ApplicationState{State: v1beta1.RunningState}},
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-2", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{IdempotencyKey: &key},
+	}
+	ctrl, _ := newFakeController(original)
+
+	found, err := ctrl.findOriginalApplication(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, original, found)
+	skipDuplicateApplication(app, found)
+	assert.Equal(t, v1beta1.SkippedState, app.Status.AppState.State)
+	assert.Equal(t, "foo-1", app.Status.AttachedTo)
+	assert.False(t, app.Status.TerminationTime.IsZero())
+
+	// Applications with another key or in another namespace are not duplicates.
+	app.Spec.IdempotencyKey = stringptr("pipeline-run-43")
+	found, err = ctrl.findOriginalApplication(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, found)
+
+	app.Spec.IdempotencyKey = &key
+	app.Namespace = "other"
+	found, err = ctrl.findOriginalApplication(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, found)
+}
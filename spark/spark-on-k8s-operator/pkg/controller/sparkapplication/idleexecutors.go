@@ -0,0 +1,248 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	defaultIdleExecutorCPUThreshold = "50m"
+	defaultIdleExecutorMinExecutors = 1
+	// idleExecutorCheckInterval is about the resolution of the metrics server.
+	idleExecutorCheckInterval = 30 * time.Second
+)
+
+var fetchPodCPUUsage = getPodCPUUsage
+
+// podMetricsList is the subset of a metrics.k8s.io PodMetricsList the CPU usage of pods is read from.
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Usage apiv1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// getPodCPUUsage returns the CPU usage in millicores of the pods in the given namespace matching the given
+// selector, by pod name, as reported by the metrics server.
+func getPodCPUUsage(kubeClient clientset.Interface, namespace string, selector labels.Selector) (map[string]int64, error) {
+	raw, err := kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").
+		Param("labelSelector", selector.String()).
+		DoRaw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the metrics of pods in namespace %s: %v", namespace, err)
+	}
+	var list podMetricsList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse the metrics of pods in namespace %s: %v", namespace, err)
+	}
+	usage := make(map[string]int64)
+	for _, item := range list.Items {
+		for _, container := range item.Containers {
+			usage[item.Metadata.Name] += container.Usage.Cpu().MilliValue()
+		}
+	}
+	return usage, nil
+}
+
+// idleExecutorTracker remembers since when the executors of running applications have been idle.
+type idleExecutorTracker struct {
+	mutex sync.Mutex
+	apps  map[string]*idleExecutors
+}
+
+type idleExecutors struct {
+	lastCheck time.Time
+	// since is the time each idle executor was first seen idle, by executor pod name.
+	since map[string]time.Time
+}
+
+func newIdleExecutorTracker() *idleExecutorTracker {
+	return &idleExecutorTracker{apps: make(map[string]*idleExecutors)}
+}
+
+// due tells if the executors of the application with the given key are to be checked again.
+func (t *idleExecutorTracker) due(key string, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	app, ok := t.apps[key]
+	return !ok || now.Sub(app.lastCheck) >= idleExecutorCheckInterval
+}
+
+// observe records which executors of the application with the given key are idle, given their CPU usage, and
+// returns since when each of them has been idle. Executors not in usage are forgotten.
+func (t *idleExecutorTracker) observe(key string, usage map[string]int64, threshold int64, now time.Time) map[string]time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	app, ok := t.apps[key]
+	if !ok {
+		app = &idleExecutors{since: make(map[string]time.Time)}
+		t.apps[key] = app
+	}
+	app.lastCheck = now
+	idle := make(map[string]time.Time)
+	for name, millis := range usage {
+		if millis >= threshold {
+			continue
+		}
+		since, ok := app.since[name]
+		if !ok {
+			since = now
+		}
+		idle[name] = since
+	}
+	app.since = idle
+
+	result := make(map[string]time.Time, len(idle))
+	for name, since := range idle {
+		result[name] = since
+	}
+	return result
+}
+
+// forget forgets the executors of the application with the given key.
+func (t *idleExecutorTracker) forget(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.apps, key)
+}
+
+func getIdleExecutorCPUThreshold(spec *v1beta1.ExecutorIdleTimeoutSpec) (int64, error) {
+	threshold := defaultIdleExecutorCPUThreshold
+	if spec.CPUThreshold != nil {
+		threshold = *spec.CPUThreshold
+	}
+	quantity, err := resource.ParseQuantity(threshold)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpuThreshold %q: %v", threshold, err)
+	}
+	return quantity.MilliValue(), nil
+}
+
+func getIdleExecutorMinExecutors(spec *v1beta1.ExecutorIdleTimeoutSpec) int {
+	if spec.MinExecutors != nil && *spec.MinExecutors >= 0 {
+		return int(*spec.MinExecutors)
+	}
+	return defaultIdleExecutorMinExecutors
+}
+
+// getIdleExecutorsToDelete returns the names of the executors idle since before the given deadline that are
+// deleted, longest idle first, keeping at least minExecutors of the given running executors.
+func getIdleExecutorsToDelete(idleSince map[string]time.Time, running []string, deadline time.Time, minExecutors int) []string {
+	var expired []string
+	for _, name := range running {
+		if since, ok := idleSince[name]; ok && !since.After(deadline) {
+			expired = append(expired, name)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		a, b := idleSince[expired[i]], idleSince[expired[j]]
+		if a.Equal(b) {
+			return expired[i] < expired[j]
+		}
+		return a.Before(b)
+	})
+	if removable := len(running) - minExecutors; len(expired) > removable {
+		if removable < 0 {
+			removable = 0
+		}
+		expired = expired[:removable]
+	}
+	return expired
+}
+
+// reclaimIdleExecutors deletes the executors of the given running application that have been idle for longer
+// than its idle timeout. Each deleted executor lowers the number of executors the webhook lets Spark run for
+// the rest of the run, so that Spark does not replace it.
+func (c *Controller) reclaimIdleExecutors(key string, app *v1beta1.SparkApplication) {
+	spec := app.Spec.ExecutorIdleTimeout
+	if spec == nil || app.Status.AppState.State != v1beta1.RunningState || util.IsDynamicAllocationEnabled(app) {
+		c.idleExecutors.forget(key)
+		return
+	}
+	now := time.Now()
+	if !c.idleExecutors.due(key, now) {
+		return
+	}
+	defer c.queue.AddAfter(key, idleExecutorCheckInterval)
+
+	threshold, err := getIdleExecutorCPUThreshold(spec)
+	if err != nil {
+		glog.Errorf("failed to check for idle executors of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+	selector := labels.SelectorFromSet(labels.Set{
+		config.SparkAppNameLabel: app.Name,
+		config.SparkRoleLabel:    config.SparkExecutorRole,
+	})
+	usage, err := fetchPodCPUUsage(c.kubeClient, app.Namespace, selector)
+	if err != nil {
+		glog.Warningf("failed to check for idle executors of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+
+	pods, err := c.podLister.Pods(app.Namespace).List(selector)
+	if err != nil {
+		glog.Errorf("failed to get the executors of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+	// Executors being deleted already are neither counted nor deleted again.
+	var running []string
+	for _, pod := range pods {
+		if pod.Status.Phase == apiv1.PodRunning && pod.DeletionTimestamp == nil {
+			running = append(running, pod.Name)
+		}
+	}
+	idleSince := c.idleExecutors.observe(key, usage, threshold, now)
+	deadline := now.Add(-time.Duration(spec.TimeoutSeconds) * time.Second)
+	for _, name := range getIdleExecutorsToDelete(idleSince, running, deadline, getIdleExecutorMinExecutors(spec)) {
+		err := c.kubeClient.CoreV1().Pods(app.Namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			glog.Errorf("failed to delete idle executor pod %s/%s: %v", app.Namespace, name, err)
+			continue
+		}
+		glog.Infof("Deleted executor pod %s/%s of SparkApplication %s, which has been idle since %v", app.Namespace,
+			name, app.Name, idleSince[name])
+		app.Status.ReclaimedExecutors++
+		c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkExecutorIdleTimeout",
+			"Executor %s deleted after being idle since %v", name, idleSince[name].Format(time.RFC3339))
+	}
+}
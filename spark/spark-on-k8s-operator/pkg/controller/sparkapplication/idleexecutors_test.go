@@ -0,0 +1,127 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestIdleExecutorTracker(t *testing.T) {
+	tracker := newIdleExecutorTracker()
+	start := time.Now()
+	assert.True(t, tracker.due("default/foo", start))
+
+	idle := tracker.observe("default/foo", map[string]int64{"exec-1": 10, "exec-2": 900}, 50, start)
+	assert.Equal(t, map[string]time.Time{"exec-1": start}, idle)
+	assert.False(t, tracker.due("default/foo", start.Add(time.Second)))
+	assert.True(t, tracker.due("default/foo", start.Add(idleExecutorCheckInterval)))
+
+	// Executors stay idle since they were first seen idle, until they are busy again.
+	later := start.Add(time.Minute)
+	idle = tracker.observe("default/foo", map[string]int64{"exec-1": 0, "exec-2": 0}, 50, later)
+	assert.Equal(t, map[string]time.Time{"exec-1": start, "exec-2": later}, idle)
+	idle = tracker.observe("default/foo", map[string]int64{"exec-1": 100, "exec-2": 0}, 50, later.Add(time.Minute))
+	assert.Equal(t, map[string]time.Time{"exec-2": later}, idle)
+
+	tracker.forget("default/foo")
+	assert.True(t, tracker.due("default/foo", later))
+}
+
+func TestGetIdleExecutorsToDelete(t *testing.T) {
+	now := time.Now()
+	idleSince := map[string]time.Time{
+		"exec-1": now.Add(-10 * time.Minute),
+		"exec-2": now.Add(-20 * time.Minute),
+		"exec-3": now.Add(-time.Minute),
+		"exec-4": now.Add(-30 * time.Minute),
+	}
+	running := []string{"exec-1", "exec-2", "exec-3"}
+	deadline := now.Add(-5 * time.Minute)
+
+	// exec-3 has not been idle long enough and exec-4 is not running anymore.
+	assert.Equal(t, []string{"exec-2", "exec-1"}, getIdleExecutorsToDelete(idleSince, running, deadline, 1))
+	assert.Equal(t, []string{"exec-2"}, getIdleExecutorsToDelete(idleSince, running, deadline, 2))
+	assert.Empty(t, getIdleExecutorsToDelete(idleSince, running, deadline, 3))
+	assert.Empty(t, getIdleExecutorsToDelete(idleSince, running, deadline, 5))
+}
+
+func TestReclaimIdleExecutors(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			ExecutorIdleTimeout: &v1beta1.ExecutorIdleTimeoutSpec{TimeoutSeconds: 300},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+		},
+	}
+	var pods []*apiv1.Pod
+	for _, name := range []string{"foo-exec-1", "foo-exec-2", "foo-exec-3"} {
+		pods = append(pods, &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					config.SparkAppNameLabel: "foo",
+					config.SparkRoleLabel:    config.SparkExecutorRole,
+				},
+			},
+			Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+		})
+	}
+	ctrl, _ := newFakeController(app, pods...)
+	for _, pod := range pods {
+		ctrl.kubeClient.CoreV1().Pods(pod.Namespace).Create(pod)
+	}
+
+	usage := map[string]int64{"foo-exec-1": 5, "foo-exec-2": 10, "foo-exec-3": 1200}
+	defer func() { fetchPodCPUUsage = getPodCPUUsage }()
+	fetchPodCPUUsage = func(kubeClient clientset.Interface, namespace string, selector labels.Selector) (map[string]int64, error) {
+		return usage, nil
+	}
+
+	// 1. Executors that only just became idle are kept.
+	ctrl.reclaimIdleExecutors("default/foo", app)
+	assert.Equal(t, int32(0), app.Status.ReclaimedExecutors)
+
+	// 2. Executors idle for longer than the timeout are deleted. The tracker keeps when executors were first seen
+	// idle, so it is reset to have foo-exec-1 seen idle ten minutes ago.
+	ctrl.idleExecutors.forget("default/foo")
+	ctrl.idleExecutors.observe("default/foo", map[string]int64{"foo-exec-1": 0}, 50, time.Now().Add(-10*time.Minute))
+	ctrl.reclaimIdleExecutors("default/foo", app)
+	assert.Equal(t, int32(1), app.Status.ReclaimedExecutors)
+	_, err := ctrl.kubeClient.CoreV1().Pods("default").Get("foo-exec-1", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = ctrl.kubeClient.CoreV1().Pods("default").Get("foo-exec-2", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// 3. Applications using dynamic allocation are left alone.
+	app.Spec.SparkConf = map[string]string{config.SparkDynamicAllocationEnabledKey: "true"}
+	ctrl.idleExecutors.observe("default/foo", map[string]int64{"foo-exec-2": 0}, 50, time.Now().Add(-10*time.Minute))
+	ctrl.reclaimIdleExecutors("default/foo", app)
+	assert.Equal(t, int32(1), app.Status.ReclaimedExecutors)
+}
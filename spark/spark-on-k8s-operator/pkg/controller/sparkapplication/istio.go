@@ -0,0 +1,83 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	sparkDriverContainerName = "spark-kubernetes-driver"
+	// istioProxyQuitTimeout bounds the requests stopping Istio sidecar proxies, which are sent while processing
+	// pod updates.
+	istioProxyQuitTimeout = 5 * time.Second
+)
+
+var httpPost = (&http.Client{Timeout: istioProxyQuitTimeout}).Post
+
+// shouldQuitIstioProxy tells if the Istio sidecar proxy of the given driver pod is still running although
+// the Spark driver container has terminated, which would keep the pod from ever completing.
+func shouldQuitIstioProxy(pod *apiv1.Pod) bool {
+	if pod.Status.Phase != apiv1.PodRunning || pod.Status.PodIP == "" {
+		return false
+	}
+
+	sparkContainerTerminated := false
+	proxyRunning := false
+	for _, status := range pod.Status.ContainerStatuses {
+		switch status.Name {
+		case sparkDriverContainerName:
+			sparkContainerTerminated = status.State.Terminated != nil
+		case config.IstioProxyContainerName:
+			proxyRunning = status.State.Running != nil
+		}
+	}
+	return sparkContainerTerminated && proxyRunning
+}
+
+// quitIstioProxy asks the Istio sidecar proxy of the given pod to exit.
+func quitIstioProxy(pod *apiv1.Pod) error {
+	url := fmt.Sprintf(config.IstioProxyQuitURLFormat, pod.Status.PodIP)
+	glog.Infof("Spark container of pod %s/%s has terminated, stopping the Istio sidecar proxy", pod.Namespace, pod.Name)
+	resp, err := httpPost(url, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to stop the Istio sidecar proxy of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	// Recent versions of pilot-agent only accept requests to stop the proxy from within the pod.
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to stop the Istio sidecar proxy of pod %s/%s: the proxy only accepts requests "+
+			"from within the pod, run it as a native sidecar or stop it from the driver container instead",
+			pod.Namespace, pod.Name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to stop the Istio sidecar proxy of pod %s/%s: got status %s",
+			pod.Namespace, pod.Name, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newIstioDriverPod(sparkState, proxyState apiv1.ContainerState) *apiv1.Pod {
+	return &apiv1.Pod{
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			PodIP: "10.0.0.1",
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: sparkDriverContainerName, State: sparkState},
+				{Name: config.IstioProxyContainerName, State: proxyState},
+			},
+		},
+	}
+}
+
+func TestShouldQuitIstioProxy(t *testing.T) {
+	running := apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}}
+	terminated := apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{}}
+
+	assert.True(t, shouldQuitIstioProxy(newIstioDriverPod(terminated, running)))
+	assert.False(t, shouldQuitIstioProxy(newIstioDriverPod(running, running)))
+	assert.False(t, shouldQuitIstioProxy(newIstioDriverPod(terminated, terminated)))
+
+	pod := newIstioDriverPod(terminated, running)
+	pod.Status.Phase = apiv1.PodSucceeded
+	assert.False(t, shouldQuitIstioProxy(pod))
+}
+
+func TestQuitIstioProxy(t *testing.T) {
+	var postedURL string
+	status := http.StatusOK
+	defer func(post func(string, string, io.Reader) (*http.Response, error)) { httpPost = post }(httpPost)
+	httpPost = func(url, contentType string, body io.Reader) (*http.Response, error) {
+		postedURL = url
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	terminated := apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{}}
+	running := apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}}
+	assert.Nil(t, quitIstioProxy(newIstioDriverPod(terminated, running)))
+	assert.Equal(t, "http://10.0.0.1:15020/quitquitquit", postedURL)
+
+	// Proxies refusing requests from outside the pod cannot be stopped.
+	status = http.StatusForbidden
+	err := quitIstioProxy(newIstioDriverPod(terminated, running))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "only accepts requests from within the pod")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// recordLaunchLatency records in the status of the given application when the driver and the first executor
+// of its current run were first seen running, as of the given time. The latency from the creation of the
+// SparkApplication is only recorded for its first run, as later runs are not started by its creation.
+func recordLaunchLatency(app *v1beta1.SparkApplication, now metav1.Time) {
+	latency := app.Status.LaunchLatency
+	if latency == nil {
+		if app.Status.AppState.State != v1beta1.RunningState {
+			return
+		}
+		latency = &v1beta1.LaunchLatency{DriverRunningTime: now}
+		if app.Status.ExecutionAttempts <= 1 && !app.CreationTimestamp.IsZero() {
+			latency.CreationToDriverRunningMillis = toMillis(now.Sub(app.CreationTimestamp.Time))
+		}
+		app.Status.LaunchLatency = latency
+	}
+
+	if !latency.FirstExecutorRunningTime.IsZero() {
+		return
+	}
+	for _, state := range app.Status.ExecutorState {
+		if state == v1beta1.ExecutorRunningState {
+			latency.FirstExecutorRunningTime = now
+			latency.DriverToFirstExecutorRunningMillis = toMillis(now.Sub(latency.DriverRunningTime.Time))
+			return
+		}
+	}
+}
+
+func toMillis(d time.Duration) *int64 {
+	if d < 0 {
+		d = 0
+	}
+	millis := int64(d / time.Millisecond)
+	return &millis
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestRecordLaunchLatency(t *testing.T) {
+	created := time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", CreationTimestamp: metav1.NewTime(created)},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:          v1beta1.ApplicationState{State: v1beta1.SubmittedState},
+			ExecutionAttempts: 1,
+		},
+	}
+
+	// Nothing is recorded until the driver is running.
+	recordLaunchLatency(app, metav1.NewTime(created.Add(5*time.Second)))
+	assert.Nil(t, app.Status.LaunchLatency)
+
+	app.Status.AppState.State = v1beta1.RunningState
+	app.Status.ExecutorState = map[string]v1beta1.ExecutorState{"exec-1": v1beta1.ExecutorPendingState}
+	recordLaunchLatency(app, metav1.NewTime(created.Add(12*time.Second)))
+	latency := app.Status.LaunchLatency
+	assert.Equal(t, int64(12000), *latency.CreationToDriverRunningMillis)
+	assert.Nil(t, latency.DriverToFirstExecutorRunningMillis)
+
+	app.Status.ExecutorState["exec-1"] = v1beta1.ExecutorRunningState
+	recordLaunchLatency(app, metav1.NewTime(created.Add(20*time.Second)))
+	assert.Equal(t, int64(8000), *latency.DriverToFirstExecutorRunningMillis)
+	assert.Equal(t, metav1.NewTime(created.Add(20*time.Second)), latency.FirstExecutorRunningTime)
+
+	// Later syncs do not change the recorded latencies.
+	recordLaunchLatency(app, metav1.NewTime(created.Add(60*time.Second)))
+	assert.Equal(t, int64(12000), *latency.CreationToDriverRunningMillis)
+	assert.Equal(t, int64(8000), *latency.DriverToFirstExecutorRunningMillis)
+
+	// Reruns are not started by the creation of the application.
+	app.Status = v1beta1.SparkApplicationStatus{
+		AppState:          v1beta1.ApplicationState{State: v1beta1.RunningState},
+		ExecutionAttempts: 2,
+	}
+	recordLaunchLatency(app, metav1.NewTime(created.Add(time.Hour)))
+	assert.Nil(t, app.Status.LaunchLatency.CreationToDriverRunningMillis)
+	assert.Equal(t, metav1.NewTime(created.Add(time.Hour)), app.Status.LaunchLatency.DriverRunningTime)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	defaultMemoryBumpFactor = 1.5
+	// The memory is increased up to this factor of the memory in the spec by default.
+	defaultMaxMemoryBumpFactor = 4
+)
+
+// recordOutOfMemoryRoles records the roles of the given driver and executor pods of the current run of the given
+// application that ran out of memory in its status, if its RestartPolicy bumps memory.
+func recordOutOfMemoryRoles(app *v1beta1.SparkApplication, pods []*apiv1.Pod) {
+	if app.Spec.RestartPolicy.MemoryBump == nil {
+		return
+	}
+	for _, pod := range pods {
+		if !isOutOfMemory(pod) {
+			continue
+		}
+		role := config.SparkExecutorRole
+		if util.IsDriverPod(pod) {
+			role = config.SparkDriverRole
+		}
+		if app.Status.MemoryBump == nil {
+			app.Status.MemoryBump = &v1beta1.MemoryBumpStatus{}
+		}
+		recorded := false
+		for _, r := range app.Status.MemoryBump.OutOfMemory {
+			recorded = recorded || r == role
+		}
+		if !recorded {
+			app.Status.MemoryBump.OutOfMemory = append(app.Status.MemoryBump.OutOfMemory, role)
+		}
+	}
+}
+
+// isOutOfMemoryFailure tells if pods of the failed run of the given application ran out of memory, and its
+// RestartPolicy bumps memory.
+func isOutOfMemoryFailure(app *v1beta1.SparkApplication) bool {
+	return app.Spec.RestartPolicy.MemoryBump != nil && app.Status.MemoryBump != nil &&
+		len(app.Status.MemoryBump.OutOfMemory) > 0
+}
+
+// bumpMemory increases the memory of the driver or executors of the given application whose pods ran out of
+// memory in the failed run for the next run, and records the increase in its status. It tells if the memory was
+// increased, which it is not once it is at its upper bound.
+func bumpMemory(app *v1beta1.SparkApplication) (bool, error) {
+	if !isOutOfMemoryFailure(app) {
+		return false, nil
+	}
+	policy := app.Spec.RestartPolicy.MemoryBump
+	status := app.Status.MemoryBump
+	factor := defaultMemoryBumpFactor
+	if policy.Factor != nil {
+		value, err := strconv.ParseFloat(*policy.Factor, 64)
+		if err != nil || value <= 1 {
+			return false, fmt.Errorf("invalid memory bump factor %q", *policy.Factor)
+		}
+		factor = value
+	}
+
+	bumped := false
+	for _, role := range status.OutOfMemory {
+		var ok bool
+		var err error
+		switch role {
+		case config.SparkDriverRole:
+			ok, err = bumpPodMemory(&app.Spec.Driver.SparkPodSpec, policy.MaxDriverMemory, factor,
+				&status.DriverMemory, &status.DriverMemoryOverhead)
+		case config.SparkExecutorRole:
+			ok, err = bumpPodMemory(&app.Spec.Executor.SparkPodSpec, policy.MaxExecutorMemory, factor,
+				&status.ExecutorMemory, &status.ExecutorMemoryOverhead)
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to bump the %s memory: %v", role, err)
+		}
+		bumped = bumped || ok
+	}
+	status.OutOfMemory = nil
+	if bumped {
+		status.Bumps++
+		status.LastBumpTime = metav1.Now()
+	}
+	return bumped, nil
+}
+
+// bumpPodMemory multiplies the memory of the driver or executors with the given spec by the given factor, up to
+// the given upper bound, starting from the given memory if it was increased before. A memory overhead set in the
+// spec is increased in proportion. It tells if the memory was increased.
+func bumpPodMemory(spec *v1beta1.SparkPodSpec, max *string, factor float64, memory, memoryOverhead **string) (bool,
+	error) {
+	specMiB := int64(defaultDriverMemoryMiB)
+	if spec.Memory != nil {
+		value, err := parseJVMMemoryMiB(*spec.Memory)
+		if err != nil {
+			return false, fmt.Errorf("invalid memory %q: %v", *spec.Memory, err)
+		}
+		specMiB = value
+	}
+	currentMiB := specMiB
+	if *memory != nil {
+		value, err := parseJVMMemoryMiB(**memory)
+		if err != nil {
+			return false, fmt.Errorf("invalid memory %q: %v", **memory, err)
+		}
+		currentMiB = value
+	}
+	maxMiB := specMiB * defaultMaxMemoryBumpFactor
+	if max != nil {
+		value, err := parseJVMMemoryMiB(*max)
+		if err != nil {
+			return false, fmt.Errorf("invalid maximum memory %q: %v", *max, err)
+		}
+		maxMiB = value
+	}
+
+	newMiB := int64(math.Ceil(float64(currentMiB) * factor))
+	if newMiB > maxMiB {
+		newMiB = maxMiB
+	}
+	if newMiB <= currentMiB {
+		return false, nil
+	}
+	newMemory := fmt.Sprintf("%dm", newMiB)
+	*memory = &newMemory
+	// The memory overhead defaults to a factor of the memory, which grows with it.
+	if spec.MemoryOverhead != nil && specMiB > 0 {
+		overheadMiB, err := parseJVMMemoryMiB(*spec.MemoryOverhead)
+		if err != nil {
+			return false, fmt.Errorf("invalid memory overhead %q: %v", *spec.MemoryOverhead, err)
+		}
+		newOverhead := fmt.Sprintf("%dm", int64(math.Ceil(float64(overheadMiB)*float64(newMiB)/float64(specMiB))))
+		*memoryOverhead = &newOverhead
+	}
+	return true, nil
+}
+
+// applyMemoryBump sets the memory of the driver and executors of the given application to the memory they were
+// increased to after running out of memory in previous runs.
+func applyMemoryBump(app *v1beta1.SparkApplication) {
+	status := app.Status.MemoryBump
+	if app.Spec.RestartPolicy.MemoryBump == nil || status == nil {
+		return
+	}
+	if status.DriverMemory != nil {
+		app.Spec.Driver.Memory = status.DriverMemory
+	}
+	if status.DriverMemoryOverhead != nil {
+		app.Spec.Driver.MemoryOverhead = status.DriverMemoryOverhead
+	}
+	if status.ExecutorMemory != nil {
+		app.Spec.Executor.Memory = status.ExecutorMemory
+	}
+	if status.ExecutorMemoryOverhead != nil {
+		app.Spec.Executor.MemoryOverhead = status.ExecutorMemoryOverhead
+	}
+}
+
+// bumpMemoryForRetry increases the memory of the driver or executors of the given application that failed after
+// running out of memory before it is retried.
+func (c *Controller) bumpMemoryForRetry(app *v1beta1.SparkApplication) {
+	bumped, err := bumpMemory(app)
+	if err != nil {
+		glog.Errorf("failed to bump the memory of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+	if !bumped {
+		return
+	}
+	status := app.Status.MemoryBump
+	glog.Infof("Bumped the memory of SparkApplication %s/%s to driver %s and executors %s", app.Namespace,
+		app.Name, memoryOrUnchanged(status.DriverMemory), memoryOrUnchanged(status.ExecutorMemory))
+	c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkApplicationMemoryBumped",
+		"SparkApplication %s ran out of memory, retrying with driver memory %s and executor memory %s", app.Name,
+		memoryOrUnchanged(status.DriverMemory), memoryOrUnchanged(status.ExecutorMemory))
+}
+
+// memoryOrUnchanged returns the given increased memory, or "unchanged" if it was not increased.
+func memoryOrUnchanged(value *string) string {
+	if value == nil {
+		return "unchanged"
+	}
+	return *value
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestRecordOutOfMemoryRoles(t *testing.T) {
+	newPod := func(name, role, container string, exitCode int32) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{config.SparkRoleLabel: role}},
+			Status: apiv1.PodStatus{ContainerStatuses: []apiv1.ContainerStatus{{
+				Name:  container,
+				State: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: exitCode}},
+			}}},
+		}
+	}
+	pods := []*apiv1.Pod{
+		newPod("foo-driver", config.SparkDriverRole, sparkDriverContainerName, 1),
+		newPod("foo-exec-1", config.SparkExecutorRole, sparkExecutorContainerName, 52),
+		newPod("foo-exec-2", config.SparkExecutorRole, sparkExecutorContainerName, 52),
+	}
+
+	app := &v1beta1.SparkApplication{}
+	recordOutOfMemoryRoles(app, pods)
+	assert.Nil(t, app.Status.MemoryBump)
+
+	app.Spec.RestartPolicy.MemoryBump = &v1beta1.MemoryBumpPolicy{}
+	recordOutOfMemoryRoles(app, pods)
+	assert.Equal(t, []string{config.SparkExecutorRole}, app.Status.MemoryBump.OutOfMemory)
+	assert.True(t, isOutOfMemoryFailure(app))
+}
+
+func TestBumpMemory(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{MemoryBump: &v1beta1.MemoryBumpPolicy{
+				MaxExecutorMemory: stringptr("4g"),
+			}},
+			Driver: v1beta1.DriverSpec{SparkPodSpec: v1beta1.SparkPodSpec{Memory: stringptr("512m")}},
+			Executor: v1beta1.ExecutorSpec{SparkPodSpec: v1beta1.SparkPodSpec{
+				Memory:         stringptr("2g"),
+				MemoryOverhead: stringptr("512m"),
+			}},
+		},
+		Status: v1beta1.SparkApplicationStatus{MemoryBump: &v1beta1.MemoryBumpStatus{
+			OutOfMemory: []string{config.SparkExecutorRole},
+		}},
+	}
+
+	bumped, err := bumpMemory(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bumped)
+	status := app.Status.MemoryBump
+	assert.Equal(t, int32(1), status.Bumps)
+	assert.False(t, status.LastBumpTime.IsZero())
+	assert.Nil(t, status.DriverMemory)
+	assert.Equal(t, "3072m", *status.ExecutorMemory)
+	assert.Equal(t, "768m", *status.ExecutorMemoryOverhead)
+	assert.Nil(t, status.OutOfMemory)
+
+	// The memory is not increased beyond the upper bound.
+	status.OutOfMemory = []string{config.SparkExecutorRole, config.SparkDriverRole}
+	bumped, err = bumpMemory(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bumped)
+	assert.Equal(t, int32(2), status.Bumps)
+	assert.Equal(t, "4096m", *status.ExecutorMemory)
+	assert.Equal(t, "1024m", *status.ExecutorMemoryOverhead)
+	assert.Equal(t, "768m", *status.DriverMemory)
+	assert.Nil(t, status.DriverMemoryOverhead)
+
+	status.OutOfMemory = []string{config.SparkExecutorRole}
+	bumped, err = bumpMemory(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, bumped)
+	assert.Equal(t, int32(2), status.Bumps)
+
+	// The next run is submitted with the increased memory.
+	applyMemoryBump(app)
+	assert.Equal(t, "768m", *app.Spec.Driver.Memory)
+	assert.Equal(t, "4096m", *app.Spec.Executor.Memory)
+	assert.Equal(t, "1024m", *app.Spec.Executor.MemoryOverhead)
+
+	app.Spec.RestartPolicy.MemoryBump.Factor = stringptr("0.5")
+	status.OutOfMemory = []string{config.SparkDriverRole}
+	_, err = bumpMemory(app)
+	assert.NotNil(t, err)
+}
+
+func TestShouldRetryOutOfMemory(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{
+				Type:             v1beta1.OnFailure,
+				OnFailureRetries: int32ptr(2),
+				RetryableErrors:  []string{"reason Evicted"},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{
+				State:        v1beta1.FailingState,
+				ErrorMessage: "driver container terminated with exit code 1",
+			},
+			ExecutionAttempts: 1,
+			MemoryBump:        &v1beta1.MemoryBumpStatus{OutOfMemory: []string{config.SparkExecutorRole}},
+		},
+	}
+	assert.False(t, shouldRetry(app))
+
+	app.Spec.RestartPolicy.MemoryBump = &v1beta1.MemoryBumpPolicy{}
+	assert.True(t, shouldRetry(app))
+
+	app.Status.ExecutionAttempts = 3
+	assert.False(t, shouldRetry(app))
+}
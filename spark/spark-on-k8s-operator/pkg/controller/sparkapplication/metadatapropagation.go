@@ -0,0 +1,69 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// propagatedMetadata holds the labels and annotations of an application that are copied to the resources the
+// operator creates for it.
+type propagatedMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// apply adds the propagated labels and annotations to the given object metadata. Labels and annotations the
+// object already has are kept, so the ones the operator relies on, e.g., the app name label, cannot be replaced.
+func (m propagatedMetadata) apply(objectMeta *metav1.ObjectMeta) {
+	objectMeta.Labels = addMissingEntries(objectMeta.Labels, m.labels)
+	objectMeta.Annotations = addMissingEntries(objectMeta.Annotations, m.annotations)
+}
+
+func addMissingEntries(existing, entries map[string]string) map[string]string {
+	for key, value := range entries {
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		if existing == nil {
+			existing = make(map[string]string)
+		}
+		existing[key] = value
+	}
+	return existing
+}
+
+// SetPropagatedMetadata sets the keys of the labels and annotations of every application that are copied to the
+// resources created for applications submitted from then on.
+func (c *Controller) SetPropagatedMetadata(labels []string, annotations []string) {
+	c.defaultsMutex.Lock()
+	defer c.defaultsMutex.Unlock()
+	c.propagatedLabels = labels
+	c.propagatedAnnotations = annotations
+}
+
+// getPropagatedMetadata returns the labels and annotations of the given application to copy to the resources
+// created for it.
+func (c *Controller) getPropagatedMetadata(app *v1beta1.SparkApplication) propagatedMetadata {
+	c.defaultsMutex.RLock()
+	defer c.defaultsMutex.RUnlock()
+	labels, annotations := util.GetPropagatedMetadata(app, c.propagatedLabels, c.propagatedAnnotations)
+	return propagatedMetadata{labels: labels, annotations: annotations}
+}
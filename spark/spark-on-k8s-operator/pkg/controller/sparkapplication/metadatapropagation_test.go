@@ -0,0 +1,69 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestPropagatedMetadataApply(t *testing.T) {
+	metadata := propagatedMetadata{
+		labels:      map[string]string{"cost-center": "ads", config.SparkAppNameLabel: "bar"},
+		annotations: map[string]string{"data-classification": "pii"},
+	}
+	objectMeta := metav1.ObjectMeta{Labels: map[string]string{config.SparkAppNameLabel: "foo"}}
+	metadata.apply(&objectMeta)
+	assert.Equal(t, map[string]string{config.SparkAppNameLabel: "foo", "cost-center": "ads"}, objectMeta.Labels)
+	assert.Equal(t, map[string]string{"data-classification": "pii"}, objectMeta.Annotations)
+
+	objectMeta = metav1.ObjectMeta{}
+	propagatedMetadata{}.apply(&objectMeta)
+	assert.Nil(t, objectMeta.Labels)
+	assert.Nil(t, objectMeta.Annotations)
+}
+
+func TestPropagateMetadataToConfigMaps(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			UID:         "foo-123",
+			Labels:      map[string]string{"cost-center": "ads", "team": "x"},
+			Annotations: map[string]string{"data-classification": "pii"},
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			MetadataPropagation: &v1beta1.MetadataPropagationSpec{Labels: []string{"team"}},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	ctrl.SetPropagatedMetadata([]string{"cost-center"}, []string{"data-classification"})
+
+	assert.Nil(t, ctrl.setStartGate(app, false))
+	configMap, err := ctrl.kubeClient.CoreV1().ConfigMaps("default").Get("foo-start-gate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{config.SparkAppNameLabel: "foo", "cost-center": "ads", "team": "x"},
+		configMap.Labels)
+	assert.Equal(t, map[string]string{"data-classification": "pii"}, configMap.Annotations)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// addMLModeConfOptions returns the Spark configuration properties running the executors of the given
+// distributed training job as the slots of a barrier stage, i.e., one task per executor that gets all its cores
+// and GPUs. Properties the application sets itself are kept, except for the GPUs of the executors.
+func addMLModeConfOptions(app *v1beta1.SparkApplication) ([]string, error) {
+	mlMode := app.Spec.MLMode
+	if mlMode == nil {
+		return nil, nil
+	}
+	if len(app.Spec.ExecutorGroups) > 0 || len(app.Spec.ResourceProfiles) > 0 {
+		return nil, fmt.Errorf("mlMode does not support executor groups and resource profiles")
+	}
+	// All the tasks of a barrier stage must run at once, which dynamic allocation does not guarantee.
+	if util.IsDynamicAllocationEnabled(app) {
+		return nil, fmt.Errorf("mlMode does not support dynamic allocation")
+	}
+
+	var options []string
+	addDefault := func(key, value string) {
+		if _, ok := app.Spec.SparkConf[key]; !ok {
+			options = append(options, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	addDefault(config.SparkDynamicAllocationEnabledKey, "false")
+	// Wait for all the executors to register rather than failing the barrier stage until they have.
+	addDefault(config.SparkMinRegisteredResourcesRatioKey, "1.0")
+	if app.Spec.Executor.Cores != nil {
+		addDefault(config.SparkTaskCPUsKey, fmt.Sprintf("%d", int32(*app.Spec.Executor.Cores)))
+	}
+	if mlMode.GPU != nil {
+		options = append(options,
+			getExecutorResourceConfOptions(config.SparkExecutorResourceKeyPrefix, nil, nil, nil, mlMode.GPU)...)
+		addDefault(config.SparkExecutorGPUDiscoveryScriptKey, config.DefaultGPUDiscoveryScript)
+		addDefault(config.SparkTaskGPUAmountKey, fmt.Sprintf("%d", mlMode.GPU.Quantity))
+	}
+	return options, nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestAddMLModeConfOptions(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+	options, err := addMLModeConfOptions(app)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(options))
+
+	cores := float32(8)
+	app.Spec.Executor.Cores = &cores
+	app.Spec.MLMode = &v1beta1.MLModeSpec{GPU: &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 4}}
+	app.Spec.SparkConf = map[string]string{"spark.task.cpus": "2"}
+	options, err = addMLModeConfOptions(app)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"spark.dynamicAllocation.enabled=false",
+		"spark.scheduler.minRegisteredResourcesRatio=1.0",
+		"spark.executor.resource.gpu.amount=4",
+		"spark.executor.resource.gpu.vendor=nvidia.com",
+		"spark.executor.resource.gpu.discoveryScript=/opt/spark/examples/src/main/scripts/getGpusResources.sh",
+		"spark.task.resource.gpu.amount=4",
+	}, options)
+
+	app.Spec.SparkConf["spark.dynamicAllocation.enabled"] = "true"
+	_, err = addMLModeConfOptions(app)
+	assert.NotNil(t, err)
+
+	delete(app.Spec.SparkConf, "spark.dynamicAllocation.enabled")
+	app.Spec.ExecutorGroups = []v1beta1.ExecutorGroup{{Name: "highmem"}}
+	_, err = addMLModeConfOptions(app)
+	assert.NotNil(t, err)
+}
@@ -18,6 +18,8 @@ package sparkapplication
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/golang/glog"
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -27,19 +29,40 @@ import (
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 const (
 	metricsPropertiesKey          = "metrics.properties"
 	prometheusConfigKey           = "prometheus.yaml"
 	prometheusConfigMapNameSuffix = "prom-conf"
+	metricsConfigMapNameSuffix    = "metrics-conf"
 	prometheusConfigMapMountPath  = "/etc/metrics/conf"
 	prometheusScrapeAnnotation    = "prometheus.io/scrape"
 	prometheusPortAnnotation      = "prometheus.io/port"
 	prometheusPathAnnotation      = "prometheus.io/path"
+	defaultPrometheusServletPath  = "/metrics/prometheus"
 )
 
-func configPrometheusMonitoring(app *v1beta1.SparkApplication, kubeClient clientset.Interface) error {
+// configMonitoring configures the Spark metric system of the given application as specified in
+// .spec.monitoring. The given metadata is propagated to the ConfigMaps it creates.
+func configMonitoring(app *v1beta1.SparkApplication, metadata propagatedMetadata, kubeClient clientset.Interface) error {
+	if app.Spec.Monitoring.MetricsSink != nil {
+		if _, err := buildMetricsSinkProperties(app.Spec.Monitoring.MetricsSink); err != nil {
+			return fmt.Errorf("invalid metrics sink of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+	}
+	if app.Spec.Monitoring.Prometheus != nil {
+		// The metrics sink, if any, is added to the metrics.properties in the Prometheus ConfigMap.
+		return configPrometheusMonitoring(app, metadata, kubeClient)
+	}
+	if app.Spec.Monitoring.MetricsSink != nil {
+		return configMetricsSink(app, metadata, kubeClient)
+	}
+	return nil
+}
+
+func configPrometheusMonitoring(app *v1beta1.SparkApplication, metadata propagatedMetadata, kubeClient clientset.Interface) error {
 	port := config.DefaultPrometheusJavaAgentPort
 	if app.Spec.Monitoring.Prometheus.Port != nil {
 		port = *app.Spec.Monitoring.Prometheus.Port
@@ -56,26 +79,11 @@ func configPrometheusMonitoring(app *v1beta1.SparkApplication, kubeClient client
 			port, configFile)
 	} else {
 		glog.V(2).Infof("Using the default Prometheus configuration.")
-		prometheusConfigMapName := fmt.Sprintf("%s-%s", app.Name, prometheusConfigMapNameSuffix)
+		prometheusConfigMapName := util.BuildName(app.Name, prometheusConfigMapNameSuffix, util.DNS1123SubdomainMaxLength)
 		configMap := buildPrometheusConfigMap(app, prometheusConfigMapName)
-		retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			cm, err := kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(prometheusConfigMapName, metav1.GetOptions{})
-
-			if apiErrors.IsNotFound(err) {
-				_, createErr := kubeClient.CoreV1().ConfigMaps(app.Namespace).Create(configMap)
-				return createErr
-			}
-			if err != nil {
-				return err
-			}
-
-			cm.Data = configMap.Data
-			_, updateErr := kubeClient.CoreV1().ConfigMaps(app.Namespace).Update(cm)
-			return updateErr
-		})
-
-		if retryErr != nil {
-			return fmt.Errorf("failed to apply %s in namespace %s: %v", prometheusConfigMapName, app.Namespace, retryErr)
+		metadata.apply(&configMap.ObjectMeta)
+		if err := applyConfigMap(configMap, kubeClient); err != nil {
+			return err
 		}
 
 		javaOption = fmt.Sprintf("-javaagent:%s=%d:%s/%s", app.Spec.Monitoring.Prometheus.JmxExporterJar,
@@ -136,11 +144,142 @@ func configPrometheusMonitoring(app *v1beta1.SparkApplication, kubeClient client
 	return nil
 }
 
-func buildPrometheusConfigMap(app *v1beta1.SparkApplication, prometheusConfigMapName string) *corev1.ConfigMap {
+// configMetricsSink configures the Spark metric system to report metrics to the sink specified in
+// .spec.monitoring.metricsSink without the Prometheus JMX exporter.
+func configMetricsSink(app *v1beta1.SparkApplication, metadata propagatedMetadata, kubeClient clientset.Interface) error {
+	metricsConfigMapName := util.BuildName(app.Name, metricsConfigMapNameSuffix, util.DNS1123SubdomainMaxLength)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            metricsConfigMapName,
+			Namespace:       app.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*getOwnerReference(app)},
+		},
+		Data: map[string]string{
+			metricsPropertiesKey: getMetricsProperties(app),
+		},
+	}
+	metadata.apply(&configMap.ObjectMeta)
+	if err := applyConfigMap(configMap, kubeClient); err != nil {
+		return err
+	}
+
+	metricsConfigMap := v1beta1.NamePath{Name: metricsConfigMapName, Path: prometheusConfigMapMountPath}
+	if app.Spec.Monitoring.ExposeDriverMetrics {
+		app.Spec.Driver.ConfigMaps = append(app.Spec.Driver.ConfigMaps, metricsConfigMap)
+	}
+	if app.Spec.Monitoring.ExposeExecutorMetrics {
+		app.Spec.Executor.ConfigMaps = append(app.Spec.Executor.ConfigMaps, metricsConfigMap)
+	}
+
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	app.Spec.SparkConf["spark.metrics.namespace"] = fmt.Sprintf("%s.%s", app.Namespace, app.Name)
+	app.Spec.SparkConf["spark.metrics.conf"] = fmt.Sprintf("%s/%s", prometheusConfigMapMountPath, metricsPropertiesKey)
+
+	sink := app.Spec.Monitoring.MetricsSink
+	if sink.Type == v1beta1.PrometheusServletSink {
+		// The driver serves metrics of both the driver and, with spark.ui.prometheus.enabled, the executors
+		// through its UI.
+		if app.Spec.Monitoring.ExposeExecutorMetrics {
+			app.Spec.SparkConf["spark.ui.prometheus.enabled"] = "true"
+		}
+		if app.Spec.Monitoring.ExposeDriverMetrics || app.Spec.Monitoring.ExposeExecutorMetrics {
+			if app.Spec.Driver.Annotations == nil {
+				app.Spec.Driver.Annotations = make(map[string]string)
+			}
+			app.Spec.Driver.Annotations[prometheusScrapeAnnotation] = "true"
+			app.Spec.Driver.Annotations[prometheusPortAnnotation] = getUITargetPort(app)
+			app.Spec.Driver.Annotations[prometheusPathAnnotation] = getPrometheusServletPath(sink)
+		}
+	}
+
+	return nil
+}
+
+// applyConfigMap creates the given ConfigMap or updates its data if it already exists.
+func applyConfigMap(configMap *corev1.ConfigMap, kubeClient clientset.Interface) error {
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := kubeClient.CoreV1().ConfigMaps(configMap.Namespace).Get(configMap.Name, metav1.GetOptions{})
+
+		if apiErrors.IsNotFound(err) {
+			_, createErr := kubeClient.CoreV1().ConfigMaps(configMap.Namespace).Create(configMap)
+			return createErr
+		}
+		if err != nil {
+			return err
+		}
+
+		cm.Data = configMap.Data
+		_, updateErr := kubeClient.CoreV1().ConfigMaps(configMap.Namespace).Update(cm)
+		return updateErr
+	})
+
+	if retryErr != nil {
+		return fmt.Errorf("failed to apply %s in namespace %s: %v", configMap.Name, configMap.Namespace, retryErr)
+	}
+	return nil
+}
+
+// getMetricsProperties returns the content of the metrics.properties of the given application, including
+// the configuration of the metrics sink if there is one.
+func getMetricsProperties(app *v1beta1.SparkApplication) string {
 	metricsProperties := config.DefaultMetricsProperties
 	if app.Spec.Monitoring.MetricsProperties != nil {
 		metricsProperties = *app.Spec.Monitoring.MetricsProperties
 	}
+	if app.Spec.Monitoring.MetricsSink != nil {
+		// The sink has been validated by configMonitoring.
+		sinkProperties, _ := buildMetricsSinkProperties(app.Spec.Monitoring.MetricsSink)
+		metricsProperties = strings.TrimRight(metricsProperties, "\n") + "\n" + sinkProperties
+	}
+	return metricsProperties
+}
+
+// buildMetricsSinkProperties returns the metrics.properties lines configuring the given sink.
+func buildMetricsSinkProperties(sink *v1beta1.MetricsSinkSpec) (string, error) {
+	var name, class string
+	switch sink.Type {
+	case v1beta1.StatsDSink:
+		name, class = "statsd", "org.apache.spark.metrics.sink.StatsdSink"
+	case v1beta1.GraphiteSink:
+		if sink.Host == nil || sink.Port == nil {
+			return "", fmt.Errorf("host and port are required for the %s sink", sink.Type)
+		}
+		name, class = "graphite", "org.apache.spark.metrics.sink.GraphiteSink"
+	case v1beta1.PrometheusServletSink:
+		return fmt.Sprintf("*.sink.prometheusServlet.class=org.apache.spark.metrics.sink.PrometheusServlet\n"+
+			"*.sink.prometheusServlet.path=%s\n", getPrometheusServletPath(sink)), nil
+	default:
+		return "", fmt.Errorf("unsupported metrics sink type %q", sink.Type)
+	}
+
+	lines := []string{fmt.Sprintf("*.sink.%s.class=%s", name, class)}
+	if sink.Host != nil {
+		lines = append(lines, fmt.Sprintf("*.sink.%s.host=%s", name, *sink.Host))
+	}
+	if sink.Port != nil {
+		lines = append(lines, fmt.Sprintf("*.sink.%s.port=%d", name, *sink.Port))
+	}
+	if sink.Period != nil {
+		lines = append(lines, fmt.Sprintf("*.sink.%s.period=%d", name, *sink.Period))
+		lines = append(lines, fmt.Sprintf("*.sink.%s.unit=seconds", name))
+	}
+	if sink.Prefix != nil {
+		lines = append(lines, fmt.Sprintf("*.sink.%s.prefix=%s", name, *sink.Prefix))
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func getPrometheusServletPath(sink *v1beta1.MetricsSinkSpec) string {
+	if sink.Path != nil {
+		return *sink.Path
+	}
+	return defaultPrometheusServletPath
+}
+
+func buildPrometheusConfigMap(app *v1beta1.SparkApplication, prometheusConfigMapName string) *corev1.ConfigMap {
+	metricsProperties := getMetricsProperties(app)
 	prometheusConfig := config.DefaultPrometheusConfiguration
 	if app.Spec.Monitoring.Prometheus.Configuration != nil {
 		prometheusConfig = *app.Spec.Monitoring.Prometheus.Configuration
@@ -38,8 +38,12 @@ func TestConfigPrometheusMonitoring(t *testing.T) {
 	}
 
 	fakeClient := fake.NewSimpleClientset()
+	metadata := propagatedMetadata{
+		labels:      map[string]string{"cost-center": "ads"},
+		annotations: map[string]string{"data-classification": "pii"},
+	}
 	testFn := func(test testcase, t *testing.T) {
-		err := configPrometheusMonitoring(test.app, fakeClient)
+		err := configPrometheusMonitoring(test.app, metadata, fakeClient)
 		if err != nil {
 			t.Errorf("failed to configure Prometheus monitoring: %v", err)
 		}
@@ -50,6 +54,13 @@ func TestConfigPrometheusMonitoring(t *testing.T) {
 			t.Errorf("failed to get ConfigMap %s: %v", configMapName, err)
 		}
 
+		if configMap.Labels["cost-center"] != "ads" {
+			t.Errorf("propagated label expected %s got %s", "ads", configMap.Labels["cost-center"])
+		}
+		if configMap.Annotations["data-classification"] != "pii" {
+			t.Errorf("propagated annotation expected %s got %s", "pii", configMap.Annotations["data-classification"])
+		}
+
 		if len(configMap.Data) != 2 {
 			t.Errorf("expected %d data items got %d", 2, len(configMap.Data))
 		}
@@ -161,3 +172,99 @@ func TestConfigPrometheusMonitoring(t *testing.T) {
 		testFn(test, t)
 	}
 }
+
+func TestConfigMetricsSink(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app1",
+			Namespace: "default",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Monitoring: &v1beta1.MonitoringSpec{
+				ExposeDriverMetrics:   true,
+				ExposeExecutorMetrics: true,
+				MetricsProperties:     stringptr("driver.source.jvm.class=org.apache.spark.metrics.source.JvmSource\n"),
+				MetricsSink: &v1beta1.MetricsSinkSpec{
+					Type:   v1beta1.StatsDSink,
+					Host:   stringptr("statsd.monitoring"),
+					Port:   int32ptr(8125),
+					Period: int32ptr(10),
+				},
+			},
+		},
+	}
+	if err := configMonitoring(app, propagatedMetadata{}, fakeClient); err != nil {
+		t.Fatal(err)
+	}
+
+	configMap, err := fakeClient.CoreV1().ConfigMaps(app.Namespace).Get("app1-metrics-conf", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedProperties := "driver.source.jvm.class=org.apache.spark.metrics.source.JvmSource\n" +
+		"*.sink.statsd.class=org.apache.spark.metrics.sink.StatsdSink\n" +
+		"*.sink.statsd.host=statsd.monitoring\n" +
+		"*.sink.statsd.port=8125\n" +
+		"*.sink.statsd.period=10\n" +
+		"*.sink.statsd.unit=seconds\n"
+	if configMap.Data[metricsPropertiesKey] != expectedProperties {
+		t.Errorf("metrics.properties expected %q got %q", expectedProperties, configMap.Data[metricsPropertiesKey])
+	}
+	if app.Spec.SparkConf["spark.metrics.conf"] != "/etc/metrics/conf/metrics.properties" {
+		t.Errorf("unexpected spark.metrics.conf %s", app.Spec.SparkConf["spark.metrics.conf"])
+	}
+	if len(app.Spec.Driver.ConfigMaps) != 1 || len(app.Spec.Executor.ConfigMaps) != 1 {
+		t.Errorf("expected the metrics ConfigMap to be mounted into the driver and executors")
+	}
+	if len(app.Spec.Driver.Annotations) != 0 {
+		t.Errorf("expected no driver annotations got %d", len(app.Spec.Driver.Annotations))
+	}
+}
+
+func TestConfigMetricsSink_PrometheusServlet(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app1",
+			Namespace: "default",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConf: map[string]string{config.SparkUIPortKey: "4041"},
+			Monitoring: &v1beta1.MonitoringSpec{
+				ExposeExecutorMetrics: true,
+				MetricsSink:           &v1beta1.MetricsSinkSpec{Type: v1beta1.PrometheusServletSink},
+			},
+		},
+	}
+	if err := configMonitoring(app, propagatedMetadata{}, fakeClient); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Spec.SparkConf["spark.ui.prometheus.enabled"] != "true" {
+		t.Errorf("expected spark.ui.prometheus.enabled to be set")
+	}
+	if app.Spec.Driver.Annotations[prometheusPortAnnotation] != "4041" {
+		t.Errorf("scrape port expected %s got %s", "4041", app.Spec.Driver.Annotations[prometheusPortAnnotation])
+	}
+	if app.Spec.Driver.Annotations[prometheusPathAnnotation] != defaultPrometheusServletPath {
+		t.Errorf("scrape path expected %s got %s", defaultPrometheusServletPath, app.Spec.Driver.Annotations[prometheusPathAnnotation])
+	}
+}
+
+func TestConfigMetricsSink_Invalid(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app1",
+			Namespace: "default",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Monitoring: &v1beta1.MonitoringSpec{
+				MetricsSink: &v1beta1.MetricsSinkSpec{Type: v1beta1.GraphiteSink},
+			},
+		},
+	}
+	if err := configMonitoring(app, propagatedMetadata{}, fake.NewSimpleClientset()); err == nil {
+		t.Errorf("expected an error for a Graphite sink without host and port")
+	}
+}
@@ -0,0 +1,406 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	// nativeDriverMasterURL is the master URL of drivers started by the NativePodBuilder, which reach the API
+	// server through its in-cluster Service.
+	nativeDriverMasterURL = "k8s://https://kubernetes.default.svc"
+	// nativeDriverContainerName is the name of the driver container, which is the one spark-submit uses.
+	nativeDriverContainerName = "spark-kubernetes-driver"
+	// sparkDriverBindAddressEnvVar is the environment variable the entrypoint of the Spark images binds the driver
+	// to.
+	sparkDriverBindAddressEnvVar = "SPARK_DRIVER_BIND_ADDRESS"
+	// The memory of the driver and the minimum memory overhead Spark requests for it, in MiB.
+	defaultDriverMemoryMiB      = 1024
+	minMemoryOverheadMiB        = 384
+	defaultMemoryOverheadFactor = 0.1
+)
+
+// NativePodBuilder submits applications by creating their driver pods directly instead of running
+// spark-submit, which saves starting a JVM for every submission. The driver runs spark-submit in client mode in
+// its pod, through the driver command of the entrypoint of the Spark images, and its executors reach it through
+// a headless Service. The driver pod is built from the Spark configuration spark-submit would get, so it is
+// patched by the webhook like any other driver pod.
+type NativePodBuilder struct {
+	kubeClient clientset.Interface
+}
+
+// NewNativePodBuilder creates a NativePodBuilder creating driver pods with the given clientset.
+func NewNativePodBuilder(kubeClient clientset.Interface) *NativePodBuilder {
+	return &NativePodBuilder{kubeClient: kubeClient}
+}
+
+func (b *NativePodBuilder) Submit(submission *Submission) (bool, error) {
+	app := submission.App
+	if app.Spec.Mode != v1beta1.ClusterMode {
+		return false, fmt.Errorf("the %s submitter only submits applications in cluster mode", NativeSubmitterName)
+	}
+	if len(submission.Env) > 0 {
+		return false, fmt.Errorf("the %s submitter does not support impersonating the submitting user",
+			NativeSubmitterName)
+	}
+	args, err := parseSparkSubmitArgs(submission.Args)
+	if err != nil {
+		return false, err
+	}
+	appID, err := newSparkAppID()
+	if err != nil {
+		return false, err
+	}
+
+	pod, err := buildNativeDriverPod(app, args, appID, getNativeDriverServiceName(app))
+	if err != nil {
+		return false, err
+	}
+	service := buildNativeDriverService(app, args.conf)
+	_, err = b.kubeClient.CoreV1().Services(app.Namespace).Create(service)
+	if err = ignoreAlreadyExists(err); err != nil {
+		return false, fmt.Errorf("failed to create Service %s/%s: %v", app.Namespace, service.Name, err)
+	}
+	if _, err := b.kubeClient.CoreV1().Pods(app.Namespace).Create(pod); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create driver pod %s/%s: %v", app.Namespace, pod.Name, err)
+	}
+	return true, nil
+}
+
+// sparkSubmitArgs are the parsed arguments of spark-submit.
+type sparkSubmitArgs struct {
+	conf map[string]string
+	// options are the options other than --conf, --master and --deploy-mode, in the order they were given.
+	options         []string
+	mainFile        string
+	applicationArgs []string
+}
+
+// parseSparkSubmitArgs parses the spark-submit arguments built by buildSubmissionCommandArgs, whose options all
+// take a value.
+func parseSparkSubmitArgs(args []string) (*sparkSubmitArgs, error) {
+	parsed := &sparkSubmitArgs{conf: make(map[string]string)}
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			parsed.mainFile = args[i]
+			parsed.applicationArgs = args[i+1:]
+			break
+		}
+		if i+1 == len(args) {
+			return nil, fmt.Errorf("missing value of spark-submit option %s", args[i])
+		}
+		option, value := args[i], args[i+1]
+		i++
+		switch option {
+		case "--conf":
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid Spark configuration property %q", value)
+			}
+			parsed.conf[parts[0]] = parts[1]
+		case "--master", "--deploy-mode":
+		default:
+			parsed.options = append(parsed.options, option, value)
+		}
+	}
+	return parsed, nil
+}
+
+// newSparkAppID returns a new Spark application ID of the form Spark generates them in.
+func newSparkAppID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("spark-%x", b), nil
+}
+
+func getNativeDriverServiceName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "driver-svc", util.DNS1123LabelMaxLength)
+}
+
+// getNativeDriverPorts returns the ports of the driver and its block manager, which have to be fixed for the
+// Service of the driver.
+func getNativeDriverPorts(conf map[string]string) (int32, int32, error) {
+	ports := []int32{defaultExternalDriverPort, defaultExternalDriverBlockManagerPort}
+	for i, key := range []string{config.SparkDriverPortKey, config.SparkDriverBlockManagerPortKey} {
+		if value, ok := conf[key]; ok {
+			port, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || port <= 0 {
+				return 0, 0, fmt.Errorf("invalid %s %q", key, value)
+			}
+			ports[i] = int32(port)
+		}
+	}
+	return ports[0], ports[1], nil
+}
+
+// buildNativeDriverService builds the headless Service of the driver of the given application, whose ports have
+// been validated by buildNativeDriverPod.
+func buildNativeDriverService(app *v1beta1.SparkApplication, conf map[string]string) *apiv1.Service {
+	port, blockManagerPort, _ := getNativeDriverPorts(conf)
+	return &apiv1.Service{
+		ObjectMeta: buildAppResourceObjectMeta(app, getNativeDriverServiceName(app)),
+		Spec: apiv1.ServiceSpec{
+			ClusterIP: apiv1.ClusterIPNone,
+			Selector: map[string]string{
+				config.SparkAppNameLabel: app.Name,
+				config.SparkRoleLabel:    config.SparkDriverRole,
+			},
+			Ports: []apiv1.ServicePort{
+				{Name: "driver-rpc-port", Port: port},
+				{Name: "blockmanager", Port: blockManagerPort},
+			},
+		},
+	}
+}
+
+// buildNativeDriverPod builds the driver pod of the given application from its spark-submit arguments.
+func buildNativeDriverPod(app *v1beta1.SparkApplication, args *sparkSubmitArgs, appID string,
+	serviceName string) (*apiv1.Pod, error) {
+	conf := make(map[string]string)
+	for key, value := range args.conf {
+		conf[key] = value
+	}
+	port, blockManagerPort, err := getNativeDriverPorts(conf)
+	if err != nil {
+		return nil, err
+	}
+	conf["spark.app.id"] = appID
+	conf[config.SparkDriverHostKey] = fmt.Sprintf("%s.%s.svc", serviceName, app.Namespace)
+	conf[config.SparkDriverPortKey] = fmt.Sprint(port)
+	conf[config.SparkDriverBlockManagerPortKey] = fmt.Sprint(blockManagerPort)
+
+	image := conf[config.SparkDriverContainerImageKey]
+	if image == "" {
+		image = conf[config.SparkContainerImageKey]
+	}
+	if image == "" {
+		return nil, fmt.Errorf("no image is set for the driver")
+	}
+	podName := conf[config.SparkDriverPodNameKey]
+	if podName == "" {
+		podName = getDefaultDriverPodName(app)
+		conf[config.SparkDriverPodNameKey] = podName
+	}
+	resources, err := getNativeDriverResources(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := getConfWithPrefix(conf, config.SparkDriverLabelKeyPrefix)
+	labels[config.SparkRoleLabel] = config.SparkDriverRole
+	labels[config.SparkApplicationSelectorLabel] = appID
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            podName,
+			Namespace:       app.Namespace,
+			Labels:          labels,
+			Annotations:     getConfWithPrefix(conf, config.SparkDriverAnnotationKeyPrefix),
+			OwnerReferences: []metav1.OwnerReference{*getOwnerReference(app)},
+		},
+		Spec: apiv1.PodSpec{
+			RestartPolicy:      apiv1.RestartPolicyNever,
+			ServiceAccountName: conf[config.SparkDriverServiceAccountName],
+			NodeSelector:       getConfWithPrefix(conf, config.SparkNodeSelectorKeyPrefix),
+		},
+	}
+	for _, name := range strings.Split(conf[config.SparkImagePullSecretKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, apiv1.LocalObjectReference{Name: name})
+		}
+	}
+
+	container := apiv1.Container{
+		Name:            nativeDriverContainerName,
+		Image:           image,
+		ImagePullPolicy: apiv1.PullPolicy(conf[config.SparkContainerImagePullPolicyKey]),
+		Args:            buildNativeDriverArgs(args, conf),
+		Resources:       resources,
+		Ports: []apiv1.ContainerPort{
+			{Name: "driver-rpc-port", ContainerPort: port},
+			{Name: "blockmanager", ContainerPort: blockManagerPort},
+		},
+	}
+	env := getConfWithPrefix(conf, config.SparkDriverEnvVarConfigKeyPrefix)
+	for _, name := range sortedKeys(env) {
+		container.Env = append(container.Env, apiv1.EnvVar{Name: name, Value: env[name]})
+	}
+	secretKeyRefs := getConfWithPrefix(conf, config.SparkDriverSecretKeyRefKeyPrefix)
+	for _, name := range sortedKeys(secretKeyRefs) {
+		parts := strings.SplitN(secretKeyRefs[name], ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid secret key reference %q of environment variable %s",
+				secretKeyRefs[name], name)
+		}
+		container.Env = append(container.Env, apiv1.EnvVar{
+			Name: name,
+			ValueFrom: &apiv1.EnvVarSource{SecretKeyRef: &apiv1.SecretKeySelector{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: parts[0]},
+				Key:                  parts[1],
+			}},
+		})
+	}
+	container.Env = append(container.Env, apiv1.EnvVar{
+		Name:      sparkDriverBindAddressEnvVar,
+		ValueFrom: &apiv1.EnvVarSource{FieldRef: &apiv1.ObjectFieldSelector{FieldPath: "status.podIP"}},
+	})
+	secrets := getConfWithPrefix(conf, config.SparkDriverSecretKeyPrefix)
+	for _, name := range sortedKeys(secrets) {
+		volumeName := fmt.Sprintf("%s-volume", name)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, apiv1.Volume{
+			Name:         volumeName,
+			VolumeSource: apiv1.VolumeSource{Secret: &apiv1.SecretVolumeSource{SecretName: name}},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{
+			Name:      volumeName,
+			MountPath: secrets[name],
+		})
+	}
+	pod.Spec.Containers = []apiv1.Container{container}
+	return pod, nil
+}
+
+// buildNativeDriverArgs returns the arguments of the driver command of the entrypoint of the Spark images, which
+// runs spark-submit in client mode with them.
+func buildNativeDriverArgs(args *sparkSubmitArgs, conf map[string]string) []string {
+	driverArgs := []string{"driver", "--master", nativeDriverMasterURL}
+	driverArgs = append(driverArgs, args.options...)
+	for _, key := range sortedKeys(conf) {
+		driverArgs = append(driverArgs, "--conf", fmt.Sprintf("%s=%s", key, conf[key]))
+	}
+	if args.mainFile != "" {
+		driverArgs = append(driverArgs, args.mainFile)
+	}
+	return append(driverArgs, args.applicationArgs...)
+}
+
+// getNativeDriverResources returns the resources of the driver container, which are computed like Spark does.
+func getNativeDriverResources(conf map[string]string) (apiv1.ResourceRequirements, error) {
+	cores := conf["spark.kubernetes.driver.request.cores"]
+	if cores == "" {
+		cores = conf["spark.driver.cores"]
+	}
+	if cores == "" {
+		cores = "1"
+	}
+	cpu, err := resource.ParseQuantity(cores)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, fmt.Errorf("invalid driver cores %q: %v", cores, err)
+	}
+
+	memoryMiB := int64(defaultDriverMemoryMiB)
+	if value, ok := conf["spark.driver.memory"]; ok {
+		if memoryMiB, err = parseJVMMemoryMiB(value); err != nil {
+			return apiv1.ResourceRequirements{}, fmt.Errorf("invalid driver memory %q: %v", value, err)
+		}
+	}
+	factor := defaultMemoryOverheadFactor
+	if value, ok := conf[config.SparkMemoryOverheadFactor]; ok {
+		if factor, err = strconv.ParseFloat(value, 64); err != nil {
+			return apiv1.ResourceRequirements{}, fmt.Errorf("invalid memory overhead factor %q: %v", value, err)
+		}
+	}
+	overheadMiB := int64(math.Max(factor*float64(memoryMiB), minMemoryOverheadMiB))
+	if value, ok := conf["spark.driver.memoryOverhead"]; ok {
+		if overheadMiB, err = parseJVMMemoryMiB(value); err != nil {
+			return apiv1.ResourceRequirements{}, fmt.Errorf("invalid driver memory overhead %q: %v", value, err)
+		}
+	}
+	memory := resource.MustParse(fmt.Sprintf("%dMi", memoryMiB+overheadMiB))
+
+	resources := apiv1.ResourceRequirements{
+		Requests: apiv1.ResourceList{apiv1.ResourceCPU: cpu, apiv1.ResourceMemory: memory},
+		Limits:   apiv1.ResourceList{apiv1.ResourceMemory: memory},
+	}
+	if value, ok := conf[config.SparkDriverCoreLimitKey]; ok {
+		limit, err := resource.ParseQuantity(value)
+		if err != nil {
+			return apiv1.ResourceRequirements{}, fmt.Errorf("invalid driver core limit %q: %v", value, err)
+		}
+		resources.Limits[apiv1.ResourceCPU] = limit
+	}
+	return resources, nil
+}
+
+// parseJVMMemoryMiB parses an amount of memory in the format of JVM memory strings, e.g., "512m" or "2g", in MiB,
+// which is also the unit of amounts without a suffix.
+func parseJVMMemoryMiB(value string) (int64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	unit := 1.0
+	for _, suffix := range jvmMemoryUnits {
+		if strings.HasSuffix(value, suffix.suffix) {
+			unit = suffix.mebibytes
+			value = strings.TrimSuffix(value, suffix.suffix)
+			break
+		}
+	}
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid amount of memory")
+	}
+	return int64(math.Ceil(float64(amount) * unit)), nil
+}
+
+// jvmMemoryUnits are the suffixes of JVM memory strings with their size in MiB, with longer suffixes first.
+var jvmMemoryUnits = []struct {
+	suffix    string
+	mebibytes float64
+}{
+	{"kb", 1.0 / 1024}, {"mb", 1}, {"gb", 1024}, {"tb", 1024 * 1024},
+	{"k", 1.0 / 1024}, {"m", 1}, {"g", 1024}, {"t", 1024 * 1024}, {"b", 1.0 / 1024 / 1024},
+}
+
+// getConfWithPrefix returns the Spark configuration properties with the given prefix, with the prefix removed
+// from their keys.
+func getConfWithPrefix(conf map[string]string, prefix string) map[string]string {
+	result := make(map[string]string)
+	for key, value := range conf {
+		if strings.HasPrefix(key, prefix) {
+			result[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
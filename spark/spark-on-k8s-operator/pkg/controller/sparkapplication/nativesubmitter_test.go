@@ -0,0 +1,199 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestParseSparkSubmitArgs(t *testing.T) {
+	args, err := parseSparkSubmitArgs([]string{
+		"--master", "k8s://https://127.0.0.1:443",
+		"--deploy-mode", "cluster",
+		"--class", "org.apache.spark.examples.SparkPi",
+		"--conf", "spark.executor.instances=2",
+		"--conf", "spark.driver.extraJavaOptions=-Dfoo=bar",
+		"local:///app.jar", "--iterations", "10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"--class", "org.apache.spark.examples.SparkPi"}, args.options)
+	assert.Equal(t, map[string]string{
+		"spark.executor.instances":      "2",
+		"spark.driver.extraJavaOptions": "-Dfoo=bar",
+	}, args.conf)
+	assert.Equal(t, "local:///app.jar", args.mainFile)
+	assert.Equal(t, []string{"--iterations", "10"}, args.applicationArgs)
+
+	_, err = parseSparkSubmitArgs([]string{"--conf"})
+	assert.NotNil(t, err)
+	_, err = parseSparkSubmitArgs([]string{"--conf", "spark.executor.instances", "local:///app.jar"})
+	assert.NotNil(t, err)
+}
+
+func TestParseJVMMemoryMiB(t *testing.T) {
+	cases := map[string]int64{
+		"512":      512,
+		"512m":     512,
+		"512MB":    512,
+		"2g":       2048,
+		"1t":       1024 * 1024,
+		"1536k":    2,
+		"1048576b": 1,
+	}
+	for value, expected := range cases {
+		actual, err := parseJVMMemoryMiB(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, actual, value)
+	}
+	for _, value := range []string{"", "g", "1.5g", "-1m", "1x"} {
+		_, err := parseJVMMemoryMiB(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestBuildNativeDriverPod(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-123"},
+		Spec:       v1beta1.SparkApplicationSpec{Mode: v1beta1.ClusterMode},
+	}
+	args, err := parseSparkSubmitArgs([]string{
+		"--class", "org.apache.spark.examples.SparkPi",
+		"--conf", config.SparkContainerImageKey + "=spark:2.4.0",
+		"--conf", config.SparkDriverLabelKeyPrefix + config.SparkAppNameLabel + "=foo",
+		"--conf", config.SparkDriverAnnotationKeyPrefix + "team=data",
+		"--conf", config.SparkDriverServiceAccountName + "=spark",
+		"--conf", config.SparkDriverEnvVarConfigKeyPrefix + "LOG_LEVEL=DEBUG",
+		"--conf", config.SparkDriverSecretKeyRefKeyPrefix + "PASSWORD=db:password",
+		"--conf", config.SparkDriverSecretKeyPrefix + "gcp-key=/etc/gcp",
+		"--conf", "spark.driver.cores=2",
+		"--conf", "spark.driver.memory=2g",
+		"--conf", config.SparkDriverCoreLimitKey + "=3",
+		"local:///app.jar", "10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceName := getNativeDriverServiceName(app)
+	assert.Equal(t, "foo-driver-svc", serviceName)
+	pod, err := buildNativeDriverPod(app, args, "spark-123", serviceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, getDefaultDriverPodName(app), pod.Name)
+	assert.Equal(t, "default", pod.Namespace)
+	assert.Equal(t, map[string]string{
+		config.SparkAppNameLabel:             "foo",
+		config.SparkRoleLabel:                config.SparkDriverRole,
+		config.SparkApplicationSelectorLabel: "spark-123",
+	}, pod.Labels)
+	assert.Equal(t, map[string]string{"team": "data"}, pod.Annotations)
+	assert.Equal(t, app.UID, pod.OwnerReferences[0].UID)
+	assert.Equal(t, "spark", pod.Spec.ServiceAccountName)
+	assert.Equal(t, apiv1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	assert.Equal(t, "gcp-key-volume", pod.Spec.Volumes[0].Name)
+
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, nativeDriverContainerName, container.Name)
+	assert.Equal(t, "spark:2.4.0", container.Image)
+	assert.Equal(t, []string{"driver", "--master", nativeDriverMasterURL, "--class",
+		"org.apache.spark.examples.SparkPi"}, container.Args[:5])
+	assert.Equal(t, []string{"local:///app.jar", "10"}, container.Args[len(container.Args)-2:])
+	assert.Contains(t, container.Args, "spark.app.id=spark-123")
+	assert.Contains(t, container.Args, config.SparkDriverHostKey+"=foo-driver-svc.default.svc")
+	assert.Contains(t, container.Args, config.SparkDriverPodNameKey+"="+pod.Name)
+	assert.Equal(t, 3, len(container.Env))
+	assert.Equal(t, apiv1.EnvVar{Name: "LOG_LEVEL", Value: "DEBUG"}, container.Env[0])
+	assert.Equal(t, "db", container.Env[1].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, sparkDriverBindAddressEnvVar, container.Env[2].Name)
+	assert.Equal(t, "/etc/gcp", container.VolumeMounts[0].MountPath)
+
+	// The memory overhead is 10% of the memory of the driver, but at least 384MiB.
+	assert.Equal(t, resource.MustParse("2"), container.Resources.Requests[apiv1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("2432Mi"), container.Resources.Requests[apiv1.ResourceMemory])
+	assert.Equal(t, resource.MustParse("3"), container.Resources.Limits[apiv1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("2432Mi"), container.Resources.Limits[apiv1.ResourceMemory])
+
+	service := buildNativeDriverService(app, args.conf)
+	assert.Equal(t, serviceName, service.Name)
+	assert.Equal(t, apiv1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.Equal(t, int32(7078), service.Spec.Ports[0].Port)
+	assert.Equal(t, int32(7079), service.Spec.Ports[1].Port)
+
+	// Drivers need an image.
+	delete(args.conf, config.SparkContainerImageKey)
+	_, err = buildNativeDriverPod(app, args, "spark-123", serviceName)
+	assert.NotNil(t, err)
+}
+
+func TestNativePodBuilderSubmit(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       v1beta1.SparkApplicationSpec{Mode: v1beta1.ClusterMode},
+	}
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	builder := NewNativePodBuilder(kubeClient)
+	submission := newSubmission([]string{
+		"--master", "k8s://https://127.0.0.1:443",
+		"--deploy-mode", "cluster",
+		"--conf", config.SparkContainerImageKey + "=spark:2.4.0",
+		"local:///app.jar",
+	}, app)
+
+	submitted, err := builder.Submit(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, submitted)
+	pod, err := kubeClient.CoreV1().Pods("default").Get(getDefaultDriverPodName(app), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, "", pod.Labels[config.SparkApplicationSelectorLabel])
+	_, err = kubeClient.CoreV1().Services("default").Get("foo-driver-svc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A driver pod that already exists means the run has already been submitted.
+	submitted, err = builder.Submit(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, submitted)
+
+	// Applications in client mode and impersonating submissions are not supported.
+	submission.Env = []string{"HADOOP_USER_NAME=alice"}
+	_, err = builder.Submit(submission)
+	assert.NotNil(t, err)
+	app.Spec.Mode = v1beta1.ClientMode
+	_, err = builder.Submit(newSubmission(submission.Args, app))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/notification"
+)
+
+// SetNotifier sets the Notifier sending notifications about applications. It must be called before the
+// controller is started.
+func (c *Controller) SetNotifier(notifier *notification.Notifier) {
+	c.notifier = notifier
+}
+
+// getTerminationNotificationEvent returns the event notified for the given terminated application, if any. A
+// failure tells if the application has been retried before it failed for good.
+func getTerminationNotificationEvent(app *v1beta1.SparkApplication) (v1beta1.NotificationEvent, bool) {
+	switch app.Status.AppState.State {
+	case v1beta1.CompletedState:
+		return v1beta1.CompletedNotificationEvent, true
+	case v1beta1.FailedState:
+		if app.Spec.RestartPolicy.Type != v1beta1.Never &&
+			(app.Status.ExecutionAttempts > 1 || app.Status.SubmissionAttempts > 1) {
+			return v1beta1.RetriesExhaustedNotificationEvent, true
+		}
+		return v1beta1.FailedNotificationEvent, true
+	}
+	return "", false
+}
+
+// getSLADeadline returns the time by which the current run of the given application must have completed to
+// meet its SLA, if the application has one and the run has not yet been found to breach it.
+func getSLADeadline(app *v1beta1.SparkApplication) (time.Time, bool) {
+	if app.Spec.Notifications == nil || app.Spec.Notifications.SLASeconds == nil ||
+		!app.Status.SLABreachTime.IsZero() || app.Status.LastSubmissionAttemptTime.IsZero() {
+		return time.Time{}, false
+	}
+	switch app.Status.AppState.State {
+	case v1beta1.SubmittedState, v1beta1.RunningState:
+	default:
+		return time.Time{}, false
+	}
+	return app.Status.LastSubmissionAttemptTime.Add(time.Duration(*app.Spec.Notifications.SLASeconds) * time.Second), true
+}
+
+// checkSLA notifies that the current run of the given application breaches its SLA once it has taken longer,
+// or checks again when it would.
+func (c *Controller) checkSLA(key string, app *v1beta1.SparkApplication) {
+	deadline, ok := getSLADeadline(app)
+	if !ok {
+		return
+	}
+	if now := time.Now(); now.Before(deadline) {
+		c.queue.AddAfter(key, deadline.Sub(now))
+		return
+	}
+
+	app.Status.SLABreachTime = metav1.Now()
+	if err := c.notifier.Notify(app, v1beta1.SLABreachedNotificationEvent); err != nil {
+		glog.Error(err)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/notification"
+)
+
+func TestGetTerminationNotificationEvent(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:           v1beta1.ApplicationState{State: v1beta1.FailedState},
+			ExecutionAttempts:  1,
+			SubmissionAttempts: 1,
+		},
+	}
+	event, ok := getTerminationNotificationEvent(app)
+	assert.True(t, ok)
+	assert.Equal(t, v1beta1.FailedNotificationEvent, event)
+
+	app.Spec.RestartPolicy.Type = v1beta1.OnFailure
+	event, _ = getTerminationNotificationEvent(app)
+	assert.Equal(t, v1beta1.FailedNotificationEvent, event)
+
+	app.Status.ExecutionAttempts = 3
+	event, _ = getTerminationNotificationEvent(app)
+	assert.Equal(t, v1beta1.RetriesExhaustedNotificationEvent, event)
+
+	app.Status.AppState.State = v1beta1.CompletedState
+	event, ok = getTerminationNotificationEvent(app)
+	assert.True(t, ok)
+	assert.Equal(t, v1beta1.CompletedNotificationEvent, event)
+
+	app.Status.AppState.State = v1beta1.FailedSubmissionState
+	_, ok = getTerminationNotificationEvent(app)
+	assert.False(t, ok)
+}
+
+func TestCheckSLA(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		texts = append(texts, body["text"])
+	}))
+	defer server.Close()
+	notifier, err := notification.NewNotifier(&notification.Config{
+		Sinks: []notification.SinkConfig{
+			{Name: "team-a", Slack: &notification.SlackConfig{WebhookURL: server.URL}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slaSeconds := int64(3600)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Notifications: &v1beta1.NotificationSpec{
+				Sinks:      []string{"team-a"},
+				SLASeconds: &slaSeconds,
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.RunningState},
+			LastSubmissionAttemptTime: metav1.NewTime(time.Now().Add(-30 * time.Minute)),
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	ctrl.SetNotifier(notifier)
+
+	// The run has not taken longer than its SLA yet.
+	ctrl.checkSLA("default/foo", app)
+	assert.True(t, app.Status.SLABreachTime.IsZero())
+	assert.Equal(t, 0, len(texts))
+
+	app.Status.LastSubmissionAttemptTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	ctrl.checkSLA("default/foo", app)
+	assert.False(t, app.Status.SLABreachTime.IsZero())
+	assert.Equal(t, []string{"SparkApplication default/foo: SLABreached"}, texts)
+
+	// A breach is only notified once per run.
+	ctrl.checkSLA("default/foo", app)
+	assert.Equal(t, 1, len(texts))
+
+	_, ok := getSLADeadline(&v1beta1.SparkApplication{Spec: app.Spec})
+	assert.False(t, ok)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	outputCleanupContainerName = "output-cleanup"
+	// outputCleanupScript runs the Hadoop FsShell with the arguments of the container on the classpath of Spark,
+	// which has the Hadoop file systems the application writes with.
+	outputCleanupScript = `exec "${SPARK_HOME:-/opt/spark}/bin/spark-class" org.apache.hadoop.fs.FsShell "$@"`
+	// Committers write the output of uncommitted tasks and jobs under this directory of the output path.
+	committerTemporaryDir     = "_temporary"
+	outputCleanupBackoffLimit = 2
+)
+
+func getOutputCleanupJobName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "output-cleanup", util.DNS1123LabelMaxLength)
+}
+
+// validateOutputPaths checks that the output paths of the given application can be cleaned up safely.
+func validateOutputPaths(app *v1beta1.SparkApplication) error {
+	if len(app.Spec.OutputCleanup.OutputPaths) == 0 {
+		return fmt.Errorf("outputCleanup requires at least one output path")
+	}
+	for _, path := range app.Spec.OutputCleanup.OutputPaths {
+		trimmed := strings.TrimRight(path, "/")
+		if trimmed == "" || strings.HasSuffix(trimmed, ":") || strings.HasSuffix(trimmed, ":/") {
+			return fmt.Errorf("output path %q must not be the root of a file system", path)
+		}
+		for _, segment := range strings.Split(trimmed, "/") {
+			if segment == ".." || segment == committerTemporaryDir {
+				return fmt.Errorf("output path %q must not contain %s", path, segment)
+			}
+		}
+	}
+	return nil
+}
+
+// buildHadoopConfArgs returns the Hadoop generic options setting the Hadoop configuration properties of the given
+// application, e.g., S3 endpoints, so they apply to Hadoop commands run for it as well.
+func buildHadoopConfArgs(app *v1beta1.SparkApplication) []string {
+	var args []string
+	var keys []string
+	for key := range app.Spec.HadoopConf {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-D", fmt.Sprintf("%s=%s", key, app.Spec.HadoopConf[key]))
+	}
+	return args
+}
+
+// buildOutputCleanupArgs returns the FsShell arguments deleting the temporary directories of the committers
+// under the output paths of the given application.
+func buildOutputCleanupArgs(app *v1beta1.SparkApplication) []string {
+	args := buildHadoopConfArgs(app)
+	args = append(args, "-rm", "-r", "-f", "-skipTrash")
+	for _, path := range app.Spec.OutputCleanup.OutputPaths {
+		args = append(args, strings.TrimRight(path, "/")+"/"+committerTemporaryDir)
+	}
+	return args
+}
+
+// buildDriverJob returns a Job of the given application running the given container with the given image, or
+// the image of the driver if nil, and with the service account and environment of the driver, so it has the
+// credentials the driver writes with.
+func buildDriverJob(app *v1beta1.SparkApplication, name string, image *string, container apiv1.Container,
+	backoffLimit int32) *batchv1.Job {
+	if image == nil {
+		image = app.Spec.Image
+		if app.Spec.Driver.Image != nil {
+			image = app.Spec.Driver.Image
+		}
+	}
+	if image != nil {
+		container.Image = *image
+	}
+	if app.Spec.ImagePullPolicy != nil {
+		container.ImagePullPolicy = apiv1.PullPolicy(*app.Spec.ImagePullPolicy)
+	}
+	var envNames []string
+	for name := range app.Spec.Driver.EnvVars {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		container.Env = append(container.Env, apiv1.EnvVar{Name: name, Value: app.Spec.Driver.EnvVars[name]})
+	}
+	envNames = nil
+	for name := range app.Spec.Driver.EnvSecretKeyRefs {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		ref := app.Spec.Driver.EnvSecretKeyRefs[name]
+		container.Env = append(container.Env, apiv1.EnvVar{
+			Name: name,
+			ValueFrom: &apiv1.EnvVarSource{
+				SecretKeyRef: &apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: ref.Name},
+					Key:                  ref.Key,
+				},
+			},
+		})
+	}
+
+	podSpec := apiv1.PodSpec{
+		Containers:    []apiv1.Container{container},
+		RestartPolicy: apiv1.RestartPolicyNever,
+		NodeSelector:  app.Spec.NodeSelector,
+	}
+	// A dedicated service account of the driver is gone once the application has terminated.
+	if app.Spec.Driver.ServiceAccount != nil && !createsDriverServiceAccount(app) {
+		podSpec.ServiceAccountName = *app.Spec.Driver.ServiceAccount
+	}
+	for _, secret := range app.Spec.ImagePullSecrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, apiv1.LocalObjectReference{Name: secret})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: buildAppResourceObjectMeta(app, name),
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{config.SparkAppNameLabel: app.Name}},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+// buildOutputCleanupJob returns the Job cleaning up the output of the given failed application.
+func buildOutputCleanupJob(app *v1beta1.SparkApplication) *batchv1.Job {
+	container := apiv1.Container{
+		Name:    outputCleanupContainerName,
+		Command: []string{"/bin/sh", "-c", outputCleanupScript, "spark-class"},
+		Args:    buildOutputCleanupArgs(app),
+	}
+	return buildDriverJob(app, getOutputCleanupJobName(app), app.Spec.OutputCleanup.Image, container,
+		outputCleanupBackoffLimit)
+}
+
+// cleanUpOutput starts the Job cleaning up the output of the given failed application, unless it exists.
+func (c *Controller) cleanUpOutput(app *v1beta1.SparkApplication) error {
+	if err := validateOutputPaths(app); err != nil {
+		return err
+	}
+	job := buildOutputCleanupJob(app)
+	_, err := c.kubeClient.BatchV1().Jobs(app.Namespace).Create(job)
+	if err = ignoreAlreadyExists(err); err != nil {
+		return fmt.Errorf("failed to create Job %s/%s: %v", app.Namespace, job.Name, err)
+	}
+	glog.Infof("Started Job %s/%s to clean up the output of failed SparkApplication %s", app.Namespace, job.Name, app.Name)
+	c.recorder.Eventf(
+		app,
+		apiv1.EventTypeNormal,
+		"SparkApplicationOutputCleanupStarted",
+		"Started Job %s to clean up the output of SparkApplication %s",
+		job.Name,
+		app.Name)
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestValidateOutputPaths(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			OutputCleanup: &v1beta1.OutputCleanupSpec{
+				OutputPaths: []string{"s3a://bucket/table/", "hdfs:///data/table", "/data/table"},
+			},
+		},
+	}
+	assert.Nil(t, validateOutputPaths(app))
+
+	for _, path := range []string{"", "/", "s3a://", "hdfs:///", "/data/../etc", "/data/_temporary"} {
+		app.Spec.OutputCleanup.OutputPaths = []string{path}
+		assert.NotNil(t, validateOutputPaths(app), path)
+	}
+	app.Spec.OutputCleanup.OutputPaths = nil
+	assert.NotNil(t, validateOutputPaths(app))
+}
+
+func TestSyncSparkApplication_OutputCleanup(t *testing.T) {
+	image := "spark:2.4.0"
+	serviceAccount := "spark"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-1"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Image:      &image,
+			HadoopConf: map[string]string{"fs.s3a.endpoint": "s3.example.com"},
+			Driver: v1beta1.DriverSpec{
+				ServiceAccount: &serviceAccount,
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					EnvVars:          map[string]string{"AWS_REGION": "us-east-1"},
+					EnvSecretKeyRefs: map[string]v1beta1.NameKey{"AWS_SECRET_ACCESS_KEY": {Name: "aws", Key: "secret"}},
+				},
+			},
+			OutputCleanup: &v1beta1.OutputCleanupSpec{OutputPaths: []string{"s3a://bucket/table/"}},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:        v1beta1.ApplicationState{State: v1beta1.FailingState},
+			TerminationTime: metav1.Now(),
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.syncSparkApplication("test/foo"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := ctrl.kubeClient.BatchV1().Jobs("test").Get("foo-output-cleanup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "uid-1", string(job.OwnerReferences[0].UID))
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, apiv1.RestartPolicyNever, podSpec.RestartPolicy)
+	assert.Equal(t, "spark", podSpec.ServiceAccountName)
+	container := podSpec.Containers[0]
+	assert.Equal(t, image, container.Image)
+	assert.Equal(t, []string{"-D", "fs.s3a.endpoint=s3.example.com", "-rm", "-r", "-f", "-skipTrash",
+		"s3a://bucket/table/_temporary"}, container.Args)
+	assert.Equal(t, 2, len(container.Env))
+	assert.Equal(t, "aws", container.Env[1].ValueFrom.SecretKeyRef.Name)
+}
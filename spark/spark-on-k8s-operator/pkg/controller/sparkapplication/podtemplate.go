@@ -0,0 +1,134 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const podTemplateChangedReason = "PodTemplateChanged"
+
+// getPodTemplateHash returns a hash of the parts of the spec of the given application that determine its driver
+// and executor pods, i.e., all of it but the fields the operator only reads itself while the application runs.
+// Changes to those fields apply to the current run, while any other change requires a new run.
+func getPodTemplateHash(app *v1beta1.SparkApplication) string {
+	// The spec of applications in the cache of the informer is defaulted, while updated ones are not.
+	defaulted := app.DeepCopy()
+	v1beta1.SetSparkApplicationDefaults(defaulted)
+	spec := defaulted.Spec
+	// The fields left out are listed in hotSwappableFields.
+	spec.RestartPolicy = v1beta1.RestartPolicy{}
+	spec.FailureRetries = nil
+	spec.RetryInterval = nil
+	spec.Rotation = nil
+	spec.OutputCleanup = nil
+	spec.DriverLogCapture = nil
+	spec.Notifications = nil
+	spec.Priority = nil
+	spec.PreemptionPolicy = nil
+	spec.ExecutorIdleTimeout = nil
+	spec.RunHistory = nil
+	spec.RestartOnConfigChange = nil
+
+	// Maps are marshaled with sorted keys, so equal specs have equal hashes.
+	specBytes, err := json.Marshal(&spec)
+	if err != nil {
+		// Never happens for a spec that has been unmarshaled from JSON.
+		glog.Errorf("failed to marshal the spec of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return ""
+	}
+	hasher := util.NewHash32()
+	hasher.Write(specBytes)
+	return fmt.Sprintf("%08x", hasher.Sum32())
+}
+
+// addPodTemplateHashAnnotations annotates the driver and executor pods of the given application with the given
+// pod template hash.
+func addPodTemplateHashAnnotations(app *v1beta1.SparkApplication, hash string) {
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	app.Spec.SparkConf[config.SparkDriverAnnotationKeyPrefix+config.PodTemplateHashAnnotation] = hash
+	app.Spec.SparkConf[config.SparkExecutorAnnotationKeyPrefix+config.PodTemplateHashAnnotation] = hash
+}
+
+// getApplicationCondition returns the condition of the given type of the given application, if any.
+func getApplicationCondition(status *v1beta1.SparkApplicationStatus,
+	conditionType v1beta1.ApplicationConditionType) *v1beta1.ApplicationCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setApplicationCondition sets the condition of the given type of the given application. The transition time
+// only changes along with the status of the condition.
+func setApplicationCondition(status *v1beta1.SparkApplicationStatus, conditionType v1beta1.ApplicationConditionType,
+	conditionStatus apiv1.ConditionStatus, reason, message string) {
+	condition := getApplicationCondition(status, conditionType)
+	if condition == nil {
+		status.Conditions = append(status.Conditions, v1beta1.ApplicationCondition{Type: conditionType})
+		condition = &status.Conditions[len(status.Conditions)-1]
+	}
+	if condition.Status != conditionStatus {
+		condition.Status = conditionStatus
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Reason = reason
+	condition.Message = message
+}
+
+// setUpdateRequired reports that the pods of the current run of the given application do not match its spec.
+func setUpdateRequired(status *v1beta1.SparkApplicationStatus, hash string) {
+	setApplicationCondition(status, v1beta1.UpdateRequiredCondition, apiv1.ConditionTrue, podTemplateChangedReason,
+		fmt.Sprintf("the pod template hash changed from %s to %s, the application is restarted",
+			status.PodTemplateHash, hash))
+}
+
+// detectPodTemplateDrift restarts the given submitted or running application if its spec has changed in a way
+// that requires new pods since its current run was submitted, in case the change was missed by onUpdate, and
+// keeps the UpdateRequired condition of the application up to date.
+func (c *Controller) detectPodTemplateDrift(app *v1beta1.SparkApplication) {
+	state := app.Status.AppState.State
+	if app.Status.PodTemplateHash == "" || (state != v1beta1.SubmittedState && state != v1beta1.RunningState) {
+		return
+	}
+	hash := getPodTemplateHash(app)
+	if hash == app.Status.PodTemplateHash {
+		if condition := getApplicationCondition(&app.Status, v1beta1.UpdateRequiredCondition); condition != nil {
+			setApplicationCondition(&app.Status, v1beta1.UpdateRequiredCondition, apiv1.ConditionFalse, "", "")
+		}
+		return
+	}
+	glog.Infof("The pod template of SparkApplication %s/%s has changed from %s to %s, restarting it",
+		app.Namespace, app.Name, app.Status.PodTemplateHash, hash)
+	setUpdateRequired(&app.Status, hash)
+	app.Status.AppState.State = v1beta1.InvalidatingState
+	c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkApplicationUpdateRequired",
+		"SparkApplication %s is restarted as its pod template has changed", app.Name)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestGetPodTemplateHash(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Image:     stringptr("spark:v1"),
+			SparkConf: map[string]string{"spark.a": "1", "spark.b": "2"},
+		},
+	}
+	hash := getPodTemplateHash(app)
+	assert.Len(t, hash, 8)
+
+	// Defaults and fields the operator reads itself do not change the hash.
+	defaulted := app.DeepCopy()
+	v1beta1.SetSparkApplicationDefaults(defaulted)
+	assert.Equal(t, hash, getPodTemplateHash(defaulted))
+	operatorOnly := app.DeepCopy()
+	operatorOnly.Spec.RestartPolicy = v1beta1.RestartPolicy{Type: v1beta1.Always}
+	operatorOnly.Spec.Priority = int32ptr(10)
+	operatorOnly.Spec.RunHistory = &v1beta1.RunHistorySpec{}
+	assert.Equal(t, hash, getPodTemplateHash(operatorOnly))
+
+	changed := app.DeepCopy()
+	changed.Spec.SparkConf["spark.b"] = "3"
+	assert.NotEqual(t, hash, getPodTemplateHash(changed))
+	changed = app.DeepCopy()
+	changed.Spec.Executor.Instances = int32ptr(2)
+	assert.NotEqual(t, hash, getPodTemplateHash(changed))
+}
+
+func TestDetectPodTemplateDrift(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{Image: stringptr("spark:v1")},
+	}
+	ctrl, recorder := newFakeController(app)
+	app.Status.AppState.State = v1beta1.RunningState
+	app.Status.PodTemplateHash = getPodTemplateHash(app)
+
+	ctrl.detectPodTemplateDrift(app)
+	assert.Equal(t, v1beta1.RunningState, app.Status.AppState.State)
+	assert.Empty(t, app.Status.Conditions)
+
+	app.Spec.Image = stringptr("spark:v2")
+	ctrl.detectPodTemplateDrift(app)
+	assert.Equal(t, v1beta1.InvalidatingState, app.Status.AppState.State)
+	condition := getApplicationCondition(&app.Status, v1beta1.UpdateRequiredCondition)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, apiv1.ConditionTrue, condition.Status)
+		assert.Equal(t, podTemplateChangedReason, condition.Reason)
+	}
+	assert.Equal(t, 1, len(recorder.Events))
+
+	// The condition is cleared once the pods match the spec again.
+	app.Status.AppState.State = v1beta1.RunningState
+	app.Status.PodTemplateHash = getPodTemplateHash(app)
+	ctrl.detectPodTemplateDrift(app)
+	assert.Equal(t, apiv1.ConditionFalse, getApplicationCondition(&app.Status, v1beta1.UpdateRequiredCondition).Status)
+}
+
+func TestAddPodTemplateHashAnnotations(t *testing.T) {
+	app := &v1beta1.SparkApplication{}
+	addPodTemplateHashAnnotations(app, "0123abcd")
+	assert.Equal(t, "0123abcd",
+		app.Spec.SparkConf["spark.kubernetes.driver.annotation.sparkoperator.k8s.io/pod-template-hash"])
+	assert.Equal(t, "0123abcd",
+		app.Spec.SparkConf["spark.kubernetes.executor.annotation.sparkoperator.k8s.io/pod-template-hash"])
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// preemptFor preempts a running lower-priority application to make room for the given queued application if
+// the scheduler finds one, and tells if the given application may start. The victim is invalidated, which
+// gracefully terminates its driver and queues it again.
+func (c *Controller) preemptFor(app *v1beta1.SparkApplication) (bool, error) {
+	victim, err := c.scheduler.Preempt(app)
+	if err != nil || victim == nil {
+		return false, err
+	}
+
+	message := fmt.Sprintf("preempted by SparkApplication %s/%s with a higher priority", app.Namespace, app.Name)
+	if _, err := c.updateApplicationStatusWithRetries(victim, func(status *v1beta1.SparkApplicationStatus) {
+		status.AppState.State = v1beta1.InvalidatingState
+		status.AppState.ErrorMessage = message
+	}); err != nil {
+		c.scheduler.AbortPreemption(app, victim)
+		return false, fmt.Errorf("failed to preempt SparkApplication %s/%s: %v", victim.Namespace, victim.Name, err)
+	}
+
+	glog.Infof("SparkApplication %s/%s preempted SparkApplication %s/%s", app.Namespace, app.Name,
+		victim.Namespace, victim.Name)
+	c.recorder.Eventf(
+		victim,
+		apiv1.EventTypeWarning,
+		"SparkApplicationPreempted",
+		"SparkApplication %s was preempted by SparkApplication %s/%s with a higher priority and is queued again",
+		victim.Name,
+		app.Namespace,
+		app.Name)
+	c.recorder.Eventf(
+		app,
+		apiv1.EventTypeNormal,
+		"SparkApplicationPreempting",
+		"SparkApplication %s preempted SparkApplication %s/%s with a lower priority",
+		app.Name,
+		victim.Namespace,
+		victim.Name)
+	return true, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/scheduler"
+)
+
+func TestPreemptFor(t *testing.T) {
+	victim := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "victim", Namespace: "test"},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+		},
+	}
+	priority := int32(10)
+	queued := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "urgent", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{Priority: &priority},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.QueuedState},
+		},
+	}
+	ctrl, recorder := newFakeController(victim)
+	ctrl.scheduler = scheduler.NewFairShareScheduler(ctrl.applicationLister, 1, nil,
+		map[string]int32{"test": 10}, nil)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Create(victim); err != nil {
+		t.Fatal(err)
+	}
+
+	start, err := ctrl.preemptFor(queued)
+	assert.Nil(t, err)
+	assert.True(t, start)
+	updatedVictim, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Get("victim",
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.InvalidatingState, updatedVictim.Status.AppState.State)
+	assert.Contains(t, updatedVictim.Status.AppState.ErrorMessage, "test/urgent")
+	assert.Equal(t, 2, len(recorder.Events))
+	assert.Contains(t, <-recorder.Events, "SparkApplicationPreempted")
+	assert.Contains(t, <-recorder.Events, "SparkApplicationPreempting")
+}
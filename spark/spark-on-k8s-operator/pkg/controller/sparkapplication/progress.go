@@ -0,0 +1,386 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const progressRequestTimeout = 5 * time.Second
+
+// sparkDateLayout is the layout of the times in the responses of the Spark REST API.
+const sparkDateLayout = "2006-01-02T15:04:05.000GMT"
+
+// progressTracker runs a goroutine per running driver that periodically queries the REST API of the driver
+// for the progress of its jobs and stages, and the resources the run has used so far. Polling happens outside of the controller workers, so a slow or
+// unreachable driver does not hold up the processing of other applications.
+type progressTracker struct {
+	client   *http.Client
+	interval time.Duration
+	// onChange is called with the key of an application whenever its progress has changed.
+	onChange func(key string)
+
+	mutex   sync.Mutex
+	drivers map[string]*trackedDriver
+}
+
+type trackedDriver struct {
+	baseURL  string
+	stopCh   chan struct{}
+	progress *v1beta1.ApplicationProgress
+	usage    *v1beta1.ApplicationUsage
+}
+
+// The subset of the Spark REST API responses the progress and usage are derived from.
+type sparkApplicationInfo struct {
+	ID string `json:"id"`
+}
+
+type sparkJobData struct {
+	JobID             int32  `json:"jobId"`
+	Status            string `json:"status"`
+	NumTasks          int32  `json:"numTasks"`
+	NumCompletedTasks int32  `json:"numCompletedTasks"`
+	NumSkippedTasks   int32  `json:"numSkippedTasks"`
+}
+
+type sparkStageData struct {
+	StageID           int32  `json:"stageId"`
+	Status            string `json:"status"`
+	Name              string `json:"name"`
+	NumTasks          int32  `json:"numTasks"`
+	NumCompleteTasks  int32  `json:"numCompleteTasks"`
+	NumFailedTasks    int32  `json:"numFailedTasks"`
+	NumKilledTasks    int32  `json:"numKilledTasks"`
+	InputBytes        int64  `json:"inputBytes"`
+	OutputBytes       int64  `json:"outputBytes"`
+	OutputRecords     int64  `json:"outputRecords"`
+	ShuffleReadBytes  int64  `json:"shuffleReadBytes"`
+	ShuffleWriteBytes int64  `json:"shuffleWriteBytes"`
+}
+
+type sparkExecutorSummary struct {
+	ID         string `json:"id"`
+	AddTime    string `json:"addTime"`
+	RemoveTime string `json:"removeTime"`
+}
+
+func newProgressTracker(interval time.Duration, onChange func(key string)) *progressTracker {
+	return &progressTracker{
+		client:   &http.Client{Timeout: progressRequestTimeout},
+		interval: interval,
+		onChange: onChange,
+		drivers:  make(map[string]*trackedDriver),
+	}
+}
+
+// track starts polling the driver with the given base URL for the application with the given key, unless
+// it is already being polled. Polling of a previous driver of the application is stopped.
+func (t *progressTracker) track(key string, baseURL string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if driver, ok := t.drivers[key]; ok {
+		if driver.baseURL == baseURL {
+			return
+		}
+		close(driver.stopCh)
+	}
+	driver := &trackedDriver{baseURL: baseURL, stopCh: make(chan struct{})}
+	t.drivers[key] = driver
+	go wait.Until(func() { t.poll(key, driver) }, t.interval, driver.stopCh)
+}
+
+// untrack stops polling the driver of the application with the given key.
+func (t *progressTracker) untrack(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if driver, ok := t.drivers[key]; ok {
+		close(driver.stopCh)
+		delete(t.drivers, key)
+	}
+}
+
+// stop stops polling all drivers.
+func (t *progressTracker) stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for key, driver := range t.drivers {
+		close(driver.stopCh)
+		delete(t.drivers, key)
+	}
+}
+
+// get returns the last progress reported by the driver of the application with the given key, or nil if
+// there is none.
+func (t *progressTracker) get(key string) *v1beta1.ApplicationProgress {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if driver, ok := t.drivers[key]; ok && driver.progress != nil {
+		return driver.progress.DeepCopy()
+	}
+	return nil
+}
+
+// getUsage returns the last resource usage reported by the driver of the application with the given key, or nil
+// if there is none.
+func (t *progressTracker) getUsage(key string) *v1beta1.ApplicationUsage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if driver, ok := t.drivers[key]; ok && driver.usage != nil {
+		return driver.usage.DeepCopy()
+	}
+	return nil
+}
+
+func (t *progressTracker) poll(key string, driver *trackedDriver) {
+	progress, err := fetchDriverProgress(t.client, driver.baseURL)
+	if err != nil {
+		glog.V(2).Infof("failed to get the progress of SparkApplication %s: %v", key, err)
+		return
+	}
+	// The usage is only recorded in the status when the run ends, so failing to get it does not hold up the
+	// progress.
+	usage, err := fetchDriverUsage(t.client, driver.baseURL)
+	if err != nil {
+		glog.V(2).Infof("failed to get the resource usage of SparkApplication %s: %v", key, err)
+	}
+
+	t.mutex.Lock()
+	if t.drivers[key] != driver {
+		// The driver has been untracked or replaced in the meantime.
+		t.mutex.Unlock()
+		return
+	}
+	changed := driver.progress == nil || !progressEqual(driver.progress, progress)
+	if changed {
+		progress.LastUpdateTime = metav1.Now()
+		driver.progress = progress
+	}
+	if usage != nil {
+		// Spark forgets executors removed long ago, so the peak seen by earlier polls is kept.
+		if driver.usage != nil && driver.usage.PeakExecutors > usage.PeakExecutors {
+			usage.PeakExecutors = driver.usage.PeakExecutors
+		}
+		usage.SnapshotTime = metav1.Now()
+		driver.usage = usage
+	}
+	t.mutex.Unlock()
+
+	if changed {
+		t.onChange(key)
+	}
+}
+
+// progressEqual tells if two progress reports are the same, disregarding the time they were last updated.
+func progressEqual(a, b *v1beta1.ApplicationProgress) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	a.LastUpdateTime, b.LastUpdateTime = metav1.Time{}, metav1.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+// fetchDriverProgress queries the REST API of the driver with the given base URL for the progress of the
+// application it runs.
+func fetchDriverProgress(client *http.Client, baseURL string) (*v1beta1.ApplicationProgress, error) {
+	appURL, err := getDriverApplicationURL(client, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []sparkJobData
+	if err := getJSON(client, appURL+"/jobs", &jobs); err != nil {
+		return nil, err
+	}
+	// All stages are fetched as the output written so far is summed over the stages that have ended as well.
+	var stages []sparkStageData
+	if err := getJSON(client, appURL+"/stages", &stages); err != nil {
+		return nil, err
+	}
+
+	progress := &v1beta1.ApplicationProgress{}
+	var doneTasks, totalTasks int64
+	for _, job := range jobs {
+		switch job.Status {
+		case "RUNNING":
+			progress.ActiveJobs++
+		case "SUCCEEDED":
+			progress.CompletedJobs++
+		case "FAILED":
+			progress.FailedJobs++
+		}
+		doneTasks += int64(job.NumCompletedTasks + job.NumSkippedTasks)
+		totalTasks += int64(job.NumTasks)
+	}
+	if totalTasks > 0 {
+		progress.PercentComplete = int32(doneTasks * 100 / totalTasks)
+	}
+	for _, stage := range stages {
+		progress.OutputBytes += stage.OutputBytes
+		progress.OutputRecords += stage.OutputRecords
+		if stage.Status != "ACTIVE" {
+			continue
+		}
+		progress.ActiveStages = append(progress.ActiveStages, v1beta1.StageProgress{
+			StageID:        stage.StageID,
+			Name:           stage.Name,
+			CompletedTasks: stage.NumCompleteTasks,
+			TotalTasks:     stage.NumTasks,
+		})
+	}
+	return progress, nil
+}
+
+// fetchDriverUsage queries the REST API of the driver with the given base URL for the resources the application
+// it runs has used so far.
+func fetchDriverUsage(client *http.Client, baseURL string) (*v1beta1.ApplicationUsage, error) {
+	appURL, err := getDriverApplicationURL(client, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	var executors []sparkExecutorSummary
+	if err := getJSON(client, appURL+"/allexecutors", &executors); err != nil {
+		return nil, err
+	}
+	var stages []sparkStageData
+	if err := getJSON(client, appURL+"/stages", &stages); err != nil {
+		return nil, err
+	}
+
+	usage := &v1beta1.ApplicationUsage{PeakExecutors: getPeakExecutors(executors)}
+	for _, stage := range stages {
+		usage.Tasks += int64(stage.NumCompleteTasks + stage.NumFailedTasks + stage.NumKilledTasks)
+		usage.InputBytes += stage.InputBytes
+		usage.OutputBytes += stage.OutputBytes
+		usage.ShuffleReadBytes += stage.ShuffleReadBytes
+		usage.ShuffleWriteBytes += stage.ShuffleWriteBytes
+	}
+	return usage, nil
+}
+
+// getPeakExecutors returns the largest number of the given executors that were running at the same time.
+// Executors whose times cannot be parsed are skipped.
+func getPeakExecutors(executors []sparkExecutorSummary) int32 {
+	type event struct {
+		time  time.Time
+		delta int32
+	}
+	var events []event
+	for _, executor := range executors {
+		if executor.ID == "driver" {
+			continue
+		}
+		added, err := time.Parse(sparkDateLayout, executor.AddTime)
+		if err != nil {
+			continue
+		}
+		events = append(events, event{time: added, delta: 1})
+		if removed, err := time.Parse(sparkDateLayout, executor.RemoveTime); err == nil {
+			events = append(events, event{time: removed, delta: -1})
+		}
+	}
+	// Executors removed when others are added are not counted together.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].time.Equal(events[j].time) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].time.Before(events[j].time)
+	})
+	var running, peak int32
+	for _, e := range events {
+		running += e.delta
+		if running > peak {
+			peak = running
+		}
+	}
+	return peak
+}
+
+// getDriverApplicationURL returns the URL of the application in the REST API of the driver with the given base
+// URL.
+func getDriverApplicationURL(client *http.Client, baseURL string) (string, error) {
+	var apps []sparkApplicationInfo
+	if err := getJSON(client, baseURL+"/api/v1/applications", &apps); err != nil {
+		return "", err
+	}
+	if len(apps) == 0 {
+		return "", fmt.Errorf("no application found at %s", baseURL)
+	}
+	return fmt.Sprintf("%s/api/v1/applications/%s", baseURL, url.PathEscape(apps[0].ID)), nil
+}
+
+func getJSON(client *http.Client, endpoint string, v interface{}) error {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return fmt.Errorf("GET %s: got status %s", endpoint, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: failed to decode response: %v", endpoint, err)
+	}
+	return nil
+}
+
+// updateProgress starts or stops tracking the progress of the given application depending on its state, and
+// records the last reported progress in the status of the application. The last reported resource usage is
+// recorded when the run ends.
+func (c *Controller) updateProgress(app *v1beta1.SparkApplication, driver *driverState) {
+	if c.progress == nil {
+		return
+	}
+
+	key := getApplicationKey(app.Namespace, app.Name)
+	if app.Status.AppState.State != v1beta1.RunningState || driver == nil || driver.podIP == "" {
+		if app.Status.AppState.State != v1beta1.RunningState {
+			if usage := c.progress.getUsage(key); usage != nil {
+				app.Status.Usage = usage
+			}
+		}
+		c.progress.untrack(key)
+		return
+	}
+	c.progress.track(key, fmt.Sprintf("http://%s:%s", driver.podIP, getUITargetPort(app)))
+	if progress := c.progress.get(key); progress != nil {
+		app.Status.Progress = progress
+	}
+}
+
+func getApplicationKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
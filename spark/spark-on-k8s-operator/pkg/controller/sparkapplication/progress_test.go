@@ -0,0 +1,178 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func newFakeDriverServer(jobs string, stages string, executors string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/applications", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "spark-123", "name": "foo"}]`)
+	})
+	mux.HandleFunc("/api/v1/applications/spark-123/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, jobs)
+	})
+	mux.HandleFunc("/api/v1/applications/spark-123/stages", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, stages)
+	})
+	mux.HandleFunc("/api/v1/applications/spark-123/allexecutors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, executors)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetchDriverProgress(t *testing.T) {
+	server := newFakeDriverServer(`[
+		{"jobId": 1, "status": "RUNNING", "numTasks": 100, "numCompletedTasks": 20, "numSkippedTasks": 10},
+		{"jobId": 0, "status": "SUCCEEDED", "numTasks": 50, "numCompletedTasks": 50, "numSkippedTasks": 0}
+	]`, `[
+		{"stageId": 3, "attemptId": 0, "status": "ACTIVE", "name": "count at Foo.scala:12", "numTasks": 70,
+			"numCompleteTasks": 20, "outputBytes": 1024, "outputRecords": 10},
+		{"stageId": 2, "attemptId": 0, "status": "COMPLETE", "name": "save at Foo.scala:10", "numTasks": 50,
+			"numCompleteTasks": 50, "outputBytes": 4096, "outputRecords": 40}
+	]`, `[]`)
+	defer server.Close()
+
+	progress, err := fetchDriverProgress(http.DefaultClient, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(53), progress.PercentComplete)
+	assert.Equal(t, int32(1), progress.ActiveJobs)
+	assert.Equal(t, int32(1), progress.CompletedJobs)
+	assert.Equal(t, int32(0), progress.FailedJobs)
+	assert.Equal(t, []v1beta1.StageProgress{
+		{StageID: 3, Name: "count at Foo.scala:12", CompletedTasks: 20, TotalTasks: 70},
+	}, progress.ActiveStages)
+	assert.Equal(t, int64(5120), progress.OutputBytes)
+	assert.Equal(t, int64(50), progress.OutputRecords)
+
+	// No jobs have been started yet.
+	empty := newFakeDriverServer(`[]`, `[]`, `[]`)
+	defer empty.Close()
+	progress, err = fetchDriverProgress(http.DefaultClient, empty.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(0), progress.PercentComplete)
+	assert.Nil(t, progress.ActiveStages)
+
+	unavailable := httptest.NewServer(http.NotFoundHandler())
+	defer unavailable.Close()
+	_, err = fetchDriverProgress(http.DefaultClient, unavailable.URL)
+	assert.NotNil(t, err)
+}
+
+func TestProgressTracker(t *testing.T) {
+	server := newFakeDriverServer(`[{"jobId": 0, "status": "RUNNING", "numTasks": 4, "numCompletedTasks": 1}]`, `[]`,
+		`[{"id": "1", "addTime": "2024-01-01T00:00:00.000GMT"}]`)
+	defer server.Close()
+
+	changes := make(chan string, 10)
+	tracker := newProgressTracker(10*time.Millisecond, func(key string) { changes <- key })
+	defer tracker.stop()
+
+	assert.Nil(t, tracker.get("test/foo"))
+	tracker.track("test/foo", server.URL)
+	select {
+	case key := <-changes:
+		assert.Equal(t, "test/foo", key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the progress to be reported")
+	}
+	progress := tracker.get("test/foo")
+	if assert.NotNil(t, progress) {
+		assert.Equal(t, int32(25), progress.PercentComplete)
+		assert.False(t, progress.LastUpdateTime.IsZero())
+	}
+	usage := tracker.getUsage("test/foo")
+	if assert.NotNil(t, usage) {
+		assert.Equal(t, int32(1), usage.PeakExecutors)
+		assert.False(t, usage.SnapshotTime.IsZero())
+	}
+
+	// Unchanged progress is not reported again.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, len(changes))
+
+	tracker.untrack("test/foo")
+	assert.Nil(t, tracker.get("test/foo"))
+	assert.Nil(t, tracker.getUsage("test/foo"))
+}
+
+func TestFetchDriverUsage(t *testing.T) {
+	server := newFakeDriverServer(`[]`, `[
+		{"stageId": 3, "attemptId": 0, "status": "ACTIVE", "numTasks": 70, "numCompleteTasks": 20,
+			"numFailedTasks": 2, "numKilledTasks": 1, "inputBytes": 100, "outputBytes": 1024,
+			"shuffleReadBytes": 300, "shuffleWriteBytes": 0},
+		{"stageId": 2, "attemptId": 0, "status": "COMPLETE", "numTasks": 50, "numCompleteTasks": 50,
+			"inputBytes": 2048, "outputBytes": 0, "shuffleReadBytes": 0, "shuffleWriteBytes": 300}
+	]`, `[
+		{"id": "driver", "addTime": "2024-01-01T00:00:00.000GMT"},
+		{"id": "1", "addTime": "2024-01-01T00:00:01.000GMT", "removeTime": "2024-01-01T00:05:00.000GMT"},
+		{"id": "2", "addTime": "2024-01-01T00:00:01.000GMT", "removeTime": "2024-01-01T00:10:00.000GMT"},
+		{"id": "3", "addTime": "2024-01-01T00:05:00.000GMT"},
+		{"id": "4", "addTime": "2024-01-01T00:10:00.000GMT"}
+	]`)
+	defer server.Close()
+
+	usage, err := fetchDriverUsage(http.DefaultClient, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Executors replacing removed ones do not add to the peak.
+	assert.Equal(t, &v1beta1.ApplicationUsage{
+		PeakExecutors:     2,
+		Tasks:             73,
+		InputBytes:        2148,
+		OutputBytes:       1024,
+		ShuffleReadBytes:  300,
+		ShuffleWriteBytes: 300,
+	}, usage)
+}
+
+func TestUpdateProgressRecordsUsage(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.SucceedingState},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	ctrl.progress = newProgressTracker(time.Hour, func(string) {})
+	usage := &v1beta1.ApplicationUsage{PeakExecutors: 3, Tasks: 100}
+	ctrl.progress.drivers["test/foo"] = &trackedDriver{stopCh: make(chan struct{}), usage: usage}
+
+	ctrl.updateProgress(app, nil)
+	assert.Equal(t, usage, app.Status.Usage)
+	assert.Nil(t, ctrl.progress.getUsage("test/foo"))
+
+	// The usage is kept once the driver is no longer tracked.
+	ctrl.updateProgress(app, nil)
+	assert.Equal(t, usage, app.Status.Usage)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// hadoopUserNameDriverEnvKey is the Spark configuration key of the environment variable of the driver that sets
+// the Hadoop user without Kerberos.
+const hadoopUserNameDriverEnvKey = "spark.kubernetes.driverEnv.HADOOP_USER_NAME"
+
+// SetProxyUserSettings sets the users applications may run as through spark-submit --proxy-user, and the Hadoop
+// superuser impersonating them, for applications submitted from then on.
+func (c *Controller) SetProxyUserSettings(allowed []string, superuser string) {
+	c.defaultsMutex.Lock()
+	defer c.defaultsMutex.Unlock()
+	c.allowedProxyUsers = allowed
+	c.proxyUserSuperuser = superuser
+}
+
+func isProxyUserAllowed(user string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == user || a == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProxyUser checks that the proxy user of the given application is allowed, and sets the Hadoop superuser
+// of its driver unless the application sets it.
+func (c *Controller) applyProxyUser(app *v1beta1.SparkApplication) error {
+	if app.Spec.ProxyUser == nil {
+		return nil
+	}
+	c.defaultsMutex.RLock()
+	defer c.defaultsMutex.RUnlock()
+	if !isProxyUserAllowed(*app.Spec.ProxyUser, c.allowedProxyUsers) {
+		return fmt.Errorf("proxy user %q is not allowed by the operator", *app.Spec.ProxyUser)
+	}
+	if c.proxyUserSuperuser == "" {
+		return nil
+	}
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	if _, ok := app.Spec.SparkConf[hadoopUserNameDriverEnvKey]; !ok {
+		app.Spec.SparkConf[hadoopUserNameDriverEnvKey] = c.proxyUserSuperuser
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestIsProxyUserAllowed(t *testing.T) {
+	assert.False(t, isProxyUserAllowed("alice", nil))
+	assert.True(t, isProxyUserAllowed("alice", []string{"bob", "alice"}))
+	assert.False(t, isProxyUserAllowed("carol", []string{"bob", "alice"}))
+	assert.True(t, isProxyUserAllowed("carol", []string{"*"}))
+}
+
+func TestApplyProxyUser(t *testing.T) {
+	c := &Controller{}
+	app := &v1beta1.SparkApplication{}
+
+	// Applications without a proxy user are left alone.
+	assert.Nil(t, c.applyProxyUser(app))
+	assert.Nil(t, app.Spec.SparkConf)
+
+	user := "alice"
+	app.Spec.ProxyUser = &user
+	assert.NotNil(t, c.applyProxyUser(app))
+
+	c.SetProxyUserSettings([]string{"alice"}, "")
+	assert.Nil(t, c.applyProxyUser(app))
+	assert.Nil(t, app.Spec.SparkConf)
+
+	c.SetProxyUserSettings([]string{"alice"}, "hive")
+	assert.Nil(t, c.applyProxyUser(app))
+	assert.Equal(t, map[string]string{hadoopUserNameDriverEnvKey: "hive"}, app.Spec.SparkConf)
+
+	// The superuser set by the application is kept.
+	app.Spec.SparkConf[hadoopUserNameDriverEnvKey] = "spark"
+	assert.Nil(t, c.applyProxyUser(app))
+	assert.Equal(t, "spark", app.Spec.SparkConf[hadoopUserNameDriverEnvKey])
+}
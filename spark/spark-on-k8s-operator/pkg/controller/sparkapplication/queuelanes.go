@@ -0,0 +1,406 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// queueLane is a lane of the work queue of the controller. The lower a lane, the higher its priority.
+type queueLane int
+
+const (
+	// submissionLane has applications waiting to be submitted.
+	submissionLane queueLane = iota
+	// statusLane has submitted applications, whose status follows their pods.
+	statusLane
+	// cleanupLane has applications whose run has ended or is being invalidated, and deleted applications.
+	cleanupLane
+)
+
+// queueLanes has the name and the rate limit of adds of each lane, and whether namespaces take turns in it.
+var queueLanes = []struct {
+	name       string
+	refillRate float64
+	bucketSize int
+	fair       bool
+}{
+	submissionLane: {name: "submission", refillRate: 50, bucketSize: 500, fair: true},
+	statusLane:     {name: "status", refillRate: 50, bucketSize: 500},
+	cleanupLane:    {name: "cleanup", refillRate: 20, bucketSize: 200},
+}
+
+func (l queueLane) String() string {
+	return queueLanes[l].name
+}
+
+const (
+	queueLaneLabel = "lane"
+	namespaceLabel = "namespace"
+)
+
+type laneQueueMetrics struct {
+	depth          *prometheus.GaugeVec
+	latency        *prometheus.HistogramVec
+	submissionWait *prometheus.HistogramVec
+}
+
+func newLaneQueueMetrics(prefix string) *laneQueueMetrics {
+	depth := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: util.CreateValidMetricNameLabel(prefix, "spark_app_work_queue_depth"),
+			Help: "Spark App Keys Waiting in a Lane of the Work Queue of the Operator",
+		},
+		[]string{queueLaneLabel},
+	)
+	latency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    util.CreateValidMetricNameLabel(prefix, "spark_app_work_queue_latency_seconds"),
+			Help:    "Time Spark App Keys Spent Waiting in a Lane of the Work Queue of the Operator",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{queueLaneLabel},
+	)
+	submissionWait := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    util.CreateValidMetricNameLabel(prefix, "spark_app_submission_queue_wait_seconds"),
+			Help:    "Time Spark Apps Spent Waiting in the Submission Lane of the Work Queue of the Operator by Namespace",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{namespaceLabel},
+	)
+	return &laneQueueMetrics{depth: depth, latency: latency, submissionWait: submissionWait}
+}
+
+func (m *laneQueueMetrics) registerMetrics() {
+	util.RegisterMetric(m.depth)
+	util.RegisterMetric(m.latency)
+	util.RegisterMetric(m.submissionWait)
+}
+
+// laneKeys are the keys waiting in a lane. Keys are grouped by namespace in fair lanes, and the namespaces take
+// turns in the order they got keys, so a burst of applications in one namespace does not hold up the others.
+// Other lanes have all keys in one group, in the order they were added.
+type laneKeys struct {
+	// namespaces are the namespaces with waiting keys, in the order of their turns.
+	namespaces []string
+	keys       map[string][]interface{}
+	n          int
+}
+
+func newLaneKeys() *laneKeys {
+	return &laneKeys{keys: make(map[string][]interface{})}
+}
+
+func (l *laneKeys) len() int {
+	return l.n
+}
+
+func (l *laneKeys) push(namespace string, key interface{}) {
+	if len(l.keys[namespace]) == 0 {
+		l.namespaces = append(l.namespaces, namespace)
+	}
+	l.keys[namespace] = append(l.keys[namespace], key)
+	l.n++
+}
+
+// pop removes and returns the first key of the namespace whose turn it is, which moves to the end of the turns
+// if it has more keys.
+func (l *laneKeys) pop() interface{} {
+	namespace := l.namespaces[0]
+	l.namespaces = l.namespaces[1:]
+	keys := l.keys[namespace]
+	key := keys[0]
+	if len(keys) > 1 {
+		l.keys[namespace] = keys[1:]
+		l.namespaces = append(l.namespaces, namespace)
+	} else {
+		delete(l.keys, namespace)
+	}
+	l.n--
+	return key
+}
+
+func (l *laneKeys) remove(namespace string, key interface{}) {
+	keys := l.keys[namespace]
+	for i := range keys {
+		if keys[i] != key {
+			continue
+		}
+		l.n--
+		if len(keys) > 1 {
+			l.keys[namespace] = append(keys[:i:i], keys[i+1:]...)
+			return
+		}
+		delete(l.keys, namespace)
+		for j := range l.namespaces {
+			if l.namespaces[j] == namespace {
+				l.namespaces = append(l.namespaces[:j:j], l.namespaces[j+1:]...)
+				break
+			}
+		}
+		return
+	}
+}
+
+// laneQueue is a rate-limited work queue with a lane for each kind of work, which implements
+// workqueue.RateLimitingInterface. Workers get keys from the lane with the highest priority that has any, so,
+// e.g., a flood of completing applications does not hold up new submissions, and every lane limits the rate
+// of its rate-limited adds on its own. Workers may also be dedicated to some of the lanes. Like in a workqueue, a
+// key waits in at most one lane at a time, and is processed by at most one worker at a time.
+type laneQueue struct {
+	classify func(key string) queueLane
+	limiters []workqueue.RateLimiter
+	metrics  *laneQueueMetrics
+
+	cond *sync.Cond
+	// lanes has the keys waiting in each lane.
+	lanes []*laneKeys
+	// dirty has the lane of each waiting key, including keys added while being processed, which wait in their
+	// lane once done.
+	dirty        map[interface{}]queueLane
+	addTimes     map[interface{}]time.Time
+	processing   map[interface{}]bool
+	shuttingDown bool
+}
+
+var _ workqueue.RateLimitingInterface = &laneQueue{}
+
+// newLaneQueue creates a laneQueue adding keys to the lane classify returns for them.
+func newLaneQueue(classify func(key string) queueLane) *laneQueue {
+	q := &laneQueue{
+		classify:   classify,
+		cond:       sync.NewCond(&sync.Mutex{}),
+		dirty:      make(map[interface{}]queueLane),
+		addTimes:   make(map[interface{}]time.Time),
+		processing: make(map[interface{}]bool),
+	}
+	for _, lane := range queueLanes {
+		q.lanes = append(q.lanes, newLaneKeys())
+		q.limiters = append(q.limiters, &workqueue.BucketRateLimiter{
+			Limiter: rate.NewLimiter(rate.Limit(lane.refillRate), lane.bucketSize),
+		})
+	}
+	return q
+}
+
+// Add adds the given key to the lane of its application. A key already waiting stays in its lane unless the
+// new lane has a higher priority.
+func (q *laneQueue) Add(key interface{}) {
+	lane := q.getLane(key)
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	current, waiting := q.dirty[key]
+	if waiting && current <= lane {
+		return
+	}
+	q.dirty[key] = lane
+	if q.processing[key] {
+		return
+	}
+	if waiting {
+		q.remove(current, key)
+	} else {
+		q.addTimes[key] = time.Now()
+	}
+	q.push(lane, key)
+}
+
+// Get blocks until a key is waiting, and returns the next key of the lane with the highest priority that has
+// any. Done must be called once the key has been processed.
+func (q *laneQueue) Get() (interface{}, bool) {
+	return q.getFrom(nil)
+}
+
+// getFrom is like Get, but only gets keys from the given lanes, or from all lanes if none are given.
+func (q *laneQueue) getFrom(lanes []queueLane) (interface{}, bool) {
+	if len(lanes) == 0 {
+		for lane := range queueLanes {
+			lanes = append(lanes, queueLane(lane))
+		}
+	}
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for {
+		for _, lane := range lanes {
+			if q.lanes[lane].len() == 0 {
+				continue
+			}
+			key := q.lanes[lane].pop()
+			delete(q.dirty, key)
+			q.processing[key] = true
+			if q.metrics != nil {
+				wait := time.Since(q.addTimes[key]).Seconds()
+				q.metrics.depth.WithLabelValues(lane.String()).Dec()
+				q.metrics.latency.WithLabelValues(lane.String()).Observe(wait)
+				if lane == submissionLane {
+					q.metrics.submissionWait.WithLabelValues(getKeyNamespace(key)).Observe(wait)
+				}
+			}
+			delete(q.addTimes, key)
+			return key, false
+		}
+		if q.shuttingDown {
+			return nil, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// Done marks the given key as processed. If it was added while being processed, it waits in its lane again.
+func (q *laneQueue) Done(key interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	delete(q.processing, key)
+	if lane, ok := q.dirty[key]; ok {
+		q.addTimes[key] = time.Now()
+		q.push(lane, key)
+	}
+}
+
+// Len returns the number of keys waiting in all lanes.
+func (q *laneQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.len()
+}
+
+func (q *laneQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func (q *laneQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+func (q *laneQueue) AddAfter(key interface{}, duration time.Duration) {
+	if duration <= 0 {
+		q.Add(key)
+		return
+	}
+	time.AfterFunc(duration, func() { q.Add(key) })
+}
+
+// AddRateLimited adds the given key once the rate limiter of the lane of its application allows it.
+func (q *laneQueue) AddRateLimited(key interface{}) {
+	q.AddAfter(key, q.limiters[q.getLane(key)].When(key))
+}
+
+func (q *laneQueue) Forget(key interface{}) {
+	for _, limiter := range q.limiters {
+		limiter.Forget(key)
+	}
+}
+
+func (q *laneQueue) NumRequeues(key interface{}) int {
+	requeues := 0
+	for _, limiter := range q.limiters {
+		requeues += limiter.NumRequeues(key)
+	}
+	return requeues
+}
+
+func (q *laneQueue) getLane(key interface{}) queueLane {
+	if s, ok := key.(string); ok {
+		return q.classify(s)
+	}
+	return statusLane
+}
+
+func (q *laneQueue) len() int {
+	n := 0
+	for _, keys := range q.lanes {
+		n += keys.len()
+	}
+	return n
+}
+
+func (q *laneQueue) push(lane queueLane, key interface{}) {
+	q.lanes[lane].push(getLaneNamespace(lane, key), key)
+	if q.metrics != nil {
+		q.metrics.depth.WithLabelValues(lane.String()).Inc()
+	}
+	// Workers may wait for keys of different lanes, so all of them are woken up.
+	q.cond.Broadcast()
+}
+
+func (q *laneQueue) remove(lane queueLane, key interface{}) {
+	q.lanes[lane].remove(getLaneNamespace(lane, key), key)
+	if q.metrics != nil {
+		q.metrics.depth.WithLabelValues(lane.String()).Dec()
+	}
+}
+
+// getLaneNamespace returns the namespace the given key is grouped by in the given lane.
+func getLaneNamespace(lane queueLane, key interface{}) string {
+	if !queueLanes[lane].fair {
+		return ""
+	}
+	return getKeyNamespace(key)
+}
+
+func getKeyNamespace(key interface{}) string {
+	if s, ok := key.(string); ok {
+		if namespace, _, err := cache.SplitMetaNamespaceKey(s); err == nil {
+			return namespace
+		}
+	}
+	return ""
+}
+
+// getQueueLane returns the lane of the work queue the application with the given key is processed in, by its
+// state in the cache.
+func (c *Controller) getQueueLane(key string) queueLane {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return statusLane
+	}
+	app, err := c.applicationLister.SparkApplications(namespace).Get(name)
+	if err != nil {
+		// Deleted applications only have their resources cleaned up.
+		return cleanupLane
+	}
+	if !app.DeletionTimestamp.IsZero() {
+		return cleanupLane
+	}
+	switch app.Status.AppState.State {
+	case v1beta1.NewState, v1beta1.QueuedState, v1beta1.PendingRerunState, v1beta1.FailedSubmissionState:
+		return submissionLane
+	case v1beta1.InvalidatingState, v1beta1.CompletedState, v1beta1.FailedState, v1beta1.SkippedState:
+		return cleanupLane
+	}
+	return statusLane
+}
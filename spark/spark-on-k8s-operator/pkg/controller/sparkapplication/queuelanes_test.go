@@ -0,0 +1,180 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestLaneQueue(t *testing.T) {
+	lanes := map[string]queueLane{
+		"default/new":       submissionLane,
+		"default/running":   statusLane,
+		"default/completed": cleanupLane,
+	}
+	q := newLaneQueue(func(key string) queueLane { return lanes[key] })
+
+	q.Add("default/completed")
+	q.Add("default/running")
+	q.Add("default/new")
+	q.Add("default/running")
+	assert.Equal(t, 3, q.Len())
+
+	// Keys are processed by the priority of their lanes.
+	key, _ := q.Get()
+	assert.Equal(t, "default/new", key)
+	q.Done(key)
+	key, _ = q.Get()
+	assert.Equal(t, "default/running", key)
+
+	// A key added while being processed waits until it is done.
+	lanes["default/running"] = submissionLane
+	q.Add("default/running")
+	key, _ = q.Get()
+	assert.Equal(t, "default/completed", key)
+	assert.Equal(t, 0, q.Len())
+	q.Done("default/running")
+	assert.Equal(t, 1, q.Len())
+	assert.Equal(t, submissionLane, q.dirty["default/running"])
+
+	// A waiting key moves up to a lane with a higher priority, but not down.
+	lanes["default/completed"] = statusLane
+	q.Done("default/completed")
+	q.Add("default/completed")
+	lanes["default/completed"] = cleanupLane
+	q.Add("default/completed")
+	assert.Equal(t, statusLane, q.dirty["default/completed"])
+	lanes["default/completed"] = submissionLane
+	q.Add("default/completed")
+	assert.Equal(t, 2, q.lanes[submissionLane].len())
+	assert.Equal(t, 0, q.lanes[statusLane].len())
+
+	q.ShutDown()
+	key, shutdown := q.Get()
+	assert.Equal(t, "default/running", key)
+	assert.False(t, shutdown)
+	q.Get()
+	key, shutdown = q.Get()
+	assert.Nil(t, key)
+	assert.True(t, shutdown)
+}
+
+func TestLaneQueueFairness(t *testing.T) {
+	q := newLaneQueue(func(key string) queueLane {
+		if strings.HasSuffix(key, "-running") {
+			return statusLane
+		}
+		return submissionLane
+	})
+
+	// A burst of applications in one namespace does not hold up the applications of others.
+	for i := 0; i < 3; i++ {
+		q.Add(fmt.Sprintf("team-a/app-%d", i))
+	}
+	q.Add("team-b/app-0")
+	q.Add("team-c/app-0")
+	q.Add("team-b/app-1")
+	q.Add("team-a/app-running")
+	q.Add("team-b/app-running")
+
+	var keys []interface{}
+	for q.lanes[submissionLane].len() > 0 {
+		key, _ := q.Get()
+		keys = append(keys, key)
+		q.Done(key)
+	}
+	assert.Equal(t, []interface{}{"team-a/app-0", "team-b/app-0", "team-c/app-0", "team-a/app-1", "team-b/app-1",
+		"team-a/app-2"}, keys)
+
+	// Other lanes are processed in the order keys were added.
+	key, _ := q.Get()
+	assert.Equal(t, "team-a/app-running", key)
+
+	// Namespaces take turns in the order they got keys since their last turn.
+	q.Add("team-c/app-1")
+	q.Add("team-b/app-2")
+	q.Add("team-c/app-2")
+	assert.Equal(t, []string{"team-c", "team-b"}, q.lanes[submissionLane].namespaces)
+	q.remove(submissionLane, "team-b/app-2")
+	assert.Equal(t, []string{"team-c"}, q.lanes[submissionLane].namespaces)
+	assert.Equal(t, 2, q.lanes[submissionLane].len())
+}
+
+func TestLaneQueueGetFrom(t *testing.T) {
+	lanes := map[string]queueLane{
+		"default/new":     submissionLane,
+		"default/running": statusLane,
+	}
+	q := newLaneQueue(func(key string) queueLane { return lanes[key] })
+	q.Add("default/new")
+
+	got := make(chan interface{})
+	go func() {
+		key, _ := q.getFrom([]queueLane{statusLane, cleanupLane})
+		got <- key
+	}()
+	select {
+	case key := <-got:
+		t.Fatalf("got %v from the status and cleanup lanes", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+	q.Add("default/running")
+	assert.Equal(t, "default/running", <-got)
+
+	key, _ := q.getFrom([]queueLane{submissionLane})
+	assert.Equal(t, "default/new", key)
+}
+
+func TestGetQueueLane(t *testing.T) {
+	now := metav1.Now()
+	testcases := []struct {
+		name     string
+		state    v1beta1.ApplicationStateType
+		deleted  bool
+		expected queueLane
+	}{
+		{name: "new", state: v1beta1.NewState, expected: submissionLane},
+		{name: "pending-rerun", state: v1beta1.PendingRerunState, expected: submissionLane},
+		{name: "running", state: v1beta1.RunningState, expected: statusLane},
+		{name: "succeeding", state: v1beta1.SucceedingState, expected: statusLane},
+		{name: "invalidating", state: v1beta1.InvalidatingState, expected: cleanupLane},
+		{name: "completed", state: v1beta1.CompletedState, expected: cleanupLane},
+		{name: "deleted", state: v1beta1.RunningState, deleted: true, expected: cleanupLane},
+	}
+	for _, test := range testcases {
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: test.name, Namespace: "default"},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{State: test.state},
+			},
+		}
+		if test.deleted {
+			app.DeletionTimestamp = &now
+		}
+		ctrl, _ := newFakeController(app)
+		assert.Equal(t, test.expected, ctrl.getQueueLane("default/"+test.name), test.name)
+		assert.Equal(t, cleanupLane, ctrl.getQueueLane("default/missing"), test.name)
+	}
+}
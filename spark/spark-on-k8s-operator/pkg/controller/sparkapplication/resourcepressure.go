@@ -0,0 +1,71 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"math"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// summarizeResourcePressure returns the summary of the resource pressure samples the node agent has recorded on
+// the given executor pods of an application, or nil if none has one.
+func summarizeResourcePressure(pods []*apiv1.Pod) *v1beta1.ResourcePressureSummary {
+	summary := &v1beta1.ResourcePressureSummary{}
+	var elapsed, periods, throttledPeriods, cpuPressure, memoryPressure int64
+	for _, pod := range pods {
+		value, ok := pod.Annotations[config.ResourcePressureAnnotation]
+		if !ok || !util.IsExecutorPod(pod) {
+			continue
+		}
+		sample, err := config.ParseResourcePressureSample(value)
+		if err != nil {
+			glog.Warningf("ignoring the resource pressure of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		summary.SampledExecutors++
+		elapsed += sample.ElapsedMicros
+		periods += sample.CPUPeriods
+		throttledPeriods += sample.CPUThrottledPeriods
+		cpuPressure += sample.CPUPressureMicros
+		memoryPressure += sample.MemoryPressureMicros
+		if sample.SampleTime.After(summary.LastSampleTime.Time) {
+			summary.LastSampleTime = metav1.NewTime(sample.SampleTime)
+		}
+	}
+	if summary.SampledExecutors == 0 {
+		return nil
+	}
+	summary.CPUThrottledPercent = getPercentage(throttledPeriods, periods)
+	summary.CPUPressurePercent = getPercentage(cpuPressure, elapsed)
+	summary.MemoryPressurePercent = getPercentage(memoryPressure, elapsed)
+	return summary
+}
+
+// getPercentage returns the given part of the given total in percent, rounded to one decimal place.
+func getPercentage(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Min(100, math.Round(float64(part)*1000/float64(total))/10)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestSummarizeResourcePressure(t *testing.T) {
+	newPod := func(name, role, sample string) *apiv1.Pod {
+		pod := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{config.SparkRoleLabel: role},
+		}}
+		if sample != "" {
+			pod.Annotations = map[string]string{config.ResourcePressureAnnotation: sample}
+		}
+		return pod
+	}
+
+	assert.Nil(t, summarizeResourcePressure(nil))
+	assert.Nil(t, summarizeResourcePressure([]*apiv1.Pod{newPod("exec-1", config.SparkExecutorRole, "")}))
+
+	pods := []*apiv1.Pod{
+		newPod("driver", config.SparkDriverRole, `{"sampleTime":"2020-01-01T00:10:00Z","elapsedMicros":1000}`),
+		newPod("exec-1", config.SparkExecutorRole, `{"sampleTime":"2020-01-01T00:01:00Z","elapsedMicros":60000000,`+
+			`"cpuPeriods":600,"cpuThrottledPeriods":300,"cpuPressureMicros":30000000,"memoryPressureMicros":0}`),
+		newPod("exec-2", config.SparkExecutorRole, `{"sampleTime":"2020-01-01T00:02:00Z","elapsedMicros":60000000,`+
+			`"cpuPeriods":400,"cpuThrottledPeriods":0,"cpuPressureMicros":0,"memoryPressureMicros":1000000}`),
+		newPod("exec-3", config.SparkExecutorRole, `not json`),
+		newPod("exec-4", config.SparkExecutorRole, ""),
+	}
+	summary := summarizeResourcePressure(pods)
+	assert.NotNil(t, summary)
+	assert.Equal(t, int32(2), summary.SampledExecutors)
+	assert.Equal(t, 30.0, summary.CPUThrottledPercent)
+	assert.Equal(t, 25.0, summary.CPUPressurePercent)
+	assert.Equal(t, 0.8, summary.MemoryPressurePercent)
+	assert.True(t, summary.LastSampleTime.Equal(&metav1.Time{Time: time.Date(2020, 1, 1, 0, 2, 0, 0, time.UTC)}))
+}
+
+func TestGetPercentage(t *testing.T) {
+	assert.Equal(t, 0.0, getPercentage(1, 0))
+	assert.Equal(t, 33.3, getPercentage(1, 3))
+	assert.Equal(t, 66.7, getPercentage(2, 3))
+	// Pressure stall times can exceed the elapsed time of the sample slightly.
+	assert.Equal(t, 100.0, getPercentage(11, 10))
+}
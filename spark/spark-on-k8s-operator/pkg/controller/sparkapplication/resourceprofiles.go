@@ -0,0 +1,88 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// addResourceProfileConfOptions returns the Spark configuration properties describing the executor groups and
+// resource profiles of the given app, from which the application builds its resource profiles. Executors of
+// resource profiles other than the default one are only launched with dynamic allocation, so it is enabled
+// together with shuffle tracking unless the application configures them itself.
+func addResourceProfileConfOptions(app *v1beta1.SparkApplication) []string {
+	if len(app.Spec.ExecutorGroups) == 0 && len(app.Spec.ResourceProfiles) == 0 {
+		return nil
+	}
+
+	var options []string
+	for i, group := range app.Spec.ExecutorGroups {
+		prefix := config.SparkExecutorGroupKeyPrefix + group.Name + "."
+		options = append(options,
+			fmt.Sprintf("%sresourceProfileId=%d", prefix, util.GetExecutorGroupResourceProfileID(app, i)))
+		if group.Instances != nil {
+			options = append(options, fmt.Sprintf("%sinstances=%d", prefix, *group.Instances))
+		}
+		options = append(options,
+			getExecutorResourceConfOptions(prefix, group.Cores, group.Memory, group.MemoryOverhead, group.GPU)...)
+	}
+	for _, profile := range app.Spec.ResourceProfiles {
+		prefix := fmt.Sprintf("%s%d.", config.SparkResourceProfileKeyPrefix, profile.ID)
+		options = append(options, getExecutorResourceConfOptions(prefix+"executor.", profile.Cores, profile.Memory,
+			profile.MemoryOverhead, profile.GPU)...)
+		if profile.TaskCPUs != nil {
+			options = append(options, fmt.Sprintf("%stask.cpus=%d", prefix, *profile.TaskCPUs))
+		}
+	}
+
+	if _, ok := app.Spec.SparkConf[config.SparkDynamicAllocationEnabledKey]; !ok {
+		options = append(options, fmt.Sprintf("%s=true", config.SparkDynamicAllocationEnabledKey))
+		if _, ok := app.Spec.SparkConf[config.SparkDynamicAllocationShuffleTrackingKey]; !ok {
+			options = append(options, fmt.Sprintf("%s=true", config.SparkDynamicAllocationShuffleTrackingKey))
+		}
+	}
+	return options
+}
+
+// getExecutorResourceConfOptions returns the Spark configuration properties with the given key prefix describing
+// the given executor resources.
+func getExecutorResourceConfOptions(prefix string, cores *int32, memory, memoryOverhead *string,
+	gpu *v1beta1.GPUSpec) []string {
+	var options []string
+	if cores != nil {
+		options = append(options, fmt.Sprintf("%scores=%d", prefix, *cores))
+	}
+	if memory != nil {
+		options = append(options, fmt.Sprintf("%smemory=%s", prefix, *memory))
+	}
+	if memoryOverhead != nil {
+		options = append(options, fmt.Sprintf("%smemoryOverhead=%s", prefix, *memoryOverhead))
+	}
+	if gpu != nil {
+		options = append(options, fmt.Sprintf("%sgpu.amount=%d", prefix, gpu.Quantity))
+		// The vendor is the domain of the extended resource name, e.g., "nvidia.com" for "nvidia.com/gpu".
+		if slash := strings.Index(gpu.Name, "/"); slash > 0 {
+			options = append(options, fmt.Sprintf("%sgpu.vendor=%s", prefix, gpu.Name[:slash]))
+		}
+	}
+	return options
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestAddResourceProfileConfOptions(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+	assert.Equal(t, 0, len(addResourceProfileConfOptions(app)))
+
+	instances := int32(2)
+	cores := int32(4)
+	memory := "16g"
+	profileID := int32(7)
+	app.Spec.ExecutorGroups = []v1beta1.ExecutorGroup{
+		{
+			Name:      "highmem",
+			Instances: &instances,
+			Memory:    &memory,
+		},
+		{
+			Name:              "gpu",
+			ResourceProfileID: &profileID,
+			Cores:             &cores,
+			GPU:               &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 1},
+		},
+	}
+	assert.Equal(t, []string{
+		"spark.sparkoperator.executorGroup.highmem.resourceProfileId=1",
+		"spark.sparkoperator.executorGroup.highmem.instances=2",
+		"spark.sparkoperator.executorGroup.highmem.memory=16g",
+		"spark.sparkoperator.executorGroup.gpu.resourceProfileId=7",
+		"spark.sparkoperator.executorGroup.gpu.cores=4",
+		"spark.sparkoperator.executorGroup.gpu.gpu.amount=1",
+		"spark.sparkoperator.executorGroup.gpu.gpu.vendor=nvidia.com",
+		"spark.dynamicAllocation.enabled=true",
+		"spark.dynamicAllocation.shuffleTracking.enabled=true",
+	}, addResourceProfileConfOptions(app))
+
+	taskCPUs := int32(2)
+	app.Spec.ExecutorGroups = nil
+	app.Spec.ResourceProfiles = []v1beta1.ResourceProfile{
+		{
+			ID:       1,
+			Cores:    &cores,
+			Memory:   &memory,
+			TaskCPUs: &taskCPUs,
+		},
+	}
+	assert.Equal(t, []string{
+		"spark.sparkoperator.resourceProfile.1.executor.cores=4",
+		"spark.sparkoperator.resourceProfile.1.executor.memory=16g",
+		"spark.sparkoperator.resourceProfile.1.task.cpus=2",
+		"spark.dynamicAllocation.enabled=true",
+		"spark.dynamicAllocation.shuffleTracking.enabled=true",
+	}, addResourceProfileConfOptions(app))
+
+	// Dynamic allocation configured by the application is left alone.
+	app.Spec.SparkConf = map[string]string{"spark.dynamicAllocation.enabled": "true"}
+	app.Spec.ResourceProfiles = nil
+	app.Spec.ExecutorGroups = []v1beta1.ExecutorGroup{
+		{
+			Name:      "highmem",
+			Instances: &instances,
+			Memory:    &memory,
+		},
+	}
+	assert.Equal(t, []string{
+		"spark.sparkoperator.executorGroup.highmem.resourceProfileId=1",
+		"spark.sparkoperator.executorGroup.highmem.instances=2",
+		"spark.sparkoperator.executorGroup.highmem.memory=16g",
+	}, addResourceProfileConfOptions(app))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const rotationWindowTimeLayout = "15:04"
+
+// getRotationTime returns when the current run of the given application is due to be rotated, if the
+// application has a rotation policy.
+func getRotationTime(app *v1beta1.SparkApplication) (time.Time, bool, error) {
+	rotation := app.Spec.Rotation
+	if rotation == nil || rotation.MaxRuntimeBeforeRotation <= 0 || app.Status.LastSubmissionAttemptTime.IsZero() {
+		return time.Time{}, false, nil
+	}
+	due := app.Status.LastSubmissionAttemptTime.Add(time.Duration(rotation.MaxRuntimeBeforeRotation) * time.Second).UTC()
+	if rotation.Window == nil {
+		return due, true, nil
+	}
+	start, length, err := parseRotationWindow(*rotation.Window)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return getNextTimeInWindow(due, start, length), true, nil
+}
+
+// parseRotationWindow parses a "HH:MM-HH:MM" window into its start as an offset from midnight and its length.
+// Windows may span midnight, e.g., "23:00-01:00".
+func parseRotationWindow(window string) (time.Duration, time.Duration, error) {
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid rotation window %q, expected HH:MM-HH:MM", window)
+	}
+	start, err := time.Parse(rotationWindowTimeLayout, strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start of rotation window %q: %v", window, err)
+	}
+	end, err := time.Parse(rotationWindowTimeLayout, strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end of rotation window %q: %v", window, err)
+	}
+	startOffset := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+	length := end.Sub(start)
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+	return startOffset, length, nil
+}
+
+// getNextTimeInWindow returns the earliest time not before t that falls into the daily window with the given
+// start and length.
+func getNextTimeInWindow(t time.Time, start time.Duration, length time.Duration) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	// The window starting the day before may still be open if it spans midnight.
+	for day := -1; day <= 1; day++ {
+		windowStart := midnight.AddDate(0, 0, day).Add(start)
+		if t.Before(windowStart) {
+			return windowStart
+		}
+		if t.Before(windowStart.Add(length)) {
+			return t
+		}
+	}
+	return midnight.AddDate(0, 0, 2).Add(start)
+}
+
+// rotateSparkApplication gracefully terminates the driver of the given running application and invalidates
+// the current run, so that the application gets re-submitted once the driver is gone.
+func (c *Controller) rotateSparkApplication(app *v1beta1.SparkApplication) error {
+	if driverPodName := app.Status.DriverInfo.PodName; driverPodName != "" {
+		if err := c.terminateDriverPod(app, driverPodName); err != nil {
+			return err
+		}
+	}
+	glog.Infof("SparkApplication %s/%s reached its maximum runtime, rotating it", app.Namespace, app.Name)
+	c.recorder.Eventf(
+		app,
+		apiv1.EventTypeNormal,
+		"SparkApplicationRotated",
+		"SparkApplication %s reached its maximum runtime and is being restarted",
+		app.Name)
+	app.Status.AppState.State = v1beta1.InvalidatingState
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetRotationTime(t *testing.T) {
+	submitted := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	app := &v1beta1.SparkApplication{
+		Status: v1beta1.SparkApplicationStatus{LastSubmissionAttemptTime: metav1.NewTime(submitted)},
+	}
+	_, rotates, err := getRotationTime(app)
+	assert.Nil(t, err)
+	assert.False(t, rotates)
+
+	app.Spec.Rotation = &v1beta1.RotationPolicy{MaxRuntimeBeforeRotation: 24 * 3600}
+	rotationTime, rotates, err := getRotationTime(app)
+	assert.Nil(t, err)
+	assert.True(t, rotates)
+	assert.Equal(t, submitted.Add(24*time.Hour), rotationTime)
+
+	// The rotation is postponed to the next window.
+	window := "02:00-04:00"
+	app.Spec.Rotation.Window = &window
+	rotationTime, _, err = getRotationTime(app)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2019, 3, 3, 2, 0, 0, 0, time.UTC), rotationTime)
+
+	// The window is already open when the rotation is due.
+	window = "11:00-13:00"
+	rotationTime, _, err = getRotationTime(app)
+	assert.Nil(t, err)
+	assert.Equal(t, submitted.Add(24*time.Hour), rotationTime)
+
+	window = "22:00-12:30"
+	app.Spec.Rotation.MaxRuntimeBeforeRotation = 3600
+	rotationTime, _, err = getRotationTime(app)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2019, 3, 1, 22, 0, 0, 0, time.UTC), rotationTime)
+
+	window = "23:30-00:30"
+	app.Spec.Rotation.MaxRuntimeBeforeRotation = 12*3600 + 15*60
+	rotationTime, _, err = getRotationTime(app)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2019, 3, 2, 0, 15, 0, 0, time.UTC), rotationTime)
+
+	window = "2am-4am"
+	_, _, err = getRotationTime(app)
+	assert.NotNil(t, err)
+}
+
+func TestSyncSparkApplication_Rotation(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Rotation: &v1beta1.RotationPolicy{MaxRuntimeBeforeRotation: 3600},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.RunningState},
+			DriverInfo:                v1beta1.DriverInfo{PodName: "foo-driver"},
+			LastSubmissionAttemptTime: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			ExecutionAttempts:         1,
+		},
+	}
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-driver",
+			Namespace: "test",
+			Labels: map[string]string{
+				config.SparkRoleLabel:    config.SparkDriverRole,
+				config.SparkAppNameLabel: "foo",
+			},
+		},
+		Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+	}
+	ctrl, recorder := newFakeController(app, driverPod)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Create(app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.kubeClient.CoreV1().Pods("test").Create(driverPod); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.syncSparkApplication("test/foo"); err != nil {
+		t.Fatal(err)
+	}
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.InvalidatingState, updatedApp.Status.AppState.State)
+	_, err = ctrl.kubeClient.CoreV1().Pods("test").Get("foo-driver", metav1.GetOptions{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(recorder.Events))
+	assert.Contains(t, <-recorder.Events, "SparkApplicationRotated")
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const defaultRunHistoryLimit = 10
+
+// runHistoryTracker remembers when the next SparkApplicationRun of each application expires, so that the runs
+// of an application are only listed when one of them is to be deleted.
+type runHistoryTracker struct {
+	mutex  sync.Mutex
+	expiry map[string]time.Time
+}
+
+func newRunHistoryTracker() *runHistoryTracker {
+	return &runHistoryTracker{expiry: make(map[string]time.Time)}
+}
+
+// due tells if a run of the application with the given key may have expired. It is the case for applications
+// whose runs have not been listed since the operator started.
+func (t *runHistoryTracker) due(key string, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	expiry, ok := t.expiry[key]
+	return !ok || (!expiry.IsZero() && !now.Before(expiry))
+}
+
+// set records when the next run of the application with the given key expires, or that none does if expiry is
+// zero.
+func (t *runHistoryTracker) set(key string, expiry time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.expiry[key] = expiry
+}
+
+func (t *runHistoryTracker) forget(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.expiry, key)
+}
+
+func getRunHistoryLimit(spec *v1beta1.RunHistorySpec) int {
+	if spec.Limit != nil {
+		return int(*spec.Limit)
+	}
+	return defaultRunHistoryLimit
+}
+
+func isRunEnding(state v1beta1.ApplicationStateType) bool {
+	switch state {
+	case v1beta1.SucceedingState, v1beta1.FailingState, v1beta1.CompletedState, v1beta1.FailedState:
+		return true
+	}
+	return false
+}
+
+// getEndedRunState returns the state the run of the given application ended in when it changed from oldApp,
+// if the run has just ended. A run goes through SUCCEEDING or FAILING before COMPLETED, FAILED or a rerun,
+// and is only reported once. Every failed submission is a run of its own.
+func getEndedRunState(oldApp, app *v1beta1.SparkApplication) (v1beta1.ApplicationStateType, bool) {
+	oldState, state := oldApp.Status.AppState.State, app.Status.AppState.State
+	switch {
+	case state == v1beta1.FailedSubmissionState:
+		if oldState != state || !oldApp.Status.LastSubmissionAttemptTime.Equal(&app.Status.LastSubmissionAttemptTime) {
+			return state, true
+		}
+	case isRunEnding(state) && !isRunEnding(oldState) && oldState != v1beta1.FailedSubmissionState:
+		if state == v1beta1.SucceedingState || state == v1beta1.CompletedState {
+			return v1beta1.CompletedState, true
+		}
+		return v1beta1.FailedState, true
+	}
+	return "", false
+}
+
+// getRunCoreSeconds estimates the CPU core-seconds the driver and executors of the given application reserved
+// during a run of the given duration.
+func getRunCoreSeconds(app *v1beta1.SparkApplication, executors int32, duration time.Duration) int64 {
+	cores := float64(1)
+	if app.Spec.Driver.Cores != nil {
+		cores = float64(*app.Spec.Driver.Cores)
+	}
+	executorCores := float64(1)
+	if app.Spec.Executor.Cores != nil {
+		executorCores = float64(*app.Spec.Executor.Cores)
+	}
+	cores += executorCores * float64(executors)
+	return int64(cores * duration.Seconds())
+}
+
+// getRunMemoryMiBSeconds estimates the memory in MiB-seconds the driver and executors of the given application
+// reserved during a run of the given duration.
+func getRunMemoryMiBSeconds(app *v1beta1.SparkApplication, executors int32, duration time.Duration) int64 {
+	factor := defaultMemoryOverheadFactor
+	if app.Spec.MemoryOverheadFactor != nil {
+		if value, err := strconv.ParseFloat(*app.Spec.MemoryOverheadFactor, 64); err == nil {
+			factor = value
+		}
+	}
+	memoryMiB := getPodMemoryMiB(&app.Spec.Driver.SparkPodSpec, factor) +
+		getPodMemoryMiB(&app.Spec.Executor.SparkPodSpec, factor)*int64(executors)
+	return int64(float64(memoryMiB) * duration.Seconds())
+}
+
+// getPodMemoryMiB estimates the memory in MiB a driver or executor pod with the given spec requests, i.e., its
+// memory plus its memory overhead, which Spark defaults to the given factor of the memory but at least 384 MiB.
+func getPodMemoryMiB(spec *v1beta1.SparkPodSpec, overheadFactor float64) int64 {
+	memoryMiB := int64(defaultDriverMemoryMiB)
+	if spec.Memory != nil {
+		if value, err := parseJVMMemoryMiB(*spec.Memory); err == nil {
+			memoryMiB = value
+		}
+	}
+	overheadMiB := int64(math.Max(overheadFactor*float64(memoryMiB), minMemoryOverheadMiB))
+	if spec.MemoryOverhead != nil {
+		if value, err := parseJVMMemoryMiB(*spec.MemoryOverhead); err == nil {
+			overheadMiB = value
+		}
+	}
+	return memoryMiB + overheadMiB
+}
+
+// buildSparkApplicationRun returns the SparkApplicationRun recording the run of the given application that has
+// ended in the given state.
+func buildSparkApplicationRun(app *v1beta1.SparkApplication, state v1beta1.ApplicationStateType,
+	now time.Time) *v1beta1.SparkApplicationRun {
+	status := app.Status
+	submissionTime := status.LastSubmissionAttemptTime
+	name := util.BuildName(app.Name, strconv.FormatInt(submissionTime.Unix(), 10), util.DNS1123SubdomainMaxLength)
+
+	terminationTime := status.TerminationTime
+	if terminationTime.IsZero() {
+		terminationTime = metav1.NewTime(now)
+	}
+	var duration time.Duration
+	if !submissionTime.IsZero() {
+		duration = terminationTime.Sub(submissionTime.Time)
+	}
+	var driverRunningTime metav1.Time
+	if status.LaunchLatency != nil {
+		driverRunningTime = status.LaunchLatency.DriverRunningTime
+	}
+	executors := int32(len(status.ExecutorState))
+	// Runs keep the labels of their application, e.g., for reporting usage by team.
+	objectMeta := buildAppResourceObjectMeta(app, name)
+	for key, value := range withoutStateLabel(app.Labels) {
+		if _, ok := objectMeta.Labels[key]; !ok {
+			objectMeta.Labels[key] = value
+		}
+	}
+
+	return &v1beta1.SparkApplicationRun{
+		ObjectMeta: objectMeta,
+		Spec: v1beta1.SparkApplicationRunSpec{
+			ApplicationName:   app.Name,
+			ExecutionAttempt:  status.ExecutionAttempts,
+			SubmissionAttempt: status.SubmissionAttempts,
+			SourceRevision:    status.SourceRevision,
+			ApplicationSpec:   *app.Spec.DeepCopy(),
+		},
+		Status: v1beta1.SparkApplicationRunStatus{
+			SparkApplicationID: status.SparkApplicationID,
+			State:              state,
+			ErrorMessage:       status.AppState.ErrorMessage,
+			SubmittedBy:        status.SubmittedBy,
+			SubmissionTime:     submissionTime,
+			DriverRunningTime:  driverRunningTime,
+			TerminationTime:    terminationTime,
+			DurationSeconds:    int64(duration.Seconds()),
+			ResourceUsage: v1beta1.RunResourceUsage{
+				Executors:          executors,
+				ReclaimedExecutors: status.ReclaimedExecutors,
+				CoreSeconds:        getRunCoreSeconds(app, executors, duration),
+				MemoryMiBSeconds:   getRunMemoryMiBSeconds(app, executors, duration),
+			},
+		},
+	}
+}
+
+// recordRunHistory creates the SparkApplicationRun of the run of the given application if the run has just
+// ended, and deletes the runs of the application beyond its limit or older than its TTL.
+func (c *Controller) recordRunHistory(key string, oldApp, app *v1beta1.SparkApplication) {
+	spec := app.Spec.RunHistory
+	if spec == nil {
+		c.runHistory.forget(key)
+		return
+	}
+	now := time.Now()
+	state, ended := getEndedRunState(oldApp, app)
+	if ended {
+		run := buildSparkApplicationRun(app, state, now)
+		_, err := c.crdClient.SparkoperatorV1beta1().SparkApplicationRuns(app.Namespace).Create(run)
+		if err = ignoreAlreadyExists(err); err != nil {
+			glog.Errorf("failed to create SparkApplicationRun %s/%s: %v", app.Namespace, run.Name, err)
+		}
+	}
+	if !ended && !c.runHistory.due(key, now) {
+		return
+	}
+	expiry, err := c.pruneRunHistory(app, now)
+	if err != nil {
+		glog.Errorf("failed to delete the expired SparkApplicationRuns of SparkApplication %s/%s: %v",
+			app.Namespace, app.Name, err)
+		return
+	}
+	c.runHistory.set(key, expiry)
+	if !expiry.IsZero() {
+		c.queue.AddAfter(key, expiry.Sub(now))
+	}
+}
+
+// pruneRunHistory deletes the runs of the given application beyond its limit or older than its TTL, and
+// returns when the next of the remaining runs expires, if any does.
+func (c *Controller) pruneRunHistory(app *v1beta1.SparkApplication, now time.Time) (time.Time, error) {
+	spec := app.Spec.RunHistory
+	runs, err := c.crdClient.SparkoperatorV1beta1().SparkApplicationRuns(app.Namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", config.SparkAppNameLabel, app.Name),
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The most recent runs come first.
+	items := runs.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[j].Status.TerminationTime.Before(&items[i].Status.TerminationTime)
+	})
+	var expiry time.Time
+	for i, run := range items {
+		runExpiry := time.Time{}
+		if spec.TTLSeconds != nil {
+			runExpiry = run.Status.TerminationTime.Add(time.Duration(*spec.TTLSeconds) * time.Second)
+		}
+		if i < getRunHistoryLimit(spec) && (runExpiry.IsZero() || now.Before(runExpiry)) {
+			if !runExpiry.IsZero() && (expiry.IsZero() || runExpiry.Before(expiry)) {
+				expiry = runExpiry
+			}
+			continue
+		}
+		err := c.crdClient.SparkoperatorV1beta1().SparkApplicationRuns(app.Namespace).Delete(run.Name,
+			&metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return time.Time{}, err
+		}
+	}
+	return expiry, nil
+}
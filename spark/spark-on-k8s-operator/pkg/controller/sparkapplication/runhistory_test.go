@@ -0,0 +1,174 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetEndedRunState(t *testing.T) {
+	newApp := func(state v1beta1.ApplicationStateType, submissionTime int64) *v1beta1.SparkApplication {
+		return &v1beta1.SparkApplication{Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: state},
+			LastSubmissionAttemptTime: metav1.Unix(submissionTime, 0),
+		}}
+	}
+
+	type testcase struct {
+		oldState      v1beta1.ApplicationStateType
+		state         v1beta1.ApplicationStateType
+		resubmitted   bool
+		expectedState v1beta1.ApplicationStateType
+		expectedEnded bool
+	}
+	testcases := []testcase{
+		{v1beta1.RunningState, v1beta1.SucceedingState, false, v1beta1.CompletedState, true},
+		{v1beta1.RunningState, v1beta1.FailingState, false, v1beta1.FailedState, true},
+		{v1beta1.RunningState, v1beta1.FailedState, false, v1beta1.FailedState, true},
+		{v1beta1.SucceedingState, v1beta1.CompletedState, false, "", false},
+		{v1beta1.FailingState, v1beta1.PendingRerunState, false, "", false},
+		{v1beta1.SubmittedState, v1beta1.RunningState, false, "", false},
+		{v1beta1.NewState, v1beta1.FailedSubmissionState, false, v1beta1.FailedSubmissionState, true},
+		{v1beta1.FailedSubmissionState, v1beta1.FailedSubmissionState, true, v1beta1.FailedSubmissionState, true},
+		{v1beta1.FailedSubmissionState, v1beta1.FailedSubmissionState, false, "", false},
+		{v1beta1.FailedSubmissionState, v1beta1.FailedState, false, "", false},
+	}
+	for _, test := range testcases {
+		var submissionTime int64 = 100
+		if test.resubmitted {
+			submissionTime = 200
+		}
+		state, ended := getEndedRunState(newApp(test.oldState, 100), newApp(test.state, submissionTime))
+		assert.Equal(t, test.expectedEnded, ended, "%s -> %s", test.oldState, test.state)
+		assert.Equal(t, test.expectedState, state, "%s -> %s", test.oldState, test.state)
+	}
+}
+
+// listCreatedRuns makes the fake clientset of the given controller list the SparkApplicationRuns created and
+// not deleted through it, which the object tracker of the generated fake clientset cannot list. The reactors
+// must not call the clientset, which holds its lock while running them.
+func listCreatedRuns(ctrl *Controller) {
+	crdClient := ctrl.crdClient.(*crdclientfake.Clientset)
+	runs := make(map[string]*v1beta1.SparkApplicationRun)
+	crdClient.PrependReactor("create", "sparkapplicationruns", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		run := action.(kubetesting.CreateAction).GetObject().(*v1beta1.SparkApplicationRun)
+		runs[action.GetNamespace()+"/"+run.Name] = run.DeepCopy()
+		return false, nil, nil
+	})
+	crdClient.PrependReactor("delete", "sparkapplicationruns", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		delete(runs, action.GetNamespace()+"/"+action.(kubetesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+	crdClient.PrependReactor("list", "sparkapplicationruns", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		restrictions := action.(kubetesting.ListAction).GetListRestrictions()
+		list := &v1beta1.SparkApplicationRunList{}
+		for key, run := range runs {
+			if strings.HasPrefix(key, action.GetNamespace()+"/") && restrictions.Labels.Matches(labels.Set(run.Labels)) {
+				list.Items = append(list.Items, *run.DeepCopy())
+			}
+		}
+		return true, list, nil
+	})
+}
+
+func TestRecordRunHistory(t *testing.T) {
+	var limit int32 = 2
+	var ttl int64 = 3600
+	cores := float32(2)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-1", Labels: map[string]string{
+			"team": "ads", config.SparkAppStateLabel: "RUNNING"}},
+		Spec: v1beta1.SparkApplicationSpec{
+			RunHistory: &v1beta1.RunHistorySpec{Limit: &limit, TTLSeconds: &ttl},
+			Executor:   v1beta1.ExecutorSpec{SparkPodSpec: v1beta1.SparkPodSpec{Cores: &cores}},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	listCreatedRuns(ctrl)
+	key := getApplicationKey(app.Namespace, app.Name)
+	now := time.Now()
+
+	runApp := func(submissionTime time.Time, state v1beta1.ApplicationStateType) {
+		oldApp := app.DeepCopy()
+		oldApp.Status.AppState.State = v1beta1.RunningState
+		newApp := oldApp.DeepCopy()
+		newApp.Status.AppState = v1beta1.ApplicationState{State: state, ErrorMessage: "oops"}
+		newApp.Status.SparkApplicationID = "spark-1"
+		newApp.Status.LastSubmissionAttemptTime = metav1.NewTime(submissionTime)
+		newApp.Status.TerminationTime = metav1.NewTime(submissionTime.Add(100 * time.Second))
+		newApp.Status.ExecutorState = map[string]v1beta1.ExecutorState{
+			"exec-1": v1beta1.ExecutorCompletedState,
+			"exec-2": v1beta1.ExecutorCompletedState,
+		}
+		ctrl.recordRunHistory(key, oldApp, newApp)
+	}
+
+	first := now.Add(-2 * time.Hour)
+	runApp(first, v1beta1.FailingState)
+	runs, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplicationRuns("test").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first run is recorded although it has already expired, and is deleted right away.
+	assert.Equal(t, 0, len(runs.Items))
+
+	second := now.Add(-10 * time.Minute)
+	runApp(second, v1beta1.SucceedingState)
+	runs, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplicationRuns("test").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(runs.Items))
+	run := runs.Items[0]
+	assert.Equal(t, "uid-1", string(run.OwnerReferences[0].UID))
+	assert.Equal(t, "foo", run.Spec.ApplicationName)
+	assert.Equal(t, map[string]string{config.SparkAppNameLabel: "foo", "team": "ads"}, run.Labels)
+	assert.Equal(t, v1beta1.CompletedState, run.Status.State)
+	assert.Equal(t, "spark-1", run.Status.SparkApplicationID)
+	assert.Equal(t, int64(100), run.Status.DurationSeconds)
+	assert.Equal(t, int32(2), run.Status.ResourceUsage.Executors)
+	// One core of the driver and two of each executor for 100 seconds.
+	assert.Equal(t, int64(500), run.Status.ResourceUsage.CoreSeconds)
+	// 1g and 384m of overhead for the driver and each executor for 100 seconds.
+	assert.Equal(t, int64(3*1408*100), run.Status.ResourceUsage.MemoryMiBSeconds)
+	assert.False(t, ctrl.runHistory.due(key, now))
+	assert.True(t, ctrl.runHistory.due(key, second.Add(100*time.Second+time.Hour)))
+
+	// Only the last two runs are kept.
+	runApp(now.Add(-5*time.Minute), v1beta1.FailingState)
+	runApp(now.Add(-time.Minute), v1beta1.SucceedingState)
+	runs, err = ctrl.crdClient.SparkoperatorV1beta1().SparkApplicationRuns("test").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(runs.Items))
+	for _, run := range runs.Items {
+		assert.NotEqual(t, second.Unix(), run.Status.SubmissionTime.Unix())
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	// maxSubmissionOutputBytes is how much of the end of the standard output and error of spark-submit is kept
+	// in the status of applications.
+	maxSubmissionOutputBytes = 4096
+	// cgroupCPUPeriod is the CPU period of the cgroups of spark-submit, in microseconds.
+	cgroupCPUPeriod = 100000
+)
+
+// sandboxEssentialEnvVars are the environment variables of the operator spark-submit always gets, as it does not
+// run without them.
+var sandboxEssentialEnvVars = []string{"PATH", "HOME", "JAVA_HOME", sparkHomeEnvVar, kubernetesServiceHostEnvVar,
+	kubernetesServicePortEnvVar}
+
+// SubmissionSandbox restricts the environment spark-submit runs in. The zero value runs spark-submit with the
+// environment of the operator and without limits.
+type SubmissionSandbox struct {
+	// EnvAllowlist are the names of the environment variables of the operator spark-submit gets, besides the
+	// essential ones. Names ending with * match all variables with the given prefix. spark-submit gets all
+	// variables if empty.
+	EnvAllowlist []string
+	// TmpDir is the directory in which a temporary directory is created for each run of spark-submit, which is
+	// removed after the run. spark-submit uses the temporary directory of the operator if empty.
+	TmpDir string
+	// Timeout is how long spark-submit may run before it is killed. No timeout if zero.
+	Timeout time.Duration
+	// CgroupParent is a cgroup v2 directory delegated to the operator, in which a cgroup is created for each run
+	// of spark-submit with the limits below. Runs are not put in cgroups if empty.
+	CgroupParent string
+	// MemoryLimit is the memory limit of the cgroups of spark-submit in bytes. No limit if zero.
+	MemoryLimit int64
+	// CPULimit is the CPU limit of the cgroups of spark-submit in millicores. No limit if zero.
+	CPULimit int64
+}
+
+// buildEnv returns the environment of spark-submit from the given environment of the operator, with the given
+// additional variables, or nil if spark-submit inherits the environment of the operator.
+func (s *SubmissionSandbox) buildEnv(environ []string, extra []string, tmpDir string) []string {
+	if len(s.EnvAllowlist) == 0 && len(extra) == 0 && tmpDir == "" {
+		return nil
+	}
+	var env []string
+	for _, variable := range environ {
+		name := strings.SplitN(variable, "=", 2)[0]
+		if len(s.EnvAllowlist) == 0 || s.allowsEnvVar(name) {
+			env = append(env, variable)
+		}
+	}
+	env = append(env, extra...)
+	if tmpDir != "" {
+		// The JVM ignores TMPDIR, so its temporary directory is set through the options of spark-submit.
+		opts := fmt.Sprintf("-Djava.io.tmpdir=%s", tmpDir)
+		if existing := lookupEnv(env, config.SparkSubmitOptsEnvVar); existing != "" {
+			opts = existing + " " + opts
+		}
+		env = append(env, "TMPDIR="+tmpDir, fmt.Sprintf("%s=%s", config.SparkSubmitOptsEnvVar, opts))
+	}
+	return env
+}
+
+func (s *SubmissionSandbox) allowsEnvVar(name string) bool {
+	for _, names := range [][]string{sandboxEssentialEnvVars, s.EnvAllowlist} {
+		for _, allowed := range names {
+			if allowed == name {
+				return true
+			}
+			if strings.HasSuffix(allowed, "*") && strings.HasPrefix(name, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookupEnv returns the value of the given variable in the given environment, where later values override earlier
+// ones.
+func lookupEnv(env []string, name string) string {
+	var value string
+	for _, variable := range env {
+		if strings.HasPrefix(variable, name+"=") {
+			value = strings.TrimPrefix(variable, name+"=")
+		}
+	}
+	return value
+}
+
+// createTmpDir creates the temporary directory of a run of spark-submit, if the sandbox has a TmpDir.
+func (s *SubmissionSandbox) createTmpDir(submission *Submission) (string, error) {
+	if s.TmpDir == "" {
+		return "", nil
+	}
+	dir, err := ioutil.TempDir(s.TmpDir, fmt.Sprintf("spark-submit-%s-%s-", submission.Namespace, submission.Name))
+	if err != nil {
+		return "", fmt.Errorf("failed to create the temporary directory of spark-submit: %v", err)
+	}
+	return dir, nil
+}
+
+// createCgroup creates the cgroup of a run of spark-submit with the limits of the sandbox, if the sandbox has a
+// CgroupParent.
+func (s *SubmissionSandbox) createCgroup(submission *Submission) (string, error) {
+	if s.CgroupParent == "" {
+		return "", nil
+	}
+	cgroup := filepath.Join(s.CgroupParent, fmt.Sprintf("spark-submit-%s-%s-%d", submission.Namespace,
+		submission.Name, time.Now().UnixNano()))
+	if err := os.Mkdir(cgroup, 0755); err != nil {
+		return "", fmt.Errorf("failed to create the cgroup of spark-submit: %v", err)
+	}
+	limits := make(map[string]string)
+	if s.MemoryLimit > 0 {
+		limits["memory.max"] = strconv.FormatInt(s.MemoryLimit, 10)
+	}
+	if s.CPULimit > 0 {
+		limits["cpu.max"] = fmt.Sprintf("%d %d", s.CPULimit*cgroupCPUPeriod/1000, cgroupCPUPeriod)
+	}
+	for file, value := range limits {
+		if err := ioutil.WriteFile(filepath.Join(cgroup, file), []byte(value), 0644); err != nil {
+			os.Remove(cgroup)
+			return "", fmt.Errorf("failed to set %s of the cgroup of spark-submit: %v", file, err)
+		}
+	}
+	return cgroup, nil
+}
+
+// addToCgroup moves the process with the given PID into the given cgroup. Processes it starts afterwards are in
+// the cgroup too.
+func addToCgroup(cgroup string, pid int) error {
+	return ioutil.WriteFile(filepath.Join(cgroup, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
+
+// tailOutput returns the end of the given output that fits in the status of an application.
+func tailOutput(output []byte) string {
+	if len(output) > maxSubmissionOutputBytes {
+		output = output[len(output)-maxSubmissionOutputBytes:]
+	}
+	return string(output)
+}
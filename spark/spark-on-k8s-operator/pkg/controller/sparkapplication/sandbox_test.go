@@ -0,0 +1,147 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestSandboxBuildEnv(t *testing.T) {
+	environ := []string{"PATH=/usr/bin", "HOME=/root", "AWS_SECRET_ACCESS_KEY=secret", "HADOOP_CONF_DIR=/etc/hadoop",
+		"SPARK_SUBMIT_OPTS=-Dfoo=bar"}
+
+	// Without restrictions, spark-submit inherits the environment of the operator.
+	sandbox := &SubmissionSandbox{}
+	assert.Nil(t, sandbox.buildEnv(environ, nil, ""))
+	assert.Equal(t, append(environ, "HADOOP_USER_NAME=alice"),
+		sandbox.buildEnv(environ, []string{"HADOOP_USER_NAME=alice"}, ""))
+
+	sandbox = &SubmissionSandbox{EnvAllowlist: []string{"HADOOP_*"}}
+	assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/root", "HADOOP_CONF_DIR=/etc/hadoop"},
+		sandbox.buildEnv(environ, nil, ""))
+
+	sandbox = &SubmissionSandbox{EnvAllowlist: []string{config.SparkSubmitOptsEnvVar}}
+	assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/root", "SPARK_SUBMIT_OPTS=-Dfoo=bar", "TMPDIR=/tmp/run",
+		"SPARK_SUBMIT_OPTS=-Dfoo=bar -Djava.io.tmpdir=/tmp/run"}, sandbox.buildEnv(environ, nil, "/tmp/run"))
+}
+
+func TestSandboxCreateCgroup(t *testing.T) {
+	parent, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(parent)
+
+	submission := &Submission{Namespace: "default", Name: "foo"}
+	sandbox := &SubmissionSandbox{CgroupParent: parent, MemoryLimit: 1 << 30, CPULimit: 500}
+	cgroup, err := sandbox.createCgroup(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, strings.HasPrefix(filepath.Base(cgroup), "spark-submit-default-foo-"))
+	memory, err := ioutil.ReadFile(filepath.Join(cgroup, "memory.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1073741824", string(memory))
+	cpu, err := ioutil.ReadFile(filepath.Join(cgroup, "cpu.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "50000 100000", string(cpu))
+
+	// No cgroups are created without a parent.
+	cgroup, err = (&SubmissionSandbox{}).createCgroup(submission)
+	assert.Nil(t, err)
+	assert.Equal(t, "", cgroup)
+}
+
+func TestTailOutput(t *testing.T) {
+	assert.Equal(t, "short", tailOutput([]byte("short")))
+	long := strings.Repeat("a", maxSubmissionOutputBytes) + "end"
+	tail := tailOutput([]byte(long))
+	assert.Equal(t, maxSubmissionOutputBytes, len(tail))
+	assert.True(t, strings.HasSuffix(tail, "end"))
+}
+
+func TestRunSparkSubmitCapturesOutput(t *testing.T) {
+	defer func() { execCommand = exec.Command }()
+	execCommand = func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestHelperProcessOutput", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}
+
+	submission := &Submission{Namespace: "default", Name: "foo"}
+	submitted, err := runSparkSubmit(submission, nil)
+	assert.False(t, submitted)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(3), submission.Output.ExitCode)
+	assert.False(t, submission.Output.TimedOut)
+	assert.True(t, strings.Contains(submission.Output.Stdout, "submitting"))
+	assert.True(t, strings.Contains(submission.Output.Stderr, "invalid key"))
+}
+
+func TestRunSparkSubmitTimeout(t *testing.T) {
+	defer func() { execCommand = exec.Command }()
+	execCommand = func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestHelperProcessHang", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}
+
+	submission := &Submission{Namespace: "default", Name: "foo"}
+	start := time.Now()
+	submitted, err := runSparkSubmit(submission, &SubmissionSandbox{Timeout: 100 * time.Millisecond})
+	assert.False(t, submitted)
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second)
+	assert.True(t, submission.Output.TimedOut)
+	assert.Equal(t, int32(-1), submission.Output.ExitCode)
+}
+
+func TestHelperProcessOutput(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Println("submitting")
+	fmt.Fprintln(os.Stderr, "invalid key")
+	os.Exit(3)
+}
+
+func TestHelperProcessHang(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
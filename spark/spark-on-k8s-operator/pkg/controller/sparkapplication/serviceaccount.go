@@ -0,0 +1,203 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// driverPolicyRules are the permissions a driver running in the cluster needs to run its executors: managing
+// the executor pods and the ConfigMaps Spark creates for their configuration.
+var driverPolicyRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "configmaps"},
+		Verbs:     []string{"create", "delete", "deletecollection", "get", "list", "watch"},
+	},
+}
+
+// createsDriverServiceAccount tells if a dedicated ServiceAccount is created for the driver of the given
+// application. External drivers always get one, so the option only applies to drivers running in the cluster.
+// A cloud identity is bound to the dedicated ServiceAccount, so it implies one.
+func createsDriverServiceAccount(app *v1beta1.SparkApplication) bool {
+	if hasExternalDriver(app) {
+		return false
+	}
+	return (app.Spec.CreateServiceAccount != nil && *app.Spec.CreateServiceAccount) || app.Spec.CloudIdentity != nil
+}
+
+func getDriverServiceAccountName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "spark", util.DNS1123LabelMaxLength)
+}
+
+// getCloudIdentityAnnotations returns the annotations that bind the cloud identity of the given application to
+// its dedicated ServiceAccount.
+func getCloudIdentityAnnotations(app *v1beta1.SparkApplication) (map[string]string, error) {
+	identity := app.Spec.CloudIdentity
+	if identity == nil {
+		return nil, nil
+	}
+	if (identity.AWSRoleARN == nil) == (identity.GCPServiceAccount == nil) {
+		return nil, fmt.Errorf("exactly one of cloudIdentity.awsRoleARN and cloudIdentity.gcpServiceAccount must be set")
+	}
+	if identity.AWSRoleARN != nil {
+		return map[string]string{config.AWSRoleARNAnnotation: *identity.AWSRoleARN}, nil
+	}
+	return map[string]string{config.GCPServiceAccountAnnotation: *identity.GCPServiceAccount}, nil
+}
+
+// setUpDriverServiceAccount creates the dedicated ServiceAccount of the driver of the given application and
+// makes the driver run as it. With a cloud identity, the executors run as it as well.
+func (c *Controller) setUpDriverServiceAccount(app *v1beta1.SparkApplication) error {
+	if app.Spec.Driver.ServiceAccount != nil {
+		return fmt.Errorf("createServiceAccount and cloudIdentity cannot be used together with driver.serviceAccount")
+	}
+	annotations, err := getCloudIdentityAnnotations(app)
+	if err != nil {
+		return err
+	}
+	name := getDriverServiceAccountName(app)
+	if err = c.ensureServiceAccount(app, name, driverPolicyRules, annotations); err != nil {
+		return err
+	}
+	app.Spec.Driver.ServiceAccount = &name
+	if app.Spec.CloudIdentity != nil {
+		if app.Spec.SparkConf == nil {
+			app.Spec.SparkConf = make(map[string]string)
+		}
+		app.Spec.SparkConf[config.SparkExecutorServiceAccountName] = name
+	}
+	return nil
+}
+
+// deleteDriverServiceAccount deletes the dedicated ServiceAccount of the driver of the given application along
+// with its Role and RoleBinding.
+func (c *Controller) deleteDriverServiceAccount(app *v1beta1.SparkApplication) error {
+	name := app.Status.DriverInfo.ServiceAccountName
+	if !createsDriverServiceAccount(app) || name == "" {
+		return nil
+	}
+
+	glog.V(2).Infof("Deleting ServiceAccount, Role and RoleBinding %s in namespace %s", name, app.Namespace)
+	err := c.kubeClient.RbacV1().RoleBindings(app.Namespace).Delete(name, metav1.NewDeleteOptions(0))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	err = c.kubeClient.RbacV1().Roles(app.Namespace).Delete(name, metav1.NewDeleteOptions(0))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	err = c.kubeClient.CoreV1().ServiceAccounts(app.Namespace).Delete(name, metav1.NewDeleteOptions(0))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func buildAppResourceObjectMeta(app *v1beta1.SparkApplication, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       app.Namespace,
+		Labels:          map[string]string{config.SparkAppNameLabel: app.Name},
+		OwnerReferences: []metav1.OwnerReference{*getOwnerReference(app)},
+	}
+}
+
+// ensureServiceAccount creates a ServiceAccount with the given name and annotations, along with a Role granting
+// it the given permissions and a RoleBinding binding the two, unless they already exist. All of them are owned by
+// the given application, so they are garbage collected along with it. The annotations of an existing
+// ServiceAccount are updated.
+func (c *Controller) ensureServiceAccount(app *v1beta1.SparkApplication, name string, rules []rbacv1.PolicyRule,
+	annotations map[string]string) error {
+	serviceAccount, err := c.kubeClient.CoreV1().ServiceAccounts(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		objectMeta := buildAppResourceObjectMeta(app, name)
+		objectMeta.Annotations = annotations
+		_, err = c.kubeClient.CoreV1().ServiceAccounts(app.Namespace).Create(&apiv1.ServiceAccount{
+			ObjectMeta: objectMeta,
+		})
+		err = ignoreAlreadyExists(err)
+	} else if err == nil && !hasAnnotations(serviceAccount.Annotations, annotations) {
+		serviceAccount = serviceAccount.DeepCopy()
+		if serviceAccount.Annotations == nil {
+			serviceAccount.Annotations = make(map[string]string)
+		}
+		for key, value := range annotations {
+			serviceAccount.Annotations[key] = value
+		}
+		_, err = c.kubeClient.CoreV1().ServiceAccounts(app.Namespace).Update(serviceAccount)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create ServiceAccount %s/%s: %v", app.Namespace, name, err)
+	}
+
+	_, err = c.kubeClient.RbacV1().Roles(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.RbacV1().Roles(app.Namespace).Create(&rbacv1.Role{
+			ObjectMeta: buildAppResourceObjectMeta(app, name),
+			Rules:      rules,
+		})
+		err = ignoreAlreadyExists(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create Role %s/%s: %v", app.Namespace, name, err)
+	}
+
+	_, err = c.kubeClient.RbacV1().RoleBindings(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.RbacV1().RoleBindings(app.Namespace).Create(&rbacv1.RoleBinding{
+			ObjectMeta: buildAppResourceObjectMeta(app, name),
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      name,
+					Namespace: app.Namespace,
+				},
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     name,
+			},
+		})
+		err = ignoreAlreadyExists(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create RoleBinding %s/%s: %v", app.Namespace, name, err)
+	}
+	return nil
+}
+
+// hasAnnotations tells if the given annotations include all the wanted ones.
+func hasAnnotations(annotations map[string]string, wanted map[string]string) bool {
+	for key, value := range wanted {
+		if annotations[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestSetUpDriverServiceAccount(t *testing.T) {
+	createServiceAccount := true
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-1"},
+		Spec:       v1beta1.SparkApplicationSpec{CreateServiceAccount: &createServiceAccount},
+	}
+	ctrl, _ := newFakeController(app)
+
+	assert.True(t, createsDriverServiceAccount(app))
+	if err := ctrl.setUpDriverServiceAccount(app); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo-spark", *app.Spec.Driver.ServiceAccount)
+	serviceAccount, err := ctrl.kubeClient.CoreV1().ServiceAccounts("test").Get("foo-spark", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "uid-1", string(serviceAccount.OwnerReferences[0].UID))
+	role, err := ctrl.kubeClient.RbacV1().Roles("test").Get("foo-spark", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, driverPolicyRules, role.Rules)
+	roleBinding, err := ctrl.kubeClient.RbacV1().RoleBindings("test").Get("foo-spark", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo-spark", roleBinding.Subjects[0].Name)
+	assert.Equal(t, "foo-spark", roleBinding.RoleRef.Name)
+
+	// A service account of the user's choice cannot be used at the same time.
+	assert.NotNil(t, ctrl.setUpDriverServiceAccount(app))
+}
+
+func TestSetUpDriverServiceAccount_CloudIdentity(t *testing.T) {
+	roleARN := "arn:aws:iam::123456789012:role/spark"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", UID: "uid-1"},
+		Spec: v1beta1.SparkApplicationSpec{
+			CloudIdentity: &v1beta1.CloudIdentitySpec{AWSRoleARN: &roleARN},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+
+	assert.True(t, createsDriverServiceAccount(app))
+	if err := ctrl.setUpDriverServiceAccount(app); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo-spark", *app.Spec.Driver.ServiceAccount)
+	assert.Equal(t, "foo-spark", app.Spec.SparkConf[config.SparkExecutorServiceAccountName])
+	serviceAccount, err := ctrl.kubeClient.CoreV1().ServiceAccounts("test").Get("foo-spark", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, roleARN, serviceAccount.Annotations[config.AWSRoleARNAnnotation])
+
+	// The annotations of an existing ServiceAccount are updated.
+	email := "spark@project.iam.gserviceaccount.com"
+	app.Spec.Driver.ServiceAccount = nil
+	app.Spec.CloudIdentity = &v1beta1.CloudIdentitySpec{GCPServiceAccount: &email}
+	if err := ctrl.setUpDriverServiceAccount(app); err != nil {
+		t.Fatal(err)
+	}
+	serviceAccount, err = ctrl.kubeClient.CoreV1().ServiceAccounts("test").Get("foo-spark", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, email, serviceAccount.Annotations[config.GCPServiceAccountAnnotation])
+
+	// Exactly one identity must be set.
+	app.Spec.Driver.ServiceAccount = nil
+	app.Spec.CloudIdentity.AWSRoleARN = &roleARN
+	assert.NotNil(t, ctrl.setUpDriverServiceAccount(app))
+}
+
+func TestSyncSparkApplication_DeletesDriverServiceAccount(t *testing.T) {
+	createServiceAccount := true
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1beta1.SparkApplicationSpec{CreateServiceAccount: &createServiceAccount},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:        v1beta1.ApplicationState{State: v1beta1.SucceedingState},
+			DriverInfo:      v1beta1.DriverInfo{ServiceAccountName: "foo-spark"},
+			TerminationTime: metav1.Now(),
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Create(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.setUpDriverServiceAccount(app.DeepCopy()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctrl.syncSparkApplication("test/foo"); err != nil {
+		t.Fatal(err)
+	}
+	updatedApp, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications("test").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.CompletedState, updatedApp.Status.AppState.State)
+	_, err = ctrl.kubeClient.CoreV1().ServiceAccounts("test").Get("foo-spark", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = ctrl.kubeClient.RbacV1().Roles("test").Get("foo-spark", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = ctrl.kubeClient.RbacV1().RoleBindings("test").Get("foo-spark", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
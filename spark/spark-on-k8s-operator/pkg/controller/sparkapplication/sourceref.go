@@ -0,0 +1,121 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/features"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/source"
+)
+
+const sourceRetryInterval = time.Minute
+
+var fetchSource = source.Fetch
+
+// needsSource tells if the spec of the given application has to be loaded from its source before it runs,
+// which is the case at the start of every run until the source has been loaded.
+func needsSource(app *v1beta1.SparkApplication) bool {
+	if app.Spec.SourceRef == nil || app.Status.SourceRevision != "" || !features.Enabled(features.SourceReferences) {
+		return false
+	}
+	state := app.Status.AppState.State
+	return state == v1beta1.NewState || state == v1beta1.PendingRerunState
+}
+
+// isSourceLoad tells if the given update of an application is the operator loading its spec from its source,
+// which does not invalidate the application. The spec is written before the revision it was loaded at is
+// recorded in the status, so any spec update of an application still waiting for its source counts as a load.
+func isSourceLoad(oldApp, newApp *v1beta1.SparkApplication) bool {
+	return needsSource(oldApp) && reflect.DeepEqual(oldApp.Spec.SourceRef, newApp.Spec.SourceRef)
+}
+
+func (c *Controller) getSourceCredentials(app *v1beta1.SparkApplication) (*source.Credentials, error) {
+	ref := app.Spec.SourceRef
+	if ref.SecretName == nil {
+		return nil, nil
+	}
+	secret, err := c.kubeClient.CoreV1().Secrets(app.Namespace).Get(*ref.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source secret %s: %v", *ref.SecretName, err)
+	}
+	return &source.Credentials{
+		Username: string(secret.Data["username"]),
+		Password: string(secret.Data["password"]),
+	}, nil
+}
+
+// loadSource loads the spec of the given application from its source into the application, keeping its
+// sourceRef, and records the revision the spec was loaded at.
+func (c *Controller) loadSource(app *v1beta1.SparkApplication) error {
+	credentials, err := c.getSourceCredentials(app)
+	if err != nil {
+		return err
+	}
+	manifest, revision, err := fetchSource(app.Spec.SourceRef, credentials)
+	if err != nil {
+		return err
+	}
+	spec, err := source.LoadSpec(manifest)
+	if err != nil {
+		return fmt.Errorf("invalid manifest at revision %s: %v", revision, err)
+	}
+	spec.SourceRef = app.Spec.SourceRef
+	app.Spec = *spec
+	// Only applications being added are defaulted by the informer handler.
+	v1beta1.SetSparkApplicationDefaults(app)
+	app.Status.SourceRevision = revision
+	return nil
+}
+
+// syncSource loads the spec of the given application from its source and updates the application with it, then
+// records the revision in the status. The updates re-enqueue the application, which then runs with the loaded
+// spec. Failures are retried periodically.
+func (c *Controller) syncSource(key string, app *v1beta1.SparkApplication) error {
+	appToUpdate := app.DeepCopy()
+	if err := c.loadSource(appToUpdate); err != nil {
+		c.recorder.Eventf(app, apiv1.EventTypeWarning, "SparkApplicationSourceFailed",
+			"failed to load the spec of SparkApplication %s from its source: %v", app.Name, err)
+		glog.Errorf("failed to load the spec of SparkApplication %s/%s from its source: %v", app.Namespace,
+			app.Name, err)
+		c.queue.AddAfter(key, sourceRetryInterval)
+		return nil
+	}
+
+	client := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace)
+	updated, err := client.Update(appToUpdate)
+	if err != nil {
+		return fmt.Errorf("failed to update SparkApplication %s/%s with the spec from its source: %v",
+			app.Namespace, app.Name, err)
+	}
+	updated.Status.SourceRevision = appToUpdate.Status.SourceRevision
+	if _, err := client.UpdateStatus(updated); err != nil {
+		return fmt.Errorf("failed to record the source revision of SparkApplication %s/%s: %v",
+			app.Namespace, app.Name, err)
+	}
+	c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkApplicationSourceLoaded",
+		"SparkApplication %s loaded its spec from revision %s of its source", app.Name,
+		appToUpdate.Status.SourceRevision)
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/features"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/source"
+)
+
+const sourceManifest = `
+apiVersion: sparkoperator.k8s.io/v1beta1
+kind: SparkApplication
+metadata:
+  name: spark-pi
+spec:
+  type: Scala
+  mode: cluster
+  image: gcr.io/spark-operator/spark:v2.4.0
+  mainClass: org.apache.spark.examples.SparkPi
+  mainApplicationFile: local:///opt/spark/examples/jars/spark-examples_2.11-2.4.0.jar
+`
+
+func TestSyncSource(t *testing.T) {
+	if err := features.DefaultGate.Set("SourceReferences=true"); err != nil {
+		t.Fatal(err)
+	}
+	defer features.DefaultGate.Set("SourceReferences=false")
+	defer func() { fetchSource = source.Fetch }()
+
+	secretName := "git-credentials"
+	sourceRef := &v1beta1.SourceReference{
+		Git:        &v1beta1.GitSource{URL: "https://github.com/org/pipelines.git", Path: "spark-pi.yaml"},
+		SecretName: &secretName,
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi", Namespace: "default"},
+		Spec:       v1beta1.SparkApplicationSpec{SourceRef: sourceRef},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.NewState},
+		},
+	}
+	assert.True(t, needsSource(app))
+
+	ctrl, recorder := newFakeController(app)
+	ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	ctrl.kubeClient.CoreV1().Secrets(app.Namespace).Create(&apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: app.Namespace},
+		Data:       map[string][]byte{"username": []byte("ci"), "password": []byte("token")},
+	})
+
+	var fetched *source.Credentials
+	fetchSource = func(ref *v1beta1.SourceReference, credentials *source.Credentials) ([]byte, string, error) {
+		fetched = credentials
+		return []byte(sourceManifest), "0123456789abcdef0123456789abcdef01234567", nil
+	}
+	assert.Nil(t, ctrl.syncSource("default/spark-pi", app))
+	assert.Equal(t, &source.Credentials{Username: "ci", Password: "token"}, fetched)
+	assert.Equal(t, 1, len(recorder.Events))
+
+	updated, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name,
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.ScalaApplicationType, updated.Spec.Type)
+	assert.Equal(t, "org.apache.spark.examples.SparkPi", *updated.Spec.MainClass)
+	assert.Equal(t, sourceRef, updated.Spec.SourceRef)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", updated.Status.SourceRevision)
+	assert.Equal(t, v1beta1.NewState, updated.Status.AppState.State)
+	assert.False(t, needsSource(updated))
+	assert.True(t, isSourceLoad(app, updated))
+
+	// A change of the source is not a load of it.
+	changed := updated.DeepCopy()
+	revision := "v2"
+	changed.Spec.SourceRef.Git.Revision = &revision
+	assert.False(t, isSourceLoad(updated, changed))
+}
+
+func TestSyncSource_InvalidManifest(t *testing.T) {
+	if err := features.DefaultGate.Set("SourceReferences=true"); err != nil {
+		t.Fatal(err)
+	}
+	defer features.DefaultGate.Set("SourceReferences=false")
+	defer func() { fetchSource = source.Fetch }()
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			SourceRef: &v1beta1.SourceReference{
+				OCI: &v1beta1.OCISource{Reference: "ghcr.io/org/pipelines:v1"},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.PendingRerunState},
+		},
+	}
+	ctrl, recorder := newFakeController(app)
+	ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+
+	fetchSource = func(ref *v1beta1.SourceReference, credentials *source.Credentials) ([]byte, string, error) {
+		return []byte("kind: ConfigMap"), "sha256:abc", nil
+	}
+	assert.Nil(t, ctrl.syncSource("default/spark-pi", app))
+	assert.Equal(t, 1, len(recorder.Events))
+
+	updated, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name,
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", updated.Status.SourceRevision)
+	assert.True(t, needsSource(updated))
+}
+
+func TestSyncSparkApplication_KeepsSourceRevision(t *testing.T) {
+	if err := features.DefaultGate.Set("SourceReferences=true"); err != nil {
+		t.Fatal(err)
+	}
+	defer features.DefaultGate.Set("SourceReferences=false")
+	os.Setenv(sparkHomeEnvVar, "/spark")
+	os.Setenv(kubernetesServiceHostEnvVar, "localhost")
+	os.Setenv(kubernetesServicePortEnvVar, "443")
+
+	type testcase struct {
+		helperProcess string
+		expectedState v1beta1.ApplicationStateType
+	}
+	testcases := []testcase{
+		{helperProcess: "TestHelperProcessSuccess", expectedState: v1beta1.SubmittedState},
+		{helperProcess: "TestHelperProcessFailure", expectedState: v1beta1.FailedSubmissionState},
+	}
+	for _, test := range testcases {
+		// The application has been loaded from its source, which is not fetched again.
+		app := &v1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "spark-pi", Namespace: "default"},
+			Spec: v1beta1.SparkApplicationSpec{
+				SourceRef: &v1beta1.SourceReference{
+					Git: &v1beta1.GitSource{URL: "https://github.com/org/pipelines.git", Path: "spark-pi.yaml"},
+				},
+			},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState:       v1beta1.ApplicationState{State: v1beta1.NewState},
+				SourceRevision: "0123456789abcdef0123456789abcdef01234567",
+			},
+		}
+		ctrl, _ := newFakeController(app)
+		if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+		helperProcess := test.helperProcess
+		execCommand = func(command string, args ...string) *exec.Cmd {
+			cs := []string{"-test.run=" + helperProcess, "--", command}
+			cs = append(cs, args...)
+			cmd := exec.Command(os.Args[0], cs...)
+			cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+			return cmd
+		}
+
+		assert.Nil(t, ctrl.syncSparkApplication("default/spark-pi"))
+		updated, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name,
+			metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, test.expectedState, updated.Status.AppState.State)
+		assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", updated.Status.SourceRevision)
+		assert.False(t, needsSource(updated))
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
@@ -41,8 +42,21 @@ type sparkAppMetrics struct {
 	sparkAppExecutorRunningCount *util.PositiveGauge
 	sparkAppExecutorFailureCount *prometheus.CounterVec
 	sparkAppExecutorSuccessCount *prometheus.CounterVec
+	// sparkAppExecutorGPUCount has an additional pod label, so it can be joined with the GPU metrics of pods,
+	// e.g., the ones of the NVIDIA DCGM exporter.
+	sparkAppExecutorGPUCount *prometheus.GaugeVec
+
+	sparkAppDriverLaunchLatency   *prometheus.HistogramVec
+	sparkAppExecutorLaunchLatency *prometheus.HistogramVec
 }
 
+// launchLatencyBuckets are the buckets of the launch latency histograms in seconds, which are finer around
+// the usual launch latency objectives of tens of seconds.
+var launchLatencyBuckets = []float64{1, 2, 5, 10, 15, 20, 30, 45, 60, 90, 120, 300, 600}
+
+// executorPodMetricLabel is the label of per-executor metrics holding the name of the executor pod.
+const executorPodMetricLabel = "pod"
+
 func newSparkAppMetrics(prefix string, labels []string) *sparkAppMetrics {
 	validLabels := make([]string, len(labels))
 	for i, label := range labels {
@@ -98,23 +112,49 @@ func newSparkAppMetrics(prefix string, labels []string) *sparkAppMetrics {
 		},
 		validLabels,
 	)
+	sparkAppDriverLaunchLatency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    util.CreateValidMetricNameLabel(prefix, "spark_app_driver_launch_latency_seconds"),
+			Help:    "Time from Spark App Creation to the Driver Running via the Operator",
+			Buckets: launchLatencyBuckets,
+		},
+		validLabels,
+	)
+	sparkAppExecutorLaunchLatency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    util.CreateValidMetricNameLabel(prefix, "spark_app_executor_launch_latency_seconds"),
+			Help:    "Time from the Spark App Driver Running to the First Executor Running via the Operator",
+			Buckets: launchLatencyBuckets,
+		},
+		validLabels,
+	)
 	sparkAppRunningCount := util.NewPositiveGauge(util.CreateValidMetricNameLabel(prefix, "spark_app_running_count"),
 		"Spark App Running Count via the Operator", validLabels)
+	sparkAppExecutorGPUCount := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: util.CreateValidMetricNameLabel(prefix, "spark_app_executor_gpu_count"),
+			Help: "GPUs of each Running Spark App Executor in ML Mode via the Operator",
+		},
+		append(append([]string{}, validLabels...), executorPodMetricLabel),
+	)
 	sparkAppExecutorRunningCount := util.NewPositiveGauge(util.CreateValidMetricNameLabel(prefix,
 		"spark_app_executor_running_count"), "Spark App Running Executor Count via the Operator", validLabels)
 
 	return &sparkAppMetrics{
-		labels:                       validLabels,
-		prefix:                       prefix,
-		sparkAppSubmitCount:          sparkAppSubmitCount,
-		sparkAppSuccessCount:         sparkAppSuccessCount,
-		sparkAppFailureCount:         sparkAppFailureCount,
-		sparkAppRunningCount:         sparkAppRunningCount,
-		sparkAppSuccessExecutionTime: sparkAppSuccessExecutionTime,
-		sparkAppFailureExecutionTime: sparkAppFailureExecutionTime,
-		sparkAppExecutorRunningCount: sparkAppExecutorRunningCount,
-		sparkAppExecutorFailureCount: sparkAppExecutorFailureCount,
-		sparkAppExecutorSuccessCount: sparkAppExecutorSuccessCount,
+		labels:                        validLabels,
+		prefix:                        prefix,
+		sparkAppSubmitCount:           sparkAppSubmitCount,
+		sparkAppSuccessCount:          sparkAppSuccessCount,
+		sparkAppFailureCount:          sparkAppFailureCount,
+		sparkAppRunningCount:          sparkAppRunningCount,
+		sparkAppSuccessExecutionTime:  sparkAppSuccessExecutionTime,
+		sparkAppFailureExecutionTime:  sparkAppFailureExecutionTime,
+		sparkAppExecutorRunningCount:  sparkAppExecutorRunningCount,
+		sparkAppExecutorFailureCount:  sparkAppExecutorFailureCount,
+		sparkAppExecutorSuccessCount:  sparkAppExecutorSuccessCount,
+		sparkAppExecutorGPUCount:      sparkAppExecutorGPUCount,
+		sparkAppDriverLaunchLatency:   sparkAppDriverLaunchLatency,
+		sparkAppExecutorLaunchLatency: sparkAppExecutorLaunchLatency,
 	}
 }
 
@@ -126,12 +166,15 @@ func (sm *sparkAppMetrics) registerMetrics() {
 	util.RegisterMetric(sm.sparkAppFailureExecutionTime)
 	util.RegisterMetric(sm.sparkAppExecutorFailureCount)
 	util.RegisterMetric(sm.sparkAppExecutorSuccessCount)
+	util.RegisterMetric(sm.sparkAppExecutorGPUCount)
+	util.RegisterMetric(sm.sparkAppDriverLaunchLatency)
+	util.RegisterMetric(sm.sparkAppExecutorLaunchLatency)
 	sm.sparkAppRunningCount.Register()
 	sm.sparkAppExecutorRunningCount.Register()
 }
 
 func (sm *sparkAppMetrics) exportMetrics(oldApp, newApp *v1beta1.SparkApplication) {
-	metricLabels := fetchMetricLabels(newApp.Labels, sm.labels)
+	metricLabels := fetchMetricLabels(newApp.Namespace, newApp.Labels, sm.labels)
 	glog.V(2).Infof("Exporting metrics for %s; old status: %v new status: %v", newApp.Name,
 		oldApp.Status, newApp.Status)
 
@@ -187,6 +230,9 @@ func (sm *sparkAppMetrics) exportMetrics(oldApp, newApp *v1beta1.SparkApplicatio
 		}
 	}
 
+	sm.exportLaunchLatency(oldApp.Status.LaunchLatency, newApp.Status.LaunchLatency, metricLabels)
+	sm.exportExecutorGPUs(oldApp, newApp, metricLabels)
+
 	// Potential Executor status updates
 	for executor, newExecState := range newApp.Status.ExecutorState {
 		switch newExecState {
@@ -222,7 +268,60 @@ func (sm *sparkAppMetrics) exportMetrics(oldApp, newApp *v1beta1.SparkApplicatio
 	}
 }
 
-func fetchMetricLabels(specLabels map[string]string, labels []string) map[string]string {
+// exportLaunchLatency observes the launch latencies newly recorded in the status of an application.
+func (sm *sparkAppMetrics) exportLaunchLatency(oldLatency, newLatency *v1beta1.LaunchLatency, metricLabels map[string]string) {
+	if newLatency == nil {
+		return
+	}
+	if oldLatency == nil {
+		oldLatency = &v1beta1.LaunchLatency{}
+	}
+	if oldLatency.CreationToDriverRunningMillis == nil && newLatency.CreationToDriverRunningMillis != nil {
+		if m, err := sm.sparkAppDriverLaunchLatency.GetMetricWith(metricLabels); err != nil {
+			glog.Errorf("Error while exporting metrics: %v", err)
+		} else {
+			m.Observe(float64(*newLatency.CreationToDriverRunningMillis) / 1000)
+		}
+	}
+	if oldLatency.DriverToFirstExecutorRunningMillis == nil && newLatency.DriverToFirstExecutorRunningMillis != nil {
+		if m, err := sm.sparkAppExecutorLaunchLatency.GetMetricWith(metricLabels); err != nil {
+			glog.Errorf("Error while exporting metrics: %v", err)
+		} else {
+			m.Observe(float64(*newLatency.DriverToFirstExecutorRunningMillis) / 1000)
+		}
+	}
+}
+
+// exportExecutorGPUs exports the GPUs of the executors of ML mode applications that have started or stopped
+// running.
+func (sm *sparkAppMetrics) exportExecutorGPUs(oldApp, newApp *v1beta1.SparkApplication,
+	metricLabels map[string]string) {
+	if newApp.Spec.MLMode == nil || newApp.Spec.MLMode.GPU == nil {
+		return
+	}
+	for executor, newExecState := range newApp.Status.ExecutorState {
+		oldExecState := oldApp.Status.ExecutorState[executor]
+		if oldExecState == newExecState {
+			continue
+		}
+		labels := map[string]string{executorPodMetricLabel: executor}
+		for key, value := range metricLabels {
+			labels[key] = value
+		}
+		switch {
+		case newExecState == v1beta1.ExecutorRunningState:
+			if m, err := sm.sparkAppExecutorGPUCount.GetMetricWith(labels); err != nil {
+				glog.Errorf("Error while exporting metrics: %v", err)
+			} else {
+				m.Set(float64(newApp.Spec.MLMode.GPU.Quantity))
+			}
+		case oldExecState == v1beta1.ExecutorRunningState:
+			sm.sparkAppExecutorGPUCount.Delete(labels)
+		}
+	}
+}
+
+func fetchMetricLabels(namespace string, specLabels map[string]string, labels []string) map[string]string {
 	// Transform spec labels since our labels names might be not same as specLabels if we removed invalid characters.
 	validSpecLabels := make(map[string]string)
 	for labelKey, v := range specLabels {
@@ -232,7 +331,9 @@ func fetchMetricLabels(specLabels map[string]string, labels []string) map[string
 
 	metricLabels := make(map[string]string)
 	for _, label := range labels {
-		if value, ok := validSpecLabels[label]; ok {
+		if label == config.SparkAppNamespaceMetricLabel {
+			metricLabels[label] = namespace
+		} else if value, ok := validSpecLabels[label]; ok {
 			metricLabels[label] = value
 		} else {
 			metricLabels[label] = "Unknown"
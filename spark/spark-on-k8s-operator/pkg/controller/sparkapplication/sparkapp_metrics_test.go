@@ -21,7 +21,12 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	prometheus_model "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 )
 
 func TestSparkAppMetrics(t *testing.T) {
@@ -60,3 +65,63 @@ func TestSparkAppMetrics(t *testing.T) {
 	assert.Equal(t, float64(5), metrics.sparkAppExecutorRunningCount.Value(app1))
 	assert.Equal(t, float64(5), metrics.sparkAppRunningCount.Value(app1))
 }
+
+func TestFetchMetricLabels(t *testing.T) {
+	labels := []string{"app_id", config.SparkAppNamespaceMetricLabel, "team"}
+	assert.Equal(t,
+		map[string]string{"app_id": "test1", config.SparkAppNamespaceMetricLabel: "default", "team": "Unknown"},
+		fetchMetricLabels("default", map[string]string{"app-id": "test1"}, labels))
+}
+
+func TestExportLaunchLatency(t *testing.T) {
+	metrics := newSparkAppMetrics("", []string{"app-id"})
+	labels := map[string]string{"app_id": "test1"}
+	driverMillis := int64(12000)
+	executorMillis := int64(8000)
+
+	metrics.exportLaunchLatency(nil, &v1beta1.LaunchLatency{CreationToDriverRunningMillis: &driverMillis}, labels)
+	// The latency of the driver is only observed once.
+	metrics.exportLaunchLatency(
+		&v1beta1.LaunchLatency{CreationToDriverRunningMillis: &driverMillis},
+		&v1beta1.LaunchLatency{
+			CreationToDriverRunningMillis:      &driverMillis,
+			DriverToFirstExecutorRunningMillis: &executorMillis,
+		},
+		labels)
+
+	pb := &prometheus_model.Metric{}
+	metrics.sparkAppDriverLaunchLatency.With(labels).(prometheus.Metric).Write(pb)
+	assert.Equal(t, uint64(1), pb.GetHistogram().GetSampleCount())
+	assert.Equal(t, float64(12), pb.GetHistogram().GetSampleSum())
+	pb = &prometheus_model.Metric{}
+	metrics.sparkAppExecutorLaunchLatency.With(labels).(prometheus.Metric).Write(pb)
+	assert.Equal(t, uint64(1), pb.GetHistogram().GetSampleCount())
+	assert.Equal(t, float64(8), pb.GetHistogram().GetSampleSum())
+}
+
+func TestExportExecutorGPUs(t *testing.T) {
+	metrics := newSparkAppMetrics("", []string{"app-id"})
+	labels := map[string]string{"app_id": "test1"}
+	executorLabels := map[string]string{"app_id": "test1", "pod": "exec-1"}
+	oldApp := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			MLMode: &v1beta1.MLModeSpec{GPU: &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 2}},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			ExecutorState: map[string]v1beta1.ExecutorState{"exec-1": v1beta1.ExecutorPendingState},
+		},
+	}
+	newApp := oldApp.DeepCopy()
+	newApp.Status.ExecutorState["exec-1"] = v1beta1.ExecutorRunningState
+
+	metrics.exportExecutorGPUs(oldApp, newApp, labels)
+	pb := &prometheus_model.Metric{}
+	metrics.sparkAppExecutorGPUCount.With(executorLabels).Write(pb)
+	assert.Equal(t, float64(2), pb.GetGauge().GetValue())
+
+	// The GPUs of executors that have stopped running are no longer exported.
+	oldApp, newApp = newApp, newApp.DeepCopy()
+	newApp.Status.ExecutorState["exec-1"] = v1beta1.ExecutorFailedState
+	metrics.exportExecutorGPUs(oldApp, newApp, labels)
+	assert.False(t, metrics.sparkAppExecutorGPUCount.Delete(executorLabels))
+}
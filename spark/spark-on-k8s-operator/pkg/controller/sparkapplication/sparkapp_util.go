@@ -21,6 +21,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 	apiv1 "k8s.io/api/core/v1"
 )
 
@@ -42,15 +43,15 @@ func getSparkApplicationID(pod *apiv1.Pod) string {
 }
 
 func getDefaultDriverPodName(app *v1beta1.SparkApplication) string {
-	return fmt.Sprintf("%s-driver", app.Name)
+	return util.BuildName(app.Name, "driver", util.DNS1123SubdomainMaxLength)
 }
 
 func getDefaultUIServiceName(app *v1beta1.SparkApplication) string {
-	return fmt.Sprintf("%s-ui-svc", app.Name)
+	return util.BuildName(app.Name, "ui-svc", util.DNS1123LabelMaxLength)
 }
 
 func getDefaultUIIngressName(app *v1beta1.SparkApplication) string {
-	return fmt.Sprintf("%s-ui-ingress", app.Name)
+	return util.BuildName(app.Name, "ui-ingress", util.DNS1123SubdomainMaxLength)
 }
 
 func podPhaseToExecutorState(podPhase apiv1.PodPhase) v1beta1.ExecutorState {
@@ -68,10 +69,50 @@ func podPhaseToExecutorState(podPhase apiv1.PodPhase) v1beta1.ExecutorState {
 	}
 }
 
+func isAppTerminated(appState v1beta1.ApplicationStateType) bool {
+	return appState == v1beta1.CompletedState || appState == v1beta1.FailedState || appState == v1beta1.SkippedState
+}
+
 func isExecutorTerminated(executorState v1beta1.ExecutorState) bool {
 	return executorState == v1beta1.ExecutorCompletedState || executorState == v1beta1.ExecutorFailedState
 }
 
+// getDriverPodPhase returns the phase of the given driver pod. A driver pod whose Spark container has
+// terminated is considered terminated even if sidecar containers, e.g., the UI proxy, keep it running, and
+// succeeded if its Spark container succeeded before the operator stopped the UI proxy.
+func getDriverPodPhase(pod *apiv1.Pod) apiv1.PodPhase {
+	if pod.Status.Phase != apiv1.PodRunning && !isUIProxyStopped(pod) {
+		return pod.Status.Phase
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != sparkDriverContainerName || status.State.Terminated == nil {
+			continue
+		}
+		if status.State.Terminated.ExitCode == 0 {
+			return apiv1.PodSucceeded
+		}
+		return apiv1.PodFailed
+	}
+	return pod.Status.Phase
+}
+
+// getDriverFailureMessage describes why the given failed driver pod failed, e.g., because it was evicted or
+// because its Spark container exited with an error.
+func getDriverFailureMessage(pod *apiv1.Pod) string {
+	if pod.Status.Reason != "" && !isUIProxyStopped(pod) {
+		return fmt.Sprintf("driver pod failed with reason %s: %s", pod.Status.Reason, pod.Status.Message)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != sparkDriverContainerName || status.State.Terminated == nil {
+			continue
+		}
+		terminated := status.State.Terminated
+		return fmt.Sprintf("driver container terminated with exit code %d and reason %s: %s",
+			terminated.ExitCode, terminated.Reason, terminated.Message)
+	}
+	return ""
+}
+
 func driverPodPhaseToApplicationState(podPhase apiv1.PodPhase) v1beta1.ApplicationStateType {
 	switch podPhase {
 	case apiv1.PodPending:
@@ -31,11 +31,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
-)
-
-const (
-	sparkUIPortConfigurationKey = "spark.ui.port"
-	defaultSparkWebUIPort       = "4040"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 var ingressURLRegex = regexp.MustCompile("{{\\s*[$]appName\\s*}}")
@@ -57,7 +53,12 @@ type SparkIngress struct {
 	ingressURL  string
 }
 
-func createSparkUIIngress(app *v1beta1.SparkApplication, service SparkService, ingressURLFormat string, kubeClient clientset.Interface) (*SparkIngress, error) {
+func createSparkUIIngress(
+	app *v1beta1.SparkApplication,
+	service SparkService,
+	ingressURLFormat string,
+	metadata propagatedMetadata,
+	kubeClient clientset.Interface) (*SparkIngress, error) {
 	ingressURL := getSparkUIingressURL(ingressURLFormat, app.GetName())
 	ingress := extensions.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
@@ -87,6 +88,7 @@ func createSparkUIIngress(app *v1beta1.SparkApplication, service SparkService, i
 			}},
 		},
 	}
+	metadata.apply(&ingress.ObjectMeta)
 	glog.Infof("Creating an Ingress %s for the Spark UI for application %s", ingress.Name, app.Name)
 	_, err := kubeClient.ExtensionsV1beta1().Ingresses(ingress.Namespace).Create(&ingress)
 
@@ -101,12 +103,17 @@ func createSparkUIIngress(app *v1beta1.SparkApplication, service SparkService, i
 
 func createSparkUIService(
 	app *v1beta1.SparkApplication,
+	metadata propagatedMetadata,
 	kubeClient clientset.Interface) (*SparkService, error) {
 	portStr := getUITargetPort(app)
 	port, err := strconv.Atoi(portStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid Spark UI port: %s", portStr)
 	}
+	// Requests to the UI go through the OAuth2 proxy if there is one.
+	if app.Spec.Driver.UIProxy != nil {
+		port = int(util.GetUIProxyPort(app))
+	}
 
 	service := &apiv1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -132,6 +139,7 @@ func createSparkUIService(
 		},
 	}
 
+	metadata.apply(&service.ObjectMeta)
 	glog.Infof("Creating a service %s for the Spark UI for application %s", service.Name, app.Name)
 	service, err = kubeClient.CoreV1().Services(app.Namespace).Create(service)
 	if err != nil {
@@ -149,9 +157,9 @@ func createSparkUIService(
 // in Spec.SparkConf if it is present, otherwise the default port is returned.
 // Note that we don't attempt to get the port from Spec.SparkConfigMap.
 func getUITargetPort(app *v1beta1.SparkApplication) string {
-	port, ok := app.Spec.SparkConf[sparkUIPortConfigurationKey]
+	port, ok := app.Spec.SparkConf[config.SparkUIPortKey]
 	if ok {
 		return port
 	}
-	return defaultSparkWebUIPort
+	return config.DefaultSparkUIPort
 }
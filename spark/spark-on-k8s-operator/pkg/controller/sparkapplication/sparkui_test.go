@@ -40,7 +40,7 @@ func TestCreateSparkUIService(t *testing.T) {
 	}
 	testFn := func(test testcase, t *testing.T) {
 		fakeClient := fake.NewSimpleClientset()
-		sparkService, err := createSparkUIService(test.app, fakeClient)
+		sparkService, err := createSparkUIService(test.app, propagatedMetadata{}, fakeClient)
 		if err != nil {
 			if test.expectError {
 				return
@@ -80,7 +80,7 @@ func TestCreateSparkUIService(t *testing.T) {
 		}
 	}
 
-	defaultPort, err := strconv.Atoi(defaultSparkWebUIPort)
+	defaultPort, err := strconv.Atoi(config.DefaultSparkUIPort)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -93,7 +93,7 @@ func TestCreateSparkUIService(t *testing.T) {
 		},
 		Spec: v1beta1.SparkApplicationSpec{
 			SparkConf: map[string]string{
-				sparkUIPortConfigurationKey: "4041",
+				config.SparkUIPortKey: "4041",
 			},
 		},
 		Status: v1beta1.SparkApplicationStatus{
@@ -120,13 +120,28 @@ func TestCreateSparkUIService(t *testing.T) {
 		},
 		Spec: v1beta1.SparkApplicationSpec{
 			SparkConf: map[string]string{
-				sparkUIPortConfigurationKey: "4041x",
+				config.SparkUIPortKey: "4041x",
 			},
 		},
 		Status: v1beta1.SparkApplicationStatus{
 			SparkApplicationID: "foo-3",
 		},
 	}
+	app4 := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "foo-123",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{
+				UIProxy: &v1beta1.UIProxySpec{OIDCIssuerURL: "https://accounts.example.com"},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			SparkApplicationID: "foo-4",
+		},
+	}
 	testcases := []testcase{
 		{
 			name: "service with custom port",
@@ -154,6 +169,19 @@ func TestCreateSparkUIService(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "service with UI proxy",
+			app:  app4,
+			expectedService: SparkService{
+				serviceName: fmt.Sprintf("%s-ui-svc", app4.GetName()),
+				servicePort: config.DefaultUIProxyPort,
+			},
+			expectedSelector: map[string]string{
+				config.SparkAppNameLabel: "foo",
+				config.SparkRoleLabel:    config.SparkDriverRole,
+			},
+			expectError: false,
+		},
 		{
 			name:        "service with bad port configurations",
 			app:         app3,
@@ -192,7 +220,7 @@ func TestCreateSparkUIIngress(t *testing.T) {
 		ingressURL:  app.GetName() + ".ingress.clusterName.com",
 	}
 	fakeClient := fake.NewSimpleClientset()
-	sparkIngress, err := createSparkUIIngress(app, service, ingressFormat, fakeClient)
+	sparkIngress, err := createSparkUIIngress(app, service, ingressFormat, propagatedMetadata{}, fakeClient)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,205 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const (
+	// maxFieldValueLength is the length values of changed fields are truncated to in the status.
+	maxFieldValueLength = 256
+	// maxEventFieldPaths is the number of changed fields listed in events.
+	maxEventFieldPaths = 5
+)
+
+// hotSwappableFields are the JSON names of the fields of the spec getPodTemplateHash leaves out, which the
+// operator only reads itself while the application runs.
+var hotSwappableFields = map[string]bool{
+	"restartPolicy":         true,
+	"failureRetries":        true,
+	"retryInterval":         true,
+	"rotation":              true,
+	"outputCleanup":         true,
+	"driverLogCapture":      true,
+	"notifications":         true,
+	"priority":              true,
+	"preemptionPolicy":      true,
+	"executorIdleTimeout":   true,
+	"runHistory":            true,
+	"restartOnConfigChange": true,
+}
+
+// newSpecUpdateStatus describes the update of the spec of an application from the one of oldApp to the one of
+// newApp, which restarts the application if restarted is true.
+func newSpecUpdateStatus(oldApp, newApp *v1beta1.SparkApplication, restarted bool) *v1beta1.SpecUpdateStatus {
+	update := &v1beta1.SpecUpdateStatus{UpdateTime: metav1.Now(), Restarted: restarted}
+	changes, err := diffSpecs(oldApp, newApp)
+	if err != nil {
+		// Never happens for specs that have been unmarshaled from JSON.
+		glog.Errorf("failed to diff the specs of SparkApplication %s/%s: %v", newApp.Namespace, newApp.Name, err)
+		return update
+	}
+	for _, change := range changes {
+		if hotSwappableFields[getTopLevelField(change.Path)] {
+			update.HotSwappableFields = append(update.HotSwappableFields, change)
+		} else {
+			update.RestartRequiringFields = append(update.RestartRequiringFields, change)
+		}
+	}
+	return update
+}
+
+// diffSpecs returns the changed fields between the specs of the given applications, sorted by path.
+func diffSpecs(oldApp, newApp *v1beta1.SparkApplication) ([]v1beta1.FieldChange, error) {
+	oldSpec, err := getDefaultedSpecValue(oldApp)
+	if err != nil {
+		return nil, err
+	}
+	newSpec, err := getDefaultedSpecValue(newApp)
+	if err != nil {
+		return nil, err
+	}
+	var changes []v1beta1.FieldChange
+	diffValues("spec", oldSpec, newSpec, &changes)
+	return changes, nil
+}
+
+// getDefaultedSpecValue returns the JSON value of the defaulted spec of the given application.
+func getDefaultedSpecValue(app *v1beta1.SparkApplication) (interface{}, error) {
+	// Like in getPodTemplateHash, fields that were only defaulted on one side must not show up as changes.
+	defaulted := app.DeepCopy()
+	v1beta1.SetSparkApplicationDefaults(defaulted)
+	specBytes, err := json.Marshal(&defaulted.Spec)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(specBytes, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// diffValues appends the changes between the given JSON values at the given path to changes. Objects are compared
+// field by field, while any other values, including lists, are compared as a whole.
+func diffValues(path string, oldValue, newValue interface{}, changes *[]v1beta1.FieldChange) {
+	oldObject, oldIsObject := oldValue.(map[string]interface{})
+	newObject, newIsObject := newValue.(map[string]interface{})
+	if oldIsObject && newIsObject {
+		keys := make(map[string]bool)
+		for key := range oldObject {
+			keys[key] = true
+		}
+		for key := range newObject {
+			keys[key] = true
+		}
+		var sortedKeys []string
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+		for _, key := range sortedKeys {
+			diffValues(getFieldPath(path, key), oldObject[key], newObject[key], changes)
+		}
+		return
+	}
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+	*changes = append(*changes, v1beta1.FieldChange{
+		Path:     path,
+		OldValue: formatFieldValue(oldValue),
+		NewValue: formatFieldValue(newValue),
+	})
+}
+
+// getFieldPath returns the path of the field with the given key of the object at the given path. Keys of maps
+// like sparkConf often contain dots, so such keys are bracketed.
+func getFieldPath(path, key string) string {
+	if strings.ContainsAny(key, "./[]") {
+		return path + "[" + key + "]"
+	}
+	return path + "." + key
+}
+
+// getTopLevelField returns the name of the field of the spec the field at the given path is in.
+func getTopLevelField(path string) string {
+	field := strings.TrimPrefix(path, "spec.")
+	if i := strings.IndexAny(field, ".["); i >= 0 {
+		field = field[:i]
+	}
+	return field
+}
+
+// formatFieldValue returns the JSON of the given value, truncated to maxFieldValueLength, or an empty string for
+// an unset value.
+func formatFieldValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	if len(valueBytes) > maxFieldValueLength {
+		return string(valueBytes[:maxFieldValueLength-3]) + "..."
+	}
+	return string(valueBytes)
+}
+
+// formatFieldPaths returns a list of the paths of the given changed fields for events.
+func formatFieldPaths(changes []v1beta1.FieldChange) string {
+	var paths []string
+	for i, change := range changes {
+		if i == maxEventFieldPaths {
+			paths = append(paths, fmt.Sprintf("%d more", len(changes)-i))
+			break
+		}
+		paths = append(paths, change.Path)
+	}
+	return strings.Join(paths, ", ")
+}
+
+// getSpecUpdateMessage returns the message of the event reporting the given processed update of the spec of the
+// given application.
+func getSpecUpdateMessage(app *v1beta1.SparkApplication, update *v1beta1.SpecUpdateStatus) string {
+	if !update.Restarted {
+		message := fmt.Sprintf("Successfully applied spec update for SparkApplication %s without a restart", app.Name)
+		if len(update.HotSwappableFields) > 0 {
+			message += ", changed " + formatFieldPaths(update.HotSwappableFields)
+		}
+		return message
+	}
+	message := fmt.Sprintf("Successfully processed spec update for SparkApplication %s, restarting it", app.Name)
+	if len(update.RestartRequiringFields) > 0 {
+		message += " for " + formatFieldPaths(update.RestartRequiringFields)
+	}
+	if len(update.HotSwappableFields) > 0 {
+		message += "; " + formatFieldPaths(update.HotSwappableFields) + " would not have required a restart"
+	}
+	return message
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestNewSpecUpdateStatus(t *testing.T) {
+	oldApp := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Image:         stringptr("foo-image:v1"),
+			SparkConf:     map[string]string{"spark.eventLog.enabled": "true"},
+			RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+		},
+	}
+	newApp := oldApp.DeepCopy()
+	newApp.Spec.Image = stringptr("foo-image:v2")
+	newApp.Spec.SparkConf["spark.eventLog.enabled"] = "false"
+	newApp.Spec.RestartPolicy = v1beta1.RestartPolicy{Type: v1beta1.OnFailure, OnFailureRetries: int32ptr(3)}
+
+	update := newSpecUpdateStatus(oldApp, newApp, true)
+	assert.True(t, update.Restarted)
+	assert.False(t, update.UpdateTime.IsZero())
+	assert.Equal(t, []v1beta1.FieldChange{
+		{Path: "spec.image", OldValue: `"foo-image:v1"`, NewValue: `"foo-image:v2"`},
+		{Path: "spec.sparkConf[spark.eventLog.enabled]", OldValue: `"true"`, NewValue: `"false"`},
+	}, update.RestartRequiringFields)
+	// The retry intervals are defaulted for the OnFailure policy.
+	assert.Equal(t, []v1beta1.FieldChange{
+		{Path: "spec.restartPolicy.onFailureRetries", NewValue: "3"},
+		{Path: "spec.restartPolicy.onFailureRetryInterval", NewValue: "5"},
+		{Path: "spec.restartPolicy.onSubmissionFailureRetryInterval", NewValue: "5"},
+		{Path: "spec.restartPolicy.type", OldValue: `"Never"`, NewValue: `"OnFailure"`},
+	}, update.HotSwappableFields)
+	assert.Equal(t, "Successfully processed spec update for SparkApplication foo, restarting it for spec.image, "+
+		"spec.sparkConf[spark.eventLog.enabled]; spec.restartPolicy.onFailureRetries, "+
+		"spec.restartPolicy.onFailureRetryInterval, spec.restartPolicy.onSubmissionFailureRetryInterval, "+
+		"spec.restartPolicy.type would not have required a restart", getSpecUpdateMessage(newApp, update))
+
+	// Fields that are only defaulted on one side are not changes.
+	newApp = oldApp.DeepCopy()
+	newApp.Spec.RestartPolicy.Type = ""
+	update = newSpecUpdateStatus(oldApp, newApp, false)
+	assert.Nil(t, update.RestartRequiringFields)
+	assert.Nil(t, update.HotSwappableFields)
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	assert.Equal(t, "", formatFieldValue(nil))
+	assert.Equal(t, `["a","b"]`, formatFieldValue([]interface{}{"a", "b"}))
+	long := formatFieldValue(strings.Repeat("a", 2*maxFieldValueLength))
+	assert.Equal(t, maxFieldValueLength, len(long))
+	assert.True(t, strings.HasSuffix(long, "..."))
+}
+
+func TestFormatFieldPaths(t *testing.T) {
+	var changes []v1beta1.FieldChange
+	for _, path := range []string{"spec.a", "spec.b", "spec.c", "spec.d", "spec.e", "spec.f", "spec.g"} {
+		changes = append(changes, v1beta1.FieldChange{Path: path})
+	}
+	assert.Equal(t, "spec.a, spec.b", formatFieldPaths(changes[:2]))
+	assert.Equal(t, "spec.a, spec.b, spec.c, spec.d, spec.e, 2 more", formatFieldPaths(changes))
+}
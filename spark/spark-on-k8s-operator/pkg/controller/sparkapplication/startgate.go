@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// setStartGate creates or updates the ConfigMap backing the start gate of the given application, which the
+// webhook mounts into the driver. The gate file only exists while the gate is open.
+func (c *Controller) setStartGate(app *v1beta1.SparkApplication, open bool) error {
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: buildAppResourceObjectMeta(app, util.GetStartGateConfigMapName(app)),
+		Data:       map[string]string{},
+	}
+	if open {
+		configMap.Data[config.StartGateKey] = "true"
+	}
+
+	c.getPropagatedMetadata(app).apply(&configMap.ObjectMeta)
+	existing, err := c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Get(configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Create(configMap)
+	} else if err == nil {
+		existing.Data = configMap.Data
+		_, err = c.kubeClient.CoreV1().ConfigMaps(app.Namespace).Update(existing)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set the start gate ConfigMap %s/%s: %v", app.Namespace, configMap.Name, err)
+	}
+	return nil
+}
+
+// countRunningExecutors returns the number of running executors of the given application.
+func countRunningExecutors(app *v1beta1.SparkApplication) int32 {
+	var running int32
+	for _, state := range app.Status.ExecutorState {
+		if state == v1beta1.ExecutorRunningState {
+			running++
+		}
+	}
+	return running
+}
+
+// shouldOpenStartGate tells if the start gate of the given application is closed and enough of its executors
+// are running to open it.
+func shouldOpenStartGate(app *v1beta1.SparkApplication) bool {
+	if app.Spec.MinExecutorsBeforeStart == nil || !app.Status.StartGateOpenTime.IsZero() {
+		return false
+	}
+	switch app.Status.AppState.State {
+	case v1beta1.SubmittedState, v1beta1.RunningState:
+	default:
+		return false
+	}
+	return countRunningExecutors(app) >= *app.Spec.MinExecutorsBeforeStart
+}
+
+// openStartGate opens the start gate of the given application once enough of its executors are running.
+func (c *Controller) openStartGate(app *v1beta1.SparkApplication) {
+	if !shouldOpenStartGate(app) {
+		return
+	}
+	if err := c.setStartGate(app, true); err != nil {
+		glog.Errorf("failed to open the start gate of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return
+	}
+	app.Status.StartGateOpenTime = metav1.Now()
+	c.recorder.Eventf(app, apiv1.EventTypeNormal, "SparkApplicationStartGateOpened",
+		"Start gate opened with %d executors running", countRunningExecutors(app))
+}
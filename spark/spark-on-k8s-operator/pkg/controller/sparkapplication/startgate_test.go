@@ -0,0 +1,74 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestShouldOpenStartGate(t *testing.T) {
+	minExecutors := int32(2)
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{MinExecutorsBeforeStart: &minExecutors},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				"exec-1": v1beta1.ExecutorRunningState,
+				"exec-2": v1beta1.ExecutorPendingState,
+			},
+		},
+	}
+	assert.False(t, shouldOpenStartGate(app))
+
+	app.Status.ExecutorState["exec-2"] = v1beta1.ExecutorRunningState
+	assert.True(t, shouldOpenStartGate(app))
+
+	app.Status.StartGateOpenTime = metav1.Now()
+	assert.False(t, shouldOpenStartGate(app))
+
+	app.Status.StartGateOpenTime = metav1.Time{}
+	app.Status.AppState.State = v1beta1.FailingState
+	assert.False(t, shouldOpenStartGate(app))
+
+	app.Status.AppState.State = v1beta1.RunningState
+	app.Spec.MinExecutorsBeforeStart = nil
+	assert.False(t, shouldOpenStartGate(app))
+}
+
+func TestSetStartGate(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-123"},
+	}
+	ctrl, _ := newFakeController(app)
+
+	assert.Nil(t, ctrl.setStartGate(app, false))
+	configMap, err := ctrl.kubeClient.CoreV1().ConfigMaps("default").Get("foo-start-gate", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, configMap.Data)
+	assert.Equal(t, app.UID, configMap.OwnerReferences[0].UID)
+
+	assert.Nil(t, ctrl.setStartGate(app, true))
+	configMap, err = ctrl.kubeClient.CoreV1().ConfigMaps("default").Get("foo-start-gate", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{config.StartGateKey: "true"}, configMap.Data)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// syncStateLabel sets the state label of the given application to its state, so that applications can be listed
+// by state with a label selector rather than by listing all of them. It returns the patched application, or the
+// given one if the label is up to date or patching it failed, which is retried on the next status update.
+func (c *Controller) syncStateLabel(app *v1beta1.SparkApplication) *v1beta1.SparkApplication {
+	state := string(app.Status.AppState.State)
+	if state == "" || app.Labels[config.SparkAppStateLabel] == state {
+		return app
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{config.SparkAppStateLabel: state},
+		},
+	})
+	if err != nil {
+		glog.Errorf("failed to marshal the state label of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return app
+	}
+	patched, err := c.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Patch(app.Name,
+		types.MergePatchType, patch)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return app
+		}
+		glog.Warningf("failed to set the state label of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return app
+	}
+	return patched
+}
+
+// withoutStateLabel returns the given labels without the state label, which only changes along with the state.
+func withoutStateLabel(labels map[string]string) map[string]string {
+	if _, ok := labels[config.SparkAppStateLabel]; !ok {
+		return labels
+	}
+	var result map[string]string
+	for key, value := range labels {
+		if key == config.SparkAppStateLabel {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(labels)-1)
+		}
+		result[key] = value
+	}
+	return result
+}
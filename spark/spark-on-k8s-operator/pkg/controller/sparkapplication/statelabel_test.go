@@ -0,0 +1,64 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestSyncStateLabel(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Labels: map[string]string{"team": "ads"}},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.FailedState},
+		},
+	}
+	ctrl, _ := newFakeController(app)
+	if _, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := ctrl.updateApplicationStatusWithRetries(app, func(status *v1beta1.SparkApplicationStatus) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"team": "ads", config.SparkAppStateLabel: "FAILED"}, updated.Labels)
+
+	stored, err := ctrl.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name,
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "FAILED", stored.Labels[config.SparkAppStateLabel])
+
+	// New applications are not labeled.
+	app = &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"}}
+	assert.Equal(t, app, ctrl.syncStateLabel(app))
+}
+
+func TestWithoutStateLabel(t *testing.T) {
+	assert.Nil(t, withoutStateLabel(nil))
+	assert.Nil(t, withoutStateLabel(map[string]string{config.SparkAppStateLabel: "RUNNING"}))
+	assert.Equal(t, map[string]string{"team": "ads"},
+		withoutStateLabel(map[string]string{"team": "ads", config.SparkAppStateLabel: "RUNNING"}))
+}
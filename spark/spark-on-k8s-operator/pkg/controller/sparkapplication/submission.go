@@ -17,18 +17,21 @@ limitations under the License.
 package sparkapplication
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 const (
@@ -37,51 +40,160 @@ const (
 	kubernetesServicePortEnvVar = "KUBERNETES_SERVICE_PORT"
 )
 
-// submission includes information of a Spark application to be submitted.
-type submission struct {
-	namespace string
-	name      string
-	args      []string
+// Submission includes information of a Spark application to be submitted.
+type Submission struct {
+	Namespace string
+	Name      string
+	// Args are the arguments of spark-submit for the application.
+	Args []string
+	// Env is a list of additional environment variables in the form of "key=value" for spark-submit.
+	Env []string
+	// SparkHome is the Spark distribution to run spark-submit of. Defaults to SPARK_HOME if empty.
+	SparkHome string
+	// App is the application as submitted, with the defaults and settings of the operator applied.
+	App *v1beta1.SparkApplication
+	// Output is the output of spark-submit, set by submitters running it.
+	Output *v1beta1.SubmissionOutput
 }
 
-func newSubmission(args []string, app *v1beta1.SparkApplication) *submission {
-	return &submission{
-		namespace: app.Namespace,
-		name:      app.Name,
-		args:      args,
+func newSubmission(args []string, app *v1beta1.SparkApplication) *Submission {
+	return &Submission{
+		Namespace: app.Namespace,
+		Name:      app.Name,
+		Args:      args,
+		App:       app,
 	}
 }
 
-func runSparkSubmit(submission *submission) (bool, error) {
-	sparkHome, present := os.LookupEnv(sparkHomeEnvVar)
-	if !present {
-		glog.Error("SPARK_HOME is not specified")
+// getSparkSubmitCommand returns the path of spark-submit in the Spark distribution of the given submission.
+func getSparkSubmitCommand(submission *Submission) string {
+	sparkHome := submission.SparkHome
+	if sparkHome == "" {
+		var present bool
+		if sparkHome, present = os.LookupEnv(sparkHomeEnvVar); !present {
+			glog.Error("SPARK_HOME is not specified")
+		}
 	}
-	var command = filepath.Join(sparkHome, "/bin/spark-submit")
+	return filepath.Join(sparkHome, "/bin/spark-submit")
+}
 
-	cmd := execCommand(command, submission.args...)
+func runSparkSubmit(submission *Submission, sandbox *SubmissionSandbox) (bool, error) {
+	if sandbox == nil {
+		sandbox = &SubmissionSandbox{}
+	}
+	tmpDir, err := sandbox.createTmpDir(submission)
+	if err != nil {
+		return false, err
+	}
+	if tmpDir != "" {
+		defer os.RemoveAll(tmpDir)
+	}
+	cgroup, err := sandbox.createCgroup(submission)
+	if err != nil {
+		return false, err
+	}
+	if cgroup != "" {
+		defer func() {
+			if err := os.Remove(cgroup); err != nil {
+				glog.Warningf("failed to remove cgroup %s: %v", cgroup, err)
+			}
+		}()
+	}
+
+	cmd := execCommand(getSparkSubmitCommand(submission), submission.Args...)
+	env := submission.Env
+	if submission.SparkHome != "" {
+		// spark-submit loads the default configuration and jars of the distribution in SPARK_HOME.
+		env = append(env, fmt.Sprintf("%s=%s", sparkHomeEnvVar, submission.SparkHome))
+	}
+	if env = sandbox.buildEnv(os.Environ(), env, tmpDir); env != nil {
+		cmd.Env = env
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 	glog.V(2).Infof("spark-submit arguments: %v", cmd.Args)
-	output, err := cmd.Output()
-	glog.V(3).Infof("spark-submit output: %s", string(output))
+	timedOut, err := runSandboxed(cmd, sandbox, cgroup)
+	glog.V(3).Infof("spark-submit output: %s", stdout.String())
+	submission.Output = &v1beta1.SubmissionOutput{
+		TimedOut: timedOut,
+		Stdout:   tailOutput(stdout.Bytes()),
+		Stderr:   tailOutput(stderr.Bytes()),
+	}
+	if cmd.ProcessState != nil {
+		submission.Output.ExitCode = int32(cmd.ProcessState.ExitCode())
+	}
+	if timedOut {
+		return false, fmt.Errorf("spark-submit for SparkApplication %s/%s timed out after %v", submission.Namespace,
+			submission.Name, sandbox.Timeout)
+	}
 	if err != nil {
 		var errorMsg string
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			errorMsg = string(exitErr.Stderr)
+		if _, ok := err.(*exec.ExitError); ok {
+			errorMsg = stderr.String()
 		}
 		// The driver pod of the application already exists.
 		if strings.Contains(errorMsg, podAlreadyExistsErrorCode) {
-			glog.Warningf("trying to resubmit an already submitted SparkApplication %s/%s", submission.namespace, submission.name)
+			glog.Warningf("trying to resubmit an already submitted SparkApplication %s/%s", submission.Namespace, submission.Name)
 			return false, nil
 		}
 		if errorMsg != "" {
-			return false, fmt.Errorf("failed to run spark-submit for SparkApplication %s/%s: %s", submission.namespace, submission.name, errorMsg)
+			return false, fmt.Errorf("failed to run spark-submit for SparkApplication %s/%s: %s", submission.Namespace, submission.Name, errorMsg)
 		}
-		return false, fmt.Errorf("failed to run spark-submit for SparkApplication %s/%s: %v", submission.namespace, submission.name, err)
+		return false, fmt.Errorf("failed to run spark-submit for SparkApplication %s/%s: %v", submission.Namespace, submission.Name, err)
 	}
 
 	return true, nil
 }
 
+// runSandboxed runs the given command in the given cgroup, if any, killing it if it runs longer than the timeout
+// of the given sandbox. It returns whether the command timed out.
+func runSandboxed(cmd *exec.Cmd, sandbox *SubmissionSandbox, cgroup string) (bool, error) {
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	if cgroup != "" {
+		if err := addToCgroup(cgroup, cmd.Process.Pid); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return false, fmt.Errorf("failed to add spark-submit to cgroup %s: %v", cgroup, err)
+		}
+	}
+	if sandbox.Timeout <= 0 {
+		return false, cmd.Wait()
+	}
+	timedOut := make(chan struct{})
+	timer := time.AfterFunc(sandbox.Timeout, func() {
+		close(timedOut)
+		cmd.Process.Kill()
+	})
+	err := cmd.Wait()
+	if !timer.Stop() {
+		<-timedOut
+		return true, err
+	}
+	return false, err
+}
+
+// buildImpersonationEnv returns the environment variables that make spark-submit impersonate the user who
+// submitted the given SparkApplication when creating the driver resources.
+func buildImpersonationEnv(app *v1beta1.SparkApplication) ([]string, error) {
+	username := app.Annotations[config.SubmittedByAnnotation]
+	if username == "" {
+		return nil, fmt.Errorf("impersonation is enabled but no submitting user is recorded for SparkApplication %s/%s",
+			app.Namespace, app.Name)
+	}
+
+	opts := []string{fmt.Sprintf("-D%s=%s", config.KubernetesImpersonateUserProperty, username)}
+	if groups := app.Annotations[config.SubmittedByGroupsAnnotation]; groups != "" {
+		opts = append(opts, fmt.Sprintf("-D%s=%s", config.KubernetesImpersonateGroupProperty, groups))
+	}
+	if existing, ok := os.LookupEnv(config.SparkSubmitOptsEnvVar); ok && existing != "" {
+		opts = append([]string{existing}, opts...)
+	}
+	return []string{fmt.Sprintf("%s=%s", config.SparkSubmitOptsEnvVar, strings.Join(opts, " "))}, nil
+}
+
 func buildSubmissionCommandArgs(app *v1beta1.SparkApplication) ([]string, error) {
 	var args []string
 	if app.Spec.MainClass != nil {
@@ -94,6 +206,9 @@ func buildSubmissionCommandArgs(app *v1beta1.SparkApplication) ([]string, error)
 
 	args = append(args, "--master", masterURL)
 	args = append(args, "--deploy-mode", string(app.Spec.Mode))
+	if app.Spec.ProxyUser != nil {
+		args = append(args, "--proxy-user", *app.Spec.ProxyUser)
+	}
 	args = append(args, "--conf", fmt.Sprintf("spark.kubernetes.namespace=%s", app.Namespace))
 	args = append(args, "--conf", fmt.Sprintf("spark.app.name=%s", app.Name))
 	args = append(args, "--conf", fmt.Sprintf("spark.kubernetes.driver.pod.name=%s", getDefaultDriverPodName(app)))
@@ -136,11 +251,11 @@ func buildSubmissionCommandArgs(app *v1beta1.SparkApplication) ([]string, error)
 			*app.Spec.SparkConfigMap))
 	}
 
-	if app.Spec.HadoopConfigMap != nil {
+	if hadoopConfigMap := util.GetHadoopConfigMapName(app); hadoopConfigMap != nil {
 		args = append(args, "--conf", config.GetDriverAnnotationOption(config.HadoopConfigMapAnnotation,
-			*app.Spec.HadoopConfigMap))
+			*hadoopConfigMap))
 		args = append(args, "--conf", config.GetExecutorAnnotationOption(config.HadoopConfigMapAnnotation,
-			*app.Spec.HadoopConfigMap))
+			*hadoopConfigMap))
 	}
 
 	// Add Spark configuration properties.
@@ -175,6 +290,23 @@ func buildSubmissionCommandArgs(app *v1beta1.SparkApplication) ([]string, error)
 	for _, option := range options {
 		args = append(args, "--conf", option)
 	}
+	for _, option := range addResourceProfileConfOptions(app) {
+		args = append(args, "--conf", option)
+	}
+	options, err = addMLModeConfOptions(app)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		args = append(args, "--conf", option)
+	}
+	options, err = addGPUConfOptions(app)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		args = append(args, "--conf", option)
+	}
 
 	if app.Spec.MainApplicationFile != nil {
 		// Add the main application file if it is present.
@@ -215,8 +347,12 @@ func getOwnerReference(app *v1beta1.SparkApplication) *metav1.OwnerReference {
 func addDependenciesConfOptions(app *v1beta1.SparkApplication) []string {
 	var depsConfOptions []string
 
-	if len(app.Spec.Deps.Jars) > 0 {
-		depsConfOptions = append(depsConfOptions, "--jars", strings.Join(app.Spec.Deps.Jars, ","))
+	jars := app.Spec.Deps.Jars
+	if rapidsJar := getRapidsJar(app); rapidsJar != "" {
+		jars = append(append([]string{}, jars...), rapidsJar)
+	}
+	if len(jars) > 0 {
+		depsConfOptions = append(depsConfOptions, "--jars", strings.Join(jars, ","))
 	}
 	if len(app.Spec.Deps.Files) > 0 {
 		depsConfOptions = append(depsConfOptions, "--files", strings.Join(app.Spec.Deps.Files, ","))
@@ -256,7 +392,7 @@ func addDriverConfOptions(app *v1beta1.SparkApplication) ([]string, error) {
 	driverConfOptions = append(driverConfOptions,
 		fmt.Sprintf("%s%s=%s", config.SparkDriverLabelKeyPrefix, config.LaunchedBySparkOperatorLabel, "true"))
 
-	driverPodName := fmt.Sprintf("%s-driver", app.GetName())
+	driverPodName := getDefaultDriverPodName(app)
 	if app.Spec.Driver.PodName != nil {
 		driverPodName = *app.Spec.Driver.PodName
 	}
@@ -1,5 +1,5 @@
 /*
-Copyright 2017 Google LLC
+Copyright 2018 Google LLC
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -15,3 +15,44 @@ limitations under the License.
 */
 
 package sparkapplication
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestBuildImpersonationEnv(t *testing.T) {
+	os.Unsetenv(config.SparkSubmitOptsEnvVar)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+
+	_, err := buildImpersonationEnv(app)
+	assert.NotNil(t, err)
+
+	app.Annotations = map[string]string{
+		config.SubmittedByAnnotation:       "alice",
+		config.SubmittedByGroupsAnnotation: "team-a,system:authenticated",
+	}
+	env, err := buildImpersonationEnv(app)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"SPARK_SUBMIT_OPTS=-Dkubernetes.impersonate.username=alice " +
+			"-Dkubernetes.impersonate.group=team-a,system:authenticated"}, env)
+
+	os.Setenv(config.SparkSubmitOptsEnvVar, "-Dfoo=bar")
+	defer os.Unsetenv(config.SparkSubmitOptsEnvVar)
+	delete(app.Annotations, config.SubmittedByGroupsAnnotation)
+	env, err = buildImpersonationEnv(app)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SPARK_SUBMIT_OPTS=-Dfoo=bar -Dkubernetes.impersonate.username=alice"}, env)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// The names of the built-in submitters.
+const (
+	SparkSubmitSubmitterName = "spark-submit"
+	NativeSubmitterName      = "native"
+	RemoteSubmitterName      = "remote"
+	DryRunSubmitterName      = "dry-run"
+)
+
+// remoteSubmissionTimeout is how long a remote submission service may take to submit an application.
+const remoteSubmissionTimeout = 2 * time.Minute
+
+// Submitter submits the runs of SparkApplications. The controller picks the submitter of an application by
+// name, so submission backends can be added by registering them with the controller.
+type Submitter interface {
+	// Submit submits the given submission. It returns false without an error if the run has already been
+	// submitted, e.g., because its driver pod already exists.
+	Submit(submission *Submission) (bool, error)
+}
+
+// SparkSubmitter submits applications by running spark-submit.
+type SparkSubmitter struct {
+	// Sandbox restricts the environment spark-submit runs in, if not nil.
+	Sandbox *SubmissionSandbox
+}
+
+// NewSparkSubmitter creates a SparkSubmitter running spark-submit in the given sandbox.
+func NewSparkSubmitter(sandbox *SubmissionSandbox) *SparkSubmitter {
+	return &SparkSubmitter{Sandbox: sandbox}
+}
+
+func (s *SparkSubmitter) Submit(submission *Submission) (bool, error) {
+	return runSparkSubmit(submission, s.Sandbox)
+}
+
+// DryRunSubmitter only renders the spark-submit command of applications, which it logs and fails the
+// submission with, so it shows up in the status of the application.
+type DryRunSubmitter struct{}
+
+func (s *DryRunSubmitter) Submit(submission *Submission) (bool, error) {
+	command := append([]string{getSparkSubmitCommand(submission)}, submission.Args...)
+	rendered := strings.Join(command, " ")
+	glog.Infof("Dry run of SparkApplication %s/%s: %s", submission.Namespace, submission.Name, rendered)
+	return false, fmt.Errorf("dry run, not submitted: %s", rendered)
+}
+
+// RemoteSubmitter hands submissions to a submission service, which runs spark-submit outside of the operator
+// against the cluster the operator watches. The submission is posted to the URL of the service as a
+// remoteSubmission in JSON, and the service responds with a remoteSubmissionResult.
+type RemoteSubmitter struct {
+	url    string
+	client *http.Client
+}
+
+// remoteSubmission is the request body of a RemoteSubmitter.
+type remoteSubmission struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Args      []string `json:"args"`
+	Env       []string `json:"env,omitempty"`
+	SparkHome string   `json:"sparkHome,omitempty"`
+}
+
+// remoteSubmissionResult is the response body of a submission service.
+type remoteSubmissionResult struct {
+	// Submitted is false if the application had already been submitted.
+	Submitted bool `json:"submitted"`
+	// Error is why the submission failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// NewRemoteSubmitter creates a RemoteSubmitter posting submissions to the given URL.
+func NewRemoteSubmitter(url string) *RemoteSubmitter {
+	return &RemoteSubmitter{url: url, client: &http.Client{Timeout: remoteSubmissionTimeout}}
+}
+
+func (s *RemoteSubmitter) Submit(submission *Submission) (bool, error) {
+	body, err := json.Marshal(&remoteSubmission{
+		Namespace: submission.Namespace,
+		Name:      submission.Name,
+		Args:      submission.Args,
+		Env:       submission.Env,
+		SparkHome: submission.SparkHome,
+	})
+	if err != nil {
+		return false, err
+	}
+	response, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to submit SparkApplication %s/%s to %s: %v", submission.Namespace,
+			submission.Name, s.url, err)
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the response of %s: %v", s.url, err)
+	}
+
+	var result remoteSubmissionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		if response.StatusCode != http.StatusOK {
+			return false, fmt.Errorf("failed to submit SparkApplication %s/%s to %s: %s", submission.Namespace,
+				submission.Name, s.url, response.Status)
+		}
+		return false, fmt.Errorf("invalid response of %s: %v", s.url, err)
+	}
+	if result.Error != "" || response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to submit SparkApplication %s/%s to %s: %s %s", submission.Namespace,
+			submission.Name, s.url, response.Status, result.Error)
+	}
+	return result.Submitted, nil
+}
+
+// RegisterSubmitter registers the given submitter with the given name, by which applications and the default
+// submitter of the operator refer to it.
+func (c *Controller) RegisterSubmitter(name string, submitter Submitter) {
+	c.submitters[name] = submitter
+}
+
+// SetDefaultSubmitter sets the submitter of applications that do not name one.
+func (c *Controller) SetDefaultSubmitter(name string) error {
+	if _, ok := c.submitters[name]; !ok {
+		return fmt.Errorf("unknown submitter %q", name)
+	}
+	c.defaultSubmitter = name
+	return nil
+}
+
+// getSubmitter returns the submitter of the given application.
+func (c *Controller) getSubmitter(app *v1beta1.SparkApplication) (Submitter, error) {
+	name := c.defaultSubmitter
+	if app.Spec.Submitter != nil {
+		name = *app.Spec.Submitter
+	}
+	submitter, ok := c.submitters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown submitter %q", name)
+	}
+	return submitter, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestDryRunSubmitter(t *testing.T) {
+	submission := &Submission{
+		Namespace: "default",
+		Name:      "foo",
+		Args:      []string{"--conf", "spark.app.name=foo", "local:///app.jar"},
+		SparkHome: "/opt/spark",
+	}
+	submitted, err := (&DryRunSubmitter{}).Submit(submission)
+	assert.False(t, submitted)
+	if err == nil {
+		t.Fatal("expected the dry run to fail the submission")
+	}
+	assert.True(t, strings.HasPrefix(err.Error(), "dry run, not submitted: /opt/spark/bin/spark-submit --conf"))
+	assert.True(t, strings.HasSuffix(err.Error(), "local:///app.jar"))
+}
+
+func TestRemoteSubmitter(t *testing.T) {
+	var received remoteSubmission
+	var result remoteSubmissionResult
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(&result)
+	}))
+	defer server.Close()
+
+	submitter := NewRemoteSubmitter(server.URL)
+	submission := &Submission{
+		Namespace: "default",
+		Name:      "foo",
+		Args:      []string{"--master", "k8s://https://kubernetes.default.svc", "local:///app.jar"},
+		Env:       []string{"HADOOP_USER_NAME=alice"},
+	}
+
+	result = remoteSubmissionResult{Submitted: true}
+	submitted, err := submitter.Submit(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, submitted)
+	assert.Equal(t, "default", received.Namespace)
+	assert.Equal(t, "foo", received.Name)
+	assert.Equal(t, submission.Args, received.Args)
+	assert.Equal(t, submission.Env, received.Env)
+
+	// An application that has already been submitted is not submitted again.
+	result = remoteSubmissionResult{Submitted: false}
+	submitted, err = submitter.Submit(submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, submitted)
+
+	// Errors of the service fail the submission.
+	result = remoteSubmissionResult{Error: "invalid key"}
+	submitted, err = submitter.Submit(submission)
+	assert.False(t, submitted)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "invalid key"))
+
+	status = http.StatusInternalServerError
+	result = remoteSubmissionResult{}
+	_, err = submitter.Submit(submission)
+	assert.NotNil(t, err)
+}
+
+func TestGetSubmitter(t *testing.T) {
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	ctrl, _ := newFakeController(app)
+
+	submitter, err := ctrl.getSubmitter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.IsType(t, &SparkSubmitter{}, submitter)
+
+	// Applications can pick another submitter.
+	app.Spec.Submitter = stringptr(NativeSubmitterName)
+	submitter, err = ctrl.getSubmitter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.IsType(t, &NativePodBuilder{}, submitter)
+
+	app.Spec.Submitter = stringptr(RemoteSubmitterName)
+	_, err = ctrl.getSubmitter(app)
+	assert.NotNil(t, err)
+
+	// The remote submitter is only available once it has been registered.
+	assert.NotNil(t, ctrl.SetDefaultSubmitter(RemoteSubmitterName))
+	ctrl.RegisterSubmitter(RemoteSubmitterName, NewRemoteSubmitter("http://localhost"))
+	assert.Nil(t, ctrl.SetDefaultSubmitter(RemoteSubmitterName))
+	app.Spec.Submitter = nil
+	submitter, err = ctrl.getSubmitter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.IsType(t, &RemoteSubmitter{}, submitter)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const jobCancellationTimeout = 5 * time.Second
+
+// terminateDriverPod shuts down the driver of the given application in two phases. The running Spark jobs are
+// cancelled through the driver UI first, so they stop writing output, and the driver pod is then deleted with
+// its termination grace period, in which Spark stops the SparkContext and output committers clean up. Executor
+// pods are owned by the driver pod, so they are only garbage collected once the driver is gone.
+func (c *Controller) terminateDriverPod(app *v1beta1.SparkApplication, name string) error {
+	pod, err := c.kubeClient.CoreV1().Pods(app.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Do not cut short the graceful termination of a driver pod that is already being deleted.
+	if pod.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if pod.Status.Phase == apiv1.PodRunning && pod.Status.PodIP != "" {
+		client := &http.Client{Timeout: jobCancellationTimeout}
+		baseURL := fmt.Sprintf("http://%s:%s", pod.Status.PodIP, getUITargetPort(app))
+		if err := cancelSparkJobs(client, baseURL); err != nil {
+			glog.Warningf("failed to cancel the jobs of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+	}
+
+	glog.V(2).Infof("Deleting pod with name %s in namespace %s", name, app.Namespace)
+	err = c.kubeClient.CoreV1().Pods(app.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// cancelSparkJobs cancels the running jobs of the application the driver with the given UI base URL runs.
+// This requires spark.ui.killEnabled, which is enabled by default.
+func cancelSparkJobs(client *http.Client, baseURL string) error {
+	var apps []sparkApplicationInfo
+	if err := getJSON(client, baseURL+"/api/v1/applications", &apps); err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+	var jobs []sparkJobData
+	jobsURL := fmt.Sprintf("%s/api/v1/applications/%s/jobs?status=running", baseURL, url.PathEscape(apps[0].ID))
+	if err := getJSON(client, jobsURL, &jobs); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		resp, err := client.PostForm(baseURL+"/jobs/job/kill/", url.Values{"id": {fmt.Sprint(job.JobID)}})
+		if err != nil {
+			return err
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("failed to cancel job %d: got status %s", job.JobID, resp.Status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestCancelSparkJobs(t *testing.T) {
+	var cancelled []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/applications", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "spark-123"}]`)
+	})
+	mux.HandleFunc("/api/v1/applications/spark-123/jobs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "running", r.URL.Query().Get("status"))
+		fmt.Fprint(w, `[{"jobId": 3, "status": "RUNNING"}, {"jobId": 5, "status": "RUNNING"}]`)
+	})
+	mux.HandleFunc("/jobs/job/kill/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		cancelled = append(cancelled, r.FormValue("id"))
+		http.Redirect(w, r, "/jobs/", http.StatusFound)
+	})
+	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	assert.Nil(t, cancelSparkJobs(server.Client(), server.URL))
+	assert.Equal(t, []string{"3", "5"}, cancelled)
+
+	unavailable := httptest.NewServer(http.NotFoundHandler())
+	defer unavailable.Close()
+	assert.NotNil(t, cancelSparkJobs(unavailable.Client(), unavailable.URL))
+}
+
+func TestTerminateDriverPod(t *testing.T) {
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"}}
+	ctrl, _ := newFakeController(app)
+
+	driverPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "test"},
+		Status:     apiv1.PodStatus{Phase: apiv1.PodPending},
+	}
+	if _, err := ctrl.kubeClient.CoreV1().Pods("test").Create(driverPod); err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, ctrl.terminateDriverPod(app, "foo-driver"))
+	_, err := ctrl.kubeClient.CoreV1().Pods("test").Get("foo-driver", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	assert.Nil(t, ctrl.terminateDriverPod(app, "foo-driver"))
+
+	// A driver pod that is already shutting down is left alone.
+	now := metav1.Now()
+	driverPod.DeletionTimestamp = &now
+	if _, err := ctrl.kubeClient.CoreV1().Pods("test").Create(driverPod); err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, ctrl.terminateDriverPod(app, "foo-driver"))
+	_, err = ctrl.kubeClient.CoreV1().Pods("test").Get("foo-driver", metav1.GetOptions{})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+func getUIProxyNetworkPolicyName(app *v1beta1.SparkApplication) string {
+	return util.BuildName(app.Name, "ui-proxy", util.DNS1123SubdomainMaxLength)
+}
+
+// createUIProxyNetworkPolicy creates a NetworkPolicy that keeps requests from bypassing the UI proxy of the given
+// application: ingress to the driver pod is only allowed to the port of the proxy, and from the pods of the
+// application, which reach the driver on its other ports.
+func createUIProxyNetworkPolicy(
+	app *v1beta1.SparkApplication,
+	metadata propagatedMetadata,
+	kubeClient clientset.Interface) error {
+	proxyPort := intstr.FromInt(int(util.GetUIProxyPort(app)))
+	protocol := apiv1.ProtocolTCP
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getUIProxyNetworkPolicyName(app),
+			Namespace: app.Namespace,
+			Labels: map[string]string{
+				config.SparkAppNameLabel: app.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{*getOwnerReference(app)},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					config.SparkAppNameLabel: app.Name,
+					config.SparkRoleLabel:    config.SparkDriverRole,
+				},
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &protocol, Port: &proxyPort}},
+				},
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{config.SparkAppNameLabel: app.Name},
+						}},
+					},
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	metadata.apply(&policy.ObjectMeta)
+	_, err := kubeClient.NetworkingV1().NetworkPolicies(app.Namespace).Create(policy)
+	if err = ignoreAlreadyExists(err); err != nil {
+		return fmt.Errorf("failed to create NetworkPolicy %s/%s: %v", app.Namespace, policy.Name, err)
+	}
+	return nil
+}
+
+// shouldStopUIProxy tells if the UI proxy of the given driver pod is still running although the Spark driver
+// container has terminated, which would keep the pod running forever.
+func shouldStopUIProxy(pod *apiv1.Pod) bool {
+	if pod.Status.Phase != apiv1.PodRunning || isUIProxyStopped(pod) {
+		return false
+	}
+
+	sparkContainerTerminated := false
+	proxyRunning := false
+	for _, status := range pod.Status.ContainerStatuses {
+		switch status.Name {
+		case sparkDriverContainerName:
+			sparkContainerTerminated = status.State.Terminated != nil
+		case config.UIProxyContainerName:
+			proxyRunning = status.State.Running != nil
+		}
+	}
+	return sparkContainerTerminated && proxyRunning
+}
+
+// isUIProxyStopped tells if the operator has stopped the UI proxy of the given driver pod.
+func isUIProxyStopped(pod *apiv1.Pod) bool {
+	return pod.Annotations[config.UIProxyStoppedAnnotation] == "true"
+}
+
+// stopUIProxy stops the UI proxy of the given driver pod. Containers cannot be stopped individually, so the
+// active deadline of the pod is shortened to have the kubelet stop it, which fails the pod with the reason
+// DeadlineExceeded but keeps the pod and its logs. The pod is annotated so the phase of the pod is still
+// determined by the Spark driver container.
+func stopUIProxy(pod *apiv1.Pod, kubeClient clientset.Interface) error {
+	deadline := int64(1)
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{config.UIProxyStoppedAnnotation: "true"},
+		},
+		"spec": map[string]interface{}{
+			"activeDeadlineSeconds": deadline,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the patch stopping the UI proxy of pod %s/%s: %v", pod.Namespace,
+			pod.Name, err)
+	}
+	glog.Infof("Spark container of pod %s/%s has terminated, stopping the UI proxy", pod.Namespace, pod.Name)
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Patch(pod.Name, types.MergePatchType, patch); err != nil {
+		return fmt.Errorf("failed to stop the UI proxy of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
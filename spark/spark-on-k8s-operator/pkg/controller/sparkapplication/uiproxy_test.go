@@ -0,0 +1,106 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkapplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newUIProxyDriverPod(sparkState, proxyState apiv1.ContainerState) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "default"},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: sparkDriverContainerName, State: sparkState},
+				{Name: config.UIProxyContainerName, State: proxyState},
+			},
+		},
+	}
+}
+
+func TestShouldStopUIProxy(t *testing.T) {
+	running := apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}}
+	terminated := apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{}}
+
+	assert.True(t, shouldStopUIProxy(newUIProxyDriverPod(terminated, running)))
+	assert.False(t, shouldStopUIProxy(newUIProxyDriverPod(running, running)))
+	assert.False(t, shouldStopUIProxy(newUIProxyDriverPod(terminated, terminated)))
+
+	// The proxy is only stopped once.
+	pod := newUIProxyDriverPod(terminated, running)
+	pod.Annotations = map[string]string{config.UIProxyStoppedAnnotation: "true"}
+	assert.False(t, shouldStopUIProxy(pod))
+}
+
+func TestStopUIProxy(t *testing.T) {
+	running := apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{}}
+	terminated := apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{}}
+	pod := newUIProxyDriverPod(terminated, running)
+	kubeClient := kubeclientfake.NewSimpleClientset(pod)
+
+	if err := stopUIProxy(pod, kubeClient); err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := kubeClient.CoreV1().Pods("default").Get("foo-driver", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, isUIProxyStopped(stopped))
+	if assert.NotNil(t, stopped.Spec.ActiveDeadlineSeconds) {
+		assert.Equal(t, int64(1), *stopped.Spec.ActiveDeadlineSeconds)
+	}
+}
+
+func TestCreateUIProxyNetworkPolicy(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{UIProxy: &v1beta1.UIProxySpec{}},
+		},
+	}
+	kubeClient := kubeclientfake.NewSimpleClientset()
+
+	if err := createUIProxyNetworkPolicy(app, propagatedMetadata{}, kubeClient); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := kubeClient.NetworkingV1().NetworkPolicies("default").Get("foo-ui-proxy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{config.SparkAppNameLabel: "foo", config.SparkRoleLabel: config.SparkDriverRole},
+		policy.Spec.PodSelector.MatchLabels)
+	if assert.Equal(t, 2, len(policy.Spec.Ingress)) {
+		// Anyone may reach the proxy, but only the pods of the application the other ports of the driver.
+		assert.Equal(t, config.DefaultUIProxyPort, policy.Spec.Ingress[0].Ports[0].Port.IntVal)
+		assert.Nil(t, policy.Spec.Ingress[0].From)
+		assert.Nil(t, policy.Spec.Ingress[1].Ports)
+		assert.Equal(t, map[string]string{config.SparkAppNameLabel: "foo"},
+			policy.Spec.Ingress[1].From[0].PodSelector.MatchLabels)
+	}
+
+	// Resubmitted applications keep the NetworkPolicy.
+	assert.Nil(t, createUIProxyNetworkPolicy(app, propagatedMetadata{}, kubeClient))
+}
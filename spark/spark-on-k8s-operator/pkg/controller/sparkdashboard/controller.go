@@ -0,0 +1,222 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkdashboard
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+)
+
+var (
+	grafanaDashboardResource = schema.GroupVersionResource{
+		Group:    "integreatly.org",
+		Version:  "v1alpha1",
+		Resource: "grafanadashboards",
+	}
+	prometheusRuleResource = schema.GroupVersionResource{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "prometheusrules",
+	}
+)
+
+// Controller generates a GrafanaDashboard of the Grafana Operator and a PrometheusRule of the Prometheus
+// Operator for each class of SparkApplications, so that every team gets dashboards and alerts on the
+// operator metrics of their applications without setting them up by hand. The class of an application is
+// the value of the configured class label, which must also be a metric label for the metrics to be told
+// apart by class.
+type Controller struct {
+	dynamicClient      dynamic.Interface
+	queue              workqueue.RateLimitingInterface
+	cacheSynced        cache.InformerSynced
+	config             Config
+	dashboardTemplate  *template.Template
+	alertRulesTemplate *template.Template
+}
+
+// Config is the configuration of the dashboard controller.
+type Config struct {
+	// ClassLabel is the label of SparkApplications whose value is the class of the application.
+	ClassLabel string
+	// MetricsPrefix is the prefix of the operator metrics.
+	MetricsPrefix string
+	// ResourceLabels are labels added to the generated objects, e.g., to match the dashboard selector of
+	// a Grafana instance or the rule selector of a Prometheus instance.
+	ResourceLabels map[string]string
+	// DashboardTemplate is the template of the Grafana dashboard JSON. Uses a built-in template if empty.
+	DashboardTemplate string
+	// AlertRulesTemplate is the template of the YAML list of Prometheus rule groups. Uses a built-in
+	// template if empty.
+	AlertRulesTemplate string
+}
+
+// NewController creates a new dashboard controller.
+func NewController(
+	dynamicClient dynamic.Interface,
+	crdInformerFactory crdinformers.SharedInformerFactory,
+	controllerConfig Config) (*Controller, error) {
+	dashboardTemplate, err := parseTemplate("dashboard", controllerConfig.DashboardTemplate, defaultDashboardTemplate,
+		controllerConfig.MetricsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	alertRulesTemplate, err := parseTemplate("alert-rules", controllerConfig.AlertRulesTemplate,
+		defaultAlertRulesTemplate, controllerConfig.MetricsPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"spark-dashboard-controller")
+
+	controller := &Controller{
+		dynamicClient:      dynamicClient,
+		queue:              queue,
+		config:             controllerConfig,
+		dashboardTemplate:  dashboardTemplate,
+		alertRulesTemplate: alertRulesTemplate,
+	}
+
+	informer := crdInformerFactory.Sparkoperator().V1beta1().SparkApplications()
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onAdd,
+		UpdateFunc: controller.onUpdate,
+	})
+	controller.cacheSynced = informer.Informer().HasSynced
+
+	return controller, nil
+}
+
+func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
+	glog.Info("Starting the Spark dashboard controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	glog.Info("Starting the workers of the Spark dashboard controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	return nil
+}
+
+func (c *Controller) Stop() {
+	glog.Info("Stopping the Spark dashboard controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncClass(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to generate dashboards for application class %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	c.enqueue(obj)
+}
+
+func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	c.enqueue(newObj)
+}
+
+// enqueue adds the class of the given application, keyed by namespace and class, to the queue.
+func (c *Controller) enqueue(obj interface{}) {
+	app, ok := obj.(*v1beta1.SparkApplication)
+	if !ok {
+		return
+	}
+	class := app.Labels[c.config.ClassLabel]
+	if class == "" {
+		return
+	}
+
+	c.queue.Add(app.Namespace + "/" + class)
+}
+
+func (c *Controller) syncClass(key string) error {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	namespace, class := parts[0], parts[1]
+
+	dashboard, err := c.buildGrafanaDashboard(namespace, class)
+	if err != nil {
+		return err
+	}
+	if err = c.ensure(grafanaDashboardResource, dashboard); err != nil {
+		return err
+	}
+	rule, err := c.buildPrometheusRule(namespace, class)
+	if err != nil {
+		return err
+	}
+	return c.ensure(prometheusRuleResource, rule)
+}
+
+// ensure only creates the given object if it is missing and never overwrites an existing one, so that
+// teams are free to customize their dashboards and alerts after they have been generated.
+func (c *Controller) ensure(resource schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	client := c.dynamicClient.Resource(resource).Namespace(obj.GetNamespace())
+	_, err := client.Get(obj.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		glog.Infof("Creating %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+		_, err = client.Create(obj)
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+	}
+	return err
+}
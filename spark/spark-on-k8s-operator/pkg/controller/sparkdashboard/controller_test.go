@@ -0,0 +1,133 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkdashboard
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newFakeController(t *testing.T, controllerConfig Config) *Controller {
+	informerFactory := crdinformers.NewSharedInformerFactory(crdclientfake.NewSimpleClientset(), 0)
+	controller, err := NewController(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), informerFactory,
+		controllerConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return controller
+}
+
+func TestSyncClass(t *testing.T) {
+	c := newFakeController(t, Config{
+		ClassLabel:     config.SparkAppClassLabel,
+		MetricsPrefix:  "team_",
+		ResourceLabels: map[string]string{"prometheus": "k8s"},
+	})
+
+	if err := c.syncClass("team-a/Nightly_ETL"); err != nil {
+		t.Fatal(err)
+	}
+
+	options := metav1.GetOptions{}
+	dashboard, err := c.dynamicClient.Resource(grafanaDashboardResource).Namespace("team-a").Get("spark-nightly-etl", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "k8s", dashboard.GetLabels()["prometheus"])
+	assert.Equal(t, "nightly-etl", dashboard.GetLabels()[config.SparkAppClassLabel])
+	dashboardJSON, _, _ := unstructured.NestedString(dashboard.Object, "spec", "json")
+	var parsed struct {
+		Panels []struct {
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal([]byte(dashboardJSON), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `sum(team_spark_app_running_count{ sparkoperator_k8s_io_app_class="Nightly_ETL",app_namespace="team-a" })`,
+		parsed.Panels[0].Targets[0].Expr)
+
+	rule, err := c.dynamicClient.Resource(prometheusRuleResource).Namespace("team-a").Get("spark-nightly-etl", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	assert.Equal(t, 1, len(groups))
+	rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+	assert.Equal(t, 2, len(rules))
+	assert.Equal(t, `sum(increase(team_spark_app_failure_count{ sparkoperator_k8s_io_app_class="Nightly_ETL",app_namespace="team-a" }[15m])) > 0`,
+		rules[0].(map[string]interface{})["expr"])
+}
+
+func TestSyncClass_CustomTemplates(t *testing.T) {
+	c := newFakeController(t, Config{
+		ClassLabel:         "team",
+		DashboardTemplate:  `{"title": "{{.Class}}"}`,
+		AlertRulesTemplate: `[]`,
+	})
+
+	if err := c.syncClass("team-a/etl"); err != nil {
+		t.Fatal(err)
+	}
+	dashboard, err := c.dynamicClient.Resource(grafanaDashboardResource).Namespace("team-a").Get("spark-etl", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dashboardJSON, _, _ := unstructured.NestedString(dashboard.Object, "spec", "json")
+	assert.Equal(t, `{"title": "etl"}`, dashboardJSON)
+
+	// Existing objects are not overwritten.
+	c.dashboardTemplate, _ = parseTemplate("dashboard", `{"title": "changed"}`, "", "")
+	if err := c.syncClass("team-a/etl"); err != nil {
+		t.Fatal(err)
+	}
+	dashboard, _ = c.dynamicClient.Resource(grafanaDashboardResource).Namespace("team-a").Get("spark-etl", metav1.GetOptions{})
+	dashboardJSON, _, _ = unstructured.NestedString(dashboard.Object, "spec", "json")
+	assert.Equal(t, `{"title": "etl"}`, dashboardJSON)
+
+	c.dashboardTemplate, _ = parseTemplate("dashboard", `not json`, "", "")
+	assert.NotNil(t, c.syncClass("team-b/etl"))
+}
+
+func TestEnqueue(t *testing.T) {
+	c := newFakeController(t, Config{ClassLabel: config.SparkAppClassLabel})
+
+	c.enqueue(&v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "team-a"}})
+	assert.Equal(t, 0, c.queue.Len())
+
+	c.enqueue(&v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{
+		Name:      "foo",
+		Namespace: "team-a",
+		Labels:    map[string]string{config.SparkAppClassLabel: "etl"},
+	}})
+	key, _ := c.queue.Get()
+	assert.Equal(t, "team-a/etl", key)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkdashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+var invalidNameCharsRegex = regexp.MustCompile("[^a-z0-9-]+")
+
+// templateData is the data the dashboard and alert rules templates are executed with. Templates can also
+// use the function "metric", which returns the full name of the given operator metric, e.g.,
+// {{metric "spark_app_failure_count"}}, and the function "jsonEscape", which escapes a string for use in a
+// JSON string.
+type templateData struct {
+	// Class is the class of the applications.
+	Class string
+	// Namespace is the namespace of the applications.
+	Namespace string
+	// Selector is the Prometheus label matchers selecting the metrics of the class in the namespace, e.g.,
+	// app_class="etl",app_namespace="team-a".
+	Selector string
+}
+
+const defaultDashboardTemplate = `{
+  "title": "Spark Applications: {{.Class | jsonEscape}} ({{.Namespace | jsonEscape}})",
+  "tags": ["spark"],
+  "timezone": "browser",
+  "refresh": "1m",
+  "time": {"from": "now-24h", "to": "now"},
+  "schemaVersion": 16,
+  "panels": [
+    {
+      "id": 1,
+      "type": "graph",
+      "title": "Running Applications",
+      "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+      "targets": [{"expr": "sum({{metric "spark_app_running_count"}}{ {{.Selector | jsonEscape}} })", "legendFormat": "running"}]
+    },
+    {
+      "id": 2,
+      "type": "graph",
+      "title": "Submissions, Successes and Failures",
+      "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+      "targets": [
+        {"expr": "sum(increase({{metric "spark_app_submit_count"}}{ {{.Selector | jsonEscape}} }[1h]))", "legendFormat": "submitted"},
+        {"expr": "sum(increase({{metric "spark_app_success_count"}}{ {{.Selector | jsonEscape}} }[1h]))", "legendFormat": "succeeded"},
+        {"expr": "sum(increase({{metric "spark_app_failure_count"}}{ {{.Selector | jsonEscape}} }[1h]))", "legendFormat": "failed"}
+      ]
+    },
+    {
+      "id": 3,
+      "type": "graph",
+      "title": "Average Execution Time (s)",
+      "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8},
+      "targets": [
+        {"expr": "sum(rate({{metric "spark_app_success_execution_time_microseconds"}}_sum{ {{.Selector | jsonEscape}} }[1h])) / sum(rate({{metric "spark_app_success_execution_time_microseconds"}}_count{ {{.Selector | jsonEscape}} }[1h])) / 1e6", "legendFormat": "succeeded"},
+        {"expr": "sum(rate({{metric "spark_app_failure_execution_time_microseconds"}}_sum{ {{.Selector | jsonEscape}} }[1h])) / sum(rate({{metric "spark_app_failure_execution_time_microseconds"}}_count{ {{.Selector | jsonEscape}} }[1h])) / 1e6", "legendFormat": "failed"}
+      ]
+    },
+    {
+      "id": 4,
+      "type": "graph",
+      "title": "Executors",
+      "gridPos": {"x": 12, "y": 8, "w": 12, "h": 8},
+      "targets": [
+        {"expr": "sum({{metric "spark_app_executor_running_count"}}{ {{.Selector | jsonEscape}} })", "legendFormat": "running"},
+        {"expr": "sum(increase({{metric "spark_app_executor_failure_count"}}{ {{.Selector | jsonEscape}} }[1h]))", "legendFormat": "failed"}
+      ]
+    }
+  ]
+}
+`
+
+const defaultAlertRulesTemplate = `- name: spark-{{.Class}}
+  rules:
+  - alert: SparkApplicationFailed
+    expr: 'sum(increase({{metric "spark_app_failure_count"}}{ {{.Selector}} }[15m])) > 0'
+    labels:
+      severity: warning
+    annotations:
+      summary: 'SparkApplications of class {{.Class}} in namespace {{.Namespace}} failed in the last 15 minutes.'
+  - alert: SparkApplicationExecutorsFailing
+    expr: 'sum(increase({{metric "spark_app_executor_failure_count"}}{ {{.Selector}} }[15m])) > 10'
+    labels:
+      severity: warning
+    annotations:
+      summary: 'More than 10 executors of SparkApplications of class {{.Class}} in namespace {{.Namespace}} failed in the last 15 minutes.'
+`
+
+func parseTemplate(name string, text string, defaultText string, metricsPrefix string) (*template.Template, error) {
+	if text == "" {
+		text = defaultText
+	}
+	funcs := template.FuncMap{
+		"metric": func(metric string) string {
+			return util.CreateValidMetricNameLabel(metricsPrefix, metric)
+		},
+		"jsonEscape": jsonEscape,
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %v", name, err)
+	}
+	return tmpl, nil
+}
+
+func jsonEscape(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+func (c *Controller) executeTemplate(tmpl *template.Template, namespace string, class string) ([]byte, error) {
+	data := templateData{
+		Class:     class,
+		Namespace: namespace,
+		Selector: fmt.Sprintf("%s=%q,%s=%q", util.CreateValidMetricNameLabel("", c.config.ClassLabel), class,
+			config.SparkAppNamespaceMetricLabel, namespace),
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute %s template for class %s: %v", tmpl.Name(), class, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Controller) buildGrafanaDashboard(namespace string, class string) (*unstructured.Unstructured, error) {
+	dashboard, err := c.executeTemplate(c.dashboardTemplate, namespace, class)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(dashboard) {
+		return nil, fmt.Errorf("dashboard template for class %s did not produce valid JSON", class)
+	}
+
+	name := getResourceName(class)
+	obj := c.buildObject("integreatly.org/v1alpha1", "GrafanaDashboard", name, namespace, class)
+	obj.Object["spec"] = map[string]interface{}{
+		"name": name + ".json",
+		"json": string(dashboard),
+	}
+	return obj, nil
+}
+
+func (c *Controller) buildPrometheusRule(namespace string, class string) (*unstructured.Unstructured, error) {
+	rules, err := c.executeTemplate(c.alertRulesTemplate, namespace, class)
+	if err != nil {
+		return nil, err
+	}
+	var groups []interface{}
+	if err = yaml.Unmarshal(rules, &groups); err != nil {
+		return nil, fmt.Errorf("alert rules template for class %s did not produce a valid list of rule groups: %v",
+			class, err)
+	}
+
+	obj := c.buildObject("monitoring.coreos.com/v1", "PrometheusRule", getResourceName(class), namespace, class)
+	obj.Object["spec"] = map[string]interface{}{"groups": groups}
+	return obj, nil
+}
+
+func (c *Controller) buildObject(apiVersion, kind, name, namespace, class string) *unstructured.Unstructured {
+	labels := map[string]string{config.SparkAppClassLabel: sanitizeName(class)}
+	for key, value := range c.config.ResourceLabels {
+		labels[key] = value
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	return obj
+}
+
+// getResourceName returns the name of the objects generated for the given class. Classes are label
+// values, which may contain characters that are not allowed in object names.
+func getResourceName(class string) string {
+	return util.BuildName("spark", sanitizeName(class), util.DNS1123LabelMaxLength)
+}
+
+func sanitizeName(class string) string {
+	name := strings.Trim(invalidNameCharsRegex.ReplaceAllString(strings.ToLower(class), "-"), "-")
+	return util.TruncateName(name, util.DNS1123LabelMaxLength)
+}
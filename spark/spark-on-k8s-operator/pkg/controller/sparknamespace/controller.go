@@ -0,0 +1,240 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparknamespace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// Controller bootstraps namespaces labeled with config.SparkEnabledNamespaceLabel so that they are ready
+// to run SparkApplications. For each such namespace, it creates the driver service account, a Role and
+// RoleBinding for driver pod management, a LimitRange with default resource requests and limits, and a
+// NetworkPolicy that restricts ingress to Spark pods to traffic from within the namespace.
+type Controller struct {
+	kubeClient  kubernetes.Interface
+	queue       workqueue.RateLimitingInterface
+	cacheSynced cache.InformerSynced
+	lister      corelisters.NamespaceLister
+	config      Config
+}
+
+// Config is the configuration of the namespace bootstrap controller.
+type Config struct {
+	// ServiceAccountName is the name of the driver service account to create in each namespace.
+	ServiceAccountName string
+	// DefaultCPURequest is the default CPU request of containers that do not specify one.
+	DefaultCPURequest string
+	// DefaultMemoryRequest is the default memory request of containers that do not specify one.
+	DefaultMemoryRequest string
+}
+
+// NewController creates a new namespace bootstrap controller. The given informer factory is expected
+// to be restricted to namespaces labeled with config.SparkEnabledNamespaceLabel.
+func NewController(
+	kubeClient kubernetes.Interface,
+	informerFactory informers.SharedInformerFactory,
+	controllerConfig Config) *Controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"spark-namespace-controller")
+
+	controller := &Controller{
+		kubeClient: kubeClient,
+		queue:      queue,
+		config:     controllerConfig,
+	}
+
+	informer := informerFactory.Core().V1().Namespaces()
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onAdd,
+		UpdateFunc: controller.onUpdate,
+	})
+	controller.cacheSynced = informer.Informer().HasSynced
+	controller.lister = informer.Lister()
+
+	return controller
+}
+
+func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
+	glog.Info("Starting the Spark namespace bootstrap controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	glog.Info("Starting the workers of the Spark namespace bootstrap controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	return nil
+}
+
+func (c *Controller) Stop() {
+	glog.Info("Stopping the Spark namespace bootstrap controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncNamespace(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to bootstrap namespace %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	c.enqueue(obj)
+}
+
+func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	c.enqueue(newObj)
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+
+	c.queue.AddRateLimited(key)
+}
+
+func (c *Controller) syncNamespace(name string) error {
+	ns, err := c.lister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !isSparkEnabled(ns.Labels) || ns.DeletionTimestamp != nil {
+		return nil
+	}
+
+	glog.V(2).Infof("Bootstrapping Spark namespace %s", name)
+	if err := c.ensureServiceAccount(name); err != nil {
+		return err
+	}
+	if err := c.ensureRole(name); err != nil {
+		return err
+	}
+	if err := c.ensureRoleBinding(name); err != nil {
+		return err
+	}
+	if err := c.ensureLimitRange(name); err != nil {
+		return err
+	}
+	return c.ensureNetworkPolicy(name)
+}
+
+// The ensure* methods below only create missing objects and never overwrite existing ones, so that
+// cluster administrators are free to customize the objects after a namespace has been bootstrapped.
+
+func (c *Controller) ensureServiceAccount(namespace string) error {
+	_, err := c.kubeClient.CoreV1().ServiceAccounts(namespace).Get(c.config.ServiceAccountName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ServiceAccounts(namespace).Create(buildServiceAccount(namespace, c.config))
+		return ignoreAlreadyExists(err)
+	}
+	return err
+}
+
+func (c *Controller) ensureRole(namespace string) error {
+	_, err := c.kubeClient.RbacV1().Roles(namespace).Get(sparkRoleName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.RbacV1().Roles(namespace).Create(buildRole(namespace))
+		return ignoreAlreadyExists(err)
+	}
+	return err
+}
+
+func (c *Controller) ensureRoleBinding(namespace string) error {
+	_, err := c.kubeClient.RbacV1().RoleBindings(namespace).Get(sparkRoleBindingName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.RbacV1().RoleBindings(namespace).Create(buildRoleBinding(namespace, c.config))
+		return ignoreAlreadyExists(err)
+	}
+	return err
+}
+
+func (c *Controller) ensureLimitRange(namespace string) error {
+	_, err := c.kubeClient.CoreV1().LimitRanges(namespace).Get(sparkLimitRangeName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		limitRange, buildErr := buildLimitRange(namespace, c.config)
+		if buildErr != nil {
+			return buildErr
+		}
+		_, err = c.kubeClient.CoreV1().LimitRanges(namespace).Create(limitRange)
+		return ignoreAlreadyExists(err)
+	}
+	return err
+}
+
+func (c *Controller) ensureNetworkPolicy(namespace string) error {
+	_, err := c.kubeClient.NetworkingV1().NetworkPolicies(namespace).Get(sparkNetworkPolicyName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.NetworkingV1().NetworkPolicies(namespace).Create(buildNetworkPolicy(namespace))
+		return ignoreAlreadyExists(err)
+	}
+	return err
+}
+
+func isSparkEnabled(labels map[string]string) bool {
+	value, ok := labels[config.SparkEnabledNamespaceLabel]
+	return ok && value == "true"
+}
+
+func ignoreAlreadyExists(err error) error {
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
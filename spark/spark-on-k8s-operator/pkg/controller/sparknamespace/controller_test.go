@@ -0,0 +1,142 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparknamespace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newFakeController(namespaces ...*apiv1.Namespace) *Controller {
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	controller := NewController(kubeClient, informerFactory, Config{
+		ServiceAccountName:   "spark",
+		DefaultCPURequest:    "100m",
+		DefaultMemoryRequest: "256Mi",
+	})
+
+	indexer := informerFactory.Core().V1().Namespaces().Informer().GetIndexer()
+	for _, ns := range namespaces {
+		kubeClient.CoreV1().Namespaces().Create(ns)
+		indexer.Add(ns)
+	}
+	return controller
+}
+
+func TestSyncNamespace(t *testing.T) {
+	ns := &apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{config.SparkEnabledNamespaceLabel: "true"},
+		},
+	}
+	c := newFakeController(ns)
+
+	if err := c.syncNamespace(ns.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	options := metav1.GetOptions{}
+	sa, err := c.kubeClient.CoreV1().ServiceAccounts(ns.Name).Get("spark", options)
+	assert.Nil(t, err)
+	assert.Equal(t, "true", sa.Labels[config.BootstrappedBySparkOperatorLabel])
+
+	role, err := c.kubeClient.RbacV1().Roles(ns.Name).Get(sparkRoleName, options)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(role.Rules))
+
+	binding, err := c.kubeClient.RbacV1().RoleBindings(ns.Name).Get(sparkRoleBindingName, options)
+	assert.Nil(t, err)
+	assert.Equal(t, sparkRoleName, binding.RoleRef.Name)
+	assert.Equal(t, "spark", binding.Subjects[0].Name)
+	assert.Equal(t, ns.Name, binding.Subjects[0].Namespace)
+
+	limitRange, err := c.kubeClient.CoreV1().LimitRanges(ns.Name).Get(sparkLimitRangeName, options)
+	assert.Nil(t, err)
+	cpu := limitRange.Spec.Limits[0].DefaultRequest[apiv1.ResourceCPU]
+	assert.Equal(t, "100m", cpu.String())
+	assert.Nil(t, limitRange.Spec.Limits[0].Default)
+
+	policy, err := c.kubeClient.NetworkingV1().NetworkPolicies(ns.Name).Get(sparkNetworkPolicyName, options)
+	assert.Nil(t, err)
+	assert.Equal(t, config.SparkRoleLabel, policy.Spec.PodSelector.MatchExpressions[0].Key)
+
+	// A second sync must not fail on the already existing objects.
+	assert.Nil(t, c.syncNamespace(ns.Name))
+}
+
+func TestSyncNamespace_KeepsExistingObjects(t *testing.T) {
+	ns := &apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-b",
+			Labels: map[string]string{config.SparkEnabledNamespaceLabel: "true"},
+		},
+	}
+	c := newFakeController(ns)
+	existing := &apiv1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark",
+			Namespace: ns.Name,
+			Labels:    map[string]string{"owner": "admin"},
+		},
+	}
+	c.kubeClient.CoreV1().ServiceAccounts(ns.Name).Create(existing)
+
+	if err := c.syncNamespace(ns.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	sa, err := c.kubeClient.CoreV1().ServiceAccounts(ns.Name).Get("spark", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", sa.Labels["owner"])
+	assert.Equal(t, "", sa.Labels[config.BootstrappedBySparkOperatorLabel])
+}
+
+func TestSyncNamespace_NotSparkEnabled(t *testing.T) {
+	ns := &apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-c",
+			Labels: map[string]string{config.SparkEnabledNamespaceLabel: "false"},
+		},
+	}
+	c := newFakeController(ns)
+
+	if err := c.syncNamespace(ns.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	sas, err := c.kubeClient.CoreV1().ServiceAccounts(ns.Name).List(metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(sas.Items))
+
+	// Unknown namespaces are silently ignored.
+	assert.Nil(t, c.syncNamespace("non-existent"))
+}
+
+func TestBuildLimitRange_InvalidQuantity(t *testing.T) {
+	_, err := buildLimitRange("default", Config{DefaultCPURequest: "one", DefaultMemoryRequest: "1Gi"})
+	assert.NotNil(t, err)
+}
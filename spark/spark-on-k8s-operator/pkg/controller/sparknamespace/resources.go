@@ -0,0 +1,143 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparknamespace
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	sparkRoleName          = "spark-role"
+	sparkRoleBindingName   = "spark-role-binding"
+	sparkLimitRangeName    = "spark-default-limits"
+	sparkNetworkPolicyName = "spark-network-policy"
+)
+
+func buildObjectMeta(name string, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels:    map[string]string{config.BootstrappedBySparkOperatorLabel: "true"},
+	}
+}
+
+func buildServiceAccount(namespace string, controllerConfig Config) *apiv1.ServiceAccount {
+	return &apiv1.ServiceAccount{
+		ObjectMeta: buildObjectMeta(controllerConfig.ServiceAccountName, namespace),
+	}
+}
+
+// buildRole builds a Role granting the permissions the Spark driver needs to manage executor pods and
+// the headless driver service.
+func buildRole(namespace string) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: buildObjectMeta(sparkRoleName, namespace),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"*"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"services"},
+				Verbs:     []string{"*"},
+			},
+		},
+	}
+}
+
+func buildRoleBinding(namespace string, controllerConfig Config) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: buildObjectMeta(sparkRoleBindingName, namespace),
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      controllerConfig.ServiceAccountName,
+				Namespace: namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     sparkRoleName,
+		},
+	}
+}
+
+// buildLimitRange builds a LimitRange that sets default resource requests for containers that do not
+// specify any, e.g., sidecar containers injected into Spark pods. No default limits are set, as they would
+// also cap the containers that only set requests.
+func buildLimitRange(namespace string, controllerConfig Config) (*apiv1.LimitRange, error) {
+	cpu, err := resource.ParseQuantity(controllerConfig.DefaultCPURequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default CPU request %q: %v", controllerConfig.DefaultCPURequest, err)
+	}
+	memory, err := resource.ParseQuantity(controllerConfig.DefaultMemoryRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default memory request %q: %v", controllerConfig.DefaultMemoryRequest, err)
+	}
+
+	return &apiv1.LimitRange{
+		ObjectMeta: buildObjectMeta(sparkLimitRangeName, namespace),
+		Spec: apiv1.LimitRangeSpec{
+			Limits: []apiv1.LimitRangeItem{
+				{
+					Type: apiv1.LimitTypeContainer,
+					DefaultRequest: apiv1.ResourceList{
+						apiv1.ResourceCPU:    cpu,
+						apiv1.ResourceMemory: memory,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// buildNetworkPolicy builds a NetworkPolicy that only allows ingress traffic to Spark driver and executor
+// pods from pods in the same namespace.
+func buildNetworkPolicy(namespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: buildObjectMeta(sparkNetworkPolicyName, namespace),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      config.SparkRoleLabel,
+						Operator: metav1.LabelSelectorOpExists,
+					},
+				},
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{}},
+					},
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkoperatorconfiguration
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+)
+
+// Controller watches the SparkOperatorConfiguration with a given name and hands the settings it results in to
+// a callback whenever they change, so that the operator applies them without a restart. Deleting the
+// configuration reverts the settings to the command-line flags. The status of the configuration reports the
+// generation that has been applied, and whether some of its settings only take effect after a restart.
+type Controller struct {
+	crdClient       crdclientset.Interface
+	queue           workqueue.RateLimitingInterface
+	informerFactory crdinformers.SharedInformerFactory
+	cacheSynced     cache.InformerSynced
+	lister          crdlisters.SparkOperatorConfigurationLister
+	name            string
+	base            Settings
+	started         Settings
+	current         Settings
+	onChange        func(Settings)
+}
+
+// NewController creates a new Controller for the SparkOperatorConfiguration with the given name. The base
+// settings come from the command-line flags, and the started settings are those the operator started with,
+// i.e., as returned by Load. The given callback is called with the new settings whenever they change.
+func NewController(
+	crdClient crdclientset.Interface,
+	name string,
+	base Settings,
+	started Settings,
+	onChange func(Settings)) *Controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"spark-operator-configuration-controller")
+
+	// Only the named configuration is watched.
+	informerFactory := crdinformers.NewSharedInformerFactoryWithOptions(crdClient, 0*time.Second,
+		crdinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	controller := &Controller{
+		crdClient:       crdClient,
+		queue:           queue,
+		informerFactory: informerFactory,
+		name:            name,
+		base:            base,
+		started:         started,
+		current:         started,
+		onChange:        onChange,
+	}
+
+	informer := informerFactory.Sparkoperator().V1beta1().SparkOperatorConfigurations()
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) { controller.enqueue(newObj) },
+		DeleteFunc: controller.enqueue,
+	})
+	controller.cacheSynced = informer.Informer().HasSynced
+	controller.lister = informer.Lister()
+
+	return controller
+}
+
+func (c *Controller) Start(stopCh <-chan struct{}) error {
+	glog.Info("Starting the SparkOperatorConfiguration controller")
+
+	go c.informerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	// There is a single worker as there is a single configuration.
+	go wait.Until(c.runWorker, time.Second, stopCh)
+	// A configuration deleted while the operator was down has no delete event.
+	c.queue.Add(c.name)
+
+	return nil
+}
+
+func (c *Controller) Stop() {
+	glog.Info("Stopping the SparkOperatorConfiguration controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncConfiguration()
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to sync SparkOperatorConfiguration %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	c.queue.Add(c.name)
+}
+
+func (c *Controller) syncConfiguration() error {
+	config, err := c.lister.Get(c.name)
+	if errors.IsNotFound(err) {
+		c.apply(c.base)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	status := config.Status.DeepCopy()
+	status.ObservedGeneration = config.Generation
+	if err := validate(&config.Spec); err != nil {
+		// The last valid configuration stays in effect.
+		glog.Errorf("invalid SparkOperatorConfiguration %s: %v", config.Name, err)
+		status.Message = fmt.Sprintf("generation %d is invalid: %v", config.Generation, err)
+		return c.updateStatus(config, status)
+	}
+
+	settings := override(c.base, &config.Spec)
+	if c.apply(settings) || status.AppliedGeneration != config.Generation {
+		status.AppliedGeneration = config.Generation
+		status.LastAppliedTime = metav1.Now()
+	}
+	reasons := getRestartReasons(c.started, settings)
+	status.RestartRequired = reasons != ""
+	status.Message = ""
+	if status.RestartRequired {
+		status.Message = fmt.Sprintf("restart the operator to apply all settings: %s", reasons)
+	}
+	return c.updateStatus(config, status)
+}
+
+// apply hands the given settings to the callback if they differ from the current settings, and tells if
+// they did.
+func (c *Controller) apply(settings Settings) bool {
+	if reflect.DeepEqual(settings, c.current) {
+		return false
+	}
+	glog.Infof("Applying the settings of SparkOperatorConfiguration %s", c.name)
+	c.onChange(settings)
+	c.current = settings
+	return true
+}
+
+func (c *Controller) updateStatus(
+	config *v1beta1.SparkOperatorConfiguration,
+	newStatus *v1beta1.SparkOperatorConfigurationStatus) error {
+	// If the status has not changed, do not perform an update.
+	if reflect.DeepEqual(newStatus, &config.Status) {
+		return nil
+	}
+
+	toUpdate := config.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		toUpdate.Status = *newStatus
+		_, updateErr := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().UpdateStatus(toUpdate)
+		if updateErr == nil {
+			return nil
+		}
+
+		result, err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Get(
+			toUpdate.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		toUpdate = result
+
+		return updateErr
+	})
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkoperatorconfiguration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+)
+
+func TestLoad(t *testing.T) {
+	base := Settings{MaxRunningApplications: 10, MetricsPrefix: "spark"}
+	crdClient := crdclientfake.NewSimpleClientset()
+
+	// Without a configuration, the flags apply.
+	settings, err := Load(crdClient, "spark-operator", base)
+	assert.Nil(t, err)
+	assert.Equal(t, base, settings)
+
+	maxRunning := int32(0)
+	prefix := "operator"
+	enforce := true
+	config := &v1beta1.SparkOperatorConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-operator"},
+		Spec: v1beta1.SparkOperatorConfigurationSpec{
+			Defaults: &v1beta1.OperatorDefaults{
+				SparkConf:        map[string]string{"spark.eventLog.enabled": "true"},
+				PropagatedLabels: []string{"cost-center"},
+			},
+			Webhook: &v1beta1.OperatorWebhookConfiguration{EnforceLinuxNodes: &enforce},
+			Queueing: &v1beta1.OperatorQueueingConfiguration{
+				MaxRunningApplications: &maxRunning,
+				QueueWeights:           map[string]int32{"team-a": 2},
+				MaxPriorities:          map[string]int32{"prod": 100},
+			},
+			Metrics:    &v1beta1.OperatorMetricsConfiguration{Prefix: &prefix},
+			ProxyUsers: &v1beta1.OperatorProxyUserConfiguration{Allowed: []string{"alice"}},
+		},
+	}
+	if _, err := crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Create(config); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = Load(crdClient, "spark-operator", base)
+	assert.Nil(t, err)
+	assert.Equal(t, Settings{
+		DefaultSparkConf:       map[string]string{"spark.eventLog.enabled": "true"},
+		EnforceLinuxNodes:      true,
+		MaxRunningApplications: 0,
+		QueueWeights:           map[string]int{"team-a": 2},
+		MaxPriorities:          map[string]int32{"prod": 100},
+		MetricsPrefix:          "operator",
+		AllowedProxyUsers:      []string{"alice"},
+		PropagatedLabels:       []string{"cost-center"},
+	}, settings)
+
+	config.Spec.Queueing.QueueWeights["team-b"] = 0
+	if _, err := crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Update(config); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(crdClient, "spark-operator", base)
+	assert.NotNil(t, err)
+}
+
+func TestSyncConfiguration(t *testing.T) {
+	base := Settings{MaxRunningApplications: 10, MetricsPrefix: "spark"}
+	var applied []Settings
+	c := newFakeController(base, func(settings Settings) { applied = append(applied, settings) })
+
+	// The startup settings are not handed to the callback again.
+	config := &v1beta1.SparkOperatorConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "spark-operator", Generation: 1}}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Create(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncConfiguration(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, applied)
+	config = getConfiguration(t, c)
+	assert.Equal(t, int64(1), config.Status.ObservedGeneration)
+	assert.Equal(t, int64(1), config.Status.AppliedGeneration)
+	assert.False(t, config.Status.LastAppliedTime.IsZero())
+	assert.False(t, config.Status.RestartRequired)
+
+	// Reloadable settings are applied.
+	maxRunning := int32(20)
+	seccomp := "runtime/default"
+	config.Generation = 2
+	config.Spec.Queueing = &v1beta1.OperatorQueueingConfiguration{MaxRunningApplications: &maxRunning}
+	config.Spec.Webhook = &v1beta1.OperatorWebhookConfiguration{DefaultSeccompProfile: &seccomp}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Update(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncConfiguration(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, applied, 1)
+	assert.Equal(t, 20, applied[0].MaxRunningApplications)
+	assert.Equal(t, seccomp, applied[0].DefaultSeccompProfile)
+	config = getConfiguration(t, c)
+	assert.Equal(t, int64(2), config.Status.AppliedGeneration)
+	assert.False(t, config.Status.RestartRequired)
+
+	// Metrics settings require a restart.
+	prefix := "operator"
+	config.Generation = 3
+	config.Spec.Metrics = &v1beta1.OperatorMetricsConfiguration{Prefix: &prefix}
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Update(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncConfiguration(); err != nil {
+		t.Fatal(err)
+	}
+	config = getConfiguration(t, c)
+	assert.Equal(t, int64(3), config.Status.AppliedGeneration)
+	assert.True(t, config.Status.RestartRequired)
+	assert.Contains(t, config.Status.Message, "metrics")
+
+	// An invalid generation is observed but not applied.
+	negative := int32(-1)
+	config.Generation = 4
+	config.Spec.Queueing.MaxRunningApplications = &negative
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Update(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncConfiguration(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, applied, 2)
+	config = getConfiguration(t, c)
+	assert.Equal(t, int64(4), config.Status.ObservedGeneration)
+	assert.Equal(t, int64(3), config.Status.AppliedGeneration)
+	assert.Contains(t, config.Status.Message, "generation 4 is invalid")
+
+	// Deleting the configuration reverts to the flags.
+	if err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Delete(config.Name, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncConfiguration(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, applied, 3)
+	assert.Equal(t, base, applied[2])
+}
+
+func getConfiguration(t *testing.T, c *Controller) *v1beta1.SparkOperatorConfiguration {
+	config, err := c.crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Get(c.name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func newFakeController(started Settings, onChange func(Settings)) *Controller {
+	crdClient := crdclientfake.NewSimpleClientset()
+	controller := NewController(crdClient, "spark-operator", started, started, onChange)
+	informer := controller.informerFactory.Sparkoperator().V1beta1().SparkOperatorConfigurations().Informer()
+	crdClient.PrependReactor("create", "sparkoperatorconfigurations",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			informer.GetStore().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("update", "sparkoperatorconfigurations",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.UpdateAction).GetObject()
+			informer.GetStore().Update(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("delete", "sparkoperatorconfigurations",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			name := action.(kubetesting.DeleteAction).GetName()
+			informer.GetStore().Delete(&v1beta1.SparkOperatorConfiguration{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			return false, nil, nil
+		})
+	return controller
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkoperatorconfiguration
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+// Settings are the settings of the operator a SparkOperatorConfiguration can override. They are initialized
+// from the command-line flags. A MaxRunningApplications that is not positive means unlimited.
+type Settings struct {
+	DefaultSparkConf       map[string]string
+	DefaultSeccompProfile  string
+	DefaultAppArmorProfile string
+	EnforceLinuxNodes      bool
+	MaxRunningApplications int
+	QueueWeights           map[string]int
+	MaxPriorities          map[string]int32
+	MetricsPrefix          string
+	MetricsLabels          []string
+	AllowedProxyUsers      []string
+	ProxyUserSuperuser     string
+	PropagatedLabels       []string
+	PropagatedAnnotations  []string
+}
+
+// Load returns the given settings overridden by the SparkOperatorConfiguration with the given name, which are
+// the settings the operator starts with. The given settings are returned as is if the configuration does not
+// exist.
+func Load(crdClient crdclientset.Interface, name string, base Settings) (Settings, error) {
+	config, err := crdClient.SparkoperatorV1beta1().SparkOperatorConfigurations().Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return base, nil
+	}
+	if err != nil {
+		return base, fmt.Errorf("failed to get SparkOperatorConfiguration %s: %v", name, err)
+	}
+	if err := validate(&config.Spec); err != nil {
+		return base, fmt.Errorf("invalid SparkOperatorConfiguration %s: %v", name, err)
+	}
+	return override(base, &config.Spec), nil
+}
+
+func validate(spec *v1beta1.SparkOperatorConfigurationSpec) error {
+	if spec.ProxyUsers != nil {
+		for _, user := range spec.ProxyUsers.Allowed {
+			if user == "" {
+				return fmt.Errorf("proxyUsers.allowed must not contain empty users")
+			}
+		}
+	}
+	if spec.Queueing == nil {
+		return nil
+	}
+	if max := spec.Queueing.MaxRunningApplications; max != nil && *max < 0 {
+		return fmt.Errorf("queueing.maxRunningApplications must not be negative")
+	}
+	for queue, weight := range spec.Queueing.QueueWeights {
+		if queue == "" || weight <= 0 {
+			return fmt.Errorf("invalid weight %d of queue %q, weights must be positive", weight, queue)
+		}
+	}
+	for namespace := range spec.Queueing.MaxPriorities {
+		if namespace == "" {
+			return fmt.Errorf("queueing.maxPriorities must not contain empty namespaces")
+		}
+	}
+	return nil
+}
+
+// override returns the given settings overridden by the settings set in the given spec.
+func override(base Settings, spec *v1beta1.SparkOperatorConfigurationSpec) Settings {
+	settings := base
+	if defaults := spec.Defaults; defaults != nil {
+		if defaults.SparkConf != nil {
+			settings.DefaultSparkConf = defaults.SparkConf
+		}
+		if defaults.PropagatedLabels != nil {
+			settings.PropagatedLabels = defaults.PropagatedLabels
+		}
+		if defaults.PropagatedAnnotations != nil {
+			settings.PropagatedAnnotations = defaults.PropagatedAnnotations
+		}
+	}
+	if webhook := spec.Webhook; webhook != nil {
+		if webhook.DefaultSeccompProfile != nil {
+			settings.DefaultSeccompProfile = *webhook.DefaultSeccompProfile
+		}
+		if webhook.DefaultAppArmorProfile != nil {
+			settings.DefaultAppArmorProfile = *webhook.DefaultAppArmorProfile
+		}
+		if webhook.EnforceLinuxNodes != nil {
+			settings.EnforceLinuxNodes = *webhook.EnforceLinuxNodes
+		}
+	}
+	if queueing := spec.Queueing; queueing != nil {
+		if queueing.MaxRunningApplications != nil {
+			settings.MaxRunningApplications = int(*queueing.MaxRunningApplications)
+		}
+		if queueing.QueueWeights != nil {
+			settings.QueueWeights = make(map[string]int)
+			for queue, weight := range queueing.QueueWeights {
+				settings.QueueWeights[queue] = int(weight)
+			}
+		}
+		if queueing.MaxPriorities != nil {
+			settings.MaxPriorities = queueing.MaxPriorities
+		}
+	}
+	if metrics := spec.Metrics; metrics != nil {
+		if metrics.Prefix != nil {
+			settings.MetricsPrefix = *metrics.Prefix
+		}
+		if metrics.Labels != nil {
+			settings.MetricsLabels = metrics.Labels
+		}
+	}
+	if proxyUsers := spec.ProxyUsers; proxyUsers != nil {
+		if proxyUsers.Allowed != nil {
+			settings.AllowedProxyUsers = proxyUsers.Allowed
+		}
+		if proxyUsers.Superuser != nil {
+			settings.ProxyUserSuperuser = *proxyUsers.Superuser
+		}
+	}
+	return settings
+}
+
+// getRestartReasons returns why the given settings cannot fully take effect without restarting the operator
+// that started with the other given settings, if at all.
+func getRestartReasons(started Settings, settings Settings) string {
+	var reasons []string
+	if settings.MetricsPrefix != started.MetricsPrefix || !reflect.DeepEqual(settings.MetricsLabels, started.MetricsLabels) {
+		reasons = append(reasons, "metrics settings changed")
+	}
+	if started.MaxRunningApplications <= 0 && settings.MaxRunningApplications > 0 {
+		reasons = append(reasons, "queueing was disabled at startup")
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, ", ")
+}
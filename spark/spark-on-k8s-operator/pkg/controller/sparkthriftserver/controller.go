@@ -0,0 +1,383 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkthriftserver
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdscheme "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/scheme"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+var (
+	keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// Controller runs each SparkThriftServer as a long-running SparkApplication, exposes the driver of the
+// application through a Service and optionally an Ingress, and reports the state of the application and the
+// endpoint of the server in the status of the server.
+type Controller struct {
+	crdClient    crdclientset.Interface
+	kubeClient   kubernetes.Interface
+	queue        workqueue.RateLimitingInterface
+	cacheSynced  []cache.InformerSynced
+	serverLister crdlisters.SparkThriftServerLister
+	appLister    crdlisters.SparkApplicationLister
+}
+
+// NewController creates a new Controller.
+func NewController(
+	crdClient crdclientset.Interface,
+	kubeClient kubernetes.Interface,
+	informerFactory crdinformers.SharedInformerFactory) *Controller {
+	crdscheme.AddToScheme(scheme.Scheme)
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
+		"spark-thrift-server-controller")
+
+	controller := &Controller{
+		crdClient:  crdClient,
+		kubeClient: kubeClient,
+		queue:      queue,
+	}
+
+	serverInformer := informerFactory.Sparkoperator().V1beta1().SparkThriftServers()
+	serverInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) { controller.enqueue(newObj) },
+		DeleteFunc: controller.dequeue,
+	})
+	appInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications()
+	appInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.onAppChange,
+		UpdateFunc: func(oldObj, newObj interface{}) { controller.onAppChange(newObj) },
+		DeleteFunc: controller.onAppChange,
+	})
+	controller.cacheSynced = []cache.InformerSynced{serverInformer.Informer().HasSynced, appInformer.Informer().HasSynced}
+	controller.serverLister = serverInformer.Lister()
+	controller.appLister = appInformer.Lister()
+
+	return controller
+}
+
+func (c *Controller) Start(workers int, stopCh <-chan struct{}) error {
+	glog.Info("Starting the SparkThriftServer controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.cacheSynced...) {
+		return fmt.Errorf("timed out waiting for cache to sync")
+	}
+
+	glog.Info("Starting the workers of the SparkThriftServer controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	return nil
+}
+
+func (c *Controller) Stop() {
+	glog.Info("Stopping the SparkThriftServer controller")
+	c.queue.ShutDown()
+}
+
+func (c *Controller) runWorker() {
+	defer utilruntime.HandleCrash()
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncSparkThriftServer(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("failed to sync SparkThriftServer %q: %v", key, err))
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+
+	c.queue.AddRateLimited(key)
+}
+
+func (c *Controller) dequeue(obj interface{}) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.Errorf("failed to get key for %v: %v", obj, err)
+		return
+	}
+
+	c.queue.Forget(key)
+	c.queue.Done(key)
+}
+
+// onAppChange enqueues the SparkThriftServer owning the given SparkApplication, if any, to update its status.
+func (c *Controller) onAppChange(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	app, ok := obj.(*v1beta1.SparkApplication)
+	if !ok {
+		return
+	}
+	if owner := getOwningServer(&app.ObjectMeta); owner != "" {
+		c.queue.Add(app.Namespace + "/" + owner)
+	}
+}
+
+// getOwningServer returns the name of the SparkThriftServer the object with the given metadata was created
+// for, if any.
+func getOwningServer(meta *metav1.ObjectMeta) string {
+	if ref := metav1.GetControllerOf(meta); ref != nil && ref.Kind == reflect.TypeOf(v1beta1.SparkThriftServer{}).Name() {
+		return ref.Name
+	}
+	return ""
+}
+
+func (c *Controller) syncSparkThriftServer(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	server, err := c.serverLister.SparkThriftServers(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The resources of a deleted server are garbage collected.
+			return nil
+		}
+		return err
+	}
+
+	glog.V(2).Infof("Syncing SparkThriftServer %s/%s", server.Namespace, server.Name)
+	status := server.Status.DeepCopy()
+	app, err := buildSparkApplication(server)
+	if err != nil {
+		glog.Errorf("invalid SparkThriftServer %s/%s: %v", server.Namespace, server.Name, err)
+		status.AppState = v1beta1.ApplicationState{State: v1beta1.FailedState, ErrorMessage: err.Error()}
+		return c.updateSparkThriftServerStatus(server, status)
+	}
+
+	if err := c.syncScript(server); err != nil {
+		return err
+	}
+	current, err := c.syncSparkApplication(server, app)
+	if err != nil {
+		return err
+	}
+	if err := c.syncService(server); err != nil {
+		return err
+	}
+	if err := c.syncIngress(server); err != nil {
+		return err
+	}
+	status.SparkApplicationName = current.Name
+	status.AppState = current.Status.AppState
+	status.Endpoint = getEndpoint(server)
+	return c.updateSparkThriftServerStatus(server, status)
+}
+
+// syncScript creates or updates the ConfigMap with the script starting the given server.
+func (c *Controller) syncScript(server *v1beta1.SparkThriftServer) error {
+	configMap := buildScriptConfigMap(server)
+	existing, err := c.kubeClient.CoreV1().ConfigMaps(server.Namespace).Get(configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(server.Namespace).Create(configMap)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Data, configMap.Data) {
+		return nil
+	}
+	existing.Data = configMap.Data
+	_, err = c.kubeClient.CoreV1().ConfigMaps(server.Namespace).Update(existing)
+	return err
+}
+
+// syncSparkApplication creates the given SparkApplication generated for the given server, or updates the
+// existing one if the generated spec has changed, and returns the current SparkApplication.
+func (c *Controller) syncSparkApplication(server *v1beta1.SparkThriftServer, app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
+	existing, err := c.appLister.SparkApplications(server.Namespace).Get(app.Name)
+	if errors.IsNotFound(err) {
+		glog.Infof("Creating SparkApplication %s/%s for SparkThriftServer %s", app.Namespace, app.Name, server.Name)
+		return c.crdClient.SparkoperatorV1beta1().SparkApplications(server.Namespace).Create(app)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if getOwningServer(&existing.ObjectMeta) != server.Name {
+		return nil, fmt.Errorf("SparkApplication %s/%s exists and is not owned by SparkThriftServer %s",
+			existing.Namespace, existing.Name, server.Name)
+	}
+	if existing.Annotations[config.GeneratedSpecHashAnnotation] == app.Annotations[config.GeneratedSpecHashAnnotation] {
+		return existing, nil
+	}
+
+	// The SparkApplication controller reruns applications whose spec has changed, which restarts the server.
+	glog.Infof("Updating SparkApplication %s/%s of changed SparkThriftServer %s", app.Namespace, app.Name, server.Name)
+	toUpdate := existing.DeepCopy()
+	// Labels and annotations set by others, e.g., the state label of the application, are kept.
+	toUpdate.Labels = util.MergeOwnedEntries(existing.Labels, app.Labels)
+	toUpdate.Annotations = util.MergeOwnedEntries(existing.Annotations, app.Annotations)
+	toUpdate.Spec = app.Spec
+	return c.crdClient.SparkoperatorV1beta1().SparkApplications(server.Namespace).Update(toUpdate)
+}
+
+// syncService creates or updates the Service exposing the given server.
+func (c *Controller) syncService(server *v1beta1.SparkThriftServer) error {
+	service := buildService(server)
+	existing, err := c.kubeClient.CoreV1().Services(server.Namespace).Get(service.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		glog.Infof("Creating Service %s/%s for SparkThriftServer %s", service.Namespace, service.Name, server.Name)
+		_, err = c.kubeClient.CoreV1().Services(server.Namespace).Create(service)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if getOwningServer(&existing.ObjectMeta) != server.Name {
+		return fmt.Errorf("Service %s/%s exists and is not owned by SparkThriftServer %s",
+			existing.Namespace, existing.Name, server.Name)
+	}
+	if existing.Spec.Type == service.Spec.Type &&
+		reflect.DeepEqual(existing.Spec.Selector, service.Spec.Selector) &&
+		reflect.DeepEqual(existing.Annotations, service.Annotations) &&
+		portsMatch(existing.Spec.Ports, service.Spec.Ports) {
+		return nil
+	}
+
+	// The cluster IP of the existing Service is kept, so that clients resolving it keep working.
+	toUpdate := existing.DeepCopy()
+	toUpdate.Annotations = service.Annotations
+	toUpdate.Spec.Type = service.Spec.Type
+	toUpdate.Spec.Selector = service.Spec.Selector
+	toUpdate.Spec.Ports = service.Spec.Ports
+	_, err = c.kubeClient.CoreV1().Services(server.Namespace).Update(toUpdate)
+	return err
+}
+
+// portsMatch tells if the existing ports of a Service match the desired ones, ignoring the node ports
+// allocated to the existing ones.
+func portsMatch(existing []apiv1.ServicePort, desired []apiv1.ServicePort) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range existing {
+		if existing[i].Name != desired[i].Name || existing[i].Port != desired[i].Port ||
+			existing[i].TargetPort != desired[i].TargetPort {
+			return false
+		}
+	}
+	return true
+}
+
+// syncIngress creates or updates the Ingress of the given server if it has an ingress host, or deletes the
+// Ingress of the server otherwise.
+func (c *Controller) syncIngress(server *v1beta1.SparkThriftServer) error {
+	ingress := buildIngress(server)
+	existing, err := c.kubeClient.ExtensionsV1beta1().Ingresses(server.Namespace).Get(getServiceName(server), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if ingress == nil {
+			return nil
+		}
+		glog.Infof("Creating Ingress %s/%s for SparkThriftServer %s", ingress.Namespace, ingress.Name, server.Name)
+		_, err = c.kubeClient.ExtensionsV1beta1().Ingresses(server.Namespace).Create(ingress)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if getOwningServer(&existing.ObjectMeta) != server.Name {
+		if ingress == nil {
+			return nil
+		}
+		return fmt.Errorf("Ingress %s/%s exists and is not owned by SparkThriftServer %s",
+			existing.Namespace, existing.Name, server.Name)
+	}
+	if ingress == nil {
+		glog.Infof("Deleting Ingress %s/%s of SparkThriftServer %s", existing.Namespace, existing.Name, server.Name)
+		return c.kubeClient.ExtensionsV1beta1().Ingresses(server.Namespace).Delete(existing.Name, &metav1.DeleteOptions{})
+	}
+	if reflect.DeepEqual(existing.Spec, ingress.Spec) {
+		return nil
+	}
+	toUpdate := existing.DeepCopy()
+	toUpdate.Spec = ingress.Spec
+	_, err = c.kubeClient.ExtensionsV1beta1().Ingresses(server.Namespace).Update(toUpdate)
+	return err
+}
+
+func (c *Controller) updateSparkThriftServerStatus(server *v1beta1.SparkThriftServer, newStatus *v1beta1.SparkThriftServerStatus) error {
+	// If the status has not changed, do not perform an update.
+	if reflect.DeepEqual(newStatus, &server.Status) {
+		return nil
+	}
+
+	toUpdate := server.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		toUpdate.Status = *newStatus
+		_, updateErr := c.crdClient.SparkoperatorV1beta1().SparkThriftServers(toUpdate.Namespace).UpdateStatus(toUpdate)
+		if updateErr == nil {
+			return nil
+		}
+
+		result, err := c.crdClient.SparkoperatorV1beta1().SparkThriftServers(toUpdate.Namespace).Get(
+			toUpdate.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		toUpdate = result
+
+		return updateErr
+	})
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkthriftserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestBuildSparkApplication(t *testing.T) {
+	var minExecutors int32 = 1
+	idleTimeout := "120s"
+	ldapURL := "ldap://ldap.example.com"
+	impersonate := true
+	server := &v1beta1.SparkThriftServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "sql", Namespace: "analytics", Labels: map[string]string{"team": "a"}},
+		Spec: v1beta1.SparkThriftServerSpec{
+			SparkVersion:  "3.0.0",
+			TransportMode: v1beta1.ThriftHTTPTransport,
+			Autoscaling: &v1beta1.ThriftServerAutoscaling{
+				MinExecutors:        &minExecutors,
+				MaxExecutors:        10,
+				ExecutorIdleTimeout: &idleTimeout,
+			},
+			Authentication: &v1beta1.ThriftServerAuthentication{
+				Type:             v1beta1.ThriftLDAPAuthentication,
+				LDAPURL:          &ldapURL,
+				ImpersonateUsers: &impersonate,
+			},
+			SparkConf:  map[string]string{"spark.sql.shuffle.partitions": "64"},
+			HadoopConf: map[string]string{"hive.server2.thrift.http.port": "8080", "hive.metastore.uris": "thrift://hms:9083"},
+		},
+	}
+
+	app, err := buildSparkApplication(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "sql", app.Name)
+	assert.Equal(t, "sql", app.Labels[config.SparkThriftServerNameLabel])
+	assert.Equal(t, "a", app.Labels["team"])
+	assert.Equal(t, "SparkThriftServer", app.OwnerReferences[0].Kind)
+	assert.Equal(t, v1beta1.Always, app.Spec.RestartPolicy.Type)
+	assert.Equal(t, "local:///etc/spark-thrift-server/thrift_server.py", *app.Spec.MainApplicationFile)
+	assert.Equal(t, []v1beta1.NamePath{{Name: "sql-thrift-script", Path: scriptMountPath}}, app.Spec.Driver.ConfigMaps)
+	assert.NotEmpty(t, app.Annotations[config.GeneratedSpecHashAnnotation])
+
+	// The port of the server is not overridden by hadoopConf.
+	assert.Equal(t, map[string]string{
+		"hive.metastore.uris":                  "thrift://hms:9083",
+		"hive.server2.transport.mode":          "http",
+		"hive.server2.thrift.http.port":        "10001",
+		"hive.server2.thrift.http.path":        "cliservice",
+		"hive.server2.authentication":          "LDAP",
+		"hive.server2.authentication.ldap.url": "ldap://ldap.example.com",
+		"hive.server2.enable.doAs":             "true",
+	}, app.Spec.HadoopConf)
+	assert.Equal(t, map[string]string{
+		"spark.sql.shuffle.partitions":                    "64",
+		"spark.dynamicAllocation.enabled":                 "true",
+		"spark.dynamicAllocation.shuffleTracking.enabled": "true",
+		"spark.dynamicAllocation.minExecutors":            "1",
+		"spark.dynamicAllocation.maxExecutors":            "10",
+		"spark.dynamicAllocation.executorIdleTimeout":     "120s",
+	}, app.Spec.SparkConf)
+	assert.Equal(t, "jdbc:hive2://sql.analytics.svc:10001/;transportMode=http;httpPath=cliservice", getEndpoint(server))
+	// The server itself is not modified.
+	assert.Nil(t, server.Spec.Driver.ConfigMaps)
+	assert.Len(t, server.Spec.HadoopConf, 2)
+}
+
+func TestValidate(t *testing.T) {
+	host := "sql.example.com"
+	var minExecutors int32 = 5
+	server := &v1beta1.SparkThriftServer{Spec: v1beta1.SparkThriftServerSpec{SparkVersion: "3.0.0"}}
+	assert.Nil(t, validate(server))
+
+	server.Spec.IngressHost = &host
+	assert.NotNil(t, validate(server))
+	server.Spec.TransportMode = v1beta1.ThriftHTTPTransport
+	assert.Nil(t, validate(server))
+
+	server.Spec.Autoscaling = &v1beta1.ThriftServerAutoscaling{MinExecutors: &minExecutors, MaxExecutors: 2}
+	assert.NotNil(t, validate(server))
+	server.Spec.Autoscaling = nil
+
+	server.Spec.Authentication = &v1beta1.ThriftServerAuthentication{Type: v1beta1.ThriftLDAPAuthentication}
+	assert.NotNil(t, validate(server))
+}
+
+func TestSyncSparkThriftServer(t *testing.T) {
+	host := "sql.example.com"
+	server := &v1beta1.SparkThriftServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "sql", Namespace: "analytics"},
+		Spec: v1beta1.SparkThriftServerSpec{
+			SparkVersion:  "3.0.0",
+			TransportMode: v1beta1.ThriftHTTPTransport,
+			IngressHost:   &host,
+		},
+	}
+	c := newFakeController()
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkThriftServers(server.Namespace).Create(server); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.syncSparkThriftServer("analytics/sql"); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err := c.kubeClient.CoreV1().ConfigMaps("analytics").Get("sql-thrift-script", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, thriftServerScript, configMap.Data[scriptFileName])
+	service, err := c.kubeClient.CoreV1().Services("analytics").Get("sql", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, apiv1.ServiceTypeClusterIP, service.Spec.Type)
+	assert.Equal(t, "sql", service.Spec.Selector[config.SparkAppNameLabel])
+	assert.Equal(t, int32(10001), service.Spec.Ports[0].Port)
+	assert.Equal(t, "10002", service.Annotations["prometheus.io/port"])
+	ingress, err := c.kubeClient.ExtensionsV1beta1().Ingresses("analytics").Get("sql", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, host, ingress.Spec.Rules[0].Host)
+	app, err := c.crdClient.SparkoperatorV1beta1().SparkApplications("analytics").Get("sql", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The state of the SparkApplication and the endpoint are reported in the status of the server.
+	app.Status.AppState.State = v1beta1.RunningState
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkApplications("analytics").Update(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncSparkThriftServer("analytics/sql"); err != nil {
+		t.Fatal(err)
+	}
+	server, err = c.crdClient.SparkoperatorV1beta1().SparkThriftServers("analytics").Get("sql", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "sql", server.Status.SparkApplicationName)
+	assert.Equal(t, v1beta1.RunningState, server.Status.AppState.State)
+	assert.Equal(t, "jdbc:hive2://sql.analytics.svc:10001/;transportMode=http;httpPath=cliservice", server.Status.Endpoint)
+
+	// Switching to the binary transport mode without an ingress host updates the Service and deletes the Ingress.
+	server.Spec.TransportMode = v1beta1.ThriftBinaryTransport
+	server.Spec.IngressHost = nil
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkThriftServers("analytics").Update(server); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncSparkThriftServer("analytics/sql"); err != nil {
+		t.Fatal(err)
+	}
+	service, err = c.kubeClient.CoreV1().Services("analytics").Get("sql", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(10000), service.Spec.Ports[0].Port)
+	_, err = c.kubeClient.ExtensionsV1beta1().Ingresses("analytics").Get("sql", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	app, err = c.crdClient.SparkoperatorV1beta1().SparkApplications("analytics").Get("sql", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "10000", app.Spec.HadoopConf["hive.server2.thrift.port"])
+}
+
+func TestSyncSparkThriftServer_NotOwned(t *testing.T) {
+	server := &v1beta1.SparkThriftServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "sql", Namespace: "analytics"},
+		Spec:       v1beta1.SparkThriftServerSpec{SparkVersion: "3.0.0"},
+	}
+	c := newFakeController()
+	if _, err := c.crdClient.SparkoperatorV1beta1().SparkThriftServers(server.Namespace).Create(server); err != nil {
+		t.Fatal(err)
+	}
+	service := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "sql", Namespace: "analytics"}}
+	if _, err := c.kubeClient.CoreV1().Services(service.Namespace).Create(service); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotNil(t, c.syncSparkThriftServer("analytics/sql"))
+}
+
+func newFakeController() *Controller {
+	crdClient := crdclientfake.NewSimpleClientset()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 1*time.Second)
+	controller := NewController(crdClient, kubeClient, informerFactory)
+	serverInformer := informerFactory.Sparkoperator().V1beta1().SparkThriftServers().Informer()
+	appInformer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
+	crdClient.PrependReactor("create", "sparkthriftservers",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			serverInformer.GetStore().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("update", "sparkthriftservers",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.UpdateAction).GetObject()
+			serverInformer.GetStore().Update(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("create", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			appInformer.GetStore().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("update", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.UpdateAction).GetObject()
+			appInformer.GetStore().Update(obj)
+			return false, obj, nil
+		})
+	return controller
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkthriftserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	apiv1 "k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	// scriptMountPath is where the ConfigMap with the script starting the server is mounted in the driver.
+	scriptMountPath = "/etc/spark-thrift-server"
+	scriptFileName  = "thrift_server.py"
+
+	defaultBinaryPort = 10000
+	defaultHTTPPort   = 10001
+	// metricsPort is the port the script serves the session metrics of the server on.
+	metricsPort = 10002
+	httpPath    = "cliservice"
+
+	thriftPortName  = "thrift"
+	metricsPortName = "metrics"
+)
+
+// thriftServerScript starts the Thrift server in the driver of a SparkApplication, as its launcher class
+// cannot be submitted in cluster mode, and serves the number of open sessions and running statements of the
+// server as Prometheus metrics until the SparkContext stops.
+const thriftServerScript = `import argparse
+import threading
+import time
+from http.server import BaseHTTPRequestHandler, HTTPServer
+
+from pyspark.sql import SparkSession
+
+parser = argparse.ArgumentParser()
+parser.add_argument("--metrics-port", type=int, required=True)
+args = parser.parse_args()
+
+spark = SparkSession.builder.enableHiveSupport().getOrCreate()
+jvm = spark.sparkContext._jvm
+jsc = spark.sparkContext._jsc.sc()
+thriftserver = jvm.org.apache.spark.sql.hive.thriftserver
+thriftserver.HiveThriftServer2.startWithContext(spark._jsparkSession.sqlContext())
+
+
+def get_stats():
+    """Returns the tracker of sessions and statements, which moved to the status store in Spark 3."""
+    try:
+        store = jsc.statusStore().store()
+    except Exception:
+        return thriftserver.HiveThriftServer2.listener()
+    try:
+        return thriftserver.ui.HiveThriftServer2AppStatusStore(store)
+    except Exception:
+        return thriftserver.ui.HiveThriftServer2AppStatusStore(store, jvm.scala.Option.apply(None))
+
+
+stats = get_stats()
+
+
+class MetricsHandler(BaseHTTPRequestHandler):
+    def do_GET(self):
+        body = ("# HELP spark_thrift_server_open_sessions Number of open sessions.\n"
+                "# TYPE spark_thrift_server_open_sessions gauge\n"
+                "spark_thrift_server_open_sessions %d\n"
+                "# HELP spark_thrift_server_running_statements Number of running statements.\n"
+                "# TYPE spark_thrift_server_running_statements gauge\n"
+                "spark_thrift_server_running_statements %d\n"
+                % (stats.getOnlineSessionNum(), stats.getTotalRunning())).encode("utf-8")
+        self.send_response(200)
+        self.send_header("Content-Type", "text/plain; version=0.0.4")
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def log_message(self, *args):
+        pass
+
+
+metrics_server = HTTPServer(("", args.metrics_port), MetricsHandler)
+
+
+def wait_for_stop():
+    while not jsc.isStopped():
+        time.sleep(10)
+    metrics_server.shutdown()
+
+
+threading.Thread(target=wait_for_stop, daemon=True).start()
+metrics_server.serve_forever()
+spark.stop()
+`
+
+func getTransportMode(server *v1beta1.SparkThriftServer) v1beta1.ThriftTransportMode {
+	if server.Spec.TransportMode == "" {
+		return v1beta1.ThriftBinaryTransport
+	}
+	return server.Spec.TransportMode
+}
+
+func getPort(server *v1beta1.SparkThriftServer) int32 {
+	if server.Spec.Port != nil {
+		return *server.Spec.Port
+	}
+	if getTransportMode(server) == v1beta1.ThriftHTTPTransport {
+		return defaultHTTPPort
+	}
+	return defaultBinaryPort
+}
+
+func getScriptConfigMapName(server *v1beta1.SparkThriftServer) string {
+	return util.BuildName(server.Name, "thrift-script", util.DNS1123SubdomainMaxLength)
+}
+
+// getServiceName returns the name of the Service of the given server, which is also the name of the server
+// so that the endpoint is predictable.
+func getServiceName(server *v1beta1.SparkThriftServer) string {
+	return server.Name
+}
+
+// getEndpoint returns the JDBC URL of the given server in the cluster.
+func getEndpoint(server *v1beta1.SparkThriftServer) string {
+	endpoint := fmt.Sprintf("jdbc:hive2://%s.%s.svc:%d/", getServiceName(server), server.Namespace, getPort(server))
+	if getTransportMode(server) == v1beta1.ThriftHTTPTransport {
+		endpoint += fmt.Sprintf(";transportMode=http;httpPath=%s", httpPath)
+	}
+	return endpoint
+}
+
+// validate returns an error if the settings of the given server contradict each other.
+func validate(server *v1beta1.SparkThriftServer) error {
+	mode := getTransportMode(server)
+	if mode != v1beta1.ThriftBinaryTransport && mode != v1beta1.ThriftHTTPTransport {
+		return fmt.Errorf("unknown transportMode %q", mode)
+	}
+	if server.Spec.IngressHost != nil && mode != v1beta1.ThriftHTTPTransport {
+		return fmt.Errorf("ingressHost requires the http transportMode")
+	}
+	if port := getPort(server); port == metricsPort {
+		return fmt.Errorf("port %d is reserved for metrics", port)
+	}
+	if autoscaling := server.Spec.Autoscaling; autoscaling != nil {
+		if autoscaling.MaxExecutors < 1 {
+			return fmt.Errorf("autoscaling.maxExecutors must be positive")
+		}
+		if autoscaling.MinExecutors != nil && *autoscaling.MinExecutors > autoscaling.MaxExecutors {
+			return fmt.Errorf("autoscaling.minExecutors must not exceed autoscaling.maxExecutors")
+		}
+	}
+	if auth := server.Spec.Authentication; auth != nil {
+		switch auth.Type {
+		case v1beta1.ThriftNoAuthentication:
+		case v1beta1.ThriftLDAPAuthentication:
+			if auth.LDAPURL == nil {
+				return fmt.Errorf("authentication type %s requires authentication.ldapURL", auth.Type)
+			}
+		default:
+			return fmt.Errorf("unknown authentication type %q", auth.Type)
+		}
+	}
+	return nil
+}
+
+// buildHadoopConf returns the HiveServer2 configuration of the given server, which takes precedence over the
+// hadoopConf of the server because the Service depends on it.
+func buildHadoopConf(server *v1beta1.SparkThriftServer) map[string]string {
+	conf := make(map[string]string)
+	for key, value := range server.Spec.HadoopConf {
+		conf[key] = value
+	}
+	port := fmt.Sprintf("%d", getPort(server))
+	mode := getTransportMode(server)
+	conf["hive.server2.transport.mode"] = string(mode)
+	if mode == v1beta1.ThriftHTTPTransport {
+		conf["hive.server2.thrift.http.port"] = port
+		conf["hive.server2.thrift.http.path"] = httpPath
+	} else {
+		conf["hive.server2.thrift.port"] = port
+	}
+
+	if auth := server.Spec.Authentication; auth != nil {
+		conf["hive.server2.authentication"] = string(auth.Type)
+		if auth.LDAPURL != nil {
+			conf["hive.server2.authentication.ldap.url"] = *auth.LDAPURL
+		}
+		if auth.LDAPBaseDN != nil {
+			conf["hive.server2.authentication.ldap.baseDN"] = *auth.LDAPBaseDN
+		}
+		impersonate := auth.ImpersonateUsers != nil && *auth.ImpersonateUsers
+		conf["hive.server2.enable.doAs"] = fmt.Sprintf("%t", impersonate)
+	}
+	return conf
+}
+
+// buildSparkConf returns the Spark configuration of the given server, with dynamic allocation based on
+// shuffle tracking, which needs no external shuffle service, if the server autoscales.
+func buildSparkConf(server *v1beta1.SparkThriftServer) map[string]string {
+	conf := make(map[string]string)
+	for key, value := range server.Spec.SparkConf {
+		conf[key] = value
+	}
+	if autoscaling := server.Spec.Autoscaling; autoscaling != nil {
+		var minExecutors int32
+		if autoscaling.MinExecutors != nil {
+			minExecutors = *autoscaling.MinExecutors
+		}
+		conf["spark.dynamicAllocation.enabled"] = "true"
+		conf["spark.dynamicAllocation.shuffleTracking.enabled"] = "true"
+		conf["spark.dynamicAllocation.minExecutors"] = fmt.Sprintf("%d", minExecutors)
+		conf[config.SparkDynamicAllocationMaxExecutorsKey] = fmt.Sprintf("%d", autoscaling.MaxExecutors)
+		if autoscaling.ExecutorIdleTimeout != nil {
+			conf["spark.dynamicAllocation.executorIdleTimeout"] = *autoscaling.ExecutorIdleTimeout
+		}
+	}
+	return conf
+}
+
+// buildSparkApplication generates the SparkApplication running the given server, which is restarted
+// whenever it terminates.
+func buildSparkApplication(server *v1beta1.SparkThriftServer) (*v1beta1.SparkApplication, error) {
+	if err := validate(server); err != nil {
+		return nil, err
+	}
+
+	app := &v1beta1.SparkApplication{}
+	app.Name = server.Name
+	app.Namespace = server.Namespace
+	app.OwnerReferences = append(app.OwnerReferences, newOwnerReference(server))
+	app.Labels = make(map[string]string)
+	for key, value := range server.Labels {
+		app.Labels[key] = value
+	}
+	app.Labels[config.SparkThriftServerNameLabel] = server.Name
+
+	spec := server.Spec.DeepCopy()
+	pythonVersion := "3"
+	mainApplicationFile := fmt.Sprintf("local://%s/%s", scriptMountPath, scriptFileName)
+	app.Spec = v1beta1.SparkApplicationSpec{
+		Type:                v1beta1.PythonApplicationType,
+		PythonVersion:       &pythonVersion,
+		Mode:                v1beta1.ClusterMode,
+		Image:               spec.Image,
+		MainApplicationFile: &mainApplicationFile,
+		Arguments:           []string{"--metrics-port", fmt.Sprintf("%d", metricsPort)},
+		SparkVersion:        spec.SparkVersion,
+		Deps:                spec.Deps,
+		SparkConf:           buildSparkConf(server),
+		HadoopConf:          buildHadoopConf(server),
+		Driver:              spec.Driver,
+		Executor:            spec.Executor,
+		RestartPolicy:       v1beta1.RestartPolicy{Type: v1beta1.Always},
+	}
+	app.Spec.Driver.ConfigMaps = append(app.Spec.Driver.ConfigMaps,
+		v1beta1.NamePath{Name: getScriptConfigMapName(server), Path: scriptMountPath})
+
+	hash, err := hashSpec(&app.Spec)
+	if err != nil {
+		return nil, err
+	}
+	app.Annotations = map[string]string{config.GeneratedSpecHashAnnotation: hash}
+	return app, nil
+}
+
+// hashSpec returns a hash of the given spec, which tells if the spec generated for a server has changed
+// without comparing it to the spec of the SparkApplication, which gets defaulted.
+func hashSpec(spec *v1beta1.SparkApplicationSpec) (string, error) {
+	specBytes, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hasher := util.NewHash32()
+	hasher.Write(specBytes)
+	return fmt.Sprintf("%08x", hasher.Sum32()), nil
+}
+
+func newOwnerReference(server *v1beta1.SparkThriftServer) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: v1beta1.SchemeGroupVersion.String(),
+		Kind:       reflect.TypeOf(v1beta1.SparkThriftServer{}).Name(),
+		Name:       server.Name,
+		UID:        server.UID,
+		Controller: &controller,
+	}
+}
+
+func newObjectMeta(server *v1beta1.SparkThriftServer, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       server.Namespace,
+		Labels:          map[string]string{config.SparkThriftServerNameLabel: server.Name},
+		OwnerReferences: []metav1.OwnerReference{newOwnerReference(server)},
+	}
+}
+
+func buildScriptConfigMap(server *v1beta1.SparkThriftServer) *apiv1.ConfigMap {
+	return &apiv1.ConfigMap{
+		ObjectMeta: newObjectMeta(server, getScriptConfigMapName(server)),
+		Data:       map[string]string{scriptFileName: thriftServerScript},
+	}
+}
+
+// buildService returns the Service exposing the HiveServer2 endpoint and the metrics of the driver of the
+// given server, which Prometheus scrapes through the annotations of the Service.
+func buildService(server *v1beta1.SparkThriftServer) *apiv1.Service {
+	serviceType := apiv1.ServiceTypeClusterIP
+	if server.Spec.ServiceType != nil {
+		serviceType = *server.Spec.ServiceType
+	}
+	service := &apiv1.Service{
+		ObjectMeta: newObjectMeta(server, getServiceName(server)),
+		Spec: apiv1.ServiceSpec{
+			Type: serviceType,
+			Selector: map[string]string{
+				config.SparkAppNameLabel: server.Name,
+				config.SparkRoleLabel:    config.SparkDriverRole,
+			},
+			Ports: []apiv1.ServicePort{
+				{
+					Name:       thriftPortName,
+					Port:       getPort(server),
+					TargetPort: intstr.FromInt(int(getPort(server))),
+				},
+				{
+					Name:       metricsPortName,
+					Port:       metricsPort,
+					TargetPort: intstr.FromInt(metricsPort),
+				},
+			},
+		},
+	}
+	service.Annotations = map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   fmt.Sprintf("%d", metricsPort),
+		"prometheus.io/path":   "/metrics",
+	}
+	return service
+}
+
+// buildIngress returns the Ingress exposing the HTTP endpoint of the given server at its ingress host, or
+// nil if the server has no ingress host.
+func buildIngress(server *v1beta1.SparkThriftServer) *extensions.Ingress {
+	if server.Spec.IngressHost == nil {
+		return nil
+	}
+	return &extensions.Ingress{
+		ObjectMeta: newObjectMeta(server, getServiceName(server)),
+		Spec: extensions.IngressSpec{
+			Rules: []extensions.IngressRule{{
+				Host: *server.Spec.IngressHost,
+				IngressRuleValue: extensions.IngressRuleValue{
+					HTTP: &extensions.HTTPIngressRuleValue{
+						Paths: []extensions.HTTPIngressPath{{
+							Path: "/" + httpPath,
+							Backend: extensions.IngressBackend{
+								ServiceName: getServiceName(server),
+								ServicePort: intstr.FromString(thriftPortName),
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+}
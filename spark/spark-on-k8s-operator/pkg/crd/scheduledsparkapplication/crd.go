@@ -52,10 +52,16 @@ func GetCRD() *apiextensionsv1beta1.CustomResourceDefinition {
 				Kind:       reflect.TypeOf(v1beta1.ScheduledSparkApplication{}).Name(),
 			},
 			Validation: getCustomResourceValidation(),
+			Subresources: &apiextensionsv1beta1.CustomResourceSubresources{
+				Status: &apiextensionsv1beta1.CustomResourceSubresourceStatus{},
+			},
 		},
 	}
 }
 
+// getCustomResourceValidation returns the validation of the CRD. The x-kubernetes-list-type and
+// x-kubernetes-map-type extensions used by server-side apply are only in manifest/spark-operator-crds.yaml, as the
+// apiextensions client in use cannot express them.
 func getCustomResourceValidation() *apiextensionsv1beta1.CustomResourceValidation {
 	return &apiextensionsv1beta1.CustomResourceValidation{
 		OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
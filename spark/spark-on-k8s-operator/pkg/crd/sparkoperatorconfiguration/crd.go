@@ -0,0 +1,101 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sparkoperatorconfiguration
+
+import (
+	"reflect"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// CRD metadata.
+const (
+	Plural    = "sparkoperatorconfigurations"
+	Singular  = "sparkoperatorconfiguration"
+	ShortName = "operatorconfig"
+	Group     = sparkoperator.GroupName
+	Version   = v1beta1.Version
+	FullName  = Plural + "." + Group
+)
+
+func GetCRD() *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: FullName,
+		},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Group:   Group,
+			Version: Version,
+			Scope:   apiextensionsv1beta1.ClusterScoped,
+			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+				Plural:     Plural,
+				Singular:   Singular,
+				ShortNames: []string{ShortName},
+				Kind:       reflect.TypeOf(v1beta1.SparkOperatorConfiguration{}).Name(),
+			},
+			Validation: getCustomResourceValidation(),
+			// With the status subresource, the generation of a configuration only changes with its spec.
+			Subresources: &apiextensionsv1beta1.CustomResourceSubresources{
+				Status: &apiextensionsv1beta1.CustomResourceSubresourceStatus{},
+			},
+		},
+	}
+}
+
+func getCustomResourceValidation() *apiextensionsv1beta1.CustomResourceValidation {
+	return &apiextensionsv1beta1.CustomResourceValidation{
+		OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
+			Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+				"spec": {
+					Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+						"queueing": {
+							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+								"maxRunningApplications": {
+									Type:    "integer",
+									Minimum: float64Ptr(0),
+								},
+								"queueWeights": {
+									AdditionalProperties: &apiextensionsv1beta1.JSONSchemaPropsOrBool{
+										Schema: &apiextensionsv1beta1.JSONSchemaProps{
+											Type:    "integer",
+											Minimum: float64Ptr(1),
+										},
+									},
+								},
+								"maxPriorities": {
+									AdditionalProperties: &apiextensionsv1beta1.JSONSchemaPropsOrBool{
+										Schema: &apiextensionsv1beta1.JSONSchemaProps{
+											Type: "integer",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datahub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	ingestEndpoint     = "/aspects?action=ingestProposal"
+	orchestrator       = "spark"
+	pushRequestTimeout = 5 * time.Second
+)
+
+// Proposal is a DataHub MetadataChangeProposal upserting a single aspect of an entity.
+type Proposal struct {
+	EntityType string        `json:"entityType"`
+	EntityURN  string        `json:"entityUrn"`
+	ChangeType string        `json:"changeType"`
+	AspectName string        `json:"aspectName"`
+	Aspect     GenericAspect `json:"aspect"`
+}
+
+// GenericAspect is an aspect serialized as a JSON string, which is how the GMS ingestProposal endpoint
+// expects aspects.
+type GenericAspect struct {
+	Value       string `json:"value"`
+	ContentType string `json:"contentType"`
+}
+
+type ingestRequest struct {
+	Proposal *Proposal `json:"proposal"`
+}
+
+// Client pushes job metadata of terminated SparkApplications to the metadata service (GMS) of DataHub, so
+// that the catalog reflects the latest run of each job.
+type Client struct {
+	url     string
+	cluster string
+	token   string
+	client  *http.Client
+}
+
+// NewClient creates a new Client for the GMS with the given base URL. Jobs are put into the given DataHub
+// cluster, i.e., environment. The token is sent as a bearer token if set.
+func NewClient(url string, cluster string, token string) *Client {
+	return &Client{
+		url:     strings.TrimSuffix(url, "/"),
+		cluster: cluster,
+		token:   token,
+		client:  &http.Client{Timeout: pushRequestTimeout},
+	}
+}
+
+// getJobName returns the name of the job of the given application, which is the name of the
+// ScheduledSparkApplication that created it if any, so that the runs of a schedule update the same job.
+func getJobName(app *v1beta1.SparkApplication) string {
+	for _, ref := range app.OwnerReferences {
+		if ref.Kind == "ScheduledSparkApplication" {
+			return ref.Name
+		}
+	}
+	return app.Name
+}
+
+// getJobURN returns the URN of the DataHub job of the given application, which belongs to a flow per
+// namespace.
+func (c *Client) getJobURN(app *v1beta1.SparkApplication) string {
+	flowURN := fmt.Sprintf("urn:li:dataFlow:(%s,%s,%s)", orchestrator, app.Namespace, c.cluster)
+	return fmt.Sprintf("urn:li:dataJob:(%s,%s)", flowURN, getJobName(app))
+}
+
+// getDatasetURNs returns the URNs of the datasets listed in the given annotation of the application. Datasets
+// are either given as URNs or as "<platform>:<name>", e.g., "hive:db.table", in the cluster of the client.
+func (c *Client) getDatasetURNs(app *v1beta1.SparkApplication, annotation string) []string {
+	urns := []string{}
+	for _, dataset := range splitDatasets(app.Annotations[annotation]) {
+		dataset = strings.TrimSpace(dataset)
+		if dataset == "" {
+			continue
+		}
+		if !strings.HasPrefix(dataset, "urn:li:") {
+			if parts := strings.SplitN(dataset, ":", 2); len(parts) == 2 {
+				dataset = fmt.Sprintf("urn:li:dataset:(urn:li:dataPlatform:%s,%s,%s)",
+					parts[0], parts[1], strings.ToUpper(c.cluster))
+			}
+		}
+		urns = append(urns, dataset)
+	}
+	sort.Strings(urns)
+	return urns
+}
+
+// splitDatasets splits the given comma-separated list of datasets, ignoring the commas within URNs.
+func splitDatasets(list string) []string {
+	var datasets []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				datasets = append(datasets, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(datasets, list[start:])
+}
+
+func getOwner(app *v1beta1.SparkApplication) string {
+	if owner := app.Annotations[config.OwnerAnnotation]; owner != "" {
+		return owner
+	}
+	return app.Status.SubmittedBy
+}
+
+func getJobInfo(app *v1beta1.SparkApplication) map[string]interface{} {
+	properties := map[string]string{
+		"namespace": app.Namespace,
+		"status":    string(app.Status.AppState.State),
+	}
+	if app.Status.SparkApplicationID != "" {
+		properties["sparkApplicationId"] = app.Status.SparkApplicationID
+	}
+	submissionTime := app.Status.LastSubmissionAttemptTime
+	if !submissionTime.IsZero() {
+		properties["submissionTime"] = submissionTime.UTC().Format(time.RFC3339)
+	}
+	if !app.Status.TerminationTime.IsZero() {
+		properties["terminationTime"] = app.Status.TerminationTime.UTC().Format(time.RFC3339)
+	}
+	if !submissionTime.IsZero() && !app.Status.TerminationTime.IsZero() {
+		duration := app.Status.TerminationTime.Sub(submissionTime.Time)
+		properties["durationSeconds"] = fmt.Sprintf("%d", int64(duration.Seconds()))
+	}
+	if app.Status.AppState.ErrorMessage != "" {
+		properties["errorMessage"] = app.Status.AppState.ErrorMessage
+	}
+	return map[string]interface{}{
+		"name":             getJobName(app),
+		"type":             map[string]string{"string": "SPARK"},
+		"customProperties": properties,
+	}
+}
+
+// getProposals returns the proposals upserting the metadata of the job of the given application.
+func (c *Client) getProposals(app *v1beta1.SparkApplication) ([]*Proposal, error) {
+	urn := c.getJobURN(app)
+	aspects := map[string]interface{}{
+		"dataJobInfo": getJobInfo(app),
+		"dataJobInputOutput": map[string][]string{
+			"inputDatasets":  c.getDatasetURNs(app, config.InputsAnnotation),
+			"outputDatasets": c.getDatasetURNs(app, config.OutputsAnnotation),
+		},
+	}
+	if owner := getOwner(app); owner != "" {
+		if !strings.HasPrefix(owner, "urn:li:") {
+			owner = "urn:li:corpuser:" + owner
+		}
+		aspects["ownership"] = map[string]interface{}{
+			"owners": []map[string]string{{"owner": owner, "type": "DATAOWNER"}},
+		}
+	}
+
+	var names []string
+	for name := range aspects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var proposals []*Proposal
+	for _, name := range names {
+		value, err := json.Marshal(aspects[name])
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, &Proposal{
+			EntityType: "dataJob",
+			EntityURN:  urn,
+			ChangeType: "UPSERT",
+			AspectName: name,
+			Aspect:     GenericAspect{Value: string(value), ContentType: "application/json"},
+		})
+	}
+	return proposals, nil
+}
+
+// Push upserts the metadata of the job of the given terminated application, i.e., its owner, inputs and
+// outputs, status, and duration.
+func (c *Client) Push(app *v1beta1.SparkApplication) error {
+	proposals, err := c.getProposals(app)
+	if err != nil {
+		return err
+	}
+	for _, proposal := range proposals {
+		if err := c.ingest(proposal); err != nil {
+			return fmt.Errorf("failed to push %s of SparkApplication %s/%s: %v",
+				proposal.AspectName, app.Namespace, app.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) ingest(proposal *Proposal) error {
+	body, err := json.Marshal(&ingestRequest{Proposal: proposal})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+ingestEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-RestLi-Protocol-Version", "2.0.0")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("got status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datahub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetDatasetURNs(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				config.InputsAnnotation: "hive:db.events, urn:li:dataset:(urn:li:dataPlatform:s3,bucket/raw,DEV),,",
+			},
+		},
+	}
+	c := NewClient("http://gms:8080", "prod", "")
+	assert.Equal(t, []string{
+		"urn:li:dataset:(urn:li:dataPlatform:hive,db.events,PROD)",
+		"urn:li:dataset:(urn:li:dataPlatform:s3,bucket/raw,DEV)",
+	}, c.getDatasetURNs(app, config.InputsAnnotation))
+	assert.Equal(t, []string{}, c.getDatasetURNs(app, config.OutputsAnnotation))
+}
+
+func TestPush(t *testing.T) {
+	proposals := make(map[string]Proposal)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/aspects", r.URL.Path)
+		assert.Equal(t, "ingestProposal", r.URL.Query().Get("action"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var req ingestRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		proposals[req.Proposal.AspectName] = *req.Proposal
+	}))
+	defer server.Close()
+
+	start := time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nightly-1",
+			Namespace: "team-a",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ScheduledSparkApplication", Name: "nightly"},
+			},
+			Annotations: map[string]string{
+				config.InputsAnnotation:  "hive:db.events",
+				config.OutputsAnnotation: "hive:db.daily",
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.CompletedState},
+			LastSubmissionAttemptTime: metav1.NewTime(start),
+			TerminationTime:           metav1.NewTime(start.Add(90 * time.Second)),
+			SubmittedBy:               "alice",
+		},
+	}
+	if err := NewClient(server.URL, "prod", "secret").Push(app); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, proposals, 3)
+	jobURN := "urn:li:dataJob:(urn:li:dataFlow:(spark,team-a,prod),nightly)"
+	for _, proposal := range proposals {
+		assert.Equal(t, "dataJob", proposal.EntityType)
+		assert.Equal(t, jobURN, proposal.EntityURN)
+		assert.Equal(t, "UPSERT", proposal.ChangeType)
+	}
+
+	var info struct {
+		Name             string            `json:"name"`
+		CustomProperties map[string]string `json:"customProperties"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(proposals["dataJobInfo"].Aspect.Value), &info))
+	assert.Equal(t, "nightly", info.Name)
+	assert.Equal(t, "COMPLETED", info.CustomProperties["status"])
+	assert.Equal(t, "90", info.CustomProperties["durationSeconds"])
+
+	var io map[string][]string
+	assert.Nil(t, json.Unmarshal([]byte(proposals["dataJobInputOutput"].Aspect.Value), &io))
+	assert.Equal(t, []string{"urn:li:dataset:(urn:li:dataPlatform:hive,db.events,PROD)"}, io["inputDatasets"])
+	assert.Equal(t, []string{"urn:li:dataset:(urn:li:dataPlatform:hive,db.daily,PROD)"}, io["outputDatasets"])
+
+	assert.Contains(t, proposals["ownership"].Aspect.Value, `"owner":"urn:li:corpuser:alice"`)
+
+	// The owner annotation takes precedence over the submitter.
+	app.Annotations[config.OwnerAnnotation] = "urn:li:corpGroup:data-eng"
+	if err := NewClient(server.URL, "prod", "secret").Push(app); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, proposals["ownership"].Aspect.Value, `"owner":"urn:li:corpGroup:data-eng"`)
+}
+
+func TestPush_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"}}
+	assert.NotNil(t, NewClient(server.URL, "prod", "").Push(app))
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// The feature gates of the operator.
+const (
+	// ExternalDrivers runs the executors of SparkApplications whose driver runs outside of the cluster.
+	ExternalDrivers Feature = "ExternalDrivers"
+	// OutputCleanup deletes the output of failed SparkApplications that set outputCleanup.
+	OutputCleanup Feature = "OutputCleanup"
+	// ApplicationRotation periodically restarts long-running SparkApplications that set a rotation interval.
+	ApplicationRotation Feature = "ApplicationRotation"
+	// OperatorConfiguration overrides command-line flags with a SparkOperatorConfiguration.
+	OperatorConfiguration Feature = "OperatorConfiguration"
+	// Preemption lets queued SparkApplications preempt running lower-priority SparkApplications.
+	Preemption Feature = "Preemption"
+	// DriverLogCapture saves the end of the driver log of failed SparkApplications that set driverLogCapture.
+	DriverLogCapture Feature = "DriverLogCapture"
+	// SourceReferences loads the spec of SparkApplications that set sourceRef from a Git or OCI artifact.
+	SourceReferences Feature = "SourceReferences"
+	// ExecutorIdleTimeout deletes idle executors of SparkApplications that set executorIdleTimeout.
+	ExecutorIdleTimeout Feature = "ExecutorIdleTimeout"
+	// RunHistory records the runs of SparkApplications that set runHistory as SparkApplicationRuns.
+	RunHistory Feature = "RunHistory"
+	// ConfigChangeDetection reports SparkApplications whose ConfigMaps or Secrets change while they run as
+	// Stale, and restarts the ones that set restartOnConfigChange.
+	ConfigChangeDetection Feature = "ConfigChangeDetection"
+	// DumpUpload uploads the heap dumps and flight recordings of runs of SparkApplications that set
+	// debug.uploadPath in which the driver or an executor ran out of memory.
+	DumpUpload Feature = "DumpUpload"
+	// DataCache runs the node-local cache daemons and warmup Jobs of SparkApplications that set dataCache.
+	DataCache Feature = "DataCache"
+	// HadoopClusters generates the Hadoop configuration files of SparkApplications that set hadoopClusterRef.
+	HadoopClusters Feature = "HadoopClusters"
+	// AutoTuning sizes the shuffle partitions and executors of SparkApplications that set autoTuning from the
+	// size of their input.
+	AutoTuning Feature = "AutoTuning"
+)
+
+// Stage is the maturity of a feature.
+type Stage string
+
+// Different stages of features. Alpha features are disabled by default, beta features are enabled by default
+// and can be disabled, and GA features are always enabled.
+const (
+	Alpha Stage = "ALPHA"
+	Beta  Stage = "BETA"
+	GA    Stage = "GA"
+)
+
+// FeatureSpec describes a feature gate.
+type FeatureSpec struct {
+	Default bool
+	Stage   Stage
+}
+
+var defaultFeatures = map[Feature]FeatureSpec{
+	ExternalDrivers:       {Default: true, Stage: Beta},
+	OutputCleanup:         {Default: true, Stage: Beta},
+	ApplicationRotation:   {Default: true, Stage: Beta},
+	OperatorConfiguration: {Default: false, Stage: Alpha},
+	Preemption:            {Default: false, Stage: Alpha},
+	DriverLogCapture:      {Default: false, Stage: Alpha},
+	SourceReferences:      {Default: false, Stage: Alpha},
+	ExecutorIdleTimeout:   {Default: false, Stage: Alpha},
+	RunHistory:            {Default: false, Stage: Alpha},
+	ConfigChangeDetection: {Default: false, Stage: Alpha},
+	DumpUpload:            {Default: false, Stage: Alpha},
+	DataCache:             {Default: false, Stage: Alpha},
+	HadoopClusters:        {Default: false, Stage: Alpha},
+	AutoTuning:            {Default: false, Stage: Alpha},
+}
+
+// Gate tells which features are enabled. A Gate is a flag.Value parsing a comma-separated list of
+// "<feature>=<bool>" pairs, and is not meant to be changed once the flags are parsed.
+type Gate struct {
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+// DefaultGate is the Gate of the operator, which is set by the -feature-gates flag.
+var DefaultGate = NewGate()
+
+// Enabled tells if the given feature is enabled by DefaultGate.
+func Enabled(feature Feature) bool {
+	return DefaultGate.Enabled(feature)
+}
+
+// NewGate creates a new Gate with all features in their default state.
+func NewGate() *Gate {
+	return &Gate{
+		known:   defaultFeatures,
+		enabled: make(map[Feature]bool),
+	}
+}
+
+// Set enables or disables the features in the given comma-separated list of "<feature>=<bool>" pairs. It
+// fails on unknown features and on disabling GA features.
+func (g *Gate) Set(value string) error {
+	enabled := make(map[Feature]bool)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid feature gate %q, expected <feature>=<bool>", pair)
+		}
+		feature := Feature(strings.TrimSpace(parts[0]))
+		spec, ok := g.known[feature]
+		if !ok {
+			return fmt.Errorf("unknown feature gate %q, known feature gates are %s", feature,
+				strings.Join(g.knownNames(), ", "))
+		}
+		on, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %s: %v", feature, err)
+		}
+		if spec.Stage == GA && !on {
+			return fmt.Errorf("feature gate %s is GA and cannot be disabled", feature)
+		}
+		enabled[feature] = on
+	}
+	for feature, on := range enabled {
+		g.enabled[feature] = on
+	}
+	return nil
+}
+
+// String returns the features that have been set explicitly.
+func (g *Gate) String() string {
+	var pairs []string
+	for feature, on := range g.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", feature, on))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Enabled tells if the given feature is enabled.
+func (g *Gate) Enabled(feature Feature) bool {
+	if on, ok := g.enabled[feature]; ok {
+		return on
+	}
+	return g.known[feature].Default
+}
+
+// KnownFeatures returns a description of each known feature gate, i.e., its stage and default.
+func (g *Gate) KnownFeatures() []string {
+	var descriptions []string
+	for _, name := range g.knownNames() {
+		spec := g.known[Feature(name)]
+		descriptions = append(descriptions, fmt.Sprintf("%s=true|false (%s - default=%t)", name, spec.Stage, spec.Default))
+	}
+	return descriptions
+}
+
+func (g *Gate) knownNames() []string {
+	var names []string
+	for feature := range g.known {
+		names = append(names, string(feature))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterMetrics exports the state of each known feature gate as a gauge that is 1 if the feature is
+// enabled and 0 otherwise.
+func (g *Gate) RegisterMetrics(prefix string) {
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: util.CreateValidMetricNameLabel(prefix, "feature_enabled"),
+			Help: "Whether a Feature Gate of the Operator is Enabled",
+		},
+		[]string{"name", "stage"},
+	)
+	for feature, spec := range g.known {
+		value := 0.0
+		if g.Enabled(feature) {
+			value = 1
+		}
+		gauge.WithLabelValues(string(feature), string(spec.Stage)).Set(value)
+	}
+	util.RegisterMetric(gauge)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	gate := NewGate()
+	assert.True(t, gate.Enabled(ExternalDrivers))
+	assert.False(t, gate.Enabled(OperatorConfiguration))
+
+	assert.Nil(t, gate.Set("OperatorConfiguration=true, ExternalDrivers=false"))
+	assert.True(t, gate.Enabled(OperatorConfiguration))
+	assert.False(t, gate.Enabled(ExternalDrivers))
+	assert.True(t, gate.Enabled(OutputCleanup))
+	assert.Equal(t, "ExternalDrivers=false,OperatorConfiguration=true", gate.String())
+
+	// Invalid lists leave the gate unchanged.
+	assert.NotNil(t, gate.Set("OutputCleanup=false,Volcano=true"))
+	assert.NotNil(t, gate.Set("OutputCleanup"))
+	assert.NotNil(t, gate.Set("OutputCleanup=maybe"))
+	assert.True(t, gate.Enabled(OutputCleanup))
+}
+
+func TestSet_GA(t *testing.T) {
+	gate := &Gate{
+		known:   map[Feature]FeatureSpec{"Stable": {Default: true, Stage: GA}},
+		enabled: make(map[Feature]bool),
+	}
+	assert.NotNil(t, gate.Set("Stable=false"))
+	assert.Nil(t, gate.Set("Stable=true"))
+	assert.True(t, gate.Enabled("Stable"))
+}
+
+func TestKnownFeatures(t *testing.T) {
+	assert.Contains(t, NewGate().KnownFeatures(), "OperatorConfiguration=true|false (ALPHA - default=false)")
+}
@@ -0,0 +1,29 @@
+// +build boringcrypto
+
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	// fipsonly restricts all TLS configurations to FIPS-approved settings. It only exists in the BoringCrypto
+	// toolchain, which is why this file needs the boringcrypto build tag.
+	_ "crypto/tls/fipsonly"
+)
+
+func init() {
+	boringCrypto = true
+}
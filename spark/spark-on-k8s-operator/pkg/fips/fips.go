@@ -0,0 +1,113 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fips implements the FIPS mode of the operator, which restricts the TLS servers of the operator and
+// the cryptography SparkApplications may configure to FIPS-approved algorithms.
+package fips
+
+import (
+	"crypto/tls"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// boringCrypto tells if the operator is built with the BoringCrypto toolchain and the boringcrypto build
+	// tag, which makes all cryptography of the Go standard library use the FIPS-validated BoringCrypto module.
+	boringCrypto = false
+	enabled      = false
+)
+
+// cipherSuites are the FIPS-approved TLS 1.2 cipher suites the TLS servers of the operator accept.
+var cipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// allowedSparkCipherSuites are the cipher suites SparkApplications may enable for the SSL of Spark, by their
+// JSSE names.
+var allowedSparkCipherSuites = map[string]bool{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": true,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": true,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   true,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   true,
+	"TLS_AES_128_GCM_SHA256":                  true,
+	"TLS_AES_256_GCM_SHA384":                  true,
+}
+
+var (
+	sparkSSLProtocolKey   = regexp.MustCompile(`^spark\.ssl(\.[^.]+)?\.protocol$`)
+	sparkSSLAlgorithmsKey = regexp.MustCompile(`^spark\.ssl(\.[^.]+)?\.enabledAlgorithms$`)
+)
+
+// Enable turns on the FIPS mode. It fails unless the operator is built with BoringCrypto, as the cryptography
+// of the standard library is not FIPS-validated otherwise. It must be called before any TLS server is set up.
+func Enable() error {
+	if !boringCrypto {
+		return fmt.Errorf("FIPS mode requires an operator built with BoringCrypto and the boringcrypto build tag")
+	}
+	enabled = true
+	return nil
+}
+
+// Enabled tells if the FIPS mode is on.
+func Enabled() bool {
+	return enabled
+}
+
+// RestrictTLSConfig restricts the given TLS server configuration to TLS 1.2 or later and FIPS-approved cipher
+// suites and curves.
+func RestrictTLSConfig(config *tls.Config) {
+	config.MinVersion = tls.VersionTLS12
+	config.CipherSuites = cipherSuites
+	config.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+	config.PreferServerCipherSuites = true
+}
+
+// ValidateSparkConf returns an error listing the properties of the given Spark configuration that request
+// cryptography that is not FIPS-approved.
+func ValidateSparkConf(conf map[string]string) error {
+	var forbidden []string
+	for key, value := range conf {
+		switch {
+		case sparkSSLProtocolKey.MatchString(key):
+			if value != "TLSv1.2" && value != "TLSv1.3" {
+				forbidden = append(forbidden, fmt.Sprintf("%s=%s", key, value))
+			}
+		case sparkSSLAlgorithmsKey.MatchString(key):
+			for _, algorithm := range strings.Split(value, ",") {
+				if algorithm = strings.TrimSpace(algorithm); algorithm != "" && !allowedSparkCipherSuites[algorithm] {
+					forbidden = append(forbidden, fmt.Sprintf("%s=%s", key, algorithm))
+				}
+			}
+		case key == "spark.authenticate.enableSaslEncryption" || key == "spark.network.sasl.serverAlwaysEncrypt":
+			// SASL encryption uses DIGEST-MD5, which is not FIPS-approved. spark.network.crypto.enabled is the
+			// approved alternative.
+			if strings.EqualFold(value, "true") {
+				forbidden = append(forbidden, fmt.Sprintf("%s=%s", key, value))
+			}
+		}
+	}
+	if len(forbidden) > 0 {
+		sort.Strings(forbidden)
+		return fmt.Errorf("FIPS mode forbids %s", strings.Join(forbidden, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnable(t *testing.T) {
+	// FIPS mode is only available in BoringCrypto builds.
+	assert.Equal(t, boringCrypto, Enable() == nil)
+	assert.Equal(t, boringCrypto, Enabled())
+}
+
+func TestRestrictTLSConfig(t *testing.T) {
+	config := &tls.Config{}
+	RestrictTLSConfig(config)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, cipherSuites, config.CipherSuites)
+	assert.True(t, config.PreferServerCipherSuites)
+}
+
+func TestValidateSparkConf(t *testing.T) {
+	assert.Nil(t, ValidateSparkConf(map[string]string{
+		"spark.ssl.protocol":              "TLSv1.2",
+		"spark.ssl.ui.protocol":           "TLSv1.3",
+		"spark.ssl.enabledAlgorithms":     "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_AES_256_GCM_SHA384",
+		"spark.network.crypto.enabled":    "true",
+		"spark.authenticate":              "true",
+		"spark.executor.extraJavaOptions": "-Dprotocol=TLSv1",
+	}))
+
+	err := ValidateSparkConf(map[string]string{
+		"spark.ssl.protocol":                      "TLSv1.1",
+		"spark.ssl.ui.enabledAlgorithms":          "TLS_RSA_WITH_3DES_EDE_CBC_SHA,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+		"spark.authenticate.enableSaslEncryption": "true",
+	})
+	assert.EqualError(t, err, "FIPS mode forbids spark.authenticate.enableSaslEncryption=true, "+
+		"spark.ssl.protocol=TLSv1.1, spark.ssl.ui.enabledAlgorithms=TLS_RSA_WITH_3DES_EDE_CBC_SHA")
+}
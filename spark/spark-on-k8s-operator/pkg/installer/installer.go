@@ -0,0 +1,381 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/ghodss/yaml"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd"
+	hccrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/hadoopcluster"
+	ijcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/ingestjob"
+	ssacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/scheduledsparkapplication"
+	sacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplication"
+	sarcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplicationrun"
+	socrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkoperatorconfiguration"
+	stscrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkthriftserver"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/webhook"
+)
+
+// Names of the objects of an installation, which match the ones in the manifests under manifest/.
+const (
+	// DefaultImage is the image of the operator installed unless another one is given.
+	DefaultImage          = "gcr.io/spark-operator/spark-operator:v2.4.0-v1beta1-latest"
+	operatorName          = "sparkoperator"
+	webhookCertSecretName = "spark-webhook-certs"
+	webhookCertDir        = "/etc/webhook-certs"
+	nameLabel             = "app.kubernetes.io/name"
+)
+
+var createOrUpdateCRD = crd.CreateOrUpdateCRD
+
+// Config describes an installation of the operator.
+type Config struct {
+	// Namespace is the namespace the operator runs in.
+	Namespace string
+	// Image is the image of the operator.
+	Image string
+	// EnableWebhook tells if the mutating admission webhook is installed.
+	EnableWebhook bool
+	// WebhookServiceName, WebhookPort and WebhookConfigName configure the webhook like the flags of the operator
+	// with the same names.
+	WebhookServiceName string
+	WebhookPort        int
+	WebhookConfigName  string
+	// Args are additional command-line arguments of the operator.
+	Args []string
+}
+
+// Installer creates or updates the CustomResourceDefinitions, RBAC, webhook, and Deployment of the operator,
+// so that it is installed without Helm or manifests.
+type Installer struct {
+	config              Config
+	kubeClient          kubernetes.Interface
+	apiExtensionsClient apiextensionsclient.Interface
+	out                 io.Writer
+}
+
+// New creates a new Installer with the given configuration, which reports the objects it applies to out.
+func New(
+	config Config,
+	kubeClient kubernetes.Interface,
+	apiExtensionsClient apiextensionsclient.Interface,
+	out io.Writer) *Installer {
+	return &Installer{
+		config:              config,
+		kubeClient:          kubeClient,
+		apiExtensionsClient: apiExtensionsClient,
+		out:                 out,
+	}
+}
+
+// Install creates the objects of the installation that do not exist yet and updates the ones that do, so that
+// it can be run repeatedly. Unless it is an upgrade, the installation does not need to exist. The webhook
+// certificates are only generated if their secret does not exist yet.
+func (i *Installer) Install(upgrade bool) error {
+	if upgrade {
+		_, err := i.kubeClient.AppsV1().Deployments(i.config.Namespace).Get(operatorName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("the operator is not installed in namespace %s", i.config.Namespace)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	var certs map[string][]byte
+	if i.config.EnableWebhook {
+		secret, err := i.kubeClient.CoreV1().Secrets(i.config.Namespace).Get(webhookCertSecretName,
+			metav1.GetOptions{})
+		if err == nil {
+			certs = secret.Data
+		} else if errors.IsNotFound(err) {
+			if certs, err = webhook.GenerateCerts(i.config.Namespace, i.config.WebhookServiceName); err != nil {
+				return err
+			}
+		} else {
+			return err
+		}
+	}
+
+	for _, obj := range i.Objects(certs) {
+		if err := i.apply(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DryRun writes the objects the installation consists of as a multi-document YAML to out instead of applying
+// them. The webhook certificates written are newly generated.
+func (i *Installer) DryRun() error {
+	var certs map[string][]byte
+	if i.config.EnableWebhook {
+		var err error
+		if certs, err = webhook.GenerateCerts(i.config.Namespace, i.config.WebhookServiceName); err != nil {
+			return err
+		}
+	}
+	for _, obj := range i.Objects(certs) {
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(i.out, "---\n%s", content)
+	}
+	return nil
+}
+
+// Objects returns the objects of the installation in the order they are applied, with the given webhook
+// certificates.
+func (i *Installer) Objects(certs map[string][]byte) []runtime.Object {
+	namespace := i.config.Namespace
+	objects := []runtime.Object{
+		&apiv1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		},
+		&apiv1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: operatorName, Namespace: namespace},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: operatorName},
+			Rules:      clusterRoleRules,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: operatorName},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: operatorName, Namespace: namespace},
+			},
+			RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: operatorName},
+		},
+	}
+	for _, definition := range []*apiextensionsv1beta1.CustomResourceDefinition{
+		sacrd.GetCRD(), ssacrd.GetCRD(), ijcrd.GetCRD(), stscrd.GetCRD(), sarcrd.GetCRD(), socrd.GetCRD(),
+		hccrd.GetCRD(),
+	} {
+		definition.TypeMeta = metav1.TypeMeta{
+			APIVersion: apiextensionsv1beta1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		}
+		objects = append(objects, definition)
+	}
+
+	if i.config.EnableWebhook {
+		objects = append(objects,
+			&apiv1.Secret{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+				ObjectMeta: metav1.ObjectMeta{Name: webhookCertSecretName, Namespace: namespace},
+				Data:       certs,
+			},
+			&apiv1.Service{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+				ObjectMeta: metav1.ObjectMeta{Name: i.config.WebhookServiceName, Namespace: namespace},
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{Name: "webhook", Port: 443, TargetPort: intstr.FromInt(i.config.WebhookPort)},
+					},
+					Selector: map[string]string{nameLabel: operatorName},
+				},
+			},
+			webhook.NewWebhookConfiguration(i.config.WebhookConfigName, namespace, i.config.WebhookServiceName,
+				webhook.GetCACert(certs)))
+	}
+	return append(objects, i.buildDeployment())
+}
+
+func (i *Installer) buildDeployment() *appsv1.Deployment {
+	labels := map[string]string{nameLabel: operatorName}
+	replicas := int32(1)
+	args := []string{"-logtostderr", "-install-crds=false"}
+	container := apiv1.Container{
+		Name:            operatorName,
+		Image:           i.config.Image,
+		ImagePullPolicy: apiv1.PullIfNotPresent,
+	}
+	podSpec := apiv1.PodSpec{ServiceAccountName: operatorName}
+	if i.config.EnableWebhook {
+		args = append(args,
+			"-enable-webhook=true",
+			"-webhook-svc-namespace="+i.config.Namespace,
+			"-webhook-svc-name="+i.config.WebhookServiceName,
+			fmt.Sprintf("-webhook-port=%d", i.config.WebhookPort),
+			"-webhook-config-name="+i.config.WebhookConfigName,
+			"-webhook-cert-dir="+webhookCertDir)
+		container.Ports = []apiv1.ContainerPort{{ContainerPort: int32(i.config.WebhookPort)}}
+		container.VolumeMounts = []apiv1.VolumeMount{{Name: "webhook-certs", MountPath: webhookCertDir}}
+		podSpec.Volumes = []apiv1.Volume{{
+			Name: "webhook-certs",
+			VolumeSource: apiv1.VolumeSource{
+				Secret: &apiv1.SecretVolumeSource{SecretName: webhookCertSecretName},
+			},
+		}}
+	}
+	container.Args = append(args, i.config.Args...)
+	podSpec.Containers = []apiv1.Container{container}
+
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: operatorName, Namespace: i.config.Namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			// Only one operator may run at a time.
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+// apply creates the given object if it does not exist, or updates the parts of it the installation manages
+// otherwise. Namespaces, ServiceAccounts and Secrets are only created.
+func (i *Installer) apply(obj runtime.Object) error {
+	var name string
+	var result string
+	var err error
+	switch o := obj.(type) {
+	case *apiv1.Namespace:
+		name = "namespace/" + o.Name
+		_, err = i.kubeClient.CoreV1().Namespaces().Create(o)
+		result, err = createResult(err)
+	case *apiv1.ServiceAccount:
+		name = "serviceaccount/" + o.Name
+		_, err = i.kubeClient.CoreV1().ServiceAccounts(o.Namespace).Create(o)
+		result, err = createResult(err)
+	case *apiv1.Secret:
+		name = "secret/" + o.Name
+		_, err = i.kubeClient.CoreV1().Secrets(o.Namespace).Create(o)
+		result, err = createResult(err)
+	case *rbacv1.ClusterRole:
+		name = "clusterrole/" + o.Name
+		client := i.kubeClient.RbacV1().ClusterRoles()
+		existing, getErr := client.Get(o.Name, metav1.GetOptions{})
+		result, err = update(getErr, func() error { _, err := client.Create(o); return err },
+			func() (bool, error) {
+				if reflect.DeepEqual(existing.Rules, o.Rules) {
+					return false, nil
+				}
+				existing.Rules = o.Rules
+				_, err := client.Update(existing)
+				return true, err
+			})
+	case *rbacv1.ClusterRoleBinding:
+		name = "clusterrolebinding/" + o.Name
+		client := i.kubeClient.RbacV1().ClusterRoleBindings()
+		existing, getErr := client.Get(o.Name, metav1.GetOptions{})
+		result, err = update(getErr, func() error { _, err := client.Create(o); return err },
+			func() (bool, error) {
+				if reflect.DeepEqual(existing.Subjects, o.Subjects) {
+					return false, nil
+				}
+				existing.Subjects = o.Subjects
+				_, err := client.Update(existing)
+				return true, err
+			})
+	case *apiextensionsv1beta1.CustomResourceDefinition:
+		name = "customresourcedefinition/" + o.Name
+		result, err = "applied", createOrUpdateCRD(i.apiExtensionsClient, o)
+	case *apiv1.Service:
+		name = "service/" + o.Name
+		client := i.kubeClient.CoreV1().Services(o.Namespace)
+		existing, getErr := client.Get(o.Name, metav1.GetOptions{})
+		result, err = update(getErr, func() error { _, err := client.Create(o); return err },
+			func() (bool, error) {
+				if reflect.DeepEqual(existing.Spec.Ports, o.Spec.Ports) &&
+					reflect.DeepEqual(existing.Spec.Selector, o.Spec.Selector) {
+					return false, nil
+				}
+				// Keep the cluster IP and other fields set by the API server.
+				existing.Spec.Ports = o.Spec.Ports
+				existing.Spec.Selector = o.Spec.Selector
+				_, err := client.Update(existing)
+				return true, err
+			})
+	case *admissionv1beta1.MutatingWebhookConfiguration:
+		name = "mutatingwebhookconfiguration/" + o.Name
+		client := i.kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+		existing, getErr := client.Get(o.Name, metav1.GetOptions{})
+		result, err = update(getErr, func() error { _, err := client.Create(o); return err },
+			func() (bool, error) {
+				if reflect.DeepEqual(existing.Webhooks, o.Webhooks) {
+					return false, nil
+				}
+				existing.Webhooks = o.Webhooks
+				_, err := client.Update(existing)
+				return true, err
+			})
+	case *appsv1.Deployment:
+		name = "deployment/" + o.Name
+		client := i.kubeClient.AppsV1().Deployments(o.Namespace)
+		existing, getErr := client.Get(o.Name, metav1.GetOptions{})
+		result, err = update(getErr, func() error { _, err := client.Create(o); return err },
+			func() (bool, error) {
+				existing.Labels = o.Labels
+				existing.Spec = o.Spec
+				_, err := client.Update(existing)
+				return true, err
+			})
+	default:
+		return fmt.Errorf("unsupported object %T", obj)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %v", name, err)
+	}
+	fmt.Fprintf(i.out, "%s %s\n", name, result)
+	return nil
+}
+
+func createResult(err error) (string, error) {
+	if errors.IsAlreadyExists(err) {
+		return "unchanged", nil
+	}
+	return "created", err
+}
+
+// update creates an object if getting it failed because it does not exist, or updates it if it was found.
+func update(getErr error, createFunc func() error, updateFunc func() (bool, error)) (string, error) {
+	if errors.IsNotFound(getErr) {
+		return "created", createFunc()
+	}
+	if getErr != nil {
+		return "", getErr
+	}
+	updated, err := updateFunc()
+	if !updated {
+		return "unchanged", err
+	}
+	return "configured", err
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd"
+)
+
+func newTestConfig() Config {
+	return Config{
+		Namespace:          "spark-operator",
+		Image:              "registry.example.com/spark-operator:v1",
+		EnableWebhook:      true,
+		WebhookServiceName: "spark-webhook",
+		WebhookPort:        8080,
+		WebhookConfigName:  "spark-webhook-config",
+		Args:               []string{"-enable-metrics=true"},
+	}
+}
+
+func TestInstall(t *testing.T) {
+	var crds []string
+	createOrUpdateCRD = func(
+		clientset apiextensionsclient.Interface,
+		definition *apiextensionsv1beta1.CustomResourceDefinition) error {
+		crds = append(crds, definition.Name)
+		return nil
+	}
+	defer func() { createOrUpdateCRD = crd.CreateOrUpdateCRD }()
+
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	var out bytes.Buffer
+	installer := New(newTestConfig(), kubeClient, apiextensionsfake.NewSimpleClientset(), &out)
+
+	// Nothing to upgrade yet.
+	assert.NotNil(t, installer.Install(true))
+
+	assert.Nil(t, installer.Install(false))
+	assert.Equal(t, 7, len(crds))
+	assert.True(t, strings.Contains(out.String(), "deployment/sparkoperator created\n"))
+
+	deployment, err := kubeClient.AppsV1().Deployments("spark-operator").Get(operatorName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "registry.example.com/spark-operator:v1", container.Image)
+	assert.Contains(t, container.Args, "-enable-webhook=true")
+	assert.Contains(t, container.Args, "-webhook-svc-namespace=spark-operator")
+	assert.Contains(t, container.Args, "-enable-metrics=true")
+	assert.Equal(t, webhookCertSecretName, deployment.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+
+	secret, err := kubeClient.CoreV1().Secrets("spark-operator").Get(webhookCertSecretName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	webhookConfig, err := kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(
+		"spark-webhook-config", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, secret.Data["ca-cert.pem"], webhookConfig.Webhooks[0].ClientConfig.CABundle)
+	role, err := kubeClient.RbacV1().ClusterRoles().Get(operatorName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, clusterRoleRules, role.Rules)
+
+	// An upgrade keeps the certificates and only changes what changed.
+	out.Reset()
+	config := newTestConfig()
+	config.Image = "registry.example.com/spark-operator:v2"
+	assert.Nil(t, New(config, kubeClient, apiextensionsfake.NewSimpleClientset(), &out).Install(true))
+	assert.True(t, strings.Contains(out.String(), "secret/spark-webhook-certs unchanged\n"))
+	assert.True(t, strings.Contains(out.String(), "clusterrole/sparkoperator unchanged\n"))
+	assert.True(t, strings.Contains(out.String(), "mutatingwebhookconfiguration/spark-webhook-config unchanged\n"))
+	assert.True(t, strings.Contains(out.String(), "deployment/sparkoperator configured\n"))
+	deployment, err = kubeClient.AppsV1().Deployments("spark-operator").Get(operatorName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "registry.example.com/spark-operator:v2", deployment.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestDryRun(t *testing.T) {
+	var out bytes.Buffer
+	config := newTestConfig()
+	config.EnableWebhook = false
+	assert.Nil(t, New(config, nil, nil, &out).DryRun())
+
+	manifests := out.String()
+	assert.Equal(t, 12, strings.Count(manifests, "---\n"))
+	assert.True(t, strings.Contains(manifests, "kind: ClusterRole\n"))
+	assert.True(t, strings.Contains(manifests, "kind: CustomResourceDefinition\n"))
+	assert.True(t, strings.Contains(manifests, "name: sparkapplicationruns.sparkoperator.k8s.io\n"))
+	assert.True(t, strings.Contains(manifests, "name: hadoopclusters.sparkoperator.k8s.io\n"))
+	assert.True(t, strings.Contains(manifests, "image: registry.example.com/spark-operator:v1\n"))
+	assert.False(t, strings.Contains(manifests, "kind: MutatingWebhookConfiguration\n"))
+}
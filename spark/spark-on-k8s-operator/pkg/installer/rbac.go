@@ -0,0 +1,89 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// clusterRoleRules are the rules of the ClusterRole of the operator, which must be kept in sync with
+// manifest/spark-operator-rbac.yaml.
+var clusterRoleRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}},
+	{APIGroups: []string{""}, Resources: []string{"services", "configmaps", "secrets"}, Verbs: []string{"create", "get", "delete"}},
+	// The rules below are needed to keep the scripts of IngestJobs, SparkThriftServers, and Livy sessions, and
+	// the Services and Ingresses of SparkThriftServers, up to date.
+	{APIGroups: []string{""}, Resources: []string{"configmaps", "services"}, Verbs: []string{"update"}},
+	{APIGroups: []string{"extensions"}, Resources: []string{"ingresses"}, Verbs: []string{"update"}},
+	{APIGroups: []string{"extensions"}, Resources: []string{"ingresses"}, Verbs: []string{"create", "get", "delete"}},
+	// Nodes are listed by the webhook to check that nodes with the requireNodeFeatures of SparkApplications exist.
+	{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list"}},
+	{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "update", "patch"}},
+	{APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"}, Verbs: []string{"create", "get", "update", "delete"}},
+	{APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions/status"}, Verbs: []string{"update"}},
+	{APIGroups: []string{"admissionregistration.k8s.io"}, Resources: []string{"mutatingwebhookconfigurations"}, Verbs: []string{"create", "get", "update", "delete"}},
+	{
+		APIGroups: []string{"sparkoperator.k8s.io"},
+		Resources: []string{"sparkapplications", "sparkapplications/status", "scheduledsparkapplications",
+			"scheduledsparkapplications/status", "ingestjobs", "ingestjobs/status", "sparkthriftservers",
+			"sparkthriftservers/status", "sparkapplicationruns", "sparkoperatorconfigurations",
+			"sparkoperatorconfigurations/status", "hadoopclusters"},
+		Verbs: []string{"*"},
+	},
+	// The rules below are only needed with -archive-bucket-url set. The logs of drivers are also read with
+	// -enable-livy=true and the DriverLogCapture feature.
+	{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"list"}},
+	{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+	// The rule below is only needed with -enable-impersonation=true.
+	{APIGroups: []string{""}, Resources: []string{"users", "groups", "serviceaccounts"}, Verbs: []string{"impersonate"}},
+	// The rules below are only needed with -enable-namespace-bootstrap=true. The operator must itself hold
+	// the permissions it grants to the driver service account in the spark-role Role.
+	{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list", "watch"}},
+	{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"*"}},
+	{APIGroups: []string{""}, Resources: []string{"serviceaccounts", "limitranges"}, Verbs: []string{"create", "get"}},
+	{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles", "rolebindings"}, Verbs: []string{"create", "get"}},
+	{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies"}, Verbs: []string{"create", "get"}},
+	// The rules below are only needed with -enable-dashboards=true.
+	{APIGroups: []string{"integreatly.org"}, Resources: []string{"grafanadashboards"}, Verbs: []string{"create", "get"}},
+	{APIGroups: []string{"monitoring.coreos.com"}, Resources: []string{"prometheusrules"}, Verbs: []string{"create", "get"}},
+	// The rules below are only needed for SparkApplications with an external driver. The operator must itself
+	// hold the permissions it grants to the service account of the driver.
+	{APIGroups: []string{""}, Resources: []string{"endpoints"}, Verbs: []string{"create", "get", "update"}},
+	{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"create", "get"}},
+	{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles", "rolebindings"}, Verbs: []string{"create", "get"}},
+	{APIGroups: []string{""}, Resources: []string{"configmaps", "persistentvolumeclaims"}, Verbs: []string{"*"}},
+	// The rules below are only needed for SparkApplications with createServiceAccount or cloudIdentity set, in
+	// addition to the ones for an external driver above.
+	{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"update", "delete"}},
+	{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles", "rolebindings"}, Verbs: []string{"delete"}},
+	// The rules below are only needed with -default-env-configmap set.
+	{APIGroups: []string{""}, Resources: []string{"configmaps", "namespaces"}, Verbs: []string{"list", "watch"}},
+	// The rule below is only needed with the ConfigChangeDetection feature, which also watches ConfigMaps.
+	{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list", "watch"}},
+	// The rule below is only needed for SparkApplications with outputCleanup, debug.uploadPath, or
+	// dataCache.warmupPaths set.
+	{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"create"}},
+	// The rule below is only needed with the DataCache feature.
+	{APIGroups: []string{"apps"}, Resources: []string{"daemonsets"}, Verbs: []string{"create", "get", "update"}},
+	// The rules below are only needed with -enable-ui=true or -enable-livy=true.
+	{APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"tokenreviews"}, Verbs: []string{"create"}},
+	{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"subjectaccessreviews"}, Verbs: []string{"create"}},
+	// The rule below is only needed with -enable-ui=true or -archive-bucket-url set.
+	{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"list"}},
+	// The rule below is only needed with the ExecutorIdleTimeout feature.
+	{APIGroups: []string{"metrics.k8s.io"}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lineage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const (
+	// Producer identifies the operator as the producer of the events it emits.
+	Producer = "https://github.com/GoogleCloudPlatform/spark-on-k8s-operator"
+
+	runEventSchemaURL  = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunEvent"
+	parentFacetSchema  = "https://openlineage.io/spec/facets/1-0-0/ParentRunFacet.json#/$defs/ParentRunFacet"
+	lineageEndpoint    = "/api/v1/lineage"
+	listenerClass      = "io.openlineage.spark.agent.OpenLineageSparkListener"
+	extraListenersKey  = "spark.extraListeners"
+	emitRequestTimeout = 5 * time.Second
+)
+
+// The types of the run events emitted for SparkApplications.
+const (
+	StartEvent    = "START"
+	CompleteEvent = "COMPLETE"
+	FailEvent     = "FAIL"
+)
+
+// RunEvent is an OpenLineage run event.
+type RunEvent struct {
+	EventType string    `json:"eventType"`
+	EventTime time.Time `json:"eventTime"`
+	Producer  string    `json:"producer"`
+	SchemaURL string    `json:"schemaURL"`
+	Run       Run       `json:"run"`
+	Job       Job       `json:"job"`
+	Inputs    []Dataset `json:"inputs"`
+	Outputs   []Dataset `json:"outputs"`
+}
+
+// Run identifies a run of a job.
+type Run struct {
+	RunID  string     `json:"runId"`
+	Facets *RunFacets `json:"facets,omitempty"`
+}
+
+// RunFacets are the facets of a run the operator knows about.
+type RunFacets struct {
+	Parent *ParentRunFacet `json:"parent,omitempty"`
+}
+
+// ParentRunFacet links a run to the run of the job that started it.
+type ParentRunFacet struct {
+	Producer  string `json:"_producer"`
+	SchemaURL string `json:"_schemaURL"`
+	Run       Run    `json:"run"`
+	Job       Job    `json:"job"`
+}
+
+// Job identifies a job.
+type Job struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Dataset identifies a dataset read or written by a job. The operator does not know about the datasets of
+// an application, which the Spark listener reports.
+type Dataset struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Client emits OpenLineage run events of SparkApplications to an OpenLineage-compatible backend, e.g.,
+// Marquez, and configures applications to run the OpenLineage Spark listener, which reports the datasets
+// each Spark job reads and writes as children of the runs of the operator.
+type Client struct {
+	url         string
+	namespace   string
+	listenerJar string
+	client      *http.Client
+}
+
+// NewClient creates a new Client for the backend with the given base URL. Jobs are put into the given
+// OpenLineage namespace, or the Kubernetes namespace of the application if empty. The listener jar is added
+// to the dependencies of applications if set, otherwise it is expected to be in the Spark image.
+func NewClient(url string, namespace string, listenerJar string) *Client {
+	return &Client{
+		url:         strings.TrimSuffix(url, "/"),
+		namespace:   namespace,
+		listenerJar: listenerJar,
+		client:      &http.Client{Timeout: emitRequestTimeout},
+	}
+}
+
+// NewRunID returns a new random run ID, which OpenLineage requires to be a UUID.
+func NewRunID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	// Version 4 and the RFC 4122 variant.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func (c *Client) getJob(app *v1beta1.SparkApplication) Job {
+	namespace := c.namespace
+	if namespace == "" {
+		namespace = app.Namespace
+	}
+	return Job{Namespace: namespace, Name: fmt.Sprintf("%s.%s", app.Namespace, app.Name)}
+}
+
+// getParent returns the ScheduledSparkApplication that created the given application as the parent of its
+// runs, using the UID of the ScheduledSparkApplication as the ID of the parent run.
+func (c *Client) getParent(app *v1beta1.SparkApplication) *ParentRunFacet {
+	for _, ref := range app.OwnerReferences {
+		if ref.Kind != "ScheduledSparkApplication" {
+			continue
+		}
+		job := c.getJob(app)
+		job.Name = fmt.Sprintf("%s.%s", app.Namespace, ref.Name)
+		return &ParentRunFacet{
+			Producer:  Producer,
+			SchemaURL: parentFacetSchema,
+			Run:       Run{RunID: string(ref.UID)},
+			Job:       job,
+		}
+	}
+	return nil
+}
+
+// ConfigureListener adds the Spark configuration running the OpenLineage listener to the given application,
+// which reports the Spark jobs of the application as children of the run with the given ID.
+func (c *Client) ConfigureListener(app *v1beta1.SparkApplication, runID string) {
+	if app.Spec.SparkConf == nil {
+		app.Spec.SparkConf = make(map[string]string)
+	}
+	if listeners := app.Spec.SparkConf[extraListenersKey]; listeners != "" {
+		app.Spec.SparkConf[extraListenersKey] = listeners + "," + listenerClass
+	} else {
+		app.Spec.SparkConf[extraListenersKey] = listenerClass
+	}
+	if c.listenerJar != "" {
+		app.Spec.Deps.Jars = append(app.Spec.Deps.Jars, c.listenerJar)
+	}
+
+	job := c.getJob(app)
+	app.Spec.SparkConf["spark.openlineage.transport.type"] = "http"
+	app.Spec.SparkConf["spark.openlineage.transport.url"] = c.url
+	app.Spec.SparkConf["spark.openlineage.namespace"] = job.Namespace
+	app.Spec.SparkConf["spark.openlineage.parentJobNamespace"] = job.Namespace
+	app.Spec.SparkConf["spark.openlineage.parentJobName"] = job.Name
+	app.Spec.SparkConf["spark.openlineage.parentRunId"] = runID
+}
+
+// Emit emits a run event of the given type for the run with the given ID of the given application.
+func (c *Client) Emit(app *v1beta1.SparkApplication, eventType string, runID string) error {
+	event := &RunEvent{
+		EventType: eventType,
+		EventTime: time.Now().UTC(),
+		Producer:  Producer,
+		SchemaURL: runEventSchemaURL,
+		Run:       Run{RunID: runID},
+		Job:       c.getJob(app),
+		Inputs:    []Dataset{},
+		Outputs:   []Dataset{},
+	}
+	if parent := c.getParent(app); parent != nil {
+		event.Run.Facets = &RunFacets{Parent: parent}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.url+lineageEndpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to emit %s event of SparkApplication %s/%s: %v", eventType, app.Namespace, app.Name, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to emit %s event of SparkApplication %s/%s: got status %s",
+			eventType, app.Namespace, app.Name, resp.Status)
+	}
+	return nil
+}
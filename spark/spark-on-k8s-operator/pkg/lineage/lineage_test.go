@@ -0,0 +1,99 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lineage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestNewRunID(t *testing.T) {
+	runID, err := NewRunID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), runID)
+}
+
+func TestConfigureListener(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "team-a"},
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConf: map[string]string{"spark.extraListeners": "com.example.Listener"},
+		},
+	}
+	NewClient("http://marquez:5000/", "", "local:///opt/openlineage-spark.jar").ConfigureListener(app, "run-1")
+	assert.Equal(t, "com.example.Listener,"+listenerClass, app.Spec.SparkConf["spark.extraListeners"])
+	assert.Equal(t, []string{"local:///opt/openlineage-spark.jar"}, app.Spec.Deps.Jars)
+	assert.Equal(t, "http://marquez:5000", app.Spec.SparkConf["spark.openlineage.transport.url"])
+	assert.Equal(t, "team-a", app.Spec.SparkConf["spark.openlineage.namespace"])
+	assert.Equal(t, "team-a.foo", app.Spec.SparkConf["spark.openlineage.parentJobName"])
+	assert.Equal(t, "run-1", app.Spec.SparkConf["spark.openlineage.parentRunId"])
+
+	app.Spec.SparkConf = nil
+	app.Spec.Deps.Jars = nil
+	NewClient("http://marquez:5000", "spark", "").ConfigureListener(app, "run-1")
+	assert.Equal(t, listenerClass, app.Spec.SparkConf["spark.extraListeners"])
+	assert.Nil(t, app.Spec.Deps.Jars)
+	assert.Equal(t, "spark", app.Spec.SparkConf["spark.openlineage.parentJobNamespace"])
+}
+
+func TestEmit(t *testing.T) {
+	var events []RunEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, lineageEndpoint, r.URL.Path)
+		var event RunEvent
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nightly-1",
+			Namespace: "team-a",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ScheduledSparkApplication", Name: "nightly", UID: "parent-uid"},
+			},
+		},
+	}
+	client := NewClient(server.URL, "", "")
+	assert.Nil(t, client.Emit(app, StartEvent, "run-1"))
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, StartEvent, events[0].EventType)
+	assert.Equal(t, Run{RunID: "parent-uid"}, events[0].Run.Facets.Parent.Run)
+	assert.Equal(t, "run-1", events[0].Run.RunID)
+	assert.Equal(t, Job{Namespace: "team-a", Name: "team-a.nightly-1"}, events[0].Job)
+	assert.Equal(t, Job{Namespace: "team-a", Name: "team-a.nightly"}, events[0].Run.Facets.Parent.Job)
+
+	app.OwnerReferences = nil
+	assert.Nil(t, client.Emit(app, CompleteEvent, "run-1"))
+	assert.Nil(t, events[1].Run.Facets)
+
+	unavailable := httptest.NewServer(http.NotFoundHandler())
+	defer unavailable.Close()
+	assert.NotNil(t, NewClient(unavailable.URL, "", "").Emit(app, FailEvent, "run-1"))
+}
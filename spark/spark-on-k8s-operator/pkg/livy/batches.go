@@ -0,0 +1,215 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livy
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const batchNamePrefix = "livy-batch"
+
+// batchRequest is the body of a request creating a batch.
+type batchRequest struct {
+	sparkRequest
+	File      string   `json:"file"`
+	ClassName string   `json:"className,omitempty"`
+	Args      []string `json:"args,omitempty"`
+}
+
+// batch is a batch as returned by Livy.
+type batch struct {
+	ID      int      `json:"id"`
+	Name    *string  `json:"name"`
+	AppID   *string  `json:"appId"`
+	AppInfo appInfo  `json:"appInfo"`
+	Log     []string `json:"log"`
+	State   string   `json:"state"`
+}
+
+// getBatchState returns the Livy state of the batch run by the given application.
+func getBatchState(app *v1beta1.SparkApplication) string {
+	switch app.Status.AppState.State {
+	case v1beta1.NewState, v1beta1.QueuedState:
+		return "not_started"
+	case v1beta1.SubmittedState, v1beta1.PendingRerunState, v1beta1.InvalidatingState:
+		return "starting"
+	case v1beta1.CompletedState, v1beta1.SucceedingState:
+		return "success"
+	case v1beta1.FailedState, v1beta1.FailingState, v1beta1.FailedSubmissionState:
+		return "dead"
+	}
+	return "running"
+}
+
+func toBatch(app *v1beta1.SparkApplication) batch {
+	b := batch{
+		ID:      getID(app, config.LivyBatchIDLabel),
+		AppID:   getAppID(app),
+		AppInfo: getAppInfo(app),
+		Log:     getLog(app),
+		State:   getBatchState(app),
+	}
+	if name, ok := app.Annotations[config.LivyNameAnnotation]; ok {
+		b.Name = &name
+	}
+	return b
+}
+
+// buildBatchApplication returns the SparkApplication running the batch in the given request.
+func (s *Server) buildBatchApplication(req *batchRequest) (*v1beta1.SparkApplication, error) {
+	if req.File == "" {
+		return nil, fmt.Errorf("file is required")
+	}
+	app, err := s.buildApplication(&req.sparkRequest)
+	if err != nil {
+		return nil, err
+	}
+	file := req.File
+	app.Spec.MainApplicationFile = &file
+	app.Spec.Arguments = req.Args
+	switch {
+	case strings.HasSuffix(file, ".py"):
+		app.Spec.Type = v1beta1.PythonApplicationType
+	case strings.HasSuffix(file, ".R"):
+		app.Spec.Type = v1beta1.RApplicationType
+	default:
+		if req.ClassName == "" {
+			return nil, fmt.Errorf("className is required for file %s", file)
+		}
+		app.Spec.Type = v1beta1.ScalaApplicationType
+		className := req.ClassName
+		app.Spec.MainClass = &className
+	}
+	return app, nil
+}
+
+// serveBatches serves the batch API at /batches, /batches/<id>, /batches/<id>/state, and /batches/<id>/log.
+func (s *Server) serveBatches(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r, batchesPath)
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listBatches(w, r)
+		case http.MethodPost:
+			s.createBatch(w, r)
+		default:
+			notAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+	if len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	app, err := s.getApplication(config.LivyBatchIDLabel, batchNamePrefix, parts[0])
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	if app == nil {
+		http.Error(w, fmt.Sprintf("batch %s not found", parts[0]), http.StatusNotFound)
+		return
+	}
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, toBatch(app))
+		case http.MethodDelete:
+			glog.Infof("Deleting SparkApplication %s/%s of Livy batch %s", app.Namespace, app.Name, parts[0])
+			s.deleteApplication(w, app)
+		default:
+			notAllowed(w, http.MethodGet, http.MethodDelete)
+		}
+		return
+	}
+	if r.Method != http.MethodGet {
+		notAllowed(w, http.MethodGet)
+		return
+	}
+	switch parts[1] {
+	case "state":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": toBatch(app).ID, "state": getBatchState(app)})
+	case "log":
+		s.serveDriverLog(w, r, app, toBatch(app).ID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listBatches(w http.ResponseWriter, r *http.Request) {
+	apps, err := s.listApplications(config.LivyBatchIDLabel)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	from, to := getPage(r, len(apps))
+	batches := []batch{}
+	for _, app := range apps[from:to] {
+		batches = append(batches, toBatch(app))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"from": from, "total": len(apps), "sessions": batches})
+}
+
+func (s *Server) createBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	app, err := s.buildBatchApplication(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := s.createApplication(app, config.LivyBatchIDLabel, batchNamePrefix)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	glog.Infof("Created SparkApplication %s/%s for Livy batch", created.Namespace, created.Name)
+	writeJSON(w, http.StatusCreated, toBatch(created))
+}
+
+// serveDriverLog serves the last lines of the log of the driver of the given application, as many as given in
+// the "size" query parameter. Livy counts lines from the start of the log, which the API server cannot, so
+// "from" is ignored.
+func (s *Server) serveDriverLog(w http.ResponseWriter, r *http.Request, app *v1beta1.SparkApplication, id int) {
+	lines := getLog(app)
+	if podName := app.Status.DriverInfo.PodName; podName != "" {
+		tailLines := int64(100)
+		if size, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64); err == nil && size > 0 {
+			tailLines = size
+		}
+		data, err := s.kubeClient.CoreV1().Pods(app.Namespace).GetLogs(podName,
+			&apiv1.PodLogOptions{TailLines: &tailLines}).DoRaw()
+		if err == nil {
+			lines = append(strings.Split(strings.TrimRight(string(data), "\n"), "\n"), lines...)
+		} else {
+			glog.V(2).Infof("failed to get the log of driver pod %s/%s: %v", app.Namespace, podName, err)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "from": 0, "total": len(lines), "log": lines})
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	batchesPath  = "/batches"
+	sessionsPath = "/sessions"
+
+	// maxCreateAttempts is how many IDs are tried when creating a batch or session, in case other requests
+	// take the same ID concurrently.
+	maxCreateAttempts = 5
+)
+
+// Server serves a subset of the REST API of Apache Livy, so that tools speaking Livy, e.g., Zeppelin or
+// Jupyter with sparkmagic, can run Spark on Kubernetes without change. Batches are run as SparkApplications,
+// and interactive sessions as SparkApplications running a REPL in the driver, to which the server forwards
+// the statements of the session. All batches and sessions live in a single namespace.
+//
+// Clients authenticate with bearer tokens reviewed by the API server with a TokenReview, and every request is
+// authorized with a SubjectAccessReview against the RBAC rules of the cluster on the SparkApplications in the
+// namespace: listing and getting batches and sessions requires list and get, creating them requires create,
+// running statements requires update, and deleting them requires delete.
+type Server struct {
+	crdClient  crdclientset.Interface
+	kubeClient kubernetes.Interface
+	lister     crdlisters.SparkApplicationLister
+	config     Config
+	server     *http.Server
+	client     *http.Client
+	// getREPLURL returns the base URL of the REPL in the driver of the given session.
+	getREPLURL func(app *v1beta1.SparkApplication) (string, error)
+}
+
+// Config is the configuration of the Livy-compatible server.
+type Config struct {
+	// Address is the address the server listens on, e.g., 127.0.0.1 to only be reachable through a proxy in the
+	// same pod. The server listens on all addresses if empty.
+	Address string
+	// Port is the port the server listens on.
+	Port int
+	// Namespace is the namespace of the SparkApplications of batches and sessions.
+	Namespace string
+	// Image is the container image of batches and sessions, unless set in spark.kubernetes.container.image.
+	Image string
+	// SparkVersion is the version of Spark in the image.
+	SparkVersion string
+	// ServiceAccount is the service account of the drivers of batches and sessions.
+	ServiceAccount string
+}
+
+// sparkRequest has the properties of the Spark application of a batch or session that Livy accepts.
+type sparkRequest struct {
+	Name           string            `json:"name,omitempty"`
+	ProxyUser      string            `json:"proxyUser,omitempty"`
+	Jars           []string          `json:"jars,omitempty"`
+	PyFiles        []string          `json:"pyFiles,omitempty"`
+	Files          []string          `json:"files,omitempty"`
+	Archives       []string          `json:"archives,omitempty"`
+	DriverMemory   string            `json:"driverMemory,omitempty"`
+	DriverCores    float32           `json:"driverCores,omitempty"`
+	ExecutorMemory string            `json:"executorMemory,omitempty"`
+	ExecutorCores  float32           `json:"executorCores,omitempty"`
+	NumExecutors   int32             `json:"numExecutors,omitempty"`
+	Queue          string            `json:"queue,omitempty"`
+	Conf           map[string]string `json:"conf,omitempty"`
+}
+
+// appInfo has the links of the application of a batch or session.
+type appInfo struct {
+	DriverLogURL *string `json:"driverLogUrl"`
+	SparkUIURL   *string `json:"sparkUiUrl"`
+}
+
+// NewServer creates a new Livy-compatible server.
+func NewServer(
+	crdClient crdclientset.Interface,
+	kubeClient kubernetes.Interface,
+	crdInformerFactory crdinformers.SharedInformerFactory,
+	serverConfig Config) *Server {
+	s := &Server{
+		crdClient:  crdClient,
+		kubeClient: kubeClient,
+		lister:     crdInformerFactory.Sparkoperator().V1beta1().SparkApplications().Lister(),
+		config:     serverConfig,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+	s.getREPLURL = s.getDriverREPLURL
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", serverConfig.Address, serverConfig.Port),
+		Handler: s.handler(),
+	}
+	return s
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(batchesPath, s.authorized(batchesPath, s.serveBatches))
+	mux.HandleFunc(batchesPath+"/", s.authorized(batchesPath, s.serveBatches))
+	mux.HandleFunc(sessionsPath, s.authorized(sessionsPath, s.serveSessions))
+	mux.HandleFunc(sessionsPath+"/", s.authorized(sessionsPath, s.serveSessions))
+	return mux
+}
+
+// authorized authenticates the bearer token of requests, and only passes on requests whose user may perform
+// the verb of the request on the SparkApplications in the namespace.
+func (s *Server) authorized(prefix string, handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info, err := util.AuthenticateBearerToken(s.kubeClient, r)
+		if err != nil {
+			s.serveError(w, err)
+			return
+		}
+		if info == nil {
+			http.Error(w, "no authenticated user", http.StatusUnauthorized)
+			return
+		}
+		verb := getVerb(r, prefix)
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   info.Username,
+				Groups: info.Groups,
+				UID:    info.UID,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: s.config.Namespace,
+					Verb:      verb,
+					Group:     v1beta1.SchemeGroupVersion.Group,
+					Resource:  "sparkapplications",
+				},
+			},
+		}
+		result, err := s.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(review)
+		if err != nil {
+			s.serveError(w, fmt.Errorf("failed to review access of user %s: %v", info.Username, err))
+			return
+		}
+		if !result.Status.Allowed {
+			http.Error(w, fmt.Sprintf("user %s may not %s SparkApplications in namespace %s", info.Username, verb,
+				s.config.Namespace), http.StatusForbidden)
+			return
+		}
+		handle(w, r)
+	}
+}
+
+// getVerb returns the verb on SparkApplications the given request to batches or sessions needs.
+func getVerb(r *http.Request, prefix string) string {
+	collection := len(splitPath(r, prefix)) == 0
+	switch r.Method {
+	case http.MethodGet:
+		if collection {
+			return "list"
+		}
+		return "get"
+	case http.MethodPost:
+		if collection {
+			return "create"
+		}
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return "get"
+}
+
+// Start starts the Livy-compatible server.
+func (s *Server) Start() {
+	go func() {
+		glog.Infof("Starting the Livy-compatible server on port %d", s.config.Port)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			glog.Errorf("error while serving the Livy-compatible API: %v", err)
+		}
+	}()
+}
+
+// Stop stops the Livy-compatible server.
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	glog.Info("Stopping the Livy-compatible server")
+	return s.server.Shutdown(ctx)
+}
+
+// splitPath splits the path of the given request below the given prefix, e.g., "/batches/1/state" into "1"
+// and "state".
+func splitPath(r *http.Request, prefix string) []string {
+	rest := strings.Trim(strings.TrimPrefix(path.Clean(r.URL.Path), prefix), "/")
+	if rest == "" {
+		return nil
+	}
+	return strings.Split(rest, "/")
+}
+
+// listApplications returns the applications with the given ID label, ordered by ID.
+func (s *Server) listApplications(idLabel string) ([]*v1beta1.SparkApplication, error) {
+	selector, err := labels.Parse(idLabel)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := s.lister.SparkApplications(s.config.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(apps, func(i, j int) bool { return getID(apps[i], idLabel) < getID(apps[j], idLabel) })
+	return apps, nil
+}
+
+// getApplication returns the application with the given ID of a batch or session, or nil if there is none.
+// The application is read from the API server, so that clients can poll a batch or session right after
+// creating it.
+func (s *Server) getApplication(idLabel string, namePrefix string, id string) (*v1beta1.SparkApplication, error) {
+	name := fmt.Sprintf("%s-%s", namePrefix, id)
+	app, err := s.crdClient.SparkoperatorV1beta1().SparkApplications(s.config.Namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if app.Labels[idLabel] != id {
+		return nil, nil
+	}
+	return app, nil
+}
+
+func getID(app *v1beta1.SparkApplication, idLabel string) int {
+	id, _ := strconv.Atoi(app.Labels[idLabel])
+	return id
+}
+
+// createApplication creates the given application with the next free ID of the given ID label, trying
+// further IDs if another batch or session takes the ID first, and returns the created application.
+func (s *Server) createApplication(app *v1beta1.SparkApplication, idLabel string, namePrefix string) (*v1beta1.SparkApplication, error) {
+	apps, err := s.listApplications(idLabel)
+	if err != nil {
+		return nil, err
+	}
+	id := 0
+	if len(apps) > 0 {
+		id = getID(apps[len(apps)-1], idLabel) + 1
+	}
+	for attempt := 0; attempt < maxCreateAttempts; attempt++ {
+		app.Name = fmt.Sprintf("%s-%d", namePrefix, id)
+		app.Labels[idLabel] = strconv.Itoa(id)
+		created, err := s.crdClient.SparkoperatorV1beta1().SparkApplications(s.config.Namespace).Create(app)
+		if !errors.IsAlreadyExists(err) {
+			return created, err
+		}
+		id++
+	}
+	return nil, fmt.Errorf("failed to find a free ID for SparkApplication %s", namePrefix)
+}
+
+// deleteApplication deletes the given application, which stops its driver and executors.
+func (s *Server) deleteApplication(w http.ResponseWriter, app *v1beta1.SparkApplication) {
+	err := s.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Delete(app.Name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		s.serveError(w, fmt.Errorf("failed to delete SparkApplication %s/%s: %v", app.Namespace, app.Name, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"msg": "deleted"})
+}
+
+// buildApplication returns a SparkApplication with the properties in the given request.
+func (s *Server) buildApplication(req *sparkRequest) (*v1beta1.SparkApplication, error) {
+	if len(req.Archives) > 0 {
+		return nil, fmt.Errorf("archives are not supported")
+	}
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   s.config.Namespace,
+			Labels:      make(map[string]string),
+			Annotations: make(map[string]string),
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Mode:          v1beta1.ClusterMode,
+			SparkVersion:  s.config.SparkVersion,
+			RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+			Deps: v1beta1.Dependencies{
+				Jars:    req.Jars,
+				PyFiles: req.PyFiles,
+				Files:   req.Files,
+			},
+		},
+	}
+	if req.Name != "" {
+		app.Annotations[config.LivyNameAnnotation] = req.Name
+	}
+	// Livy impersonates the proxy user, which is recorded as the owner since the application runs with the
+	// service account of its driver.
+	if req.ProxyUser != "" {
+		app.Annotations[config.OwnerAnnotation] = req.ProxyUser
+	}
+	if req.Queue != "" {
+		app.Labels[config.SparkAppQueueLabel] = req.Queue
+	}
+
+	image := s.config.Image
+	if len(req.Conf) > 0 {
+		app.Spec.SparkConf = make(map[string]string)
+		for key, value := range req.Conf {
+			if key == config.SparkContainerImageKey {
+				image = value
+				continue
+			}
+			app.Spec.SparkConf[key] = value
+		}
+	}
+	if image != "" {
+		app.Spec.Image = &image
+	}
+
+	if s.config.ServiceAccount != "" {
+		serviceAccount := s.config.ServiceAccount
+		app.Spec.Driver.ServiceAccount = &serviceAccount
+	}
+	if req.DriverMemory != "" {
+		memory := req.DriverMemory
+		app.Spec.Driver.Memory = &memory
+	}
+	if req.DriverCores > 0 {
+		cores := req.DriverCores
+		app.Spec.Driver.Cores = &cores
+	}
+	if req.ExecutorMemory != "" {
+		memory := req.ExecutorMemory
+		app.Spec.Executor.Memory = &memory
+	}
+	if req.ExecutorCores > 0 {
+		cores := req.ExecutorCores
+		app.Spec.Executor.Cores = &cores
+	}
+	if req.NumExecutors > 0 {
+		instances := req.NumExecutors
+		app.Spec.Executor.Instances = &instances
+	}
+	return app, nil
+}
+
+func getAppInfo(app *v1beta1.SparkApplication) appInfo {
+	var info appInfo
+	address := app.Status.DriverInfo.WebUIIngressAddress
+	if address == "" {
+		address = app.Status.DriverInfo.WebUIAddress
+	}
+	if address != "" && !isTerminated(app) {
+		if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+			address = "http://" + address
+		}
+		info.SparkUIURL = &address
+	}
+	return info
+}
+
+func getAppID(app *v1beta1.SparkApplication) *string {
+	if app.Status.SparkApplicationID == "" {
+		return nil
+	}
+	id := app.Status.SparkApplicationID
+	return &id
+}
+
+func getLog(app *v1beta1.SparkApplication) []string {
+	if app.Status.AppState.ErrorMessage == "" {
+		return []string{}
+	}
+	return strings.Split(app.Status.AppState.ErrorMessage, "\n")
+}
+
+func isTerminated(app *v1beta1.SparkApplication) bool {
+	switch app.Status.AppState.State {
+	case v1beta1.CompletedState, v1beta1.FailedState, v1beta1.SkippedState:
+		return true
+	}
+	return false
+}
+
+// getPage returns the bounds of the page of a list of the given length selected by the "from" and "size"
+// query parameters of the given request, which default to the first 100 items as in Livy.
+func getPage(r *http.Request, length int) (int, int) {
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil || from < 0 {
+		from = 0
+	}
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || size < 0 {
+		size = 100
+	}
+	if from > length {
+		from = length
+	}
+	to := from + size
+	if to > length {
+		to = length
+	}
+	return from, to
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		glog.Errorf("failed to write Livy response: %v", err)
+	}
+}
+
+func notAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
+
+func (s *Server) serveError(w http.ResponseWriter, err error) {
+	glog.Errorf("failed to serve Livy request: %v", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
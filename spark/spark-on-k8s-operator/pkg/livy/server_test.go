@@ -0,0 +1,266 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// newFakeServer creates a server on fake clients, where the token "token-of-<user>" authenticates the user, and
+// users may perform a verb on SparkApplications if the given permissions contain "<user> <verb>". Without
+// permissions, alice may perform any verb.
+func newFakeServer(permissions ...string) *Server {
+	crdClient := crdclientfake.NewSimpleClientset()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if strings.HasPrefix(review.Spec.Token, "token-of-") {
+			review.Status.Authenticated = true
+			review.Status.User.Username = strings.TrimPrefix(review.Spec.Token, "token-of-")
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		if len(permissions) == 0 {
+			review.Status.Allowed = review.Spec.User == "alice"
+		}
+		for _, p := range permissions {
+			if p == review.Spec.User+" "+review.Spec.ResourceAttributes.Verb {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0*time.Second)
+	server := NewServer(crdClient, kubeClient, informerFactory, Config{
+		Namespace:      "livy",
+		Image:          "spark:2.4.0",
+		SparkVersion:   "2.4.0",
+		ServiceAccount: "spark",
+	})
+	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
+	crdClient.PrependReactor("create", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			obj := action.(kubetesting.CreateAction).GetObject()
+			informer.GetIndexer().Add(obj)
+			return false, obj, nil
+		})
+	crdClient.PrependReactor("delete", "sparkapplications",
+		func(action kubetesting.Action) (bool, runtime.Object, error) {
+			name := action.(kubetesting.DeleteAction).GetName()
+			if obj, exists, _ := informer.GetIndexer().GetByKey("livy/" + name); exists {
+				informer.GetIndexer().Delete(obj)
+			}
+			return false, nil, nil
+		})
+	return server
+}
+
+func serve(s *Server, method, target, body string) *httptest.ResponseRecorder {
+	return serveAs(s, "alice", method, target, body)
+}
+
+func serveAs(s *Server, userName, method, target, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if userName != "" {
+		request.Header.Set("Authorization", "Bearer token-of-"+userName)
+	}
+	recorder := httptest.NewRecorder()
+	s.handler().ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAuthorized(t *testing.T) {
+	s := newFakeServer("bob list", "bob get")
+
+	assert.Equal(t, http.StatusUnauthorized, serveAs(s, "", http.MethodGet, "/batches", "").Code)
+	request := httptest.NewRequest(http.MethodGet, "/batches", nil)
+	request.Header.Set("Authorization", "Bearer forged")
+	recorder := httptest.NewRecorder()
+	s.handler().ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	assert.Equal(t, http.StatusOK, serveAs(s, "bob", http.MethodGet, "/batches", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(s, "bob", http.MethodPost, "/batches",
+		`{"file": "local:///opt/app.jar", "className": "com.example.App"}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(s, "bob", http.MethodDelete, "/sessions/0", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(s, "carol", http.MethodGet, "/sessions", "").Code)
+}
+
+func TestGetVerb(t *testing.T) {
+	verb := func(method, target string) string {
+		return getVerb(httptest.NewRequest(method, target, nil), sessionsPath)
+	}
+	assert.Equal(t, "list", verb(http.MethodGet, "/sessions"))
+	assert.Equal(t, "get", verb(http.MethodGet, "/sessions/0/statements/1"))
+	assert.Equal(t, "create", verb(http.MethodPost, "/sessions"))
+	assert.Equal(t, "update", verb(http.MethodPost, "/sessions/0/statements"))
+	assert.Equal(t, "delete", verb(http.MethodDelete, "/sessions/0"))
+}
+
+func TestBatches(t *testing.T) {
+	s := newFakeServer()
+
+	recorder := serve(s, http.MethodPost, "/batches", `{
+		"file": "local:///opt/spark/examples/src/main/python/pi.py",
+		"args": ["10"],
+		"name": "pi",
+		"proxyUser": "alice",
+		"numExecutors": 2,
+		"executorMemory": "2g",
+		"conf": {"spark.kubernetes.container.image": "spark-py:2.4.0", "spark.sql.shuffle.partitions": "8"}
+	}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	var b batch
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &b))
+	assert.Equal(t, 0, b.ID)
+	assert.Equal(t, "pi", *b.Name)
+	assert.Equal(t, "not_started", b.State)
+
+	app, err := s.crdClient.SparkoperatorV1beta1().SparkApplications("livy").Get("livy-batch-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "0", app.Labels[config.LivyBatchIDLabel])
+	assert.Equal(t, "alice", app.Annotations[config.OwnerAnnotation])
+	assert.Equal(t, v1beta1.PythonApplicationType, app.Spec.Type)
+	assert.Equal(t, []string{"10"}, app.Spec.Arguments)
+	assert.Equal(t, "spark-py:2.4.0", *app.Spec.Image)
+	assert.Equal(t, map[string]string{"spark.sql.shuffle.partitions": "8"}, app.Spec.SparkConf)
+	assert.Equal(t, int32(2), *app.Spec.Executor.Instances)
+	assert.Equal(t, "2g", *app.Spec.Executor.Memory)
+	assert.Equal(t, "spark", *app.Spec.Driver.ServiceAccount)
+
+	// A JAR file requires a main class.
+	recorder = serve(s, http.MethodPost, "/batches", `{"file": "local:///opt/app.jar"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = serve(s, http.MethodPost, "/batches", `{"file": "local:///opt/app.jar", "className": "com.example.App"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &b))
+	assert.Equal(t, 1, b.ID)
+
+	app.Status.AppState.State = v1beta1.RunningState
+	app.Status.SparkApplicationID = "spark-123"
+	if _, err := s.crdClient.SparkoperatorV1beta1().SparkApplications("livy").Update(app); err != nil {
+		t.Fatal(err)
+	}
+	recorder = serve(s, http.MethodGet, "/batches/0", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &b))
+	assert.Equal(t, "running", b.State)
+	assert.Equal(t, "spark-123", *b.AppID)
+	recorder = serve(s, http.MethodGet, "/batches/0/state", "")
+	assert.JSONEq(t, `{"id": 0, "state": "running"}`, recorder.Body.String())
+
+	recorder = serve(s, http.MethodGet, "/batches?from=1", "")
+	var list struct {
+		From     int     `json:"from"`
+		Total    int     `json:"total"`
+		Sessions []batch `json:"sessions"`
+	}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &list))
+	assert.Equal(t, 2, list.Total)
+	assert.Len(t, list.Sessions, 1)
+	assert.Equal(t, 1, list.Sessions[0].ID)
+
+	recorder = serve(s, http.MethodDelete, "/batches/0", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	_, err = s.crdClient.SparkoperatorV1beta1().SparkApplications("livy").Get("livy-batch-0", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/batches/0", "").Code)
+}
+
+func TestSessions(t *testing.T) {
+	var forwarded []string
+	repl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		forwarded = append(forwarded, r.Method+" "+r.URL.Path+" "+string(body))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/statements":
+			w.Write([]byte(`{"total_statements": 1, "statements": [{"id": 0, "state": "available"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/statements":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 1, "state": "waiting"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer repl.Close()
+	s := newFakeServer()
+	s.getREPLURL = func(app *v1beta1.SparkApplication) (string, error) { return repl.URL, nil }
+
+	assert.Equal(t, http.StatusBadRequest, serve(s, http.MethodPost, "/sessions", `{"kind": "spark"}`).Code)
+	recorder := serve(s, http.MethodPost, "/sessions", `{"kind": "sql", "driverMemory": "4g"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	var sess session
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &sess))
+	assert.Equal(t, 0, sess.ID)
+	assert.Equal(t, "sql", sess.Kind)
+	assert.Equal(t, "not_started", sess.State)
+
+	configMap, err := s.kubeClient.CoreV1().ConfigMaps("livy").Get(replConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, replScript, configMap.Data[replFileName])
+	app, err := s.crdClient.SparkoperatorV1beta1().SparkApplications("livy").Get("livy-session-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "local:///etc/livy-repl/repl.py", *app.Spec.MainApplicationFile)
+	assert.Equal(t, []string{"--port", "8998", "--kind", "sql"}, app.Spec.Arguments)
+	assert.Equal(t, []v1beta1.NamePath{{Name: replConfigMapName, Path: replMountPath}}, app.Spec.Driver.ConfigMaps)
+	assert.Equal(t, "4g", *app.Spec.Driver.Memory)
+
+	// Statements are only accepted once the session runs.
+	assert.Equal(t, http.StatusConflict, serve(s, http.MethodPost, "/sessions/0/statements", `{"code": "SELECT 1"}`).Code)
+	app.Status.AppState.State = v1beta1.RunningState
+	if _, err := s.crdClient.SparkoperatorV1beta1().SparkApplications("livy").Update(app); err != nil {
+		t.Fatal(err)
+	}
+	recorder = serve(s, http.MethodGet, "/sessions/0/state", "")
+	assert.JSONEq(t, `{"id": 0, "state": "idle"}`, recorder.Body.String())
+
+	forwarded = nil
+	recorder = serve(s, http.MethodPost, "/sessions/0/statements", `{"code": "SELECT 1"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.JSONEq(t, `{"id": 1, "state": "waiting"}`, recorder.Body.String())
+	assert.Contains(t, forwarded, `POST /statements {"code": "SELECT 1"}`)
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/sessions/0/statements/7", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/sessions/1", "").Code)
+}
@@ -0,0 +1,477 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	sessionNamePrefix = "livy-session"
+
+	// replConfigMapName is the name of the ConfigMap with the REPL script, which is mounted into the drivers
+	// of sessions at replMountPath.
+	replConfigMapName = "livy-repl"
+	replMountPath     = "/etc/livy-repl"
+	replFileName      = "repl.py"
+	replPort          = 8998
+	// replProbeTimeout is how long the state of a session waits for the REPL of the session to respond.
+	replProbeTimeout = 2 * time.Second
+)
+
+// sessionKinds are the kinds of statements the REPL runs.
+var sessionKinds = map[string]bool{"pyspark": true, "sql": true}
+
+// replScript runs the statements of a session one at a time in the driver, and serves them over HTTP in the
+// format of the statement API of Livy, which the server forwards requests to.
+const replScript = `import argparse
+import ast
+import io
+import json
+import queue
+import threading
+import traceback
+from contextlib import redirect_stdout
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from socketserver import ThreadingMixIn
+
+from pyspark.sql import SparkSession
+
+parser = argparse.ArgumentParser()
+parser.add_argument("--port", type=int, required=True)
+parser.add_argument("--kind", default="pyspark")
+parser.add_argument("--max-rows", type=int, default=1000)
+args = parser.parse_args()
+
+spark = SparkSession.builder.enableHiveSupport().getOrCreate()
+sc = spark.sparkContext
+scope = {"spark": spark, "sc": sc}
+statements = []
+pending = queue.Queue()
+lock = threading.Lock()
+
+
+def run_pyspark(code):
+    tree = ast.parse(code)
+    last = None
+    if tree.body and isinstance(tree.body[-1], ast.Expr):
+        last = ast.Expression(tree.body.pop().value)
+    out = io.StringIO()
+    with redirect_stdout(out):
+        exec(compile(tree, "<stdin>", "exec"), scope)
+        if last is not None:
+            value = eval(compile(last, "<stdin>", "eval"), scope)
+            if value is not None:
+                print(repr(value))
+    return {"text/plain": out.getvalue().rstrip("\n")}
+
+
+def run_sql(code):
+    df = spark.sql(code)
+    rows = [list(row) for row in df.take(args.max_rows)]
+    return {"application/json": {"schema": df.schema.jsonValue(), "data": rows}}
+
+
+runners = {"pyspark": run_pyspark, "sql": run_sql}
+
+
+def run_statements():
+    while True:
+        statement = pending.get()
+        with lock:
+            if statement["state"] != "waiting":
+                continue
+            statement["state"] = "running"
+        sc.setJobGroup("livy-%d" % statement["id"], statement["code"][:100], True)
+        try:
+            data = runners[statement["kind"]](statement["code"])
+            output = {"status": "ok", "execution_count": statement["id"], "data": data}
+        except Exception as e:
+            output = {"status": "error", "execution_count": statement["id"], "ename": type(e).__name__,
+                      "evalue": str(e), "traceback": traceback.format_exc().splitlines(True)}
+        with lock:
+            statement["state"] = "cancelled" if statement["state"] == "cancelling" else "available"
+            statement["output"] = output
+            statement["progress"] = 1.0
+
+
+class Handler(BaseHTTPRequestHandler):
+    def reply(self, status, body):
+        data = json.dumps(body, default=str).encode("utf-8")
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        self.send_header("Content-Length", str(len(data)))
+        self.end_headers()
+        self.wfile.write(data)
+
+    def statement(self, parts):
+        try:
+            index = int(parts[1])
+        except ValueError:
+            return None
+        return statements[index] if 0 <= index < len(statements) else None
+
+    def do_GET(self):
+        parts = self.path.strip("/").split("/")
+        with lock:
+            if parts == ["statements"]:
+                return self.reply(200, {"total_statements": len(statements), "statements": statements})
+            if len(parts) == 2 and parts[0] == "statements":
+                statement = self.statement(parts)
+                if statement is not None:
+                    return self.reply(200, statement)
+        self.reply(404, {"msg": "not found"})
+
+    def do_POST(self):
+        parts = self.path.strip("/").split("/")
+        length = int(self.headers.get("Content-Length", 0))
+        body = json.loads(self.rfile.read(length) or b"{}")
+        if parts == ["statements"]:
+            kind = body.get("kind") or args.kind
+            if kind not in runners:
+                return self.reply(400, {"msg": "unsupported statement kind %s" % kind})
+            with lock:
+                statement = {"id": len(statements), "code": body.get("code", ""), "kind": kind,
+                             "state": "waiting", "output": None, "progress": 0.0}
+                statements.append(statement)
+                pending.put(statement)
+                return self.reply(201, statement)
+        if len(parts) == 3 and parts[0] == "statements" and parts[2] == "cancel":
+            with lock:
+                statement = self.statement(parts)
+                if statement is not None:
+                    if statement["state"] == "waiting":
+                        statement["state"] = "cancelled"
+                    elif statement["state"] == "running":
+                        statement["state"] = "cancelling"
+                        sc.cancelJobGroup("livy-%d" % statement["id"])
+                    return self.reply(200, {"msg": "canceled"})
+        self.reply(404, {"msg": "not found"})
+
+    def log_message(self, *args):
+        pass
+
+
+class Server(ThreadingMixIn, HTTPServer):
+    daemon_threads = True
+
+
+threading.Thread(target=run_statements, daemon=True).start()
+Server(("", args.port), Handler).serve_forever()
+`
+
+// sessionRequest is the body of a request creating an interactive session.
+type sessionRequest struct {
+	sparkRequest
+	Kind string `json:"kind,omitempty"`
+}
+
+// session is an interactive session as returned by Livy.
+type session struct {
+	ID        int      `json:"id"`
+	Name      *string  `json:"name"`
+	AppID     *string  `json:"appId"`
+	Owner     *string  `json:"owner"`
+	ProxyUser *string  `json:"proxyUser"`
+	Kind      string   `json:"kind"`
+	AppInfo   appInfo  `json:"appInfo"`
+	Log       []string `json:"log"`
+	State     string   `json:"state"`
+}
+
+// replStatements is the list of statements served by the REPL of a session.
+type replStatements struct {
+	Statements []struct {
+		State string `json:"state"`
+	} `json:"statements"`
+}
+
+// getSessionState returns the Livy state of the session run by the given application. A running session is
+// starting until its REPL responds, and busy while the REPL has statements waiting or running.
+func (s *Server) getSessionState(app *v1beta1.SparkApplication) string {
+	switch app.Status.AppState.State {
+	case v1beta1.NewState, v1beta1.QueuedState:
+		return "not_started"
+	case v1beta1.SubmittedState, v1beta1.PendingRerunState, v1beta1.InvalidatingState:
+		return "starting"
+	case v1beta1.SucceedingState, v1beta1.FailingState:
+		return "shutting_down"
+	case v1beta1.CompletedState:
+		return "success"
+	case v1beta1.FailedState, v1beta1.FailedSubmissionState:
+		return "dead"
+	}
+
+	url, err := s.getREPLURL(app)
+	if err != nil {
+		return "starting"
+	}
+	client := &http.Client{Timeout: replProbeTimeout}
+	resp, err := client.Get(url + "/statements")
+	if err != nil {
+		return "starting"
+	}
+	defer resp.Body.Close()
+	var list replStatements
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&list) != nil {
+		return "starting"
+	}
+	for _, statement := range list.Statements {
+		if statement.State == "waiting" || statement.State == "running" {
+			return "busy"
+		}
+	}
+	return "idle"
+}
+
+func (s *Server) toSession(app *v1beta1.SparkApplication) session {
+	sess := session{
+		ID:      getID(app, config.LivySessionIDLabel),
+		AppID:   getAppID(app),
+		Kind:    app.Annotations[config.LivySessionKindAnnotation],
+		AppInfo: getAppInfo(app),
+		Log:     getLog(app),
+		State:   s.getSessionState(app),
+	}
+	if name, ok := app.Annotations[config.LivyNameAnnotation]; ok {
+		sess.Name = &name
+	}
+	if owner, ok := app.Annotations[config.OwnerAnnotation]; ok {
+		sess.Owner = &owner
+		sess.ProxyUser = &owner
+	}
+	return sess
+}
+
+// buildSessionApplication returns the SparkApplication running the REPL of the session in the given request.
+func (s *Server) buildSessionApplication(req *sessionRequest) (*v1beta1.SparkApplication, error) {
+	kind := req.Kind
+	if kind == "" {
+		kind = "pyspark"
+	}
+	if !sessionKinds[kind] {
+		return nil, fmt.Errorf("unsupported session kind %s", kind)
+	}
+	app, err := s.buildApplication(&req.sparkRequest)
+	if err != nil {
+		return nil, err
+	}
+	app.Annotations[config.LivySessionKindAnnotation] = kind
+	pythonVersion := "3"
+	mainApplicationFile := fmt.Sprintf("local://%s/%s", replMountPath, replFileName)
+	app.Spec.Type = v1beta1.PythonApplicationType
+	app.Spec.PythonVersion = &pythonVersion
+	app.Spec.MainApplicationFile = &mainApplicationFile
+	app.Spec.Arguments = []string{"--port", fmt.Sprintf("%d", replPort), "--kind", kind}
+	app.Spec.Driver.ConfigMaps = append(app.Spec.Driver.ConfigMaps,
+		v1beta1.NamePath{Name: replConfigMapName, Path: replMountPath})
+	return app, nil
+}
+
+// serveSessions serves the session API at /sessions, /sessions/<id>, /sessions/<id>/state, and
+// /sessions/<id>/statements[/<statement id>[/cancel]].
+func (s *Server) serveSessions(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r, sessionsPath)
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listSessions(w, r)
+		case http.MethodPost:
+			s.createSession(w, r)
+		default:
+			notAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	app, err := s.getApplication(config.LivySessionIDLabel, sessionNamePrefix, parts[0])
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	if app == nil {
+		http.Error(w, fmt.Sprintf("session %s not found", parts[0]), http.StatusNotFound)
+		return
+	}
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.toSession(app))
+		case http.MethodDelete:
+			glog.Infof("Deleting SparkApplication %s/%s of Livy session %s", app.Namespace, app.Name, parts[0])
+			s.deleteApplication(w, app)
+		default:
+			notAllowed(w, http.MethodGet, http.MethodDelete)
+		}
+		return
+	}
+	switch parts[1] {
+	case "state":
+		if r.Method != http.MethodGet {
+			notAllowed(w, http.MethodGet)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": getID(app, config.LivySessionIDLabel), "state": s.getSessionState(app)})
+	case "log":
+		if r.Method != http.MethodGet {
+			notAllowed(w, http.MethodGet)
+			return
+		}
+		s.serveDriverLog(w, r, app, getID(app, config.LivySessionIDLabel))
+	case "statements":
+		s.forwardToREPL(w, r, app, "/"+strings.Join(parts[1:], "/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	apps, err := s.listApplications(config.LivySessionIDLabel)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	from, to := getPage(r, len(apps))
+	sessions := []session{}
+	for _, app := range apps[from:to] {
+		sessions = append(sessions, s.toSession(app))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"from": from, "total": len(apps), "sessions": sessions})
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	var req sessionRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	app, err := s.buildSessionApplication(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.syncREPLScript(); err != nil {
+		s.serveError(w, err)
+		return
+	}
+	created, err := s.createApplication(app, config.LivySessionIDLabel, sessionNamePrefix)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	glog.Infof("Created SparkApplication %s/%s for Livy session", created.Namespace, created.Name)
+	writeJSON(w, http.StatusCreated, s.toSession(created))
+}
+
+// syncREPLScript creates or updates the ConfigMap with the REPL script of sessions.
+func (s *Server) syncREPLScript() error {
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: replConfigMapName, Namespace: s.config.Namespace},
+		Data:       map[string]string{replFileName: replScript},
+	}
+	existing, err := s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace).Get(replConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace).Create(configMap)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Data, configMap.Data) {
+		return nil
+	}
+	existing.Data = configMap.Data
+	_, err = s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace).Update(existing)
+	return err
+}
+
+// getDriverREPLURL returns the base URL of the REPL in the running driver pod of the given session.
+func (s *Server) getDriverREPLURL(app *v1beta1.SparkApplication) (string, error) {
+	podName := app.Status.DriverInfo.PodName
+	if podName == "" {
+		return "", fmt.Errorf("SparkApplication %s/%s has no driver pod", app.Namespace, app.Name)
+	}
+	pod, err := s.kubeClient.CoreV1().Pods(app.Namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("driver pod %s/%s has no IP", app.Namespace, podName)
+	}
+	return fmt.Sprintf("http://%s:%d", pod.Status.PodIP, replPort), nil
+}
+
+// forwardToREPL forwards the given request with the given path to the REPL of the given session, and
+// responds with the response of the REPL.
+func (s *Server) forwardToREPL(w http.ResponseWriter, r *http.Request, app *v1beta1.SparkApplication, replPath string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		notAllowed(w, http.MethodGet, http.MethodPost)
+		return
+	}
+	if state := s.getSessionState(app); state != "idle" && state != "busy" {
+		http.Error(w, fmt.Sprintf("session is %s", state), http.StatusConflict)
+		return
+	}
+	url, err := s.getREPLURL(app)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+
+	var body bytes.Buffer
+	if r.Body != nil {
+		if _, err := io.Copy(&body, r.Body); err != nil {
+			http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	req, err := http.NewRequest(r.Method, url+replPath, &body)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.serveError(w, fmt.Errorf("failed to reach the REPL of SparkApplication %s/%s: %v", app.Namespace, app.Name, err))
+		return
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(data)
+}
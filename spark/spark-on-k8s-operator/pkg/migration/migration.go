@@ -0,0 +1,485 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration rewrites the custom resources created by earlier versions of the operator in the latest
+// schema, so that upgrades do not leave objects behind that are stored in an old API version or use deprecated
+// fields.
+package migration
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/golang/glog"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	ijcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/ingestjob"
+	ssacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/scheduledsparkapplication"
+	sacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplication"
+	sarcrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplicationrun"
+	socrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkoperatorconfiguration"
+	stscrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkthriftserver"
+)
+
+// Names of the fixes of objects written by earlier versions of the operator.
+const (
+	// DeprecatedRetriesFix moves the deprecated fields failureRetries and retryInterval of the spec of an
+	// application, which the operator ignores, to onFailureRetries and onFailureRetryInterval of its
+	// restartPolicy, unless those are set.
+	DeprecatedRetriesFix = "deprecated-retries"
+	// DefaultsFix sets the fields of the spec of an application that have defaults to their defaults. The
+	// operator applies the same defaults when it reads the application, so they do not restart it.
+	DefaultsFix = "defaults"
+	// ObservedGenerationFix sets the observedGeneration of applications that earlier versions of the operator
+	// processed without recording it to their generation.
+	ObservedGenerationFix = "observed-generation"
+	// StateLabelFix sets the state label of applications last updated by earlier versions of the operator, which
+	// did not set it, so that listing applications by state finds them.
+	StateLabelFix = "state-label"
+)
+
+// specFixes are the fixes of the specs of applications, in the order they are applied. None of them changes the
+// driver and executor pods, so running applications are not restarted by them.
+var specFixes = []struct {
+	name string
+	fix  func(spec *v1beta1.SparkApplicationSpec) bool
+}{
+	{name: DeprecatedRetriesFix, fix: fixDeprecatedRetries},
+	{name: DefaultsFix, fix: fixDefaults},
+}
+
+// Summary tells what a migration did, or would do in a dry run, for each resource.
+type Summary struct {
+	DryRun    bool
+	Resources []*ResourceSummary
+}
+
+// ResourceSummary tells what a migration did with the objects of a resource.
+type ResourceSummary struct {
+	// Resource is the plural name of the resource.
+	Resource string
+	// Skipped tells why the resource was skipped, if it was.
+	Skipped string
+	// StorageRewrite tells if all objects were rewritten because some may be stored in an old API version.
+	StorageRewrite bool
+	Scanned        int
+	Rewritten      int
+	Failed         int
+	// Fixes has the number of objects each fix was applied to, by the name of the fix.
+	Fixes  map[string]int
+	Errors []string
+}
+
+// Failed returns the number of objects that failed to be migrated.
+func (s *Summary) Failed() int {
+	failed := 0
+	for _, resource := range s.Resources {
+		failed += resource.Failed
+	}
+	return failed
+}
+
+// Print prints the summary as a table, followed by the errors.
+func (s *Summary) Print(out io.Writer) {
+	if s.DryRun {
+		fmt.Fprintln(out, "Dry run, no objects were written.")
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tSCANNED\tREWRITTEN\tFAILED\tFIXES")
+	for _, resource := range s.Resources {
+		if resource.Skipped != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t%s\n", resource.Resource, resource.Skipped)
+			continue
+		}
+		var fixes []string
+		for name, count := range resource.Fixes {
+			fixes = append(fixes, fmt.Sprintf("%s=%d", name, count))
+		}
+		sort.Strings(fixes)
+		if resource.StorageRewrite {
+			fixes = append([]string{"storage-version"}, fixes...)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", resource.Resource, resource.Scanned, resource.Rewritten,
+			resource.Failed, strings.Join(fixes, ", "))
+	}
+	w.Flush()
+	for _, resource := range s.Resources {
+		for _, err := range resource.Errors {
+			fmt.Fprintf(out, "%s: %s\n", resource.Resource, err)
+		}
+	}
+}
+
+// Migrator migrates the custom resources of the operator. Objects are rewritten in the latest API version if the
+// CustomResourceDefinition of their resource may have stored them in an older one, and objects with deprecated
+// fields or fields written by earlier versions of the operator are fixed. Once all objects of a resource have
+// been rewritten, the old versions are removed from the stored versions of its CustomResourceDefinition.
+type Migrator struct {
+	client              crdclientset.Interface
+	apiExtensionsClient apiextensionsclient.Interface
+	namespace           string
+	dryRun              bool
+}
+
+// New creates a Migrator migrating the objects in the given namespace, or in all namespaces if it is empty. The
+// stored versions of CustomResourceDefinitions are only checked and updated with a non-nil apiExtensionsClient,
+// and objects are always rewritten without one. A dry run only reports what would be done.
+func New(client crdclientset.Interface, apiExtensionsClient apiextensionsclient.Interface, namespace string,
+	dryRun bool) *Migrator {
+	return &Migrator{
+		client:              client,
+		apiExtensionsClient: apiExtensionsClient,
+		namespace:           namespace,
+		dryRun:              dryRun,
+	}
+}
+
+// Run migrates the objects of all resources of the operator.
+func (m *Migrator) Run() *Summary {
+	resources := []struct {
+		crdName string
+		plural  string
+		migrate func(summary *ResourceSummary) error
+	}{
+		{sacrd.FullName, sacrd.Plural, m.migrateSparkApplications},
+		{ssacrd.FullName, ssacrd.Plural, m.migrateScheduledSparkApplications},
+		{sarcrd.FullName, sarcrd.Plural, m.migrateSparkApplicationRuns},
+		{ijcrd.FullName, ijcrd.Plural, m.migrateIngestJobs},
+		{stscrd.FullName, stscrd.Plural, m.migrateSparkThriftServers},
+		{socrd.FullName, socrd.Plural, m.migrateSparkOperatorConfigurations},
+	}
+
+	summary := &Summary{DryRun: m.dryRun}
+	for _, resource := range resources {
+		resourceSummary := &ResourceSummary{Resource: resource.plural, Fixes: make(map[string]int)}
+		summary.Resources = append(summary.Resources, resourceSummary)
+		resourceSummary.StorageRewrite = m.needsStorageRewrite(resource.crdName)
+		if err := resource.migrate(resourceSummary); err != nil {
+			if errors.IsNotFound(err) {
+				resourceSummary.Skipped = "not installed"
+				continue
+			}
+			resourceSummary.Skipped = fmt.Sprintf("failed to list: %v", err)
+			continue
+		}
+		if resourceSummary.StorageRewrite && resourceSummary.Failed == 0 && m.namespace == "" && !m.dryRun {
+			if err := m.setStoredVersion(resource.crdName); err != nil {
+				resourceSummary.Errors = append(resourceSummary.Errors,
+					fmt.Sprintf("failed to update the stored versions of %s: %v", resource.crdName, err))
+			}
+		}
+	}
+	return summary
+}
+
+// needsStorageRewrite tells if objects of the resource with the given CustomResourceDefinition may be stored in
+// an API version other than the latest one.
+func (m *Migrator) needsStorageRewrite(crdName string) bool {
+	if m.apiExtensionsClient == nil {
+		return true
+	}
+	crd, err := m.apiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName,
+		metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			glog.Warningf("failed to get CustomResourceDefinition %s, rewriting all its objects: %v", crdName, err)
+		}
+		return true
+	}
+	for _, version := range crd.Status.StoredVersions {
+		if version != v1beta1.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// setStoredVersion records that all objects of the resource with the given CustomResourceDefinition are stored
+// in the latest API version.
+func (m *Migrator) setStoredVersion(crdName string) error {
+	if m.apiExtensionsClient == nil {
+		return nil
+	}
+	client := m.apiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		crd, err := client.Get(crdName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(crd.Status.StoredVersions, []string{v1beta1.Version}) {
+			return nil
+		}
+		crd.Status.StoredVersions = []string{v1beta1.Version}
+		_, err = client.UpdateStatus(crd)
+		return err
+	})
+}
+
+// migrate records the given fixes of an object in the summary and writes the object, if it has fixes or all
+// objects of its resource are rewritten.
+func (m *Migrator) migrate(summary *ResourceSummary, key string, fixes []string, write func() error) {
+	summary.Scanned++
+	if len(fixes) == 0 && !summary.StorageRewrite {
+		return
+	}
+	for _, fix := range fixes {
+		summary.Fixes[fix]++
+	}
+	if m.dryRun {
+		summary.Rewritten++
+		return
+	}
+	if err := retry.RetryOnConflict(retry.DefaultRetry, write); err != nil {
+		if errors.IsNotFound(err) {
+			// The object has been deleted since it was listed.
+			return
+		}
+		summary.Failed++
+		summary.Errors = append(summary.Errors, fmt.Sprintf("failed to migrate %s: %v", key, err))
+		return
+	}
+	summary.Rewritten++
+}
+
+func (m *Migrator) migrateSparkApplications(summary *ResourceSummary) error {
+	client := m.client.SparkoperatorV1beta1().SparkApplications(m.namespace)
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		app := &list.Items[i]
+		fixes := fixSparkApplication(app.DeepCopy())
+		m.migrate(summary, app.Namespace+"/"+app.Name, fixes, func() error {
+			client := m.client.SparkoperatorV1beta1().SparkApplications(app.Namespace)
+			current, err := client.Get(app.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			fixSparkApplicationSpec(&current.Spec)
+			fixStateLabel(current)
+			updated, err := client.Update(current)
+			if err != nil {
+				return err
+			}
+			// The status is only written through its subresource.
+			if fixSparkApplicationStatus(updated) {
+				_, err = client.UpdateStatus(updated)
+			}
+			return err
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migrateScheduledSparkApplications(summary *ResourceSummary) error {
+	client := m.client.SparkoperatorV1beta1().ScheduledSparkApplications(m.namespace)
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		app := &list.Items[i]
+		fixes := fixSparkApplicationSpec(app.Spec.Template.DeepCopy())
+		m.migrate(summary, app.Namespace+"/"+app.Name, fixes, func() error {
+			client := m.client.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace)
+			current, err := client.Get(app.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			fixSparkApplicationSpec(&current.Spec.Template)
+			_, err = client.Update(current)
+			return err
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migrateSparkApplicationRuns(summary *ResourceSummary) error {
+	client := m.client.SparkoperatorV1beta1().SparkApplicationRuns(m.namespace)
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		run := &list.Items[i]
+		m.migrate(summary, run.Namespace+"/"+run.Name, nil, func() error {
+			current, err := m.client.SparkoperatorV1beta1().SparkApplicationRuns(run.Namespace).Get(run.Name,
+				metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = m.client.SparkoperatorV1beta1().SparkApplicationRuns(run.Namespace).Update(current)
+			return err
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migrateIngestJobs(summary *ResourceSummary) error {
+	client := m.client.SparkoperatorV1beta1().IngestJobs(m.namespace)
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		job := &list.Items[i]
+		m.migrate(summary, job.Namespace+"/"+job.Name, nil, func() error {
+			current, err := m.client.SparkoperatorV1beta1().IngestJobs(job.Namespace).Get(job.Name,
+				metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = m.client.SparkoperatorV1beta1().IngestJobs(job.Namespace).Update(current)
+			return err
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migrateSparkThriftServers(summary *ResourceSummary) error {
+	client := m.client.SparkoperatorV1beta1().SparkThriftServers(m.namespace)
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		server := &list.Items[i]
+		m.migrate(summary, server.Namespace+"/"+server.Name, nil, func() error {
+			current, err := m.client.SparkoperatorV1beta1().SparkThriftServers(server.Namespace).Get(server.Name,
+				metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = m.client.SparkoperatorV1beta1().SparkThriftServers(server.Namespace).Update(current)
+			return err
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migrateSparkOperatorConfigurations(summary *ResourceSummary) error {
+	// SparkOperatorConfigurations are cluster-scoped, so they are only migrated along with all namespaces.
+	if m.namespace != "" {
+		summary.Skipped = "cluster-scoped"
+		return nil
+	}
+	client := m.client.SparkoperatorV1beta1().SparkOperatorConfigurations()
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		configuration := &list.Items[i]
+		m.migrate(summary, configuration.Name, nil, func() error {
+			current, err := client.Get(configuration.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = client.Update(current)
+			return err
+		})
+	}
+	return nil
+}
+
+// fixSparkApplication applies the fixes of the spec and the status to the given application, and returns the
+// names of the ones it applied.
+func fixSparkApplication(app *v1beta1.SparkApplication) []string {
+	fixes := fixSparkApplicationSpec(&app.Spec)
+	if fixStateLabel(app) {
+		fixes = append(fixes, StateLabelFix)
+	}
+	if fixSparkApplicationStatus(app) {
+		fixes = append(fixes, ObservedGenerationFix)
+	}
+	return fixes
+}
+
+// fixSparkApplicationSpec applies the fixes of specs to the given spec, and returns the names of the ones it
+// applied.
+func fixSparkApplicationSpec(spec *v1beta1.SparkApplicationSpec) []string {
+	var fixes []string
+	for _, fix := range specFixes {
+		if fix.fix(spec) {
+			fixes = append(fixes, fix.name)
+		}
+	}
+	return fixes
+}
+
+// fixSparkApplicationStatus records the generation of applications processed by earlier versions of the
+// operator, which did not record the generation they observed, as observed.
+func fixSparkApplicationStatus(app *v1beta1.SparkApplication) bool {
+	if app.Status.AppState.State == v1beta1.NewState || app.Status.ObservedGeneration != 0 || app.Generation == 0 {
+		return false
+	}
+	app.Status.ObservedGeneration = app.Generation
+	return true
+}
+
+// fixStateLabel sets the state label of the given application to its state, like the operator does on every
+// update of its status.
+func fixStateLabel(app *v1beta1.SparkApplication) bool {
+	state := string(app.Status.AppState.State)
+	if state == "" || app.Labels[config.SparkAppStateLabel] == state {
+		return false
+	}
+	if app.Labels == nil {
+		app.Labels = make(map[string]string)
+	}
+	app.Labels[config.SparkAppStateLabel] = state
+	return true
+}
+
+func fixDeprecatedRetries(spec *v1beta1.SparkApplicationSpec) bool {
+	fixed := false
+	if spec.FailureRetries != nil {
+		if spec.RestartPolicy.OnFailureRetries == nil {
+			spec.RestartPolicy.OnFailureRetries = spec.FailureRetries
+		}
+		spec.FailureRetries = nil
+		fixed = true
+	}
+	if spec.RetryInterval != nil {
+		if spec.RestartPolicy.OnFailureRetryInterval == nil {
+			spec.RestartPolicy.OnFailureRetryInterval = spec.RetryInterval
+		}
+		spec.RetryInterval = nil
+		fixed = true
+	}
+	return fixed
+}
+
+func fixDefaults(spec *v1beta1.SparkApplicationSpec) bool {
+	defaulted := &v1beta1.SparkApplication{Spec: *spec.DeepCopy()}
+	v1beta1.SetSparkApplicationDefaults(defaulted)
+	if reflect.DeepEqual(defaulted.Spec, *spec) {
+		return false
+	}
+	*spec = defaulted.Spec
+	return true
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	ssacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/scheduledsparkapplication"
+	sacrd "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/crd/sparkapplication"
+)
+
+func int32ptr(n int32) *int32 {
+	return &n
+}
+
+func int64ptr(n int64) *int64 {
+	return &n
+}
+
+func TestFixDeprecatedRetries(t *testing.T) {
+	spec := &v1beta1.SparkApplicationSpec{FailureRetries: int32ptr(3), RetryInterval: int64ptr(10)}
+	assert.True(t, fixDeprecatedRetries(spec))
+	assert.Nil(t, spec.FailureRetries)
+	assert.Nil(t, spec.RetryInterval)
+	assert.Equal(t, int32(3), *spec.RestartPolicy.OnFailureRetries)
+	assert.Equal(t, int64(10), *spec.RestartPolicy.OnFailureRetryInterval)
+
+	// The restart policy takes precedence over the deprecated fields.
+	spec = &v1beta1.SparkApplicationSpec{
+		FailureRetries: int32ptr(3),
+		RestartPolicy:  v1beta1.RestartPolicy{OnFailureRetries: int32ptr(1)},
+	}
+	assert.True(t, fixDeprecatedRetries(spec))
+	assert.Nil(t, spec.FailureRetries)
+	assert.Equal(t, int32(1), *spec.RestartPolicy.OnFailureRetries)
+
+	assert.False(t, fixDeprecatedRetries(spec))
+}
+
+func TestFixSparkApplication(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       v1beta1.SparkApplicationSpec{FailureRetries: int32ptr(3)},
+		Status:     v1beta1.SparkApplicationStatus{AppState: v1beta1.ApplicationState{State: v1beta1.CompletedState}},
+	}
+	assert.Equal(t, []string{DeprecatedRetriesFix, DefaultsFix, StateLabelFix, ObservedGenerationFix},
+		fixSparkApplication(app))
+	assert.Equal(t, "COMPLETED", app.Labels[config.SparkAppStateLabel])
+	assert.Equal(t, v1beta1.ClusterMode, app.Spec.Mode)
+	assert.Equal(t, v1beta1.Never, app.Spec.RestartPolicy.Type)
+	assert.Equal(t, int64(2), app.Status.ObservedGeneration)
+
+	assert.Nil(t, fixSparkApplication(app))
+
+	// Applications not processed yet are left to the controller.
+	app = &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       v1beta1.SparkApplicationSpec{Mode: v1beta1.ClusterMode},
+	}
+	app.Spec.RestartPolicy.Type = v1beta1.Never
+	assert.Nil(t, fixSparkApplication(app))
+}
+
+func newCRD(name string, storedVersions ...string) *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     apiextensionsv1beta1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+// newFakeClientset returns a fake clientset with the given objects created through it, which also lists them by
+// resource, as the object tracker of the generated fake clientset cannot list objects of the API group of the
+// operator. The other resources are not installed.
+func newFakeClientset(t *testing.T, app *v1beta1.SparkApplication,
+	scheduledApp *v1beta1.ScheduledSparkApplication) *crdclientfake.Clientset {
+	client := crdclientfake.NewSimpleClientset()
+	client.PrependReactor("list", "*", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		switch action.GetResource().Resource {
+		case sacrd.Plural:
+			return true, &v1beta1.SparkApplicationList{Items: []v1beta1.SparkApplication{*app.DeepCopy()}}, nil
+		case ssacrd.Plural:
+			return true, &v1beta1.ScheduledSparkApplicationList{
+				Items: []v1beta1.ScheduledSparkApplication{*scheduledApp.DeepCopy()}}, nil
+		}
+		return true, nil, errors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+	if _, err := client.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SparkoperatorV1beta1().ScheduledSparkApplications(scheduledApp.Namespace).Create(
+		scheduledApp); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRun(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Generation: 2},
+		Spec:       v1beta1.SparkApplicationSpec{FailureRetries: int32ptr(3)},
+		Status:     v1beta1.SparkApplicationStatus{AppState: v1beta1.ApplicationState{State: v1beta1.RunningState}},
+	}
+	scheduledApp := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"},
+		Spec: v1beta1.ScheduledSparkApplicationSpec{
+			Template: v1beta1.SparkApplicationSpec{
+				Mode:          v1beta1.ClusterMode,
+				RestartPolicy: v1beta1.RestartPolicy{Type: v1beta1.Never},
+			},
+		},
+	}
+	apiExtensionsClient := apiextensionsfake.NewSimpleClientset(
+		newCRD(sacrd.FullName, "v1alpha1", v1beta1.Version),
+		newCRD(ssacrd.FullName, v1beta1.Version))
+
+	// A dry run does not write anything.
+	client := newFakeClientset(t, app, scheduledApp)
+	summary := New(client, apiExtensionsClient, "", true).Run()
+	assert.True(t, summary.DryRun)
+	assert.Equal(t, 1, summary.Resources[0].Rewritten)
+	for _, action := range append(client.Actions(), apiExtensionsClient.Actions()...) {
+		assert.NotEqual(t, "update", action.GetVerb())
+	}
+
+	client = newFakeClientset(t, app, scheduledApp)
+	summary = New(client, apiExtensionsClient, "", false).Run()
+	assert.Equal(t, 0, summary.Failed())
+
+	applications := summary.Resources[0]
+	assert.Equal(t, sacrd.Plural, applications.Resource)
+	assert.True(t, applications.StorageRewrite)
+	assert.Equal(t, 1, applications.Scanned)
+	assert.Equal(t, 1, applications.Rewritten)
+	assert.Equal(t, map[string]int{DeprecatedRetriesFix: 1, DefaultsFix: 1, StateLabelFix: 1,
+		ObservedGenerationFix: 1}, applications.Fixes)
+
+	// Objects of resources stored in the latest version alone are only rewritten if they need fixes.
+	scheduledApplications := summary.Resources[1]
+	assert.False(t, scheduledApplications.StorageRewrite)
+	assert.Equal(t, 1, scheduledApplications.Scanned)
+	assert.Equal(t, 0, scheduledApplications.Rewritten)
+
+	migrated, err := client.SparkoperatorV1beta1().SparkApplications("default").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, migrated.Spec.FailureRetries)
+	assert.Equal(t, int32(3), *migrated.Spec.RestartPolicy.OnFailureRetries)
+	assert.Equal(t, int64(2), migrated.Status.ObservedGeneration)
+	assert.Equal(t, "RUNNING", migrated.Labels[config.SparkAppStateLabel])
+
+	crd, err := apiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(sacrd.FullName,
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{v1beta1.Version}, crd.Status.StoredVersions)
+
+	var out bytes.Buffer
+	summary.Print(&out)
+	assert.Contains(t, out.String(),
+		"storage-version, defaults=1, deprecated-retries=1, observed-generation=1, state-label=1")
+}
+
+func TestRunInNamespace(t *testing.T) {
+	summary := New(crdclientfake.NewSimpleClientset(), nil, "default", false).Run()
+	for _, resource := range summary.Resources {
+		// Without an apiextensions client, all objects are rewritten.
+		assert.True(t, resource.StorageRewrite)
+	}
+	assert.Equal(t, "cluster-scoped", summary.Resources[len(summary.Resources)-1].Skipped)
+}
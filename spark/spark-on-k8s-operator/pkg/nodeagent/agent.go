@@ -0,0 +1,118 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// Agent runs on every node and periodically records the cgroup v2 CPU throttling and pressure stall information
+// of the Spark executor pods running on the node in their ResourcePressureAnnotation, from which the operator
+// summarizes the resource pressure of each application.
+type Agent struct {
+	kubeClient kubernetes.Interface
+	nodeName   string
+	cgroupRoot string
+	interval   time.Duration
+}
+
+// New creates an Agent for the node with the given name, reading the cgroup hierarchy mounted at the given root.
+func New(kubeClient kubernetes.Interface, nodeName, cgroupRoot string, interval time.Duration) (*Agent, error) {
+	if nodeName == "" {
+		return nil, fmt.Errorf("the node name must be set")
+	}
+	if !isCgroupV2(cgroupRoot) {
+		return nil, fmt.Errorf("%s is not a cgroup v2 hierarchy", cgroupRoot)
+	}
+	return &Agent{
+		kubeClient: kubeClient,
+		nodeName:   nodeName,
+		cgroupRoot: cgroupRoot,
+		interval:   interval,
+	}, nil
+}
+
+// Run records samples until the given channel is closed.
+func (a *Agent) Run(stopCh <-chan struct{}) {
+	glog.Infof("Recording the resource pressure of Spark executors on node %s every %v", a.nodeName, a.interval)
+	wait.Until(a.recordSamples, a.interval, stopCh)
+}
+
+func (a *Agent) recordSamples() {
+	pods, err := a.kubeClient.CoreV1().Pods(apiv1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			config.LaunchedBySparkOperatorLabel: "true",
+			config.SparkRoleLabel:               config.SparkExecutorRole,
+		}).String(),
+		FieldSelector: fields.SelectorFromSet(fields.Set{
+			"spec.nodeName": a.nodeName,
+			"status.phase":  string(apiv1.PodRunning),
+		}).String(),
+	})
+	if err != nil {
+		glog.Errorf("failed to list the Spark executor pods on node %s: %v", a.nodeName, err)
+		return
+	}
+	now := time.Now()
+	for i := range pods.Items {
+		if err := a.recordSample(&pods.Items[i], now); err != nil {
+			glog.Warningf("failed to record the resource pressure of pod %s/%s: %v", pods.Items[i].Namespace,
+				pods.Items[i].Name, err)
+		}
+	}
+}
+
+func (a *Agent) recordSample(pod *apiv1.Pod, now time.Time) error {
+	if pod.Status.StartTime == nil {
+		return nil
+	}
+	dir, err := findPodCgroup(a.cgroupRoot, pod.UID)
+	if err != nil {
+		return err
+	}
+	sample, err := readSample(dir, pod.Status.StartTime.Time, now)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{config.ResourcePressureAnnotation: string(value)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.kubeClient.CoreV1().Pods(pod.Namespace).Patch(pod.Name, types.MergePatchType, patch)
+	return err
+}
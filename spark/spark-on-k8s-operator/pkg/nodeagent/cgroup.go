@@ -0,0 +1,152 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// maxPodCgroupDepth is the maximum depth below the cgroup root of the cgroups of pods, e.g.,
+// kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice.
+const maxPodCgroupDepth = 3
+
+// isCgroupV2 tells if the cgroup hierarchy at the given root is a cgroup v2 unified hierarchy.
+func isCgroupV2(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+	return err == nil
+}
+
+// findPodCgroup returns the directory of the cgroup of the pod with the given UID below the given cgroup root.
+// Pod cgroups are named "pod<uid>" by the cgroupfs driver and "kubepods-<qos>-pod<uid>.slice", with the dashes
+// of the UID replaced by underscores, by the systemd driver.
+func findPodCgroup(root string, uid types.UID) (string, error) {
+	names := []string{"pod" + string(uid), "pod" + strings.Replace(string(uid), "-", "_", -1)}
+	var found string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		// Unreadable directories are skipped.
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if found != "" {
+			return filepath.SkipDir
+		}
+		relative, _ := filepath.Rel(root, path)
+		if relative == "." {
+			return nil
+		}
+		depth := len(strings.Split(relative, string(filepath.Separator)))
+		if !strings.HasPrefix(relative, "kubepods") || depth > maxPodCgroupDepth {
+			return filepath.SkipDir
+		}
+		base := strings.TrimSuffix(info.Name(), ".slice")
+		for _, name := range names {
+			if strings.HasSuffix(base, name) {
+				found = path
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("no cgroup found for pod %s", uid)
+	}
+	return found, nil
+}
+
+// parseFlatKeyed parses the content of a flat keyed cgroup file, e.g., cpu.stat, of "<key> <value>" lines.
+func parseFlatKeyed(content string) map[string]int64 {
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+	return values
+}
+
+// parsePressureTotal returns the total stall time in microseconds of the "some" line of the content of a PSI
+// file, e.g., cpu.pressure, which looks like "some avg10=0.00 avg60=0.00 avg300=0.00 total=12345".
+func parsePressureTotal(content string) (int64, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "total=") {
+				return strconv.ParseInt(strings.TrimPrefix(field, "total="), 10, 64)
+			}
+		}
+	}
+	return 0, fmt.Errorf("no total stall time of some tasks found in %q", content)
+}
+
+func readFile(dir, name string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, name))
+	return string(content), err
+}
+
+// readSample reads the CPU throttling and pressure stall information of the pod cgroup in the given directory.
+func readSample(dir string, podStart, now time.Time) (*config.ResourcePressureSample, error) {
+	cpuStat, err := readFile(dir, "cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	cpuPressure, err := readFile(dir, "cpu.pressure")
+	if err != nil {
+		return nil, err
+	}
+	memoryPressure, err := readFile(dir, "memory.pressure")
+	if err != nil {
+		return nil, err
+	}
+
+	stat := parseFlatKeyed(cpuStat)
+	sample := &config.ResourcePressureSample{
+		SampleTime:          now.UTC().Truncate(time.Second),
+		ElapsedMicros:       int64(now.Sub(podStart) / time.Microsecond),
+		CPUPeriods:          stat["nr_periods"],
+		CPUThrottledPeriods: stat["nr_throttled"],
+	}
+	if sample.CPUPressureMicros, err = parsePressureTotal(cpuPressure); err != nil {
+		return nil, err
+	}
+	if sample.MemoryPressureMicros, err = parsePressureTotal(memoryPressure); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlatKeyed(t *testing.T) {
+	values := parseFlatKeyed("usage_usec 1000\nnr_periods 20\nnr_throttled 5\ninvalid\nthrottled_usec x\n")
+	assert.Equal(t, map[string]int64{"usage_usec": 1000, "nr_periods": 20, "nr_throttled": 5}, values)
+}
+
+func TestParsePressureTotal(t *testing.T) {
+	total, err := parsePressureTotal("some avg10=0.00 avg60=1.00 avg300=0.50 total=12345\n" +
+		"full avg10=0.00 avg60=0.00 avg300=0.00 total=678\n")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), total)
+
+	_, err = parsePressureTotal("full avg10=0.00 avg60=0.00 avg300=0.00 total=678\n")
+	assert.Error(t, err)
+}
+
+func TestFindPodCgroupAndReadSample(t *testing.T) {
+	root, err := ioutil.TempDir("", "spark-node-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	podDir := filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice",
+		"kubepods-burstable-pod1234_abcd.slice")
+	if err := os.MkdirAll(filepath.Join(podDir, "cri-containerd-0.scope"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "system.slice", "pod1234_abcd.slice"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"cpu.stat":        "usage_usec 1000\nnr_periods 20\nnr_throttled 5\n",
+		"cpu.pressure":    "some avg10=0.00 avg60=0.00 avg300=0.00 total=300\nfull avg10=0.00 total=100\n",
+		"memory.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=50\nfull avg10=0.00 total=10\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(podDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.False(t, isCgroupV2(root))
+	if err := ioutil.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu memory"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, isCgroupV2(root))
+
+	dir, err := findPodCgroup(root, "1234-abcd")
+	assert.NoError(t, err)
+	assert.Equal(t, podDir, dir)
+	_, err = findPodCgroup(root, "5678-efgh")
+	assert.Error(t, err)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sample, err := readSample(dir, start, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(time.Minute), sample.SampleTime)
+	assert.Equal(t, int64(60000000), sample.ElapsedMicros)
+	assert.Equal(t, int64(20), sample.CPUPeriods)
+	assert.Equal(t, int64(5), sample.CPUThrottledPeriods)
+	assert.Equal(t, int64(300), sample.CPUPressureMicros)
+	assert.Equal(t, int64(50), sample.MemoryPressureMicros)
+
+	_, err = readSample(filepath.Join(root, "system.slice"), start, start)
+	assert.Error(t, err)
+}
@@ -0,0 +1,329 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// DefaultTemplate is the template of notification messages used unless a route, an application or the
+// configuration sets another one.
+const DefaultTemplate = `SparkApplication {{.App.Namespace}}/{{.App.Name}}: {{.Event}}` +
+	`{{with .App.Status.AppState.ErrorMessage}}: {{.}}{{end}}`
+
+// Config configures the notification sinks of the operator and the routes of notifications to them.
+type Config struct {
+	// Sinks are the notification sinks, which applications refer to by name.
+	Sinks []SinkConfig `json:"sinks"`
+	// Routes route the notifications about applications to sinks.
+	Routes []RouteConfig `json:"routes,omitempty"`
+	// Template is the template of notification messages.
+	// Optional. Defaults to DefaultTemplate.
+	Template string `json:"template,omitempty"`
+}
+
+// SinkConfig configures a notification sink. Exactly one of Slack, PagerDuty, Email and Webhook must be set.
+type SinkConfig struct {
+	Name      string           `json:"name"`
+	Slack     *SlackConfig     `json:"slack,omitempty"`
+	PagerDuty *PagerDutyConfig `json:"pagerDuty,omitempty"`
+	Email     *EmailConfig     `json:"email,omitempty"`
+	Webhook   *WebhookConfig   `json:"webhook,omitempty"`
+}
+
+// RouteConfig routes the notifications about the applications matching it to sinks.
+type RouteConfig struct {
+	// Sinks are the names of the sinks the notifications are sent to.
+	Sinks []string `json:"sinks"`
+	// Namespaces are the namespaces of the applications matching the route.
+	// Optional. Defaults to all namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector is a label selector of the applications matching the route, e.g., "team=a,tier!=dev".
+	// Optional. Defaults to all applications.
+	Selector string `json:"selector,omitempty"`
+	// Events are the events routed.
+	// Optional. Defaults to all events but Completed.
+	Events []v1beta1.NotificationEvent `json:"events,omitempty"`
+	// Template is the template of the messages sent by the route.
+	// Optional. Defaults to the template of the configuration.
+	Template string `json:"template,omitempty"`
+}
+
+// Message is what notification templates are executed with.
+type Message struct {
+	Event     v1beta1.NotificationEvent
+	App       *v1beta1.SparkApplication
+	Artifacts *ArtifactManifest
+}
+
+// ArtifactManifest describes the output of the current run of an application, so that downstream systems can
+// ingest it without probing storage.
+type ArtifactManifest struct {
+	// SparkApplicationID is the ID Spark assigned to the run.
+	SparkApplicationID string `json:"sparkApplicationId,omitempty"`
+	// OutputPaths are the paths the application declares it writes its output to.
+	OutputPaths []string `json:"outputPaths,omitempty"`
+	// BytesWritten and RecordsWritten are the output written by the run as last reported by the driver.
+	BytesWritten   int64 `json:"bytesWritten"`
+	RecordsWritten int64 `json:"recordsWritten"`
+	// TerminationTime is the time the run terminated, if it has.
+	TerminationTime *metav1.Time `json:"terminationTime,omitempty"`
+}
+
+// newArtifactManifest returns the artifact manifest of the current run of the given application.
+func newArtifactManifest(app *v1beta1.SparkApplication) *ArtifactManifest {
+	manifest := &ArtifactManifest{SparkApplicationID: app.Status.SparkApplicationID}
+	if app.Spec.Notifications != nil && len(app.Spec.Notifications.OutputPaths) > 0 {
+		manifest.OutputPaths = app.Spec.Notifications.OutputPaths
+	} else if app.Spec.OutputCleanup != nil {
+		manifest.OutputPaths = app.Spec.OutputCleanup.OutputPaths
+	}
+	if progress := app.Status.Progress; progress != nil {
+		manifest.BytesWritten = progress.OutputBytes
+		manifest.RecordsWritten = progress.OutputRecords
+	}
+	if !app.Status.TerminationTime.IsZero() {
+		terminationTime := app.Status.TerminationTime
+		manifest.TerminationTime = &terminationTime
+	}
+	return manifest
+}
+
+// Sink sends notification messages somewhere.
+type Sink interface {
+	Send(msg *Message, text string) error
+}
+
+type route struct {
+	sinks      []string
+	namespaces []string
+	selector   labels.Selector
+	events     []v1beta1.NotificationEvent
+	template   *template.Template
+}
+
+// Notifier sends notifications about applications to the sinks routed to by the configuration of the operator
+// and the sinks named by the applications.
+type Notifier struct {
+	sinks    map[string]Sink
+	routes   []route
+	template *template.Template
+}
+
+// LoadConfig reads a YAML or JSON notification configuration from the file with the given path.
+func LoadConfig(path string) (*Config, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the notification configuration from %s: %v", path, err)
+	}
+	var config Config
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the notification configuration from %s: %v", path, err)
+	}
+	return &config, nil
+}
+
+// NewNotifier creates a new Notifier with the sinks and routes of the given configuration.
+func NewNotifier(config *Config) (*Notifier, error) {
+	text := config.Template
+	if text == "" {
+		text = DefaultTemplate
+	}
+	defaultTemplate, err := template.New("default").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification template: %v", err)
+	}
+	notifier := &Notifier{sinks: make(map[string]Sink), template: defaultTemplate}
+
+	for _, sinkConfig := range config.Sinks {
+		if sinkConfig.Name == "" {
+			return nil, fmt.Errorf("every notification sink must have a name")
+		}
+		if _, ok := notifier.sinks[sinkConfig.Name]; ok {
+			return nil, fmt.Errorf("duplicate notification sink %s", sinkConfig.Name)
+		}
+		sink, err := newSink(sinkConfig)
+		if err != nil {
+			return nil, err
+		}
+		notifier.sinks[sinkConfig.Name] = sink
+	}
+
+	for i, routeConfig := range config.Routes {
+		r := route{
+			sinks:      routeConfig.Sinks,
+			namespaces: routeConfig.Namespaces,
+			events:     routeConfig.Events,
+			template:   defaultTemplate,
+			selector:   labels.Everything(),
+		}
+		for _, name := range r.sinks {
+			if _, ok := notifier.sinks[name]; !ok {
+				return nil, fmt.Errorf("notification route %d refers to unknown sink %s", i, name)
+			}
+		}
+		if routeConfig.Selector != "" {
+			if r.selector, err = labels.Parse(routeConfig.Selector); err != nil {
+				return nil, fmt.Errorf("invalid selector of notification route %d: %v", i, err)
+			}
+		}
+		if routeConfig.Template != "" {
+			if r.template, err = template.New(fmt.Sprintf("route-%d", i)).Parse(routeConfig.Template); err != nil {
+				return nil, fmt.Errorf("invalid template of notification route %d: %v", i, err)
+			}
+		}
+		notifier.routes = append(notifier.routes, r)
+	}
+	return notifier, nil
+}
+
+func newSink(config SinkConfig) (Sink, error) {
+	var sinks []Sink
+	var err error
+	if config.Slack != nil {
+		var sink *slackSink
+		if sink, err = newSlackSink(config.Slack); err == nil {
+			sinks = append(sinks, sink)
+		}
+	}
+	if config.PagerDuty != nil && err == nil {
+		var sink *pagerDutySink
+		if sink, err = newPagerDutySink(config.PagerDuty); err == nil {
+			sinks = append(sinks, sink)
+		}
+	}
+	if config.Email != nil && err == nil {
+		var sink *emailSink
+		if sink, err = newEmailSink(config.Email); err == nil {
+			sinks = append(sinks, sink)
+		}
+	}
+	if config.Webhook != nil && err == nil {
+		var sink *webhookSink
+		if sink, err = newWebhookSink(config.Webhook); err == nil {
+			sinks = append(sinks, sink)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification sink %s: %v", config.Name, err)
+	}
+	if len(sinks) != 1 {
+		return nil, fmt.Errorf("notification sink %s must have exactly one of slack, pagerDuty, email and webhook", config.Name)
+	}
+	return sinks[0], nil
+}
+
+// Notify sends a notification of the given event of the given application to every sink a matching route or
+// the application refers to. Each sink is sent at most one notification.
+func (n *Notifier) Notify(app *v1beta1.SparkApplication, event v1beta1.NotificationEvent) error {
+	msg := &Message{Event: event, App: app, Artifacts: newArtifactManifest(app)}
+	// The template of the first route or of the application naming a sink is used for it.
+	templates := make(map[string]*template.Template)
+	var order []string
+	add := func(name string, tmpl *template.Template) {
+		if _, ok := templates[name]; !ok {
+			templates[name] = tmpl
+			order = append(order, name)
+		}
+	}
+
+	for _, r := range n.routes {
+		if r.matches(app, event) {
+			for _, name := range r.sinks {
+				add(name, r.template)
+			}
+		}
+	}
+	if spec := app.Spec.Notifications; spec != nil && matchesEvent(spec.Events, event) {
+		tmpl := n.template
+		if spec.Template != nil {
+			var err error
+			if tmpl, err = template.New("app").Parse(*spec.Template); err != nil {
+				return fmt.Errorf("invalid notification template of SparkApplication %s/%s: %v",
+					app.Namespace, app.Name, err)
+			}
+		}
+		for _, name := range spec.Sinks {
+			add(name, tmpl)
+		}
+	}
+
+	var errs []string
+	for _, name := range order {
+		sink, ok := n.sinks[name]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown notification sink %s", name))
+			continue
+		}
+		var text bytes.Buffer
+		if err := templates[name].Execute(&text, msg); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to render the notification for sink %s: %v", name, err))
+			continue
+		}
+		if err := sink.Send(msg, text.String()); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to send the notification to sink %s: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to notify %s of SparkApplication %s/%s: %s", event, app.Namespace, app.Name,
+			strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (r *route) matches(app *v1beta1.SparkApplication, event v1beta1.NotificationEvent) bool {
+	if !matchesEvent(r.events, event) {
+		return false
+	}
+	if len(r.namespaces) > 0 {
+		found := false
+		for _, namespace := range r.namespaces {
+			if namespace == app.Namespace {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.selector.Matches(labels.Set(app.Labels))
+}
+
+// matchesEvent tells if the given event is among the given events. Running out of retries is a failure, so it
+// matches Failed as well. No events match all events but Completed, which would be noisy for existing routes.
+func matchesEvent(events []v1beta1.NotificationEvent, event v1beta1.NotificationEvent) bool {
+	if len(events) == 0 {
+		return event != v1beta1.CompletedNotificationEvent
+	}
+	for _, e := range events {
+		if e == event || (e == v1beta1.FailedNotificationEvent && event == v1beta1.RetriesExhaustedNotificationEvent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+type fakeSink struct {
+	texts []string
+}
+
+func (s *fakeSink) Send(msg *Message, text string) error {
+	s.texts = append(s.texts, text)
+	return nil
+}
+
+func TestNewNotifier_Invalid(t *testing.T) {
+	slack := &SlackConfig{WebhookURL: "https://hooks.slack.com/services/x"}
+	for _, config := range []*Config{
+		{Sinks: []SinkConfig{{Slack: slack}}},
+		{Sinks: []SinkConfig{{Name: "a", Slack: slack}, {Name: "a", Slack: slack}}},
+		{Sinks: []SinkConfig{{Name: "a"}}},
+		{Sinks: []SinkConfig{{Name: "a", Slack: slack, PagerDuty: &PagerDutyConfig{RoutingKey: "key"}}}},
+		{Sinks: []SinkConfig{{Name: "a", Slack: &SlackConfig{}}}},
+		{Sinks: []SinkConfig{{Name: "a", Email: &EmailConfig{From: "spark@example.com"}}}},
+		{Sinks: []SinkConfig{{Name: "a", Webhook: &WebhookConfig{}}}},
+		{Sinks: []SinkConfig{{Name: "a", Slack: slack}}, Routes: []RouteConfig{{Sinks: []string{"b"}}}},
+		{Sinks: []SinkConfig{{Name: "a", Slack: slack}}, Routes: []RouteConfig{{Sinks: []string{"a"}, Selector: "team in"}}},
+		{Sinks: []SinkConfig{{Name: "a", Slack: slack}}, Template: "{{.App"},
+	} {
+		_, err := NewNotifier(config)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	slack := &SlackConfig{WebhookURL: "https://hooks.slack.com/services/x"}
+	notifier, err := NewNotifier(&Config{
+		Sinks: []SinkConfig{
+			{Name: "team-a", Slack: slack},
+			{Name: "oncall", Slack: slack},
+			{Name: "own", Slack: slack},
+		},
+		Routes: []RouteConfig{
+			{Sinks: []string{"team-a"}, Namespaces: []string{"team-a"}},
+			{
+				Sinks:    []string{"oncall", "team-a"},
+				Selector: "tier=prod",
+				Events:   []v1beta1.NotificationEvent{v1beta1.FailedNotificationEvent},
+				Template: "{{.Event}} {{.App.Name}}",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	teamA := &fakeSink{}
+	oncall := &fakeSink{}
+	own := &fakeSink{}
+	notifier.sinks = map[string]Sink{"team-a": teamA, "oncall": oncall, "own": own}
+
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "team-a", Labels: map[string]string{"tier": "prod"}},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.FailedState, ErrorMessage: "driver OOMKilled"},
+		},
+	}
+	assert.Nil(t, notifier.Notify(app, v1beta1.RetriesExhaustedNotificationEvent))
+	assert.Equal(t, []string{"SparkApplication team-a/foo: RetriesExhausted: driver OOMKilled"}, teamA.texts)
+	assert.Equal(t, []string{"RetriesExhausted foo"}, oncall.texts)
+
+	// The oncall route only routes failures.
+	assert.Nil(t, notifier.Notify(app, v1beta1.SLABreachedNotificationEvent))
+	assert.Equal(t, 2, len(teamA.texts))
+	assert.Equal(t, 1, len(oncall.texts))
+
+	// Completions are only routed if listed explicitly.
+	assert.Nil(t, notifier.Notify(app, v1beta1.CompletedNotificationEvent))
+	assert.Equal(t, 2, len(teamA.texts))
+
+	// Applications add their own sinks and template.
+	app.Namespace = "team-b"
+	template := "{{.App.Name}} is late"
+	app.Spec.Notifications = &v1beta1.NotificationSpec{
+		Sinks:    []string{"own"},
+		Events:   []v1beta1.NotificationEvent{v1beta1.SLABreachedNotificationEvent},
+		Template: &template,
+	}
+	assert.Nil(t, notifier.Notify(app, v1beta1.SLABreachedNotificationEvent))
+	assert.Equal(t, []string{"foo is late"}, own.texts)
+	assert.Equal(t, 2, len(teamA.texts))
+
+	app.Spec.Notifications.Sinks = []string{"unknown"}
+	assert.NotNil(t, notifier.Notify(app, v1beta1.SLABreachedNotificationEvent))
+}
+
+func TestSlackAndPagerDutySinks(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(&Config{
+		Sinks: []SinkConfig{
+			{Name: "slack", Slack: &SlackConfig{WebhookURL: server.URL}},
+			{Name: "pagerduty", PagerDuty: &PagerDutyConfig{RoutingKey: "key", URL: server.URL}},
+		},
+		Routes: []RouteConfig{{Sinks: []string{"slack", "pagerduty"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "uid-1"},
+	}
+	assert.Nil(t, notifier.Notify(app, v1beta1.FailedNotificationEvent))
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, "SparkApplication default/foo: Failed", requests[0]["text"])
+	assert.Equal(t, "key", requests[1]["routing_key"])
+	assert.Equal(t, "trigger", requests[1]["event_action"])
+	assert.Equal(t, "default/foo/uid-1/Failed", requests[1]["dedup_key"])
+	payload := requests[1]["payload"].(map[string]interface{})
+	assert.Equal(t, "SparkApplication default/foo: Failed", payload["summary"])
+	assert.Equal(t, "error", payload["severity"])
+}
+
+func TestWebhookSink(t *testing.T) {
+	var payload map[string]interface{}
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(&Config{
+		Sinks: []SinkConfig{{Name: "ingest", Webhook: &WebhookConfig{
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+		}}},
+		Routes: []RouteConfig{{
+			Sinks:  []string{"ingest"},
+			Events: []v1beta1.NotificationEvent{v1beta1.CompletedNotificationEvent},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "uid-1"},
+		Spec: v1beta1.SparkApplicationSpec{
+			OutputCleanup: &v1beta1.OutputCleanupSpec{OutputPaths: []string{"s3a://bucket/table"}},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			SparkApplicationID: "spark-123",
+			AppState:           v1beta1.ApplicationState{State: v1beta1.CompletedState},
+			TerminationTime:    metav1.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+			Progress:           &v1beta1.ApplicationProgress{OutputBytes: 5120, OutputRecords: 50},
+		},
+	}
+	assert.Nil(t, notifier.Notify(app, v1beta1.CompletedNotificationEvent))
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Equal(t, "Completed", payload["event"])
+	assert.Equal(t, "default", payload["namespace"])
+	assert.Equal(t, "foo", payload["name"])
+	assert.Equal(t, "uid-1", payload["uid"])
+	assert.Equal(t, "COMPLETED", payload["state"])
+	assert.Equal(t, "SparkApplication default/foo: Completed", payload["text"])
+	assert.Equal(t, map[string]interface{}{
+		"sparkApplicationId": "spark-123",
+		"outputPaths":        []interface{}{"s3a://bucket/table"},
+		"bytesWritten":       float64(5120),
+		"recordsWritten":     float64(50),
+		"terminationTime":    "2019-01-02T03:04:05Z",
+	}, payload["artifacts"])
+}
+
+func TestNewArtifactManifest(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		Spec: v1beta1.SparkApplicationSpec{
+			OutputCleanup: &v1beta1.OutputCleanupSpec{OutputPaths: []string{"s3a://bucket/tmp"}},
+			Notifications: &v1beta1.NotificationSpec{OutputPaths: []string{"s3a://bucket/table"}},
+		},
+	}
+	manifest := newArtifactManifest(app)
+	assert.Equal(t, []string{"s3a://bucket/table"}, manifest.OutputPaths)
+	assert.Equal(t, int64(0), manifest.BytesWritten)
+	assert.Nil(t, manifest.TerminationTime)
+}
+
+func TestEmailSink(t *testing.T) {
+	defer func() { sendMail = smtp.SendMail }()
+	var addr, from string
+	var to []string
+	var body string
+	sendMail = func(a string, auth smtp.Auth, f string, t []string, msg []byte) error {
+		addr, from, to, body = a, f, t, string(msg)
+		return nil
+	}
+
+	sink, err := newEmailSink(&EmailConfig{
+		SMTPAddress: "smtp.example.com:587",
+		From:        "spark@example.com",
+		To:          []string{"team-a@example.com", "oncall@example.com"},
+		Username:    "spark",
+		Password:    "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &v1beta1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	assert.Nil(t, sink.Send(&Message{Event: v1beta1.FailedNotificationEvent, App: app}, "it failed"))
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "spark@example.com", from)
+	assert.Equal(t, []string{"team-a@example.com", "oncall@example.com"}, to)
+	assert.True(t, strings.Contains(body, "Subject: SparkApplication default/foo Failed\r\n"))
+	assert.True(t, strings.HasSuffix(body, "\r\n\r\nit failed\r\n"))
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const (
+	sendRequestTimeout = 5 * time.Second
+	// DefaultPagerDutyURL is the endpoint of the PagerDuty Events API v2.
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+	pagerDutySource     = "spark-operator"
+)
+
+var sendMail = smtp.SendMail
+
+// SlackConfig configures a sink posting messages to a Slack incoming webhook.
+type SlackConfig struct {
+	// WebhookURL is the URL of the incoming webhook.
+	WebhookURL string `json:"webhookURL"`
+}
+
+// PagerDutyConfig configures a sink triggering PagerDuty alerts through the Events API v2.
+type PagerDutyConfig struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string `json:"routingKey"`
+	// Severity is the severity of the alerts, one of "critical", "error", "warning" and "info".
+	// Optional. Defaults to "error".
+	Severity string `json:"severity,omitempty"`
+	// URL is the endpoint of the Events API.
+	// Optional. Defaults to DefaultPagerDutyURL.
+	URL string `json:"url,omitempty"`
+}
+
+// EmailConfig configures a sink sending emails through an SMTP server.
+type EmailConfig struct {
+	// SMTPAddress is the host and port of the SMTP server, e.g., "smtp.example.com:587".
+	SMTPAddress string `json:"smtpAddress"`
+	// From is the sender address.
+	From string `json:"from"`
+	// To are the recipient addresses.
+	To []string `json:"to"`
+	// Username and Password authenticate with the SMTP server if set.
+	// Optional.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// WebhookConfig configures a sink posting JSON payloads with the artifact manifest of applications to a URL.
+type WebhookConfig struct {
+	// URL is the URL the payloads are posted to.
+	URL string `json:"url"`
+	// Headers are extra HTTP headers of the requests, e.g., for authentication.
+	// Optional.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type slackSink struct {
+	url    string
+	client *http.Client
+}
+
+func newSlackSink(config *SlackConfig) (*slackSink, error) {
+	if config.WebhookURL == "" {
+		return nil, fmt.Errorf("slack requires webhookURL")
+	}
+	return &slackSink{url: config.WebhookURL, client: &http.Client{Timeout: sendRequestTimeout}}, nil
+}
+
+func (s *slackSink) Send(msg *Message, text string) error {
+	return postJSON(s.client, s.url, nil, map[string]string{"text": text})
+}
+
+type pagerDutySink struct {
+	url        string
+	routingKey string
+	severity   string
+	client     *http.Client
+}
+
+func newPagerDutySink(config *PagerDutyConfig) (*pagerDutySink, error) {
+	if config.RoutingKey == "" {
+		return nil, fmt.Errorf("pagerDuty requires routingKey")
+	}
+	sink := &pagerDutySink{
+		url:        config.URL,
+		routingKey: config.RoutingKey,
+		severity:   config.Severity,
+		client:     &http.Client{Timeout: sendRequestTimeout},
+	}
+	if sink.url == "" {
+		sink.url = DefaultPagerDutyURL
+	}
+	if sink.severity == "" {
+		sink.severity = "error"
+	}
+	return sink, nil
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component"`
+	Group         string            `json:"group"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (s *pagerDutySink) Send(msg *Message, text string) error {
+	app := msg.App
+	event := pagerDutyEvent{
+		RoutingKey:  s.routingKey,
+		EventAction: "trigger",
+		// Repeated notifications of the same event of an application are grouped into one alert.
+		DedupKey: fmt.Sprintf("%s/%s/%s/%s", app.Namespace, app.Name, app.UID, msg.Event),
+		Payload: pagerDutyPayload{
+			Summary:   text,
+			Source:    pagerDutySource,
+			Severity:  s.severity,
+			Component: app.Name,
+			Group:     app.Namespace,
+			Class:     string(msg.Event),
+			CustomDetails: map[string]string{
+				"state":        string(app.Status.AppState.State),
+				"errorMessage": app.Status.AppState.ErrorMessage,
+			},
+		},
+	}
+	return postJSON(s.client, s.url, nil, event)
+}
+
+type emailSink struct {
+	config *EmailConfig
+	auth   smtp.Auth
+}
+
+func newEmailSink(config *EmailConfig) (*emailSink, error) {
+	if config.SMTPAddress == "" || config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("email requires smtpAddress, from and to")
+	}
+	sink := &emailSink{config: config}
+	if config.Username != "" {
+		host, _, err := net.SplitHostPort(config.SMTPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smtpAddress %q: %v", config.SMTPAddress, err)
+		}
+		sink.auth = smtp.PlainAuth("", config.Username, config.Password, host)
+	}
+	return sink, nil
+}
+
+func (s *emailSink) Send(msg *Message, text string) error {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(s.config.To, ", "))
+	fmt.Fprintf(&body, "Subject: SparkApplication %s/%s %s\r\n", msg.App.Namespace, msg.App.Name, msg.Event)
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n", text)
+	return sendMail(s.config.SMTPAddress, s.auth, s.config.From, s.config.To, body.Bytes())
+}
+
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhookSink(config *WebhookConfig) (*webhookSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook requires url")
+	}
+	return &webhookSink{url: config.URL, headers: config.Headers, client: &http.Client{Timeout: sendRequestTimeout}}, nil
+}
+
+// webhookPayload is what webhook sinks post.
+type webhookPayload struct {
+	Event        v1beta1.NotificationEvent `json:"event"`
+	Namespace    string                    `json:"namespace"`
+	Name         string                    `json:"name"`
+	UID          string                    `json:"uid"`
+	State        string                    `json:"state"`
+	ErrorMessage string                    `json:"errorMessage,omitempty"`
+	Text         string                    `json:"text"`
+	Artifacts    *ArtifactManifest         `json:"artifacts,omitempty"`
+}
+
+func (s *webhookSink) Send(msg *Message, text string) error {
+	app := msg.App
+	return postJSON(s.client, s.url, s.headers, webhookPayload{
+		Event:        msg.Event,
+		Namespace:    app.Namespace,
+		Name:         app.Name,
+		UID:          string(app.UID),
+		State:        string(app.Status.AppState.State),
+		ErrorMessage: app.Status.AppState.ErrorMessage,
+		Text:         text,
+		Artifacts:    msg.Artifacts,
+	})
+}
+
+func postJSON(client *http.Client, url string, headers map[string]string, value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("got status %s", resp.Status)
+	}
+	return nil
+}
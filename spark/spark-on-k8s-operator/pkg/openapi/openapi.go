@@ -0,0 +1,320 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi generates OpenAPI v2 definitions of the custom resources of the operator from their Go
+// types, which client SDKs in other languages are generated from.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// Schema is the subset of an OpenAPI v2 schema object the definitions use.
+type Schema struct {
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	// ListType, ListMapKeys, and MapType tell server-side apply how to merge lists and maps.
+	ListType    string   `json:"x-kubernetes-list-type,omitempty"`
+	ListMapKeys []string `json:"x-kubernetes-list-map-keys,omitempty"`
+	MapType     string   `json:"x-kubernetes-map-type,omitempty"`
+}
+
+// Info describes an OpenAPI document.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Document is an OpenAPI v2 document with definitions only. The custom resources are served by the API server,
+// so the document has no paths.
+type Document struct {
+	Swagger     string                 `json:"swagger"`
+	Info        Info                   `json:"info"`
+	Paths       map[string]interface{} `json:"paths"`
+	Definitions map[string]*Schema     `json:"definitions"`
+}
+
+// Types serialized as strings by their JSON marshaler, with their formats.
+var stringFormats = map[string]string{
+	"k8s.io/apimachinery/pkg/apis/meta/v1.Time":       "date-time",
+	"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime":  "date-time",
+	"k8s.io/apimachinery/pkg/util/intstr.IntOrString": "int-or-string",
+	"k8s.io/apimachinery/pkg/api/resource.Quantity":   "quantity",
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generator generates the definitions of Go types and of the types they refer to. Definitions are named
+// "<version>.<type>", e.g., "v1beta1.SparkApplication", like the models of the Kubernetes client SDKs.
+// Fields are not marked required, as the generated SDKs would then reject objects the API server accepts.
+// The +listType, +listMapKey, and +mapType markers of fields are turned into the corresponding extensions.
+type Generator struct {
+	definitions  map[string]*Schema
+	packages     map[string]string
+	descriptions map[string]string
+	markers      map[string][]string
+}
+
+// NewGenerator creates a new Generator without definitions.
+func NewGenerator() *Generator {
+	return &Generator{
+		definitions:  make(map[string]*Schema),
+		packages:     make(map[string]string),
+		descriptions: make(map[string]string),
+		markers:      make(map[string][]string),
+	}
+}
+
+// LoadDescriptions reads the doc comments of the types and fields of the Go package with the given import
+// path from its source in the given directory, which are used as the descriptions of their definitions.
+func (g *Generator) LoadDescriptions(pkgPath string, dir string) error {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse the package in %s: %v", dir, err)
+	}
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		for _, t := range doc.New(pkg, pkgPath, 0).Types {
+			g.descriptions[pkgPath+"."+t.Name] = cleanDescription(t.Doc)
+			for _, spec := range t.Decl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok || typeSpec.Name.Name != t.Name {
+					continue
+				}
+				for _, field := range structType.Fields.List {
+					for _, fieldName := range field.Names {
+						key := pkgPath + "." + t.Name + "." + fieldName.Name
+						g.descriptions[key] = cleanDescription(field.Doc.Text())
+						g.markers[key] = getMarkers(field.Doc.Text())
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getMarkers returns the code generation markers in a doc comment, e.g., "+listType=map".
+func getMarkers(text string) []string {
+	var markers []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "+") {
+			markers = append(markers, line[1:])
+		}
+	}
+	return markers
+}
+
+// applyMarkers sets the server-side apply extensions of the given property from the given markers.
+func applyMarkers(property *Schema, markers []string) {
+	for _, marker := range markers {
+		i := strings.Index(marker, "=")
+		if i < 0 {
+			continue
+		}
+		switch name, value := marker[:i], marker[i+1:]; name {
+		case "listType":
+			property.ListType = value
+		case "listMapKey":
+			property.ListMapKeys = append(property.ListMapKeys, value)
+		case "mapType":
+			property.MapType = value
+		}
+	}
+}
+
+// cleanDescription turns a doc comment into a description, dropping code generation tags.
+func cleanDescription(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.HasPrefix(line, "+") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
+
+// Add adds the definitions of the types of the given objects and of the types they refer to.
+func (g *Generator) Add(objs ...interface{}) error {
+	for _, obj := range objs {
+		if _, err := g.schemaOf(reflect.TypeOf(obj)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Document returns an OpenAPI document with the definitions added so far.
+func (g *Generator) Document(title string, version string) *Document {
+	return &Document{
+		Swagger:     "2.0",
+		Info:        Info{Title: title, Version: version},
+		Paths:       map[string]interface{}{},
+		Definitions: g.definitions,
+	}
+}
+
+// DefinitionName returns the name of the definition of the given named type.
+func DefinitionName(t reflect.Type) string {
+	pkgPath := t.PkgPath()
+	return pkgPath[strings.LastIndex(pkgPath, "/")+1:] + "." + t.Name()
+}
+
+func (g *Generator) schemaOf(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		if format, ok := stringFormats[t.PkgPath()+"."+t.Name()]; ok {
+			return &Schema{Type: "string", Format: format}, nil
+		}
+		if t.Implements(jsonMarshaler) || reflect.PtrTo(t).Implements(jsonMarshaler) ||
+			t.Implements(textMarshaler) || reflect.PtrTo(t).Implements(textMarshaler) {
+			return &Schema{Type: "string"}, nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		return &Schema{Type: "object"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			return schema, g.addProperties(schema, t)
+		}
+		return g.refOf(t)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// refOf returns a reference to the definition of the given struct type, adding it if needed.
+func (g *Generator) refOf(t reflect.Type) (*Schema, error) {
+	name := DefinitionName(t)
+	ref := &Schema{Ref: "#/definitions/" + name}
+	if pkgPath, ok := g.packages[name]; ok {
+		if pkgPath != t.PkgPath() {
+			return nil, fmt.Errorf("definition %s is both %s.%s and %s.%s", name, pkgPath, t.Name(),
+				t.PkgPath(), t.Name())
+		}
+		return ref, nil
+	}
+	g.packages[name] = t.PkgPath()
+
+	definition := &Schema{
+		Description: g.descriptions[t.PkgPath()+"."+t.Name()],
+		Type:        "object",
+		Properties:  make(map[string]*Schema),
+	}
+	// The definition is added before its fields so that recursive types refer to it.
+	g.definitions[name] = definition
+	if err := g.addProperties(definition, t); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// addProperties adds the fields of the given struct type to the given definition. The fields of embedded structs
+// without a JSON name are added inline.
+func (g *Generator) addProperties(definition *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := g.addProperties(definition, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property, err := g.schemaOf(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %v", field.Name, t, err)
+		}
+		// Siblings of references are ignored, so references are described by their definitions.
+		if property.Ref == "" {
+			key := t.PkgPath() + "." + t.Name() + "." + field.Name
+			property.Description = g.descriptions[key]
+			applyMarkers(property, g.markers[key])
+		}
+		definition.Properties[name] = property
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// Widget is an object to test the generator with.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec is the spec of the widget.
+	Spec WidgetSpec `json:"spec"`
+}
+
+// WidgetSpec is the spec of a widget.
+type WidgetSpec struct {
+	Name string `json:"name"`
+	// Size is the size of the widget.
+	Size *int32 `json:"size,omitempty"`
+	// +mapType=granular
+	Labels map[string]string `json:"labels,omitempty"`
+	// Parts are the parts of the widget.
+	// +listType=map
+	// +listMapKey=name
+	Parts        []WidgetSpec      `json:"parts,omitempty"`
+	Data         []byte            `json:"data,omitempty"`
+	Memory       resource.Quantity `json:"memory"`
+	CreationTime metav1.Time       `json:"creationTime"`
+	Ignored      string            `json:"-"`
+}
+
+func TestGenerator(t *testing.T) {
+	generator := NewGenerator()
+	if err := generator.LoadDescriptions("github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/openapi", "."); err != nil {
+		t.Fatal(err)
+	}
+	if err := generator.Add(&Widget{}); err != nil {
+		t.Fatal(err)
+	}
+	definitions := generator.Document("Widgets", "v1").Definitions
+
+	widget := definitions["openapi.Widget"]
+	if assert.NotNil(t, widget) {
+		assert.Equal(t, "Widget is an object to test the generator with.", widget.Description)
+		assert.Equal(t, &Schema{Type: "string"}, widget.Properties["apiVersion"])
+		assert.Equal(t, &Schema{Type: "string"}, widget.Properties["kind"])
+		assert.Equal(t, &Schema{Ref: "#/definitions/v1.ObjectMeta"}, widget.Properties["metadata"])
+		assert.Equal(t, &Schema{Ref: "#/definitions/openapi.WidgetSpec"}, widget.Properties["spec"])
+		assert.Equal(t, 4, len(widget.Properties))
+	}
+	assert.NotNil(t, definitions["v1.ObjectMeta"])
+
+	spec := definitions["openapi.WidgetSpec"]
+	if assert.NotNil(t, spec) {
+		assert.Equal(t, map[string]*Schema{
+			"size":   {Type: "integer", Format: "int32", Description: "Size is the size of the widget."},
+			"name":   {Type: "string"},
+			"labels": {Type: "object", AdditionalProperties: &Schema{Type: "string"}, MapType: "granular"},
+			"parts": {
+				Description: "Parts are the parts of the widget.",
+				Type:        "array",
+				Items:       &Schema{Ref: "#/definitions/openapi.WidgetSpec"},
+				ListType:    "map",
+				ListMapKeys: []string{"name"},
+			},
+			"data":         {Type: "string", Format: "byte"},
+			"memory":       {Type: "string", Format: "quantity"},
+			"creationTime": {Type: "string", Format: "date-time"},
+		}, spec.Properties)
+	}
+}
+
+func TestGeneratorWithSparkApplication(t *testing.T) {
+	generator := NewGenerator()
+	if err := generator.Add(&v1beta1.SparkApplication{}, &v1beta1.SparkApplicationList{}); err != nil {
+		t.Fatal(err)
+	}
+	definitions := generator.Document("Spark Operator", "v1beta1").Definitions
+	assert.NotNil(t, definitions["v1beta1.SparkApplicationSpec"])
+	assert.NotNil(t, definitions["v1beta1.SparkApplicationStatus"])
+	assert.NotNil(t, definitions["v1.Volume"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/v1beta1.SparkApplication"}},
+		definitions["v1beta1.SparkApplicationList"].Properties["items"])
+}
+
+func TestDefinitionName(t *testing.T) {
+	assert.Equal(t, "v1beta1.SparkApplication", DefinitionName(reflect.TypeOf(v1beta1.SparkApplication{})))
+	assert.Equal(t, "v1.ObjectMeta", DefinitionName(reflect.TypeOf(metav1.ObjectMeta{})))
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy exports validation rules of SparkApplications as ValidatingAdmissionPolicies, which the API
+// server evaluates with CEL, so that cluster admins can enforce them independently of the webhook of the operator.
+package policy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	apiVersion = "admissionregistration.k8s.io/v1"
+	// PartOfLabel is set on the policies and bindings to tell them apart from the ones of others.
+	PartOfLabel = "app.kubernetes.io/part-of"
+	partOf      = "spark-operator"
+)
+
+// Names of the policies.
+const (
+	MaxExecutorsPolicy      = "spark-max-executors"
+	NoHostPathPolicy        = "spark-no-host-path"
+	RequiredLabelsPolicy    = "spark-required-labels"
+	RequireLinuxNodesPolicy = "spark-require-linux-nodes"
+)
+
+// Validation actions of the bindings of the policies.
+const (
+	DenyAction  = "Deny"
+	WarnAction  = "Warn"
+	AuditAction = "Audit"
+)
+
+// specChangedCondition limits a policy to the creation of SparkApplications and updates changing their spec, so
+// that applications created before the policy can still be deleted and have their metadata and status updated.
+const specChangedCondition = "request.operation == 'CREATE' || object.spec != oldObject.spec"
+
+// Config selects the policies to export. Each policy is exported with its own binding, so that the policies can
+// be enabled and disabled independently.
+type Config struct {
+	// MaxExecutors is the maximum number of executors of an application, whether set by the instances of the
+	// executors or of an executor group, or by spark.executor.instances or spark.dynamicAllocation.maxExecutors.
+	// No limit if not positive.
+	MaxExecutors int32
+	// NoHostPath tells if applications may not mount hostPath volumes.
+	NoHostPath bool
+	// RequiredLabels are the labels every application must have on creation, e.g., a cost center.
+	RequiredLabels []string
+	// RequireLinuxNodes tells if the node selectors of applications must not select nodes other than Linux
+	// nodes, like the webhook does with -enforce-linux-nodes.
+	RequireLinuxNodes bool
+	// ValidationActions are the actions of the bindings on a violation. Defaults to Deny.
+	ValidationActions []string
+}
+
+// rule is a policy with a single validation.
+type rule struct {
+	name       string
+	expression string
+	message    string
+	// specOnly tells if the rule only applies to the creation of applications and updates changing their spec.
+	specOnly bool
+}
+
+// Validate checks the config.
+func (c Config) Validate() error {
+	for _, label := range c.RequiredLabels {
+		if errs := validation.IsQualifiedName(label); len(errs) > 0 {
+			return fmt.Errorf("invalid required label %q: %s", label, strings.Join(errs, "; "))
+		}
+	}
+	for _, action := range c.ValidationActions {
+		if action != DenyAction && action != WarnAction && action != AuditAction {
+			return fmt.Errorf("invalid validation action %q, must be one of %s, %s, and %s", action, DenyAction,
+				WarnAction, AuditAction)
+		}
+	}
+	if len(c.rules()) == 0 {
+		return fmt.Errorf("no policy is selected")
+	}
+	return nil
+}
+
+func (c Config) rules() []rule {
+	var rules []rule
+	if c.MaxExecutors > 0 {
+		rules = append(rules, rule{
+			name: MaxExecutorsPolicy,
+			expression: fmt.Sprintf("(!has(object.spec.executor) || !has(object.spec.executor.instances) || object.spec.executor.instances <= %[1]d) && "+
+				"(!has(object.spec.executorGroups) || object.spec.executorGroups.all(g, !has(g.instances) || g.instances <= %[1]d)) && "+
+				"(!has(object.spec.sparkConf) || [%[2]q, %[3]q].all(k, !(k in object.spec.sparkConf) || int(object.spec.sparkConf[k]) <= %[1]d))",
+				c.MaxExecutors, config.SparkExecutorInstancesKey, config.SparkDynamicAllocationMaxExecutorsKey),
+			message:  fmt.Sprintf("SparkApplications may run at most %d executors", c.MaxExecutors),
+			specOnly: true,
+		})
+	}
+	if c.NoHostPath {
+		rules = append(rules, rule{
+			name:       NoHostPathPolicy,
+			expression: "!has(object.spec.volumes) || object.spec.volumes.all(v, !has(v.hostPath))",
+			message:    "SparkApplications may not mount hostPath volumes",
+			specOnly:   true,
+		})
+	}
+	if len(c.RequiredLabels) > 0 {
+		rules = append(rules, rule{
+			name: RequiredLabelsPolicy,
+			expression: fmt.Sprintf("[%s].all(l, has(object.metadata.labels) && l in object.metadata.labels)",
+				quote(c.RequiredLabels)),
+			message: fmt.Sprintf("SparkApplications must have the labels %s", strings.Join(c.RequiredLabels, ", ")),
+		})
+	}
+	if c.RequireLinuxNodes {
+		keys := []string{config.NodeOSLabel, config.BetaNodeOSLabel}
+		confKeys := []string{config.SparkNodeSelectorKeyPrefix + config.NodeOSLabel,
+			config.SparkNodeSelectorKeyPrefix + config.BetaNodeOSLabel}
+		rules = append(rules, rule{
+			name: RequireLinuxNodesPolicy,
+			expression: fmt.Sprintf("(!has(object.spec.nodeSelector) || [%[1]s].all(k, !(k in object.spec.nodeSelector) || object.spec.nodeSelector[k] == %[3]q)) && "+
+				"(!has(object.spec.sparkConf) || [%[2]s].all(k, !(k in object.spec.sparkConf) || object.spec.sparkConf[k] == %[3]q))",
+				quote(keys), quote(confKeys), config.LinuxNodeOS),
+			message:  "SparkApplications may only select Linux nodes",
+			specOnly: true,
+		})
+	}
+	return rules
+}
+
+// quote returns the given strings as the elements of a CEL list.
+func quote(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Objects returns the ValidatingAdmissionPolicies selected by the given config, each followed by its
+// ValidatingAdmissionPolicyBinding. The client-go version of the operator has no types for them, so they are
+// returned as unstructured objects.
+func Objects(c Config) ([]*unstructured.Unstructured, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	actions := c.ValidationActions
+	if len(actions) == 0 {
+		actions = []string{DenyAction}
+	}
+	var objects []*unstructured.Unstructured
+	for _, r := range c.rules() {
+		objects = append(objects, newPolicy(r), newBinding(r.name, actions))
+	}
+	return objects, nil
+}
+
+func newPolicy(r rule) *unstructured.Unstructured {
+	operations := []interface{}{"CREATE"}
+	if r.specOnly {
+		operations = append(operations, "UPDATE")
+	}
+	spec := map[string]interface{}{
+		"failurePolicy": "Fail",
+		"matchConstraints": map[string]interface{}{
+			"resourceRules": []interface{}{
+				map[string]interface{}{
+					"apiGroups":   []interface{}{"sparkoperator.k8s.io"},
+					"apiVersions": []interface{}{"v1beta1"},
+					"operations":  operations,
+					"resources":   []interface{}{"sparkapplications"},
+				},
+			},
+		},
+		"validations": []interface{}{
+			map[string]interface{}{
+				"expression": r.expression,
+				"message":    r.message,
+			},
+		},
+	}
+	if r.specOnly {
+		spec["matchConditions"] = []interface{}{
+			map[string]interface{}{"name": "spec-changed", "expression": specChangedCondition},
+		}
+	}
+	return newObject("ValidatingAdmissionPolicy", r.name, spec)
+}
+
+func newBinding(policyName string, actions []string) *unstructured.Unstructured {
+	validationActions := make([]interface{}, len(actions))
+	for i, action := range actions {
+		validationActions[i] = action
+	}
+	return newObject("ValidatingAdmissionPolicyBinding", policyName, map[string]interface{}{
+		"policyName":        policyName,
+		"validationActions": validationActions,
+	})
+}
+
+func newObject(kind string, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]interface{}{PartOfLabel: partOf},
+		},
+		"spec": spec,
+	}}
+}
+
+// Write writes the given objects to out as a multi-document YAML stream.
+func Write(out io.Writer, objects []*unstructured.Unstructured) error {
+	for _, obj := range objects {
+		content, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects(t *testing.T) {
+	objects, err := Objects(Config{
+		MaxExecutors:   50,
+		NoHostPath:     true,
+		RequiredLabels: []string{"cost-center"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 6, len(objects))
+
+	var names []string
+	for _, obj := range objects {
+		assert.Equal(t, "admissionregistration.k8s.io/v1", obj.GetAPIVersion())
+		assert.Equal(t, "spark-operator", obj.GetLabels()[PartOfLabel])
+		if obj.GetKind() == "ValidatingAdmissionPolicy" {
+			names = append(names, obj.GetName())
+		}
+	}
+	assert.Equal(t, []string{MaxExecutorsPolicy, NoHostPathPolicy, RequiredLabelsPolicy}, names)
+
+	validations, _, _ := unstructured.NestedSlice(objects[0].Object, "spec", "validations")
+	expression := validations[0].(map[string]interface{})["expression"].(string)
+	assert.True(t, strings.Contains(expression, "object.spec.executor.instances <= 50"))
+	assert.True(t, strings.Contains(expression, `["spark.executor.instances", "spark.dynamicAllocation.maxExecutors"]`))
+	conditions, _, _ := unstructured.NestedSlice(objects[0].Object, "spec", "matchConditions")
+	assert.Equal(t, 1, len(conditions))
+
+	// Required labels are only checked on creation, as the operator labels existing applications.
+	rules, _, _ := unstructured.NestedSlice(objects[4].Object, "spec", "matchConstraints", "resourceRules")
+	assert.Equal(t, []interface{}{"CREATE"}, rules[0].(map[string]interface{})["operations"])
+	validations, _, _ = unstructured.NestedSlice(objects[4].Object, "spec", "validations")
+	assert.Equal(t, `["cost-center"].all(l, has(object.metadata.labels) && l in object.metadata.labels)`,
+		validations[0].(map[string]interface{})["expression"])
+
+	binding := objects[1]
+	assert.Equal(t, "ValidatingAdmissionPolicyBinding", binding.GetKind())
+	policyName, _, _ := unstructured.NestedString(binding.Object, "spec", "policyName")
+	assert.Equal(t, MaxExecutorsPolicy, policyName)
+	actions, _, _ := unstructured.NestedStringSlice(binding.Object, "spec", "validationActions")
+	assert.Equal(t, []string{DenyAction}, actions)
+}
+
+func TestObjectsWithValidationActions(t *testing.T) {
+	objects, err := Objects(Config{RequireLinuxNodes: true, ValidationActions: []string{WarnAction, AuditAction}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(objects))
+	assert.Equal(t, RequireLinuxNodesPolicy, objects[0].GetName())
+	actions, _, _ := unstructured.NestedStringSlice(objects[1].Object, "spec", "validationActions")
+	assert.Equal(t, []string{WarnAction, AuditAction}, actions)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NotNil(t, Config{}.Validate())
+	assert.NotNil(t, Config{NoHostPath: true, ValidationActions: []string{"Reject"}}.Validate())
+	assert.NotNil(t, Config{RequiredLabels: []string{"not a label"}}.Validate())
+	assert.Nil(t, Config{RequiredLabels: []string{"example.com/team"}}.Validate())
+}
+
+func TestWrite(t *testing.T) {
+	objects, err := Objects(Config{NoHostPath: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := Write(&out, objects); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, strings.Count(out.String(), "---\n"))
+	assert.True(t, strings.Contains(out.String(), "kind: ValidatingAdmissionPolicy\n"))
+	assert.True(t, strings.Contains(out.String(), "kind: ValidatingAdmissionPolicyBinding\n"))
+	assert.True(t, strings.Contains(out.String(), "name: spark-no-host-path\n"))
+}
@@ -0,0 +1,388 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// FairShareScheduler decides when queued SparkApplications may start, so that the number of concurrently
+// running applications stays within a configured capacity. Free capacity is shared between queues, one per
+// namespace unless overridden by the config.SparkAppQueueLabel label, in proportion to the queue weights:
+// the next application to start always comes from the queue with the lowest number of running applications
+// relative to its weight. Within a queue, applications start in the order of their priorities, and in the
+// order they were queued if their priorities are equal. As a result, an application queued in an under-served
+// queue overtakes applications queued earlier in over-served queues. Running applications are only stopped to
+// make room for queued ones through Preempt.
+//
+// As priorities decide which applications are preempted across namespaces, the priority an application sets is
+// capped at the maximum priority the operator allows for its namespace, which is 0 for namespaces without one.
+type FairShareScheduler struct {
+	mutex      sync.Mutex
+	lister     crdlisters.SparkApplicationLister
+	maxRunning int
+	weights    map[string]int
+	// maxPriorities are the maximum priorities of applications by namespace, with AllNamespaces applying to
+	// namespaces not listed.
+	maxPriorities map[string]int32
+	// starting holds the keys of applications that have been admitted but may not yet show up as running
+	// in the lister.
+	starting map[string]bool
+	// preempted holds the keys of applications that have been chosen to be preempted but may still show up as
+	// running in the lister.
+	preempted map[string]bool
+	metrics   *schedulerMetrics
+}
+
+// AllNamespaces is the key of maxPriorities applying to namespaces not listed.
+const AllNamespaces = "*"
+
+// NewFairShareScheduler creates a new FairShareScheduler that allows at most maxRunning applications to run
+// concurrently. Queues not listed in weights get a weight of 1, and applications in namespaces not listed in
+// maxPriorities a priority of at most 0.
+func NewFairShareScheduler(
+	lister crdlisters.SparkApplicationLister,
+	maxRunning int,
+	weights map[string]int,
+	maxPriorities map[string]int32,
+	metricsConfig *util.MetricConfig) *FairShareScheduler {
+	scheduler := &FairShareScheduler{
+		lister:        lister,
+		maxRunning:    maxRunning,
+		weights:       weights,
+		maxPriorities: maxPriorities,
+		starting:      make(map[string]bool),
+		preempted:     make(map[string]bool),
+	}
+	if metricsConfig != nil {
+		scheduler.metrics = newSchedulerMetrics(metricsConfig.MetricsPrefix)
+		scheduler.metrics.registerMetrics()
+	}
+	return scheduler
+}
+
+// ParseQueueWeights parses queue weights in the form of "queue=weight".
+func ParseQueueWeights(values []string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid queue weight %q, expected queue=weight", value)
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid queue weight %q, weight must be a positive integer", value)
+		}
+		weights[parts[0]] = weight
+	}
+	return weights, nil
+}
+
+// ParseMaxPriorities parses maximum priorities in the form of "namespace=priority", where the namespace * applies
+// to namespaces not listed.
+func ParseMaxPriorities(values []string) (map[string]int32, error) {
+	maxPriorities := make(map[string]int32)
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid maximum priority %q, expected namespace=priority", value)
+		}
+		priority, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum priority %q, priority must be an integer", value)
+		}
+		maxPriorities[parts[0]] = int32(priority)
+	}
+	return maxPriorities, nil
+}
+
+// SetLimits changes the maximum number of concurrently running applications, the queue weights, and the maximum
+// priorities. Running applications are not affected if the capacity shrinks. A maxRunning that is not positive
+// lets all queued applications start.
+func (s *FairShareScheduler) SetLimits(maxRunning int, weights map[string]int, maxPriorities map[string]int32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maxRunning = maxRunning
+	s.weights = weights
+	s.maxPriorities = maxPriorities
+}
+
+// GetQueue returns the name of the queue the given application belongs to.
+func GetQueue(app *v1beta1.SparkApplication) string {
+	if queue, ok := app.Labels[config.SparkAppQueueLabel]; ok && queue != "" {
+		return queue
+	}
+	return app.Namespace
+}
+
+// GetPriority returns the priority the given application sets.
+func GetPriority(app *v1beta1.SparkApplication) int32 {
+	if app.Spec.Priority != nil {
+		return *app.Spec.Priority
+	}
+	return 0
+}
+
+// getPriority returns the priority of the given application, capped at the maximum priority of its namespace.
+func (s *FairShareScheduler) getPriority(app *v1beta1.SparkApplication) int32 {
+	max, ok := s.maxPriorities[app.Namespace]
+	if !ok {
+		max = s.maxPriorities[AllNamespaces]
+	}
+	if priority := GetPriority(app); priority < max {
+		return priority
+	}
+	return max
+}
+
+// ShouldStart tells if the given queued application may start now. An application that is allowed to start
+// is counted as running from then on, so the caller is expected to submit it right away.
+func (s *FairShareScheduler) ShouldStart(app *v1beta1.SparkApplication) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	apps, err := s.lister.List(labels.Everything())
+	if err != nil {
+		return false, fmt.Errorf("failed to list SparkApplications: %v", err)
+	}
+
+	running := make(map[string]int)
+	queued := make(map[string][]*v1beta1.SparkApplication)
+	totalRunning := 0
+	seen := make(map[string]bool)
+	for _, a := range apps {
+		key := getKey(a)
+		seen[key] = true
+		queue := GetQueue(a)
+		if a.Status.AppState.State == v1beta1.QueuedState {
+			if !s.starting[key] {
+				queued[queue] = append(queued[queue], a)
+				continue
+			}
+		} else {
+			// The application has been observed as started.
+			delete(s.starting, key)
+		}
+		if !isRunning(a) {
+			// The application has been observed as stopped.
+			delete(s.preempted, key)
+		}
+		if s.starting[key] || isRunning(a) {
+			running[queue]++
+			totalRunning++
+		}
+	}
+	// Forget about admitted applications that have been deleted since.
+	for key := range s.starting {
+		if !seen[key] {
+			delete(s.starting, key)
+		}
+	}
+	for key := range s.preempted {
+		if !seen[key] {
+			delete(s.preempted, key)
+		}
+	}
+	s.exportQueuedCounts(queued)
+
+	key := getKey(app)
+	if s.maxRunning <= 0 {
+		s.starting[key] = true
+		s.observeWaitTime(app)
+		return true, nil
+	}
+	free := s.maxRunning - totalRunning
+	for ; free > 0; free-- {
+		next := s.pickNext(running, queued)
+		if next == nil {
+			break
+		}
+		if getKey(next) == key {
+			s.starting[key] = true
+			s.observeWaitTime(app)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Preempt chooses a running application to make room for the given queued application if there is no capacity
+// left for it, and the application allows preemption. The victim is the application with the lowest priority
+// below the one of the given application, and the most recently submitted one among those, which loses the
+// least work. If there is a victim, the given application is admitted right away like by ShouldStart, and the
+// caller is expected to stop the victim. Until the victim has stopped, the applications running may exceed the
+// capacity by one.
+func (s *FairShareScheduler) Preempt(app *v1beta1.SparkApplication) (*v1beta1.SparkApplication, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := getKey(app)
+	if s.maxRunning <= 0 || s.starting[key] {
+		return nil, nil
+	}
+	if app.Spec.PreemptionPolicy != nil && *app.Spec.PreemptionPolicy == v1beta1.PreemptNever {
+		return nil, nil
+	}
+
+	apps, err := s.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SparkApplications: %v", err)
+	}
+
+	totalRunning := 0
+	priority := s.getPriority(app)
+	var victim *v1beta1.SparkApplication
+	for _, a := range apps {
+		if s.starting[getKey(a)] {
+			totalRunning++
+			continue
+		}
+		if !isRunning(a) {
+			continue
+		}
+		totalRunning++
+		if s.preempted[getKey(a)] || s.getPriority(a) >= priority {
+			continue
+		}
+		if victim == nil || s.isPreferredVictim(a, victim) {
+			victim = a
+		}
+	}
+	if victim == nil || totalRunning < s.maxRunning {
+		return nil, nil
+	}
+
+	s.preempted[getKey(victim)] = true
+	s.starting[key] = true
+	s.observeWaitTime(app)
+	if s.metrics != nil {
+		s.metrics.preemptionCount.WithLabelValues(GetQueue(victim)).Inc()
+	}
+	return victim, nil
+}
+
+// AbortPreemption undoes a preemption by Preempt that the caller failed to carry out, so that the victim keeps
+// running and the given application stays queued.
+func (s *FairShareScheduler) AbortPreemption(app *v1beta1.SparkApplication, victim *v1beta1.SparkApplication) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.starting, getKey(app))
+	delete(s.preempted, getKey(victim))
+}
+
+// isPreferredVictim tells if the running application a should rather be preempted than b.
+func (s *FairShareScheduler) isPreferredVictim(a, b *v1beta1.SparkApplication) bool {
+	if s.getPriority(a) != s.getPriority(b) {
+		return s.getPriority(a) < s.getPriority(b)
+	}
+	if !a.Status.LastSubmissionAttemptTime.Equal(&b.Status.LastSubmissionAttemptTime) {
+		return b.Status.LastSubmissionAttemptTime.Before(&a.Status.LastSubmissionAttemptTime)
+	}
+	return getKey(a) < getKey(b)
+}
+
+// pickNext removes and returns the next application to start from the queue with the lowest share of
+// running applications relative to its weight.
+func (s *FairShareScheduler) pickNext(
+	running map[string]int,
+	queued map[string][]*v1beta1.SparkApplication) *v1beta1.SparkApplication {
+	var names []string
+	for name, apps := range queued {
+		if len(apps) > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	best := names[0]
+	for _, name := range names[1:] {
+		// Compare running[name]/weight(name) with running[best]/weight(best) without division.
+		if running[name]*s.weight(best) < running[best]*s.weight(name) {
+			best = name
+		}
+	}
+
+	apps := queued[best]
+	sort.Slice(apps, func(i, j int) bool {
+		if s.getPriority(apps[i]) != s.getPriority(apps[j]) {
+			return s.getPriority(apps[i]) > s.getPriority(apps[j])
+		}
+		if !apps[i].Status.QueuedTime.Equal(&apps[j].Status.QueuedTime) {
+			return apps[i].Status.QueuedTime.Before(&apps[j].Status.QueuedTime)
+		}
+		return getKey(apps[i]) < getKey(apps[j])
+	})
+	next := apps[0]
+	queued[best] = apps[1:]
+	running[best]++
+	return next
+}
+
+func (s *FairShareScheduler) weight(queue string) int {
+	if weight, ok := s.weights[queue]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (s *FairShareScheduler) exportQueuedCounts(queued map[string][]*v1beta1.SparkApplication) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.queuedCount.Reset()
+	for queue, apps := range queued {
+		s.metrics.queuedCount.WithLabelValues(queue).Set(float64(len(apps)))
+	}
+}
+
+func (s *FairShareScheduler) observeWaitTime(app *v1beta1.SparkApplication) {
+	if s.metrics == nil || app.Status.QueuedTime.IsZero() {
+		return
+	}
+	wait := time.Since(app.Status.QueuedTime.Time)
+	s.metrics.waitTime.WithLabelValues(GetQueue(app)).Observe(wait.Seconds())
+}
+
+// isRunning tells if the given application occupies capacity.
+func isRunning(app *v1beta1.SparkApplication) bool {
+	switch app.Status.AppState.State {
+	case v1beta1.SubmittedState, v1beta1.RunningState, v1beta1.UnknownState:
+		return true
+	}
+	return false
+}
+
+func getKey(app *v1beta1.SparkApplication) string {
+	key, _ := cache.MetaNamespaceKeyFunc(app)
+	return key
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// maxPriorities allows the applications of the tests to set priorities of up to 10.
+var maxPriorities = map[string]int32{"default": 10}
+
+func newFakeScheduler(maxRunning int, weights map[string]int) (*FairShareScheduler, cache.Indexer) {
+	crdClient := crdclientfake.NewSimpleClientset()
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0*time.Second)
+	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications()
+	return NewFairShareScheduler(informer.Lister(), maxRunning, weights, maxPriorities, nil),
+		informer.Informer().GetIndexer()
+}
+
+func newApp(name, namespace string, state v1beta1.ApplicationStateType, queuedAt time.Time) *v1beta1.SparkApplication {
+	return &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:   v1beta1.ApplicationState{State: state},
+			QueuedTime: metav1.NewTime(queuedAt),
+		},
+	}
+}
+
+func TestShouldStart_Capacity(t *testing.T) {
+	s, indexer := newFakeScheduler(2, nil)
+	now := time.Now()
+	running := newApp("running", "default", v1beta1.RunningState, now.Add(-time.Hour))
+	first := newApp("first", "default", v1beta1.QueuedState, now.Add(-2*time.Minute))
+	second := newApp("second", "default", v1beta1.QueuedState, now.Add(-time.Minute))
+	for _, app := range []*v1beta1.SparkApplication{running, first, second} {
+		indexer.Add(app)
+	}
+
+	// Applications within a queue start in the order they were queued.
+	start, err := s.ShouldStart(second)
+	assert.Nil(t, err)
+	assert.False(t, start)
+	start, err = s.ShouldStart(first)
+	assert.Nil(t, err)
+	assert.True(t, start)
+
+	// The admitted application occupies capacity although it is still queued in the lister.
+	start, err = s.ShouldStart(second)
+	assert.Nil(t, err)
+	assert.False(t, start)
+
+	// Capacity freed up by a completed application is given to the next queued application.
+	running.Status.AppState.State = v1beta1.CompletedState
+	indexer.Update(running)
+	start, err = s.ShouldStart(second)
+	assert.Nil(t, err)
+	assert.True(t, start)
+}
+
+func TestShouldStart_FairShare(t *testing.T) {
+	s, indexer := newFakeScheduler(3, map[string]int{"team-a": 2})
+	now := time.Now()
+	apps := []*v1beta1.SparkApplication{
+		newApp("a-running", "team-a", v1beta1.RunningState, now.Add(-time.Hour)),
+		newApp("b-running", "team-b", v1beta1.RunningState, now.Add(-time.Hour)),
+		newApp("b-queued", "team-b", v1beta1.QueuedState, now.Add(-2*time.Minute)),
+		newApp("a-queued", "team-a", v1beta1.QueuedState, now.Add(-time.Minute)),
+	}
+	for _, app := range apps {
+		indexer.Add(app)
+	}
+
+	// team-a has a higher weight, so its application goes first although it was queued later. With only
+	// one free slot, the application of team-b has to wait.
+	start, err := s.ShouldStart(apps[2])
+	assert.Nil(t, err)
+	assert.False(t, start)
+	start, err = s.ShouldStart(apps[3])
+	assert.Nil(t, err)
+	assert.True(t, start)
+}
+
+func TestShouldStart_QueueLabel(t *testing.T) {
+	s, indexer := newFakeScheduler(1, nil)
+	now := time.Now()
+	running := newApp("running", "team-a", v1beta1.RunningState, now.Add(-time.Hour))
+	running.Labels = map[string]string{config.SparkAppQueueLabel: "shared"}
+	queued := newApp("queued", "team-b", v1beta1.QueuedState, now)
+	queued.Labels = map[string]string{config.SparkAppQueueLabel: "shared"}
+	indexer.Add(running)
+	indexer.Add(queued)
+
+	assert.Equal(t, "shared", GetQueue(queued))
+	start, err := s.ShouldStart(queued)
+	assert.Nil(t, err)
+	assert.False(t, start)
+
+	indexer.Delete(running)
+	start, err = s.ShouldStart(queued)
+	assert.Nil(t, err)
+	assert.True(t, start)
+}
+
+func TestSetLimits(t *testing.T) {
+	s, indexer := newFakeScheduler(1, nil)
+	now := time.Now()
+	running := newApp("running", "default", v1beta1.RunningState, now.Add(-time.Hour))
+	queued := newApp("queued", "default", v1beta1.QueuedState, now)
+	indexer.Add(running)
+	indexer.Add(queued)
+
+	start, err := s.ShouldStart(queued)
+	assert.Nil(t, err)
+	assert.False(t, start)
+
+	s.SetLimits(2, nil, maxPriorities)
+	start, err = s.ShouldStart(queued)
+	assert.Nil(t, err)
+	assert.True(t, start)
+
+	// Without a limit, all queued applications start.
+	s.SetLimits(0, nil, maxPriorities)
+	another := newApp("another", "default", v1beta1.QueuedState, now)
+	indexer.Add(another)
+	start, err = s.ShouldStart(another)
+	assert.Nil(t, err)
+	assert.True(t, start)
+}
+
+func TestParseQueueWeights(t *testing.T) {
+	weights, err := ParseQueueWeights([]string{"team-a=3", "team-b=1"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"team-a": 3, "team-b": 1}, weights)
+
+	for _, value := range []string{"team-a", "=2", "team-a=0", "team-a=x"} {
+		_, err = ParseQueueWeights([]string{value})
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestParseMaxPriorities(t *testing.T) {
+	maxPriorities, err := ParseMaxPriorities([]string{"prod=100", "*=-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int32{"prod": 100, "*": -1}, maxPriorities)
+
+	for _, value := range []string{"prod", "=2", "prod=x"} {
+		_, err = ParseMaxPriorities([]string{value})
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestGetPriority(t *testing.T) {
+	s, _ := newFakeScheduler(1, nil)
+	high := int32(100)
+	low := int32(-1)
+	app := newApp("app", "default", v1beta1.QueuedState, time.Now())
+	assert.Equal(t, int32(0), s.getPriority(app))
+
+	// Priorities are capped at the maximum priority of the namespace.
+	app.Spec.Priority = &high
+	assert.Equal(t, int32(10), s.getPriority(app))
+	app.Spec.Priority = &low
+	assert.Equal(t, int32(-1), s.getPriority(app))
+
+	// Namespaces without a maximum priority cannot raise their priority unless a default is configured.
+	other := newApp("other", "other", v1beta1.QueuedState, time.Now())
+	other.Spec.Priority = &high
+	assert.Equal(t, int32(0), s.getPriority(other))
+	s.SetLimits(1, nil, map[string]int32{AllNamespaces: 50})
+	assert.Equal(t, int32(50), s.getPriority(other))
+}
+
+func TestPreempt_CappedPriority(t *testing.T) {
+	s, indexer := newFakeScheduler(1, nil)
+	now := time.Now()
+	high := int32(100)
+	running := newApp("running", "default", v1beta1.RunningState, now.Add(-time.Hour))
+	intruder := newApp("intruder", "other", v1beta1.QueuedState, now)
+	intruder.Spec.Priority = &high
+	indexer.Add(running)
+	indexer.Add(intruder)
+
+	// Applications cannot preempt others by setting a priority their namespace is not allowed.
+	victim, err := s.Preempt(intruder)
+	assert.Nil(t, err)
+	assert.Nil(t, victim)
+}
+
+func TestShouldStart_Priority(t *testing.T) {
+	s, indexer := newFakeScheduler(1, nil)
+	now := time.Now()
+	first := newApp("first", "default", v1beta1.QueuedState, now.Add(-time.Minute))
+	urgent := newApp("urgent", "default", v1beta1.QueuedState, now)
+	priority := int32(10)
+	urgent.Spec.Priority = &priority
+	indexer.Add(first)
+	indexer.Add(urgent)
+
+	// Higher-priority applications start first although they were queued later.
+	start, err := s.ShouldStart(first)
+	assert.Nil(t, err)
+	assert.False(t, start)
+	start, err = s.ShouldStart(urgent)
+	assert.Nil(t, err)
+	assert.True(t, start)
+}
+
+func TestPreempt(t *testing.T) {
+	s, indexer := newFakeScheduler(2, nil)
+	now := time.Now()
+	low := int32(-1)
+	high := int32(10)
+	older := newApp("older", "default", v1beta1.RunningState, now.Add(-time.Hour))
+	older.Status.LastSubmissionAttemptTime = metav1.NewTime(now.Add(-time.Hour))
+	newer := newApp("newer", "default", v1beta1.RunningState, now.Add(-time.Hour))
+	newer.Status.LastSubmissionAttemptTime = metav1.NewTime(now.Add(-time.Minute))
+	urgent := newApp("urgent", "default", v1beta1.QueuedState, now)
+	urgent.Spec.Priority = &high
+	for _, app := range []*v1beta1.SparkApplication{older, newer, urgent} {
+		indexer.Add(app)
+	}
+
+	// Applications without a higher priority cannot preempt.
+	normal := newApp("normal", "default", v1beta1.QueuedState, now)
+	indexer.Add(normal)
+	victim, err := s.Preempt(normal)
+	assert.Nil(t, err)
+	assert.Nil(t, victim)
+
+	// The most recently submitted application of the lowest priority is preempted.
+	victim, err = s.Preempt(urgent)
+	assert.Nil(t, err)
+	assert.Equal(t, "newer", victim.Name)
+
+	// The victim is not preempted twice while it is still running.
+	another := newApp("another", "default", v1beta1.QueuedState, now)
+	another.Spec.Priority = &high
+	indexer.Add(another)
+	victim, err = s.Preempt(another)
+	assert.Nil(t, err)
+	assert.Equal(t, "older", victim.Name)
+
+	// Applications of a lower priority are preferred over more recently submitted ones.
+	s, indexer = newFakeScheduler(2, nil)
+	older.Spec.Priority = &low
+	for _, app := range []*v1beta1.SparkApplication{older, newer, urgent} {
+		indexer.Add(app)
+	}
+	victim, err = s.Preempt(urgent)
+	assert.Nil(t, err)
+	assert.Equal(t, "older", victim.Name)
+}
+
+func TestPreempt_NoPreemption(t *testing.T) {
+	s, indexer := newFakeScheduler(2, nil)
+	now := time.Now()
+	high := int32(10)
+	running := newApp("running", "default", v1beta1.RunningState, now.Add(-time.Hour))
+	urgent := newApp("urgent", "default", v1beta1.QueuedState, now)
+	urgent.Spec.Priority = &high
+	indexer.Add(running)
+	indexer.Add(urgent)
+
+	// Nothing is preempted while there is free capacity.
+	victim, err := s.Preempt(urgent)
+	assert.Nil(t, err)
+	assert.Nil(t, victim)
+
+	// Nothing is preempted for applications that never preempt.
+	s.SetLimits(1, nil, maxPriorities)
+	never := v1beta1.PreemptNever
+	urgent.Spec.PreemptionPolicy = &never
+	victim, err = s.Preempt(urgent)
+	assert.Nil(t, err)
+	assert.Nil(t, victim)
+
+	// An aborted preemption leaves the victim to be preempted again.
+	urgent.Spec.PreemptionPolicy = nil
+	victim, err = s.Preempt(urgent)
+	assert.Nil(t, err)
+	assert.Equal(t, "running", victim.Name)
+	s.AbortPreemption(urgent, victim)
+	victim, err = s.Preempt(urgent)
+	assert.Nil(t, err)
+	assert.Equal(t, "running", victim.Name)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const queueLabel = "queue"
+
+type schedulerMetrics struct {
+	queuedCount     *prometheus.GaugeVec
+	waitTime        *prometheus.HistogramVec
+	preemptionCount *prometheus.CounterVec
+}
+
+func newSchedulerMetrics(prefix string) *schedulerMetrics {
+	queuedCount := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: util.CreateValidMetricNameLabel(prefix, "spark_app_queued_count"),
+			Help: "Spark Apps Waiting in a Queue of the Operator",
+		},
+		[]string{queueLabel},
+	)
+	waitTime := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    util.CreateValidMetricNameLabel(prefix, "spark_app_queue_wait_time_seconds"),
+			Help:    "Time Spark Apps Spent Waiting in a Queue of the Operator",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		},
+		[]string{queueLabel},
+	)
+	preemptionCount := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: util.CreateValidMetricNameLabel(prefix, "spark_app_preemption_count"),
+			Help: "Spark Apps Preempted to Make Room for Higher-Priority Spark Apps",
+		},
+		[]string{queueLabel},
+	)
+	return &schedulerMetrics{
+		queuedCount:     queuedCount,
+		waitTime:        waitTime,
+		preemptionCount: preemptionCount,
+	}
+}
+
+func (sm *schedulerMetrics) registerMetrics() {
+	util.RegisterMetric(sm.queuedCount)
+	util.RegisterMetric(sm.waitTime)
+	util.RegisterMetric(sm.preemptionCount)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+var (
+	execCommandContext = exec.CommandContext
+	commitSHA          = regexp.MustCompile("^[0-9a-fA-F]{40}$")
+	// gitTimeout bounds fetching a Git source, so an unresponsive remote does not block the worker fetching it.
+	gitTimeout = fetchRequestTimeout
+)
+
+// FetchGit fetches the manifest in the given Git source by running git. Only the commit of the revision of the
+// source is fetched. It returns the manifest and the SHA of the commit. git is killed if fetching takes longer
+// than gitTimeout.
+func FetchGit(source *v1beta1.GitSource, credentials *Credentials) ([]byte, string, error) {
+	if source.URL == "" || source.Path == "" {
+		return nil, "", fmt.Errorf("git sources require url and path")
+	}
+	revision := "HEAD"
+	if source.Revision != nil && *source.Revision != "" {
+		revision = *source.Revision
+	}
+
+	dir, err := ioutil.TempDir("", "spark-source-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(dir)
+
+	var config []string
+	if credentials != nil {
+		// Pass the credentials in a header rather than the URL, so that they do not show up in errors.
+		token := base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+		config = []string{"-c", "http.extraHeader=Authorization: Basic " + token}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	git := func(args ...string) (string, error) {
+		cmd := execCommandContext(ctx, "git", append(config, args...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return "", fmt.Errorf("git %s timed out after %v", args[0], gitTimeout)
+			}
+			return "", fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.String(), nil
+	}
+
+	if _, err := git("init", "-q"); err != nil {
+		return nil, "", err
+	}
+	if _, err := git("fetch", "-q", "--depth=1", "--", source.URL, revision); err != nil {
+		return nil, "", fmt.Errorf("failed to fetch revision %s of %s: %v", revision, source.URL, err)
+	}
+	sha, err := git("rev-parse", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return nil, "", err
+	}
+	sha = strings.TrimSpace(sha)
+	if commitSHA.MatchString(revision) && !strings.EqualFold(sha, revision) {
+		return nil, "", fmt.Errorf("fetched commit %s of %s, expected commit %s", sha, source.URL, revision)
+	}
+	manifest, err := git("show", "FETCH_HEAD:"+strings.TrimPrefix(source.Path, "/"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s at commit %s of %s: %v", source.Path, sha, source.URL, err)
+	}
+	if len(manifest) > maxManifestSize {
+		return nil, "", fmt.Errorf("%s at commit %s of %s is larger than %d bytes", source.Path, sha, source.URL,
+			maxManifestSize)
+	}
+	return []byte(manifest), sha, nil
+}
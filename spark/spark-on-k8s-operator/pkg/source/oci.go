@@ -0,0 +1,308 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const (
+	fetchRequestTimeout = 30 * time.Second
+	dockerHubRegistry   = "registry-1.docker.io"
+	// layerTitleAnnotation is the annotation "oras push" sets to the name of the file of a layer.
+	layerTitleAnnotation = "org.opencontainers.image.title"
+)
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+type reference struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// parseReference parses a reference of the form [<registry>/]<repository>[:<tag>][@<digest>], where the
+// registry defaults to Docker Hub and the tag to "latest".
+func parseReference(ref string) (*reference, error) {
+	r := &reference{registry: dockerHubRegistry}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		r.digest = ref[i+1:]
+		ref = ref[:i]
+		if !strings.HasPrefix(r.digest, "sha256:") {
+			return nil, fmt.Errorf("unsupported digest %s, only sha256 digests are supported", r.digest)
+		}
+	}
+	if i := strings.LastIndex(ref, ":"); i >= 0 && !strings.Contains(ref[i:], "/") {
+		r.tag = ref[i+1:]
+		ref = ref[:i]
+	}
+	if i := strings.Index(ref, "/"); i >= 0 {
+		if host := ref[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			r.registry = host
+			ref = ref[i+1:]
+		}
+	}
+	if r.registry == dockerHubRegistry && !strings.Contains(ref, "/") {
+		ref = "library/" + ref
+	}
+	r.repository = ref
+	if r.repository == "" {
+		return nil, fmt.Errorf("invalid reference, no repository")
+	}
+	if r.tag == "" && r.digest == "" {
+		r.tag = "latest"
+	}
+	return r, nil
+}
+
+// FetchOCI fetches the manifest in the given OCI source from its registry through the OCI distribution API. It
+// returns the manifest and the digest of the artifact manifest.
+func FetchOCI(source *v1beta1.OCISource, credentials *Credentials) ([]byte, string, error) {
+	ref, err := parseReference(source.Reference)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid OCI reference %s: %v", source.Reference, err)
+	}
+	c := &registryClient{
+		ref:         ref,
+		credentials: credentials,
+		client:      &http.Client{Timeout: fetchRequestTimeout},
+	}
+
+	version := ref.digest
+	if version == "" {
+		version = ref.tag
+	}
+	content, err := c.get("manifests/"+version, strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch the manifest of %s: %v", source.Reference, err)
+	}
+	digest := computeDigest(content)
+	if ref.digest != "" && digest != ref.digest {
+		return nil, "", fmt.Errorf("fetched artifact manifest of %s has digest %s", source.Reference, digest)
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to parse the manifest of %s: %v", source.Reference, err)
+	}
+	layer, err := selectLayer(manifest.Layers, source.Path)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %v", source.Reference, err)
+	}
+	if layer.Size > maxManifestSize {
+		return nil, "", fmt.Errorf("layer %s of %s is larger than %d bytes", layer.Digest, source.Reference,
+			maxManifestSize)
+	}
+
+	blob, err := c.get("blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch layer %s of %s: %v", layer.Digest, source.Reference, err)
+	}
+	if computeDigest(blob) != layer.Digest {
+		return nil, "", fmt.Errorf("fetched layer %s of %s has digest %s", layer.Digest, source.Reference,
+			computeDigest(blob))
+	}
+	return blob, digest, nil
+}
+
+func selectLayer(layers []ociDescriptor, path *string) (*ociDescriptor, error) {
+	if path == nil || *path == "" {
+		if len(layers) != 1 {
+			return nil, fmt.Errorf("the artifact has %d layers, path is required to select one", len(layers))
+		}
+		return &layers[0], nil
+	}
+	for i := range layers {
+		if layers[i].Annotations[layerTitleAnnotation] == *path {
+			return &layers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("the artifact has no layer titled %s", *path)
+}
+
+func computeDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// registryClient gets manifests and blobs of a repository, authenticating with the registry as challenged.
+type registryClient struct {
+	ref           *reference
+	credentials   *Credentials
+	client        *http.Client
+	authorization string
+}
+
+func (c *registryClient) url(path string) string {
+	scheme := "https"
+	if host := strings.Split(c.ref.registry, ":")[0]; host == "localhost" || host == "127.0.0.1" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, c.ref.registry, c.ref.repository, path)
+}
+
+func (c *registryClient) get(path string, accept string) ([]byte, error) {
+	resp, err := c.do(path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if c.authorization, err = c.authorize(challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(path, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, fmt.Errorf("got status %s", resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+}
+
+func (c *registryClient) do(path string, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	return c.client.Do(req)
+}
+
+// authorize returns the Authorization header answering the given challenge of the registry. Bearer challenges
+// are answered with a token from the token service of the registry, anonymously unless there are credentials.
+func (c *registryClient) authorize(challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.credentials == nil {
+			return "", fmt.Errorf("the registry requires credentials")
+		}
+		auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
+		return "Basic " + auth, nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	query := url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", c.ref.repository)
+	}
+	query.Set("scope", scope)
+	req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.credentials != nil {
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a registry token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get a registry token: got status %s", resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse the registry token: %v", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g., `Bearer realm="https://auth.docker.io/token",
+// service="registry.docker.io"`, into its scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := make(map[string]string)
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimSpace(rest[eq+1:])
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.Index(rest, ","); comma >= 0 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+		rest = strings.TrimSpace(rest)
+	}
+	return parts[0], params
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// maxManifestSize is the maximum size of a manifest, which is well above what fits into an object.
+const maxManifestSize = 4 << 20
+
+// Credentials authenticate with a Git server or an OCI registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Fetch fetches the manifest the given reference refers to. It returns the manifest and the revision it was
+// fetched at, which is a commit SHA for Git sources and a manifest digest for OCI sources.
+func Fetch(ref *v1beta1.SourceReference, credentials *Credentials) ([]byte, string, error) {
+	switch {
+	case ref.Git != nil && ref.OCI != nil:
+		return nil, "", fmt.Errorf("sourceRef must have only one of git and oci")
+	case ref.Git != nil:
+		return FetchGit(ref.Git, credentials)
+	case ref.OCI != nil:
+		return FetchOCI(ref.OCI, credentials)
+	}
+	return nil, "", fmt.Errorf("sourceRef must have one of git and oci")
+}
+
+// LoadSpec loads the spec of the SparkApplication manifest in the given YAML or JSON content.
+func LoadSpec(content []byte) (*v1beta1.SparkApplicationSpec, error) {
+	var app v1beta1.SparkApplication
+	if err := yaml.Unmarshal(content, &app); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest: %v", err)
+	}
+	if app.Kind != "" && app.Kind != "SparkApplication" {
+		return nil, fmt.Errorf("the manifest is a %s, not a SparkApplication", app.Kind)
+	}
+	if app.Spec.Type == "" {
+		return nil, fmt.Errorf("the manifest has no spec.type")
+	}
+	if app.Spec.SourceRef != nil {
+		return nil, fmt.Errorf("the manifest must not have a sourceRef")
+	}
+	return &app.Spec, nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+const manifest = `apiVersion: sparkoperator.k8s.io/v1beta1
+kind: SparkApplication
+metadata:
+  name: spark-pi
+spec:
+  type: Scala
+  mainApplicationFile: local:///opt/spark/examples/jars/spark-examples_2.11-2.4.0.jar
+`
+
+func TestLoadSpec(t *testing.T) {
+	spec, err := LoadSpec([]byte(manifest))
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.ScalaApplicationType, spec.Type)
+
+	for _, content := range []string{
+		"kind: ScheduledSparkApplication\nspec:\n  type: Scala\n",
+		"kind: SparkApplication\n",
+		"spec:\n  type: Scala\n  sourceRef:\n    git:\n      url: https://example.com/repo.git\n",
+		"spec: [",
+	} {
+		_, err := LoadSpec([]byte(content))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	type testcase struct {
+		ref      string
+		expected reference
+	}
+	for _, test := range []testcase{
+		{"ghcr.io/org/pipelines:v1", reference{registry: "ghcr.io", repository: "org/pipelines", tag: "v1"}},
+		{"localhost:5000/pipelines", reference{registry: "localhost:5000", repository: "pipelines", tag: "latest"}},
+		{"org/pipelines@sha256:abc", reference{registry: dockerHubRegistry, repository: "org/pipelines", digest: "sha256:abc"}},
+		{"pipelines", reference{registry: dockerHubRegistry, repository: "library/pipelines", tag: "latest"}},
+	} {
+		ref, err := parseReference(test.ref)
+		assert.Nil(t, err, test.ref)
+		assert.Equal(t, test.expected, *ref, test.ref)
+	}
+	_, err := parseReference("ghcr.io/org/pipelines@md5:abc")
+	assert.NotNil(t, err)
+}
+
+func TestFetchOCI(t *testing.T) {
+	layerDigest := computeDigest([]byte(manifest))
+	artifact, _ := json.Marshal(ociManifest{Layers: []ociDescriptor{
+		{MediaType: "application/vnd.oci.image.layer.v1.tar", Digest: computeDigest([]byte("other")), Size: 5,
+			Annotations: map[string]string{layerTitleAnnotation: "other.yaml"}},
+		{MediaType: "application/vnd.oci.image.layer.v1.tar", Digest: layerDigest, Size: int64(len(manifest)),
+			Annotations: map[string]string{layerTitleAnnotation: "spark-pi.yaml"}},
+	}})
+	artifactDigest := computeDigest(artifact)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "ci", user)
+			assert.Equal(t, "token", password)
+			assert.Equal(t, "repository:org/pipelines:pull", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token": "registry-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer registry-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/pipelines/manifests/v1", "/v2/org/pipelines/manifests/" + artifactDigest:
+			w.Write(artifact)
+		case "/v2/org/pipelines/blobs/" + layerDigest:
+			fmt.Fprint(w, manifest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "http://")
+	credentials := &Credentials{Username: "ci", Password: "token"}
+	path := "spark-pi.yaml"
+	content, revision, err := FetchOCI(&v1beta1.OCISource{Reference: registry + "/org/pipelines:v1", Path: &path},
+		credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, string(content))
+	assert.Equal(t, artifactDigest, revision)
+
+	_, revision, err = FetchOCI(&v1beta1.OCISource{
+		Reference: registry + "/org/pipelines@" + artifactDigest, Path: &path}, credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, artifactDigest, revision)
+
+	// The artifact has two layers.
+	_, _, err = FetchOCI(&v1beta1.OCISource{Reference: registry + "/org/pipelines:v1"}, credentials)
+	assert.NotNil(t, err)
+
+	// The artifact is pinned to another digest.
+	_, _, err = FetchOCI(&v1beta1.OCISource{
+		Reference: registry + "/org/pipelines@" + computeDigest([]byte("other")), Path: &path}, credentials)
+	assert.NotNil(t, err)
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo, err := ioutil.TempDir("", "spark-source-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repo)
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"},
+			args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	if err := os.MkdirAll(filepath.Join(repo, "apps"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repo, "apps", "spark-pi.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "Add spark-pi")
+	git("tag", "v1")
+	sha := git("rev-parse", "HEAD")
+
+	source := &v1beta1.GitSource{URL: "file://" + repo, Path: "apps/spark-pi.yaml"}
+	content, revision, err := FetchGit(source, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, string(content))
+	assert.Equal(t, sha, revision)
+
+	tag := "v1"
+	source.Revision = &tag
+	_, revision, err = FetchGit(source, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, sha, revision)
+
+	source.Path = "apps/missing.yaml"
+	_, _, err = FetchGit(source, nil)
+	assert.NotNil(t, err)
+}
+
+func TestFetchGit_Timeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not installed")
+	}
+	defer func(timeout time.Duration) { gitTimeout = timeout }(gitTimeout)
+	defer func() { execCommandContext = exec.CommandContext }()
+	gitTimeout = 100 * time.Millisecond
+	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sleep", "10")
+	}
+
+	start := time.Now()
+	_, _, err := FetchGit(&v1beta1.GitSource{URL: "https://example.com/repo.git", Path: "app.yaml"}, nil)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "timed out")
+	}
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
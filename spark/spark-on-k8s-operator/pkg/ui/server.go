@@ -0,0 +1,614 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+const (
+	applicationsPath    = "/applications/"
+	apiApplicationsPath = "/api/applications"
+	// proxySecretHeader is the request header carrying the secret shared with the authenticating proxy.
+	proxySecretHeader = "X-Proxy-Secret"
+)
+
+// Server serves a web UI listing SparkApplications across namespaces, with the state, executors and progress
+// of each application, links to the driver UI and the Spark history server, and actions to kill or resubmit
+// an application.
+//
+// Users are authenticated either by a bearer token reviewed by the API server with a TokenReview, or by an
+// authenticating proxy, e.g., oauth2-proxy, in the same pod that passes the name and groups of the user in
+// request headers. The headers are only trusted on requests coming from the loopback interface, and, as other
+// processes in the pod such as a service mesh sidecar or a port-forward also connect from there, only if the
+// server only listens on the loopback interface or the request carries the secret shared with the proxy, so
+// that clients reaching the server directly cannot claim to be any user. Every request is authorized against the RBAC rules
+// of the cluster using a SubjectAccessReview for that user, so users see and act on exactly the applications
+// they could see and act on with kubectl.
+type Server struct {
+	crdClient  crdclientset.Interface
+	kubeClient kubernetes.Interface
+	lister     crdlisters.SparkApplicationLister
+	config     Config
+	server     *http.Server
+}
+
+// Config is the configuration of the UI server.
+type Config struct {
+	// Address is the address the server listens on, e.g., 127.0.0.1 to only be reachable through a proxy in the
+	// same pod. The server listens on all addresses if empty.
+	Address string
+	// Port is the port the server listens on.
+	Port int
+	// UserHeader is the request header carrying the name of the user authenticated by a proxy in the same pod.
+	// Users can only authenticate with bearer tokens if empty.
+	UserHeader string
+	// ProxySecret is the secret the proxy passes in the X-Proxy-Secret header. Required with UserHeader unless
+	// the server only listens on the loopback interface.
+	ProxySecret string
+	// GroupsHeader is the request header carrying the comma-separated groups of the user authenticated by a
+	// proxy in the same pod.
+	GroupsHeader string
+	// HistoryServerURL is the base URL of the Spark history server. No history links are shown if empty.
+	HistoryServerURL string
+}
+
+// user is an authenticated user of the UI.
+type user struct {
+	name   string
+	groups []string
+}
+
+// applicationSummary is what the UI shows, and the JSON API returns, for an application.
+type applicationSummary struct {
+	Namespace         string                       `json:"namespace"`
+	Name              string                       `json:"name"`
+	State             v1beta1.ApplicationStateType `json:"state"`
+	ErrorMessage      string                       `json:"errorMessage,omitempty"`
+	SubmittedBy       string                       `json:"submittedBy,omitempty"`
+	SubmissionTime    *metav1.Time                 `json:"submissionTime,omitempty"`
+	TerminationTime   *metav1.Time                 `json:"terminationTime,omitempty"`
+	RunningExecutors  int                          `json:"runningExecutors"`
+	FailedExecutors   int                          `json:"failedExecutors"`
+	TotalExecutors    int                          `json:"totalExecutors"`
+	PercentComplete   *int32                       `json:"percentComplete,omitempty"`
+	DriverUIURL       string                       `json:"driverUIURL,omitempty"`
+	HistoryServerURL  string                       `json:"historyServerURL,omitempty"`
+	ExecutionAttempts int32                        `json:"executionAttempts"`
+}
+
+// timelineEntry is an event of an application shown on its timeline.
+type timelineEntry struct {
+	Time    time.Time
+	Type    string
+	Reason  string
+	Message string
+}
+
+// Validate checks that the user headers of the given configuration can only be set by the proxy.
+func (c Config) Validate() error {
+	if c.UserHeader == "" || c.ProxySecret != "" {
+		return nil
+	}
+	if ip := net.ParseIP(c.Address); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("trusting the user header %s requires listening on a loopback address or a proxy secret",
+			c.UserHeader)
+	}
+	return nil
+}
+
+// NewServer creates a new UI server.
+func NewServer(
+	crdClient crdclientset.Interface,
+	kubeClient kubernetes.Interface,
+	crdInformerFactory crdinformers.SharedInformerFactory,
+	serverConfig Config) *Server {
+	s := &Server{
+		crdClient:  crdClient,
+		kubeClient: kubeClient,
+		lister:     crdInformerFactory.Sparkoperator().V1beta1().SparkApplications().Lister(),
+		config:     serverConfig,
+	}
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", serverConfig.Address, serverConfig.Port),
+		Handler: s.handler(),
+	}
+	return s
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.authenticated(s.serveList))
+	mux.HandleFunc(apiApplicationsPath, s.authenticated(s.serveAPIList))
+	mux.HandleFunc(applicationsPath, s.authenticated(s.serveApplication))
+	return mux
+}
+
+// Start starts the UI server.
+func (s *Server) Start() {
+	go func() {
+		glog.Infof("Starting the Spark operator UI server on port %d", s.config.Port)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			glog.Errorf("error while serving the Spark operator UI: %v", err)
+		}
+	}()
+}
+
+// Stop stops the UI server.
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	glog.Info("Stopping the Spark operator UI server")
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) authenticated(handle func(http.ResponseWriter, *http.Request, *user)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, err := s.authenticate(r)
+		if err != nil {
+			glog.Errorf("failed to authenticate request: %v", err)
+			http.Error(w, "failed to authenticate request", http.StatusInternalServerError)
+			return
+		}
+		if u == nil {
+			http.Error(w, "no authenticated user", http.StatusUnauthorized)
+			return
+		}
+		handle(w, r, u)
+	}
+}
+
+// authenticate returns the user of the given request, or nil if the user is not authenticated. The user headers
+// are only trusted on requests from the loopback interface, i.e., from a proxy in the same pod, that carry the
+// proxy secret if there is one.
+func (s *Server) authenticate(r *http.Request) (*user, error) {
+	info, err := util.AuthenticateBearerToken(s.kubeClient, r)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		return &user{name: info.Username, groups: info.Groups}, nil
+	}
+
+	if s.config.UserHeader == "" || !util.IsLoopbackRequest(r) {
+		return nil, nil
+	}
+	if s.config.ProxySecret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(proxySecretHeader)),
+		[]byte(s.config.ProxySecret)) != 1 {
+		return nil, nil
+	}
+	name := r.Header.Get(s.config.UserHeader)
+	if name == "" {
+		return nil, nil
+	}
+	u := &user{name: name}
+	for _, group := range strings.Split(r.Header.Get(s.config.GroupsHeader), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			u.groups = append(u.groups, group)
+		}
+	}
+	return u, nil
+}
+
+// allowed tells if the given user may perform the given verb on the given resource, as decided by the
+// authorizer of the API server.
+func (s *Server) allowed(u *user, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:               u.name,
+			Groups:             u.groups,
+			ResourceAttributes: &attributes,
+		},
+	}
+	result, err := s.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(review)
+	if err != nil {
+		return false, fmt.Errorf("failed to review access of user %s: %v", u.name, err)
+	}
+	return result.Status.Allowed, nil
+}
+
+func applicationAttributes(namespace, name, verb string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     v1beta1.SchemeGroupVersion.Group,
+		Resource:  "sparkapplications",
+		Name:      name,
+	}
+}
+
+// listApplications returns the applications in the given namespace, or in all namespaces if empty, in the
+// namespaces the given user may list applications in.
+func (s *Server) listApplications(u *user, namespace string) ([]*v1beta1.SparkApplication, error) {
+	var apps []*v1beta1.SparkApplication
+	var err error
+	if namespace == "" {
+		apps, err = s.lister.List(labels.Everything())
+	} else {
+		apps, err = s.lister.SparkApplications(namespace).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	allowedNamespaces := make(map[string]bool)
+	var visible []*v1beta1.SparkApplication
+	for _, app := range apps {
+		allowed, checked := allowedNamespaces[app.Namespace]
+		if !checked {
+			if allowed, err = s.allowed(u, applicationAttributes(app.Namespace, "", "list")); err != nil {
+				return nil, err
+			}
+			allowedNamespaces[app.Namespace] = allowed
+		}
+		if allowed {
+			visible = append(visible, app)
+		}
+	}
+	sort.Slice(visible, func(i, j int) bool {
+		if visible[i].Namespace != visible[j].Namespace {
+			return visible[i].Namespace < visible[j].Namespace
+		}
+		return visible[i].Name < visible[j].Name
+	})
+	return visible, nil
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, u *user) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	namespace := r.URL.Query().Get("namespace")
+	apps, err := s.listApplications(u, namespace)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+
+	var summaries []applicationSummary
+	for _, app := range apps {
+		summaries = append(summaries, s.summarize(app))
+	}
+	s.render(w, listTemplate, map[string]interface{}{
+		"User":         u.name,
+		"Namespace":    namespace,
+		"Applications": summaries,
+	})
+}
+
+func (s *Server) serveAPIList(w http.ResponseWriter, r *http.Request, u *user) {
+	apps, err := s.listApplications(u, r.URL.Query().Get("namespace"))
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+
+	summaries := []applicationSummary{}
+	for _, app := range apps {
+		summaries = append(summaries, s.summarize(app))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summaries); err != nil {
+		glog.Errorf("failed to write the list of SparkApplications: %v", err)
+	}
+}
+
+// serveApplication serves the page of an application at /applications/<namespace>/<name> and the actions on
+// it at /applications/<namespace>/<name>/<action>.
+func (s *Server) serveApplication(w http.ResponseWriter, r *http.Request, u *user) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, applicationsPath), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	namespace, name := parts[0], parts[1]
+
+	app, err := s.lister.SparkApplications(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+
+	if len(parts) == 2 {
+		s.serveDetails(w, u, app)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "actions must be posted", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isSameOrigin(r) {
+		http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		return
+	}
+	switch parts[2] {
+	case "kill":
+		s.kill(w, r, u, app)
+	case "resubmit":
+		s.resubmit(w, r, u, app)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveDetails(w http.ResponseWriter, u *user, app *v1beta1.SparkApplication) {
+	if !s.authorize(w, u, applicationAttributes(app.Namespace, app.Name, "get")) {
+		return
+	}
+
+	timeline, err := s.getTimeline(app)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	executors := make([]string, 0, len(app.Status.ExecutorState))
+	for name := range app.Status.ExecutorState {
+		executors = append(executors, name)
+	}
+	sort.Strings(executors)
+
+	s.render(w, detailsTemplate, map[string]interface{}{
+		"User":          u.name,
+		"Application":   s.summarize(app),
+		"Progress":      app.Status.Progress,
+		"Executors":     executors,
+		"ExecutorState": app.Status.ExecutorState,
+		"Timeline":      timeline,
+	})
+}
+
+// getTimeline returns the events recorded for the given application, oldest first. The events record every
+// state transition of the application and its driver and executors.
+func (s *Server) getTimeline(app *v1beta1.SparkApplication) ([]timelineEntry, error) {
+	selector := fields.Set{
+		"involvedObject.kind": "SparkApplication",
+		"involvedObject.name": app.Name,
+	}.AsSelector().String()
+	events, err := s.kubeClient.CoreV1().Events(app.Namespace).List(metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the events of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	}
+
+	var timeline []timelineEntry
+	for _, event := range events.Items {
+		if event.InvolvedObject.UID != "" && event.InvolvedObject.UID != app.UID {
+			// The event belongs to a deleted application with the same name.
+			continue
+		}
+		entryTime := event.LastTimestamp.Time
+		if entryTime.IsZero() {
+			entryTime = event.CreationTimestamp.Time
+		}
+		timeline = append(timeline, timelineEntry{
+			Time:    entryTime,
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Message: event.Message,
+		})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].Time.Before(timeline[j].Time) })
+	return timeline, nil
+}
+
+// kill deletes the driver pod of the given application. Whether the application is run again then depends
+// on its restart policy, as for any other driver failure.
+func (s *Server) kill(w http.ResponseWriter, r *http.Request, u *user, app *v1beta1.SparkApplication) {
+	podName := app.Status.DriverInfo.PodName
+	if podName == "" || isTerminated(app) {
+		http.Error(w, "the application is not running", http.StatusConflict)
+		return
+	}
+	if !s.authorize(w, u, authorizationv1.ResourceAttributes{
+		Namespace: app.Namespace,
+		Verb:      "delete",
+		Resource:  "pods",
+		Name:      podName,
+	}) {
+		return
+	}
+
+	glog.Infof("User %s is killing SparkApplication %s/%s", u.name, app.Namespace, app.Name)
+	err := s.kubeClient.CoreV1().Pods(app.Namespace).Delete(podName, metav1.NewDeleteOptions(0))
+	if err != nil && !errors.IsNotFound(err) {
+		s.serveError(w, fmt.Errorf("failed to delete driver pod %s/%s: %v", app.Namespace, podName, err))
+		return
+	}
+	redirectToApplication(w, r, app)
+}
+
+// resubmit invalidates the current run of the given application, the same way a change of its spec does, so
+// the operator cleans up the run and submits the application again.
+func (s *Server) resubmit(w http.ResponseWriter, r *http.Request, u *user, app *v1beta1.SparkApplication) {
+	if !s.authorize(w, u, applicationAttributes(app.Namespace, app.Name, "update")) {
+		return
+	}
+
+	glog.Infof("User %s is resubmitting SparkApplication %s/%s", u.name, app.Namespace, app.Name)
+	toUpdate := app.DeepCopy()
+	toUpdate.Status.AppState.State = v1beta1.InvalidatingState
+	toUpdate.Status.AppState.ErrorMessage = ""
+	if _, err := s.crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).UpdateStatus(toUpdate); err != nil {
+		s.serveError(w, fmt.Errorf("failed to resubmit SparkApplication %s/%s: %v", app.Namespace, app.Name, err))
+		return
+	}
+	redirectToApplication(w, r, app)
+}
+
+// authorize tells if the given user is allowed access to the given resource, and responds with an error if
+// not.
+func (s *Server) authorize(w http.ResponseWriter, u *user, attributes authorizationv1.ResourceAttributes) bool {
+	allowed, err := s.allowed(u, attributes)
+	if err != nil {
+		s.serveError(w, err)
+		return false
+	}
+	if !allowed {
+		http.Error(w, fmt.Sprintf("user %s cannot %s %s %s/%s", u.name, attributes.Verb, attributes.Resource,
+			attributes.Namespace, attributes.Name), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (s *Server) summarize(app *v1beta1.SparkApplication) applicationSummary {
+	summary := applicationSummary{
+		Namespace:         app.Namespace,
+		Name:              app.Name,
+		State:             app.Status.AppState.State,
+		ErrorMessage:      app.Status.AppState.ErrorMessage,
+		SubmittedBy:       app.Status.SubmittedBy,
+		TotalExecutors:    len(app.Status.ExecutorState),
+		ExecutionAttempts: app.Status.ExecutionAttempts,
+	}
+	if summary.State == "" {
+		summary.State = v1beta1.NewState
+	}
+	if !app.Status.LastSubmissionAttemptTime.IsZero() {
+		summary.SubmissionTime = &app.Status.LastSubmissionAttemptTime
+	}
+	if !app.Status.TerminationTime.IsZero() {
+		summary.TerminationTime = &app.Status.TerminationTime
+	}
+	for _, state := range app.Status.ExecutorState {
+		switch state {
+		case v1beta1.ExecutorRunningState:
+			summary.RunningExecutors++
+		case v1beta1.ExecutorFailedState:
+			summary.FailedExecutors++
+		}
+	}
+	if app.Status.Progress != nil {
+		percent := app.Status.Progress.PercentComplete
+		summary.PercentComplete = &percent
+	}
+	if !isTerminated(app) {
+		summary.DriverUIURL = getDriverUIURL(app)
+	}
+	if s.config.HistoryServerURL != "" && app.Status.SparkApplicationID != "" {
+		summary.HistoryServerURL = fmt.Sprintf("%s/history/%s/", strings.TrimRight(s.config.HistoryServerURL, "/"),
+			url.PathEscape(app.Status.SparkApplicationID))
+	}
+	return summary
+}
+
+func getDriverUIURL(app *v1beta1.SparkApplication) string {
+	address := app.Status.DriverInfo.WebUIIngressAddress
+	if address == "" {
+		address = app.Status.DriverInfo.WebUIAddress
+	}
+	if address == "" {
+		return ""
+	}
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+		address = "http://" + address
+	}
+	return address
+}
+
+func isTerminated(app *v1beta1.SparkApplication) bool {
+	switch app.Status.AppState.State {
+	case v1beta1.CompletedState, v1beta1.FailedState, v1beta1.SkippedState:
+		return true
+	}
+	return false
+}
+
+// isSameOrigin tells if the given request, if sent by a browser, was sent from a page of the UI itself, so that
+// other sites cannot make the browser of a logged-in user perform actions.
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	return err == nil && originURL.Host == r.Host
+}
+
+func redirectToApplication(w http.ResponseWriter, r *http.Request, app *v1beta1.SparkApplication) {
+	http.Redirect(w, r, applicationsPath+app.Namespace+"/"+app.Name, http.StatusSeeOther)
+}
+
+func (s *Server) render(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		glog.Errorf("failed to render %s: %v", tmpl.Name(), err)
+	}
+}
+
+func (s *Server) serveError(w http.ResponseWriter, err error) {
+	glog.Errorf("failed to serve UI request: %v", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
+
+// stateClass returns the CSS class an application state is shown with.
+func stateClass(state v1beta1.ApplicationStateType) string {
+	switch state {
+	case v1beta1.RunningState, v1beta1.SubmittedState:
+		return "running"
+	case v1beta1.CompletedState, v1beta1.SucceedingState:
+		return "completed"
+	case v1beta1.FailedState, v1beta1.FailingState, v1beta1.FailedSubmissionState:
+		return "failed"
+	}
+	return ""
+}
+
+// executorStateClass returns the CSS class an executor state is shown with.
+func executorStateClass(state v1beta1.ExecutorState) string {
+	switch state {
+	case v1beta1.ExecutorRunningState:
+		return "running"
+	case v1beta1.ExecutorCompletedState:
+		return "completed"
+	case v1beta1.ExecutorFailedState:
+		return "failed"
+	}
+	return ""
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
+)
+
+// newFakeServer creates a server on fake clients, where users may perform a verb on a resource in a namespace
+// if the given permissions contain "<user> <verb> <resource> <namespace>".
+func newFakeServer(permissions []string, apps ...*v1beta1.SparkApplication) *Server {
+	crdClient := crdclientfake.NewSimpleClientset()
+	kubeClient := kubeclientfake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		permission := strings.Join([]string{review.Spec.User, attributes.Verb, attributes.Resource, attributes.Namespace}, " ")
+		for _, p := range permissions {
+			if p == permission {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "tokenreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if strings.HasPrefix(review.Spec.Token, "token-of-") {
+			review.Status.Authenticated = true
+			review.Status.User.Username = strings.TrimPrefix(review.Spec.Token, "token-of-")
+		}
+		return true, review, nil
+	})
+
+	informerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0*time.Second)
+	server := NewServer(crdClient, kubeClient, informerFactory, Config{
+		UserHeader:       "X-Forwarded-User",
+		GroupsHeader:     "X-Forwarded-Groups",
+		HistoryServerURL: "https://history.example.com/",
+	})
+	informer := informerFactory.Sparkoperator().V1beta1().SparkApplications().Informer()
+	for _, app := range apps {
+		crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+		informer.GetIndexer().Add(app)
+	}
+	return server
+}
+
+func newApp(namespace, name string, state v1beta1.ApplicationStateType) *v1beta1.SparkApplication {
+	return &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: v1beta1.SparkApplicationStatus{
+			SparkApplicationID: "spark-" + name,
+			AppState:           v1beta1.ApplicationState{State: state},
+			DriverInfo: v1beta1.DriverInfo{
+				PodName:      name + "-driver",
+				WebUIAddress: "10.0.0.1:4040",
+			},
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				name + "-exec-1": v1beta1.ExecutorRunningState,
+				name + "-exec-2": v1beta1.ExecutorFailedState,
+			},
+		},
+	}
+}
+
+func serve(s *Server, method, path, userName string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.RemoteAddr = "127.0.0.1:41234"
+	if userName != "" {
+		request.Header.Set("X-Forwarded-User", userName)
+	}
+	recorder := httptest.NewRecorder()
+	s.handler().ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newFakeServer([]string{"alice list sparkapplications team-a"}, newApp("team-a", "foo", v1beta1.RunningState))
+	serveFrom := func(remoteAddr string, header, value string) int {
+		request := httptest.NewRequest(http.MethodGet, apiApplicationsPath, nil)
+		request.RemoteAddr = remoteAddr
+		request.Header.Set(header, value)
+		recorder := httptest.NewRecorder()
+		s.handler().ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// The user headers are only trusted from a proxy in the same pod.
+	assert.Equal(t, http.StatusOK, serveFrom("127.0.0.1:41234", "X-Forwarded-User", "alice"))
+	assert.Equal(t, http.StatusOK, serveFrom("[::1]:41234", "X-Forwarded-User", "alice"))
+	assert.Equal(t, http.StatusUnauthorized, serveFrom("10.0.0.2:41234", "X-Forwarded-User", "alice"))
+
+	// Bearer tokens are reviewed by the API server.
+	assert.Equal(t, http.StatusOK, serveFrom("10.0.0.2:41234", "Authorization", "Bearer token-of-alice"))
+	assert.Equal(t, http.StatusUnauthorized, serveFrom("10.0.0.2:41234", "Authorization", "Bearer forged"))
+
+	// With a proxy secret, the user headers are only trusted on requests carrying it.
+	s.config.ProxySecret = "s3cr3t"
+	assert.Equal(t, http.StatusUnauthorized, serveFrom("127.0.0.1:41234", "X-Forwarded-User", "alice"))
+	request := httptest.NewRequest(http.MethodGet, apiApplicationsPath, nil)
+	request.RemoteAddr = "127.0.0.1:41234"
+	request.Header.Set("X-Forwarded-User", "alice")
+	request.Header.Set(proxySecretHeader, "s3cr3t")
+	recorder := httptest.NewRecorder()
+	s.handler().ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	request.Header.Set(proxySecretHeader, "forged")
+	recorder = httptest.NewRecorder()
+	s.handler().ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	s.config.UserHeader = ""
+	assert.Equal(t, http.StatusUnauthorized, serveFrom("127.0.0.1:41234", "X-Forwarded-User", "alice"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.Nil(t, Config{}.Validate())
+	assert.Nil(t, Config{UserHeader: "X-Forwarded-User", Address: "127.0.0.1"}.Validate())
+	assert.Nil(t, Config{UserHeader: "X-Forwarded-User", Address: "::1"}.Validate())
+	assert.Nil(t, Config{UserHeader: "X-Forwarded-User", ProxySecret: "s3cr3t"}.Validate())
+	assert.NotNil(t, Config{UserHeader: "X-Forwarded-User"}.Validate())
+	assert.NotNil(t, Config{UserHeader: "X-Forwarded-User", Address: "0.0.0.0"}.Validate())
+}
+
+func TestServeAPIList(t *testing.T) {
+	s := newFakeServer([]string{"alice list sparkapplications team-a"},
+		newApp("team-a", "foo", v1beta1.RunningState),
+		newApp("team-b", "bar", v1beta1.RunningState))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(s, http.MethodGet, apiApplicationsPath, "").Code)
+
+	response := serve(s, http.MethodGet, apiApplicationsPath, "alice")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var summaries []applicationSummary
+	if err := json.Unmarshal(response.Body.Bytes(), &summaries); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(summaries)) {
+		assert.Equal(t, "foo", summaries[0].Name)
+		assert.Equal(t, 1, summaries[0].RunningExecutors)
+		assert.Equal(t, 1, summaries[0].FailedExecutors)
+		assert.Equal(t, 2, summaries[0].TotalExecutors)
+		assert.Equal(t, "http://10.0.0.1:4040", summaries[0].DriverUIURL)
+		assert.Equal(t, "https://history.example.com/history/spark-foo/", summaries[0].HistoryServerURL)
+	}
+
+	response = serve(s, http.MethodGet, apiApplicationsPath+"?namespace=team-b", "alice")
+	assert.Equal(t, "[]\n", response.Body.String())
+}
+
+func TestServeList(t *testing.T) {
+	app := newApp("team-a", "foo", v1beta1.RunningState)
+	app.Status.Progress = &v1beta1.ApplicationProgress{PercentComplete: 42}
+	s := newFakeServer([]string{"alice list sparkapplications team-a"}, app)
+
+	response := serve(s, http.MethodGet, "/", "alice")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<a href="/applications/team-a/foo">foo</a>`)
+	assert.Contains(t, response.Body.String(), `42%`)
+
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/foo", "alice").Code)
+}
+
+func TestServeDetails(t *testing.T) {
+	app := newApp("team-a", "foo", v1beta1.RunningState)
+	s := newFakeServer([]string{"alice get sparkapplications team-a"}, app)
+	s.kubeClient.CoreV1().Events("team-a").Create(&apiv1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "foo.1", Namespace: "team-a"},
+		InvolvedObject: apiv1.ObjectReference{Kind: "SparkApplication", Name: "foo", Namespace: "team-a"},
+		Type:           apiv1.EventTypeNormal,
+		Reason:         "SparkApplicationSubmitted",
+		Message:        "SparkApplication foo was submitted successfully",
+		LastTimestamp:  metav1.Now(),
+	})
+
+	response := serve(s, http.MethodGet, "/applications/team-a/foo", "alice")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "SparkApplicationSubmitted")
+	assert.Contains(t, response.Body.String(), "foo-exec-2")
+
+	assert.Equal(t, http.StatusForbidden, serve(s, http.MethodGet, "/applications/team-a/foo", "bob").Code)
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/applications/team-a/bar", "alice").Code)
+}
+
+func TestKill(t *testing.T) {
+	app := newApp("team-a", "foo", v1beta1.RunningState)
+	s := newFakeServer([]string{"alice delete pods team-a"}, app)
+	s.kubeClient.CoreV1().Pods("team-a").Create(&apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "team-a"},
+	})
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(s, http.MethodGet, "/applications/team-a/foo/kill", "alice").Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, http.MethodPost, "/applications/team-a/foo/kill", "bob").Code)
+
+	request := httptest.NewRequest(http.MethodPost, "/applications/team-a/foo/kill", nil)
+	request.RemoteAddr = "127.0.0.1:41234"
+	request.Header.Set("X-Forwarded-User", "alice")
+	request.Header.Set("Origin", "https://evil.example.com")
+	recorder := httptest.NewRecorder()
+	s.handler().ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	response := serve(s, http.MethodPost, "/applications/team-a/foo/kill", "alice")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	_, err := s.kubeClient.CoreV1().Pods("team-a").Get("foo-driver", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	completed := newApp("team-a", "bar", v1beta1.CompletedState)
+	s = newFakeServer([]string{"alice delete pods team-a"}, completed)
+	assert.Equal(t, http.StatusConflict, serve(s, http.MethodPost, "/applications/team-a/bar/kill", "alice").Code)
+}
+
+func TestResubmit(t *testing.T) {
+	app := newApp("team-a", "foo", v1beta1.FailedState)
+	app.Status.AppState.ErrorMessage = "driver failed"
+	s := newFakeServer([]string{"alice update sparkapplications team-a"}, app)
+
+	assert.Equal(t, http.StatusForbidden, serve(s, http.MethodPost, "/applications/team-a/foo/resubmit", "bob").Code)
+
+	response := serve(s, http.MethodPost, "/applications/team-a/foo/resubmit", "alice")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	updated, err := s.crdClient.SparkoperatorV1beta1().SparkApplications("team-a").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.InvalidatingState, updated.Status.AppState.State)
+	assert.Equal(t, "", updated.Status.AppState.ErrorMessage)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"html/template"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var templateFuncs = template.FuncMap{
+	"stateClass":         stateClass,
+	"executorStateClass": executorStateClass,
+	"formatTime":         formatTime,
+}
+
+var listTemplate = template.Must(template.New("list").Funcs(templateFuncs).Parse(layoutHeader + `
+<h1>Spark Applications{{if .Namespace}} in {{.Namespace}}{{end}}</h1>
+<form method="get" action="/">
+  <input name="namespace" placeholder="All namespaces" value="{{.Namespace}}">
+  <button type="submit">Filter</button>
+</form>
+<table>
+  <tr>
+    <th>Namespace</th><th>Name</th><th>State</th><th>Submitted By</th><th>Submitted</th><th>Terminated</th>
+    <th>Executors (running/failed/total)</th><th>Progress</th><th>Links</th>
+  </tr>
+  {{range .Applications}}
+  <tr>
+    <td><a href="/?namespace={{.Namespace}}">{{.Namespace}}</a></td>
+    <td><a href="/applications/{{.Namespace}}/{{.Name}}">{{.Name}}</a></td>
+    <td class="{{stateClass .State}}">{{.State}}</td>
+    <td>{{.SubmittedBy}}</td>
+    <td>{{formatTime .SubmissionTime}}</td>
+    <td>{{formatTime .TerminationTime}}</td>
+    <td>{{.RunningExecutors}}/{{.FailedExecutors}}/{{.TotalExecutors}}</td>
+    <td>{{if .PercentComplete}}{{.PercentComplete}}%{{end}}</td>
+    <td>
+      {{if .DriverUIURL}}<a href="{{.DriverUIURL}}">Driver UI</a>{{end}}
+      {{if .HistoryServerURL}}<a href="{{.HistoryServerURL}}">History</a>{{end}}
+    </td>
+  </tr>
+  {{else}}
+  <tr><td colspan="9">No applications found.</td></tr>
+  {{end}}
+</table>
+` + layoutFooter))
+
+var detailsTemplate = template.Must(template.New("details").Funcs(templateFuncs).Parse(layoutHeader + `
+{{with .Application}}
+<p><a href="/">All applications</a> / <a href="/?namespace={{.Namespace}}">{{.Namespace}}</a></p>
+<h1>{{.Name}}</h1>
+<table>
+  <tr><th>State</th><td class="{{stateClass .State}}">{{.State}}</td></tr>
+  {{if .ErrorMessage}}<tr><th>Error</th><td><pre>{{.ErrorMessage}}</pre></td></tr>{{end}}
+  <tr><th>Submitted By</th><td>{{.SubmittedBy}}</td></tr>
+  <tr><th>Submitted</th><td>{{formatTime .SubmissionTime}}</td></tr>
+  <tr><th>Terminated</th><td>{{formatTime .TerminationTime}}</td></tr>
+  <tr><th>Execution Attempts</th><td>{{.ExecutionAttempts}}</td></tr>
+  <tr><th>Executors (running/failed/total)</th><td>{{.RunningExecutors}}/{{.FailedExecutors}}/{{.TotalExecutors}}</td></tr>
+  <tr><th>Links</th><td>
+    {{if .DriverUIURL}}<a href="{{.DriverUIURL}}">Driver UI</a>{{end}}
+    {{if .HistoryServerURL}}<a href="{{.HistoryServerURL}}">History</a>{{end}}
+  </td></tr>
+</table>
+<form method="post" action="/applications/{{.Namespace}}/{{.Name}}/kill" onsubmit="return confirm('Kill {{.Name}}?')">
+  <button type="submit">Kill</button>
+</form>
+<form method="post" action="/applications/{{.Namespace}}/{{.Name}}/resubmit" onsubmit="return confirm('Resubmit {{.Name}}?')">
+  <button type="submit">Resubmit</button>
+</form>
+{{end}}
+
+{{with .Progress}}
+<h2>Progress: {{.PercentComplete}}%</h2>
+<p>{{.ActiveJobs}} active, {{.CompletedJobs}} completed and {{.FailedJobs}} failed jobs.</p>
+{{if .ActiveStages}}
+<table>
+  <tr><th>Stage</th><th>Name</th><th>Tasks (completed/total)</th></tr>
+  {{range .ActiveStages}}
+  <tr><td>{{.StageID}}</td><td>{{.Name}}</td><td>{{.CompletedTasks}}/{{.TotalTasks}}</td></tr>
+  {{end}}
+</table>
+{{end}}
+{{end}}
+
+<h2>Executors</h2>
+<table>
+  <tr><th>Pod</th><th>State</th></tr>
+  {{range .Executors}}
+  {{$state := index $.ExecutorState .}}
+  <tr><td>{{.}}</td><td class="{{executorStateClass $state}}">{{$state}}</td></tr>
+  {{else}}
+  <tr><td colspan="2">No executors.</td></tr>
+  {{end}}
+</table>
+
+<h2>Timeline</h2>
+<table>
+  <tr><th>Time</th><th>Type</th><th>Reason</th><th>Message</th></tr>
+  {{range .Timeline}}
+  <tr><td>{{.Time.Format "2006-01-02 15:04:05 MST"}}</td><td>{{.Type}}</td><td>{{.Reason}}</td><td>{{.Message}}</td></tr>
+  {{else}}
+  <tr><td colspan="4">No events recorded.</td></tr>
+  {{end}}
+</table>
+` + layoutFooter))
+
+const layoutHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Spark Operator</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; }
+  table { border-collapse: collapse; margin-bottom: 1em; }
+  th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+  th { background: #f4f4f4; }
+  form { display: inline-block; margin: 0 0.5em 1em 0; }
+  .running { color: #1a73e8; }
+  .completed { color: #188038; }
+  .failed { color: #d93025; }
+  .user { float: right; color: #666; }
+</style>
+</head>
+<body>
+<div class="user">{{.User}}</div>
+`
+
+const layoutFooter = `
+</body>
+</html>
+`
+
+func formatTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
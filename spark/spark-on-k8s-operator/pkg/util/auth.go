@@ -0,0 +1,61 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AuthenticateBearerToken returns the user the bearer token of the given request belongs to, as authenticated by
+// the API server with a TokenReview. It returns nil if the request carries no bearer token or the token is not
+// authenticated.
+func AuthenticateBearerToken(kubeClient kubernetes.Interface, r *http.Request) (*authenticationv1.UserInfo, error) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return nil, nil
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if token == "" {
+		return nil, nil
+	}
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	result, err := kubeClient.AuthenticationV1().TokenReviews().Create(review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review bearer token: %v", err)
+	}
+	if !result.Status.Authenticated {
+		return nil, nil
+	}
+	return &result.Status.User, nil
+}
+
+// IsLoopbackRequest tells if the given request comes from the loopback interface, e.g., from a proxy in the same
+// pod.
+func IsLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -19,7 +19,7 @@ package util
 import (
 	"fmt"
 	"net/http"
-	"strings"
+	"regexp"
 	"sync"
 
 	"github.com/golang/glog"
@@ -30,9 +30,12 @@ import (
 	"k8s.io/client-go/util/workqueue"
 )
 
+var invalidMetricNameCharsRegex = regexp.MustCompile("[^a-zA-Z0-9_:]")
+
 func CreateValidMetricNameLabel(prefix, name string) string {
-	// "-" is not a valid character for prometheus metric names or labels.
-	return strings.Replace(prefix+name, "-", "_", -1)
+	// Only letters, digits, "_", and ":" are valid characters for prometheus metric names or labels, so that,
+	// e.g., "-", "." and "/" of Kubernetes label names are replaced.
+	return invalidMetricNameCharsRegex.ReplaceAllString(prefix+name, "_")
 }
 
 // Best effort metric registration with Prometheus.
@@ -0,0 +1,54 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DNS1123LabelMaxLength is the maximum length of names that must be DNS labels, e.g., of Services and
+	// Volumes.
+	DNS1123LabelMaxLength = 63
+	// DNS1123SubdomainMaxLength is the maximum length of names that must be DNS subdomains, e.g., of Pods and
+	// Ingresses.
+	DNS1123SubdomainMaxLength = 253
+	// nameHashLength is the length of the hash suffix, including the separating dash, added to truncated names.
+	nameHashLength = 9
+)
+
+// TruncateName returns the given name if it is no longer than maxLength. Otherwise, it truncates the name and
+// appends a hash of the full name, so that distinct names sharing a long common prefix remain distinct.
+func TruncateName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	hasher := NewHash32()
+	hasher.Write([]byte(name))
+	prefix := strings.TrimRight(name[:maxLength-nameHashLength], "-.")
+	return fmt.Sprintf("%s-%08x", prefix, hasher.Sum32())
+}
+
+// BuildName returns a name of the form "<base>-<suffix>" that is no longer than maxLength. If needed, base is
+// truncated as done by TruncateName, so the suffix always remains intact.
+func BuildName(base string, suffix string, maxLength int) string {
+	if len(base)+1+len(suffix) <= maxLength {
+		return fmt.Sprintf("%s-%s", base, suffix)
+	}
+	return fmt.Sprintf("%s-%s", TruncateName(base, maxLength-1-len(suffix)), suffix)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateName(t *testing.T) {
+	assert.Equal(t, "spark-pi", TruncateName("spark-pi", DNS1123LabelMaxLength))
+
+	long1 := strings.Repeat("a", 70) + "-1"
+	long2 := strings.Repeat("a", 70) + "-2"
+	truncated1 := TruncateName(long1, DNS1123LabelMaxLength)
+	truncated2 := TruncateName(long2, DNS1123LabelMaxLength)
+	assert.Equal(t, DNS1123LabelMaxLength, len(truncated1))
+	assert.NotEqual(t, truncated1, truncated2)
+	// Truncation is deterministic.
+	assert.Equal(t, truncated1, TruncateName(long1, DNS1123LabelMaxLength))
+
+	// No dash is left dangling before the hash suffix.
+	truncated := TruncateName(strings.Repeat("a", 53)+"-"+strings.Repeat("b", 20), DNS1123LabelMaxLength)
+	assert.True(t, strings.HasPrefix(truncated, strings.Repeat("a", 53)+"-"))
+	assert.False(t, strings.Contains(truncated, "--"))
+}
+
+func TestBuildName(t *testing.T) {
+	assert.Equal(t, "spark-pi-ui-svc", BuildName("spark-pi", "ui-svc", DNS1123LabelMaxLength))
+
+	name1 := BuildName(strings.Repeat("a", 60)+"-1", "ui-svc", DNS1123LabelMaxLength)
+	name2 := BuildName(strings.Repeat("a", 60)+"-2", "ui-svc", DNS1123LabelMaxLength)
+	assert.Equal(t, DNS1123LabelMaxLength, len(name1))
+	assert.True(t, strings.HasSuffix(name1, "-ui-svc"))
+	assert.NotEqual(t, name1, name2)
+}
@@ -19,7 +19,9 @@ package util
 import (
 	"hash"
 	"hash/fnv"
+	"path"
 	"reflect"
+	"strconv"
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -55,7 +57,161 @@ func IsDriverPod(pod *apiv1.Pod) bool {
 	return pod.Labels[config.SparkRoleLabel] == config.SparkDriverRole
 }
 
+// GetUIProxyPort returns the port the OAuth2 proxy in front of the driver UI of the given app listens on.
+func GetUIProxyPort(app *v1beta1.SparkApplication) int32 {
+	proxy := app.Spec.Driver.UIProxy
+	if proxy != nil && proxy.Port != nil {
+		return *proxy.Port
+	}
+	return config.DefaultUIProxyPort
+}
+
+// GetConnectionPoolerPort returns the port the connection pooler sidecar of the given app listens on.
+func GetConnectionPoolerPort(app *v1beta1.SparkApplication) int32 {
+	if app.Spec.ConnectionPooler.Port != nil {
+		return *app.Spec.ConnectionPooler.Port
+	}
+	return config.DefaultConnectionPoolerPort
+}
+
+// GetExecutorGroupResourceProfileID returns the ID of the Spark resource profile of the executor group at the
+// given index in the ExecutorGroups of the given app.
+func GetExecutorGroupResourceProfileID(app *v1beta1.SparkApplication, index int) int32 {
+	if id := app.Spec.ExecutorGroups[index].ResourceProfileID; id != nil {
+		return *id
+	}
+	return int32(index + 1)
+}
+
 // IsExecutorPod returns whether the given pod is a Spark executor Pod.
 func IsExecutorPod(pod *apiv1.Pod) bool {
 	return pod.Labels[config.SparkRoleLabel] == config.SparkExecutorRole
 }
+
+// GetStartGateConfigMapName returns the name of the ConfigMap backing the start gate of the given app.
+func GetStartGateConfigMapName(app *v1beta1.SparkApplication) string {
+	return BuildName(app.Name, "start-gate", DNS1123SubdomainMaxLength)
+}
+
+// GetGPUDiscoveryConfigMapName returns the name of the ConfigMap holding the GPU discovery script of the given app.
+func GetGPUDiscoveryConfigMapName(app *v1beta1.SparkApplication) string {
+	return BuildName(app.Name, "gpu-discovery", DNS1123SubdomainMaxLength)
+}
+
+// GetHadoopConfigMapName returns the name of the ConfigMap holding the Hadoop configuration files of the given app,
+// or nil if it has none. The operator generates the ConfigMap of an app referring to a HadoopCluster.
+func GetHadoopConfigMapName(app *v1beta1.SparkApplication) *string {
+	if app.Spec.HadoopClusterRef != nil {
+		name := BuildName(app.Name, "hadoop-conf", DNS1123SubdomainMaxLength)
+		return &name
+	}
+	return app.Spec.HadoopConfigMap
+}
+
+// GetDataCacheDaemonSetName returns the name of the DaemonSet of the cache daemons of the data cache with the given
+// name.
+func GetDataCacheDaemonSetName(cacheName string) string {
+	return BuildName(cacheName, "data-cache", DNS1123LabelMaxLength)
+}
+
+// GetDataCacheHostPath returns the directory on the nodes the data cache of the given app is kept in.
+func GetDataCacheHostPath(app *v1beta1.SparkApplication) string {
+	return path.Join(config.DataCacheHostDir, app.Namespace, app.Spec.DataCache.Name)
+}
+
+// GetDataCacheMountPath returns the path the data cache of the given app is mounted at in its executors.
+func GetDataCacheMountPath(app *v1beta1.SparkApplication) string {
+	if app.Spec.DataCache.MountPath != nil {
+		return *app.Spec.DataCache.MountPath
+	}
+	return config.DefaultDataCacheMountPath
+}
+
+// GetGPUSpec returns the GPUs of each executor of the given app, or nil if its executors have none.
+func GetGPUSpec(app *v1beta1.SparkApplication) *v1beta1.GPUSpec {
+	if app.Spec.GPU == nil || !app.Spec.GPU.Enabled {
+		return nil
+	}
+	gpu := &v1beta1.GPUSpec{Name: config.DefaultGPUResourceName, Quantity: 1}
+	if app.Spec.GPU.Name != nil {
+		gpu.Name = *app.Spec.GPU.Name
+	}
+	if app.Spec.GPU.Quantity != nil {
+		gpu.Quantity = *app.Spec.GPU.Quantity
+	}
+	return gpu
+}
+
+// GetExecutorInstances returns the number of executors the given app requests.
+func GetExecutorInstances(app *v1beta1.SparkApplication) int32 {
+	if app.Spec.Executor.Instances != nil {
+		return *app.Spec.Executor.Instances
+	}
+	if value, ok := app.Spec.SparkConf[config.SparkExecutorInstancesKey]; ok {
+		if instances, err := strconv.ParseInt(value, 10, 32); err == nil {
+			return int32(instances)
+		}
+	}
+	return config.DefaultSparkExecutorInstances
+}
+
+// IsDynamicAllocationEnabled returns whether the given app enables Spark dynamic allocation.
+func IsDynamicAllocationEnabled(app *v1beta1.SparkApplication) bool {
+	enabled, _ := strconv.ParseBool(app.Spec.SparkConf[config.SparkDynamicAllocationEnabledKey])
+	return enabled
+}
+
+// GetExecutorLimit returns the number of executors Spark may run for the current run of the given app, and
+// whether it is limited, which is the case once the operator has reclaimed idle executors of the run.
+func GetExecutorLimit(app *v1beta1.SparkApplication) (int32, bool) {
+	if app.Status.ReclaimedExecutors <= 0 {
+		return 0, false
+	}
+	limit := GetExecutorInstances(app) - app.Status.ReclaimedExecutors
+	if limit < 0 {
+		limit = 0
+	}
+	return limit, true
+}
+
+// MergeOwnedEntries returns the given existing labels or annotations of an object with the entries its owner
+// sets, so that updates by the owner keep the entries set by users and other tools, e.g., GitOps controllers.
+func MergeOwnedEntries(existing, owned map[string]string) map[string]string {
+	if len(existing) == 0 && len(owned) == 0 {
+		return existing
+	}
+	merged := make(map[string]string, len(existing)+len(owned))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range owned {
+		merged[key] = value
+	}
+	return merged
+}
+
+// GetPropagatedMetadata returns the labels and annotations of the given app that are copied to the resources of
+// it: the ones with the given keys, which are propagated for every app, and the ones with the keys listed in the
+// metadataPropagation of the app. Keys the app does not have are skipped.
+func GetPropagatedMetadata(app *v1beta1.SparkApplication, labelKeys, annotationKeys []string) (map[string]string, map[string]string) {
+	if propagation := app.Spec.MetadataPropagation; propagation != nil {
+		labelKeys = append(labelKeys[:len(labelKeys):len(labelKeys)], propagation.Labels...)
+		annotationKeys = append(annotationKeys[:len(annotationKeys):len(annotationKeys)], propagation.Annotations...)
+	}
+	return selectEntries(app.Labels, labelKeys), selectEntries(app.Annotations, annotationKeys)
+}
+
+func selectEntries(entries map[string]string, keys []string) map[string]string {
+	var selected map[string]string
+	for _, key := range keys {
+		value, ok := entries[key]
+		if !ok {
+			continue
+		}
+		if selected == nil {
+			selected = make(map[string]string)
+		}
+		selected[key] = value
+	}
+	return selected
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func TestMergeOwnedEntries(t *testing.T) {
+	assert.Nil(t, MergeOwnedEntries(nil, nil))
+	assert.Equal(t, map[string]string{"owner": "a"}, MergeOwnedEntries(nil, map[string]string{"owner": "a"}))
+	assert.Equal(t, map[string]string{"owner": "b", "team": "x"}, MergeOwnedEntries(
+		map[string]string{"owner": "a", "team": "x"}, map[string]string{"owner": "b"}))
+}
+
+func TestGetPropagatedMetadata(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"cost-center": "ads", "team": "x", "version": "1"},
+			Annotations: map[string]string{"data-classification": "pii", "owner": "alice"},
+		},
+	}
+	labels, annotations := GetPropagatedMetadata(app, nil, nil)
+	assert.Nil(t, labels)
+	assert.Nil(t, annotations)
+
+	labels, annotations = GetPropagatedMetadata(app, []string{"cost-center", "missing"}, []string{"data-classification"})
+	assert.Equal(t, map[string]string{"cost-center": "ads"}, labels)
+	assert.Equal(t, map[string]string{"data-classification": "pii"}, annotations)
+
+	globalLabels := []string{"cost-center"}
+	app.Spec.MetadataPropagation = &v1beta1.MetadataPropagationSpec{
+		Labels:      []string{"team"},
+		Annotations: []string{"owner"},
+	}
+	labels, annotations = GetPropagatedMetadata(app, globalLabels, []string{"data-classification"})
+	assert.Equal(t, map[string]string{"cost-center": "ads", "team": "x"}, labels)
+	assert.Equal(t, map[string]string{"data-classification": "pii", "owner": "alice"}, annotations)
+	assert.Equal(t, []string{"cost-center"}, globalLabels)
+}
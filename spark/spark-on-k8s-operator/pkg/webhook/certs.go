@@ -17,8 +17,24 @@ limitations under the License.
 package webhook
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
 	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/fips"
+)
+
+const (
+	caKeyFile = "ca-key.pem"
+	// certValidity matches the validity of the certificates generated by hack/gencerts.sh.
+	certValidity = 100000 * 24 * time.Hour
 )
 
 // certBundle is a container of a X509 certificate file and a corresponding key file for the
@@ -36,9 +52,78 @@ func configServerTLS(certBundle *certBundle) (*tls.Config, error) {
 		return nil, err
 	}
 
-	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if fips.Enabled() {
+		fips.RestrictTLSConfig(config)
+	}
+	return config, nil
 }
 
 func readCertFile(certFile string) ([]byte, error) {
 	return ioutil.ReadFile(certFile)
 }
+
+// GenerateCerts generates a CA and a server certificate signed by it for the webhook served by the Service with
+// the given namespace and name, like hack/gencerts.sh. It returns the PEM-encoded certificates and keys by the
+// names of the files the webhook reads them from.
+func GenerateCerts(serviceNamespace string, serviceName string) (map[string][]byte, error) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: serviceName + "_ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	dnsName := fmt.Sprintf("%s.%s.svc", serviceName, serviceNamespace)
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{serviceName, fmt.Sprintf("%s.%s", serviceName, serviceNamespace), dnsName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage: x509.KeyUsageContentCommitment | x509.KeyUsageDigitalSignature |
+			x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the server certificate: %v", err)
+	}
+
+	return map[string][]byte{
+		caKeyFile:      encodeKey(caKey),
+		caCertFile:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		serverKeyFile:  encodeKey(serverKey),
+		serverCertFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}),
+	}, nil
+}
+
+// GetCACert returns the CA certificate among the given certificates and keys generated by GenerateCerts.
+func GetCACert(certs map[string][]byte) []byte {
+	return certs[caCertFile]
+}
+
+func encodeKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
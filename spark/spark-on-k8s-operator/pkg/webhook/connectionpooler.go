@@ -0,0 +1,72 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// addConnectionPooler adds the PgBouncer sidecar container of the application, which the controller points the
+// JDBC URLs of the application to, to the given driver or executor pod.
+func addConnectionPooler(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	pooler := app.Spec.ConnectionPooler
+	if pooler == nil {
+		return nil
+	}
+	image := config.DefaultConnectionPoolerImage
+	if pooler.Image != nil {
+		image = *pooler.Image
+	}
+	databasePort := config.DefaultConnectionPoolerDatabasePort
+	if pooler.DatabasePort != nil {
+		databasePort = *pooler.DatabasePort
+	}
+	poolSize := config.DefaultConnectionPoolerPoolSize
+	if pooler.PoolSize != nil {
+		poolSize = *pooler.PoolSize
+	}
+	poolMode := config.DefaultConnectionPoolerPoolMode
+	if pooler.PoolMode != nil {
+		poolMode = *pooler.PoolMode
+	}
+	port := util.GetConnectionPoolerPort(app)
+
+	container := corev1.Container{
+		Name:  config.ConnectionPoolerContainerName,
+		Image: image,
+		Env: []corev1.EnvVar{
+			{Name: "DB_HOST", Value: pooler.DatabaseHost},
+			{Name: "DB_PORT", Value: fmt.Sprint(databasePort)},
+			{Name: "DB_USER", ValueFrom: secretKeyRef(pooler.SecretName, config.ConnectionPoolerUsernameKey)},
+			{Name: "DB_PASSWORD", ValueFrom: secretKeyRef(pooler.SecretName, config.ConnectionPoolerPasswordKey)},
+			{Name: "LISTEN_ADDR", Value: "127.0.0.1"},
+			{Name: "LISTEN_PORT", Value: fmt.Sprint(port)},
+			{Name: "POOL_MODE", Value: poolMode},
+			{Name: "DEFAULT_POOL_SIZE", Value: fmt.Sprint(poolSize)},
+			{Name: "MAX_DB_CONNECTIONS", Value: fmt.Sprint(poolSize)},
+		},
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, container)
+
+	return []patchOperation{{Op: "add", Path: "/spec/containers/-", Value: container}}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// addDataCache mounts the directory of the data cache of the application on the node into the given executor
+// pod. The mount receives the file systems the cache daemon mounts in the directory later on.
+func addDataCache(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	if app.Spec.DataCache == nil {
+		return nil
+	}
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	propagation := corev1.MountPropagationHostToContainer
+	volume := corev1.Volume{
+		Name: config.DataCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: util.GetDataCacheHostPath(app),
+				Type: &hostPathType,
+			},
+		},
+	}
+	mount := corev1.VolumeMount{
+		Name:             config.DataCacheVolumeName,
+		MountPath:        util.GetDataCacheMountPath(app),
+		MountPropagation: &propagation,
+	}
+	return []patchOperation{addVolume(pod, volume), addVolumeMount(pod, mount)}
+}
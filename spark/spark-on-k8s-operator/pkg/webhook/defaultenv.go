@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// defaultEnvSource provides the environment variables injected into the Spark containers of all Spark pods,
+// which are read from the data of a ConfigMap managed by the operator administrator.
+type defaultEnvSource struct {
+	informerFactory informers.SharedInformerFactory
+	configMapLister corelisters.ConfigMapLister
+	namespaceLister corelisters.NamespaceLister
+	namespace       string
+	name            string
+}
+
+// newDefaultEnvSource creates a defaultEnvSource reading the ConfigMap with the given "<namespace>/<name>" key.
+func newDefaultEnvSource(clientset kubernetes.Interface, configMap string) (*defaultEnvSource, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(configMap)
+	if err != nil || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid default environment ConfigMap %q, expected <namespace>/<name>", configMap)
+	}
+	// The namespace option only applies to ConfigMaps, Namespaces are cluster-scoped.
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0*time.Second, informers.WithNamespace(namespace))
+	return &defaultEnvSource{
+		informerFactory: factory,
+		configMapLister: factory.Core().V1().ConfigMaps().Lister(),
+		namespaceLister: factory.Core().V1().Namespaces().Lister(),
+		namespace:       namespace,
+		name:            name,
+	}, nil
+}
+
+func (s *defaultEnvSource) start(stopCh <-chan struct{}) {
+	s.informerFactory.Start(stopCh)
+	for informerType, synced := range s.informerFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			glog.Errorf("failed to sync the cache of %v for the default environment", informerType)
+		}
+	}
+}
+
+// get returns the default environment variables of Spark pods in the given namespace, which is empty if the
+// namespace has opted out of them.
+func (s *defaultEnvSource) get(namespace string) map[string]string {
+	ns, err := s.namespaceLister.Get(namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		glog.Errorf("failed to get namespace %s: %v", namespace, err)
+		return nil
+	}
+	if ns != nil && optsOutOfDefaultEnv(ns.Annotations) {
+		return nil
+	}
+
+	configMap, err := s.configMapLister.ConfigMaps(s.namespace).Get(s.name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			glog.Errorf("failed to get ConfigMap %s/%s: %v", s.namespace, s.name, err)
+		}
+		return nil
+	}
+	return configMap.Data
+}
+
+// optsOutOfDefaultEnv tells if a namespace or SparkApplication with the given annotations has opted out of the
+// default environment variables.
+func optsOutOfDefaultEnv(annotations map[string]string) bool {
+	return annotations[config.InjectDefaultEnvAnnotation] == "false"
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// addDumps mounts the directory of the given driver or executor pod on the PersistentVolumeClaim of the dumps of
+// the application at DumpDir, where its JVM writes heap dumps and flight recordings. The directory of the run
+// is recorded in the DumpPathAnnotation of pods of runs the controller has configured dumps for.
+func addDumps(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	dumpPath, ok := pod.Annotations[config.DumpPathAnnotation]
+	if app.Spec.Debug == nil || app.Spec.Debug.ClaimName == "" || !ok {
+		return nil
+	}
+	volume := corev1.Volume{
+		Name: config.DumpVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: app.Spec.Debug.ClaimName},
+		},
+	}
+	mount := corev1.VolumeMount{
+		Name:      config.DumpVolumeName,
+		MountPath: config.DumpDir,
+		SubPath:   dumpPath + "/" + pod.Name,
+	}
+	return []patchOperation{addVolume(pod, volume), addVolumeMount(pod, mount)}
+}
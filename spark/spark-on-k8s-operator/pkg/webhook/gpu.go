@@ -0,0 +1,49 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// addGPU adds the GPUs of the application to the given executor pod, and mounts the GPU discovery script the
+// controller has created for the application into it.
+func addGPU(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	gpu := util.GetGPUSpec(app)
+	if gpu == nil {
+		return nil
+	}
+	// The discovery script is executed by Spark.
+	defaultMode := int32(0755)
+	volume := corev1.Volume{
+		Name: config.GPUDiscoveryVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: util.GetGPUDiscoveryConfigMapName(app)},
+				DefaultMode:          &defaultMode,
+			},
+		},
+	}
+	ops := addExecutorResources(pod, nil, nil, gpu)
+	return append(ops,
+		addVolume(pod, volume),
+		addConfigMapVolumeMount(pod, config.GPUDiscoveryVolumeName, config.GPUDiscoveryDir))
+}
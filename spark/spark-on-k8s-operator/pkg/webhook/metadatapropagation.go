@@ -0,0 +1,159 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/golang/glog"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spov1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// propagatedResources are the resources besides pods that get the propagated labels and annotations of the
+// SparkApplication owning them, either directly or through its Spark pods, e.g., the driver Service and the
+// executor ConfigMaps Spark creates.
+var propagatedResources = []metav1.GroupVersionResource{
+	{Group: corev1.SchemeGroupVersion.Group, Version: corev1.SchemeGroupVersion.Version, Resource: "services"},
+	{Group: corev1.SchemeGroupVersion.Group, Version: corev1.SchemeGroupVersion.Version, Resource: "configmaps"},
+	{Group: corev1.SchemeGroupVersion.Group, Version: corev1.SchemeGroupVersion.Version, Resource: "persistentvolumeclaims"},
+}
+
+func isPropagatedResource(resource metav1.GroupVersionResource) bool {
+	for _, r := range propagatedResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPropagatedMetadata sets the keys of the labels and annotations of every SparkApplication that are copied to
+// its pods, Services, ConfigMaps, and PersistentVolumeClaims admitted from then on.
+func (wh *WebHook) SetPropagatedMetadata(labels []string, annotations []string) {
+	wh.configMutex.Lock()
+	defer wh.configMutex.Unlock()
+	wh.patchConfig.propagatedLabels = labels
+	wh.patchConfig.propagatedAnnotations = annotations
+}
+
+// addLabels adds the given labels to the pod. Labels already present on the pod are kept.
+func addLabels(pod *corev1.Pod, labels map[string]string) []patchOperation {
+	return addMissingEntries("/metadata/labels", pod.Labels, labels)
+}
+
+// addMissingEntries returns the operations adding the given entries missing from the existing map at the given
+// path.
+func addMissingEntries(path string, existing map[string]string, entries map[string]string) []patchOperation {
+	toAdd := make(map[string]string)
+	for key, value := range entries {
+		if _, ok := existing[key]; !ok {
+			toAdd[key] = value
+		}
+	}
+	if len(toAdd) == 0 {
+		return nil
+	}
+	return setMapEntries(path, existing, toAdd)
+}
+
+// mutatePropagatedResource adds the propagated labels and annotations of the SparkApplication owning the
+// resource under admission to it.
+func (wh *WebHook) mutatePropagatedResource(review *admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionResponse {
+	response := &admissionv1beta1.AdmissionResponse{Allowed: true}
+	if review.Request.Operation != admissionv1beta1.Create ||
+		!inSparkJobNamespace(review.Request.Namespace, wh.sparkJobNamespace) {
+		return response
+	}
+
+	var object struct {
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+	}
+	if err := json.Unmarshal(review.Request.Object.Raw, &object); err != nil {
+		glog.Errorf("failed to unmarshal the metadata of %s from the raw data in the admission request: %v",
+			review.Request.Resource.Resource, err)
+		return toAdmissionResponse(err)
+	}
+	appName, err := wh.getOwnerApplicationName(review.Request.Namespace, object.OwnerReferences)
+	if err != nil {
+		glog.Errorf("failed to get the owner of %s %s/%s: %v", review.Request.Resource.Resource,
+			review.Request.Namespace, object.Name, err)
+		return toAdmissionResponse(err)
+	}
+	if appName == "" {
+		return response
+	}
+	app, err := wh.lister.SparkApplications(review.Request.Namespace).Get(appName)
+	if errors.IsNotFound(err) {
+		return response
+	}
+	if err != nil {
+		glog.Errorf("failed to get SparkApplication %s/%s: %v", review.Request.Namespace, appName, err)
+		return toAdmissionResponse(err)
+	}
+
+	cfg := wh.getPatchConfig()
+	labels, annotations := util.GetPropagatedMetadata(app, cfg.propagatedLabels, cfg.propagatedAnnotations)
+	patchOps := addMissingEntries("/metadata/labels", object.Labels, labels)
+	patchOps = append(patchOps, addMissingEntries("/metadata/annotations", object.Annotations, annotations)...)
+	if len(patchOps) == 0 {
+		return response
+	}
+	patchBytes, err := json.Marshal(patchOps)
+	if err != nil {
+		glog.Errorf("failed to marshal patch operations %v: %v", patchOps, err)
+		return toAdmissionResponse(err)
+	}
+	response.Patch = patchBytes
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	response.PatchType = &patchType
+	return response
+}
+
+// getOwnerApplicationName returns the name of the SparkApplication owning an object with the given owner
+// references, either directly or through one of its Spark pods, or an empty string if there is none.
+func (wh *WebHook) getOwnerApplicationName(namespace string, ownerReferences []metav1.OwnerReference) (string, error) {
+	appKind := reflect.TypeOf(spov1beta1.SparkApplication{}).Name()
+	for _, ref := range ownerReferences {
+		if ref.Kind == appKind && ref.APIVersion == spov1beta1.SchemeGroupVersion.String() {
+			return ref.Name, nil
+		}
+	}
+	for _, ref := range ownerReferences {
+		if ref.Kind != "Pod" || ref.APIVersion != corev1.SchemeGroupVersion.String() {
+			continue
+		}
+		pod, err := wh.clientset.CoreV1().Pods(namespace).Get(ref.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if isSparkPod(pod) {
+			return pod.Labels[config.SparkAppNameLabel], nil
+		}
+	}
+	return "", nil
+}
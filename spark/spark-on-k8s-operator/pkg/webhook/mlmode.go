@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// getMLModeEnv returns the environment variables configuring the collective communication of the training
+// processes of a distributed training job.
+func getMLModeEnv(mlMode *v1beta1.MLModeSpec) map[string]string {
+	env := make(map[string]string)
+	if mlMode.GPU != nil {
+		env["HOROVOD_GPU_OPERATIONS"] = "NCCL"
+	}
+	if mlMode.NetworkInterface != nil {
+		env["NCCL_SOCKET_IFNAME"] = *mlMode.NetworkInterface
+		env["GLOO_SOCKET_IFNAME"] = *mlMode.NetworkInterface
+		env["HOROVOD_GLOO_IFACE"] = *mlMode.NetworkInterface
+		env["OMPI_MCA_btl_tcp_if_include"] = *mlMode.NetworkInterface
+	}
+	return env
+}
+
+// addMLMode adds the GPUs and the environment of its training process to the given executor pod of a
+// distributed training job. Environment variables the container already sets are kept.
+func addMLMode(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	mlMode := app.Spec.MLMode
+	var ops []patchOperation
+	if mlMode.GPU != nil {
+		ops = append(ops, addExecutorResources(pod, nil, nil, mlMode.GPU)...)
+	}
+	existing := getSparkContainerEnvNames(pod)
+	env := getMLModeEnv(mlMode)
+	for _, name := range sortedKeys(env) {
+		if !existing[name] {
+			ops = append(ops, addEnvironmentVariable(pod, name, env[name]))
+		}
+	}
+	return ops
+}
+
+// newMLModeAffinityTerm returns a pod affinity term preferring to place the executors of the given distributed
+// training job in the same topology of nodes with a fast interconnect.
+func newMLModeAffinityTerm(app *v1beta1.SparkApplication) corev1.WeightedPodAffinityTerm {
+	return corev1.WeightedPodAffinityTerm{
+		Weight:          100,
+		PodAffinityTerm: newSparkRoleAffinityTerm(app, config.SparkExecutorRole, *app.Spec.MLMode.TopologyKey),
+	}
+}
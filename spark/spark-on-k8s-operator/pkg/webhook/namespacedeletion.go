@@ -0,0 +1,133 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	spov1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
+)
+
+// NamespaceDeletionPolicy tells what the webhook does when a namespace with running SparkApplications is deleted.
+type NamespaceDeletionPolicy string
+
+// Different namespace deletion policies.
+const (
+	// NamespaceDeletionAllow lets namespaces be deleted regardless of their SparkApplications.
+	NamespaceDeletionAllow NamespaceDeletionPolicy = "Allow"
+	// NamespaceDeletionBlock denies the deletion of namespaces with running SparkApplications.
+	NamespaceDeletionBlock NamespaceDeletionPolicy = "Block"
+	// NamespaceDeletionDrain denies the deletion of namespaces with running SparkApplications and deletes the
+	// applications, so that the operator stops them and cleans up after them while the namespace still exists.
+	NamespaceDeletionDrain NamespaceDeletionPolicy = "Drain"
+)
+
+var namespaceResource = metav1.GroupVersionResource{
+	Group:    corev1.SchemeGroupVersion.Group,
+	Version:  corev1.SchemeGroupVersion.Version,
+	Resource: "namespaces",
+}
+
+// ParseNamespaceDeletionPolicy parses the given namespace deletion policy.
+func ParseNamespaceDeletionPolicy(policy string) (NamespaceDeletionPolicy, error) {
+	switch p := NamespaceDeletionPolicy(policy); p {
+	case NamespaceDeletionAllow, NamespaceDeletionBlock, NamespaceDeletionDrain:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown namespace deletion policy %q, expected one of %s, %s and %s", policy,
+		NamespaceDeletionAllow, NamespaceDeletionBlock, NamespaceDeletionDrain)
+}
+
+// SetNamespaceDeletionPolicy sets the policy applied to the deletion of namespaces with running SparkApplications.
+// Draining namespaces deletes applications with the given client. It must be called before the webhook is
+// started, which then also registers itself for the deletion of namespaces unless the policy is Allow.
+func (wh *WebHook) SetNamespaceDeletionPolicy(policy NamespaceDeletionPolicy, crClient crclientset.Interface) {
+	wh.nsDeletionPolicy = policy
+	wh.crClient = crClient
+}
+
+func (wh *WebHook) admitsNamespaceDeletion() bool {
+	return wh.nsDeletionPolicy != "" && wh.nsDeletionPolicy != NamespaceDeletionAllow
+}
+
+// getRunningApplications returns the names of the SparkApplications in the given namespace that have not
+// terminated yet, including the ones being deleted.
+func getRunningApplications(lister crdlisters.SparkApplicationLister, namespace string) ([]*spov1beta1.SparkApplication, error) {
+	apps, err := lister.SparkApplications(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var running []*spov1beta1.SparkApplication
+	for _, app := range apps {
+		switch app.Status.AppState.State {
+		case spov1beta1.CompletedState, spov1beta1.FailedState, spov1beta1.SkippedState:
+		default:
+			running = append(running, app)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+	return running, nil
+}
+
+func (wh *WebHook) admitNamespaceDeletion(review *admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionResponse {
+	if review.Request.Operation != admissionv1beta1.Delete {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	namespace := review.Request.Name
+	running, err := getRunningApplications(wh.lister, namespace)
+	if err != nil {
+		return toAdmissionResponse(err)
+	}
+	if len(running) == 0 {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	var names []string
+	for _, app := range running {
+		names = append(names, app.Name)
+	}
+
+	if wh.nsDeletionPolicy == NamespaceDeletionBlock {
+		return toDeniedResponse(fmt.Errorf("namespace %s has %d running SparkApplications (%s), which must be "+
+			"deleted or complete before the namespace can be deleted", namespace, len(running),
+			strings.Join(names, ", ")))
+	}
+
+	for _, app := range running {
+		if app.DeletionTimestamp != nil {
+			continue
+		}
+		glog.Infof("Deleting SparkApplication %s/%s to drain namespace %s before its deletion", app.Namespace,
+			app.Name, namespace)
+		err := wh.crClient.SparkoperatorV1beta1().SparkApplications(namespace).Delete(app.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			glog.Errorf("failed to delete SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+	}
+	return toDeniedResponse(fmt.Errorf("namespace %s has %d running SparkApplications (%s), which are being "+
+		"stopped; delete the namespace again once they are gone", namespace, len(running),
+		strings.Join(names, ", ")))
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// networkSelectionElement is an element of the Multus networks annotation in its JSON form.
+type networkSelectionElement struct {
+	Name      string  `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+	Interface *string `json:"interface,omitempty"`
+}
+
+func getNetworks(pod *corev1.Pod, app *v1beta1.SparkApplication) []v1beta1.NetworkAttachment {
+	if util.IsDriverPod(pod) {
+		return app.Spec.Driver.Networks
+	}
+	if util.IsExecutorPod(pod) {
+		return app.Spec.Executor.Networks
+	}
+	return nil
+}
+
+// getNetworkAnnotations returns the Multus annotation attaching the secondary networks of the driver or
+// executors of the application to the pod.
+func getNetworkAnnotations(pod *corev1.Pod, app *v1beta1.SparkApplication) map[string]string {
+	networks := getNetworks(pod, app)
+	if len(networks) == 0 {
+		return nil
+	}
+	var elements []networkSelectionElement
+	for _, network := range networks {
+		elements = append(elements, networkSelectionElement{
+			Name:      network.Name,
+			Namespace: network.Namespace,
+			Interface: network.Interface,
+		})
+	}
+	value, err := json.Marshal(elements)
+	if err != nil {
+		glog.Errorf("failed to marshal the networks of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		return nil
+	}
+	return map[string]string{config.MultusNetworksAnnotation: string(value)}
+}
+
+// addNetworkResources requests the devices the secondary networks of the pod need for its Spark container, one
+// per network. Extended resources are requested through their limits.
+func addNetworkResources(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	counts := make(map[string]int64)
+	var names []string
+	for _, network := range getNetworks(pod, app) {
+		if network.ResourceName == nil {
+			continue
+		}
+		if counts[*network.ResourceName] == 0 {
+			names = append(names, *network.ResourceName)
+		}
+		counts[*network.ResourceName]++
+	}
+	var ops []patchOperation
+	for _, name := range names {
+		quantity := strconv.FormatInt(counts[name], 10)
+		if op := addContainerResource(pod, "limits", corev1.ResourceName(name), quantity); op != nil {
+			ops = append(ops, *op)
+		}
+	}
+	return ops
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// getNodeFeatureLabels returns the node labels advertising the given Node Feature Discovery features. A feature
+// "<name>" is advertised by the label feature.node.kubernetes.io/<name>=true, and "<name>=<value>" by the label
+// feature.node.kubernetes.io/<name>=<value>. Names with a prefix are used as label keys as is.
+func getNodeFeatureLabels(features []string) (labels.Set, error) {
+	set := make(labels.Set)
+	for _, feature := range features {
+		key, value := feature, "true"
+		if i := strings.Index(feature, "="); i >= 0 {
+			key, value = feature[:i], feature[i+1:]
+		}
+		if !strings.Contains(key, "/") {
+			key = config.NodeFeatureLabelPrefix + key
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid node feature %q: %s", feature, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid node feature %q: %s", feature, strings.Join(errs, "; "))
+		}
+		if existing, ok := set[key]; ok && existing != value {
+			return nil, fmt.Errorf("conflicting node features %s=%s and %s=%s", key, existing, key, value)
+		}
+		set[key] = value
+	}
+	return set, nil
+}
+
+// requireNodeLabels restricts the given affinity to nodes with the given labels. The requirements are added to
+// every required node selector term, as the terms are ORed.
+func requireNodeLabels(affinity *corev1.Affinity, nodeLabels labels.Set) {
+	var requirements []corev1.NodeSelectorRequirement
+	for _, key := range sortedKeys(nodeLabels) {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{nodeLabels[key]},
+		})
+	}
+	addRequiredNodeSelectorRequirements(affinity, requirements...)
+}
+
+// validateNodeFeatures checks that the node features required by the driver and executors of the given
+// application are valid and that nodes advertising them exist, if the features have changed from oldApp, which
+// is nil on creation. Nodes are only looked up with a non-nil clientset, and failures to look them up are
+// ignored like failures to call the webhook.
+func validateNodeFeatures(app, oldApp *v1beta1.SparkApplication, clientset kubernetes.Interface) error {
+	roles := []struct {
+		name        string
+		features    []string
+		oldFeatures []string
+	}{
+		{name: "driver", features: app.Spec.Driver.RequireNodeFeatures},
+		{name: "executor", features: app.Spec.Executor.RequireNodeFeatures},
+	}
+	if oldApp != nil {
+		roles[0].oldFeatures = oldApp.Spec.Driver.RequireNodeFeatures
+		roles[1].oldFeatures = oldApp.Spec.Executor.RequireNodeFeatures
+	}
+	for _, role := range roles {
+		// Nodes may have gone away since the features were set, which must not block status updates.
+		if len(role.features) == 0 || (oldApp != nil && reflect.DeepEqual(role.features, role.oldFeatures)) {
+			continue
+		}
+		nodeLabels, err := getNodeFeatureLabels(role.features)
+		if err != nil {
+			return fmt.Errorf("%s %v", role.name, err)
+		}
+		if clientset == nil {
+			continue
+		}
+		nodes, err := clientset.CoreV1().Nodes().List(metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(nodeLabels).String(),
+			Limit:         1,
+		})
+		if err != nil {
+			glog.Warningf("failed to list the nodes with the %s node features of SparkApplication %s/%s: %v",
+				role.name, app.Namespace, app.Name, err)
+			continue
+		}
+		if len(nodes.Items) == 0 {
+			return fmt.Errorf("no node has the %s node features %s", role.name, strings.Join(role.features, ", "))
+		}
+	}
+	return nil
+}
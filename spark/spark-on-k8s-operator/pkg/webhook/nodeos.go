@@ -0,0 +1,87 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// validateNodeOS checks that the node selectors and node affinities of the SparkApplication do not route its
+// driver or executors to nodes other than Linux nodes, which Spark images cannot run on.
+func validateNodeOS(app *v1beta1.SparkApplication) error {
+	for _, key := range []string{config.NodeOSLabel, config.BetaNodeOSLabel} {
+		if value, ok := app.Spec.NodeSelector[key]; ok && value != config.LinuxNodeOS {
+			return fmt.Errorf("node selector %s=%s does not select Linux nodes", key, value)
+		}
+		if value, ok := app.Spec.SparkConf[config.SparkNodeSelectorKeyPrefix+key]; ok && value != config.LinuxNodeOS {
+			return fmt.Errorf("Spark configuration property %s%s=%s does not select Linux nodes",
+				config.SparkNodeSelectorKeyPrefix, key, value)
+		}
+	}
+	if err := validateNodeAffinityOS(app.Spec.Driver.Affinity); err != nil {
+		return fmt.Errorf("driver %v", err)
+	}
+	if err := validateNodeAffinityOS(app.Spec.Executor.Affinity); err != nil {
+		return fmt.Errorf("executor %v", err)
+	}
+	return nil
+}
+
+func validateNodeAffinityOS(affinity *corev1.Affinity) error {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, requirement := range term.MatchExpressions {
+			if requirement.Key != config.NodeOSLabel && requirement.Key != config.BetaNodeOSLabel {
+				continue
+			}
+			if !requirementMatchesLinux(requirement) {
+				return fmt.Errorf("node affinity %s %s [%s] does not select Linux nodes",
+					requirement.Key, requirement.Operator, strings.Join(requirement.Values, ","))
+			}
+		}
+	}
+	return nil
+}
+
+func requirementMatchesLinux(requirement corev1.NodeSelectorRequirement) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return containsString(requirement.Values, config.LinuxNodeOS)
+	case corev1.NodeSelectorOpNotIn:
+		return !containsString(requirement.Values, config.LinuxNodeOS)
+	case corev1.NodeSelectorOpDoesNotExist:
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -18,10 +18,13 @@ package webhook
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
-
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
@@ -32,9 +35,26 @@ import (
 const (
 	sparkDriverContainerName   = "spark-kubernetes-driver"
 	sparkExecutorContainerName = "executor"
-	maxNameLength              = 63
 )
 
+// patchConfig holds operator-level settings that apply to every Spark pod patched by the webhook.
+type patchConfig struct {
+	// enableIstioMode controls whether Spark pods are patched for running with an Istio sidecar.
+	enableIstioMode bool
+	// defaultSeccompProfile is the seccomp profile applied to Spark pods that do not specify one.
+	defaultSeccompProfile string
+	// defaultAppArmorProfile is the AppArmor profile applied to Spark containers that do not specify one.
+	defaultAppArmorProfile string
+	// enforceLinuxNodes controls whether Spark pods are restricted to Linux nodes through a node selector.
+	enforceLinuxNodes bool
+	// defaultEnv are the environment variables added to the Spark container of pods that do not set them.
+	defaultEnv map[string]string
+	// propagatedLabels and propagatedAnnotations are the keys of the labels and annotations of every
+	// SparkApplication copied to its pods and the other resources owned by it.
+	propagatedLabels      []string
+	propagatedAnnotations []string
+}
+
 // patchOperation represents a RFC6902 JSON patch operation.
 type patchOperation struct {
 	Op    string      `json:"op"`
@@ -42,17 +62,55 @@ type patchOperation struct {
 	Value interface{} `json:"value,omitempty"`
 }
 
-func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication, cfg patchConfig) []patchOperation {
+	original := pod
+	// Work on a copy as patch functions record what they add to the pod.
+	pod = pod.DeepCopy()
 	var patchOps []patchOperation
 
 	if util.IsDriverPod(pod) {
 		patchOps = append(patchOps, addOwnerReference(pod, app))
+		if app.Spec.Driver.UIProxy != nil {
+			patchOps = append(patchOps, addUIProxy(pod, app))
+		}
+		if app.Spec.Driver.TerminationGracePeriodSeconds != nil {
+			patchOps = append(patchOps, addTerminationGracePeriod(pod, *app.Spec.Driver.TerminationGracePeriodSeconds))
+		}
 	}
+	// Propagated labels go before the ones of executor groups, which take precedence.
+	propagatedLabels, propagatedAnnotations := util.GetPropagatedMetadata(app, cfg.propagatedLabels, cfg.propagatedAnnotations)
+	patchOps = append(patchOps, addLabels(pod, propagatedLabels)...)
 	patchOps = append(patchOps, addVolumes(pod, app)...)
 	patchOps = append(patchOps, addGeneralConfigMaps(pod, app)...)
 	patchOps = append(patchOps, addSparkConfigMap(pod, app)...)
 	patchOps = append(patchOps, addHadoopConfigMap(pod, app)...)
+	patchOps = append(patchOps, addStartGate(pod, app)...)
+	patchOps = append(patchOps, addAWSWebIdentity(pod, app)...)
+	patchOps = append(patchOps, addDefaultEnv(pod, app, cfg.defaultEnv)...)
+	patchOps = append(patchOps, addDumps(pod, app)...)
+	patchOps = append(patchOps, addConnectionPooler(pod, app)...)
+	if util.IsExecutorPod(pod) {
+		patchOps = append(patchOps, addLocalDirs(pod, app)...)
+		// The resources of executor groups and resource profiles replace the GPUs of the application.
+		patchOps = append(patchOps, addGPU(pod, app)...)
+		patchOps = append(patchOps, addDataCache(pod, app)...)
+		if group := getExecutorGroup(pod, app); group != nil {
+			patchOps = append(patchOps, addExecutorGroup(pod, group)...)
+		}
+		if profile := getResourceProfile(pod, app); profile != nil {
+			patchOps = append(patchOps, addExecutorResources(pod, profile.CoreRequest, profile.CoreLimit, profile.GPU)...)
+		}
+		if app.Spec.MLMode != nil {
+			patchOps = append(patchOps, addMLMode(pod, app)...)
+		}
+	}
 	patchOps = append(patchOps, addTolerations(pod, app)...)
+	patchOps = append(patchOps, addNodeFailureTolerations(pod, app)...)
+	patchOps = append(patchOps, addEphemeralStorage(pod, app)...)
+	patchOps = append(patchOps, addNetworkResources(pod, app)...)
+	if cfg.enforceLinuxNodes {
+		patchOps = append(patchOps, addLinuxNodeSelector(pod)...)
+	}
 	if pod.Spec.Affinity == nil {
 		op := addAffinity(pod, app)
 		if op != nil {
@@ -66,6 +124,38 @@ func patchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperat
 		}
 	}
 
+	annotations := make(map[string]string)
+	if cfg.enableIstioMode {
+		for key, value := range getIstioAnnotations(app) {
+			annotations[key] = value
+		}
+	}
+	for key, value := range getSecurityProfileAnnotations(pod, app, cfg) {
+		annotations[key] = value
+	}
+	for key, value := range getNetworkAnnotations(pod, app) {
+		annotations[key] = value
+	}
+	for key, value := range propagatedAnnotations {
+		if _, ok := annotations[key]; !ok {
+			annotations[key] = value
+		}
+	}
+	patchOps = append(patchOps, addAnnotations(pod, annotations)...)
+
+	// The operations in the patch annotation go last, so they can modify what the operator has patched.
+	userOps, err := getUserPatch(app)
+	if err != nil {
+		glog.Warningf("ignoring the patch of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+	} else if len(userOps) > 0 {
+		if canApplyPatch(original, append(patchOps, userOps...)) {
+			patchOps = append(patchOps, userOps...)
+		} else {
+			glog.Warningf("ignoring the patch of SparkApplication %s/%s as it does not apply to pod %s",
+				app.Namespace, app.Name, pod.Name)
+		}
+	}
+
 	return patchOps
 }
 
@@ -118,6 +208,8 @@ func addVolume(pod *corev1.Pod, volume corev1.Volume) patchOperation {
 		path += "/-"
 		value = volume
 	}
+	// Keep track of the added volume so a subsequent patch appends to the list instead of replacing it.
+	pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
 
 	return patchOperation{Op: "add", Path: path, Value: value}
 }
@@ -140,6 +232,7 @@ func addVolumeMount(pod *corev1.Pod, mount corev1.VolumeMount) patchOperation {
 		path += "/-"
 		value = mount
 	}
+	pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, mount)
 
 	return patchOperation{Op: "add", Path: path, Value: value}
 }
@@ -162,10 +255,40 @@ func addEnvironmentVariable(pod *corev1.Pod, envName, envValue string) patchOper
 		path += "/-"
 		value = corev1.EnvVar{Name: envName, Value: envValue}
 	}
+	pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: envName, Value: envValue})
 
 	return patchOperation{Op: "add", Path: path, Value: value}
 }
 
+// addDefaultEnv adds the given default environment variables to the Spark container of the pod, except for
+// those the container already sets, unless the SparkApplication has opted out of them.
+func addDefaultEnv(pod *corev1.Pod, app *v1beta1.SparkApplication, defaultEnv map[string]string) []patchOperation {
+	if len(defaultEnv) == 0 || optsOutOfDefaultEnv(app.Annotations) {
+		return nil
+	}
+	existing := getSparkContainerEnvNames(pod)
+	var ops []patchOperation
+	for _, name := range sortedKeys(defaultEnv) {
+		if !existing[name] {
+			ops = append(ops, addEnvironmentVariable(pod, name, defaultEnv[name]))
+		}
+	}
+	return ops
+}
+
+// getSparkContainerEnvNames returns the names of the environment variables the Spark container of the pod sets.
+func getSparkContainerEnvNames(pod *corev1.Pod) map[string]bool {
+	names := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		if container.Name == sparkDriverContainerName || container.Name == sparkExecutorContainerName {
+			for _, env := range container.Env {
+				names[env.Name] = true
+			}
+		}
+	}
+	return names
+}
+
 func addSparkConfigMap(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
 	var patchOps []patchOperation
 	sparkConfigMapName := app.Spec.SparkConfigMap
@@ -180,7 +303,7 @@ func addSparkConfigMap(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOp
 
 func addHadoopConfigMap(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
 	var patchOps []patchOperation
-	hadoopConfigMapName := app.Spec.HadoopConfigMap
+	hadoopConfigMapName := util.GetHadoopConfigMapName(app)
 	if hadoopConfigMapName != nil {
 		patchOps = append(patchOps, addConfigMapVolume(pod, *hadoopConfigMapName, config.HadoopConfigMapVolumeName))
 		patchOps = append(patchOps, addConfigMapVolumeMount(pod, config.HadoopConfigMapVolumeName,
@@ -190,6 +313,60 @@ func addHadoopConfigMap(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchO
 	return patchOps
 }
 
+// addStartGate mounts the start gate ConfigMap of the application into the driver, and points the driver to
+// the file that exists once the operator opens the gate.
+func addStartGate(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	if app.Spec.MinExecutorsBeforeStart == nil || !util.IsDriverPod(pod) {
+		return nil
+	}
+	return []patchOperation{
+		addConfigMapVolume(pod, util.GetStartGateConfigMapName(app), config.StartGateVolumeName),
+		addConfigMapVolumeMount(pod, config.StartGateVolumeName, config.StartGateDir),
+		addEnvironmentVariable(pod, config.StartGateFileEnvVar, config.StartGateDir+"/"+config.StartGateKey),
+	}
+}
+
+// addAWSWebIdentity mounts a ServiceAccount token the IAM role of the application is assumed with into the driver
+// or executor, and points the AWS SDKs to the role and the token, unless the EKS pod identity webhook has already
+// done so.
+func addAWSWebIdentity(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	if app.Spec.CloudIdentity == nil || app.Spec.CloudIdentity.AWSRoleARN == nil {
+		return nil
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == config.AWSIAMTokenVolumeName {
+			return nil
+		}
+	}
+	expirationSeconds := config.AWSIAMTokenExpirationSeconds
+	volume := corev1.Volume{
+		Name: config.AWSIAMTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          config.AWSIAMTokenAudience,
+							ExpirationSeconds: &expirationSeconds,
+							Path:              "token",
+						},
+					},
+				},
+			},
+		},
+	}
+	return []patchOperation{
+		addVolume(pod, volume),
+		addVolumeMount(pod, corev1.VolumeMount{
+			Name:      config.AWSIAMTokenVolumeName,
+			MountPath: config.AWSIAMTokenDir,
+			ReadOnly:  true,
+		}),
+		addEnvironmentVariable(pod, config.AWSRoleARNEnvVar, *app.Spec.CloudIdentity.AWSRoleARN),
+		addEnvironmentVariable(pod, config.AWSWebIdentityTokenFileEnvVar, config.AWSIAMTokenDir+"/token"),
+	}
+}
+
 func addGeneralConfigMaps(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
 	var configMaps []v1beta1.NamePath
 	if util.IsDriverPod(pod) {
@@ -199,13 +376,14 @@ func addGeneralConfigMaps(pod *corev1.Pod, app *v1beta1.SparkApplication) []patc
 	}
 
 	var patchOps []patchOperation
+	added := make(map[string]bool)
 	for _, namePath := range configMaps {
-		volumeName := namePath.Name + "-vol"
-		if len(volumeName) > maxNameLength {
-			volumeName = volumeName[0:maxNameLength]
-			glog.V(2).Infof("ConfigMap volume name is too long. Truncating to length %d. Result: %s.", maxNameLength, volumeName)
+		volumeName := util.BuildName(namePath.Name, "vol", util.DNS1123LabelMaxLength)
+		// A ConfigMap mounted at multiple paths is backed by a single volume.
+		if !added[volumeName] {
+			patchOps = append(patchOps, addConfigMapVolume(pod, namePath.Name, volumeName))
+			added[volumeName] = true
 		}
-		patchOps = append(patchOps, addConfigMapVolume(pod, namePath.Name, volumeName))
 		patchOps = append(patchOps, addConfigMapVolumeMount(pod, volumeName, namePath.Path))
 	}
 	return patchOps
@@ -234,20 +412,306 @@ func addConfigMapVolumeMount(pod *corev1.Pod, configMapVolumeName string, mountP
 	return addVolumeMount(pod, mount)
 }
 
+func addLocalDirs(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	var patchOps []patchOperation
+	var mountPaths []string
+	for _, dir := range app.Spec.Executor.LocalDirs {
+		volume := corev1.Volume{Name: dir.Name}
+		sources := 0
+		if dir.EmptyDir != nil {
+			volume.EmptyDir = dir.EmptyDir
+			sources++
+		}
+		if dir.HostPath != nil {
+			volume.HostPath = dir.HostPath
+			sources++
+		}
+		if dir.PersistentVolumeClaim != nil {
+			volume.PersistentVolumeClaim = dir.PersistentVolumeClaim
+			sources++
+		}
+		if sources != 1 {
+			glog.Warningf("skipping local directory %s of SparkApplication %s/%s as it does not have exactly one volume source",
+				dir.Name, app.Namespace, app.Name)
+			continue
+		}
+
+		patchOps = append(patchOps, addVolume(pod, volume))
+		patchOps = append(patchOps, addVolumeMount(pod, corev1.VolumeMount{Name: dir.Name, MountPath: dir.MountPath}))
+		mountPaths = append(mountPaths, dir.MountPath)
+	}
+
+	if len(mountPaths) > 0 {
+		patchOps = append(patchOps, addEnvironmentVariable(pod, config.SparkLocalDirsEnvVar, strings.Join(mountPaths, ",")))
+	}
+	return patchOps
+}
+
+func addTerminationGracePeriod(pod *corev1.Pod, seconds int64) patchOperation {
+	pod.Spec.TerminationGracePeriodSeconds = &seconds
+	return patchOperation{Op: "add", Path: "/spec/terminationGracePeriodSeconds", Value: seconds}
+}
+
+func addEphemeralStorage(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	var request, limit *string
+	if util.IsDriverPod(pod) {
+		request = app.Spec.Driver.EphemeralStorage
+		limit = app.Spec.Driver.EphemeralStorageLimit
+	} else if util.IsExecutorPod(pod) {
+		request = app.Spec.Executor.EphemeralStorage
+		limit = app.Spec.Executor.EphemeralStorageLimit
+		if profile := getResourceProfile(pod, app); profile != nil {
+			if profile.EphemeralStorage != nil {
+				request = profile.EphemeralStorage
+			}
+			if profile.EphemeralStorageLimit != nil {
+				limit = profile.EphemeralStorageLimit
+			}
+		}
+	}
+
+	var patchOps []patchOperation
+	if request != nil {
+		if op := addContainerResource(pod, "requests", corev1.ResourceEphemeralStorage, *request); op != nil {
+			patchOps = append(patchOps, *op)
+		}
+	}
+	if limit != nil {
+		if op := addContainerResource(pod, "limits", corev1.ResourceEphemeralStorage, *limit); op != nil {
+			patchOps = append(patchOps, *op)
+		}
+	}
+	return patchOps
+}
+
+// addContainerResource sets a resource request or limit, depending on the given field name, of the driver or
+// executor container in the pod.
+func addContainerResource(pod *corev1.Pod, field string, name corev1.ResourceName, value string) *patchOperation {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		glog.Warningf("skipping invalid %s %s %q for pod %s: %v", name, field, value, pod.Name, err)
+		return nil
+	}
+
+	i := 0
+	// Find the driver or executor container in the pod.
+	for ; i < len(pod.Spec.Containers); i++ {
+		if pod.Spec.Containers[i].Name == sparkDriverContainerName ||
+			pod.Spec.Containers[i].Name == sparkExecutorContainerName {
+			break
+		}
+	}
+	if i == len(pod.Spec.Containers) {
+		return nil
+	}
+
+	resources := &pod.Spec.Containers[i].Resources
+	list := &resources.Requests
+	if field == "limits" {
+		list = &resources.Limits
+	}
+
+	path := fmt.Sprintf("/spec/containers/%d/resources/%s", i, field)
+	var patchValue interface{}
+	if len(*list) == 0 {
+		patchValue = corev1.ResourceList{name: quantity}
+		*list = corev1.ResourceList{}
+	} else {
+		path += "/" + escapeJSONPointer(string(name))
+		patchValue = quantity
+	}
+	(*list)[name] = quantity
+
+	return &patchOperation{Op: "add", Path: path, Value: patchValue}
+}
+
+// addUIProxy adds an OAuth2 proxy sidecar container that authenticates requests to the driver UI.
+func addUIProxy(pod *corev1.Pod, app *v1beta1.SparkApplication) patchOperation {
+	proxy := app.Spec.Driver.UIProxy
+	image := config.DefaultUIProxyImage
+	if proxy.Image != nil {
+		image = *proxy.Image
+	}
+	uiPort := config.DefaultSparkUIPort
+	if port, ok := app.Spec.SparkConf[config.SparkUIPortKey]; ok {
+		uiPort = port
+	}
+	proxyPort := util.GetUIProxyPort(app)
+
+	args := []string{
+		"--provider=oidc",
+		"--oidc-issuer-url=" + proxy.OIDCIssuerURL,
+		"--client-id=" + proxy.ClientID,
+		fmt.Sprintf("--http-address=0.0.0.0:%d", proxyPort),
+		fmt.Sprintf("--upstream=http://127.0.0.1:%s/", uiPort),
+		"--reverse-proxy=true",
+		"--skip-provider-button=true",
+	}
+	emailDomains := proxy.EmailDomains
+	if len(emailDomains) == 0 {
+		emailDomains = []string{"*"}
+	}
+	for _, domain := range emailDomains {
+		args = append(args, "--email-domain="+domain)
+	}
+	for _, group := range proxy.AllowedGroups {
+		args = append(args, "--allowed-group="+group)
+	}
+
+	container := corev1.Container{
+		Name:  config.UIProxyContainerName,
+		Image: image,
+		Args:  args,
+		Ports: []corev1.ContainerPort{{Name: "ui-proxy", ContainerPort: proxyPort}},
+		Env: []corev1.EnvVar{
+			{
+				Name:      "OAUTH2_PROXY_CLIENT_SECRET",
+				ValueFrom: secretKeyRef(proxy.SecretName, config.UIProxyClientSecretKey),
+			},
+			{
+				Name:      "OAUTH2_PROXY_COOKIE_SECRET",
+				ValueFrom: secretKeyRef(proxy.SecretName, config.UIProxyCookieSecretKey),
+			},
+		},
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, container)
+
+	return patchOperation{Op: "add", Path: "/spec/containers/-", Value: container}
+}
+
+func secretKeyRef(name, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  key,
+		},
+	}
+}
+
 func addAffinity(pod *corev1.Pod, app *v1beta1.SparkApplication) *patchOperation {
 	var affinity *corev1.Affinity
+	var nodeFeatures []string
 	if util.IsDriverPod(pod) {
 		affinity = app.Spec.Driver.Affinity
+		nodeFeatures = app.Spec.Driver.RequireNodeFeatures
 	} else if util.IsExecutorPod(pod) {
 		affinity = app.Spec.Executor.Affinity
+		nodeFeatures = app.Spec.Executor.RequireNodeFeatures
+		if group := getExecutorGroup(pod, app); group != nil && group.Affinity != nil {
+			affinity = group.Affinity
+		}
+	}
+	// Work on a copy as affinity terms generated from the SparkApplication spec get added to it.
+	if affinity != nil {
+		affinity = affinity.DeepCopy()
+	} else {
+		affinity = &corev1.Affinity{}
+	}
+
+	if util.IsExecutorPod(pod) {
+		if terms := getExecutorAntiAffinityTerms(app); len(terms) > 0 {
+			if affinity.PodAntiAffinity == nil {
+				affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+			}
+			affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, terms...)
+		}
+		if app.Spec.Zone == nil && app.Spec.ZoneAffinity != nil && *app.Spec.ZoneAffinity == v1beta1.SameAsDriverZoneAffinity {
+			if affinity.PodAffinity == nil {
+				affinity.PodAffinity = &corev1.PodAffinity{}
+			}
+			affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+				affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+				newSparkRoleAffinityTerm(app, config.SparkDriverRole, config.NodeZoneLabel))
+		}
+		if app.Spec.MLMode != nil && app.Spec.MLMode.TopologyKey != nil {
+			if affinity.PodAffinity == nil {
+				affinity.PodAffinity = &corev1.PodAffinity{}
+			}
+			affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, newMLModeAffinityTerm(app))
+		}
+	}
+	if app.Spec.Zone != nil {
+		requireNodeZone(affinity, *app.Spec.Zone)
+	}
+	if len(nodeFeatures) > 0 {
+		// SparkApplications with invalid node features are rejected, so this only fails for ones created
+		// before the webhook was enabled.
+		if nodeLabels, err := getNodeFeatureLabels(nodeFeatures); err != nil {
+			glog.Warningf("ignoring the node features of SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		} else {
+			requireNodeLabels(affinity, nodeLabels)
+		}
 	}
 
-	if affinity == nil {
+	if *affinity == (corev1.Affinity{}) {
 		return nil
 	}
 	return &patchOperation{Op: "add", Path: "/spec/affinity", Value: *affinity}
 }
 
+// requireNodeZone restricts the given affinity to nodes in the given zone.
+func requireNodeZone(affinity *corev1.Affinity, zone string) {
+	addRequiredNodeSelectorRequirements(affinity, corev1.NodeSelectorRequirement{
+		Key:      config.NodeZoneLabel,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{zone},
+	})
+}
+
+// addRequiredNodeSelectorRequirements adds the given requirements to every required node selector term of the
+// given affinity, as the terms are ORed.
+func addRequiredNodeSelectorRequirements(affinity *corev1.Affinity, requirements ...corev1.NodeSelectorRequirement) {
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range selector.NodeSelectorTerms {
+		selector.NodeSelectorTerms[i].MatchExpressions = append(selector.NodeSelectorTerms[i].MatchExpressions, requirements...)
+	}
+}
+
+// getExecutorAntiAffinityTerms returns the pod anti-affinity terms that keep the executors of the application
+// apart from each other and from the driver, as requested by spreadExecutors and separateDriver.
+func getExecutorAntiAffinityTerms(app *v1beta1.SparkApplication) []corev1.WeightedPodAffinityTerm {
+	var terms []corev1.WeightedPodAffinityTerm
+	if app.Spec.SpreadExecutors != nil && *app.Spec.SpreadExecutors {
+		terms = append(terms, newSparkRoleAntiAffinityTerm(app, config.SparkExecutorRole))
+	}
+	if app.Spec.SeparateDriver != nil && *app.Spec.SeparateDriver {
+		terms = append(terms, newSparkRoleAntiAffinityTerm(app, config.SparkDriverRole))
+	}
+	return terms
+}
+
+func newSparkRoleAntiAffinityTerm(app *v1beta1.SparkApplication, role string) corev1.WeightedPodAffinityTerm {
+	return corev1.WeightedPodAffinityTerm{
+		Weight:          100,
+		PodAffinityTerm: newSparkRoleAffinityTerm(app, role, config.NodeHostnameLabel),
+	}
+}
+
+// newSparkRoleAffinityTerm returns a pod affinity term selecting the pods of the given role of the application
+// in the given topology.
+func newSparkRoleAffinityTerm(app *v1beta1.SparkApplication, role string, topologyKey string) corev1.PodAffinityTerm {
+	return corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				config.SparkAppNameLabel: app.Name,
+				config.SparkRoleLabel:    role,
+			},
+		},
+		TopologyKey: topologyKey,
+	}
+}
+
 func addTolerations(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
 	var tolerations []corev1.Toleration
 	if util.IsDriverPod(pod) {
@@ -272,10 +736,148 @@ func addToleration(pod *corev1.Pod, toleration corev1.Toleration) patchOperation
 		path += "/-"
 		value = toleration
 	}
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
 
 	return patchOperation{Op: "add", Path: path, Value: value}
 }
 
+// addNodeFailureTolerations sets the toleration seconds of the not-ready and unreachable node taints specified
+// for the driver or executors. The DefaultTolerationSeconds admission plugin has already added tolerations for
+// the taints with the cluster default by the time the webhook is called, so these are replaced. Tolerations
+// of the taints listed in the SparkApplication are left alone.
+func addNodeFailureTolerations(pod *corev1.Pod, app *v1beta1.SparkApplication) []patchOperation {
+	var podSpec v1beta1.SparkPodSpec
+	if util.IsDriverPod(pod) {
+		podSpec = app.Spec.Driver.SparkPodSpec
+	} else if util.IsExecutorPod(pod) {
+		podSpec = app.Spec.Executor.SparkPodSpec
+	}
+
+	var ops []patchOperation
+	for _, taint := range []struct {
+		key     string
+		seconds *int64
+	}{
+		{config.NodeNotReadyTaintKey, podSpec.NotReadyTolerationSeconds},
+		{config.NodeUnreachableTaintKey, podSpec.UnreachableTolerationSeconds},
+	} {
+		if taint.seconds == nil || findNoExecuteToleration(podSpec.Tolerations, taint.key) >= 0 {
+			continue
+		}
+		toleration := corev1.Toleration{
+			Key:               taint.key,
+			Operator:          corev1.TolerationOpExists,
+			Effect:            corev1.TaintEffectNoExecute,
+			TolerationSeconds: taint.seconds,
+		}
+		if i := findNoExecuteToleration(pod.Spec.Tolerations, taint.key); i >= 0 {
+			pod.Spec.Tolerations[i] = toleration
+			ops = append(ops, patchOperation{Op: "replace", Path: fmt.Sprintf("/spec/tolerations/%d", i), Value: toleration})
+		} else {
+			ops = append(ops, addToleration(pod, toleration))
+		}
+	}
+	return ops
+}
+
+func findNoExecuteToleration(tolerations []corev1.Toleration, key string) int {
+	for i, toleration := range tolerations {
+		if toleration.Key == key && toleration.Effect == corev1.TaintEffectNoExecute {
+			return i
+		}
+	}
+	return -1
+}
+
+// getExecutorGroup returns the executor group of the given executor pod, which is found by the ID of the resource
+// profile the pod is launched for. It returns nil for executors of the default resource profile.
+func getExecutorGroup(pod *corev1.Pod, app *v1beta1.SparkApplication) *v1beta1.ExecutorGroup {
+	id, ok := pod.Labels[config.SparkResourceProfileIDLabel]
+	if !ok {
+		return nil
+	}
+	for i := range app.Spec.ExecutorGroups {
+		if id == strconv.Itoa(int(util.GetExecutorGroupResourceProfileID(app, i))) {
+			return &app.Spec.ExecutorGroups[i]
+		}
+	}
+	return nil
+}
+
+// addExecutorGroup labels the given executor pod with its executor group and applies the resources and node
+// placement of the group to it.
+func addExecutorGroup(pod *corev1.Pod, group *v1beta1.ExecutorGroup) []patchOperation {
+	labels := map[string]string{config.ExecutorGroupLabel: group.Name}
+	for key, value := range group.Labels {
+		labels[key] = value
+	}
+	ops := setMapEntries("/metadata/labels", pod.Labels, labels)
+	ops = append(ops, addExecutorResources(pod, group.CoreRequest, group.CoreLimit, group.GPU)...)
+
+	if len(group.NodeSelector) > 0 {
+		ops = append(ops, setMapEntries("/spec/nodeSelector", pod.Spec.NodeSelector, group.NodeSelector)...)
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = make(map[string]string)
+		}
+		for key, value := range group.NodeSelector {
+			pod.Spec.NodeSelector[key] = value
+		}
+	}
+	for _, toleration := range group.Tolerations {
+		ops = append(ops, addToleration(pod, toleration))
+	}
+	return ops
+}
+
+// getResourceProfile returns the resource profile of the given executor pod, which is found by the ID of the
+// resource profile the pod is launched for. It returns nil for executors of the default resource profile.
+func getResourceProfile(pod *corev1.Pod, app *v1beta1.SparkApplication) *v1beta1.ResourceProfile {
+	id, ok := pod.Labels[config.SparkResourceProfileIDLabel]
+	if !ok {
+		return nil
+	}
+	for i := range app.Spec.ResourceProfiles {
+		if id == strconv.Itoa(int(app.Spec.ResourceProfiles[i].ID)) {
+			return &app.Spec.ResourceProfiles[i]
+		}
+	}
+	return nil
+}
+
+// addExecutorResources sets the given CPU request and limit and GPU limit of the executor container in the pod,
+// replacing the ones set from the executor configuration of the application.
+func addExecutorResources(pod *corev1.Pod, coreRequest, coreLimit *string, gpu *v1beta1.GPUSpec) []patchOperation {
+	var ops []patchOperation
+	if coreRequest != nil {
+		if op := addContainerResource(pod, "requests", corev1.ResourceCPU, *coreRequest); op != nil {
+			ops = append(ops, *op)
+		}
+	}
+	if coreLimit != nil {
+		if op := addContainerResource(pod, "limits", corev1.ResourceCPU, *coreLimit); op != nil {
+			ops = append(ops, *op)
+		}
+	}
+	if gpu != nil {
+		quantity := strconv.FormatInt(gpu.Quantity, 10)
+		if op := addContainerResource(pod, "limits", corev1.ResourceName(gpu.Name), quantity); op != nil {
+			ops = append(ops, *op)
+		}
+	}
+	return ops
+}
+
+// addLinuxNodeSelector adds a node selector for Linux nodes to the pod unless it already selects nodes by OS.
+func addLinuxNodeSelector(pod *corev1.Pod) []patchOperation {
+	if _, ok := pod.Spec.NodeSelector[config.NodeOSLabel]; ok {
+		return nil
+	}
+	if _, ok := pod.Spec.NodeSelector[config.BetaNodeOSLabel]; ok {
+		return nil
+	}
+	return setMapEntries("/spec/nodeSelector", pod.Spec.NodeSelector, map[string]string{config.NodeOSLabel: config.LinuxNodeOS})
+}
+
 func addSecurityContext(pod *corev1.Pod, app *v1beta1.SparkApplication) *patchOperation {
 	var secContext *corev1.PodSecurityContext
 	if util.IsDriverPod(pod) {
@@ -289,3 +891,75 @@ func addSecurityContext(pod *corev1.Pod, app *v1beta1.SparkApplication) *patchOp
 	}
 	return &patchOperation{Op: "add", Path: "/spec/securityContext", Value: *secContext}
 }
+
+// addAnnotations adds the given annotations to the pod. Annotations already present on the pod are kept.
+func addAnnotations(pod *corev1.Pod, annotations map[string]string) []patchOperation {
+	return addMissingEntries("/metadata/annotations", pod.Annotations, annotations)
+}
+
+// getIstioAnnotations returns annotations that exclude the ports used for driver and executor communication
+// from being intercepted by the Istio sidecar proxy.
+func getIstioAnnotations(app *v1beta1.SparkApplication) map[string]string {
+	ports := strings.Join(getSparkCommunicationPorts(app), ",")
+	return map[string]string{
+		config.IstioExcludeInboundPortsAnnotation:  ports,
+		config.IstioExcludeOutboundPortsAnnotation: ports,
+	}
+}
+
+func getSparkCommunicationPorts(app *v1beta1.SparkApplication) []string {
+	driverPort := config.DefaultSparkDriverPort
+	if port, ok := app.Spec.SparkConf[config.SparkDriverPortKey]; ok {
+		driverPort = port
+	}
+	blockManagerPort := config.DefaultSparkBlockManagerPort
+	if port, ok := app.Spec.SparkConf[config.SparkBlockManagerPortKey]; ok {
+		blockManagerPort = port
+	}
+	return []string{driverPort, blockManagerPort}
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapeJSONPointer escapes a string for use as a JSON pointer reference token as per RFC6901.
+func escapeJSONPointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+// getSecurityProfileAnnotations returns the seccomp and AppArmor annotations for the pod. Profiles specified
+// in the SparkApplication take precedence over the operator-level defaults.
+func getSecurityProfileAnnotations(pod *corev1.Pod, app *v1beta1.SparkApplication, cfg patchConfig) map[string]string {
+	var podSpec v1beta1.SparkPodSpec
+	var containerName string
+	if util.IsDriverPod(pod) {
+		podSpec = app.Spec.Driver.SparkPodSpec
+		containerName = sparkDriverContainerName
+	} else if util.IsExecutorPod(pod) {
+		podSpec = app.Spec.Executor.SparkPodSpec
+		containerName = sparkExecutorContainerName
+	}
+
+	annotations := make(map[string]string)
+	seccompProfile := cfg.defaultSeccompProfile
+	if podSpec.SeccompProfile != nil {
+		seccompProfile = *podSpec.SeccompProfile
+	}
+	if seccompProfile != "" {
+		annotations[config.SeccompPodAnnotation] = seccompProfile
+	}
+	appArmorProfile := cfg.defaultAppArmorProfile
+	if podSpec.AppArmorProfile != nil {
+		appArmorProfile = *podSpec.AppArmorProfile
+	}
+	if appArmorProfile != "" && containerName != "" {
+		annotations[config.AppArmorAnnotationKeyPrefix+containerName] = appArmorProfile
+	}
+	return annotations
+}
@@ -17,13 +17,13 @@ limitations under the License.
 package webhook
 
 import (
-	"encoding/json"
+	"strings"
 	"testing"
 
-	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/stretchr/testify/assert"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
@@ -206,64 +206,116 @@ func TestPatchSparkPod_Affinity(t *testing.T) {
 		modifiedPod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey)
 }
 
-func TestPatchSparkPod_ConfigMaps(t *testing.T) {
+func TestPatchSparkPod_ExecutorAntiAffinity(t *testing.T) {
+	enabled := true
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-test",
 			UID:  "spark-test-1",
 		},
 		Spec: v1beta1.SparkApplicationSpec{
-			Driver: v1beta1.DriverSpec{
+			SpreadExecutors: &enabled,
+			SeparateDriver:  &enabled,
+			Executor: v1beta1.ExecutorSpec{
 				SparkPodSpec: v1beta1.SparkPodSpec{
-					ConfigMaps: []v1beta1.NamePath{{Name: "foo", Path: "/path/to/foo"}},
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+								{
+									Weight: 1,
+									PodAffinityTerm: corev1.PodAffinityTerm{
+										LabelSelector: &metav1.LabelSelector{
+											MatchLabels: map[string]string{"app": "hdfs"},
+										},
+										TopologyKey: "kubernetes.io/hostname",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 	}
 
-	pod := &corev1.Pod{
+	executorPod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "spark-driver",
+			Name: "spark-executor",
 			Labels: map[string]string{
-				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.SparkRoleLabel:               config.SparkExecutorRole,
 				config.LaunchedBySparkOperatorLabel: "true",
 			},
 		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
-					Name:  sparkDriverContainerName,
-					Image: "spark-driver:latest",
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
 				},
 			},
 		},
 	}
 
-	modifiedPod, err := getModifiedPod(pod, app)
+	modifiedPod, err := getModifiedPod(executorPod, app)
 	if err != nil {
 		t.Fatal(err)
 	}
+	terms := modifiedPod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if assert.Equal(t, 3, len(terms)) {
+		assert.Equal(t, app.Spec.Executor.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0], terms[0])
+		assert.Equal(t, map[string]string{config.SparkAppNameLabel: "spark-test", config.SparkRoleLabel: config.SparkExecutorRole},
+			terms[1].PodAffinityTerm.LabelSelector.MatchLabels)
+		assert.Equal(t, map[string]string{config.SparkAppNameLabel: "spark-test", config.SparkRoleLabel: config.SparkDriverRole},
+			terms[2].PodAffinityTerm.LabelSelector.MatchLabels)
+		assert.Equal(t, config.NodeHostnameLabel, terms[2].PodAffinityTerm.TopologyKey)
+	}
+	// The affinity in the SparkApplication is not modified.
+	assert.Equal(t, 1, len(app.Spec.Executor.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution))
 
-	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
-	assert.Equal(t, "foo-vol", modifiedPod.Spec.Volumes[0].Name)
-	assert.True(t, modifiedPod.Spec.Volumes[0].ConfigMap != nil)
-	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
-	assert.Equal(t, "/path/to/foo", modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	// The driver is left alone.
+	driverPod := executorPod.DeepCopy()
+	driverPod.Labels[config.SparkRoleLabel] = config.SparkDriverRole
+	driverPod.Spec.Containers[0].Name = sparkDriverContainerName
+	modifiedPod, err = getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, modifiedPod.Spec.Affinity)
 }
 
-func TestPatchSparkPod_SparkConfigMap(t *testing.T) {
-	sparkConfMapName := "spark-conf"
+func TestPatchSparkPod_Zone(t *testing.T) {
+	zone := "us-east-1a"
+	sameAsDriver := v1beta1.SameAsDriverZoneAffinity
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-test",
 			UID:  "spark-test-1",
 		},
 		Spec: v1beta1.SparkApplicationSpec{
-			SparkConfigMap: &sparkConfMapName,
+			Zone: &zone,
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{
+									{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+									{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+								},
+							},
+						},
+					},
+				},
+			},
 		},
 	}
+	zoneRequirement := corev1.NodeSelectorRequirement{
+		Key:      config.NodeZoneLabel,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{zone},
+	}
 
-	pod := &corev1.Pod{
+	driverPod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-driver",
 			Labels: map[string]string{
@@ -280,30 +332,140 @@ func TestPatchSparkPod_SparkConfigMap(t *testing.T) {
 			},
 		},
 	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
 
-	modifiedPod, err := getModifiedPod(pod, app)
+	// The driver and executors are pinned to the zone, in addition to the node affinity in the spec.
+	modifiedPod, err := getModifiedPod(driverPod, app)
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{zoneRequirement}}},
+		modifiedPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := modifiedPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if assert.Equal(t, 2, len(terms)) {
+		assert.Equal(t, zoneRequirement, terms[0].MatchExpressions[1])
+		assert.Equal(t, zoneRequirement, terms[1].MatchExpressions[1])
+	}
 
-	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
-	assert.Equal(t, config.SparkConfigMapVolumeName, modifiedPod.Spec.Volumes[0].Name)
-	assert.True(t, modifiedPod.Spec.Volumes[0].ConfigMap != nil)
-	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
-	assert.Equal(t, config.DefaultSparkConfDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
-	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].Env))
-	assert.Equal(t, config.DefaultSparkConfDir, modifiedPod.Spec.Containers[0].Env[0].Value)
+	// Executors follow the driver into its zone.
+	app.Spec.Zone = nil
+	app.Spec.Executor.Affinity = nil
+	app.Spec.ZoneAffinity = &sameAsDriver
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	podAffinityTerms := modifiedPod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if assert.Equal(t, 1, len(podAffinityTerms)) {
+		assert.Equal(t, config.NodeZoneLabel, podAffinityTerms[0].TopologyKey)
+		assert.Equal(t, map[string]string{config.SparkAppNameLabel: "spark-test", config.SparkRoleLabel: config.SparkDriverRole},
+			podAffinityTerms[0].LabelSelector.MatchLabels)
+	}
+	modifiedPod, err = getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, modifiedPod.Spec.Affinity)
 }
 
-func TestPatchSparkPod_HadoopConfigMap(t *testing.T) {
-	hadoopConfMapName := "hadoop-conf"
+func TestPatchSparkPod_NodeFeatures(t *testing.T) {
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-test",
 			UID:  "spark-test-1",
 		},
 		Spec: v1beta1.SparkApplicationSpec{
-			HadoopConfigMap: &hadoopConfMapName,
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					RequireNodeFeatures: []string{"network-25g", "local-nvme", "example.com/storage=ssd"},
+				},
+			},
+		},
+	}
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkDriverContainerName, Image: "spark-driver:latest"}},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkExecutorContainerName, Image: "spark-executor:latest"}},
+		},
+	}
+
+	// Only the executors require the features.
+	modifiedPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, modifiedPod.Spec.Affinity)
+
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := modifiedPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "example.com/storage", Operator: corev1.NodeSelectorOpIn, Values: []string{"ssd"}},
+		{Key: "feature.node.kubernetes.io/local-nvme", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}},
+		{Key: "feature.node.kubernetes.io/network-25g", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}},
+	}}}, terms)
+
+	// Invalid features are ignored.
+	app.Spec.Executor.RequireNodeFeatures = []string{"local nvme"}
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, modifiedPod.Spec.Affinity)
+}
+
+func TestPatchSparkPod_ConfigMaps(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					ConfigMaps: []v1beta1.NamePath{{Name: "foo", Path: "/path/to/foo"}},
+				},
+			},
 		},
 	}
 
@@ -331,15 +493,14 @@ func TestPatchSparkPod_HadoopConfigMap(t *testing.T) {
 	}
 
 	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
-	assert.Equal(t, config.HadoopConfigMapVolumeName, modifiedPod.Spec.Volumes[0].Name)
+	assert.Equal(t, "foo-vol", modifiedPod.Spec.Volumes[0].Name)
 	assert.True(t, modifiedPod.Spec.Volumes[0].ConfigMap != nil)
 	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
-	assert.Equal(t, config.DefaultHadoopConfDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
-	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].Env))
-	assert.Equal(t, config.DefaultHadoopConfDir, modifiedPod.Spec.Containers[0].Env[0].Value)
+	assert.Equal(t, "/path/to/foo", modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
 }
 
-func TestPatchSparkPod_Tolerations(t *testing.T) {
+func TestPatchSparkPod_GeneralConfigMaps_Names(t *testing.T) {
+	longName := strings.Repeat("a", 70)
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-test",
@@ -348,20 +509,16 @@ func TestPatchSparkPod_Tolerations(t *testing.T) {
 		Spec: v1beta1.SparkApplicationSpec{
 			Driver: v1beta1.DriverSpec{
 				SparkPodSpec: v1beta1.SparkPodSpec{
-					Tolerations: []corev1.Toleration{
-						{
-							Key:      "Key",
-							Operator: "Equal",
-							Value:    "Value",
-							Effect:   "NoEffect",
-						},
+					ConfigMaps: []v1beta1.NamePath{
+						{Name: longName + "-1", Path: "/path/to/1"},
+						{Name: longName + "-2", Path: "/path/to/2"},
+						{Name: longName + "-2", Path: "/path/to/2-again"},
 					},
 				},
 			},
 		},
 	}
 
-	// Test patching a pod with a Toleration.
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-driver",
@@ -385,38 +542,76 @@ func TestPatchSparkPod_Tolerations(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, 1, len(modifiedPod.Spec.Tolerations))
-	assert.Equal(t, app.Spec.Driver.Tolerations[0], modifiedPod.Spec.Tolerations[0])
+	// ConfigMaps with names sharing a long prefix get distinct volumes, and a ConfigMap mounted twice gets one.
+	assert.Equal(t, 2, len(modifiedPod.Spec.Volumes))
+	assert.NotEqual(t, modifiedPod.Spec.Volumes[0].Name, modifiedPod.Spec.Volumes[1].Name)
+	for _, volume := range modifiedPod.Spec.Volumes {
+		assert.True(t, len(volume.Name) <= 63)
+	}
+	mounts := modifiedPod.Spec.Containers[0].VolumeMounts
+	assert.Equal(t, 3, len(mounts))
+	assert.Equal(t, mounts[1].Name, mounts[2].Name)
 }
 
-func TestPatchSparkPod_SecurityContext(t *testing.T) {
-	var user int64 = 1000
+func TestPatchSparkPod_SparkConfigMap(t *testing.T) {
+	sparkConfMapName := "spark-conf"
 	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-test",
 			UID:  "spark-test-1",
 		},
 		Spec: v1beta1.SparkApplicationSpec{
-			Driver: v1beta1.DriverSpec{
-				SparkPodSpec: v1beta1.SparkPodSpec{
-					SecurityContenxt: &corev1.PodSecurityContext{
-						RunAsUser: &user,
-					},
-				},
+			SparkConfigMap: &sparkConfMapName,
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
 			},
-			Executor: v1beta1.ExecutorSpec{
-				SparkPodSpec: v1beta1.SparkPodSpec{
-					SecurityContenxt: &corev1.PodSecurityContext{
-						RunAsUser: &user,
-					},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
 				},
 			},
 		},
 	}
 
-	driverPod := &corev1.Pod{
+	modifiedPod, err := getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, config.SparkConfigMapVolumeName, modifiedPod.Spec.Volumes[0].Name)
+	assert.True(t, modifiedPod.Spec.Volumes[0].ConfigMap != nil)
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
+	assert.Equal(t, config.DefaultSparkConfDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].Env))
+	assert.Equal(t, config.DefaultSparkConfDir, modifiedPod.Spec.Containers[0].Env[0].Value)
+}
+
+func TestPatchSparkPod_HadoopConfigMap(t *testing.T) {
+	hadoopConfMapName := "hadoop-conf"
+	app := &v1beta1.SparkApplication{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "spark-executor",
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			HadoopConfigMap: &hadoopConfMapName,
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
 			Labels: map[string]string{
 				config.SparkRoleLabel:               config.SparkDriverRole,
 				config.LaunchedBySparkOperatorLabel: "true",
@@ -432,13 +627,33 @@ func TestPatchSparkPod_SecurityContext(t *testing.T) {
 		},
 	}
 
-	modifiedDriverPod, err := getModifiedPod(driverPod, app)
+	modifiedPod, err := getModifiedPod(pod, app)
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Equal(t, app.Spec.Executor.SecurityContenxt, modifiedDriverPod.Spec.SecurityContext)
 
-	executorPod := &corev1.Pod{
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, config.HadoopConfigMapVolumeName, modifiedPod.Spec.Volumes[0].Name)
+	assert.True(t, modifiedPod.Spec.Volumes[0].ConfigMap != nil)
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
+	assert.Equal(t, config.DefaultHadoopConfDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].Env))
+	assert.Equal(t, config.DefaultHadoopConfDir, modifiedPod.Spec.Containers[0].Env[0].Value)
+}
+
+func TestPatchSparkPod_HadoopClusterRef(t *testing.T) {
+	clusterName := "prod"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			HadoopClusterRef: &clusterName,
+		},
+	}
+
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "spark-executor",
 			Labels: map[string]string{
@@ -456,36 +671,1524 @@ func TestPatchSparkPod_SecurityContext(t *testing.T) {
 		},
 	}
 
-	modifiedExecutorPod, err := getModifiedPod(executorPod, app)
+	modifiedPod, err := getModifiedPod(pod, app)
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Equal(t, app.Spec.Executor.SecurityContenxt, modifiedExecutorPod.Spec.SecurityContext)
-}
 
-func getModifiedPod(pod *corev1.Pod, app *v1beta1.SparkApplication) (*corev1.Pod, error) {
-	patchOps := patchSparkPod(pod, app)
-	patchBytes, err := json.Marshal(patchOps)
-	if err != nil {
-		return nil, err
-	}
-	patch, err := jsonpatch.DecodePatch(patchBytes)
-	if err != nil {
-		return nil, err
-	}
+	// The ConfigMap generated from the HadoopCluster is mounted.
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, "spark-test-hadoop-conf", modifiedPod.Spec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, config.DefaultHadoopConfDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, config.HadoopConfDirEnvVar, modifiedPod.Spec.Containers[0].Env[0].Name)
+}
 
-	original, err := json.Marshal(pod)
-	if err != nil {
-		return nil, err
-	}
-	modified, err := patch.Apply(original)
+func TestPatchSparkPod_StartGate(t *testing.T) {
+	minExecutors := int32(4)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			MinExecutorsBeforeStart: &minExecutors,
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, config.StartGateVolumeName, modifiedPod.Spec.Volumes[0].Name)
+	assert.Equal(t, "spark-test-start-gate", modifiedPod.Spec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
+	assert.Equal(t, config.StartGateDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, []corev1.EnvVar{{Name: config.StartGateFileEnvVar, Value: "/etc/spark-start-gate/open"}},
+		modifiedPod.Spec.Containers[0].Env)
+
+	// Executors do not wait for the gate.
+	executor := pod.DeepCopy()
+	executor.Labels[config.SparkRoleLabel] = config.SparkExecutorRole
+	executor.Spec.Containers[0].Name = sparkExecutorContainerName
+	modifiedPod, err = getModifiedPod(executor, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedPod.Spec.Volumes))
+}
+
+func TestPatchSparkPod_AWSWebIdentity(t *testing.T) {
+	roleARN := "arn:aws:iam::123456789012:role/spark"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			CloudIdentity: &v1beta1.CloudIdentitySpec{AWSRoleARN: &roleARN},
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, config.AWSIAMTokenVolumeName, modifiedPod.Spec.Volumes[0].Name)
+	projection := modifiedPod.Spec.Volumes[0].Projected.Sources[0].ServiceAccountToken
+	assert.Equal(t, config.AWSIAMTokenAudience, projection.Audience)
+	assert.Equal(t, config.AWSIAMTokenDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: config.AWSRoleARNEnvVar, Value: roleARN},
+		{Name: config.AWSWebIdentityTokenFileEnvVar, Value: config.AWSIAMTokenDir + "/token"},
+	}, modifiedPod.Spec.Containers[0].Env)
+
+	// The token is not added again if the EKS pod identity webhook has added it.
+	modifiedPod, err = getModifiedPod(modifiedPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, 2, len(modifiedPod.Spec.Containers[0].Env))
+}
+
+func TestPatchSparkPod_Tolerations(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					Tolerations: []corev1.Toleration{
+						{
+							Key:      "Key",
+							Operator: "Equal",
+							Value:    "Value",
+							Effect:   "NoEffect",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Test patching a pod with a Toleration.
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(modifiedPod.Spec.Tolerations))
+	assert.Equal(t, app.Spec.Driver.Tolerations[0], modifiedPod.Spec.Tolerations[0])
+}
+
+func TestPatchSparkPod_NodeFailureTolerations(t *testing.T) {
+	driverSeconds := int64(10)
+	executorSeconds := int64(600)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					NotReadyTolerationSeconds:    &driverSeconds,
+					UnreachableTolerationSeconds: &driverSeconds,
+				},
+			},
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					NotReadyTolerationSeconds:    &executorSeconds,
+					UnreachableTolerationSeconds: &executorSeconds,
+					Tolerations: []corev1.Toleration{
+						{
+							Key:      config.NodeUnreachableTaintKey,
+							Operator: corev1.TolerationOpExists,
+							Effect:   corev1.TaintEffectNoExecute,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The tolerations added by the DefaultTolerationSeconds admission plugin get replaced.
+	defaultSeconds := int64(300)
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Key:               config.NodeNotReadyTaintKey,
+					Operator:          corev1.TolerationOpExists,
+					Effect:            corev1.TaintEffectNoExecute,
+					TolerationSeconds: &defaultSeconds,
+				},
+			},
+		},
+	}
+	modifiedPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(modifiedPod.Spec.Tolerations)) {
+		assert.Equal(t, config.NodeNotReadyTaintKey, modifiedPod.Spec.Tolerations[0].Key)
+		assert.Equal(t, driverSeconds, *modifiedPod.Spec.Tolerations[0].TolerationSeconds)
+		assert.Equal(t, config.NodeUnreachableTaintKey, modifiedPod.Spec.Tolerations[1].Key)
+		assert.Equal(t, driverSeconds, *modifiedPod.Spec.Tolerations[1].TolerationSeconds)
+	}
+
+	// A toleration listed in the SparkApplication takes precedence.
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(modifiedPod.Spec.Tolerations)) {
+		assert.Equal(t, app.Spec.Executor.Tolerations[0], modifiedPod.Spec.Tolerations[0])
+		assert.Equal(t, config.NodeNotReadyTaintKey, modifiedPod.Spec.Tolerations[1].Key)
+		assert.Equal(t, executorSeconds, *modifiedPod.Spec.Tolerations[1].TolerationSeconds)
+	}
+}
+
+func TestPatchSparkPod_SecurityContext(t *testing.T) {
+	var user int64 = 1000
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					SecurityContenxt: &corev1.PodSecurityContext{
+						RunAsUser: &user,
+					},
+				},
+			},
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					SecurityContenxt: &corev1.PodSecurityContext{
+						RunAsUser: &user,
+					},
+				},
+			},
+		},
+	}
+
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, app.Spec.Executor.SecurityContenxt, modifiedDriverPod.Spec.SecurityContext)
+
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedExecutorPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, app.Spec.Executor.SecurityContenxt, modifiedExecutorPod.Spec.SecurityContext)
+}
+
+func TestPatchSparkPod_UIProxy(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConf: map[string]string{config.SparkUIPortKey: "4041"},
+			Driver: v1beta1.DriverSpec{
+				UIProxy: &v1beta1.UIProxySpec{
+					OIDCIssuerURL: "https://accounts.example.com",
+					ClientID:      "spark-ui",
+					SecretName:    "spark-ui-oauth2",
+					AllowedGroups: []string{"data-eng", "data-science"},
+				},
+			},
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(modifiedPod.Spec.Containers))
+	proxy := modifiedPod.Spec.Containers[1]
+	assert.Equal(t, config.UIProxyContainerName, proxy.Name)
+	assert.Equal(t, config.DefaultUIProxyImage, proxy.Image)
+	assert.Equal(t, config.DefaultUIProxyPort, proxy.Ports[0].ContainerPort)
+	assert.Contains(t, proxy.Args, "--oidc-issuer-url=https://accounts.example.com")
+	assert.Contains(t, proxy.Args, "--upstream=http://127.0.0.1:4041/")
+	assert.Contains(t, proxy.Args, "--email-domain=*")
+	assert.Contains(t, proxy.Args, "--allowed-group=data-eng")
+	assert.Contains(t, proxy.Args, "--allowed-group=data-science")
+	assert.Equal(t, 2, len(proxy.Env))
+	assert.Equal(t, "spark-ui-oauth2", proxy.Env[0].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, config.UIProxyClientSecretKey, proxy.Env[0].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, config.UIProxyCookieSecretKey, proxy.Env[1].ValueFrom.SecretKeyRef.Key)
+}
+
+func TestPatchSparkPod_Networks(t *testing.T) {
+	sriov := "mellanox.com/sriov"
+	networkNamespace := "networks"
+	rdmaInterface := "rdma0"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					Networks: []v1beta1.NetworkAttachment{
+						{Name: "sriov-a", ResourceName: &sriov},
+						{Name: "sriov-b", Namespace: &networkNamespace, Interface: &rdmaInterface, ResourceName: &sriov},
+						{Name: "macvlan"},
+					},
+				},
+			},
+		},
+	}
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkDriverContainerName, Image: "spark-driver:latest"}},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkExecutorContainerName, Image: "spark-executor:latest"}},
+		},
+	}
+
+	// Only the executors are attached to the networks.
+	modifiedPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, modifiedPod.Annotations, config.MultusNetworksAnnotation)
+	assert.Equal(t, 0, len(modifiedPod.Spec.Containers[0].Resources.Limits))
+
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t,
+		`[{"name":"sriov-a"},{"name":"sriov-b","namespace":"networks","interface":"rdma0"},{"name":"macvlan"}]`,
+		modifiedPod.Annotations[config.MultusNetworksAnnotation])
+	assert.Equal(t, resource.MustParse("2"),
+		modifiedPod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(sriov)])
+}
+
+func TestPatchSparkPod_MLMode(t *testing.T) {
+	networkInterface := "net1"
+	topologyKey := "example.com/nvlink-domain"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			MLMode: &v1beta1.MLModeSpec{
+				GPU:              &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 4},
+				NetworkInterface: &networkInterface,
+				TopologyKey:      &topologyKey,
+			},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  sparkExecutorContainerName,
+				Image: "spark-executor:latest",
+				Env:   []corev1.EnvVar{{Name: "NCCL_SOCKET_IFNAME", Value: "eth0"}},
+			}},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	container := modifiedPod.Spec.Containers[0]
+	assert.Equal(t, resource.MustParse("4"), container.Resources.Limits[corev1.ResourceName("nvidia.com/gpu")])
+	env := make(map[string]string)
+	for _, envVar := range container.Env {
+		env[envVar.Name] = envVar.Value
+	}
+	// Environment variables the container sets are kept.
+	assert.Equal(t, map[string]string{
+		"NCCL_SOCKET_IFNAME":          "eth0",
+		"GLOO_SOCKET_IFNAME":          "net1",
+		"HOROVOD_GLOO_IFACE":          "net1",
+		"HOROVOD_GPU_OPERATIONS":      "NCCL",
+		"OMPI_MCA_btl_tcp_if_include": "net1",
+	}, env)
+	assert.Equal(t, 5, len(container.Env))
+
+	terms := modifiedPod.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, 1, len(terms))
+	assert.Equal(t, topologyKey, terms[0].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, config.SparkExecutorRole, terms[0].PodAffinityTerm.LabelSelector.MatchLabels[config.SparkRoleLabel])
+}
+
+func TestPatchSparkPod_GPU(t *testing.T) {
+	quantity := int64(2)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			GPU: &v1beta1.GPUAccelerationSpec{Enabled: true, Quantity: &quantity},
+		},
+	}
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkDriverContainerName, Image: "spark-driver:latest"}},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkExecutorContainerName, Image: "spark-executor:latest"}},
+		},
+	}
+
+	// Only the executors get GPUs.
+	modifiedPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, 0, len(modifiedPod.Spec.Containers[0].Resources.Limits))
+
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, resource.MustParse("2"),
+		modifiedPod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(config.DefaultGPUResourceName)])
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, "spark-test-gpu-discovery", modifiedPod.Spec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, int32(0755), *modifiedPod.Spec.Volumes[0].ConfigMap.DefaultMode)
+	assert.Equal(t, config.GPUDiscoveryDir, modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+
+	// Disabled GPUs are ignored.
+	app.Spec.GPU.Enabled = false
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedPod.Spec.Volumes))
+}
+
+func TestPatchSparkPod_DataCache(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-test",
+			Namespace: "default",
+			UID:       "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			DataCache: &v1beta1.DataCacheSpec{Name: "hot-tables"},
+		},
+	}
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkDriverContainerName, Image: "spark-driver:latest"}},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkExecutorContainerName, Image: "spark-executor:latest"}},
+		},
+	}
+
+	// Only the executors mount the cache.
+	modifiedPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedPod.Spec.Volumes))
+
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, "/var/cache/spark-data/default/hot-tables", modifiedPod.Spec.Volumes[0].HostPath.Path)
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].VolumeMounts))
+	mount := modifiedPod.Spec.Containers[0].VolumeMounts[0]
+	assert.Equal(t, config.DataCacheVolumeName, mount.Name)
+	assert.Equal(t, config.DefaultDataCacheMountPath, mount.MountPath)
+	assert.Equal(t, corev1.MountPropagationHostToContainer, *mount.MountPropagation)
+
+	mountPath := "/cache"
+	app.Spec.DataCache.MountPath = &mountPath
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/cache", modifiedPod.Spec.Containers[0].VolumeMounts[0].MountPath)
+}
+
+func TestPatchSparkPod_ConnectionPooler(t *testing.T) {
+	poolSize := int32(4)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			ConnectionPooler: &v1beta1.ConnectionPoolerSpec{
+				DatabaseHost: "metastore-db",
+				SecretName:   "metastore-db-credentials",
+				PoolSize:     &poolSize,
+			},
+		},
+	}
+	containerNames := map[string]string{
+		config.SparkDriverRole:   sparkDriverContainerName,
+		config.SparkExecutorRole: sparkExecutorContainerName,
+	}
+	for role, containerName := range containerNames {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "spark-" + role,
+				Labels: map[string]string{
+					config.SparkRoleLabel:               role,
+					config.LaunchedBySparkOperatorLabel: "true",
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: containerName, Image: "spark:latest"}},
+			},
+		}
+		modifiedPod, err := getModifiedPod(pod, app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 2, len(modifiedPod.Spec.Containers))
+		sidecar := modifiedPod.Spec.Containers[1]
+		assert.Equal(t, config.ConnectionPoolerContainerName, sidecar.Name)
+		assert.Equal(t, config.DefaultConnectionPoolerImage, sidecar.Image)
+		env := make(map[string]corev1.EnvVar)
+		for _, envVar := range sidecar.Env {
+			env[envVar.Name] = envVar
+		}
+		assert.Equal(t, "metastore-db", env["DB_HOST"].Value)
+		assert.Equal(t, "5432", env["DB_PORT"].Value)
+		assert.Equal(t, "6432", env["LISTEN_PORT"].Value)
+		assert.Equal(t, "4", env["DEFAULT_POOL_SIZE"].Value)
+		assert.Equal(t, "transaction", env["POOL_MODE"].Value)
+		assert.Equal(t, "metastore-db-credentials", env["DB_PASSWORD"].ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, config.ConnectionPoolerPasswordKey, env["DB_PASSWORD"].ValueFrom.SecretKeyRef.Key)
+	}
+}
+
+func TestPatchSparkPod_Dumps(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Debug: &v1beta1.DebugSpec{ClaimName: "dumps"},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+			Annotations: map[string]string{config.DumpPathAnnotation: "spark-test/1600000000"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: sparkExecutorContainerName, Image: "spark-executor:latest"}},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(modifiedPod.Spec.Volumes))
+	assert.Equal(t, "dumps", modifiedPod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, corev1.VolumeMount{
+		Name:      config.DumpVolumeName,
+		MountPath: config.DumpDir,
+		SubPath:   "spark-test/1600000000/spark-executor",
+	}, modifiedPod.Spec.Containers[0].VolumeMounts[0])
+
+	// Pods of runs submitted before dumps were configured are not changed.
+	delete(executorPod.Annotations, config.DumpPathAnnotation)
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedPod.Spec.Volumes))
+}
+
+func TestPatchSparkPod_EphemeralStorage(t *testing.T) {
+	request := "10Gi"
+	limit := "20Gi"
+	invalid := "lots"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					EphemeralStorage:      &request,
+					EphemeralStorageLimit: &invalid,
+				},
+			},
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					EphemeralStorage:      &request,
+					EphemeralStorageLimit: &limit,
+				},
+			},
+		},
+	}
+
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := modifiedDriverPod.Spec.Containers[0].Resources
+	assert.Equal(t, 2, len(resources.Requests))
+	assert.Equal(t, resource.MustParse("1"), resources.Requests[corev1.ResourceCPU])
+	assert.Equal(t, resource.MustParse(request), resources.Requests[corev1.ResourceEphemeralStorage])
+	assert.Equal(t, 0, len(resources.Limits))
+
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedExecutorPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources = modifiedExecutorPod.Spec.Containers[0].Resources
+	assert.Equal(t, resource.MustParse(request), resources.Requests[corev1.ResourceEphemeralStorage])
+	assert.Equal(t, resource.MustParse(limit), resources.Limits[corev1.ResourceEphemeralStorage])
+}
+
+func TestPatchSparkPod_LocalDirs(t *testing.T) {
+	sizeLimit := resource.MustParse("4Gi")
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{
+				LocalDirs: []v1beta1.LocalDir{
+					{
+						Name:      "spark-local-tmpfs",
+						MountPath: "/tmp/spark-local-tmpfs",
+						EmptyDir: &corev1.EmptyDirVolumeSource{
+							Medium:    corev1.StorageMediumMemory,
+							SizeLimit: &sizeLimit,
+						},
+					},
+					{
+						Name:      "spark-local-ssd",
+						MountPath: "/tmp/spark-local-ssd",
+						HostPath:  &corev1.HostPathVolumeSource{Path: "/mnt/disks/ssd0"},
+					},
+					{
+						Name:      "spark-local-invalid",
+						MountPath: "/tmp/spark-local-invalid",
+					},
+				},
+			},
+		},
+	}
+
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedDriverPod.Spec.Volumes))
+	assert.Equal(t, 0, len(modifiedDriverPod.Spec.Containers[0].Env))
+
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedExecutorPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(modifiedExecutorPod.Spec.Volumes))
+	assert.Equal(t, "spark-local-tmpfs", modifiedExecutorPod.Spec.Volumes[0].Name)
+	assert.Equal(t, corev1.StorageMediumMemory, modifiedExecutorPod.Spec.Volumes[0].EmptyDir.Medium)
+	assert.Equal(t, "spark-local-ssd", modifiedExecutorPod.Spec.Volumes[1].Name)
+	assert.Equal(t, "/mnt/disks/ssd0", modifiedExecutorPod.Spec.Volumes[1].HostPath.Path)
+	assert.Equal(t, 2, len(modifiedExecutorPod.Spec.Containers[0].VolumeMounts))
+	assert.Equal(t, "/tmp/spark-local-ssd", modifiedExecutorPod.Spec.Containers[0].VolumeMounts[1].MountPath)
+	assert.Equal(t, 1, len(modifiedExecutorPod.Spec.Containers[0].Env))
+	assert.Equal(t, config.SparkLocalDirsEnvVar, modifiedExecutorPod.Spec.Containers[0].Env[0].Name)
+	assert.Equal(t, "/tmp/spark-local-tmpfs,/tmp/spark-local-ssd", modifiedExecutorPod.Spec.Containers[0].Env[0].Value)
+}
+
+func TestPatchSparkPod_TerminationGracePeriod(t *testing.T) {
+	gracePeriod := int64(120)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Driver: v1beta1.DriverSpec{TerminationGracePeriodSeconds: &gracePeriod},
+		},
+	}
+	defaultGracePeriod := int64(30)
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers:                    []corev1.Container{{Name: sparkDriverContainerName, Image: "spark-driver:latest"}},
+			TerminationGracePeriodSeconds: &defaultGracePeriod,
+		},
+	}
+	modifiedPod, err := getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, gracePeriod, *modifiedPod.Spec.TerminationGracePeriodSeconds)
+
+	// Executors are not affected.
+	executorPod := driverPod.DeepCopy()
+	executorPod.Labels[config.SparkRoleLabel] = config.SparkExecutorRole
+	executorPod.Spec.Containers[0].Name = sparkExecutorContainerName
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, defaultGracePeriod, *modifiedPod.Spec.TerminationGracePeriodSeconds)
+}
+
+func getModifiedPod(pod *corev1.Pod, app *v1beta1.SparkApplication) (*corev1.Pod, error) {
+	return getModifiedPodWithConfig(pod, app, patchConfig{})
+}
+
+func getModifiedPodWithConfig(pod *corev1.Pod, app *v1beta1.SparkApplication, cfg patchConfig) (*corev1.Pod, error) {
+	return applyPatch(pod, patchSparkPod(pod, app, cfg))
+}
+
+func TestPatchSparkPod_IstioAnnotations(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			SparkConf: map[string]string{config.SparkDriverPortKey: "8000"},
+		},
+	}
+
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPodWithConfig(driverPod, app, patchConfig{enableIstioMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "8000,7079", modifiedDriverPod.Annotations[config.IstioExcludeInboundPortsAnnotation])
+	assert.Equal(t, "8000,7079", modifiedDriverPod.Annotations[config.IstioExcludeOutboundPortsAnnotation])
+
+	// Existing annotations are kept.
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+			Annotations: map[string]string{
+				config.IstioExcludeInboundPortsAnnotation: "9000",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedExecutorPod, err := getModifiedPodWithConfig(executorPod, app, patchConfig{enableIstioMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "9000", modifiedExecutorPod.Annotations[config.IstioExcludeInboundPortsAnnotation])
+	assert.Equal(t, "8000,7079", modifiedExecutorPod.Annotations[config.IstioExcludeOutboundPortsAnnotation])
+
+	// Nothing is added if Istio mode is disabled.
+	modifiedDriverPod, err = getModifiedPod(driverPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedDriverPod.Annotations))
+}
+
+func TestPatchSparkPod_SecurityProfiles(t *testing.T) {
+	profile := "localhost/spark"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					SeccompProfile:  &profile,
+					AppArmorProfile: &profile,
+				},
+			},
+		},
+	}
+	cfg := patchConfig{defaultSeccompProfile: "runtime/default", defaultAppArmorProfile: "runtime/default"}
+
+	// The driver gets the operator-level defaults.
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+
+	modifiedDriverPod, err := getModifiedPodWithConfig(driverPod, app, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "bar", modifiedDriverPod.Annotations["foo"])
+	assert.Equal(t, "runtime/default", modifiedDriverPod.Annotations[config.SeccompPodAnnotation])
+	assert.Equal(t, "runtime/default",
+		modifiedDriverPod.Annotations[config.AppArmorAnnotationKeyPrefix+sparkDriverContainerName])
+
+	// The executors get the profiles specified in the SparkApplication.
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedExecutorPod, err := getModifiedPodWithConfig(executorPod, app, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, profile, modifiedExecutorPod.Annotations[config.SeccompPodAnnotation])
+	assert.Equal(t, profile,
+		modifiedExecutorPod.Annotations[config.AppArmorAnnotationKeyPrefix+sparkExecutorContainerName])
+}
+
+func TestPatchSparkPod_LinuxNodeSelector(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+			NodeSelector: map[string]string{"pool": "spark"},
+		},
+	}
+
+	modifiedPod, err := getModifiedPodWithConfig(executorPod, app, patchConfig{enforceLinuxNodes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"pool": "spark", config.NodeOSLabel: config.LinuxNodeOS}, modifiedPod.Spec.NodeSelector)
+
+	// Pods already selecting nodes by OS are left alone.
+	executorPod.Spec.NodeSelector = map[string]string{config.BetaNodeOSLabel: config.LinuxNodeOS}
+	modifiedPod, err = getModifiedPodWithConfig(executorPod, app, patchConfig{enforceLinuxNodes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{config.BetaNodeOSLabel: config.LinuxNodeOS}, modifiedPod.Spec.NodeSelector)
+
+	// Nothing is added if not enforced.
+	executorPod.Spec.NodeSelector = nil
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(modifiedPod.Spec.NodeSelector))
+}
+
+func TestPatchSparkPod_ExecutorGroups(t *testing.T) {
+	coreLimit := "4"
+	ephemeralStorageLimit := "20Gi"
+	gpuProfileID := int32(5)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					EphemeralStorageLimit: &ephemeralStorageLimit,
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{},
+					},
+				},
+			},
+			ExecutorGroups: []v1beta1.ExecutorGroup{
+				{
+					Name:         "highmem",
+					NodeSelector: map[string]string{"pool": "highmem"},
+				},
+				{
+					Name:              "gpu",
+					ResourceProfileID: &gpuProfileID,
+					CoreLimit:         &coreLimit,
+					GPU:               &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 2},
+					NodeSelector:      map[string]string{"pool": "gpu"},
+					Tolerations: []corev1.Toleration{
+						{
+							Key:      "nvidia.com/gpu",
+							Operator: corev1.TolerationOpExists,
+							Effect:   corev1.TaintEffectNoSchedule,
+						},
+					},
+					Affinity: &corev1.Affinity{
+						PodAffinity: &corev1.PodAffinity{},
+					},
+					Labels: map[string]string{"accelerator": "true"},
+				},
+			},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+				config.SparkResourceProfileIDLabel:  "5",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+				},
+			},
+		},
+	}
+
+	modifiedPod, err := getModifiedPodWithConfig(executorPod, app, patchConfig{enforceLinuxNodes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "gpu", modifiedPod.Labels[config.ExecutorGroupLabel])
+	assert.Equal(t, "true", modifiedPod.Labels["accelerator"])
+	resources := modifiedPod.Spec.Containers[0].Resources
+	assert.Equal(t, 3, len(resources.Limits))
+	assert.Equal(t, resource.MustParse(coreLimit), resources.Limits[corev1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("2"), resources.Limits[corev1.ResourceName("nvidia.com/gpu")])
+	assert.Equal(t, resource.MustParse(ephemeralStorageLimit), resources.Limits[corev1.ResourceEphemeralStorage])
+	assert.Equal(t, map[string]string{"pool": "gpu", config.NodeOSLabel: config.LinuxNodeOS}, modifiedPod.Spec.NodeSelector)
+	assert.Equal(t, app.Spec.ExecutorGroups[1].Tolerations, modifiedPod.Spec.Tolerations)
+	assert.NotNil(t, modifiedPod.Spec.Affinity.PodAffinity)
+	assert.Nil(t, modifiedPod.Spec.Affinity.NodeAffinity)
+
+	// The resource profile ID of a group defaults to its position in the executor groups.
+	executorPod.Labels[config.SparkResourceProfileIDLabel] = "1"
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "highmem", modifiedPod.Labels[config.ExecutorGroupLabel])
+	assert.Equal(t, map[string]string{"pool": "highmem"}, modifiedPod.Spec.NodeSelector)
+	assert.NotNil(t, modifiedPod.Spec.Affinity.NodeAffinity)
+
+	// Executors of the default resource profile are not in any group.
+	executorPod.Labels[config.SparkResourceProfileIDLabel] = "0"
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := modifiedPod.Labels[config.ExecutorGroupLabel]
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(modifiedPod.Spec.NodeSelector))
+}
+
+func TestPatchSparkPod_ResourceProfiles(t *testing.T) {
+	executorStorage := "10Gi"
+	profileStorage := "50Gi"
+	profileCoreLimit := "4"
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			Executor: v1beta1.ExecutorSpec{
+				SparkPodSpec: v1beta1.SparkPodSpec{
+					EphemeralStorage: &executorStorage,
+				},
+			},
+			ResourceProfiles: []v1beta1.ResourceProfile{
+				{
+					ID:               1,
+					CoreLimit:        &profileCoreLimit,
+					EphemeralStorage: &profileStorage,
+					GPU:              &v1beta1.GPUSpec{Name: "nvidia.com/gpu", Quantity: 1},
+				},
+			},
+		},
+	}
+	executorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+				config.SparkResourceProfileIDLabel:  "1",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+			},
+		},
+	}
+
+	// The resources of the profile replace the ones set from the executor spec.
+	modifiedPod, err := getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := modifiedPod.Spec.Containers[0].Resources
+	assert.Equal(t, resource.MustParse("1"), resources.Requests[corev1.ResourceCPU])
+	assert.Equal(t, resource.MustParse(profileStorage), resources.Requests[corev1.ResourceEphemeralStorage])
+	assert.Equal(t, resource.MustParse(profileCoreLimit), resources.Limits[corev1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("1"), resources.Limits[corev1.ResourceName("nvidia.com/gpu")])
+
+	// Executors of other profiles get the resources of the executor spec.
+	executorPod.Labels[config.SparkResourceProfileIDLabel] = "0"
+	modifiedPod, err = getModifiedPod(executorPod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources = modifiedPod.Spec.Containers[0].Resources
+	assert.Equal(t, resource.MustParse(executorStorage), resources.Requests[corev1.ResourceEphemeralStorage])
+	assert.Equal(t, resource.MustParse("1"), resources.Limits[corev1.ResourceCPU])
+	assert.Equal(t, 1, len(resources.Limits))
+}
+
+func TestPatchSparkPod_DefaultEnv(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+					Env:   []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://team-proxy:3128"}},
+				},
+			},
+		},
+	}
+	cfg := patchConfig{defaultEnv: map[string]string{
+		"HTTPS_PROXY":       "http://proxy:3128",
+		"JAVA_TOOL_OPTIONS": "-XX:+UseG1GC",
+	}}
+
+	// Variables set by the container are kept.
+	modifiedPod, err := getModifiedPodWithConfig(pod, app, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://team-proxy:3128"},
+		{Name: "JAVA_TOOL_OPTIONS", Value: "-XX:+UseG1GC"},
+	}, modifiedPod.Spec.Containers[0].Env)
+
+	// SparkApplications can opt out.
+	app.Annotations = map[string]string{config.InjectDefaultEnvAnnotation: "false"}
+	modifiedPod, err = getModifiedPodWithConfig(pod, app, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(modifiedPod.Spec.Containers[0].Env))
+}
+
+func TestPatchSparkPod_PropagatedMetadata(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-test",
+			UID:         "spark-test-1",
+			Labels:      map[string]string{"cost-center": "ads", "team": "x", config.SparkRoleLabel: "app"},
+			Annotations: map[string]string{"data-classification": "pii"},
+		},
+		Spec: v1beta1.SparkApplicationSpec{
+			MetadataPropagation: &v1beta1.MetadataPropagationSpec{Labels: []string{"team", config.SparkRoleLabel}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-driver",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+				"team":                              "y",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkDriverContainerName,
+					Image: "spark-driver:latest",
+				},
+			},
+		},
+	}
+	cfg := patchConfig{propagatedLabels: []string{"cost-center"}, propagatedAnnotations: []string{"data-classification"}}
+
+	// Labels the pod already has are kept.
+	modifiedPod, err := getModifiedPodWithConfig(pod, app, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{
+		config.SparkRoleLabel:               config.SparkDriverRole,
+		config.LaunchedBySparkOperatorLabel: "true",
+		"team":                              "y",
+		"cost-center":                       "ads",
+	}, modifiedPod.Labels)
+	assert.Equal(t, map[string]string{"data-classification": "pii"}, modifiedPod.Annotations)
+
+	// Nothing is propagated without keys.
+	modifiedPod, err = getModifiedPodWithConfig(pod, app, patchConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(modifiedPod.Labels))
+	assert.Equal(t, 0, len(modifiedPod.Annotations))
+}
+
+func TestPatchSparkPod_UserPatch(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-test",
+			UID:  "spark-test-1",
+			Annotations: map[string]string{
+				config.PatchAnnotation: `[
+					{"op": "add", "path": "/spec/hostAliases", "value": [{"ip": "10.0.0.1", "hostnames": ["metastore"]}]},
+					{"op": "add", "path": "/spec/containers/0/env/-", "value": {"name": "FOO", "value": "bar"}},
+					{"op": "add", "path": "/metadata/labels/team", "value": "data"}
+				]`,
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "spark-executor",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkExecutorRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  sparkExecutorContainerName,
+					Image: "spark-executor:latest",
+					Env:   []corev1.EnvVar{{Name: "SPARK_USER", Value: "spark"}},
+				},
+			},
+		},
+	}
+
+	modifiedPod, err := getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []corev1.HostAlias{{IP: "10.0.0.1", Hostnames: []string{"metastore"}}}, modifiedPod.Spec.HostAliases)
+	assert.Equal(t, corev1.EnvVar{Name: "FOO", Value: "bar"}, modifiedPod.Spec.Containers[0].Env[1])
+	assert.Equal(t, "data", modifiedPod.Labels["team"])
+
+	// Patches that do not apply to the pod are ignored instead of making the pod creation fail.
+	app.Annotations[config.PatchAnnotation] = `[{"op": "replace", "path": "/spec/containers/1/env", "value": []}]`
+	modifiedPod, err = getModifiedPod(pod, app)
 	if err != nil {
-		return nil, err
+		t.Fatal(err)
 	}
-	modifiedPod := &corev1.Pod{}
-	if err := json.Unmarshal(modified, modifiedPod); err != nil {
-		return nil, err
+	assert.Equal(t, pod.Spec.Containers, modifiedPod.Spec.Containers)
+
+	// So are patches of fields users may not patch.
+	app.Annotations[config.PatchAnnotation] = `[{"op": "add", "path": "/spec/hostNetwork", "value": true}]`
+	modifiedPod, err = getModifiedPod(pod, app)
+	if err != nil {
+		t.Fatal(err)
 	}
+	assert.False(t, modifiedPod.Spec.HostNetwork)
+}
 
-	return modifiedPod, nil
+func TestIsUserPatchPathAllowed(t *testing.T) {
+	assert.True(t, isUserPatchPathAllowed("/spec/priorityClassName"))
+	assert.True(t, isUserPatchPathAllowed("/spec/tolerations/-"))
+	assert.True(t, isUserPatchPathAllowed("/spec/containers/0/livenessProbe"))
+	assert.True(t, isUserPatchPathAllowed("/spec/containers/1/env/0/value"))
+	assert.True(t, isUserPatchPathAllowed("/metadata/annotations/example.com~1owner"))
+	assert.False(t, isUserPatchPathAllowed("/spec/priorityClassNameX"))
+	assert.False(t, isUserPatchPathAllowed("/spec/containers/-"))
+	assert.False(t, isUserPatchPathAllowed("/spec/containers/0/image"))
+	assert.False(t, isUserPatchPathAllowed("/spec/containers/0/securityContext/privileged"))
+	assert.False(t, isUserPatchPathAllowed("/spec/volumes/-"))
+	assert.False(t, isUserPatchPathAllowed("/spec/serviceAccountName"))
+	assert.False(t, isUserPatchPathAllowed("/metadata/labels"))
+	assert.False(t, isUserPatchPathAllowed("/metadata/labels/spark-role"))
+	assert.False(t, isUserPatchPathAllowed("/metadata/labels/sparkoperator.k8s.io~1app-name"))
+	assert.False(t, isUserPatchPathAllowed("/metadata/ownerReferences"))
 }
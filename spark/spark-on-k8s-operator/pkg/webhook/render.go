@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+// PatchOptions are the operator-level settings the webhook patches Spark pods with, which are set through the
+// flags of the operator.
+type PatchOptions struct {
+	// EnableIstioMode corresponds to -enable-istio-mode.
+	EnableIstioMode bool `json:"enableIstioMode,omitempty"`
+	// DefaultSeccompProfile corresponds to -default-seccomp-profile.
+	DefaultSeccompProfile string `json:"defaultSeccompProfile,omitempty"`
+	// DefaultAppArmorProfile corresponds to -default-apparmor-profile.
+	DefaultAppArmorProfile string `json:"defaultAppArmorProfile,omitempty"`
+	// EnforceLinuxNodes corresponds to -enforce-linux-nodes.
+	EnforceLinuxNodes bool `json:"enforceLinuxNodes,omitempty"`
+	// DefaultEnv are the environment variables -default-env-configmap sets for the namespace of the pod.
+	DefaultEnv map[string]string `json:"defaultEnv,omitempty"`
+}
+
+func (o PatchOptions) toPatchConfig() patchConfig {
+	return patchConfig{
+		enableIstioMode:        o.EnableIstioMode,
+		defaultSeccompProfile:  o.DefaultSeccompProfile,
+		defaultAppArmorProfile: o.DefaultAppArmorProfile,
+		enforceLinuxNodes:      o.EnforceLinuxNodes,
+		defaultEnv:             o.DefaultEnv,
+	}
+}
+
+// PatchSparkPod returns the given pod as the webhook patches it for the given SparkApplication with the given
+// options, without modifying the given pod. Pods that are not Spark pods launched by the operator are returned
+// unchanged. It lets the behavior of the webhook be tested without an API server, see package webhooktest.
+func PatchSparkPod(pod *corev1.Pod, app *v1beta1.SparkApplication, options PatchOptions) (*corev1.Pod, error) {
+	if !isSparkPod(pod) {
+		return pod.DeepCopy(), nil
+	}
+	return applyPatch(pod, patchSparkPod(pod, app, options.toPatchConfig()))
+}
+
+// applyPatch returns the given pod with the given patch operations applied to it.
+func applyPatch(pod *corev1.Pod, ops []patchOperation) (*corev1.Pod, error) {
+	podBytes, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.DecodePatch(patchBytes)
+	if err != nil {
+		return nil, err
+	}
+	patchedBytes, err := patch.Apply(podBytes)
+	if err != nil {
+		return nil, err
+	}
+	patched := &corev1.Pod{}
+	if err := json.Unmarshal(patchedBytes, patched); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
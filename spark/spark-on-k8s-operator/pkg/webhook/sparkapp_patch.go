@@ -0,0 +1,87 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+var invalidLabelValueChars = regexp.MustCompile("[^-A-Za-z0-9_.]")
+
+// patchSparkApplication records the given user as the submitter of the SparkApplication in its annotations
+// and labels. Any existing values are overwritten so that users cannot claim to be someone else.
+func patchSparkApplication(app *v1beta1.SparkApplication, username string, groups []string) []patchOperation {
+	annotations := map[string]string{
+		config.SubmittedByAnnotation:       username,
+		config.SubmittedByGroupsAnnotation: strings.Join(groups, ","),
+	}
+	labels := map[string]string{
+		config.SubmittedByLabel: sanitizeLabelValue(username),
+	}
+
+	var ops []patchOperation
+	ops = append(ops, setMapEntries("/metadata/annotations", app.Annotations, annotations)...)
+	ops = append(ops, setMapEntries("/metadata/labels", app.Labels, labels)...)
+	return ops
+}
+
+// unpatchSparkApplication removes any submitter the SparkApplication claims in its annotations and labels, for
+// SparkApplications whose submitter is not known to the webhook.
+func unpatchSparkApplication(app *v1beta1.SparkApplication) []patchOperation {
+	var ops []patchOperation
+	ops = append(ops, removeMapEntries("/metadata/annotations", app.Annotations,
+		config.SubmittedByAnnotation, config.SubmittedByGroupsAnnotation)...)
+	ops = append(ops, removeMapEntries("/metadata/labels", app.Labels, config.SubmittedByLabel)...)
+	return ops
+}
+
+// setMapEntries returns the patch operations that set the given entries in the map at the given path.
+func setMapEntries(path string, existing map[string]string, entries map[string]string) []patchOperation {
+	if len(existing) == 0 {
+		return []patchOperation{{Op: "add", Path: path, Value: entries}}
+	}
+
+	var ops []patchOperation
+	for _, key := range sortedKeys(entries) {
+		ops = append(ops, patchOperation{Op: "add", Path: path + "/" + escapeJSONPointer(key), Value: entries[key]})
+	}
+	return ops
+}
+
+// removeMapEntries returns the patch operations that remove the given keys from the map at the given path.
+func removeMapEntries(path string, existing map[string]string, keys ...string) []patchOperation {
+	var ops []patchOperation
+	for _, key := range keys {
+		if _, ok := existing[key]; ok {
+			ops = append(ops, patchOperation{Op: "remove", Path: path + "/" + escapeJSONPointer(key)})
+		}
+	}
+	return ops
+}
+
+// sanitizeLabelValue turns the given string into a valid label value by replacing invalid characters with
+// dashes and truncating it to the maximum length of a label value.
+func sanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	value = util.TruncateName(value, util.DNS1123LabelMaxLength)
+	return strings.Trim(value, "-_.")
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+// allowedUserPatchPaths are the pod fields the patch annotation of a SparkApplication may modify. Fields that
+// affect the security of the pod or the node it runs on, e.g., the security context, volumes, or host
+// namespaces, are deliberately left out.
+var allowedUserPatchPaths = []string{
+	"/spec/dnsConfig",
+	"/spec/dnsPolicy",
+	"/spec/enableServiceLinks",
+	"/spec/hostAliases",
+	"/spec/priorityClassName",
+	"/spec/readinessGates",
+	"/spec/terminationGracePeriodSeconds",
+	"/spec/tolerations",
+	"/spec/topologySpreadConstraints",
+}
+
+// allowedUserPatchContainerFields are the fields of the containers of the pod the patch annotation of a
+// SparkApplication may modify.
+var allowedUserPatchContainerFields = []string{
+	"env",
+	"lifecycle",
+	"livenessProbe",
+	"readinessProbe",
+	"startupProbe",
+	"terminationMessagePath",
+	"terminationMessagePolicy",
+}
+
+// getUserPatch returns the JSON patch operations in the patch annotation of the SparkApplication, or an error
+// if the annotation is malformed or any of the operations modifies a pod field users may not patch.
+func getUserPatch(app *v1beta1.SparkApplication) ([]patchOperation, error) {
+	value, ok := app.Annotations[config.PatchAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var ops []patchOperation
+	if err := json.Unmarshal([]byte(value), &ops); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation, expected a JSON patch: %v", config.PatchAnnotation, err)
+	}
+	for _, op := range ops {
+		switch op.Op {
+		case "add", "replace", "remove":
+		default:
+			return nil, fmt.Errorf("unsupported operation %q in %s annotation", op.Op, config.PatchAnnotation)
+		}
+		if !isUserPatchPathAllowed(op.Path) {
+			return nil, fmt.Errorf("path %q in %s annotation may not be patched", op.Path, config.PatchAnnotation)
+		}
+	}
+	return ops, nil
+}
+
+func isUserPatchPathAllowed(path string) bool {
+	// Single labels and annotations may be patched, except for those the operator and Spark track pods by.
+	for _, prefix := range []string{"/metadata/labels/", "/metadata/annotations/"} {
+		if strings.HasPrefix(path, prefix) {
+			token := strings.TrimPrefix(path, prefix)
+			if token == "" || strings.Contains(token, "/") {
+				return false
+			}
+			key := unescapeJSONPointer(token)
+			return !strings.HasPrefix(key, config.LabelAnnotationPrefix) &&
+				key != config.SparkRoleLabel && key != config.SparkApplicationSelectorLabel
+		}
+	}
+
+	for _, allowed := range allowedUserPatchPaths {
+		if hasPathPrefix(path, allowed) {
+			return true
+		}
+	}
+
+	// Only fields of existing containers may be patched, e.g., /spec/containers/0/env/-.
+	tokens := strings.SplitN(path, "/", 6)
+	if len(tokens) < 5 || tokens[0] != "" || tokens[1] != "spec" || tokens[2] != "containers" {
+		return false
+	}
+	if _, err := strconv.Atoi(tokens[3]); err != nil {
+		return false
+	}
+	for _, field := range allowedUserPatchContainerFields {
+		if tokens[4] == field {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix tells if the given JSON pointer refers to the field with the given prefix or anything in it.
+func hasPathPrefix(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// unescapeJSONPointer reverses escapeJSONPointer.
+func unescapeJSONPointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~1", "/", -1), "~0", "~", -1)
+}
+
+// canApplyPatch tells if the given patch operations apply to the pod, so that a user patch that does not
+// match the pod does not make the API server reject it.
+func canApplyPatch(pod *corev1.Pod, ops []patchOperation) bool {
+	_, err := applyPatch(pod, ops)
+	return err == nil
+}
@@ -24,6 +24,8 @@ import (
 	"net/http"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -36,6 +38,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	spov1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
 	crinformers "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	crdlisters "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/listers/sparkoperator.k8s.io/v1beta1"
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
@@ -43,10 +47,11 @@ import (
 )
 
 const (
-	webhookName    = "webhook.sparkoperator.k8s.io"
-	serverCertFile = "server-cert.pem"
-	serverKeyFile  = "server-key.pem"
-	caCertFile     = "ca-cert.pem"
+	webhookName          = "webhook.sparkoperator.k8s.io"
+	submitterWebhookName = "submitter.webhook.sparkoperator.k8s.io"
+	serverCertFile       = "server-cert.pem"
+	serverKeyFile        = "server-key.pem"
+	caCertFile           = "ca-cert.pem"
 )
 
 var podResource = metav1.GroupVersionResource{
@@ -55,6 +60,12 @@ var podResource = metav1.GroupVersionResource{
 	Resource: "pods",
 }
 
+var sparkApplicationResource = metav1.GroupVersionResource{
+	Group:    spov1beta1.SchemeGroupVersion.Group,
+	Version:  spov1beta1.SchemeGroupVersion.Version,
+	Resource: "sparkapplications",
+}
+
 // WebHook encapsulates things needed to run the webhook.
 type WebHook struct {
 	clientset         kubernetes.Interface
@@ -63,6 +74,13 @@ type WebHook struct {
 	cert              *certBundle
 	serviceRef        *v1beta1.ServiceReference
 	sparkJobNamespace string
+	configMutex       sync.RWMutex
+	patchConfig       patchConfig
+	defaultEnv        *defaultEnvSource
+	crClient          crclientset.Interface
+	nsDeletionPolicy  NamespaceDeletionPolicy
+	requireSubmitter  bool
+	stopCh            chan struct{}
 }
 
 // New creates a new WebHook instance.
@@ -73,7 +91,12 @@ func New(
 	webhookServiceNamespace string,
 	webhookServiceName string,
 	webhookPort int,
-	jobNamespace string) (*WebHook, error) {
+	jobNamespace string,
+	enableIstioMode bool,
+	defaultSeccompProfile string,
+	defaultAppArmorProfile string,
+	enforceLinuxNodes bool,
+	defaultEnvConfigMap string) (*WebHook, error) {
 	cert := &certBundle{
 		serverCertFile: filepath.Join(certDir, serverCertFile),
 		serverKeyFile:  filepath.Join(certDir, serverKeyFile),
@@ -91,6 +114,20 @@ func New(
 		cert:              cert,
 		serviceRef:        serviceRef,
 		sparkJobNamespace: jobNamespace,
+		patchConfig: patchConfig{
+			enableIstioMode:        enableIstioMode,
+			defaultSeccompProfile:  defaultSeccompProfile,
+			defaultAppArmorProfile: defaultAppArmorProfile,
+			enforceLinuxNodes:      enforceLinuxNodes,
+		},
+		stopCh: make(chan struct{}),
+	}
+	if defaultEnvConfigMap != "" {
+		defaultEnv, err := newDefaultEnvSource(clientset, defaultEnvConfigMap)
+		if err != nil {
+			return nil, err
+		}
+		hook.defaultEnv = defaultEnv
 	}
 
 	mux := http.NewServeMux()
@@ -108,8 +145,18 @@ func New(
 	return hook, nil
 }
 
+// SetRequireSubmitter sets whether SparkApplications must not be admitted without the webhook recording their
+// submitter, which is the case when the operator impersonates submitters. It must be called before the webhook is
+// started, which then registers itself for SparkApplications with the failure policy Fail.
+func (wh *WebHook) SetRequireSubmitter(requireSubmitter bool) {
+	wh.requireSubmitter = requireSubmitter
+}
+
 // Start starts the admission webhook server and registers itself to the API server.
 func (wh *WebHook) Start(webhookConfigName string) error {
+	if wh.defaultEnv != nil {
+		wh.defaultEnv.start(wh.stopCh)
+	}
 	go func() {
 		glog.Info("Starting the Spark pod admission webhook server")
 		if err := wh.server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
@@ -126,6 +173,7 @@ func (wh *WebHook) Stop(webhookConfigName string) error {
 		return err
 	}
 	glog.Infof("Webhook %s deregistered", webhookConfigName)
+	close(wh.stopCh)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	glog.Info("Stopping the Spark pod admission webhook server")
@@ -164,8 +212,14 @@ func (wh *WebHook) serve(w http.ResponseWriter, r *http.Request) {
 	if _, _, err := deserializer.Decode(body, nil, review); err != nil {
 		glog.Error(err)
 		reviewResponse = toAdmissionResponse(err)
+	} else if review.Request.Resource == namespaceResource {
+		reviewResponse = wh.admitNamespaceDeletion(review)
+	} else if review.Request.Resource == sparkApplicationResource {
+		reviewResponse = mutateSparkApplications(review, wh.sparkJobNamespace, wh.getPatchConfig(), wh.clientset)
+	} else if isPropagatedResource(review.Request.Resource) {
+		reviewResponse = wh.mutatePropagatedResource(review)
 	} else {
-		reviewResponse = mutatePods(review, wh.lister, wh.sparkJobNamespace)
+		reviewResponse = mutatePods(review, wh.lister, wh.sparkJobNamespace, wh.getPodPatchConfig(review.Request.Namespace))
 	}
 
 	response := admissionv1beta1.AdmissionReview{}
@@ -188,6 +242,31 @@ func (wh *WebHook) serve(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (wh *WebHook) getPatchConfig() patchConfig {
+	wh.configMutex.RLock()
+	defer wh.configMutex.RUnlock()
+	return wh.patchConfig
+}
+
+// SetPodSecurityDefaults changes the default seccomp and AppArmor profiles of Spark pods, and whether Spark
+// pods are restricted to Linux nodes, for requests admitted from then on.
+func (wh *WebHook) SetPodSecurityDefaults(seccompProfile string, appArmorProfile string, enforceLinuxNodes bool) {
+	wh.configMutex.Lock()
+	defer wh.configMutex.Unlock()
+	wh.patchConfig.defaultSeccompProfile = seccompProfile
+	wh.patchConfig.defaultAppArmorProfile = appArmorProfile
+	wh.patchConfig.enforceLinuxNodes = enforceLinuxNodes
+}
+
+// getPodPatchConfig returns the configuration for patching Spark pods in the given namespace.
+func (wh *WebHook) getPodPatchConfig(namespace string) patchConfig {
+	cfg := wh.getPatchConfig()
+	if wh.defaultEnv != nil {
+		cfg.defaultEnv = wh.defaultEnv.get(namespace)
+	}
+	return cfg
+}
+
 func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 	client := wh.clientset.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
 	existing, getErr := client.Get(webhookConfigName, metav1.GetOptions{})
@@ -195,30 +274,11 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 		return getErr
 	}
 
-	ignorePolicy := v1beta1.Ignore
 	caCert, err := readCertFile(wh.cert.caCertFile)
 	if err != nil {
 		return err
 	}
-	webhook := v1beta1.Webhook{
-		Name: webhookName,
-		Rules: []v1beta1.RuleWithOperations{
-			{
-				Operations: []v1beta1.OperationType{v1beta1.Create, v1beta1.Update},
-				Rule: v1beta1.Rule{
-					APIGroups:   []string{""},
-					APIVersions: []string{"v1"},
-					Resources:   []string{"pods"},
-				},
-			},
-		},
-		ClientConfig: v1beta1.WebhookClientConfig{
-			Service:  wh.serviceRef,
-			CABundle: caCert,
-		},
-		FailurePolicy: &ignorePolicy,
-	}
-	webhooks := []v1beta1.Webhook{webhook}
+	webhooks := buildWebhooks(wh.serviceRef, caCert, wh.admitsNamespaceDeletion(), wh.requireSubmitter)
 
 	if getErr == nil && existing != nil {
 		// Update case.
@@ -246,6 +306,99 @@ func (wh *WebHook) selfRegistration(webhookConfigName string) error {
 	return nil
 }
 
+// NewWebhookConfiguration returns the MutatingWebhookConfiguration with the given name registering the webhook
+// served by the given Service, whose certificate is signed by the given CA certificate.
+func NewWebhookConfiguration(
+	name string,
+	serviceNamespace string,
+	serviceName string,
+	caCert []byte) *v1beta1.MutatingWebhookConfiguration {
+	path := "/webhook"
+	serviceRef := &v1beta1.ServiceReference{
+		Namespace: serviceNamespace,
+		Name:      serviceName,
+		Path:      &path,
+	}
+	return &v1beta1.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Kind:       "MutatingWebhookConfiguration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Webhooks: buildWebhooks(serviceRef, caCert, false, false),
+	}
+}
+
+func buildWebhooks(
+	serviceRef *v1beta1.ServiceReference,
+	caCert []byte,
+	namespaceDeletion bool,
+	requireSubmitter bool) []v1beta1.Webhook {
+	ignorePolicy := v1beta1.Ignore
+	clientConfig := v1beta1.WebhookClientConfig{
+		Service:  serviceRef,
+		CABundle: caCert,
+	}
+	sparkApplicationRule := v1beta1.RuleWithOperations{
+		Operations: []v1beta1.OperationType{v1beta1.Create, v1beta1.Update},
+		Rule: v1beta1.Rule{
+			APIGroups:   []string{sparkApplicationResource.Group},
+			APIVersions: []string{sparkApplicationResource.Version},
+			Resources:   []string{sparkApplicationResource.Resource},
+		},
+	}
+	webhook := v1beta1.Webhook{
+		Name: webhookName,
+		Rules: []v1beta1.RuleWithOperations{
+			{
+				Operations: []v1beta1.OperationType{v1beta1.Create, v1beta1.Update},
+				Rule: v1beta1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				},
+			},
+			{
+				Operations: []v1beta1.OperationType{v1beta1.Create},
+				Rule: v1beta1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"services", "configmaps", "persistentvolumeclaims"},
+				},
+			},
+		},
+		ClientConfig:  clientConfig,
+		FailurePolicy: &ignorePolicy,
+	}
+	if namespaceDeletion {
+		webhook.Rules = append(webhook.Rules, v1beta1.RuleWithOperations{
+			Operations: []v1beta1.OperationType{v1beta1.Delete},
+			Rule: v1beta1.Rule{
+				APIGroups:   []string{namespaceResource.Group},
+				APIVersions: []string{namespaceResource.Version},
+				Resources:   []string{namespaceResource.Resource},
+			},
+		})
+	}
+	if !requireSubmitter {
+		webhook.Rules = append(webhook.Rules, sparkApplicationRule)
+		return []v1beta1.Webhook{webhook}
+	}
+
+	// SparkApplications are admitted by a separate webhook failing closed, so that none is created or updated with
+	// a submitter not recorded by the webhook while it is unavailable.
+	failPolicy := v1beta1.Fail
+	submitterWebhook := v1beta1.Webhook{
+		Name:          submitterWebhookName,
+		Rules:         []v1beta1.RuleWithOperations{sparkApplicationRule},
+		ClientConfig:  clientConfig,
+		FailurePolicy: &failPolicy,
+	}
+	return []v1beta1.Webhook{webhook, submitterWebhook}
+}
+
 func (wh *WebHook) selfDeregistration(webhookConfigName string) error {
 	client := wh.clientset.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
 	return client.Delete(webhookConfigName, metav1.NewDeleteOptions(0))
@@ -254,7 +407,8 @@ func (wh *WebHook) selfDeregistration(webhookConfigName string) error {
 func mutatePods(
 	review *admissionv1beta1.AdmissionReview,
 	lister crdlisters.SparkApplicationLister,
-	sparkJobNs string) *admissionv1beta1.AdmissionResponse {
+	sparkJobNs string,
+	cfg patchConfig) *admissionv1beta1.AdmissionResponse {
 	if review.Request.Resource != podResource {
 		glog.Errorf("expected resource to be %s, got %s", podResource, review.Request.Resource)
 		return nil
@@ -285,7 +439,13 @@ func mutatePods(
 		return toAdmissionResponse(err)
 	}
 
-	patchOps := patchSparkPod(pod, app)
+	if review.Request.Operation == admissionv1beta1.Create && exceedsExecutorLimit(pod, app) {
+		limit, _ := util.GetExecutorLimit(app)
+		return toDeniedResponse(fmt.Errorf("SparkApplication %s/%s may run at most %d executors after %d idle "+
+			"executors were reclaimed", app.Namespace, app.Name, limit, app.Status.ReclaimedExecutors))
+	}
+
+	patchOps := patchSparkPod(pod, app, cfg)
 	if len(patchOps) > 0 {
 		glog.V(2).Infof("Pod %s in namespace %s is subject to mutation", pod.GetObjectMeta().GetName(), review.Request.Namespace)
 		patchBytes, err := json.Marshal(patchOps)
@@ -301,6 +461,94 @@ func mutatePods(
 	return response
 }
 
+// exceedsExecutorLimit tells if admitting the given pod would let the given app run more executors than it may
+// for the rest of its current run, which is limited once the operator reclaims idle executors of the run.
+func exceedsExecutorLimit(pod *corev1.Pod, app *spov1beta1.SparkApplication) bool {
+	limit, limited := util.GetExecutorLimit(app)
+	if !limited || !util.IsExecutorPod(pod) {
+		return false
+	}
+	var active int32
+	for name, state := range app.Status.ExecutorState {
+		if name != pod.Name && (state == spov1beta1.ExecutorPendingState || state == spov1beta1.ExecutorRunningState) {
+			active++
+		}
+	}
+	return active >= limit
+}
+
+func mutateSparkApplications(
+	review *admissionv1beta1.AdmissionReview,
+	sparkJobNs string,
+	cfg patchConfig,
+	clientset kubernetes.Interface) *admissionv1beta1.AdmissionResponse {
+	response := &admissionv1beta1.AdmissionResponse{Allowed: true}
+	if !inSparkJobNamespace(review.Request.Namespace, sparkJobNs) {
+		return response
+	}
+
+	app := &spov1beta1.SparkApplication{}
+	if err := json.Unmarshal(review.Request.Object.Raw, app); err != nil {
+		glog.Errorf("failed to unmarshal a SparkApplication from the raw data in the admission request: %v", err)
+		return toDeniedResponse(err)
+	}
+
+	if _, err := getUserPatch(app); err != nil {
+		glog.V(2).Infof("SparkApplication %s/%s is rejected: %v", review.Request.Namespace, app.Name, err)
+		return toDeniedResponse(err)
+	}
+	if cfg.enforceLinuxNodes {
+		if err := validateNodeOS(app); err != nil {
+			glog.V(2).Infof("SparkApplication %s/%s is rejected: %v", review.Request.Namespace, app.Name, err)
+			return toDeniedResponse(err)
+		}
+	}
+	var oldApp *spov1beta1.SparkApplication
+	if review.Request.Operation == admissionv1beta1.Update {
+		oldApp = &spov1beta1.SparkApplication{}
+		if err := json.Unmarshal(review.Request.OldObject.Raw, oldApp); err != nil {
+			glog.Errorf("failed to unmarshal a SparkApplication from the raw data in the admission request: %v", err)
+			return toDeniedResponse(err)
+		}
+	}
+	if err := validateNodeFeatures(app, oldApp, clientset); err != nil {
+		glog.V(2).Infof("SparkApplication %s/%s is rejected: %v", review.Request.Namespace, app.Name, err)
+		return toDeniedResponse(err)
+	}
+
+	username := review.Request.UserInfo.Username
+	groups := review.Request.UserInfo.Groups
+	// Keep the user who created the SparkApplication as the submitter on updates. SparkApplications created
+	// before the webhook was enabled have no known submitter, as the user updating them is not necessarily the
+	// one who created them, so any submitter they claim is removed instead.
+	if oldApp != nil {
+		username = oldApp.Annotations[config.SubmittedByAnnotation]
+		groups = nil
+		if oldGroups := oldApp.Annotations[config.SubmittedByGroupsAnnotation]; oldGroups != "" {
+			groups = strings.Split(oldGroups, ",")
+		}
+	}
+	var patchOps []patchOperation
+	if username != "" {
+		patchOps = patchSparkApplication(app, username, groups)
+	} else {
+		patchOps = unpatchSparkApplication(app)
+	}
+	if len(patchOps) == 0 {
+		return response
+	}
+
+	patchBytes, err := json.Marshal(patchOps)
+	if err != nil {
+		glog.Errorf("failed to marshal patch operations %v: %v", patchOps, err)
+		return toDeniedResponse(err)
+	}
+	response.Patch = patchBytes
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	response.PatchType = &patchType
+	return response
+}
+
 func toAdmissionResponse(err error) *admissionv1beta1.AdmissionResponse {
 	return &admissionv1beta1.AdmissionResponse{
 		Allowed: true,
@@ -311,6 +559,18 @@ func toAdmissionResponse(err error) *admissionv1beta1.AdmissionResponse {
 	}
 }
 
+func toDeniedResponse(err error) *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		},
+	}
+}
+
 func inSparkJobNamespace(podNs string, sparkJobNamespace string) bool {
 	if sparkJobNamespace == apiv1.NamespaceAll {
 		return true
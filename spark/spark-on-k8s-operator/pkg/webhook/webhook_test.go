@@ -17,16 +17,23 @@ limitations under the License.
 package webhook
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"testing"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/stretchr/testify/assert"
 
 	"k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 
 	spov1beta1 "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
@@ -73,7 +80,7 @@ func TestMutatePod(t *testing.T) {
 			Namespace: "default",
 		},
 	}
-	response := mutatePods(review, lister, "default")
+	response := mutatePods(review, lister, "default", patchConfig{})
 	assert.True(t, response.Allowed)
 
 	// 2. Test processing Spark pod with only one patch: adding an OwnerReference.
@@ -95,7 +102,7 @@ func TestMutatePod(t *testing.T) {
 		t.Error(err)
 	}
 	review.Request.Object.Raw = podBytes
-	response = mutatePods(review, lister, "default")
+	response = mutatePods(review, lister, "default", patchConfig{})
 	assert.True(t, response.Allowed)
 	assert.Equal(t, v1beta1.PatchTypeJSONPatch, *response.PatchType)
 	assert.True(t, len(response.Patch) > 0)
@@ -168,7 +175,7 @@ func TestMutatePod(t *testing.T) {
 		t.Error(err)
 	}
 	review.Request.Object.Raw = podBytes
-	response = mutatePods(review, lister, "default")
+	response = mutatePods(review, lister, "default", patchConfig{})
 	assert.True(t, response.Allowed)
 	assert.Equal(t, v1beta1.PatchTypeJSONPatch, *response.PatchType)
 	assert.True(t, len(response.Patch) > 0)
@@ -180,3 +187,505 @@ func TestMutatePod(t *testing.T) {
 func serializePod(pod *corev1.Pod) ([]byte, error) {
 	return json.Marshal(pod)
 }
+
+func TestMutateSparkApplication(t *testing.T) {
+	app := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-app",
+			Namespace: "default",
+		},
+	}
+	appBytes, err := json.Marshal(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Resource:  sparkApplicationResource,
+			Operation: v1beta1.Create,
+			Object: runtime.RawExtension{
+				Raw: appBytes,
+			},
+			Namespace: "default",
+			UserInfo: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:default:pipeline",
+				Groups:   []string{"system:serviceaccounts"},
+			},
+		},
+	}
+
+	// 1. The submitter is recorded on creation.
+	response := mutateSparkApplications(review, "default", patchConfig{}, nil)
+	assert.True(t, response.Allowed)
+	modifiedApp := applyResponsePatch(t, appBytes, response)
+	assert.Equal(t, "system:serviceaccount:default:pipeline", modifiedApp.Annotations[config.SubmittedByAnnotation])
+	assert.Equal(t, "system:serviceaccounts", modifiedApp.Annotations[config.SubmittedByGroupsAnnotation])
+	assert.Equal(t, "system-serviceaccount-default-pipeline", modifiedApp.Labels[config.SubmittedByLabel])
+
+	// 2. The original submitter is kept on updates by other users.
+	oldBytes, err := json.Marshal(modifiedApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modifiedApp.Annotations[config.SubmittedByAnnotation] = "mallory"
+	newBytes, err := json.Marshal(modifiedApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review.Request.Operation = v1beta1.Update
+	review.Request.OldObject.Raw = oldBytes
+	review.Request.Object.Raw = newBytes
+	review.Request.UserInfo = authenticationv1.UserInfo{Username: "mallory"}
+	response = mutateSparkApplications(review, "default", patchConfig{}, nil)
+	modifiedApp = applyResponsePatch(t, newBytes, response)
+	assert.Equal(t, "system:serviceaccount:default:pipeline", modifiedApp.Annotations[config.SubmittedByAnnotation])
+
+	// 3. SparkApplications without a recorded submitter are not patched on updates.
+	review.Request.OldObject.Raw = appBytes
+	review.Request.Object.Raw = appBytes
+	response = mutateSparkApplications(review, "default", patchConfig{}, nil)
+	assert.True(t, response.Allowed)
+	assert.Nil(t, response.Patch)
+
+	// 4. A submitter claimed on updates of SparkApplications without a recorded submitter is removed.
+	forgedApp := app.DeepCopy()
+	forgedApp.Annotations = map[string]string{
+		config.SubmittedByAnnotation:       "mallory",
+		config.SubmittedByGroupsAnnotation: "system:masters",
+		"foo":                              "bar",
+	}
+	forgedApp.Labels = map[string]string{config.SubmittedByLabel: "mallory"}
+	forgedBytes, err := json.Marshal(forgedApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review.Request.Object.Raw = forgedBytes
+	response = mutateSparkApplications(review, "default", patchConfig{}, nil)
+	assert.True(t, response.Allowed)
+	modifiedApp = applyResponsePatch(t, forgedBytes, response)
+	assert.Equal(t, map[string]string{"foo": "bar"}, modifiedApp.Annotations)
+	assert.Empty(t, modifiedApp.Labels)
+}
+
+func TestBuildWebhooks(t *testing.T) {
+	webhooks := buildWebhooks(&admissionregistrationv1beta1.ServiceReference{}, nil, false, false)
+	assert.Equal(t, 1, len(webhooks))
+	assert.Equal(t, admissionregistrationv1beta1.Ignore, *webhooks[0].FailurePolicy)
+
+	// SparkApplications are admitted by a webhook failing closed when their submitter is required.
+	webhooks = buildWebhooks(&admissionregistrationv1beta1.ServiceReference{}, nil, false, true)
+	assert.Equal(t, 2, len(webhooks))
+	assert.Equal(t, admissionregistrationv1beta1.Ignore, *webhooks[0].FailurePolicy)
+	for _, rule := range webhooks[0].Rules {
+		assert.NotContains(t, rule.Resources, sparkApplicationResource.Resource)
+	}
+	assert.Equal(t, submitterWebhookName, webhooks[1].Name)
+	assert.Equal(t, admissionregistrationv1beta1.Fail, *webhooks[1].FailurePolicy)
+	assert.Equal(t, []string{sparkApplicationResource.Resource}, webhooks[1].Rules[0].Resources)
+}
+
+func TestMutateSparkApplication_NodeOS(t *testing.T) {
+	app := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-app",
+			Namespace: "default",
+		},
+		Spec: spov1beta1.SparkApplicationSpec{
+			NodeSelector: map[string]string{config.NodeOSLabel: config.LinuxNodeOS},
+		},
+	}
+	testFn := func(app *spov1beta1.SparkApplication, cfg patchConfig, expectAllowed bool) {
+		appBytes, err := json.Marshal(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		review := &v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Resource:  sparkApplicationResource,
+				Operation: v1beta1.Create,
+				Object: runtime.RawExtension{
+					Raw: appBytes,
+				},
+				Namespace: "default",
+			},
+		}
+		response := mutateSparkApplications(review, "default", cfg, nil)
+		assert.Equal(t, expectAllowed, response.Allowed)
+	}
+
+	enforced := patchConfig{enforceLinuxNodes: true}
+	testFn(app, enforced, true)
+
+	windows := app.DeepCopy()
+	windows.Spec.NodeSelector[config.NodeOSLabel] = "windows"
+	testFn(windows, enforced, false)
+	testFn(windows, patchConfig{}, true)
+
+	windows = app.DeepCopy()
+	windows.Spec.SparkConf = map[string]string{config.SparkNodeSelectorKeyPrefix + config.BetaNodeOSLabel: "windows"}
+	testFn(windows, enforced, false)
+
+	windows = app.DeepCopy()
+	windows.Spec.Executor.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: config.NodeOSLabel, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"linux"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	testFn(windows, enforced, false)
+	windows.Spec.Executor.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].
+		MatchExpressions[0].Operator = corev1.NodeSelectorOpIn
+	testFn(windows, enforced, true)
+}
+
+func TestMutateSparkApplication_NodeFeatures(t *testing.T) {
+	app := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-app",
+			Namespace: "default",
+		},
+		Spec: spov1beta1.SparkApplicationSpec{
+			Executor: spov1beta1.ExecutorSpec{
+				SparkPodSpec: spov1beta1.SparkPodSpec{RequireNodeFeatures: []string{"local-nvme"}},
+			},
+		},
+	}
+	clientset := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{"feature.node.kubernetes.io/local-nvme": "true"},
+		},
+	})
+	testFn := func(app, oldApp *spov1beta1.SparkApplication, expectAllowed bool) {
+		appBytes, err := json.Marshal(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		review := &v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Resource:  sparkApplicationResource,
+				Operation: v1beta1.Create,
+				Object:    runtime.RawExtension{Raw: appBytes},
+				Namespace: "default",
+			},
+		}
+		if oldApp != nil {
+			oldBytes, err := json.Marshal(oldApp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			review.Request.Operation = v1beta1.Update
+			review.Request.OldObject = runtime.RawExtension{Raw: oldBytes}
+		}
+		response := mutateSparkApplications(review, "default", patchConfig{}, clientset)
+		assert.Equal(t, expectAllowed, response.Allowed)
+	}
+
+	testFn(app, nil, true)
+
+	missing := app.DeepCopy()
+	missing.Spec.Executor.RequireNodeFeatures = []string{"local-nvme", "network-25g"}
+	testFn(missing, nil, false)
+	// Applications whose node features have not changed are admitted, even if the nodes have gone away.
+	testFn(missing, missing, true)
+	testFn(missing, app, false)
+
+	invalid := app.DeepCopy()
+	invalid.Spec.Driver.RequireNodeFeatures = []string{"local nvme"}
+	testFn(invalid, nil, false)
+}
+
+func TestMutateSparkApplication_UserPatch(t *testing.T) {
+	app := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-app",
+			Namespace:   "default",
+			Annotations: map[string]string{config.PatchAnnotation: `[{"op": "add", "path": "/spec/hostPID", "value": true}]`},
+		},
+	}
+	appBytes, err := json.Marshal(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Resource:  sparkApplicationResource,
+			Operation: v1beta1.Create,
+			Object: runtime.RawExtension{
+				Raw: appBytes,
+			},
+			Namespace: "default",
+		},
+	}
+	response := mutateSparkApplications(review, "default", patchConfig{}, nil)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, "/spec/hostPID")
+}
+
+func TestDefaultEnvSource(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	source, err := newDefaultEnvSource(clientset, "spark-operator/default-env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.informerFactory.Core().V1().Namespaces().Informer().GetIndexer().Add(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "opted-out",
+			Annotations: map[string]string{config.InjectDefaultEnvAnnotation: "false"},
+		},
+	})
+	assert.Nil(t, source.get("default"))
+
+	source.informerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-env", Namespace: "spark-operator"},
+		Data:       map[string]string{"AWS_REGION": "us-east-1"},
+	})
+	assert.Equal(t, map[string]string{"AWS_REGION": "us-east-1"}, source.get("default"))
+	assert.Nil(t, source.get("opted-out"))
+
+	_, err = newDefaultEnvSource(clientset, "default-env")
+	assert.NotNil(t, err)
+}
+
+func applyResponsePatch(t *testing.T, original []byte, response *v1beta1.AdmissionResponse) *spov1beta1.SparkApplication {
+	patch, err := jsonpatch.DecodePatch(response.Patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, err := patch.Apply(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &spov1beta1.SparkApplication{}
+	if err := json.Unmarshal(patched, app); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestGenerateCerts(t *testing.T) {
+	certs, err := GenerateCerts("spark-operator", "spark-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tls.X509KeyPair(certs[serverCertFile], certs[serverKeyFile])
+	assert.Nil(t, err)
+
+	roots := x509.NewCertPool()
+	assert.True(t, roots.AppendCertsFromPEM(GetCACert(certs)))
+	block, _ := pem.Decode(certs[serverCertFile])
+	serverCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = serverCert.Verify(x509.VerifyOptions{DNSName: "spark-webhook.spark-operator.svc", Roots: roots})
+	assert.Nil(t, err)
+}
+
+func TestAdmitNamespaceDeletion(t *testing.T) {
+	newApp := func(name string, state spov1beta1.ApplicationStateType) *spov1beta1.SparkApplication {
+		return &spov1beta1.SparkApplication{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team"},
+			Status:     spov1beta1.SparkApplicationStatus{AppState: spov1beta1.ApplicationState{State: state}},
+		}
+	}
+	apps := []*spov1beta1.SparkApplication{
+		newApp("running", spov1beta1.RunningState),
+		newApp("completed", spov1beta1.CompletedState),
+		newApp("new", spov1beta1.NewState),
+	}
+	crdClient := crdclientfake.NewSimpleClientset()
+	informer := crdinformers.NewSharedInformerFactory(crdClient, 0*time.Second).Sparkoperator().V1beta1().SparkApplications()
+	for _, app := range apps {
+		informer.Informer().GetIndexer().Add(app)
+		crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+	}
+	remaining := func() []string {
+		var names []string
+		for _, app := range apps {
+			if _, err := crdClient.SparkoperatorV1beta1().SparkApplications(app.Namespace).Get(app.Name,
+				metav1.GetOptions{}); err == nil {
+				names = append(names, app.Name)
+			}
+		}
+		return names
+	}
+	wh := &WebHook{lister: informer.Lister()}
+	review := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Resource:  namespaceResource,
+			Operation: v1beta1.Delete,
+			Name:      "team",
+		},
+	}
+
+	// 1. Blocking leaves the applications alone.
+	wh.SetNamespaceDeletionPolicy(NamespaceDeletionBlock, crdClient)
+	response := wh.admitNamespaceDeletion(review)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, "2 running SparkApplications (new, running)")
+	assert.Equal(t, []string{"running", "completed", "new"}, remaining())
+
+	// 2. Namespaces without running applications can be deleted.
+	review.Request.Name = "other"
+	response = wh.admitNamespaceDeletion(review)
+	assert.True(t, response.Allowed)
+
+	// 3. Draining deletes the running applications.
+	review.Request.Name = "team"
+	wh.SetNamespaceDeletionPolicy(NamespaceDeletionDrain, crdClient)
+	response = wh.admitNamespaceDeletion(review)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, "being stopped")
+	assert.Equal(t, []string{"completed"}, remaining())
+
+	// 4. Other operations are always allowed.
+	review.Request.Operation = v1beta1.Update
+	assert.True(t, wh.admitNamespaceDeletion(review).Allowed)
+}
+
+func TestMutatePropagatedResource(t *testing.T) {
+	app := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-app",
+			Namespace:   "default",
+			Labels:      map[string]string{"cost-center": "ads"},
+			Annotations: map[string]string{"data-classification": "pii"},
+		},
+	}
+	driverPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-app-driver",
+			Namespace: "default",
+			Labels: map[string]string{
+				config.SparkRoleLabel:               config.SparkDriverRole,
+				config.LaunchedBySparkOperatorLabel: "true",
+				config.SparkAppNameLabel:            app.Name,
+			},
+		},
+	}
+	otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	informer := crdinformers.NewSharedInformerFactory(crdclientfake.NewSimpleClientset(), 0*time.Second).Sparkoperator().V1beta1().SparkApplications()
+	informer.Informer().GetIndexer().Add(app)
+	wh := &WebHook{clientset: fake.NewSimpleClientset(driverPod, otherPod), lister: informer.Lister()}
+	wh.SetPropagatedMetadata([]string{"cost-center"}, []string{"data-classification"})
+
+	mutate := func(configMap *corev1.ConfigMap) *corev1.ConfigMap {
+		raw, err := json.Marshal(configMap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		review := &v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+				Operation: v1beta1.Create,
+				Namespace: "default",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+		response := wh.mutatePropagatedResource(review)
+		assert.True(t, response.Allowed)
+		if response.Patch == nil {
+			return configMap
+		}
+		patch, err := jsonpatch.DecodePatch(response.Patch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		patched, err := patch.Apply(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := &corev1.ConfigMap{}
+		if err := json.Unmarshal(patched, result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	podOwner := func(name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "v1", Kind: "Pod", Name: name}}
+	}
+
+	// 1. ConfigMaps Spark creates for executors are owned by the driver pod.
+	result := mutate(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:            "spark-exec-conf",
+		Namespace:       "default",
+		Labels:          map[string]string{"cost-center": "infra"},
+		OwnerReferences: podOwner(driverPod.Name),
+	}})
+	assert.Equal(t, map[string]string{"cost-center": "infra"}, result.Labels)
+	assert.Equal(t, map[string]string{"data-classification": "pii"}, result.Annotations)
+
+	// 2. Resources owned by the SparkApplication.
+	result = mutate(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "spark-app-start-gate",
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: spov1beta1.SchemeGroupVersion.String(),
+			Kind:       "SparkApplication",
+			Name:       app.Name,
+		}},
+	}})
+	assert.Equal(t, map[string]string{"cost-center": "ads"}, result.Labels)
+
+	// 3. Resources owned by other pods are left alone.
+	result = mutate(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:            "other",
+		Namespace:       "default",
+		OwnerReferences: podOwner(otherPod.Name),
+	}})
+	assert.Nil(t, result.Labels)
+	assert.Nil(t, result.Annotations)
+}
+
+func TestParseNamespaceDeletionPolicy(t *testing.T) {
+	policy, err := ParseNamespaceDeletionPolicy("Drain")
+	assert.NoError(t, err)
+	assert.Equal(t, NamespaceDeletionDrain, policy)
+	_, err = ParseNamespaceDeletionPolicy("drain")
+	assert.Error(t, err)
+}
+
+func TestExceedsExecutorLimit(t *testing.T) {
+	instances := int32(3)
+	app := &spov1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-app", Namespace: "default"},
+		Spec: spov1beta1.SparkApplicationSpec{
+			Executor: spov1beta1.ExecutorSpec{Instances: &instances},
+		},
+		Status: spov1beta1.SparkApplicationStatus{
+			ExecutorState: map[string]spov1beta1.ExecutorState{
+				"exec-1": spov1beta1.ExecutorRunningState,
+				"exec-2": spov1beta1.ExecutorPendingState,
+				"exec-3": spov1beta1.ExecutorCompletedState,
+			},
+		},
+	}
+	executor := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "exec-4",
+			Labels: map[string]string{config.SparkRoleLabel: config.SparkExecutorRole},
+		},
+	}
+
+	// Executors are not limited unless idle executors have been reclaimed.
+	assert.False(t, exceedsExecutorLimit(executor, app))
+
+	app.Status.ReclaimedExecutors = 1
+	assert.True(t, exceedsExecutorLimit(executor, app))
+	app.Status.ExecutorState["exec-2"] = spov1beta1.ExecutorFailedState
+	assert.False(t, exceedsExecutorLimit(executor, app))
+
+	driver := executor.DeepCopy()
+	driver.Labels[config.SparkRoleLabel] = config.SparkDriverRole
+	app.Status.ReclaimedExecutors = 3
+	assert.False(t, exceedsExecutorLimit(driver, app))
+	assert.True(t, exceedsExecutorLimit(executor, app))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhooktest renders Spark pods as the webhook of the operator patches them, and compares them with
+// golden files. It is used to test the webhook itself, and lets platform teams test their own mutating webhooks
+// and pod templates against the behavior of the operator without a cluster.
+//
+// A fixture is a YAML file holding a SparkApplication, the driver and executor pods Spark creates for it before
+// they are patched, and the options the webhook patches them with, e.g.:
+//
+//	options:
+//	  enforceLinuxNodes: true
+//	application:
+//	  apiVersion: sparkoperator.k8s.io/v1beta1
+//	  kind: SparkApplication
+//	  ...
+//	pods:
+//	- apiVersion: v1
+//	  kind: Pod
+//	  ...
+//
+// RunGoldenTests renders every fixture in a directory, applies the given mutators to the patched pods, and
+// compares the result with the golden file of the fixture. Golden files are written instead if the tests are run
+// with -update-golden.
+package webhooktest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/webhook"
+)
+
+// GoldenFileSuffix is the suffix of golden files, which replaces the .yaml extension of their fixtures.
+const GoldenFileSuffix = ".golden.yaml"
+
+var update = flag.Bool("update-golden", false, "Whether to write golden files instead of comparing with them.")
+
+// Fixture is a SparkApplication along with its driver and executor pods before they are patched.
+type Fixture struct {
+	// Options are the operator-level settings the pods are patched with.
+	Options webhook.PatchOptions `json:"options,omitempty"`
+	// Application is the SparkApplication the pods belong to.
+	Application *v1beta1.SparkApplication `json:"application"`
+	// Pods are the driver and executor pods as Spark creates them.
+	Pods []corev1.Pod `json:"pods"`
+}
+
+// Mutator mutates a pod the webhook of the operator has patched, like another mutating webhook that is called
+// after it does.
+type Mutator func(pod *corev1.Pod, app *v1beta1.SparkApplication) error
+
+// LoadFixture reads the fixture in the given YAML file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	if err := yaml.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %v", path, err)
+	}
+	if fixture.Application == nil {
+		return nil, fmt.Errorf("fixture %s has no application", path)
+	}
+	return fixture, nil
+}
+
+// Render returns the pods of the given fixture as the webhook patches them, followed by the given mutators in
+// order.
+func Render(fixture *Fixture, mutators ...Mutator) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	for i := range fixture.Pods {
+		pod, err := webhook.PatchSparkPod(&fixture.Pods[i], fixture.Application, fixture.Options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to patch pod %s: %v", fixture.Pods[i].Name, err)
+		}
+		for _, mutate := range mutators {
+			if err := mutate(pod, fixture.Application); err != nil {
+				return nil, fmt.Errorf("failed to mutate pod %s: %v", pod.Name, err)
+			}
+		}
+		pods = append(pods, *pod)
+	}
+	return pods, nil
+}
+
+// CompareGolden compares the YAML of the given pods with the given golden file, and fails the test with a diff
+// if they differ. It writes the golden file instead if the tests are run with -update-golden.
+func CompareGolden(t *testing.T, goldenPath string, pods []corev1.Pod) {
+	t.Helper()
+	actual, err := yaml.Marshal(pods)
+	if err != nil {
+		t.Fatalf("failed to marshal the pods: %v", err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(goldenPath, actual, 0644); err != nil {
+			t.Fatalf("failed to write golden file %s: %v", goldenPath, err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s, run the tests with -update-golden to write it: %v", goldenPath, err)
+	}
+	if string(expected) == string(actual) {
+		return
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: goldenPath,
+		ToFile:   "rendered",
+		Context:  3,
+	})
+	if err != nil {
+		t.Fatalf("failed to diff with golden file %s: %v", goldenPath, err)
+	}
+	t.Errorf("the rendered pods differ from golden file %s, run the tests with -update-golden to update it:\n%s",
+		goldenPath, diff)
+}
+
+// RunGoldenTests renders every fixture in the given directory, i.e., every file with the .yaml extension that is
+// not a golden file, in a subtest named after the fixture, and compares the pods with the golden file of the
+// fixture.
+func RunGoldenTests(t *testing.T, dir string, mutators ...Mutator) {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, path := range paths {
+		if strings.HasSuffix(path, GoldenFileSuffix) {
+			continue
+		}
+		found = true
+		path := path
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			fixture, err := LoadFixture(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pods, err := Render(fixture, mutators...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			CompareGolden(t, strings.TrimSuffix(path, ".yaml")+GoldenFileSuffix, pods)
+		})
+	}
+	if !found {
+		t.Fatalf("no fixtures found in %s", dir)
+	}
+}
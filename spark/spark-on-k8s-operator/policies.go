@@ -0,0 +1,51 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/policy"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
+)
+
+// runPolicies runs the policies subcommand with the given command-line arguments, which prints the selected
+// validation rules of SparkApplications as ValidatingAdmissionPolicies and bindings to apply with kubectl.
+func runPolicies(args []string) error {
+	flags := flag.NewFlagSet("policies", flag.ExitOnError)
+	maxExecutors := flags.Int("max-executors", 0, "The maximum number of executors of a SparkApplication. No limit if not positive.")
+	noHostPath := flags.Bool("no-host-path", false, "Whether SparkApplications may not mount hostPath volumes.")
+	requireLinuxNodes := flags.Bool("require-linux-nodes", false, "Whether the node selectors of SparkApplications must only select Linux nodes.")
+	var requiredLabels util.ArrayFlags
+	flags.Var(&requiredLabels, "required-label", "A label every SparkApplication must have on creation. May be repeated.")
+	var validationActions util.ArrayFlags
+	flags.Var(&validationActions, "validation-action", "An action of the bindings on a violation: Deny, Warn, or Audit. May be repeated. Defaults to Deny.")
+	flags.Parse(args)
+
+	objects, err := policy.Objects(policy.Config{
+		MaxExecutors:      int32(*maxExecutors),
+		NoHostPath:        *noHostPath,
+		RequiredLabels:    requiredLabels,
+		RequireLinuxNodes: *requireLinuxNodes,
+		ValidationActions: validationActions,
+	})
+	if err != nil {
+		return err
+	}
+	return policy.Write(os.Stdout, objects)
+}
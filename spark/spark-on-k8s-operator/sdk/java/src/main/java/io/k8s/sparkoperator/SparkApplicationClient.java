@@ -0,0 +1,131 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io.k8s.sparkoperator;
+
+import io.k8s.sparkoperator.models.V1beta1DriverInfo;
+import io.k8s.sparkoperator.models.V1beta1SparkApplication;
+import io.kubernetes.client.ApiException;
+import io.kubernetes.client.apis.CoreV1Api;
+import io.kubernetes.client.apis.CustomObjectsApi;
+import io.kubernetes.client.models.V1DeleteOptions;
+import io.kubernetes.client.util.Config;
+import java.io.IOException;
+import java.util.concurrent.TimeUnit;
+import java.util.concurrent.TimeoutException;
+
+/**
+ * Submits SparkApplications, waits for them, and reads their driver logs. The models of the custom resources
+ * are generated from the OpenAPI definitions of the operator, while the requests go through the Kubernetes
+ * client.
+ */
+public class SparkApplicationClient {
+  public static final String GROUP = "sparkoperator.k8s.io";
+  public static final String VERSION = "v1beta1";
+  public static final String PLURAL = "sparkapplications";
+  public static final String DRIVER_CONTAINER = "spark-kubernetes-driver";
+
+  private final CustomObjectsApi customApi;
+  private final CoreV1Api coreApi;
+  private final JSON json = new JSON();
+
+  /** Creates a client with the default configuration of the Kubernetes client. */
+  public SparkApplicationClient() throws IOException {
+    this(Config.defaultClient());
+  }
+
+  /** Creates a client with the given Kubernetes client. */
+  public SparkApplicationClient(io.kubernetes.client.ApiClient apiClient) {
+    this.customApi = new CustomObjectsApi(apiClient);
+    this.coreApi = new CoreV1Api(apiClient);
+  }
+
+  /** Creates the given application in its namespace and returns the created application. */
+  public V1beta1SparkApplication submit(V1beta1SparkApplication app) throws ApiException {
+    if (app.getApiVersion() == null) {
+      app.setApiVersion(GROUP + "/" + VERSION);
+    }
+    if (app.getKind() == null) {
+      app.setKind("SparkApplication");
+    }
+    String namespace = app.getMetadata().getNamespace() != null ? app.getMetadata().getNamespace() : "default";
+    Object created = customApi.createNamespacedCustomObject(GROUP, VERSION, namespace, PLURAL, toMap(app), null);
+    return toModel(created);
+  }
+
+  /** Returns the application with the given namespace and name. */
+  public V1beta1SparkApplication get(String namespace, String name) throws ApiException {
+    return toModel(customApi.getNamespacedCustomObject(GROUP, VERSION, namespace, PLURAL, name));
+  }
+
+  /** Deletes the application with the given namespace and name along with its driver and executors. */
+  public void delete(String namespace, String name) throws ApiException {
+    customApi.deleteNamespacedCustomObject(
+        GROUP, VERSION, namespace, PLURAL, name, new V1DeleteOptions(), null, null, null);
+  }
+
+  /**
+   * Waits until the application with the given namespace and name has completed or failed, and returns it.
+   * Applications with the restart policy Always never terminate.
+   *
+   * @throws TimeoutException if the application has not terminated within the given timeout
+   */
+  public V1beta1SparkApplication waitForCompletion(
+      String namespace, String name, long timeout, TimeUnit unit, long pollingIntervalSeconds)
+      throws ApiException, InterruptedException, TimeoutException {
+    long deadline = System.nanoTime() + unit.toNanos(timeout);
+    while (true) {
+      V1beta1SparkApplication app = get(namespace, name);
+      String state = getState(app);
+      if ("COMPLETED".equals(state) || "FAILED".equals(state)) {
+        return app;
+      }
+      if (System.nanoTime() >= deadline) {
+        throw new TimeoutException(String.format(
+            "timed out waiting for SparkApplication %s/%s to complete, it is %s",
+            namespace, name, state != null ? state : "new"));
+      }
+      TimeUnit.SECONDS.sleep(pollingIntervalSeconds);
+    }
+  }
+
+  /** Returns the log of the driver of the application with the given namespace and name. */
+  public String getLogs(String namespace, String name) throws ApiException {
+    V1beta1SparkApplication app = get(namespace, name);
+    V1beta1DriverInfo driverInfo = app.getStatus() != null ? app.getStatus().getDriverInfo() : null;
+    if (driverInfo == null || driverInfo.getPodName() == null) {
+      throw new IllegalStateException(
+          String.format("SparkApplication %s/%s has no driver pod yet", namespace, name));
+    }
+    return coreApi.readNamespacedPodLog(driverInfo.getPodName(), namespace, DRIVER_CONTAINER,
+        false, null, null, false, null, null, false);
+  }
+
+  private static String getState(V1beta1SparkApplication app) {
+    if (app.getStatus() == null || app.getStatus().getApplicationState() == null) {
+      return null;
+    }
+    return app.getStatus().getApplicationState().getState();
+  }
+
+  private Object toMap(V1beta1SparkApplication app) {
+    return json.deserialize(json.serialize(app), Object.class);
+  }
+
+  private V1beta1SparkApplication toModel(Object obj) {
+    return json.deserialize(json.serialize(obj), V1beta1SparkApplication.class);
+  }
+}
@@ -0,0 +1,31 @@
+# Copyright 2018 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from setuptools import setup, find_packages
+
+setup(
+    name='spark-operator',
+    version='0.1.0',
+    description='Python SDK of the Kubernetes Operator for Apache Spark',
+    url='https://github.com/GoogleCloudPlatform/spark-on-k8s-operator',
+    license='Apache License 2.0',
+    packages=find_packages(exclude=['test', 'test.*']),
+    install_requires=[
+        'certifi>=14.05.14',
+        'kubernetes>=9.0.0',
+        'python-dateutil>=2.1',
+        'six>=1.10',
+        'urllib3>=1.15',
+    ],
+)
@@ -0,0 +1,132 @@
+# Copyright 2018 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Submits SparkApplications, waits for them, and reads their driver logs.
+
+The models of the custom resources are generated from the OpenAPI definitions of the operator, while the
+requests go through the Kubernetes client.
+"""
+
+import json
+import os
+import time
+
+from kubernetes import client as k8s_client
+from kubernetes import config as k8s_config
+
+from spark_operator.api_client import ApiClient
+
+GROUP = 'sparkoperator.k8s.io'
+VERSION = 'v1beta1'
+PLURAL = 'sparkapplications'
+DRIVER_CONTAINER = 'spark-kubernetes-driver'
+TERMINAL_STATES = ('COMPLETED', 'FAILED')
+
+
+class _Response(object):
+    """Wraps a JSON string in the shape the generated ApiClient deserializes."""
+
+    def __init__(self, data):
+        self.data = data
+
+
+class SparkApplicationClient(object):
+    """A client of SparkApplications.
+
+    Applications can be given as V1beta1SparkApplication models or as dicts of the same shape, and are
+    returned as V1beta1SparkApplication models.
+    """
+
+    def __init__(self, config_file=None, context=None, api_client=None):
+        """Creates a client with the given Kubernetes ApiClient, or else loads the given kubeconfig file and
+        context, or the in-cluster configuration when running in a pod without a kubeconfig file."""
+        if api_client is None:
+            if config_file or not os.getenv('KUBERNETES_SERVICE_HOST'):
+                k8s_config.load_kube_config(config_file=config_file, context=context)
+            else:
+                k8s_config.load_incluster_config()
+            api_client = k8s_client.ApiClient()
+        self.custom_api = k8s_client.CustomObjectsApi(api_client)
+        self.core_api = k8s_client.CoreV1Api(api_client)
+        self.models = ApiClient()
+
+    def submit(self, app, namespace=None):
+        """Creates the given application, in its own namespace unless another is given."""
+        body = self.models.sanitize_for_serialization(app)
+        body.setdefault('apiVersion', GROUP + '/' + VERSION)
+        body.setdefault('kind', 'SparkApplication')
+        namespace = namespace or body.get('metadata', {}).get('namespace') or 'default'
+        created = self.custom_api.create_namespaced_custom_object(GROUP, VERSION, namespace, PLURAL, body)
+        return self._to_model(created)
+
+    def get(self, name, namespace='default'):
+        """Returns the application with the given name."""
+        app = self.custom_api.get_namespaced_custom_object(GROUP, VERSION, namespace, PLURAL, name)
+        return self._to_model(app)
+
+    def delete(self, name, namespace='default'):
+        """Deletes the application with the given name along with its driver and executors."""
+        self.custom_api.delete_namespaced_custom_object(GROUP, VERSION, namespace, PLURAL, name,
+                                                        k8s_client.V1DeleteOptions())
+
+    def wait_for_completion(self, name, namespace='default', timeout_seconds=3600, polling_interval=5):
+        """Waits until the application has completed or failed and returns it.
+
+        Raises a RuntimeError if the application has not terminated after the given number of seconds.
+        Applications with the restart policy Always never terminate.
+        """
+        deadline = time.time() + timeout_seconds
+        while True:
+            app = self.get(name, namespace)
+            if _get_state(app) in TERMINAL_STATES:
+                return app
+            if time.time() >= deadline:
+                raise RuntimeError('timed out waiting for SparkApplication %s/%s to complete, it is %s' %
+                                   (namespace, name, _get_state(app) or 'new'))
+            time.sleep(polling_interval)
+
+    def get_logs(self, name, namespace='default', follow=False, container=DRIVER_CONTAINER):
+        """Returns the log of the driver of the application.
+
+        With follow, returns an iterator over the lines of the log that ends when the driver terminates.
+        """
+        app = self.get(name, namespace)
+        driver_info = app.status.driver_info if app.status else None
+        if driver_info is None or not driver_info.pod_name:
+            raise RuntimeError('SparkApplication %s/%s has no driver pod yet' % (namespace, name))
+        if not follow:
+            return self.core_api.read_namespaced_pod_log(driver_info.pod_name, namespace, container=container)
+        response = self.core_api.read_namespaced_pod_log(driver_info.pod_name, namespace, container=container,
+                                                         follow=True, _preload_content=False)
+        return _iter_lines(response)
+
+    def _to_model(self, obj):
+        return self.models.deserialize(_Response(json.dumps(obj)), 'V1beta1SparkApplication')
+
+
+def _iter_lines(response):
+    pending = b''
+    for chunk in response.stream():
+        lines = (pending + chunk).split(b'\n')
+        pending = lines.pop()
+        for line in lines:
+            yield line.decode('utf-8')
+    if pending:
+        yield pending.decode('utf-8')
+
+
+def _get_state(app):
+    if app.status is None or app.status.application_state is None:
+        return None
+    return app.status.application_state.state
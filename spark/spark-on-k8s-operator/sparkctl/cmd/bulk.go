@@ -0,0 +1,116 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var Selector string
+var BulkRate float32
+
+// bulkTarget is an object a bulk operation acts on.
+type bulkTarget struct {
+	namespace string
+	name      string
+}
+
+func (t bulkTarget) String() string {
+	return t.namespace + "/" + t.name
+}
+
+// addBulkFlags adds the flags selecting the objects of the given kind a command acts on by labels instead of
+// by name.
+func addBulkFlags(cmd *cobra.Command, kind string) {
+	cmd.Flags().StringVarP(&Selector, "selector", "l", "",
+		fmt.Sprintf("label selector of the %ss to act on instead of the one with a given name", kind))
+	cmd.Flags().BoolVarP(&AllNamespaces, "all-namespaces", "A", false,
+		fmt.Sprintf("whether to select %ss in all namespaces instead of only the given namespace", kind))
+	cmd.Flags().Float32Var(&BulkRate, "rate", 5,
+		fmt.Sprintf("maximum number of %ss to act on per second when acting on a selector", kind))
+}
+
+// checkBulkArgs checks that the given arguments of a command name exactly one object, or none if a selector
+// is given.
+func checkBulkArgs(args []string, kind string) error {
+	if Selector != "" {
+		if len(args) != 0 {
+			return fmt.Errorf("a %s name cannot be given together with a selector", kind)
+		}
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("must specify a %s name or a selector", kind)
+	}
+	if AllNamespaces {
+		return fmt.Errorf("--all-namespaces requires a selector")
+	}
+	return nil
+}
+
+func getSelectedNamespace() string {
+	if AllNamespaces {
+		return apiv1.NamespaceAll
+	}
+	return Namespace
+}
+
+func listSelectedSparkApplications(crdClientset crdclientset.Interface) ([]v1beta1.SparkApplication, error) {
+	apps, err := crdClientset.SparkoperatorV1beta1().SparkApplications(getSelectedNamespace()).List(
+		metav1.ListOptions{LabelSelector: Selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SparkApplications: %v", err)
+	}
+	return apps.Items, nil
+}
+
+// runBulk runs the given operation on each of the given targets, at most BulkRate of them per second so that
+// the operator and the API server are not flooded, and reports the progress using the given verb and its past
+// tense. Failures do not stop the remaining targets from being acted on.
+func runBulk(verb string, pastVerb string, targets []bulkTarget, operation func(target bulkTarget) error) error {
+	if len(targets) == 0 {
+		fmt.Println("no objects matched the selector")
+		return nil
+	}
+
+	limiter := flowcontrol.NewTokenBucketRateLimiter(BulkRate, 1)
+	defer limiter.Stop()
+	failed := 0
+	for i, target := range targets {
+		limiter.Accept()
+		if err := operation(target); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "[%d/%d] failed to %s %s: %v\n", i+1, len(targets), verb, target, err)
+			continue
+		}
+		fmt.Printf("[%d/%d] %s %s\n", i+1, len(targets), pastVerb, target)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d objects", verb, failed, len(targets))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+)
+
+func TestBulkResubmit(t *testing.T) {
+	Namespace = "a"
+	Selector = "team=fraud"
+	BulkRate = 100
+	defer func() { Selector = "" }()
+
+	crdClientset := newBulkFakeClientset()
+	for _, app := range []*v1beta1.SparkApplication{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "a", Labels: map[string]string{"team": "fraud"}},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{State: v1beta1.FailedState, ErrorMessage: "OOMKilled"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "b", Labels: map[string]string{"team": "fraud"}},
+			Status:     v1beta1.SparkApplicationStatus{AppState: v1beta1.ApplicationState{State: v1beta1.FailedState}},
+		},
+	} {
+		if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Nil(t, doBulkResubmit(crdClientset))
+	foo, err := crdClientset.SparkoperatorV1beta1().SparkApplications("a").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.InvalidatingState, foo.Status.AppState.State)
+	assert.Empty(t, foo.Status.AppState.ErrorMessage)
+	// Only the applications in the given namespace are resubmitted.
+	bar, err := crdClientset.SparkoperatorV1beta1().SparkApplications("b").Get("bar", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.FailedState, bar.Status.AppState.State)
+
+	assert.NotNil(t, doResubmit("a", "missing", crdClientset))
+}
+
+func TestBulkSetSuspend(t *testing.T) {
+	Selector = "team=fraud"
+	AllNamespaces = true
+	BulkRate = 100
+	defer func() { Selector, AllNamespaces = "", false }()
+
+	crdClientset := newBulkFakeClientset()
+	for _, app := range []*v1beta1.ScheduledSparkApplication{
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "a", Labels: map[string]string{"team": "fraud"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "b", Labels: map[string]string{"team": "fraud"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "b", Labels: map[string]string{"team": "ads"}}},
+	} {
+		if _, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Nil(t, doBulkSetSuspend(true, "suspend", "suspended", crdClientset))
+	for namespace, name := range map[string]string{"a": "foo", "b": "bar"} {
+		app, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, *app.Spec.Suspend)
+	}
+	baz, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications("b").Get("baz", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, baz.Spec.Suspend)
+
+	assert.Nil(t, doBulkSetSuspend(false, "resume", "resumed", crdClientset))
+	foo, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications("a").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, *foo.Spec.Suspend)
+}
+
+// newBulkFakeClientset returns a fake clientset that lists the objects created through it by label selector,
+// which the object tracker of the generated fake clientset cannot do for the API group of the operator. The
+// objects are listed as created, so callers have to get their current version, and the reactors must not call
+// the clientset, which holds its lock while running them.
+func newBulkFakeClientset() *crdclientfake.Clientset {
+	crdClientset := crdclientfake.NewSimpleClientset()
+	var apps []*v1beta1.SparkApplication
+	var scheduledApps []*v1beta1.ScheduledSparkApplication
+	var runs []*v1beta1.SparkApplicationRun
+	crdClientset.PrependReactor("create", "*", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		switch obj := action.(kubetesting.CreateAction).GetObject().(type) {
+		case *v1beta1.SparkApplication:
+			apps = append(apps, obj)
+		case *v1beta1.ScheduledSparkApplication:
+			scheduledApps = append(scheduledApps, obj)
+		case *v1beta1.SparkApplicationRun:
+			runs = append(runs, obj)
+		}
+		return false, nil, nil
+	})
+	matches := func(action kubetesting.Action, meta metav1.ObjectMeta) bool {
+		restrictions := action.(kubetesting.ListAction).GetListRestrictions()
+		return (action.GetNamespace() == "" || action.GetNamespace() == meta.Namespace) &&
+			restrictions.Labels.Matches(labels.Set(meta.Labels))
+	}
+	crdClientset.PrependReactor("list", "sparkapplications", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		list := &v1beta1.SparkApplicationList{}
+		for _, app := range apps {
+			if matches(action, app.ObjectMeta) {
+				list.Items = append(list.Items, *app.DeepCopy())
+			}
+		}
+		return true, list, nil
+	})
+	crdClientset.PrependReactor("list", "scheduledsparkapplications", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		list := &v1beta1.ScheduledSparkApplicationList{}
+		for _, app := range scheduledApps {
+			if matches(action, app.ObjectMeta) {
+				list.Items = append(list.Items, *app.DeepCopy())
+			}
+		}
+		return true, list, nil
+	})
+	crdClientset.PrependReactor("list", "sparkapplicationruns", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		list := &v1beta1.SparkApplicationRunList{}
+		for _, run := range runs {
+			if matches(action, run.ObjectMeta) {
+				list.Items = append(list.Items, *run.DeepCopy())
+			}
+		}
+		return true, list, nil
+	})
+	return crdClientset
+}
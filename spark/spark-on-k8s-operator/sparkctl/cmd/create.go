@@ -37,6 +37,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/util"
 )
 
 const bufferSize = 1024
@@ -401,20 +402,15 @@ func (uh uploadHandler) uploadToBucket(uploadPath, localFilePath string) (string
 	return fmt.Sprintf("%s://%s/%s", uh.hdpScheme, uh.blobUploadBucket, uploadFilePath), nil
 }
 
-func uploadLocalDependencies(app *v1beta1.SparkApplication, files []string) ([]string, error) {
-	if UploadToPath == "" {
-		return nil, fmt.Errorf(
-			"unable to upload local dependencies: no upload location specified via --upload-to")
-	}
-
-	uploadLocationUrl, err := url.Parse(UploadToPath)
+// newUploadHandler returns an uploadHandler for the bucket of the given gs:// or s3:// upload location.
+func newUploadHandler(ctx context.Context, location string) (*uploadHandler, error) {
+	uploadLocationUrl, err := url.Parse(location)
 	if err != nil {
 		return nil, err
 	}
 	uploadBucket := uploadLocationUrl.Host
 
 	var uh *uploadHandler
-	ctx := context.Background()
 	switch uploadLocationUrl.Scheme {
 	case "gs":
 		uh, err = newGCSBlob(ctx, uploadBucket, UploadToEndpoint, UploadToRegion)
@@ -428,6 +424,19 @@ func uploadLocalDependencies(app *v1beta1.SparkApplication, files []string) ([]s
 	if err != nil {
 		return nil, err
 	}
+	return uh, nil
+}
+
+func uploadLocalDependencies(app *v1beta1.SparkApplication, files []string) ([]string, error) {
+	if UploadToPath == "" {
+		return nil, fmt.Errorf(
+			"unable to upload local dependencies: no upload location specified via --upload-to")
+	}
+
+	uh, err := newUploadHandler(context.Background(), UploadToPath)
+	if err != nil {
+		return nil, err
+	}
 
 	var uploadedFilePaths []string
 	uploadPath := filepath.Join(rootPath, app.Namespace, app.Name)
@@ -506,7 +515,7 @@ func buildHadoopConfigMap(appName string, hadoopConfDir string) (*apiv1.ConfigMa
 
 	configMap := &apiv1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      appName + "-hadoop-config",
+			Name:      util.BuildName(appName, "hadoop-config", util.DNS1123SubdomainMaxLength),
 			Namespace: Namespace,
 		},
 		Data:       hadoopStringConfigFiles,
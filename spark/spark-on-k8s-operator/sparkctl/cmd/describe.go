@@ -0,0 +1,359 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+// maxDescribedEvents is the number of most recent events describe shows.
+const maxDescribedEvents = 10
+
+var DescribeOutput string
+
+// applicationDescription is the description of a SparkApplication printed by describe.
+type applicationDescription struct {
+	Name         string                         `json:"name"`
+	Namespace    string                         `json:"namespace"`
+	State        v1beta1.ApplicationStateType   `json:"state"`
+	ErrorMessage string                         `json:"errorMessage,omitempty"`
+	Spec         specHighlights                 `json:"spec"`
+	Conditions   []v1beta1.ApplicationCondition `json:"conditions,omitempty"`
+	Executors    executorSummary                `json:"executors"`
+	Timeline     []timelineEntry                `json:"timeline"`
+	Events       []eventDescription             `json:"events,omitempty"`
+}
+
+// specHighlights are the fields of the spec of a SparkApplication that tell what it runs and with how much.
+type specHighlights struct {
+	Type                string   `json:"type"`
+	Mode                string   `json:"mode,omitempty"`
+	SparkVersion        string   `json:"sparkVersion"`
+	Image               string   `json:"image,omitempty"`
+	MainApplicationFile string   `json:"mainApplicationFile,omitempty"`
+	MainClass           string   `json:"mainClass,omitempty"`
+	Arguments           []string `json:"arguments,omitempty"`
+	DriverCores         string   `json:"driverCores,omitempty"`
+	DriverMemory        string   `json:"driverMemory,omitempty"`
+	ExecutorInstances   string   `json:"executorInstances,omitempty"`
+	ExecutorCores       string   `json:"executorCores,omitempty"`
+	ExecutorMemory      string   `json:"executorMemory,omitempty"`
+	RestartPolicy       string   `json:"restartPolicy,omitempty"`
+}
+
+// executorSummary counts the executors of a SparkApplication by state.
+type executorSummary struct {
+	Total   int            `json:"total"`
+	ByState map[string]int `json:"byState,omitempty"`
+}
+
+// timelineEntry is a phase of the current run of a SparkApplication, lasting from its start until the start of the
+// next phase, or until now for the last phase of a run that has not terminated.
+type timelineEntry struct {
+	Phase           string      `json:"phase"`
+	Start           metav1.Time `json:"start"`
+	DurationSeconds *int64      `json:"durationSeconds,omitempty"`
+}
+
+type eventDescription struct {
+	Type          string      `json:"type"`
+	Reason        string      `json:"reason"`
+	LastTimestamp metav1.Time `json:"lastTimestamp"`
+	Count         int32       `json:"count,omitempty"`
+	Message       string      `json:"message"`
+}
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <name>",
+	Short: "Describe a SparkApplication",
+	Long: `Describe a SparkApplication with a given name, showing the highlights of its spec, its conditions, its executors,
+a timeline of its current run, and its recent events`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "must specify a SparkApplication name")
+			return
+		}
+		if DescribeOutput != "" && DescribeOutput != "json" {
+			fmt.Fprintf(os.Stderr, "unsupported output format %q\n", DescribeOutput)
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		kubeClientset, err := getKubeClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get KubeClient: %v\n", err)
+			return
+		}
+
+		if err := doDescribe(args[0], crdClientset, kubeClientset, os.Stdout, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to describe SparkApplication %s: %v\n", args[0], err)
+		}
+	},
+}
+
+func init() {
+	describeCmd.Flags().StringVarP(&DescribeOutput, "output", "o", "",
+		"the output format, either empty for a human-readable description or json")
+}
+
+func doDescribe(name string, crdClientset crdclientset.Interface, kubeClientset kubernetes.Interface, out io.Writer,
+	now time.Time) error {
+	app, err := getSparkApplication(name, crdClientset)
+	if err != nil {
+		return fmt.Errorf("failed to get SparkApplication %s: %v", name, err)
+	}
+
+	// Only the events of the current SparkApplication with the name are shown, like by the event command.
+	kind := "SparkApplication"
+	uid := string(app.UID)
+	eventsInterface := kubeClientset.CoreV1().Events(app.Namespace)
+	selector := eventsInterface.GetFieldSelector(&app.Name, &app.Namespace, &kind, &uid)
+	events, err := eventsInterface.List(metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list the events of SparkApplication %s: %v", name, err)
+	}
+
+	description := describeApplication(app, events.Items, now)
+	if DescribeOutput == "json" {
+		data, err := json.MarshalIndent(description, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(data, '\n'))
+		return err
+	}
+	printDescription(description, out, now)
+	return nil
+}
+
+func describeApplication(app *v1beta1.SparkApplication, events []apiv1.Event, now time.Time) *applicationDescription {
+	description := &applicationDescription{
+		Name:         app.Name,
+		Namespace:    app.Namespace,
+		State:        app.Status.AppState.State,
+		ErrorMessage: app.Status.AppState.ErrorMessage,
+		Spec:         getSpecHighlights(&app.Spec),
+		Conditions:   app.Status.Conditions,
+		Executors:    executorSummary{Total: len(app.Status.ExecutorState)},
+		Timeline:     getTimeline(app, now),
+	}
+
+	for _, state := range app.Status.ExecutorState {
+		if description.Executors.ByState == nil {
+			description.Executors.ByState = make(map[string]int)
+		}
+		description.Executors.ByState[string(state)]++
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.After(events[j].LastTimestamp.Time)
+	})
+	if len(events) > maxDescribedEvents {
+		events = events[:maxDescribedEvents]
+	}
+	for _, event := range events {
+		description.Events = append(description.Events, eventDescription{
+			Type:          event.Type,
+			Reason:        event.Reason,
+			LastTimestamp: event.LastTimestamp,
+			Count:         event.Count,
+			Message:       strings.TrimSpace(event.Message),
+		})
+	}
+	return description
+}
+
+func getSpecHighlights(spec *v1beta1.SparkApplicationSpec) specHighlights {
+	highlights := specHighlights{
+		Type:                string(spec.Type),
+		Mode:                string(spec.Mode),
+		SparkVersion:        spec.SparkVersion,
+		Image:               stringValue(spec.Image),
+		MainApplicationFile: stringValue(spec.MainApplicationFile),
+		MainClass:           stringValue(spec.MainClass),
+		Arguments:           spec.Arguments,
+		DriverMemory:        stringValue(spec.Driver.Memory),
+		ExecutorMemory:      stringValue(spec.Executor.Memory),
+		RestartPolicy:       string(spec.RestartPolicy.Type),
+	}
+	if spec.Driver.Cores != nil {
+		highlights.DriverCores = fmt.Sprintf("%v", *spec.Driver.Cores)
+	}
+	if spec.Executor.Instances != nil {
+		highlights.ExecutorInstances = fmt.Sprintf("%d", *spec.Executor.Instances)
+	}
+	if spec.Executor.Cores != nil {
+		highlights.ExecutorCores = fmt.Sprintf("%v", *spec.Executor.Cores)
+	}
+	return highlights
+}
+
+// getTimeline returns the phases of the current run of the given application in the order they started.
+func getTimeline(app *v1beta1.SparkApplication, now time.Time) []timelineEntry {
+	terminated := "Terminated"
+	switch app.Status.AppState.State {
+	case v1beta1.CompletedState, v1beta1.FailedState, v1beta1.SkippedState:
+		terminated = string(app.Status.AppState.State)
+	}
+	timeline := []timelineEntry{
+		{Phase: "Created", Start: app.CreationTimestamp},
+		{Phase: "Queued", Start: app.Status.QueuedTime},
+	}
+	// Times of earlier runs are not reset, so the ones before the last submission belong to an earlier run.
+	runPhases := []timelineEntry{
+		{Phase: "Submitted", Start: app.Status.LastSubmissionAttemptTime},
+		{Phase: terminated, Start: app.Status.TerminationTime},
+	}
+	if app.Status.LaunchLatency != nil {
+		runPhases = append(runPhases,
+			timelineEntry{Phase: "Driver running", Start: app.Status.LaunchLatency.DriverRunningTime},
+			timelineEntry{Phase: "Executors running", Start: app.Status.LaunchLatency.FirstExecutorRunningTime})
+	}
+	for _, entry := range runPhases {
+		if !entry.Start.Before(&app.Status.LastSubmissionAttemptTime) {
+			timeline = append(timeline, entry)
+		}
+	}
+	for i := len(timeline) - 1; i >= 0; i-- {
+		if timeline[i].Start.IsZero() {
+			timeline = append(timeline[:i], timeline[i+1:]...)
+		}
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Start.Before(&timeline[j].Start)
+	})
+	for i := range timeline {
+		end := now
+		if i+1 < len(timeline) {
+			end = timeline[i+1].Start.Time
+		} else if timeline[i].Phase == terminated {
+			// The run ends with its termination.
+			break
+		}
+		seconds := int64(end.Sub(timeline[i].Start.Time).Seconds())
+		timeline[i].DurationSeconds = &seconds
+	}
+	return timeline
+}
+
+func printDescription(description *applicationDescription, out io.Writer, now time.Time) {
+	fmt.Fprintf(out, "Name:          %s\n", description.Name)
+	fmt.Fprintf(out, "Namespace:     %s\n", description.Namespace)
+	fmt.Fprintf(out, "State:         %s\n", formatNotAvailable(string(description.State)))
+	if description.ErrorMessage != "" {
+		fmt.Fprintf(out, "Error:         %s\n", description.ErrorMessage)
+	}
+
+	spec := description.Spec
+	fmt.Fprintln(out, "\nSpec:")
+	fmt.Fprintf(out, "  Type:        %s %s (Spark %s)\n", spec.Type, formatNotAvailable(spec.Mode), spec.SparkVersion)
+	fmt.Fprintf(out, "  Image:       %s\n", formatNotAvailable(spec.Image))
+	main := formatNotAvailable(spec.MainApplicationFile)
+	if spec.MainClass != "" {
+		main = fmt.Sprintf("%s (%s)", main, spec.MainClass)
+	}
+	fmt.Fprintf(out, "  Main:        %s\n", main)
+	if len(spec.Arguments) > 0 {
+		fmt.Fprintf(out, "  Arguments:   %s\n", strings.Join(spec.Arguments, " "))
+	}
+	fmt.Fprintf(out, "  Driver:      %s cores, %s memory\n", formatNotAvailable(spec.DriverCores),
+		formatNotAvailable(spec.DriverMemory))
+	fmt.Fprintf(out, "  Executors:   %s x %s cores, %s memory\n", formatNotAvailable(spec.ExecutorInstances),
+		formatNotAvailable(spec.ExecutorCores), formatNotAvailable(spec.ExecutorMemory))
+	fmt.Fprintf(out, "  Restart:     %s\n", formatNotAvailable(spec.RestartPolicy))
+
+	fmt.Fprintln(out, "\nTimeline:")
+	table := tablewriter.NewWriter(out)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Phase", "Started", "Duration"})
+	for _, entry := range description.Timeline {
+		duration := "-"
+		if entry.DurationSeconds != nil {
+			duration = (time.Duration(*entry.DurationSeconds) * time.Second).String()
+		}
+		table.Append([]string{entry.Phase, entry.Start.Time.Format(time.RFC3339), duration})
+	}
+	table.Render()
+
+	fmt.Fprintf(out, "\nExecutors: %d", description.Executors.Total)
+	var states []string
+	for state, count := range description.Executors.ByState {
+		states = append(states, fmt.Sprintf("%d %s", count, state))
+	}
+	sort.Strings(states)
+	if len(states) > 0 {
+		fmt.Fprintf(out, " (%s)", strings.Join(states, ", "))
+	}
+	fmt.Fprintln(out)
+
+	if len(description.Conditions) > 0 {
+		fmt.Fprintln(out, "\nConditions:")
+		// Messages are printed on a single line so that they can be searched for.
+		table := tablewriter.NewWriter(out)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Type", "Status", "Age", "Reason", "Message"})
+		for _, condition := range description.Conditions {
+			table.Append([]string{
+				string(condition.Type),
+				string(condition.Status),
+				getAge(condition.LastTransitionTime, now),
+				condition.Reason,
+				condition.Message,
+			})
+		}
+		table.Render()
+	}
+
+	if len(description.Events) > 0 {
+		fmt.Fprintln(out, "\nEvents:")
+		table := tablewriter.NewWriter(out)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Type", "Reason", "Age", "Message"})
+		for _, event := range description.Events {
+			table.Append([]string{event.Type, event.Reason, getAge(event.LastTimestamp, now), event.Message})
+		}
+		table.Render()
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+)
+
+func TestGetTimeline(t *testing.T) {
+	created := time.Date(2019, 1, 2, 3, 0, 0, 0, time.UTC)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.RunningState},
+			LastSubmissionAttemptTime: metav1.NewTime(created.Add(time.Minute)),
+			// The termination of an earlier run.
+			TerminationTime: metav1.NewTime(created.Add(30 * time.Second)),
+			LaunchLatency: &v1beta1.LaunchLatency{
+				DriverRunningTime: metav1.NewTime(created.Add(2 * time.Minute)),
+			},
+		},
+	}
+	timeline := getTimeline(app, created.Add(10*time.Minute))
+	assert.Equal(t, 3, len(timeline))
+	assert.Equal(t, "Created", timeline[0].Phase)
+	assert.Equal(t, int64(60), *timeline[0].DurationSeconds)
+	assert.Equal(t, "Submitted", timeline[1].Phase)
+	assert.Equal(t, int64(60), *timeline[1].DurationSeconds)
+	assert.Equal(t, "Driver running", timeline[2].Phase)
+	assert.Equal(t, int64(480), *timeline[2].DurationSeconds)
+
+	app.Status.AppState.State = v1beta1.CompletedState
+	app.Status.TerminationTime = metav1.NewTime(created.Add(5 * time.Minute))
+	timeline = getTimeline(app, created.Add(10*time.Minute))
+	assert.Equal(t, 4, len(timeline))
+	assert.Equal(t, int64(180), *timeline[2].DurationSeconds)
+	assert.Equal(t, "COMPLETED", timeline[3].Phase)
+	assert.Nil(t, timeline[3].DurationSeconds)
+}
+
+func TestDescribe(t *testing.T) {
+	Namespace = "default"
+	created := time.Date(2019, 1, 2, 3, 0, 0, 0, time.UTC)
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+		Spec: v1beta1.SparkApplicationSpec{
+			Type:                v1beta1.ScalaApplicationType,
+			Mode:                v1beta1.ClusterMode,
+			SparkVersion:        "2.4.0",
+			Image:               stringptr("spark:2.4.0"),
+			MainClass:           stringptr("org.apache.spark.examples.SparkPi"),
+			MainApplicationFile: stringptr("local:///opt/spark/examples/jars/spark-examples.jar"),
+			Executor:            v1beta1.ExecutorSpec{Instances: int32ptr(2)},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: v1beta1.RunningState},
+			LastSubmissionAttemptTime: metav1.NewTime(created.Add(time.Minute)),
+			ExecutorState: map[string]v1beta1.ExecutorState{
+				"foo-exec-1": v1beta1.ExecutorRunningState,
+				"foo-exec-2": v1beta1.ExecutorPendingState,
+			},
+		},
+	}
+	event := &apiv1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "foo.1", Namespace: "default"},
+		Type:           apiv1.EventTypeNormal,
+		Reason:         "SparkApplicationSubmitted",
+		Message:        "SparkApplication foo was submitted successfully",
+		LastTimestamp:  metav1.NewTime(created.Add(time.Minute)),
+		InvolvedObject: apiv1.ObjectReference{Kind: "SparkApplication", Name: "foo", Namespace: "default"},
+	}
+	crdClientset := crdclientfake.NewSimpleClientset()
+	if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+		t.Fatal(err)
+	}
+	kubeClientset := kubeclientfake.NewSimpleClientset(event)
+	now := created.Add(5 * time.Minute)
+
+	DescribeOutput = "json"
+	var out bytes.Buffer
+	if err := doDescribe("foo", crdClientset, kubeClientset, &out, now); err != nil {
+		t.Fatal(err)
+	}
+	var description applicationDescription
+	if err := json.Unmarshal(out.Bytes(), &description); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, v1beta1.RunningState, description.State)
+	assert.Equal(t, "2", description.Spec.ExecutorInstances)
+	assert.Equal(t, executorSummary{Total: 2, ByState: map[string]int{"RUNNING": 1, "PENDING": 1}},
+		description.Executors)
+	assert.Equal(t, 2, len(description.Timeline))
+	assert.Equal(t, int64(240), *description.Timeline[1].DurationSeconds)
+	assert.Equal(t, 1, len(description.Events))
+	assert.Equal(t, "SparkApplicationSubmitted", description.Events[0].Reason)
+
+	DescribeOutput = ""
+	out.Reset()
+	if err := doDescribe("foo", crdClientset, kubeClientset, &out, now); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, out.String(), "Main:        local:///opt/spark/examples/jars/spark-examples.jar (org.apache.spark.examples.SparkPi)")
+	assert.Contains(t, out.String(), "Executors: 2 (1 PENDING, 1 RUNNING)")
+	assert.Contains(t, out.String(), "4m0s")
+	assert.Contains(t, out.String(), "SparkApplication foo was submitted successfully")
+}
+
+func stringptr(s string) *string {
+	return &s
+}
+
+func int32ptr(n int32) *int32 {
+	return &n
+}
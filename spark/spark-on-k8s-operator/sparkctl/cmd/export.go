@@ -0,0 +1,112 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var AllNamespaces bool
+var ExportFile string
+
+// operatorState holds exported SparkApplications and ScheduledSparkApplications including their statuses.
+type operatorState struct {
+	SparkApplications          []v1beta1.SparkApplication          `json:"sparkApplications"`
+	ScheduledSparkApplications []v1beta1.ScheduledSparkApplication `json:"scheduledSparkApplications"`
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export SparkApplication and ScheduledSparkApplication objects",
+	Long: `Export SparkApplication and ScheduledSparkApplication objects including their statuses to a file
+that can be imported to another cluster using the import command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		out := os.Stdout
+		if ExportFile != "" {
+			out, err = os.Create(ExportFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", ExportFile, err)
+				return
+			}
+			defer out.Close()
+		}
+
+		namespace := Namespace
+		if AllNamespaces {
+			namespace = apiv1.NamespaceAll
+		}
+		if err = doExport(namespace, out, crdClientset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to export operator state: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	exportCmd.Flags().BoolVarP(&AllNamespaces, "all-namespaces", "A", false,
+		"whether to export objects in all namespaces instead of only the given namespace")
+	exportCmd.Flags().StringVarP(&ExportFile, "output", "o", "",
+		"the file to write exported objects to, defaults to stdout")
+}
+
+func doExport(namespace string, out io.Writer, crdClientset crdclientset.Interface) error {
+	apps, err := crdClientset.SparkoperatorV1beta1().SparkApplications(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list SparkApplications: %v", err)
+	}
+	scheduledApps, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list ScheduledSparkApplications: %v", err)
+	}
+
+	state := &operatorState{
+		SparkApplications:          apps.Items,
+		ScheduledSparkApplications: scheduledApps.Items,
+	}
+	if err = writeOperatorState(state, out); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d SparkApplications and %d ScheduledSparkApplications\n",
+		len(state.SparkApplications), len(state.ScheduledSparkApplications))
+	return nil
+}
+
+func writeOperatorState(state *operatorState, out io.Writer) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
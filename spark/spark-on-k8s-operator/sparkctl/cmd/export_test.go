@@ -0,0 +1,84 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestImport(t *testing.T) {
+	sapp := &v1beta1.ScheduledSparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "team-a", UID: "sapp-uid"},
+		Spec:       v1beta1.ScheduledSparkApplicationSpec{Schedule: "@daily"},
+	}
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "nightly-run",
+			Namespace:       "team-a",
+			UID:             "app-uid",
+			ResourceVersion: "42",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ScheduledSparkApplication", Name: "nightly", UID: "sapp-uid"},
+				{Kind: "ScheduledSparkApplication", Name: "deleted", UID: "gone-uid"},
+			},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState: v1beta1.ApplicationState{State: v1beta1.CompletedState},
+		},
+	}
+	state := &operatorState{
+		SparkApplications:          []v1beta1.SparkApplication{*app},
+		ScheduledSparkApplications: []v1beta1.ScheduledSparkApplication{*sapp},
+	}
+
+	file, err := ioutil.TempFile("", "sparkctl-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if err := writeOperatorState(state, file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	target := crdclientfake.NewSimpleClientset()
+	if err := doImport(file.Name(), target); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := target.SparkoperatorV1beta1().SparkApplications("team-a").Get("nightly-run", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1beta1.CompletedState, imported.Status.AppState.State)
+	assert.Equal(t, "app-uid", imported.Annotations[config.AdoptedFromUIDAnnotation])
+	assert.Equal(t, "", imported.ResourceVersion)
+	assert.Equal(t, 1, len(imported.OwnerReferences))
+	assert.Equal(t, "nightly", imported.OwnerReferences[0].Name)
+
+	importedSapp, err := target.SparkoperatorV1beta1().ScheduledSparkApplications("team-a").Get("nightly", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "sapp-uid", importedSapp.Annotations[config.AdoptedFromUIDAnnotation])
+}
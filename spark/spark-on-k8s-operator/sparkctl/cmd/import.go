@@ -0,0 +1,157 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import exported SparkApplication and ScheduledSparkApplication objects",
+	Long: `Import SparkApplication and ScheduledSparkApplication objects from a file written by the export command.
+Objects are re-created with their statuses preserved and are marked as adopted. Objects that already exist are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "must specify a file written by the export command")
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		if err := doImport(args[0], crdClientset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to import operator state: %v\n", err)
+		}
+	},
+}
+
+func doImport(file string, crdClientset crdclientset.Interface) error {
+	state, err := loadOperatorState(file)
+	if err != nil {
+		return fmt.Errorf("failed to read exported objects from %s: %v", file, err)
+	}
+
+	// ScheduledSparkApplications are imported first so that owner references of the SparkApplications they
+	// own can be pointed to the new UIDs.
+	newUIDs := make(map[types.UID]types.UID)
+	for i := range state.ScheduledSparkApplications {
+		sapp := &state.ScheduledSparkApplications[i]
+		oldUID := sapp.UID
+		resetObjectMeta(&sapp.ObjectMeta)
+		created, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(sapp.Namespace).Create(sapp)
+		if errors.IsAlreadyExists(err) {
+			fmt.Printf("ScheduledSparkApplication \"%s/%s\" already exists, skipping\n", sapp.Namespace, sapp.Name)
+			existing, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(sapp.Namespace).Get(
+				sapp.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get ScheduledSparkApplication %s/%s: %v", sapp.Namespace, sapp.Name, err)
+			}
+			newUIDs[oldUID] = existing.UID
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create ScheduledSparkApplication %s/%s: %v", sapp.Namespace, sapp.Name, err)
+		}
+		newUIDs[oldUID] = created.UID
+		fmt.Printf("ScheduledSparkApplication \"%s/%s\" imported\n", sapp.Namespace, sapp.Name)
+	}
+
+	for i := range state.SparkApplications {
+		app := &state.SparkApplications[i]
+		resetObjectMeta(&app.ObjectMeta)
+		app.OwnerReferences = remapOwnerReferences(app.OwnerReferences, newUIDs)
+		created, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app)
+		if err != nil {
+			if errors.IsAlreadyExists(err) {
+				fmt.Printf("SparkApplication \"%s/%s\" already exists, skipping\n", app.Namespace, app.Name)
+				continue
+			}
+			return fmt.Errorf("failed to create SparkApplication %s/%s: %v", app.Namespace, app.Name, err)
+		}
+		// The status is ignored on creation and has to be written through the status subresource.
+		created.Status = app.Status
+		if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).UpdateStatus(
+			created); err != nil {
+			return fmt.Errorf("failed to restore the status of SparkApplication %s/%s: %v", app.Namespace,
+				app.Name, err)
+		}
+		fmt.Printf("SparkApplication \"%s/%s\" imported\n", app.Namespace, app.Name)
+	}
+
+	return nil
+}
+
+func loadOperatorState(file string) (*operatorState, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	state := &operatorState{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, bufferSize).Decode(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// resetObjectMeta clears the fields of the given exported ObjectMeta that are set by the API server of the
+// source cluster and marks the object as adopted.
+func resetObjectMeta(meta *metav1.ObjectMeta) {
+	if meta.Namespace == "" {
+		meta.Namespace = Namespace
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	if _, ok := meta.Annotations[config.AdoptedFromUIDAnnotation]; !ok {
+		meta.Annotations[config.AdoptedFromUIDAnnotation] = string(meta.UID)
+	}
+	meta.UID = ""
+	meta.ResourceVersion = ""
+	meta.SelfLink = ""
+	meta.Generation = 0
+	meta.CreationTimestamp = metav1.Time{}
+}
+
+// remapOwnerReferences points owner references to the new UIDs of imported owners. References to owners that
+// have not been imported are dropped, as the garbage collector would otherwise delete the object.
+func remapOwnerReferences(refs []metav1.OwnerReference, newUIDs map[types.UID]types.UID) []metav1.OwnerReference {
+	var result []metav1.OwnerReference
+	for _, ref := range refs {
+		if uid, ok := newUIDs[ref.UID]; ok {
+			ref.UID = uid
+			result = append(result, ref)
+		}
+	}
+	return result
+}
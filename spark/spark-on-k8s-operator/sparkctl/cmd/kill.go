@@ -0,0 +1,159 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var KillTimeout int32
+
+var killCmd = &cobra.Command{
+	Use:   "kill <name> | -l <selector> [--all-namespaces] [--timeout <seconds>]",
+	Short: "Kill SparkApplications and wait for their drivers to shut down",
+	Long: `Delete a SparkApplication object with a given name, or all the ones matching a label selector, and wait for
+their drivers to shut down. The operator cancels the running jobs and gives each driver its termination grace
+period to commit or clean up its output.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkBulkArgs(args, "SparkApplication"); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		kubeClientset, err := getKubeClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get Kubernetes client: %v\n", err)
+			return
+		}
+
+		if Selector != "" {
+			if err := doBulkKill(crdClientset, kubeClientset); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to kill SparkApplications: %v\n", err)
+			}
+			return
+		}
+		if err := doKill(args[0], crdClientset, kubeClientset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to kill SparkApplication %s: %v\n", args[0], err)
+		}
+	},
+}
+
+func init() {
+	killCmd.Flags().Int32VarP(&KillTimeout, "timeout", "t", 300,
+		"seconds to wait for the driver to shut down")
+	addBulkFlags(killCmd, "SparkApplication")
+}
+
+func doKill(name string, crdClientset crdclientset.Interface, kubeClientset clientset.Interface) error {
+	app, err := getSparkApplication(name, crdClientset)
+	if err != nil {
+		return fmt.Errorf("failed to get SparkApplication %s: %v", name, err)
+	}
+
+	if err := doDelete(name, crdClientset); err != nil {
+		return err
+	}
+
+	driverPodName := app.Status.DriverInfo.PodName
+	if driverPodName == "" {
+		return nil
+	}
+	fmt.Printf("waiting for driver pod \"%s\" to shut down\n", driverPodName)
+	err = wait.PollImmediate(1*time.Second, time.Duration(KillTimeout)*time.Second, func() (bool, error) {
+		_, err := kubeClientset.CoreV1().Pods(Namespace).Get(driverPodName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("driver pod %s did not shut down: %v", driverPodName, err)
+	}
+
+	fmt.Printf("driver pod \"%s\" shut down\n", driverPodName)
+	return nil
+}
+
+// doBulkKill deletes the SparkApplications matching the selector and waits for all of their drivers to shut
+// down together, so that the grace periods of the drivers overlap.
+func doBulkKill(crdClientset crdclientset.Interface, kubeClientset clientset.Interface) error {
+	apps, err := listSelectedSparkApplications(crdClientset)
+	if err != nil {
+		return err
+	}
+
+	var targets []bulkTarget
+	driverPods := make(map[bulkTarget]bool)
+	for _, app := range apps {
+		targets = append(targets, bulkTarget{namespace: app.Namespace, name: app.Name})
+		if app.Status.DriverInfo.PodName != "" {
+			driverPods[bulkTarget{namespace: app.Namespace, name: app.Status.DriverInfo.PodName}] = false
+		}
+	}
+	deleteErr := runBulk("kill", "killed SparkApplication", targets, func(target bulkTarget) error {
+		err := crdClientset.SparkoperatorV1beta1().SparkApplications(target.namespace).Delete(target.name,
+			&metav1.DeleteOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+	if len(driverPods) == 0 {
+		return deleteErr
+	}
+
+	fmt.Printf("waiting for %d driver pods to shut down\n", len(driverPods))
+	shutDown := 0
+	err = wait.PollImmediate(1*time.Second, time.Duration(KillTimeout)*time.Second, func() (bool, error) {
+		for pod, done := range driverPods {
+			if done {
+				continue
+			}
+			_, err := kubeClientset.CoreV1().Pods(pod.namespace).Get(pod.name, metav1.GetOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return false, err
+			}
+			if errors.IsNotFound(err) {
+				driverPods[pod] = true
+				shutDown++
+				fmt.Printf("[%d/%d] driver pod %s shut down\n", shutDown, len(driverPods), pod)
+			}
+		}
+		return shutDown == len(driverPods), nil
+	})
+	if err != nil {
+		return fmt.Errorf("%d of %d driver pods did not shut down: %v", len(driverPods)-shutDown, len(driverPods), err)
+	}
+	return deleteErr
+}
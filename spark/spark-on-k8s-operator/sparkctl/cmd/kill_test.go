@@ -0,0 +1,103 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+)
+
+func TestKill(t *testing.T) {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Status: v1beta1.SparkApplicationStatus{
+			DriverInfo: v1beta1.DriverInfo{PodName: "foo-driver"},
+		},
+	}
+	driverPod := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-driver", Namespace: "default"}}
+
+	Namespace = "default"
+	KillTimeout = 1
+	crdClientset := crdclientfake.NewSimpleClientset()
+	if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications("default").Create(app); err != nil {
+		t.Fatal(err)
+	}
+	kubeClientset := kubeclientfake.NewSimpleClientset(driverPod)
+	// The driver pod is not deleted by the fake clients.
+	err := doKill("foo", crdClientset, kubeClientset)
+	assert.Contains(t, err.Error(), "did not shut down")
+	_, err = crdClientset.SparkoperatorV1beta1().SparkApplications("default").Get("foo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications("default").Create(app); err != nil {
+		t.Fatal(err)
+	}
+	kubeClientset = kubeclientfake.NewSimpleClientset()
+	assert.Nil(t, doKill("foo", crdClientset, kubeClientset))
+}
+
+func TestBulkKill(t *testing.T) {
+	Namespace = "default"
+	Selector = "team=fraud"
+	AllNamespaces = true
+	BulkRate = 100
+	KillTimeout = 1
+	defer func() { Selector, AllNamespaces = "", false }()
+
+	crdClientset := newBulkFakeClientset()
+	for _, app := range []*v1beta1.SparkApplication{
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "a", Labels: map[string]string{"team": "fraud"}},
+			Status: v1beta1.SparkApplicationStatus{DriverInfo: v1beta1.DriverInfo{PodName: "foo-driver"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "b", Labels: map[string]string{"team": "fraud"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "a", Labels: map[string]string{"team": "ads"}}},
+	} {
+		if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kubeClientset := kubeclientfake.NewSimpleClientset()
+
+	assert.Nil(t, doBulkKill(crdClientset, kubeClientset))
+	_, err := crdClientset.SparkoperatorV1beta1().SparkApplications("a").Get("foo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = crdClientset.SparkoperatorV1beta1().SparkApplications("b").Get("bar", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = crdClientset.SparkoperatorV1beta1().SparkApplications("a").Get("baz", metav1.GetOptions{})
+	assert.Nil(t, err)
+}
+
+func TestCheckBulkArgs(t *testing.T) {
+	defer func() { Selector, AllNamespaces = "", false }()
+
+	Selector, AllNamespaces = "", false
+	assert.Nil(t, checkBulkArgs([]string{"foo"}, "SparkApplication"))
+	assert.NotNil(t, checkBulkArgs(nil, "SparkApplication"))
+	AllNamespaces = true
+	assert.NotNil(t, checkBulkArgs([]string{"foo"}, "SparkApplication"))
+	Selector = "team=fraud"
+	assert.Nil(t, checkBulkArgs(nil, "SparkApplication"))
+	assert.NotNil(t, checkBulkArgs([]string{"foo"}, "SparkApplication"))
+}
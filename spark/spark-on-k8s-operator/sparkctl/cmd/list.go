@@ -18,50 +18,185 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
 	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
 )
 
+// listPageSize is the number of SparkApplications list fetches per request, so that listing thousands of
+// applications neither times out nor loads the API server with a single huge response.
+const listPageSize = 500
+
+var ListStates []string
+var ListLabels []string
+var ListSince time.Duration
+var ListSortBy string
+
+// listSortKeys are the keys list can sort SparkApplications by. Ages and durations are sorted in descending order.
+var listSortKeys = map[string]func(a, b *v1beta1.SparkApplication, now time.Time) bool{
+	"name": func(a, b *v1beta1.SparkApplication, now time.Time) bool {
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	},
+	"state": func(a, b *v1beta1.SparkApplication, now time.Time) bool {
+		return a.Status.AppState.State < b.Status.AppState.State
+	},
+	"age": func(a, b *v1beta1.SparkApplication, now time.Time) bool {
+		return b.Status.LastSubmissionAttemptTime.Before(&a.Status.LastSubmissionAttemptTime)
+	},
+	"duration": func(a, b *v1beta1.SparkApplication, now time.Time) bool {
+		return getRunDuration(a, now) > getRunDuration(b, now)
+	},
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List SparkApplication objects",
-	Long:  `List SparkApplication objects in a given namespaces.`,
+	Long: `List SparkApplication objects in a given namespaces, optionally filtered by state, labels, and how recently
+they ran, and sorted`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if _, ok := listSortKeys[ListSortBy]; ListSortBy != "" && !ok {
+			fmt.Fprintf(os.Stderr, "unsupported sort key %q, must be one of name, state, age, and duration\n",
+				ListSortBy)
+			return
+		}
+
 		crdClientset, err := getSparkApplicationClient()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
 			return
 		}
 
-		if err = doList(crdClientset); err != nil {
+		if err = doList(crdClientset, os.Stdout, time.Now()); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to list SparkApplications: %v\n", err)
 		}
 	},
 }
 
-func doList(crdClientset crdclientset.Interface) error {
-	apps, err := crdClientset.SparkoperatorV1beta1().SparkApplications(Namespace).List(metav1.ListOptions{})
+func init() {
+	listCmd.Flags().StringSliceVarP(&ListStates, "state", "s", nil,
+		"the states of the SparkApplications to list, e.g., FAILED or RUNNING,PENDING_RERUN")
+	listCmd.Flags().StringArrayVarP(&ListLabels, "label", "l", nil,
+		"a label selector the SparkApplications to list must match, e.g., team=ads; may be repeated")
+	listCmd.Flags().DurationVar(&ListSince, "since", 0,
+		"only list SparkApplications submitted or terminated within the given duration, e.g., 24h")
+	listCmd.Flags().StringVar(&ListSortBy, "sort-by", "",
+		"the key to sort SparkApplications by: name, state, age (newest submission first), or duration "+
+			"(longest run first)")
+	listCmd.Flags().BoolVarP(&AllNamespaces, "all-namespaces", "A", false,
+		"whether to list SparkApplications in all namespaces instead of only the given namespace")
+}
+
+// getListSelector returns the label selector of the SparkApplications to list. States are selected by the label
+// the operator keeps set to the state of each application, so that the API server does the filtering.
+func getListSelector() (string, error) {
+	selector, err := labels.Parse(strings.Join(ListLabels, ","))
+	if err != nil {
+		return "", fmt.Errorf("invalid label selector: %v", err)
+	}
+	if len(ListStates) > 0 {
+		var states []string
+		for _, state := range ListStates {
+			states = append(states, strings.ToUpper(state))
+		}
+		requirement, err := labels.NewRequirement(config.SparkAppStateLabel, selection.In, states)
+		if err != nil {
+			return "", fmt.Errorf("invalid state: %v", err)
+		}
+		selector = selector.Add(*requirement)
+	}
+	return selector.String(), nil
+}
+
+func doList(crdClientset crdclientset.Interface, out io.Writer, now time.Time) error {
+	selector, err := getListSelector()
 	if err != nil {
 		return err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "State", "Submission Age", "Termination Age"})
-	for _, app := range apps.Items {
-		table.Append([]string{
-			string(app.Name),
-			string(app.Status.AppState.State),
-			getSinceTime(app.Status.LastSubmissionAttemptTime),
-			getSinceTime(app.Status.TerminationTime),
+	// Applications are listed page by page, keeping only the ones matching the filters the API server cannot
+	// apply to custom resources.
+	var apps []*v1beta1.SparkApplication
+	options := metav1.ListOptions{LabelSelector: selector, Limit: listPageSize}
+	for {
+		page, err := crdClientset.SparkoperatorV1beta1().SparkApplications(getSelectedNamespace()).List(options)
+		if err != nil {
+			return err
+		}
+		for i := range page.Items {
+			if ListSince <= 0 || ranSince(&page.Items[i], now.Add(-ListSince)) {
+				apps = append(apps, &page.Items[i])
+			}
+		}
+		if page.Continue == "" {
+			break
+		}
+		options.Continue = page.Continue
+	}
+
+	if less, ok := listSortKeys[ListSortBy]; ok {
+		sort.SliceStable(apps, func(i, j int) bool {
+			return less(apps[i], apps[j], now)
 		})
 	}
+
+	table := tablewriter.NewWriter(out)
+	header := []string{"Name", "State", "Submission Age", "Termination Age", "Duration"}
+	if AllNamespaces {
+		header = append([]string{"Namespace"}, header...)
+	}
+	table.SetHeader(header)
+	for _, app := range apps {
+		duration := "N.A."
+		if !app.Status.LastSubmissionAttemptTime.IsZero() {
+			duration = getRunDuration(app, now).Round(time.Second).String()
+		}
+		row := []string{
+			app.Name,
+			string(app.Status.AppState.State),
+			getAge(app.Status.LastSubmissionAttemptTime, now),
+			getAge(app.Status.TerminationTime, now),
+			duration,
+		}
+		if AllNamespaces {
+			row = append([]string{app.Namespace}, row...)
+		}
+		table.Append(row)
+	}
 	table.Render()
 
 	return nil
 }
+
+// ranSince tells if the given application was last submitted or terminated after the given time.
+func ranSince(app *v1beta1.SparkApplication, since time.Time) bool {
+	return app.Status.LastSubmissionAttemptTime.After(since) || app.Status.TerminationTime.After(since)
+}
+
+// getRunDuration returns how long the current run of the given application has run, or ran if it has terminated.
+func getRunDuration(app *v1beta1.SparkApplication, now time.Time) time.Duration {
+	submitted := app.Status.LastSubmissionAttemptTime
+	if submitted.IsZero() {
+		return 0
+	}
+	terminated := app.Status.TerminationTime
+	if !terminated.IsZero() && !terminated.Before(&submitted) {
+		return terminated.Sub(submitted.Time)
+	}
+	return now.Sub(submitted.Time)
+}
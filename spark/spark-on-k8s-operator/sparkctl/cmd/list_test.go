@@ -0,0 +1,121 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func newListedApp(name string, state v1beta1.ApplicationStateType, team string, submitted time.Time,
+	ran time.Duration) *v1beta1.SparkApplication {
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"team": team, config.SparkAppStateLabel: string(state)},
+		},
+		Status: v1beta1.SparkApplicationStatus{
+			AppState:                  v1beta1.ApplicationState{State: state},
+			LastSubmissionAttemptTime: metav1.NewTime(submitted),
+		},
+	}
+	if state == v1beta1.CompletedState || state == v1beta1.FailedState {
+		app.Status.TerminationTime = metav1.NewTime(submitted.Add(ran))
+	}
+	return app
+}
+
+func TestGetListSelector(t *testing.T) {
+	ListLabels = []string{"team=ads", "env!=dev"}
+	ListStates = []string{"failed", "SUBMISSION_FAILED"}
+	selector, err := getListSelector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "env!=dev,sparkoperator.k8s.io/app-state in (FAILED,SUBMISSION_FAILED),team=ads", selector)
+
+	ListLabels = []string{"team in ads"}
+	ListStates = nil
+	_, err = getListSelector()
+	assert.NotNil(t, err)
+	ListLabels = nil
+}
+
+func TestList(t *testing.T) {
+	Namespace = "default"
+	now := time.Date(2019, 1, 2, 3, 0, 0, 0, time.UTC)
+	crdClientset := newBulkFakeClientset()
+	for _, app := range []*v1beta1.SparkApplication{
+		newListedApp("short", v1beta1.FailedState, "ads", now.Add(-time.Hour), time.Minute),
+		newListedApp("long", v1beta1.FailedState, "ads", now.Add(-2*time.Hour), time.Hour),
+		newListedApp("old", v1beta1.FailedState, "ads", now.Add(-48*time.Hour), time.Minute),
+		newListedApp("other-team", v1beta1.FailedState, "search", now.Add(-time.Hour), time.Minute),
+		newListedApp("running", v1beta1.RunningState, "ads", now.Add(-time.Hour), 0),
+	} {
+		if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ListStates = []string{"FAILED"}
+	ListLabels = []string{"team=ads"}
+	ListSince = 24 * time.Hour
+	ListSortBy = "duration"
+	var out bytes.Buffer
+	if err := doList(crdClientset, &out, now); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	var names []string
+	for _, line := range lines {
+		fields := strings.Fields(strings.Replace(line, "|", " ", -1))
+		if len(fields) > 0 && fields[0] != "NAME" && !strings.HasPrefix(fields[0], "+") {
+			names = append(names, fields[0])
+		}
+	}
+	assert.Equal(t, []string{"long", "short"}, names)
+	assert.Contains(t, out.String(), "1h0m0s")
+
+	ListStates = nil
+	ListLabels = nil
+	ListSince = 0
+	ListSortBy = ""
+}
+
+func TestGetRunDuration(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Hour, getRunDuration(newListedApp("foo", v1beta1.RunningState, "ads",
+		now.Add(-time.Hour), 0), now))
+	assert.Equal(t, time.Minute, getRunDuration(newListedApp("foo", v1beta1.CompletedState, "ads",
+		now.Add(-time.Hour), time.Minute), now))
+
+	// The termination of an earlier run is ignored.
+	app := newListedApp("foo", v1beta1.RunningState, "ads", now.Add(-time.Hour), 0)
+	app.Status.TerminationTime = metav1.NewTime(now.Add(-2 * time.Hour))
+	assert.Equal(t, time.Hour, getRunDuration(app, now))
+	assert.Equal(t, time.Duration(0), getRunDuration(&v1beta1.SparkApplication{}, now))
+}
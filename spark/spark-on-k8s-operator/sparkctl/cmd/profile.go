@@ -0,0 +1,318 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	sparkDriverContainerName   = "spark-kubernetes-driver"
+	sparkExecutorContainerName = "executor"
+	profilerContainerPrefix    = "sparkctl-profiler"
+	profileUploadRootPath      = "spark-profiles"
+	flameGraphBeginMarker      = "----- BEGIN FLAME GRAPH -----"
+	flameGraphEndMarker        = "----- END FLAME GRAPH -----"
+	profilerStartTimeout       = 2 * time.Minute
+	profilerPollInterval       = 2 * time.Second
+	// findJVMScript sets pid to the process ID of the JVM in the target container, whose process namespace the
+	// profiler container shares.
+	findJVMScript = `pid=$(grep -lx java /proc/[0-9]*/comm 2>/dev/null | head -n 1 | cut -d / -f 3)
+if [ -z "$pid" ]; then echo "no JVM found" >&2; exit 1; fi
+`
+)
+
+var ProfilePod string
+var ProfileDuration time.Duration
+var ProfileEvent string
+var ProfilerImage string
+var ProfilerCommand string
+var ProfileOutput string
+var ThreadDump bool
+
+var profileCmd = &cobra.Command{
+	Use:   "profile <name> [--pod <pod>] [--duration <duration>] [--image <image>] [--upload-to <location>]",
+	Short: "Profile the driver or an executor of a running SparkApplication with async-profiler",
+	Long: `Profile the driver or an executor of a running SparkApplication with a given name with async-profiler,
+without rebuilding its image. An ephemeral container running async-profiler from the given image is added to the
+pod, attaches to the JVM for the given duration, and writes a flame graph that is saved to a local file and
+optionally uploaded to a gs:// or s3:// bucket. With --thread-dump, the JVM is asked to print a thread dump to
+its log instead, which is then printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "must specify a SparkApplication name")
+			return
+		}
+
+		kubeClientset, err := getKubeClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get Kubernetes client: %v\n", err)
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		if err := doProfile(args[0], kubeClientset, crdClientset, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to profile SparkApplication %s: %v\n", args[0], err)
+		}
+	},
+}
+
+func init() {
+	profileCmd.Flags().StringVarP(&ProfilePod, "pod", "p", "",
+		"name of the driver or executor pod to profile, the driver pod if unset")
+	profileCmd.Flags().DurationVarP(&ProfileDuration, "duration", "d", 60*time.Second,
+		"how long to profile for")
+	profileCmd.Flags().StringVar(&ProfileEvent, "event", "itimer",
+		"the async-profiler event to sample, e.g., cpu, itimer, wall, alloc, or lock")
+	profileCmd.Flags().StringVar(&ProfilerImage, "image", "",
+		"the image of async-profiler, required unless taking a thread dump, which defaults to the image of the pod")
+	profileCmd.Flags().StringVar(&ProfilerCommand, "profiler", "/opt/async-profiler/bin/asprof",
+		"the path of the async-profiler launcher in the image")
+	profileCmd.Flags().StringVarP(&ProfileOutput, "output", "o", "",
+		"the local file the flame graph is saved to, <pod>-<time>.html if unset")
+	profileCmd.Flags().StringVarP(&UploadToPath, "upload-to", "u", "",
+		"a URL of the gs:// or s3:// bucket the flame graph is uploaded to")
+	profileCmd.Flags().StringVarP(&UploadToRegion, "upload-to-region", "r", "",
+		"the GCS or S3 storage region for the bucket")
+	profileCmd.Flags().StringVarP(&UploadToEndpoint, "upload-to-endpoint", "e",
+		"https://storage.googleapis.com", "the GCS or S3 storage api endpoint url")
+	profileCmd.Flags().BoolVar(&ThreadDump, "thread-dump", false,
+		"take a thread dump of the JVM instead of profiling it")
+}
+
+func doProfile(name string, kubeClientset clientset.Interface, crdClientset crdclientset.Interface, now time.Time) error {
+	app, err := crdClientset.SparkoperatorV1beta1().SparkApplications(Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get SparkApplication %s: %v", name, err)
+	}
+	pod, err := getProfiledPod(app, kubeClientset)
+	if err != nil {
+		return err
+	}
+	target := getSparkContainer(pod)
+	if target == nil {
+		return fmt.Errorf("pod %s has no Spark container", pod.Name)
+	}
+
+	image := ProfilerImage
+	var script string
+	if ThreadDump {
+		if image == "" {
+			image = target.Image
+		}
+		script = buildThreadDumpScript()
+	} else {
+		if image == "" {
+			return fmt.Errorf("must specify the image of async-profiler with --image")
+		}
+		if script, err = buildProfilerScript(ProfilerCommand, ProfileEvent, ProfileDuration); err != nil {
+			return err
+		}
+	}
+
+	containerName := fmt.Sprintf("%s-%d", profilerContainerPrefix, now.Unix())
+	patch, err := buildProfilerContainerPatch(containerName, image, script, target)
+	if err != nil {
+		return err
+	}
+	if _, err := kubeClientset.CoreV1().Pods(Namespace).Patch(pod.Name, types.StrategicMergePatchType, patch,
+		"ephemeralcontainers"); err != nil {
+		return fmt.Errorf("failed to add ephemeral container %s to pod %s: %v", containerName, pod.Name, err)
+	}
+	fmt.Printf("added ephemeral container %s to pod %s\n", containerName, pod.Name)
+
+	log, err := waitForLog(kubeClientset, pod.Name, containerName)
+	if err != nil {
+		return err
+	}
+	if ThreadDump {
+		// The JVM prints the thread dump to the log of the Spark container.
+		sinceTime := metav1.NewTime(now)
+		rawLogs, err := kubeClientset.CoreV1().Pods(Namespace).GetLogs(pod.Name,
+			&apiv1.PodLogOptions{Container: target.Name, SinceTime: &sinceTime}).Do().Raw()
+		if err != nil {
+			return fmt.Errorf("failed to get the log of pod %s: %v", pod.Name, err)
+		}
+		fmt.Println(string(rawLogs))
+		return nil
+	}
+
+	flameGraph, err := extractFlameGraph(log)
+	if err != nil {
+		return err
+	}
+	output := ProfileOutput
+	if output == "" {
+		output = fmt.Sprintf("%s-%s.html", pod.Name, now.UTC().Format("20060102T150405Z"))
+	}
+	if err := ioutil.WriteFile(output, flameGraph, 0644); err != nil {
+		return fmt.Errorf("failed to save the flame graph: %v", err)
+	}
+	fmt.Printf("saved the flame graph of pod %s to %s\n", pod.Name, output)
+
+	if UploadToPath != "" {
+		uh, err := newUploadHandler(context.Background(), UploadToPath)
+		if err != nil {
+			return err
+		}
+		uploadedPath, err := uh.uploadToBucket(filepath.Join(profileUploadRootPath, Namespace, app.Name), output)
+		if err != nil {
+			return fmt.Errorf("failed to upload the flame graph: %v", err)
+		}
+		fmt.Printf("uploaded the flame graph to %s\n", uploadedPath)
+	}
+	return nil
+}
+
+// getProfiledPod returns the running pod of the given application to profile, i.e., the one given by --pod or
+// the driver pod.
+func getProfiledPod(app *v1beta1.SparkApplication, kubeClientset clientset.Interface) (*apiv1.Pod, error) {
+	podName := ProfilePod
+	if podName == "" {
+		podName = app.Status.DriverInfo.PodName
+	}
+	if podName == "" {
+		return nil, fmt.Errorf("SparkApplication %s has no driver pod", app.Name)
+	}
+	pod, err := kubeClientset.CoreV1().Pods(Namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s: %v", podName, err)
+	}
+	if pod.Labels[config.SparkAppNameLabel] != app.Name {
+		return nil, fmt.Errorf("pod %s does not belong to SparkApplication %s", podName, app.Name)
+	}
+	if pod.Status.Phase != apiv1.PodRunning {
+		return nil, fmt.Errorf("pod %s is not running", podName)
+	}
+	return pod, nil
+}
+
+// getSparkContainer returns the driver or executor container of the given pod.
+func getSparkContainer(pod *apiv1.Pod) *apiv1.Container {
+	for i, container := range pod.Spec.Containers {
+		if container.Name == sparkDriverContainerName || container.Name == sparkExecutorContainerName {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// buildProfilerScript returns the script of the profiler container, which profiles the JVM for the given
+// duration and prints the flame graph between markers, as the log of the container is all that can be read.
+func buildProfilerScript(profiler, event string, duration time.Duration) (string, error) {
+	seconds := int(duration.Seconds())
+	if seconds < 1 {
+		return "", fmt.Errorf("the profiling duration must be at least a second")
+	}
+	if strings.ContainsAny(event, " '\"$;&|") {
+		return "", fmt.Errorf("invalid event %q", event)
+	}
+	script := findJVMScript
+	script += fmt.Sprintf("'%s' -d %d -e %s -f /tmp/flamegraph.html \"$pid\" >&2 || exit 1\n",
+		profiler, seconds, event)
+	script += fmt.Sprintf("echo '%s'\ncat /tmp/flamegraph.html\necho '%s'\n",
+		flameGraphBeginMarker, flameGraphEndMarker)
+	return script, nil
+}
+
+// buildThreadDumpScript returns the script of the container taking a thread dump, which sends SIGQUIT to the JVM.
+func buildThreadDumpScript() string {
+	return findJVMScript + `kill -QUIT "$pid" && echo "sent SIGQUIT to JVM $pid"` + "\n"
+}
+
+// buildProfilerContainerPatch returns the patch of the ephemeralcontainers subresource of a pod adding an
+// ephemeral container with the given name, image, and script, which targets the given Spark container to share
+// its process namespace and runs as its user to be able to attach to the JVM.
+func buildProfilerContainerPatch(name, image, script string, target *apiv1.Container) ([]byte, error) {
+	container := map[string]interface{}{
+		"name":                     name,
+		"image":                    image,
+		"command":                  []string{"/bin/sh", "-c", script},
+		"targetContainerName":      target.Name,
+		"terminationMessagePolicy": string(apiv1.TerminationMessageFallbackToLogsOnError),
+	}
+	if target.SecurityContext != nil {
+		securityContext := make(map[string]interface{})
+		if target.SecurityContext.RunAsUser != nil {
+			securityContext["runAsUser"] = *target.SecurityContext.RunAsUser
+		}
+		if target.SecurityContext.RunAsGroup != nil {
+			securityContext["runAsGroup"] = *target.SecurityContext.RunAsGroup
+		}
+		if len(securityContext) > 0 {
+			container["securityContext"] = securityContext
+		}
+	}
+	// The types of ephemeral containers differ between Kubernetes versions, unlike the JSON of the patch.
+	return json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"ephemeralContainers": []interface{}{container}},
+	})
+}
+
+// waitForLog waits for the given container of the given pod to start, and returns its log once it has
+// terminated.
+func waitForLog(kubeClientset clientset.Interface, podName, containerName string) ([]byte, error) {
+	deadline := time.Now().Add(profilerStartTimeout)
+	for {
+		request := kubeClientset.CoreV1().Pods(Namespace).GetLogs(podName,
+			&apiv1.PodLogOptions{Container: containerName, Follow: true})
+		reader, err := request.Stream()
+		if err == nil {
+			defer reader.Close()
+			return ioutil.ReadAll(reader)
+		}
+		// Logs are unavailable until the container has started.
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("container %s of pod %s did not start: %v", containerName, podName, err)
+		}
+		time.Sleep(profilerPollInterval)
+	}
+}
+
+// extractFlameGraph returns the flame graph between the markers in the given log of the profiler container.
+func extractFlameGraph(log []byte) ([]byte, error) {
+	begin := bytes.Index(log, []byte(flameGraphBeginMarker+"\n"))
+	end := bytes.LastIndex(log, []byte(flameGraphEndMarker))
+	if begin < 0 || end < begin {
+		return nil, fmt.Errorf("the profiler did not write a flame graph: %s", strings.TrimSpace(string(log)))
+	}
+	return log[begin+len(flameGraphBeginMarker)+1 : end], nil
+}
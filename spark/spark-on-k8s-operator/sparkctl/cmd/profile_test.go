@@ -0,0 +1,136 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestGetProfiledPod(t *testing.T) {
+	Namespace = "default"
+	newPod := func(name, appName string, phase apiv1.PodPhase) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{config.SparkAppNameLabel: appName},
+			},
+			Status: apiv1.PodStatus{Phase: phase},
+		}
+	}
+	kubeClientset := kubeclientfake.NewSimpleClientset(
+		newPod("foo-driver", "foo", apiv1.PodRunning),
+		newPod("foo-exec-1", "foo", apiv1.PodRunning),
+		newPod("foo-exec-2", "foo", apiv1.PodSucceeded),
+		newPod("bar-exec-1", "bar", apiv1.PodRunning))
+	app := &v1beta1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Status:     v1beta1.SparkApplicationStatus{DriverInfo: v1beta1.DriverInfo{PodName: "foo-driver"}},
+	}
+
+	ProfilePod = ""
+	pod, err := getProfiledPod(app, kubeClientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo-driver", pod.Name)
+
+	ProfilePod = "foo-exec-1"
+	pod, err = getProfiledPod(app, kubeClientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo-exec-1", pod.Name)
+
+	for _, name := range []string{"foo-exec-2", "bar-exec-1", "foo-exec-3"} {
+		ProfilePod = name
+		_, err = getProfiledPod(app, kubeClientset)
+		assert.NotNil(t, err, name)
+	}
+	ProfilePod = ""
+}
+
+func TestBuildProfilerScript(t *testing.T) {
+	script, err := buildProfilerScript("/opt/async-profiler/bin/asprof", "cpu", 90*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, strings.HasPrefix(script, findJVMScript))
+	assert.Contains(t, script, `'/opt/async-profiler/bin/asprof' -d 90 -e cpu -f /tmp/flamegraph.html "$pid"`)
+	assert.Contains(t, script, flameGraphBeginMarker)
+
+	_, err = buildProfilerScript("/opt/async-profiler/bin/asprof", "cpu", 100*time.Millisecond)
+	assert.NotNil(t, err)
+	_, err = buildProfilerScript("/opt/async-profiler/bin/asprof", "cpu; rm -rf /", time.Minute)
+	assert.NotNil(t, err)
+}
+
+func TestBuildProfilerContainerPatch(t *testing.T) {
+	user := int64(185)
+	target := &apiv1.Container{
+		Name:            sparkExecutorContainerName,
+		SecurityContext: &apiv1.SecurityContext{RunAsUser: &user},
+	}
+	patch, err := buildProfilerContainerPatch("sparkctl-profiler-1", "async-profiler:2.9", "true", target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pod struct {
+		Spec struct {
+			EphemeralContainers []struct {
+				apiv1.Container
+				TargetContainerName string `json:"targetContainerName"`
+			} `json:"ephemeralContainers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(patch, &pod); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(pod.Spec.EphemeralContainers))
+	container := pod.Spec.EphemeralContainers[0]
+	assert.Equal(t, "sparkctl-profiler-1", container.Name)
+	assert.Equal(t, "async-profiler:2.9", container.Image)
+	assert.Equal(t, []string{"/bin/sh", "-c", "true"}, container.Command)
+	assert.Equal(t, sparkExecutorContainerName, container.TargetContainerName)
+	assert.Equal(t, user, *container.SecurityContext.RunAsUser)
+	assert.Nil(t, container.SecurityContext.RunAsGroup)
+}
+
+func TestExtractFlameGraph(t *testing.T) {
+	log := "Profiling for 60 seconds\nDone\n" + flameGraphBeginMarker + "\n<html></html>\n" + flameGraphEndMarker + "\n"
+	flameGraph, err := extractFlameGraph([]byte(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "<html></html>\n", string(flameGraph))
+
+	_, err = extractFlameGraph([]byte("no JVM found\n"))
+	assert.NotNil(t, err)
+}
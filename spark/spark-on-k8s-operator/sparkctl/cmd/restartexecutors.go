@@ -0,0 +1,91 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+var RestartBatchSize int32
+var RestartInterval time.Duration
+
+var restartExecutorsCmd = &cobra.Command{
+	Use:   "restart-executors <name> [--batch <size>] [--interval <duration>]",
+	Short: "Restart the executors of a running SparkApplication in waves",
+	Long: `Restart the executors of a running SparkApplication with a given name without restarting its driver. The
+operator deletes the executors in waves of the given size while Spark replaces them, so that the new executors
+pick up rotated secrets and ConfigMaps. Each wave starts once the replacements of the previous one are running
+and the interval has passed. The progress is reported in the executorRestart field of the status.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "must specify a SparkApplication name")
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		if err := doRestartExecutors(args[0], crdClientset, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to restart the executors of SparkApplication %s: %v\n", args[0], err)
+			return
+		}
+		fmt.Printf("requested a restart of the executors of SparkApplication \"%s\"\n", args[0])
+	},
+}
+
+func init() {
+	restartExecutorsCmd.Flags().Int32VarP(&RestartBatchSize, "batch", "b", 10,
+		"number of executors restarted in each wave")
+	restartExecutorsCmd.Flags().DurationVarP(&RestartInterval, "interval", "i", 30*time.Second,
+		"minimum time between two waves")
+}
+
+func doRestartExecutors(name string, crdClientset crdclientset.Interface, now time.Time) error {
+	request, err := config.NewExecutorRestartRequest(RestartBatchSize, RestartInterval, now)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		app, err := crdClientset.SparkoperatorV1beta1().SparkApplications(Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if app.Status.AppState.State != v1beta1.RunningState {
+			return fmt.Errorf("SparkApplication %s is not running", name)
+		}
+		if app.Annotations == nil {
+			app.Annotations = make(map[string]string)
+		}
+		app.Annotations[config.RestartExecutorsAnnotation] = request
+		_, err = crdClientset.SparkoperatorV1beta1().SparkApplications(Namespace).Update(app)
+		return err
+	})
+}
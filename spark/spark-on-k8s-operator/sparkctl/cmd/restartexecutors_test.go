@@ -0,0 +1,73 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientfake "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned/fake"
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/config"
+)
+
+func TestRestartExecutors(t *testing.T) {
+	Namespace = "default"
+	RestartBatchSize = 5
+	RestartInterval = time.Minute
+	crdClientset := crdclientfake.NewSimpleClientset()
+	for _, app := range []*v1beta1.SparkApplication{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{State: v1beta1.RunningState},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"},
+			Status: v1beta1.SparkApplicationStatus{
+				AppState: v1beta1.ApplicationState{State: v1beta1.CompletedState},
+			},
+		},
+	} {
+		if _, err := crdClientset.SparkoperatorV1beta1().SparkApplications(app.Namespace).Create(app); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Date(2019, 1, 2, 3, 4, 5, 600, time.UTC)
+	assert.Nil(t, doRestartExecutors("foo", crdClientset, now))
+	app, err := crdClientset.SparkoperatorV1beta1().SparkApplications("default").Get("foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := config.ParseExecutorRestartRequest(app.Annotations[config.RestartExecutorsAnnotation])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, now.Truncate(time.Second), request.RequestedAt)
+	assert.Equal(t, int32(5), request.BatchSize)
+	assert.Equal(t, int64(60), request.IntervalSeconds)
+
+	assert.NotNil(t, doRestartExecutors("bar", crdClientset, now))
+	RestartBatchSize = 0
+	assert.NotNil(t, doRestartExecutors("foo", crdClientset, now))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var resubmitCmd = &cobra.Command{
+	Use:   "resubmit <name> | -l <selector> [--all-namespaces]",
+	Short: "Resubmit SparkApplications",
+	Long: `Invalidate the current run of a SparkApplication with a given name, or of all the ones matching a label
+selector, so that the operator cleans up the run and submits the application again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkBulkArgs(args, "SparkApplication"); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		if Selector != "" {
+			if err := doBulkResubmit(crdClientset); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to resubmit SparkApplications: %v\n", err)
+			}
+			return
+		}
+		if err := doResubmit(Namespace, args[0], crdClientset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to resubmit SparkApplication %s: %v\n", args[0], err)
+			return
+		}
+		fmt.Printf("SparkApplication \"%s\" resubmitted\n", args[0])
+	},
+}
+
+func init() {
+	addBulkFlags(resubmitCmd, "SparkApplication")
+}
+
+func doBulkResubmit(crdClientset crdclientset.Interface) error {
+	apps, err := listSelectedSparkApplications(crdClientset)
+	if err != nil {
+		return err
+	}
+	var targets []bulkTarget
+	for _, app := range apps {
+		targets = append(targets, bulkTarget{namespace: app.Namespace, name: app.Name})
+	}
+	return runBulk("resubmit", "resubmitted SparkApplication", targets, func(target bulkTarget) error {
+		return doResubmit(target.namespace, target.name, crdClientset)
+	})
+}
+
+// doResubmit invalidates the current run of the given application, the same way a change of its spec does.
+func doResubmit(namespace string, name string, crdClientset crdclientset.Interface) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		app, err := crdClientset.SparkoperatorV1beta1().SparkApplications(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		switch app.Status.AppState.State {
+		case v1beta1.InvalidatingState, v1beta1.PendingRerunState:
+			// The application is already being rerun.
+			return nil
+		}
+		app.Status.AppState.State = v1beta1.InvalidatingState
+		app.Status.AppState.ErrorMessage = ""
+		_, err = crdClientset.SparkoperatorV1beta1().SparkApplications(namespace).UpdateStatus(app)
+		return err
+	})
+}
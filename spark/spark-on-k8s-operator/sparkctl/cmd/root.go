@@ -48,7 +48,8 @@ func init() {
 		"The namespace in which the SparkApplication is to be created")
 	rootCmd.PersistentFlags().StringVarP(&KubeConfig, "kubeconfig", "k", defaultKubeConfig,
 		"The path to the local Kubernetes configuration file")
-	rootCmd.AddCommand(createCmd, deleteCmd, eventCommand, statusCmd, logCommand, listCmd, forwardCmd)
+	rootCmd.AddCommand(createCmd, deleteCmd, killCmd, eventCommand, statusCmd, logCommand, listCmd, forwardCmd,
+		exportCmd, importCmd, resubmitCmd, suspendCmd, resumeCmd, restartExecutorsCmd, profileCmd, describeCmd, usageCmd)
 }
 
 func Execute() {
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var suspendCmd = &cobra.Command{
+	Use:   "suspend <name> | -l <selector> [--all-namespaces]",
+	Short: "Suspend ScheduledSparkApplications",
+	Long: `Suspend the subsequent runs of a ScheduledSparkApplication with a given name, or of all the ones matching
+a label selector. Runs that have already started are not affected.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSetSuspend(args, true)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume <name> | -l <selector> [--all-namespaces]",
+	Short: "Resume suspended ScheduledSparkApplications",
+	Long: `Resume the runs of a suspended ScheduledSparkApplication with a given name, or of all the ones matching a
+label selector.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSetSuspend(args, false)
+	},
+}
+
+func init() {
+	addBulkFlags(suspendCmd, "ScheduledSparkApplication")
+	addBulkFlags(resumeCmd, "ScheduledSparkApplication")
+}
+
+func runSetSuspend(args []string, suspend bool) {
+	verb, pastVerb := "suspend", "suspended"
+	if !suspend {
+		verb, pastVerb = "resume", "resumed"
+	}
+	if err := checkBulkArgs(args, "ScheduledSparkApplication"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	crdClientset, err := getSparkApplicationClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+		return
+	}
+
+	if Selector != "" {
+		if err := doBulkSetSuspend(suspend, verb, pastVerb, crdClientset); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to %s ScheduledSparkApplications: %v\n", verb, err)
+		}
+		return
+	}
+	if err := doSetSuspend(Namespace, args[0], suspend, crdClientset); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to %s ScheduledSparkApplication %s: %v\n", verb, args[0], err)
+		return
+	}
+	fmt.Printf("ScheduledSparkApplication \"%s\" %s\n", args[0], pastVerb)
+}
+
+func doBulkSetSuspend(suspend bool, verb string, pastVerb string, crdClientset crdclientset.Interface) error {
+	apps, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(getSelectedNamespace()).List(
+		metav1.ListOptions{LabelSelector: Selector})
+	if err != nil {
+		return fmt.Errorf("failed to list ScheduledSparkApplications: %v", err)
+	}
+	var targets []bulkTarget
+	for _, app := range apps.Items {
+		targets = append(targets, bulkTarget{namespace: app.Namespace, name: app.Name})
+	}
+	return runBulk(verb, pastVerb+" ScheduledSparkApplication", targets, func(target bulkTarget) error {
+		return doSetSuspend(target.namespace, target.name, suspend, crdClientset)
+	})
+}
+
+func doSetSuspend(namespace string, name string, suspend bool, crdClientset crdclientset.Interface) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		app, err := crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(namespace).Get(name,
+			metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if app.Spec.Suspend != nil && *app.Spec.Suspend == suspend {
+			return nil
+		}
+		app.Spec.Suspend = &suspend
+		_, err = crdClientset.SparkoperatorV1beta1().ScheduledSparkApplications(namespace).Update(app)
+		return err
+	})
+}
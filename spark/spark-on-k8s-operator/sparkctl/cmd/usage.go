@@ -0,0 +1,243 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+	crdclientset "github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/clientset/versioned"
+)
+
+var UsageSince string
+var UsageGroupBy string
+var UsageTeamLabel string
+var UsageOutput string
+var CoreHourPrice float64
+var GBHourPrice float64
+
+// usageGroupKeys return the key of the group a run is reported in for each supported --group-by.
+var usageGroupKeys = map[string]func(run *v1beta1.SparkApplicationRun) string{
+	"app": func(run *v1beta1.SparkApplicationRun) string {
+		return run.Namespace + "/" + run.Spec.ApplicationName
+	},
+	"team": func(run *v1beta1.SparkApplicationRun) string {
+		return run.Labels[UsageTeamLabel]
+	},
+	"namespace": func(run *v1beta1.SparkApplicationRun) string {
+		return run.Namespace
+	},
+	"user": func(run *v1beta1.SparkApplicationRun) string {
+		return run.Status.SubmittedBy
+	},
+}
+
+// usageRow is the usage of a group of runs.
+type usageRow struct {
+	Group            string  `json:"group"`
+	Runs             int     `json:"runs"`
+	FailedRuns       int     `json:"failedRuns"`
+	CoreHours        float64 `json:"coreHours"`
+	GBHours          float64 `json:"gbHours"`
+	EstimatedCost    float64 `json:"estimatedCost,omitempty"`
+	coreSeconds      int64
+	memoryMiBSeconds int64
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report the resource usage and cost of SparkApplications",
+	Long: `Report the core-hours, GB-hours, and estimated cost of the runs of SparkApplications that ended within a
+given duration, grouped by application, team, namespace, or user. Only the runs of SparkApplications that set
+runHistory are recorded and reported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		since, err := parseUsageSince(UsageSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --since %q: %v\n", UsageSince, err)
+			return
+		}
+		if _, ok := usageGroupKeys[UsageGroupBy]; !ok {
+			fmt.Fprintf(os.Stderr, "unsupported --group-by %q, must be one of app, team, namespace, and user\n",
+				UsageGroupBy)
+			return
+		}
+		if UsageOutput != "table" && UsageOutput != "csv" && UsageOutput != "json" {
+			fmt.Fprintf(os.Stderr, "unsupported output format %q, must be one of table, csv, and json\n", UsageOutput)
+			return
+		}
+
+		crdClientset, err := getSparkApplicationClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get SparkApplication client: %v\n", err)
+			return
+		}
+
+		if err = doUsage(crdClientset, os.Stdout, time.Now().Add(-since)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to report usage: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&UsageSince, "since", "7d",
+		"the duration before now in which the reported runs ended, e.g., 7d or 12h")
+	usageCmd.Flags().StringVarP(&UsageGroupBy, "group-by", "g", "app",
+		"what to aggregate usage by: app, team, namespace, or user")
+	usageCmd.Flags().StringVar(&UsageTeamLabel, "team-label", "team",
+		"the label of SparkApplications naming their team, used with --group-by team")
+	usageCmd.Flags().StringVarP(&UsageOutput, "output", "o", "table", "the output format: table, csv, or json")
+	usageCmd.Flags().Float64Var(&CoreHourPrice, "core-hour-price", 0,
+		"the price of a core-hour, for estimating the cost of the runs")
+	usageCmd.Flags().Float64Var(&GBHourPrice, "gb-hour-price", 0,
+		"the price of a GB-hour of memory, for estimating the cost of the runs")
+	usageCmd.Flags().BoolVarP(&AllNamespaces, "all-namespaces", "A", false,
+		"whether to report the usage in all namespaces instead of only the given namespace")
+}
+
+// parseUsageSince parses a duration like time.ParseDuration, and also accepts a number of days, e.g., 7d.
+func parseUsageSince(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid number of days")
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func doUsage(crdClientset crdclientset.Interface, out io.Writer, since time.Time) error {
+	groups := make(map[string]*usageRow)
+	groupKey := usageGroupKeys[UsageGroupBy]
+	options := metav1.ListOptions{Limit: listPageSize}
+	for {
+		runs, err := crdClientset.SparkoperatorV1beta1().SparkApplicationRuns(getSelectedNamespace()).List(options)
+		if err != nil {
+			return fmt.Errorf("failed to list SparkApplicationRuns: %v", err)
+		}
+		for i := range runs.Items {
+			run := &runs.Items[i]
+			if run.Status.TerminationTime.Time.Before(since) {
+				continue
+			}
+			key := groupKey(run)
+			if key == "" {
+				key = "<none>"
+			}
+			row, ok := groups[key]
+			if !ok {
+				row = &usageRow{Group: key}
+				groups[key] = row
+			}
+			row.Runs++
+			if run.Status.State != v1beta1.CompletedState {
+				row.FailedRuns++
+			}
+			row.coreSeconds += run.Status.ResourceUsage.CoreSeconds
+			row.memoryMiBSeconds += run.Status.ResourceUsage.MemoryMiBSeconds
+		}
+		if runs.Continue == "" {
+			break
+		}
+		options.Continue = runs.Continue
+	}
+
+	var rows []*usageRow
+	for _, row := range groups {
+		row.CoreHours = float64(row.coreSeconds) / 3600
+		row.GBHours = float64(row.memoryMiBSeconds) / 1024 / 3600
+		// Costs are estimated to the cent.
+		row.EstimatedCost = math.Round((row.CoreHours*CoreHourPrice+row.GBHours*GBHourPrice)*100) / 100
+		rows = append(rows, row)
+	}
+	// The most expensive groups come first.
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].CoreHours != rows[j].CoreHours {
+			return rows[i].CoreHours > rows[j].CoreHours
+		}
+		return rows[i].Group < rows[j].Group
+	})
+	return writeUsage(rows, out)
+}
+
+func writeUsage(rows []*usageRow, out io.Writer) error {
+	withCost := CoreHourPrice > 0 || GBHourPrice > 0
+	switch UsageOutput {
+	case "json":
+		if rows == nil {
+			rows = []*usageRow{}
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(data, '\n'))
+		return err
+	case "csv":
+		writer := csv.NewWriter(out)
+		header := []string{UsageGroupBy, "runs", "failed_runs", "core_hours", "gb_hours"}
+		if withCost {
+			header = append(header, "estimated_cost")
+		}
+		writer.Write(header)
+		for _, row := range rows {
+			writer.Write(formatUsageRow(row, withCost))
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	table := tablewriter.NewWriter(out)
+	header := []string{strings.Title(UsageGroupBy), "Runs", "Failed Runs", "Core-Hours", "GB-Hours"}
+	if withCost {
+		header = append(header, "Estimated Cost")
+	}
+	table.SetHeader(header)
+	for _, row := range rows {
+		table.Append(formatUsageRow(row, withCost))
+	}
+	table.Render()
+	return nil
+}
+
+func formatUsageRow(row *usageRow, withCost bool) []string {
+	fields := []string{
+		row.Group,
+		strconv.Itoa(row.Runs),
+		strconv.Itoa(row.FailedRuns),
+		strconv.FormatFloat(row.CoreHours, 'f', 2, 64),
+		strconv.FormatFloat(row.GBHours, 'f', 2, 64),
+	}
+	if withCost {
+		fields = append(fields, strconv.FormatFloat(row.EstimatedCost, 'f', 2, 64))
+	}
+	return fields
+}
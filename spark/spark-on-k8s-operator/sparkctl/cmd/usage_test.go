@@ -0,0 +1,115 @@
+/*
+Copyright 2018 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/apis/sparkoperator.k8s.io/v1beta1"
+)
+
+func newUsageRun(name, app, team string, state v1beta1.ApplicationStateType, terminated time.Time,
+	coreSeconds, memoryMiBSeconds int64) *v1beta1.SparkApplicationRun {
+	return &v1beta1.SparkApplicationRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"team": team}},
+		Spec:       v1beta1.SparkApplicationRunSpec{ApplicationName: app},
+		Status: v1beta1.SparkApplicationRunStatus{
+			State:           state,
+			TerminationTime: metav1.NewTime(terminated),
+			ResourceUsage:   v1beta1.RunResourceUsage{CoreSeconds: coreSeconds, MemoryMiBSeconds: memoryMiBSeconds},
+		},
+	}
+}
+
+func TestParseUsageSince(t *testing.T) {
+	since, err := parseUsageSince("7d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 7*24*time.Hour, since)
+	since, err = parseUsageSince("12h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 12*time.Hour, since)
+	_, err = parseUsageSince("xd")
+	assert.NotNil(t, err)
+}
+
+func TestUsage(t *testing.T) {
+	Namespace = "default"
+	now := time.Date(2019, 1, 8, 0, 0, 0, 0, time.UTC)
+	crdClientset := newBulkFakeClientset()
+	for _, run := range []*v1beta1.SparkApplicationRun{
+		newUsageRun("foo-1", "foo", "ads", v1beta1.CompletedState, now.Add(-time.Hour), 7200, 1024*3600),
+		newUsageRun("foo-2", "foo", "ads", v1beta1.FailedState, now.Add(-2*time.Hour), 3600, 1024*3600),
+		newUsageRun("bar-1", "bar", "ads", v1beta1.CompletedState, now.Add(-3*time.Hour), 36000, 0),
+		newUsageRun("baz-1", "baz", "search", v1beta1.CompletedState, now.Add(-time.Hour), 3600, 0),
+		// A run that ended too long ago.
+		newUsageRun("foo-0", "foo", "ads", v1beta1.CompletedState, now.Add(-8*24*time.Hour), 3600, 0),
+	} {
+		if _, err := crdClientset.SparkoperatorV1beta1().SparkApplicationRuns(run.Namespace).Create(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	UsageGroupBy = "team"
+	UsageTeamLabel = "team"
+	UsageOutput = "json"
+	CoreHourPrice = 0.05
+	GBHourPrice = 0.01
+	var out bytes.Buffer
+	if err := doUsage(crdClientset, &out, now.Add(-7*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	var rows []usageRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []usageRow{
+		{Group: "ads", Runs: 3, FailedRuns: 1, CoreHours: 13, GBHours: 2, EstimatedCost: 0.67},
+		{Group: "search", Runs: 1, CoreHours: 1, EstimatedCost: 0.05},
+	}, rows)
+
+	UsageGroupBy = "app"
+	UsageOutput = "csv"
+	out.Reset()
+	if err := doUsage(crdClientset, &out, now.Add(-7*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "app,runs,failed_runs,core_hours,gb_hours,estimated_cost\n"+
+		"default/bar,1,0,10.00,0.00,0.50\n"+
+		"default/foo,2,1,3.00,2.00,0.17\n"+
+		"default/baz,1,0,1.00,0.00,0.05\n", out.String())
+
+	UsageOutput = "table"
+	CoreHourPrice = 0
+	GBHourPrice = 0
+	out.Reset()
+	if err := doUsage(crdClientset, &out, now.Add(-7*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, out.String(), "CORE-HOURS")
+	assert.NotContains(t, out.String(), "ESTIMATED COST")
+}
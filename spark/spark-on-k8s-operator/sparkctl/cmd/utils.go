@@ -31,6 +31,15 @@ func getSinceTime(timestamp metav1.Time) string {
 	return duration.ShortHumanDuration(time.Since(timestamp.Time))
 }
 
+// getAge is like getSinceTime, but relative to the given time.
+func getAge(timestamp metav1.Time, now time.Time) string {
+	if timestamp.IsZero() {
+		return "N.A."
+	}
+
+	return duration.ShortHumanDuration(now.Sub(timestamp.Time))
+}
+
 func formatNotAvailable(info string) string {
 	if info == "" {
 		return "N.A."